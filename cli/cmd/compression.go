@@ -0,0 +1,70 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolCompressionUse   = "compression [COMMAND]"
+	cmdVolCompressionShort = "Manage the compression of the data of a volume"
+)
+
+func newVolCompressionCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolCompressionUse,
+		Short: cmdVolCompressionShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newVolCompressionSetCmd(client),
+	)
+	return cmd
+}
+
+const (
+	cmdVolCompressionSetUse   = "set [VOLUME NAME] [CODEC]"
+	cmdVolCompressionSetShort = "Set the codec compressing the data written afterwards: snappy, lz4 or none"
+)
+
+func newVolCompressionSetCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolCompressionSetUse,
+		Short: cmdVolCompressionSetShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName, codec = args[0], args[1]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			if err = client.AdminAPI().SetVolumeCompression(volumeName, calcAuthKey(svv.Owner), codec); err != nil {
+				return
+			}
+			stdout("Set compression of volume [%v] to [%v] success.\n", volumeName, codec)
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
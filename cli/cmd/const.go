@@ -69,10 +69,13 @@ const (
 	CliFlagINodeStartID       = "inode-start"
 	CliFlagId                 = "id"
 	CliFlagZoneName           = "zonename"
+	CliFlagEncryption         = "encryption"
 	CliFlagAutoRepairRate     = "auto-repair-rate"
 	CliFlagDelBatchCount      = "delete-batch-count"
 	CliFlagDelWorkerSleepMs   = "delete-worker-sleep-ms"
 	CliFlagMarkDelRate        = "mark-delete-rate"
+	CliFlagS3RequestRate      = "request-rate"
+	CliFlagS3Bandwidth        = "bandwidth"
 
 	//CliFlagSetDataPartitionCount	= "count" use dp-count instead
 
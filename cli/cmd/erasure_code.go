@@ -0,0 +1,84 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolErasureCodeUse   = "ec [COMMAND]"
+	cmdVolErasureCodeShort = "Manage the erasure coded data partitions of a volume"
+)
+
+func newVolErasureCodeCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolErasureCodeUse,
+		Short: cmdVolErasureCodeShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newVolErasureCodeSetCmd(client),
+	)
+	return cmd
+}
+
+const (
+	cmdVolErasureCodeSetUse   = "set [VOLUME NAME] [DATA SHARDS] [PARITY SHARDS] [COLD DAYS]"
+	cmdVolErasureCodeSetShort = "Set the erasure code of the volume, 0 data and parity shards disable it"
+)
+
+func newVolErasureCodeSetCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolErasureCodeSetUse,
+		Short: cmdVolErasureCodeSetShort,
+		Args:  cobra.MinimumNArgs(4),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var dataNum, parityNum, coldDays uint64
+			if dataNum, err = strconv.ParseUint(args[1], 10, 8); err != nil {
+				return
+			}
+			if parityNum, err = strconv.ParseUint(args[2], 10, 8); err != nil {
+				return
+			}
+			if coldDays, err = strconv.ParseUint(args[3], 10, 32); err != nil {
+				return
+			}
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			if err = client.AdminAPI().SetVolumeErasureCode(volumeName, calcAuthKey(svv.Owner), uint8(dataNum),
+				uint8(parityNum), uint32(coldDays)); err != nil {
+				return
+			}
+			stdout("Set erasure code of volume [%v] to [%v+%v] with cold days [%v] success.\n",
+				volumeName, dataNum, parityNum, coldDays)
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/meta"
 )
 
 func formatClusterView(cv *proto.ClusterView) string {
@@ -87,6 +88,41 @@ func formatNodeView(view *proto.NodeView, tableRow bool) string {
 	return sb.String()
 }
 
+func formatErasureCode(svv *proto.SimpleVolView) string {
+	if svv.ECDataNum == 0 {
+		return "Disabled"
+	}
+	return fmt.Sprintf("%v+%v, cold after %v days", svv.ECDataNum, svv.ECParityNum, svv.ECColdDays)
+}
+
+func formatCompression(compression string) string {
+	if compression == "" {
+		return "Disabled"
+	}
+	return compression
+}
+
+func formatS3QoS(requestRate, bandwidth uint64) string {
+	var limits = make([]string, 0, 2)
+	if requestRate > 0 {
+		limits = append(limits, fmt.Sprintf("%v requests/s", requestRate))
+	}
+	if bandwidth > 0 {
+		limits = append(limits, fmt.Sprintf("%v/s", formatSize(bandwidth)))
+	}
+	if len(limits) == 0 {
+		return "Unlimited"
+	}
+	return strings.Join(limits, ", ")
+}
+
+func formatColdZone(zoneName string) string {
+	if zoneName == "" {
+		return "Disabled"
+	}
+	return zoneName
+}
+
 func formatSimpleVolView(svv *proto.SimpleVolView) string {
 
 	var sb = strings.Builder{}
@@ -101,6 +137,12 @@ func formatSimpleVolView(svv *proto.SimpleVolView) string {
 	sb.WriteString(fmt.Sprintf("  Follower read        : %v\n", formatEnabledDisabled(svv.FollowerRead)))
 	sb.WriteString(fmt.Sprintf("  Enable token         : %v\n", formatEnabledDisabled(svv.EnableToken)))
 	sb.WriteString(fmt.Sprintf("  Cross zone           : %v\n", formatEnabledDisabled(svv.CrossZone)))
+	sb.WriteString(fmt.Sprintf("  Trash days           : %v\n", svv.TrashDays))
+	sb.WriteString(fmt.Sprintf("  Erasure code         : %v\n", formatErasureCode(svv)))
+	sb.WriteString(fmt.Sprintf("  Compression          : %v\n", formatCompression(svv.Compression)))
+	sb.WriteString(fmt.Sprintf("  Encryption           : %v\n", formatEnabledDisabled(svv.Encrypted)))
+	sb.WriteString(fmt.Sprintf("  S3 QoS               : %v\n", formatS3QoS(svv.S3RequestRate, svv.S3Bandwidth)))
+	sb.WriteString(fmt.Sprintf("  Cold zone            : %v\n", formatColdZone(svv.ColdZoneName)))
 	sb.WriteString(fmt.Sprintf("  Inode count          : %v\n", svv.InodeCount))
 	sb.WriteString(fmt.Sprintf("  Dentry count         : %v\n", svv.DentryCount))
 	sb.WriteString(fmt.Sprintf("  Max metaPartition ID : %v\n", svv.MaxMetaPartitionID))
@@ -133,6 +175,51 @@ func formatVolInfoTableRow(vi *proto.VolInfo) string {
 		formatVolumeStatus(vi.Status), time.Unix(vi.CreateTime, 0).Local().Format(time.RFC1123))
 }
 
+var (
+	quotaTablePattern = "%-8v    %-32v    %-12v    %-12v    %-12v    %-12v    %-12v    %-8v"
+	quotaTableHeader  = fmt.Sprintf(quotaTablePattern,
+		"ID", "PATH", "INODE", "USED FILES", "MAX FILES", "USED BYTES", "MAX BYTES", "EXCEEDED")
+)
+
+func formatQuotaTableRow(quota *proto.QuotaInfo) string {
+	var maxFiles, maxBytes = "Unlimited", "Unlimited"
+	if quota.MaxFiles > 0 {
+		maxFiles = strconv.FormatUint(quota.MaxFiles, 10)
+	}
+	if quota.MaxBytes > 0 {
+		maxBytes = formatSize(quota.MaxBytes)
+	}
+	return fmt.Sprintf(quotaTablePattern,
+		quota.QuotaID, quota.Path, quota.RootInode, quota.UsedFiles, maxFiles,
+		formatSize(quota.UsedBytes), maxBytes, formatYesNo(quota.Exceeded))
+}
+
+var (
+	trashTablePattern = "%-10v    %-40v    %-12v    %-6v    %-12v    %-24v    %-10v"
+	trashTableHeader  = fmt.Sprintf(trashTablePattern,
+		"BUCKET", "NAME", "INODE", "TYPE", "PARENT", "ORIGINAL NAME", "DELETE TIME")
+)
+
+func formatTrashTableRow(entry *meta.TrashEntry) string {
+	var typ = "File"
+	if proto.IsDir(entry.Type) {
+		typ = "Dir"
+	}
+	return fmt.Sprintf(trashTablePattern,
+		entry.Bucket, entry.Name, entry.Inode, typ, entry.ParentID, entry.TrashOrigin.Name,
+		time.Unix(entry.DeleteTime, 0).Local().Format(time.RFC1123))
+}
+
+var (
+	snapshotTablePattern = "%-8v    %-32v    %-10v"
+	snapshotTableHeader  = fmt.Sprintf(snapshotTablePattern, "ID", "NAME", "CREATE TIME")
+)
+
+func formatSnapshotTableRow(snapshot *proto.SnapshotInfo) string {
+	return fmt.Sprintf(snapshotTablePattern,
+		snapshot.ID, snapshot.Name, time.Unix(snapshot.CreateTime, 0).Local().Format(time.RFC1123))
+}
+
 var (
 	dataPartitionTablePattern = "%-8v    %-8v    %-10v    %-10v     %-18v    %-18v"
 	dataPartitionTableHeader  = fmt.Sprintf(dataPartitionTablePattern,
@@ -0,0 +1,196 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/chubaofs/chubaofs/sdk/meta"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdQuotaUse   = "quota [COMMAND]"
+	cmdQuotaShort = "Manage volume and directory quotas"
+)
+
+func newQuotaCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdQuotaUse,
+		Short: cmdQuotaShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newQuotaListCmd(client),
+		newQuotaSetCmd(client),
+		newQuotaDeleteCmd(client),
+	)
+	return cmd
+}
+
+const (
+	cmdQuotaListUse   = "list [VOLUME NAME]"
+	cmdQuotaListShort = "List quotas of a volume"
+)
+
+func newQuotaListCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     cmdQuotaListUse,
+		Short:   cmdQuotaListShort,
+		Aliases: []string{"ls"},
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var quotas []*proto.QuotaInfo
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			if quotas, err = client.AdminAPI().ListQuota(args[0]); err != nil {
+				return
+			}
+			stdout("%v\n", quotaTableHeader)
+			for _, quota := range quotas {
+				stdout("%v\n", formatQuotaTableRow(quota))
+			}
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+const (
+	cmdQuotaSetUse   = "set [VOLUME NAME] [PATH]"
+	cmdQuotaSetShort = "Set the quota of a directory, the quota of \"/\" limits the whole volume"
+)
+
+func newQuotaSetCmd(client *master.MasterClient) *cobra.Command {
+	var optMaxFiles uint64
+	var optMaxBytes uint64
+	var cmd = &cobra.Command{
+		Use:   cmdQuotaSetUse,
+		Short: cmdQuotaSetShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			var path = args[1]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			var mw *meta.MetaWrapper
+			if mw, err = meta.NewMetaWrapper(&meta.MetaConfig{Volume: volumeName, Masters: client.Nodes()}); err != nil {
+				return
+			}
+			defer func() {
+				_ = mw.Close()
+			}()
+			var inode uint64
+			if inode, err = lookupPath(mw, path); err != nil {
+				err = fmt.Errorf("Lookup path [%v] failed: %v\n", path, err)
+				return
+			}
+			var quota *proto.QuotaInfo
+			if quota, err = client.AdminAPI().SetQuota(volumeName, calcAuthKey(svv.Owner), path, inode, optMaxFiles, optMaxBytes); err != nil {
+				return
+			}
+			// inodes created from now on inherit the quota of the directory, tag the existing ones
+			if quota.QuotaID != proto.RootQuotaID {
+				if err = mw.ApplyQuota_ll(inode, quota.QuotaID); err != nil {
+					err = fmt.Errorf("Apply quota [%v] to path [%v] failed: %v\n", quota.QuotaID, path, err)
+					return
+				}
+			}
+			stdout("Set quota [%v] of path [%v] success.\n", quota.QuotaID, path)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().Uint64Var(&optMaxFiles, "max-files", 0, "Specify the max number of inodes, 0 means unlimited")
+	cmd.Flags().Uint64Var(&optMaxBytes, "max-bytes", 0, "Specify the max number of bytes, 0 means unlimited")
+	return cmd
+}
+
+const (
+	cmdQuotaDeleteUse   = "delete [VOLUME NAME] [QUOTA ID]"
+	cmdQuotaDeleteShort = "Delete a quota of a volume"
+)
+
+func newQuotaDeleteCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdQuotaDeleteUse,
+		Short: cmdQuotaDeleteShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var quotaID uint64
+			if quotaID, err = strconv.ParseUint(args[1], 10, 32); err != nil {
+				return
+			}
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			if err = client.AdminAPI().DeleteQuota(volumeName, calcAuthKey(svv.Owner), uint32(quotaID)); err != nil {
+				return
+			}
+			stdout("Delete quota [%v] success.\n", quotaID)
+		},
+	}
+	return cmd
+}
+
+func lookupPath(mw *meta.MetaWrapper, path string) (inode uint64, err error) {
+	inode = proto.RootIno
+	for _, name := range strings.Split(path, "/") {
+		if name == "" {
+			continue
+		}
+		var mode uint32
+		if inode, mode, err = mw.Lookup_ll(inode, name); err != nil {
+			return
+		}
+		if !proto.IsDir(mode) {
+			return 0, fmt.Errorf("%v is not a directory", name)
+		}
+	}
+	return
+}
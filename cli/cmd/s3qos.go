@@ -0,0 +1,63 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolS3QoSUse   = "s3qos [VOLUME NAME]"
+	cmdVolS3QoSShort = "Limit the requests of a volume served by the ObjectNodes"
+)
+
+func newVolS3QoSCmd(client *master.MasterClient) *cobra.Command {
+	var optRequestRate, optBandwidth uint64
+	var cmd = &cobra.Command{
+		Use:   cmdVolS3QoSUse,
+		Short: cmdVolS3QoSShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			var requestRate, bandwidth = svv.S3RequestRate, svv.S3Bandwidth
+			if cmd.Flags().Changed(CliFlagS3RequestRate) {
+				requestRate = optRequestRate
+			}
+			if cmd.Flags().Changed(CliFlagS3Bandwidth) {
+				bandwidth = optBandwidth
+			}
+			if err = client.AdminAPI().SetVolumeS3QoS(volumeName, calcAuthKey(svv.Owner), requestRate, bandwidth); err != nil {
+				return
+			}
+			stdout("Set S3 QoS of volume [%v] to [%v] success.\n", volumeName, formatS3QoS(requestRate, bandwidth))
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	cmd.Flags().Uint64Var(&optRequestRate, CliFlagS3RequestRate, 0, "Specify requests per second, 0 means unlimited")
+	cmd.Flags().Uint64Var(&optBandwidth, CliFlagS3Bandwidth, 0, "Specify bytes per second, 0 means unlimited")
+	return cmd
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolSnapshotUse   = "snapshot [COMMAND]"
+	cmdVolSnapshotShort = "Manage read-only snapshots of a volume"
+)
+
+func newVolSnapshotCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolSnapshotUse,
+		Short: cmdVolSnapshotShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newVolSnapshotCreateCmd(client),
+		newVolSnapshotDeleteCmd(client),
+		newVolSnapshotListCmd(client),
+	)
+	return cmd
+}
+
+const (
+	cmdVolSnapshotCreateUse   = "create [VOLUME NAME] [SNAPSHOT NAME]"
+	cmdVolSnapshotCreateShort = "Create a read-only snapshot of the volume"
+)
+
+func newVolSnapshotCreateCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolSnapshotCreateUse,
+		Short: cmdVolSnapshotCreateShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			var snapshot *proto.SnapshotInfo
+			if snapshot, err = client.AdminAPI().CreateSnapshot(volumeName, calcAuthKey(svv.Owner), args[1]); err != nil {
+				return
+			}
+			stdout("Create snapshot [%v] of volume [%v] success, mount it with option snapshot=%v.\n",
+				snapshot.Name, volumeName, snapshot.Name)
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
+
+const (
+	cmdVolSnapshotDeleteUse   = "delete [VOLUME NAME] [SNAPSHOT NAME]"
+	cmdVolSnapshotDeleteShort = "Delete a snapshot of the volume and release the data only referenced by it"
+)
+
+func newVolSnapshotDeleteCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolSnapshotDeleteUse,
+		Short: cmdVolSnapshotDeleteShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			if err = client.AdminAPI().DeleteSnapshot(volumeName, calcAuthKey(svv.Owner), args[1]); err != nil {
+				return
+			}
+			stdout("Delete snapshot [%v] of volume [%v] success.\n", args[1], volumeName)
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
+
+const (
+	cmdVolSnapshotListUse   = "list [VOLUME NAME]"
+	cmdVolSnapshotListShort = "List snapshots of the volume"
+)
+
+func newVolSnapshotListCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     cmdVolSnapshotListUse,
+		Short:   cmdVolSnapshotListShort,
+		Aliases: []string{"ls"},
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var snapshots []*proto.SnapshotInfo
+			if snapshots, err = client.AdminAPI().ListSnapshot(args[0]); err != nil {
+				return
+			}
+			stdout("%v\n", snapshotTableHeader)
+			for _, snapshot := range snapshots {
+				stdout("%v\n", formatSnapshotTableRow(snapshot))
+			}
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolColdZoneUse   = "coldzone [VOLUME NAME] [ZONE NAME]"
+	cmdVolColdZoneShort = "Set the zone of the cold data partitions of a volume, an empty zone disables the cold storage class"
+)
+
+func newVolColdZoneCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolColdZoneUse,
+		Short: cmdVolColdZoneShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName, zoneName = args[0], args[1]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			if err = client.AdminAPI().SetVolumeColdZone(volumeName, calcAuthKey(svv.Owner), zoneName); err != nil {
+				return
+			}
+			stdout("Set cold zone of volume [%v] to [%v] success.\n", volumeName, formatColdZone(zoneName))
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
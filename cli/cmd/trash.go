@@ -0,0 +1,223 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/chubaofs/chubaofs/sdk/meta"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdVolTrashUse   = "trash [COMMAND]"
+	cmdVolTrashShort = "Manage the trash of a volume"
+)
+
+func newVolTrashCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolTrashUse,
+		Short: cmdVolTrashShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newVolTrashSetCmd(client),
+		newVolTrashListCmd(client),
+		newVolTrashRestoreCmd(client),
+		newVolTrashPurgeCmd(client),
+	)
+	return cmd
+}
+
+func validVolsArgs(client *master.MasterClient) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func newTrashMetaWrapper(client *master.MasterClient, volumeName string) (*meta.MetaWrapper, error) {
+	return meta.NewMetaWrapper(&meta.MetaConfig{Volume: volumeName, Masters: client.Nodes()})
+}
+
+const (
+	cmdVolTrashSetUse   = "set [VOLUME NAME] [DAYS]"
+	cmdVolTrashSetShort = "Set the retention of the trash, 0 disables the trash"
+)
+
+func newVolTrashSetCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolTrashSetUse,
+		Short: cmdVolTrashSetShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var days uint64
+			if days, err = strconv.ParseUint(args[1], 10, 32); err != nil {
+				return
+			}
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+				return
+			}
+			if err = client.AdminAPI().SetVolumeTrashDays(volumeName, calcAuthKey(svv.Owner), uint32(days)); err != nil {
+				return
+			}
+			stdout("Set trash days of volume [%v] to [%v] success.\n", volumeName, days)
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
+
+const (
+	cmdVolTrashListUse   = "list [VOLUME NAME]"
+	cmdVolTrashListShort = "List the deleted files and directories in the trash"
+)
+
+func newVolTrashListCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     cmdVolTrashListUse,
+		Short:   cmdVolTrashListShort,
+		Aliases: []string{"ls"},
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var mw *meta.MetaWrapper
+			if mw, err = newTrashMetaWrapper(client, args[0]); err != nil {
+				return
+			}
+			defer func() {
+				_ = mw.Close()
+			}()
+			var entries []*meta.TrashEntry
+			if entries, err = mw.ListTrash_ll(); err != nil {
+				return
+			}
+			stdout("%v\n", trashTableHeader)
+			for _, entry := range entries {
+				stdout("%v\n", formatTrashTableRow(entry))
+			}
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
+
+const (
+	cmdVolTrashRestoreUse   = "restore [VOLUME NAME] [BUCKET] [NAME]"
+	cmdVolTrashRestoreShort = "Restore a deleted file or directory to where it is deleted from"
+)
+
+func newVolTrashRestoreCmd(client *master.MasterClient) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   cmdVolTrashRestoreUse,
+		Short: cmdVolTrashRestoreShort,
+		Args:  cobra.MinimumNArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var mw *meta.MetaWrapper
+			if mw, err = newTrashMetaWrapper(client, args[0]); err != nil {
+				return
+			}
+			defer func() {
+				_ = mw.Close()
+			}()
+			if err = mw.RestoreTrash_ll(args[1], args[2]); err != nil {
+				err = fmt.Errorf("Restore [%v/%v] failed: %v\n", args[1], args[2], err)
+				return
+			}
+			stdout("Restore [%v/%v] success.\n", args[1], args[2])
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	return cmd
+}
+
+const (
+	cmdVolTrashPurgeUse   = "purge [VOLUME NAME]"
+	cmdVolTrashPurgeShort = "Delete the expired files and directories in the trash permanently"
+)
+
+func newVolTrashPurgeCmd(client *master.MasterClient) *cobra.Command {
+	var optAll bool
+	var optYes bool
+	var cmd = &cobra.Command{
+		Use:   cmdVolTrashPurgeUse,
+		Short: cmdVolTrashPurgeShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var volumeName = args[0]
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			var mw *meta.MetaWrapper
+			if mw, err = newTrashMetaWrapper(client, volumeName); err != nil {
+				return
+			}
+			defer func() {
+				_ = mw.Close()
+			}()
+			var before = time.Now().AddDate(0, 0, -int(mw.TrashDays()))
+			if optAll {
+				// ask user for confirm
+				if !optYes {
+					stdout("All the files and directories in the trash of volume [%v] will be deleted permanently.\n", volumeName)
+					stdout("\nConfirm (yes/no)[no]: ")
+					var userConfirm string
+					_, _ = fmt.Scanln(&userConfirm)
+					if userConfirm != "yes" {
+						err = fmt.Errorf("Abort by user.\n")
+						return
+					}
+				}
+				before = time.Now().Add(time.Hour)
+			}
+			if err = mw.PurgeTrash_ll(before); err != nil {
+				return
+			}
+			stdout("Purge trash of volume [%v] success.\n", volumeName)
+		},
+		ValidArgsFunction: validVolsArgs(client),
+	}
+	cmd.Flags().BoolVar(&optAll, "all", false, "Purge all the files and directories in the trash, even if they are not expired")
+	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
+	return cmd
+}
@@ -15,7 +15,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/chubaofs/chubaofs/proto"
@@ -39,6 +41,8 @@ func newUserCmd(client *master.MasterClient) *cobra.Command {
 		newUserInfoCmd(client),
 		newUserListCmd(client),
 		newUserPermCmd(client),
+		newUserPolicyCmd(client),
+		newUserS3QoSCmd(client),
 		newUserUpdateCmd(client),
 		newUserDeleteCmd(client),
 	)
@@ -387,6 +391,114 @@ func newUserPermCmd(client *master.MasterClient) *cobra.Command {
 	return cmd
 }
 
+const (
+	cmdUserPolicyUse   = "policy [USER ID] [POLICY FILE]"
+	cmdUserPolicyShort = "Setup identity policy for a user"
+)
+
+func newUserPolicyCmd(client *master.MasterClient) *cobra.Command {
+	var optRemove bool
+	var cmd = &cobra.Command{
+		Use:   cmdUserPolicyUse,
+		Short: cmdUserPolicyShort,
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var userID = args[0]
+			var userInfo *proto.UserInfo
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			if optRemove {
+				if userInfo, err = client.UserAPI().RemoveIdentityPolicy(userID); err != nil {
+					return
+				}
+				printUserInfo(userInfo)
+				return
+			}
+			if len(args) < 2 {
+				err = fmt.Errorf("Policy file not specified ")
+				return
+			}
+			var data []byte
+			if data, err = ioutil.ReadFile(args[1]); err != nil {
+				return
+			}
+			var policy *proto.IAMPolicy
+			if policy, err = proto.ParseIAMPolicy(data); err != nil {
+				err = fmt.Errorf("Invalid policy: %v ", err)
+				return
+			}
+			var param = &proto.UserIdentityPolicyParam{UserID: userID, Policy: policy}
+			if userInfo, err = client.UserAPI().UpdateIdentityPolicy(param); err != nil {
+				return
+			}
+			printUserInfo(userInfo)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			return validUsers(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().BoolVar(&optRemove, "remove", false, "Remove identity policy of the user")
+	return cmd
+}
+
+const (
+	cmdUserS3QoSUse   = "s3qos [USER ID]"
+	cmdUserS3QoSShort = "Limit the requests of a user served by the ObjectNodes"
+)
+
+func newUserS3QoSCmd(client *master.MasterClient) *cobra.Command {
+	var optRequestRate, optBandwidth uint64
+	var cmd = &cobra.Command{
+		Use:   cmdUserS3QoSUse,
+		Short: cmdUserS3QoSShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			var userID = args[0]
+			var userInfo *proto.UserInfo
+			defer func() {
+				if err != nil {
+					errout("Error: %v", err)
+				}
+			}()
+			if userInfo, err = client.UserAPI().GetUserInfo(userID); err != nil {
+				return
+			}
+			var param = &proto.UserS3QoSParam{
+				UserID:        userID,
+				S3RequestRate: userInfo.S3RequestRate,
+				S3Bandwidth:   userInfo.S3Bandwidth,
+			}
+			if cmd.Flags().Changed(CliFlagS3RequestRate) {
+				param.S3RequestRate = optRequestRate
+			}
+			if cmd.Flags().Changed(CliFlagS3Bandwidth) {
+				param.S3Bandwidth = optBandwidth
+			}
+			if userInfo, err = client.UserAPI().UpdateS3QoS(param); err != nil {
+				return
+			}
+			printUserInfo(userInfo)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			return validUsers(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().Uint64Var(&optRequestRate, CliFlagS3RequestRate, 0, "Specify requests per second, 0 means unlimited")
+	cmd.Flags().Uint64Var(&optBandwidth, CliFlagS3Bandwidth, 0, "Specify bytes per second, 0 means unlimited")
+	return cmd
+}
+
 const (
 	cmdUserListShort = "List cluster users"
 )
@@ -425,6 +537,7 @@ func printUserInfo(userInfo *proto.UserInfo) {
 	stdout("  Secret Key : %v\n", userInfo.SecretKey)
 	stdout("  Type       : %v\n", userInfo.UserType)
 	stdout("  Create Time: %v\n", userInfo.CreateTime)
+	stdout("  S3 QoS     : %v\n", formatS3QoS(userInfo.S3RequestRate, userInfo.S3Bandwidth))
 	if userInfo.Policy == nil {
 		return
 	}
@@ -436,4 +549,11 @@ func printUserInfo(userInfo *proto.UserInfo) {
 	for vol, perms := range userInfo.Policy.AuthorizedVols {
 		stdout("%-20v    %-12v\n", vol, strings.Join(perms, ","))
 	}
+	if userInfo.Policy.IdentityPolicy == nil {
+		return
+	}
+	stdout("[Identity Policy]\n")
+	if data, err := json.MarshalIndent(userInfo.Policy.IdentityPolicy, "", "  "); err == nil {
+		stdout("%v\n", string(data))
+	}
 }
@@ -49,6 +49,12 @@ func newVolCmd(client *master.MasterClient) *cobra.Command {
 		newVolDeleteCmd(client),
 		newVolTransferCmd(client),
 		newVolAddDPCmd(client),
+		newVolTrashCmd(client),
+		newVolSnapshotCmd(client),
+		newVolErasureCodeCmd(client),
+		newVolCompressionCmd(client),
+		newVolS3QoSCmd(client),
+		newVolColdZoneCmd(client),
 	)
 	return cmd
 }
@@ -103,6 +109,7 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 	var optFollowerRead bool
 	var optYes bool
 	var optZoneName string
+	var optEncryption bool
 	var cmd = &cobra.Command{
 		Use:   cmdVolCreateUse,
 		Short: cmdVolCreateShort,
@@ -127,6 +134,7 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 				stdout("  Replicas            : %v\n", optReplicas)
 				stdout("  Allow follower read : %v\n", formatEnabledDisabled(optFollowerRead))
 				stdout("  ZoneName            : %v\n", optZoneName)
+				stdout("  Encryption          : %v\n", formatEnabledDisabled(optEncryption))
 				stdout("\nConfirm (yes/no)[yes]: ")
 				var userConfirm string
 				_, _ = fmt.Scanln(&userConfirm)
@@ -138,7 +146,7 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 
 			err = client.AdminAPI().CreateVolume(
 				volumeName, userID, optMPCount, optDPSize,
-				optCapacity, optReplicas, optFollowerRead, optZoneName)
+				optCapacity, optReplicas, optFollowerRead, optZoneName, optEncryption)
 			if err != nil {
 				err = fmt.Errorf("Create volume failed case:\n%v\n", err)
 				return
@@ -153,6 +161,7 @@ func newVolCreateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().IntVar(&optReplicas, CliFlagReplicas, cmdVolDefaultReplicas, "Specify data partition replicas number")
 	cmd.Flags().BoolVar(&optFollowerRead, CliFlagEnableFollowerRead, cmdVolDefaultFollowerReader, "Enable read form replica follower")
 	cmd.Flags().StringVar(&optZoneName, CliFlagZoneName, cmdVolDefaultZoneName, "Specify volume zone name")
+	cmd.Flags().BoolVar(&optEncryption, CliFlagEncryption, false, "Encrypt the data of the volume at rest")
 	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
 	return cmd
 }
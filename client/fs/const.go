@@ -66,6 +66,14 @@ func ParseError(err error) fuse.Errno {
 	}
 }
 
+// ParseWriteError returns EDQUOT if the write is rejected by a quota, and EIO otherwise.
+func ParseWriteError(err error) fuse.Errno {
+	if err == syscall.EDQUOT {
+		return fuse.Errno(syscall.EDQUOT)
+	}
+	return fuse.EIO
+}
+
 // ParseType returns the dentry type.
 func ParseType(t uint32) fuse.DirentType {
 	if proto.IsDir(t) {
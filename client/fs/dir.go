@@ -236,34 +236,36 @@ func (d *Dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 	metric := exporter.NewTPCnt("readdir")
 	defer metric.Set(err)
 
-	children, err := d.super.mw.ReadDir_ll(d.info.Inode)
-	if err != nil {
-		log.LogErrorf("Readdir: ino(%v) err(%v)", d.info.Inode, err)
-		return make([]fuse.Dirent, 0), ParseError(err)
-	}
-
-	inodes := make([]uint64, 0, len(children))
-	dirents := make([]fuse.Dirent, 0, len(children))
+	dirents := make([]fuse.Dirent, 0)
 
 	var dcache *DentryCache
 	if !d.super.disableDcache {
 		dcache = NewDentryCache()
 	}
 
-	for _, child := range children {
-		dentry := fuse.Dirent{
-			Inode: child.Inode,
-			Type:  ParseType(child.Type),
-			Name:  child.Name,
+	// Read the directory page by page, so that inodes of a large directory are fetched in batches.
+	err = d.super.mw.ReadDirPages_ll(d.info.Inode, "", func(children []proto.Dentry) bool {
+		inodes := make([]uint64, 0, len(children))
+		for _, child := range children {
+			dentry := fuse.Dirent{
+				Inode: child.Inode,
+				Type:  ParseType(child.Type),
+				Name:  child.Name,
+			}
+			inodes = append(inodes, child.Inode)
+			dirents = append(dirents, dentry)
+			dcache.Put(child.Name, child.Inode)
 		}
-		inodes = append(inodes, child.Inode)
-		dirents = append(dirents, dentry)
-		dcache.Put(child.Name, child.Inode)
-	}
 
-	infos := d.super.mw.BatchInodeGet(inodes)
-	for _, info := range infos {
-		d.super.ic.Put(info)
+		infos := d.super.mw.BatchInodeGet(inodes)
+		for _, info := range infos {
+			d.super.ic.Put(info)
+		}
+		return true
+	})
+	if err != nil {
+		log.LogErrorf("Readdir: ino(%v) err(%v)", d.info.Inode, err)
+		return make([]fuse.Dirent, 0), ParseError(err)
 	}
 	d.dcache = dcache
 
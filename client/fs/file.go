@@ -234,7 +234,7 @@ func (f *File) Write(ctx context.Context, req *fuse.WriteRequest, resp *fuse.Wri
 	if err != nil {
 		msg := fmt.Sprintf("Write: ino(%v) offset(%v) len(%v) err(%v)", ino, req.Offset, reqlen, err)
 		f.super.handleError("Write", msg)
-		return fuse.EIO
+		return ParseWriteError(err)
 	}
 
 	resp.Size = size
@@ -246,7 +246,7 @@ func (f *File) Write(ctx context.Context, req *fuse.WriteRequest, resp *fuse.Wri
 		if err = f.super.ec.Flush(ino); err != nil {
 			msg := fmt.Sprintf("Write: failed to wait for flush, ino(%v) offset(%v) len(%v) err(%v) req(%v)", ino, req.Offset, reqlen, err, req)
 			f.super.handleError("Wrtie", msg)
-			return fuse.EIO
+			return ParseWriteError(err)
 		}
 	}
 
@@ -271,7 +271,7 @@ func (f *File) Flush(ctx context.Context, req *fuse.FlushRequest) (err error) {
 	if err != nil {
 		msg := fmt.Sprintf("Flush: ino(%v) err(%v)", f.info.Inode, err)
 		f.super.handleError("Flush", msg)
-		return fuse.EIO
+		return ParseWriteError(err)
 	}
 	f.super.ic.Delete(f.info.Inode)
 	elapsed := time.Since(start)
@@ -287,7 +287,7 @@ func (f *File) Fsync(ctx context.Context, req *fuse.FsyncRequest) (err error) {
 	if err != nil {
 		msg := fmt.Sprintf("Fsync: ino(%v) err(%v)", f.info.Inode, err)
 		f.super.handleError("Fsync", msg)
-		return fuse.EIO
+		return ParseWriteError(err)
 	}
 	f.super.ic.Delete(f.info.Inode)
 	elapsed := time.Since(start)
@@ -0,0 +1,116 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	masterSDK "github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	ECMigrateInterval = time.Hour
+
+	ecMigrateLeaseTTL           = 5 * time.Minute
+	ecMigrateLeaseCheckInterval = time.Minute
+)
+
+type migrateDir struct {
+	ino      uint64
+	coldDays uint32
+}
+
+// migrateColdFiles periodically walks the directory tree of the volume and migrates the regular files which are not
+// modified for the cold days into erasure coded data partitions. The cold days of the volume can be overridden
+// by the extended attribute of a directory, which applies to the whole subtree. Only the mount holding the task
+// lease of the volume granted by the master migrates the files, and it walks the whole volume.
+func (s *Super) migrateColdFiles(masters []string) {
+	ticker := time.NewTicker(ecMigrateLeaseCheckInterval)
+	defer ticker.Stop()
+
+	var (
+		lease       = masterSDK.NewTaskLeaseHolder(masterSDK.NewMasterClient(masters, false), "ecmigrate."+s.volname, ecMigrateLeaseTTL)
+		lastMigrate time.Time
+	)
+	for range ticker.C {
+		if !lease.Hold() || time.Since(lastMigrate) < ECMigrateInterval {
+			continue
+		}
+		lastMigrate = time.Now()
+		s.migrateColdTree(lease.Hold)
+	}
+}
+
+// migrateColdTree walks the volume and stops once the lease is lost.
+func (s *Super) migrateColdTree(hold func() bool) {
+	var migrated, failed int
+	dirs := []migrateDir{{ino: proto.RootIno, coldDays: s.ec.ErasureCodeColdDays()}}
+	for len(dirs) > 0 && hold() {
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+		coldDays := s.dirColdDays(dir)
+
+		err := s.mw.ReadDirPages_ll(dir.ino, "", func(children []proto.Dentry) bool {
+			if !hold() {
+				return false
+			}
+			for _, child := range children {
+				if proto.IsDir(child.Type) {
+					dirs = append(dirs, migrateDir{ino: child.Inode, coldDays: coldDays})
+					continue
+				}
+				if coldDays == 0 || !proto.IsRegular(child.Type) {
+					continue
+				}
+				info, err := s.mw.InodeGet_ll(child.Inode)
+				if err != nil || time.Since(info.ModifyTime) < time.Duration(coldDays)*24*time.Hour {
+					continue
+				}
+				if err = s.ec.MigrateToErasureCoded(child.Inode); err != nil {
+					log.LogWarnf("migrateColdTree: ino(%v) name(%v) err(%v)", child.Inode, child.Name, err)
+					failed++
+					continue
+				}
+				migrated++
+			}
+			return true
+		})
+		if err != nil {
+			log.LogWarnf("migrateColdTree: read dir(%v) err(%v)", dir.ino, err)
+		}
+	}
+	log.LogInfof("migrateColdTree: vol(%v) migrated(%v) failed(%v)", s.volname, migrated, failed)
+}
+
+// dirColdDays returns the cold days of the directory, which is inherited from the parent if not set.
+func (s *Super) dirColdDays(dir migrateDir) uint32 {
+	info, err := s.mw.XAttrGet_ll(dir.ino, proto.ECColdDaysXAttrKey)
+	if err != nil {
+		return dir.coldDays
+	}
+	value := string(info.Get(proto.ECColdDaysXAttrKey))
+	if value == "" {
+		return dir.coldDays
+	}
+	days, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		log.LogWarnf("dirColdDays: invalid xattr, ino(%v) value(%v)", dir.ino, value)
+		return dir.coldDays
+	}
+	return uint32(days)
+}
@@ -73,6 +73,8 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		Authenticate:  opt.Authenticate,
 		TicketMess:    opt.TicketMess,
 		ValidateOwner: opt.Authenticate || opt.AccessKey == "",
+		EnableTrash:   opt.Snapshot == "",
+		Snapshot:      opt.Snapshot,
 	}
 	s.mw, err = meta.NewMetaWrapper(metaConfig)
 	if err != nil {
@@ -104,16 +106,19 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 	s.enableXattr = opt.EnableXattr
 
 	var extentConfig = &stream.ExtentConfig{
-		Volume:            opt.Volname,
-		Masters:           masters,
-		FollowerRead:      opt.FollowerRead,
-		NearRead:          opt.NearRead,
-		ReadRate:          opt.ReadRate,
-		WriteRate:         opt.WriteRate,
-		OnAppendExtentKey: s.mw.AppendExtentKey,
-		OnGetExtents:      s.mw.GetExtents,
-		OnTruncate:        s.mw.Truncate,
-		OnEvictIcache:     s.ic.Delete,
+		Volume:             opt.Volname,
+		Masters:            masters,
+		FollowerRead:       opt.FollowerRead,
+		NearRead:           opt.NearRead,
+		ReadRate:           opt.ReadRate,
+		WriteRate:          opt.WriteRate,
+		OnAppendExtentKey:  s.mw.AppendExtentKey,
+		OnGetExtents:       s.mw.GetExtentsCOW,
+		OnTruncate:         s.mw.Truncate,
+		OnEvictIcache:      s.ic.Delete,
+		OnPrepareMigration: s.mw.PrepareMigration,
+		OnReplaceExtents:   s.mw.ReplaceExtents,
+		OnGetEncryptionKey: s.mw.GetEncryptionKey,
 	}
 	s.ec, err = stream.NewExtentClient(extentConfig)
 	if err != nil {
@@ -124,6 +129,10 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		return nil, err
 	}
 
+	if opt.ECMigrate && !opt.Rdonly {
+		go s.migrateColdFiles(masters)
+	}
+
 	log.LogInfof("NewSuper: cluster(%v) volname(%v) icacheExpiration(%v) LookupValidDuration(%v) AttrValidDuration(%v)", s.cluster, s.volname, inodeExpiration, LookupValidDuration, AttrValidDuration)
 	return s, nil
 }
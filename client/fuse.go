@@ -350,6 +350,12 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 	opt.EnableXattr = GlobalMountOptions[proto.EnableXattr].GetBool()
 	opt.NearRead = GlobalMountOptions[proto.NearRead].GetBool()
 	opt.EnablePosixACL = GlobalMountOptions[proto.EnablePosixACL].GetBool()
+	opt.Snapshot = GlobalMountOptions[proto.Snapshot].GetString()
+	opt.ECMigrate = GlobalMountOptions[proto.ECMigrate].GetBool()
+	if opt.Snapshot != "" {
+		// the volume snapshot is read-only
+		opt.Rdonly = true
+	}
 
 	if opt.MountPoint == "" || opt.Volname == "" || opt.Owner == "" || opt.Master == "" {
 		return nil, errors.New(fmt.Sprintf("invalid config file: lack of mandatory fields, mountPoint(%v), volName(%v), owner(%v), masterAddr(%v)", opt.MountPoint, opt.Volname, opt.Owner, opt.Master))
@@ -0,0 +1,206 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"sort"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/repl"
+	"github.com/chubaofs/chubaofs/storage"
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/erasure"
+	"github.com/chubaofs/chubaofs/util/errors"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+func (dp *DataPartition) isErasureCoded() bool {
+	return dp.config.ECDataNum > 0
+}
+
+// Handle OpRepairErasureCodedShard packet.
+func (s *DataNode) handlePacketToRepairErasureCodedShard(p *repl.Packet) {
+	task := &proto.AdminTask{}
+	if err := json.Unmarshal(p.Data, task); err != nil {
+		p.PackErrorBody(ActionRepairErasureCodedShard, err.Error())
+		return
+	}
+	p.PacketOkReply()
+	go s.asyncRepairErasureCodedShard(task)
+}
+
+func (s *DataNode) asyncRepairErasureCodedShard(task *proto.AdminTask) {
+	var (
+		err      error
+		repaired int
+	)
+	request := &proto.RepairErasureCodedShardRequest{}
+	response := &proto.RepairErasureCodedShardResponse{}
+	bytes, _ := json.Marshal(task.Request)
+	if err = json.Unmarshal(bytes, request); err == nil {
+		if dp := s.space.Partition(request.PartitionID); dp == nil {
+			err = proto.ErrDataPartitionNotExists
+		} else {
+			repaired, err = dp.repairErasureCodedShard(request, s.localServerAddr)
+		}
+	}
+	response.PartitionID = request.PartitionID
+	response.RepairedExtent = repaired
+	if err != nil {
+		response.Status = proto.TaskFailed
+		response.Result = err.Error()
+	} else {
+		response.Status = proto.TaskSucceeds
+	}
+	task.Response = response
+	if err = MasterClient.NodeAPI().ResponseDataNodeTask(task); err != nil {
+		err = errors.Trace(err, "repair erasure coded shard failed,PartitionID(%v)", request.PartitionID)
+		log.LogError(errors.Stack(err))
+	}
+}
+
+// Rebuild the normal extents missing or incomplete in the local shard from the shards on the other hosts.
+// The index of the local shard is the position of the local address in the hosts.
+func (dp *DataPartition) repairErasureCodedShard(request *proto.RepairErasureCodedShardRequest, localAddr string) (repaired int, err error) {
+	var encoder *erasure.Encoder
+	if encoder, err = erasure.NewEncoder(int(request.DataNum), int(request.ParityNum)); err != nil {
+		return
+	}
+	if len(request.Hosts) != int(request.DataNum+request.ParityNum) {
+		return 0, fmt.Errorf("hosts %v do not match %v+%v shards", request.Hosts, request.DataNum, request.ParityNum)
+	}
+	index := -1
+	for i, host := range request.Hosts {
+		if host == localAddr {
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, fmt.Errorf("local address %v is not in hosts %v", localAddr, request.Hosts)
+	}
+
+	// the sizes of each extent on the other shards
+	remoteSizes := make(map[uint64][]uint64)
+	for i, host := range request.Hosts {
+		if i == index {
+			continue
+		}
+		var extents []*storage.ExtentInfo
+		if extents, err = dp.getRemoteExtentInfo(proto.NormalExtentType, nil, host); err != nil {
+			log.LogWarnf("action[repairErasureCodedShard] partition(%v) get extents from %v err(%v)", dp.partitionID, host, err)
+			continue
+		}
+		for _, extent := range extents {
+			remoteSizes[extent.FileID] = append(remoteSizes[extent.FileID], extent.Size)
+		}
+	}
+	err = nil
+
+	store := dp.ExtentStore()
+	for extentID, sizes := range remoteSizes {
+		// only the extent existing on enough shards can be rebuilt,
+		// which prevents the extent deleted when the local shard is down from being created again
+		if len(sizes) < int(request.DataNum) || store.IsDeletedNormalExtent(extentID) {
+			continue
+		}
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+		size := sizes[request.DataNum-1]
+		var localSize uint64
+		if store.HasExtent(extentID) {
+			var info *storage.ExtentInfo
+			if info, err = store.Watermark(extentID); err != nil {
+				return
+			}
+			localSize = info.Size
+		} else if err = store.Create(extentID); err != nil {
+			return
+		}
+		if localSize >= size {
+			continue
+		}
+		if err = dp.rebuildErasureCodedExtent(encoder, request.Hosts, index, extentID, localSize, size); err != nil {
+			return
+		}
+		log.LogInfof("action[repairErasureCodedShard] partition(%v) shard(%v) extent(%v) rebuilt from %v to %v",
+			dp.partitionID, index, extentID, localSize, size)
+		repaired++
+	}
+	return
+}
+
+func (dp *DataPartition) rebuildErasureCodedExtent(encoder *erasure.Encoder, hosts []string, index int, extentID, offset, size uint64) (err error) {
+	store := dp.ExtentStore()
+	for offset < size {
+		length := util.Min(util.BlockSize, int(size-offset))
+		shards := make([][]byte, len(hosts))
+		available := 0
+		for i, host := range hosts {
+			if i == index || available == encoder.DataNum() {
+				continue
+			}
+			if shards[i], err = dp.readErasureCodedShard(host, extentID, offset, length); err != nil {
+				log.LogWarnf("action[rebuildErasureCodedExtent] partition(%v) extent(%v) read from %v err(%v)",
+					dp.partitionID, extentID, host, err)
+				shards[i] = nil
+				continue
+			}
+			available++
+		}
+		if err = encoder.Reconstruct(shards); err != nil {
+			return errors.Trace(err, "rebuild extent(%v) at offset(%v)", extentID, offset)
+		}
+		data := shards[index]
+		crc := crc32.ChecksumIEEE(data)
+		if err = store.Write(extentID, int64(offset), int64(length), data, crc, storage.AppendWriteType, false); err != nil {
+			dp.checkIsDiskError(err)
+			return
+		}
+		offset += uint64(length)
+	}
+	return
+}
+
+func (dp *DataPartition) readErasureCodedShard(host string, extentID, offset uint64, size int) (data []byte, err error) {
+	request := repl.NewExtentRepairReadPacket(dp.partitionID, extentID, int(offset), size)
+	// read without the limit of the repair read, the shard is read by the client in the same way
+	request.Opcode = proto.OpStreamFollowerRead
+	var conn *net.TCPConn
+	if conn, err = gConnPool.GetConnect(host); err != nil {
+		return
+	}
+	defer gConnPool.PutConnect(conn, true)
+	if err = request.WriteToConn(conn); err != nil {
+		return
+	}
+	data = make([]byte, 0, size)
+	for len(data) < size {
+		reply := repl.NewPacket()
+		if err = reply.ReadFromConn(conn, proto.ReadDeadlineTime); err != nil {
+			return
+		}
+		if reply.ResultCode != proto.OpOk {
+			return nil, fmt.Errorf("result(%v) %v", reply.GetResultMsg(), string(reply.Data[:reply.Size]))
+		}
+		if crc32.ChecksumIEEE(reply.Data[:reply.Size]) != reply.CRC {
+			return nil, storage.CrcMismatchError
+		}
+		data = append(data, reply.Data[:reply.Size]...)
+	}
+	return
+}
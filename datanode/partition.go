@@ -60,6 +60,8 @@ type DataPartitionMetadata struct {
 	Hosts                   []string
 	DataPartitionCreateType int
 	LastTruncateID          uint64
+	ECDataNum               uint8
+	ECParityNum             uint8
 }
 
 type sortedPeers []proto.Peer
@@ -201,6 +203,8 @@ func LoadDataPartition(partitionDir string, disk *Disk) (dp *DataPartition, err
 		RaftStore:     disk.space.GetRaftStore(),
 		NodeID:        disk.space.GetNodeID(),
 		ClusterID:     disk.space.GetClusterID(),
+		ECDataNum:     meta.ECDataNum,
+		ECParityNum:   meta.ECParityNum,
 	}
 	if dp, err = newDataPartition(dpCfg, disk); err != nil {
 		return
@@ -430,6 +434,8 @@ func (dp *DataPartition) PersistMetadata() (err error) {
 		DataPartitionCreateType: dp.DataPartitionCreateType,
 		CreateTime:              time.Now().Format(TimeLayout),
 		LastTruncateID:          dp.lastTruncateID,
+		ECDataNum:               dp.config.ECDataNum,
+		ECParityNum:             dp.config.ECParityNum,
 	}
 	if metaData, err = json.Marshal(md); err != nil {
 		return
@@ -453,6 +459,10 @@ func (dp *DataPartition) statusUpdateScheduler() {
 			if index >= math.MaxUint32 {
 				index = 0
 			}
+			// the shards of an erasure coded partition are repaired by the tasks from the master
+			if dp.isErasureCoded() {
+				continue
+			}
 			if index%2 == 0 {
 				dp.LaunchRepair(proto.TinyExtentType)
 			} else {
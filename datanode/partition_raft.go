@@ -45,6 +45,8 @@ type dataPartitionCfg struct {
 	Hosts         []string            `json:"hosts"`
 	NodeID        uint64              `json:"-"`
 	RaftStore     raftstore.RaftStore `json:"-"`
+	ECDataNum     uint8               `json:"ec_data_num"`
+	ECParityNum   uint8               `json:"ec_parity_num"`
 }
 
 func (dp *DataPartition) raftPort() (heartbeat, replica int, err error) {
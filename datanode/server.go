@@ -45,6 +45,7 @@ var (
 	ErrNoSpaceToCreatePartition    = errors.New("No disk space to create a data partition")
 	ErrNewSpaceManagerFailed       = errors.New("Creater new space manager failed")
 	ErrGetMasterDatanodeInfoFailed = errors.New("Failed to get datanode info from master")
+	ErrRandomWriteErasureCoded     = errors.New("Erasure coded data partition cannot be overwritten in place")
 
 	LocalIP, serverPort string
 	gConnPool           = util.NewConnectPool()
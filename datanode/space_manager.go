@@ -25,6 +25,7 @@ import (
 	"github.com/chubaofs/chubaofs/proto"
 	"github.com/chubaofs/chubaofs/raftstore"
 	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/compress"
 	"github.com/chubaofs/chubaofs/util/log"
 )
 
@@ -137,6 +138,22 @@ func (manager *SpaceManager) RangePartitions(f func(partition *DataPartition) bo
 	}
 }
 
+// SetCompressions sets the codec of the extent stores by the compression of the volumes delivered by heartbeat.
+func (manager *SpaceManager) SetCompressions(compressions map[string]string) {
+	manager.RangePartitions(func(dp *DataPartition) bool {
+		codec, err := compress.ParseCodec(compressions[dp.volumeID])
+		if err != nil {
+			log.LogWarnf("action[SetCompressions] partition(%v) err(%v)", dp.partitionID, err)
+		}
+		if store := dp.ExtentStore(); store.Compression() != codec {
+			log.LogInfof("action[SetCompressions] partition(%v) compression from(%v) to(%v)",
+				dp.partitionID, store.Compression(), codec)
+			store.SetCompression(codec)
+		}
+		return true
+	})
+}
+
 func (manager *SpaceManager) GetDisks() (disks []*Disk) {
 	manager.diskMutex.RLock()
 	defer manager.diskMutex.RUnlock()
@@ -295,6 +312,8 @@ func (manager *SpaceManager) CreatePartition(request *proto.CreateDataPartitionR
 		NodeID:        manager.nodeID,
 		ClusterID:     manager.clusterID,
 		PartitionSize: request.PartitionSize,
+		ECDataNum:     request.ECDataNum,
+		ECParityNum:   request.ECParityNum,
 	}
 	dp = manager.partitions[dpCfg.PartitionID]
 	if dp != nil {
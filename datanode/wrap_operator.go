@@ -114,6 +114,8 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c *net.TCPConn) (err error) {
 		s.handlePacketToReadTinyDeleteRecordFile(p, c)
 	case proto.OpBroadcastMinAppliedID:
 		s.handleBroadcastMinAppliedID(p)
+	case proto.OpRepairErasureCodedShard:
+		s.handlePacketToRepairErasureCodedShard(p)
 	default:
 		p.PackErrorBody(repl.ErrorUnknownOp.Error(), repl.ErrorUnknownOp.Error()+strconv.Itoa(int(p.Opcode)))
 	}
@@ -211,6 +213,7 @@ func (s *DataNode) handleHeartbeatPacket(p *repl.Packet) {
 		if task.OpCode == proto.OpDataNodeHeartbeat {
 			marshaled, _ := json.Marshal(task.Request)
 			_ = json.Unmarshal(marshaled, request)
+			s.space.SetCompressions(request.Compressions)
 			response.Status = proto.TaskSucceeds
 		} else {
 			response.Status = proto.TaskFailed
@@ -432,6 +435,10 @@ func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 		}
 	}()
 	partition := p.Object.(*DataPartition)
+	if partition.isErasureCoded() {
+		err = ErrRandomWriteErasureCoded
+		return
+	}
 	_, isLeader := partition.IsRaftLeader()
 	if !isLeader {
 		err = raft.ErrNotLeader
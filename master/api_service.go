@@ -28,6 +28,7 @@ import (
 
 	"github.com/chubaofs/chubaofs/proto"
 	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/compress"
 	"github.com/chubaofs/chubaofs/util/cryptoutil"
 	"github.com/chubaofs/chubaofs/util/errors"
 	"github.com/chubaofs/chubaofs/util/log"
@@ -582,6 +583,14 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 		description    string
 		dpSelectorName string
 		dpSelectorParm string
+		trashDays      uint32
+		ecDataNum      uint8
+		ecParityNum    uint8
+		ecColdDays     uint32
+		compression    string
+		s3RequestRate  uint64
+		s3Bandwidth    uint64
+		coldZoneName   string
 		vol            *Vol
 	)
 
@@ -609,6 +618,28 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if trashDays, err = parseTrashDaysToUpdateVol(r, vol); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if ecDataNum, ecParityNum, ecColdDays, err = parseErasureCodeToUpdateVol(r, vol); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if compression, err = parseCompressionToUpdateVol(r, vol); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if s3RequestRate, s3Bandwidth, err = parseS3QoSToUpdateVol(r, vol); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	coldZoneName = parseColdZoneNameToUpdateVol(r, vol)
+
 	newArgs := getVolVarargs(vol)
 
 	newArgs.zoneName = zoneName
@@ -619,6 +650,14 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 	newArgs.enableToken = enableToken
 	newArgs.dpSelectorName = dpSelectorName
 	newArgs.dpSelectorParm = dpSelectorParm
+	newArgs.trashDays = trashDays
+	newArgs.ecDataNum = ecDataNum
+	newArgs.ecParityNum = ecParityNum
+	newArgs.ecColdDays = ecColdDays
+	newArgs.compression = compression
+	newArgs.s3RequestRate = s3RequestRate
+	newArgs.s3Bandwidth = s3Bandwidth
+	newArgs.coldZoneName = coldZoneName
 
 	if err = m.cluster.updateVol(name, authKey, newArgs); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
@@ -696,6 +735,121 @@ func (m *Server) volShrink(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) setQuota(w http.ResponseWriter, r *http.Request) {
+	var (
+		name     string
+		authKey  string
+		path     string
+		inode    uint64
+		maxFiles uint64
+		maxBytes uint64
+		quota    *proto.QuotaInfo
+		err      error
+	)
+	if name, authKey, path, inode, maxFiles, maxBytes, err = parseRequestToSetQuota(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if quota, err = m.cluster.setQuota(name, authKey, path, inode, maxFiles, maxBytes); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(quota))
+}
+
+func (m *Server) deleteQuota(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    string
+		authKey string
+		quotaID uint32
+		msg     string
+		err     error
+	)
+	if name, authKey, quotaID, err = parseRequestToDeleteQuota(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.deleteQuota(name, authKey, quotaID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg = fmt.Sprintf("delete quota[%v] of vol[%v] successfully\n", quotaID, name)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
+func (m *Server) listQuota(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		vol  *Vol
+		err  error
+	)
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(vol.quotaList()))
+}
+
+func (m *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
+	var (
+		name         string
+		authKey      string
+		snapshotName string
+		snapshot     *proto.SnapshotInfo
+		err          error
+	)
+	if name, authKey, snapshotName, err = parseRequestToSnapshot(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if snapshot, err = m.cluster.createSnapshot(name, authKey, snapshotName); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(snapshot))
+}
+
+func (m *Server) deleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	var (
+		name         string
+		authKey      string
+		snapshotName string
+		msg          string
+		err          error
+	)
+	if name, authKey, snapshotName, err = parseRequestToSnapshot(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.deleteSnapshot(name, authKey, snapshotName); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg = fmt.Sprintf("delete snapshot[%v] of vol[%v] successfully\n", snapshotName, name)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
+func (m *Server) listSnapshot(w http.ResponseWriter, r *http.Request) {
+	var (
+		name string
+		vol  *Vol
+		err  error
+	)
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if vol, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(vol.snapshotList()))
+}
+
 func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 	var (
 		name         string
@@ -713,12 +867,22 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 		enableToken  bool
 		zoneName     string
 		description  string
+		encryption   bool
 	)
 
 	if name, owner, zoneName, description, mpCount, dpReplicaNum, size, capacity, followerRead, authenticate, crossZone, enableToken, err = parseRequestToCreateVol(r); err != nil {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
 		return
 	}
+	if encryption, err = extractEncryption(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if encryption && len(m.cluster.MasterSecretKey) == 0 {
+		err = fmt.Errorf("master service key is not configured, encrypted volume is unsupported")
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
 	if !(dpReplicaNum == 2 || dpReplicaNum == 3) {
 		err = fmt.Errorf("replicaNum can only be 2 and 3,received replicaNum is[%v]", dpReplicaNum)
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
@@ -729,6 +893,13 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if encryption {
+		if err = m.cluster.enableVolEncryption(vol); err != nil {
+			sendErrReply(w, r, newErrHTTPReply(err))
+			return
+		}
+	}
+
 	if err = m.associateVolWithUser(owner, name); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
@@ -737,6 +908,78 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+// The data key of an encrypted volume is only served to the clients holding a ticket issued by the authnode,
+// which grants the access to the volume, and the reply is encrypted by the session key of the ticket.
+func (m *Server) getVolEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    string
+		key     []byte
+		body    []byte
+		message string
+		jobj    proto.APIAccessReq
+		ticket  cryptoutil.Ticket
+		ts      int64
+		err     error
+	)
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if jobj, ticket, ts, err = parseAndCheckTicket(r, m.cluster.MasterSecretKey, name); err != nil {
+		if err == proto.ErrExpiredTicket {
+			sendErrReply(w, r, newErrHTTPReply(err))
+			return
+		}
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeInvalidTicket, Msg: err.Error()})
+		return
+	}
+	if jobj.Type != proto.MsgMasterGetVolEncryptionKeyReq {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeInvalidTicket, Msg: fmt.Sprintf("unexpected request type [%v]", jobj.Type)})
+		return
+	}
+	if key, err = m.cluster.getVolEncryptionKey(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	if body, err = json.Marshal(newSuccessHTTPReply(key)); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeMasterAPIGenRespError, Msg: err.Error()})
+		return
+	}
+	if message, err = genRespMessage(body, &jobj, ts, ticket.SessionKey.Key); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeMasterAPIGenRespError, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(message))
+}
+
+func (m *Server) acquireTaskLease(w http.ResponseWriter, r *http.Request) {
+	var (
+		name  string
+		owner string
+		ttl   int64
+		err   error
+	)
+	if err = r.ParseForm(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if name = r.FormValue(nameKey); name == "" {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: keyNotFound(nameKey).Error()})
+		return
+	}
+	if owner = r.FormValue(taskOwnerKey); owner == "" {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: keyNotFound(taskOwnerKey).Error()})
+		return
+	}
+	if value := r.FormValue(taskLeaseTTLKey); value != "" {
+		if ttl, err = strconv.ParseInt(value, 10, 64); err != nil {
+			sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: unmatchedKey(taskLeaseTTLKey).Error()})
+			return
+		}
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.taskLeases.acquire(name, owner, ttl)))
+}
+
 func (m *Server) getVolSimpleInfo(w http.ResponseWriter, r *http.Request) {
 	var (
 		err     error
@@ -791,6 +1034,15 @@ func newSimpleView(vol *Vol) *proto.SimpleVolView {
 		Description:        vol.description,
 		DpSelectorName:     vol.dpSelectorName,
 		DpSelectorParm:     vol.dpSelectorParm,
+		TrashDays:          vol.trashDays,
+		ECDataNum:          vol.ecDataNum,
+		ECParityNum:        vol.ecParityNum,
+		ECColdDays:         vol.ecColdDays,
+		Compression:        vol.compression,
+		Encrypted:          len(vol.encryptionKey) > 0,
+		S3RequestRate:      vol.s3RequestRate,
+		S3Bandwidth:        vol.s3Bandwidth,
+		ColdZoneName:       vol.coldZoneName,
 	}
 }
 
@@ -1461,6 +1713,97 @@ func parseBoolFieldToUpdateVol(r *http.Request, vol *Vol) (followerRead, authent
 	return
 }
 
+func parseTrashDaysToUpdateVol(r *http.Request, vol *Vol) (trashDays uint32, err error) {
+	if trashDaysStr := r.FormValue(trashDaysKey); trashDaysStr != "" {
+		var value uint64
+		if value, err = strconv.ParseUint(trashDaysStr, 10, 32); err != nil {
+			err = unmatchedKey(trashDaysKey)
+			return
+		}
+		trashDays = uint32(value)
+	} else {
+		trashDays = vol.trashDays
+	}
+	return
+}
+
+// parseS3QoSToUpdateVol parses the limits of the requests to the volume served by the ObjectNodes,
+// 0 means unlimited.
+func parseS3QoSToUpdateVol(r *http.Request, vol *Vol) (requestRate, bandwidth uint64, err error) {
+	requestRate, bandwidth = vol.s3RequestRate, vol.s3Bandwidth
+	if value := r.FormValue(s3RequestRateKey); value != "" {
+		if requestRate, err = strconv.ParseUint(value, 10, 64); err != nil {
+			err = unmatchedKey(s3RequestRateKey)
+			return
+		}
+	}
+	if value := r.FormValue(s3BandwidthKey); value != "" {
+		if bandwidth, err = strconv.ParseUint(value, 10, 64); err != nil {
+			err = unmatchedKey(s3BandwidthKey)
+			return
+		}
+	}
+	return
+}
+
+// parseColdZoneNameToUpdateVol parses the zone of the cold data partitions, an empty value disables
+// the cold storage class of the volume.
+func parseColdZoneNameToUpdateVol(r *http.Request, vol *Vol) (coldZoneName string) {
+	if values, ok := r.Form[coldZoneNameKey]; ok {
+		return values[0]
+	}
+	return vol.coldZoneName
+}
+
+func parseCompressionToUpdateVol(r *http.Request, vol *Vol) (compression string, err error) {
+	name := r.FormValue(compressionKey)
+	if name == "" {
+		return vol.compression, nil
+	}
+	var codec compress.Codec
+	if codec, err = compress.ParseCodec(name); err != nil {
+		return
+	}
+	if codec != compress.None {
+		compression = codec.String()
+	}
+	return
+}
+
+func parseErasureCodeToUpdateVol(r *http.Request, vol *Vol) (dataNum, parityNum uint8, coldDays uint32, err error) {
+	var parse = func(key string, bitSize int, defaultValue uint64) (value uint64, err error) {
+		str := r.FormValue(key)
+		if str == "" {
+			return defaultValue, nil
+		}
+		if value, err = strconv.ParseUint(str, 10, bitSize); err != nil {
+			err = unmatchedKey(key)
+		}
+		return
+	}
+	var value uint64
+	if value, err = parse(ecDataNumKey, 8, uint64(vol.ecDataNum)); err != nil {
+		return
+	}
+	dataNum = uint8(value)
+	if value, err = parse(ecParityNumKey, 8, uint64(vol.ecParityNum)); err != nil {
+		return
+	}
+	parityNum = uint8(value)
+	if value, err = parse(ecColdDaysKey, 32, uint64(vol.ecColdDays)); err != nil {
+		return
+	}
+	coldDays = uint32(value)
+	if dataNum == 0 && parityNum == 0 {
+		return
+	}
+	if dataNum == 0 || parityNum == 0 || int(dataNum)+int(parityNum) > proto.MaxECShardNum {
+		err = fmt.Errorf("ecDataNum and ecParityNum must be both positive and their sum can not be larger than %v,"+
+			"received ecDataNum[%v] ecParityNum[%v]", proto.MaxECShardNum, dataNum, parityNum)
+	}
+	return
+}
+
 func parseRequestToSetVolCapacity(r *http.Request) (name, authKey string, capacity int, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -1477,6 +1820,88 @@ func parseRequestToSetVolCapacity(r *http.Request) (name, authKey string, capaci
 	return
 }
 
+func parseRequestToSetQuota(r *http.Request) (name, authKey, path string, inode, maxFiles, maxBytes uint64, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if name, err = extractName(r); err != nil {
+		return
+	}
+	if authKey, err = extractAuthKey(r); err != nil {
+		return
+	}
+	if inode, err = extractUint64(r, inodeKey); err != nil {
+		return
+	}
+	path = r.FormValue(pathKey)
+	if value := r.FormValue(maxFilesKey); value != "" {
+		if maxFiles, err = strconv.ParseUint(value, 10, 64); err != nil {
+			err = unmatchedKey(maxFilesKey)
+			return
+		}
+	}
+	if value := r.FormValue(maxBytesKey); value != "" {
+		if maxBytes, err = strconv.ParseUint(value, 10, 64); err != nil {
+			err = unmatchedKey(maxBytesKey)
+			return
+		}
+	}
+	return
+}
+
+func parseRequestToDeleteQuota(r *http.Request) (name, authKey string, quotaID uint32, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if name, err = extractName(r); err != nil {
+		return
+	}
+	if authKey, err = extractAuthKey(r); err != nil {
+		return
+	}
+	var value string
+	if value = r.FormValue(idKey); value == "" {
+		err = keyNotFound(idKey)
+		return
+	}
+	var id uint64
+	if id, err = strconv.ParseUint(value, 10, 32); err != nil {
+		err = unmatchedKey(idKey)
+		return
+	}
+	quotaID = uint32(id)
+	return
+}
+
+func parseRequestToSnapshot(r *http.Request) (name, authKey, snapshotName string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if name, err = extractName(r); err != nil {
+		return
+	}
+	if authKey, err = extractAuthKey(r); err != nil {
+		return
+	}
+	if snapshotName = r.FormValue(snapshotKey); snapshotName == "" {
+		err = keyNotFound(snapshotKey)
+		return
+	}
+	return
+}
+
+func extractUint64(r *http.Request, key string) (value uint64, err error) {
+	var str string
+	if str = r.FormValue(key); str == "" {
+		err = keyNotFound(key)
+		return
+	}
+	if value, err = strconv.ParseUint(str, 10, 64); err != nil {
+		err = unmatchedKey(key)
+	}
+	return
+}
+
 func parseRequestToCreateVol(r *http.Request) (name, owner, zoneName, description string, mpCount, dpReplicaNum, size, capacity int, followerRead, authenticate, crossZone, enableToken bool, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -1713,6 +2138,19 @@ func extractAuthenticate(r *http.Request) (authenticate bool, err error) {
 	return
 }
 
+func extractEncryption(r *http.Request) (encryption bool, err error) {
+	var value string
+	if value = r.FormValue(encryptionKey); value == "" {
+		encryption = false
+		return
+	}
+	if encryption, err = strconv.ParseBool(value); err != nil {
+		err = unmatchedKey(encryptionKey)
+		return
+	}
+	return
+}
+
 func extractCrossZone(r *http.Request) (crossZone bool, err error) {
 	var value string
 	if value = r.FormValue(crossZoneKey); value == "" {
@@ -346,6 +346,64 @@ func TestUpdateVol(t *testing.T) {
 
 }
 
+func TestUpdateVolS3QoS(t *testing.T) {
+	reqURL := fmt.Sprintf("%v%v?name=%v&authKey=%v&s3RequestRate=100&s3Bandwidth=1048576",
+		hostAddr, proto.AdminUpdateVol, commonVol.Name, buildAuthKey("cfs"))
+	process(reqURL, t)
+	vol, err := server.cluster.getVol(commonVolName)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if vol.s3RequestRate != 100 || vol.s3Bandwidth != 1048576 {
+		t.Errorf("expect s3 qos is 100/1048576, but is %v/%v", vol.s3RequestRate, vol.s3Bandwidth)
+		return
+	}
+	// the limits are kept if they are not specified
+	reqURL = fmt.Sprintf("%v%v?name=%v&authKey=%v&s3RequestRate=0",
+		hostAddr, proto.AdminUpdateVol, commonVol.Name, buildAuthKey("cfs"))
+	process(reqURL, t)
+	if vol.s3RequestRate != 0 || vol.s3Bandwidth != 1048576 {
+		t.Errorf("expect s3 qos is 0/1048576, but is %v/%v", vol.s3RequestRate, vol.s3Bandwidth)
+		return
+	}
+}
+
+func TestUpdateVolColdZone(t *testing.T) {
+	reqURL := fmt.Sprintf("%v%v?name=%v&authKey=%v&coldZoneName=%v",
+		hostAddr, proto.AdminUpdateVol, commonVol.Name, buildAuthKey("cfs"), testZone2)
+	process(reqURL, t)
+	vol, err := server.cluster.getVol(commonVolName)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if vol.coldZoneName != testZone2 {
+		t.Errorf("expect cold zone is %v, but is %v", testZone2, vol.coldZoneName)
+		return
+	}
+	vol.autoCreateColdDataPartitions(server.cluster)
+	if count := vol.coldRWCount(); count < minNumOfRWColdDataPartitions {
+		t.Errorf("expect at least %v cold partitions, but is %v", minNumOfRWColdDataPartitions, count)
+		return
+	}
+	// the zone must exist
+	newArgs := getVolVarargs(vol)
+	newArgs.coldZoneName = "nonexistent"
+	if err = server.cluster.updateVol(commonVolName, buildAuthKey("cfs"), newArgs); err == nil {
+		t.Errorf("expect updating to a nonexistent cold zone fails")
+		return
+	}
+	// an empty zone disables the cold storage class
+	reqURL = fmt.Sprintf("%v%v?name=%v&authKey=%v&coldZoneName=",
+		hostAddr, proto.AdminUpdateVol, commonVol.Name, buildAuthKey("cfs"))
+	process(reqURL, t)
+	if vol.coldZoneName != "" {
+		t.Errorf("expect cold zone is empty, but is %v", vol.coldZoneName)
+		return
+	}
+}
+
 func setVolCapacity(capacity uint64, url string, t *testing.T) {
 	reqURL := fmt.Sprintf("%v%v?name=%v&capacity=%v&authKey=%v",
 		hostAddr, url, commonVol.Name, capacity, buildAuthKey("cfs"))
@@ -757,6 +815,118 @@ func TestRemovePolicy(t *testing.T) {
 	}
 }
 
+func TestUpdateIdentityPolicy(t *testing.T) {
+	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.UserUpdateIdentityPolicy)
+	policy, err := proto.ParseIAMPolicy([]byte(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::` + commonVolName + `/prefix/*"},
+			{"Effect": "Deny", "Action": "s3:*", "Resource": "*", "Condition": {"NotIpAddress": {"aws:SourceIp": "10.0.0.0/8"}}}
+		]
+	}`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	param := &proto.UserIdentityPolicyParam{UserID: testUserID, Policy: policy}
+	data, err := json.Marshal(param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fmt.Println(reqURL)
+	post(reqURL, data, t)
+	userInfo, err := server.user.getUserInfo(testUserID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if userInfo.Policy.IdentityPolicy == nil || len(userInfo.Policy.IdentityPolicy.Statements) != 2 {
+		t.Errorf("expect identity policy with 2 statements, but is %v", userInfo.Policy.IdentityPolicy)
+		return
+	}
+}
+
+func TestSimulatePolicy(t *testing.T) {
+	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.UserSimulatePolicy)
+	cases := []struct {
+		key      string
+		sourceIP string
+		decision string
+	}{
+		{"prefix/a.txt", "10.1.1.1", proto.IAMDecisionAllow.String()},
+		{"other/a.txt", "10.1.1.1", proto.IAMDecisionNotApplicable.String()},
+		{"prefix/a.txt", "192.168.1.1", proto.IAMDecisionDeny.String()},
+	}
+	for _, c := range cases {
+		param := &proto.UserPolicySimulateParam{
+			UserID:  testUserID,
+			Action:  "s3:GetObject",
+			Volume:  commonVolName,
+			Key:     c.key,
+			Context: proto.IAMContext{"aws:SourceIp": {c.sourceIP}},
+		}
+		data, err := json.Marshal(param)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		fmt.Println(reqURL)
+		reply := post(reqURL, data, t)
+		if reply == nil {
+			return
+		}
+		result := &proto.UserPolicySimulateResult{}
+		if data, err = json.Marshal(reply.Data); err != nil {
+			t.Error(err)
+			return
+		}
+		if err = json.Unmarshal(data, result); err != nil {
+			t.Error(err)
+			return
+		}
+		if result.Decision != c.decision {
+			t.Errorf("key %v from %v: expect decision %v, but is %v", c.key, c.sourceIP, c.decision, result.Decision)
+		}
+	}
+}
+
+func TestRemoveIdentityPolicy(t *testing.T) {
+	reqURL := fmt.Sprintf("%v%v?user=%v", hostAddr, proto.UserRemoveIdentityPolicy, testUserID)
+	fmt.Println(reqURL)
+	process(reqURL, t)
+	userInfo, err := server.user.getUserInfo(testUserID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if userInfo.Policy.IdentityPolicy != nil {
+		t.Errorf("expect no identity policy, but is %v", userInfo.Policy.IdentityPolicy)
+		return
+	}
+}
+
+func TestUpdateUserS3QoS(t *testing.T) {
+	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.UserUpdateS3QoS)
+	param := &proto.UserS3QoSParam{UserID: testUserID, S3RequestRate: 50, S3Bandwidth: 1024}
+	data, err := json.Marshal(param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fmt.Println(reqURL)
+	post(reqURL, data, t)
+	userInfo, err := server.user.getUserInfo(testUserID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if userInfo.S3RequestRate != 50 || userInfo.S3Bandwidth != 1024 {
+		t.Errorf("expect s3 qos is 50/1024, but is %v/%v", userInfo.S3RequestRate, userInfo.S3Bandwidth)
+		return
+	}
+}
+
 func TestTransferVol(t *testing.T) {
 	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.UserTransferVol)
 	param := &proto.UserTransferVolParam{Volume: commonVolName, UserSrc: "cfs", UserDst: testUserID, Force: false}
@@ -171,6 +171,97 @@ func (m *Server) removeUserPolicy(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
 }
 
+func (m *Server) updateUserIdentityPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		userInfo *proto.UserInfo
+		bytes    []byte
+		err      error
+	)
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	var param = proto.UserIdentityPolicyParam{}
+	if err = json.Unmarshal(bytes, &param); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if param.Policy == nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: "identity policy not specified"})
+		return
+	}
+	if err = param.Policy.Validate(); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if userInfo, err = m.user.updateIdentityPolicy(&param); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+}
+
+func (m *Server) removeUserIdentityPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		userID   string
+		userInfo *proto.UserInfo
+		err      error
+	)
+	if userID, err = parseUser(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if userInfo, err = m.user.removeIdentityPolicy(userID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+}
+
+func (m *Server) updateUserS3QoS(w http.ResponseWriter, r *http.Request) {
+	var (
+		userInfo *proto.UserInfo
+		bytes    []byte
+		err      error
+	)
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	var param = proto.UserS3QoSParam{}
+	if err = json.Unmarshal(bytes, &param); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if userInfo, err = m.user.updateS3QoS(&param); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+}
+
+func (m *Server) simulateUserPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		result *proto.UserPolicySimulateResult
+		bytes  []byte
+		err    error
+	)
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	var param = proto.UserPolicySimulateParam{}
+	if err = json.Unmarshal(bytes, &param); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if result, err = m.user.simulatePolicy(&param); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(result))
+}
+
 func (m *Server) deleteUserVolPolicy(w http.ResponseWriter, r *http.Request) {
 	var (
 		vol string
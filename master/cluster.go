@@ -55,6 +55,7 @@ type Cluster struct {
 	MasterSecretKey           []byte
 	lastMasterZoneForDataNode string
 	lastMasterZoneForMetaNode string
+	taskLeases                *taskLeaseManager
 }
 
 func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition raftstore.Partition, cfg *clusterConfig) (c *Cluster) {
@@ -72,6 +73,7 @@ func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition
 	c.fsm = fsm
 	c.partition = partition
 	c.idAlloc = newIDAllocator(c.fsm.store, c.partition)
+	c.taskLeases = newTaskLeaseManager()
 	return
 }
 
@@ -248,22 +250,35 @@ func (c *Cluster) checkLeaderAddr() {
 
 func (c *Cluster) checkDataNodeHeartbeat() {
 	tasks := make([]*proto.AdminTask, 0)
+	compressions := c.volCompressions()
 	c.dataNodes.Range(func(addr, dataNode interface{}) bool {
 		node := dataNode.(*DataNode)
 		node.checkLiveness()
-		task := node.createHeartbeatTask(c.masterAddr())
+		task := node.createHeartbeatTask(c.masterAddr(), compressions)
 		tasks = append(tasks, task)
 		return true
 	})
 	c.addDataNodeTasks(tasks)
 }
 
+// Return the compression codec of each volume, which is delivered to the data nodes by heartbeat.
+func (c *Cluster) volCompressions() (compressions map[string]string) {
+	compressions = make(map[string]string)
+	for name, vol := range c.allVols() {
+		if vol.compression != "" {
+			compressions[name] = vol.compression
+		}
+	}
+	return
+}
+
 func (c *Cluster) checkMetaNodeHeartbeat() {
 	tasks := make([]*proto.AdminTask, 0)
+	exceededQuotas := c.exceededQuotas()
 	c.metaNodes.Range(func(addr, metaNode interface{}) bool {
 		node := metaNode.(*MetaNode)
 		node.checkHeartbeat()
-		task := node.createHeartbeatTask(c.masterAddr())
+		task := node.createHeartbeatTask(c.masterAddr(), exceededQuotas)
 		tasks = append(tasks, task)
 		return true
 	})
@@ -663,9 +678,18 @@ func (c *Cluster) batchCreateDataPartition(vol *Vol, reqCount int) (err error) {
 // - If succeeded, replicate the data through raft and persist it to RocksDB.
 // - Otherwise, throw errors
 func (c *Cluster) createDataPartition(volName string, zoneNum int) (dp *DataPartition, err error) {
+	return c.doCreateDataPartition(volName, zoneNum, 0, 0, proto.StorageClassStandard)
+}
+
+// The partition is erasure coded if ecDataNum is not zero,
+// the shards are placed on ecDataNum+ecParityNum data nodes instead of the replicas.
+// The partition of the cold storage class is placed in the cold zone of the vol.
+func (c *Cluster) doCreateDataPartition(volName string, zoneNum int, ecDataNum, ecParityNum, storageClass uint8) (dp *DataPartition, err error) {
 	var (
 		vol         *Vol
 		partitionID uint64
+		replicaNum  uint8
+		zoneName    string
 		targetHosts []string
 		targetPeers []proto.Peer
 		wg          sync.WaitGroup
@@ -676,14 +700,25 @@ func (c *Cluster) createDataPartition(volName string, zoneNum int) (dp *DataPart
 	}
 	vol.createDpMutex.Lock()
 	defer vol.createDpMutex.Unlock()
-	errChannel := make(chan error, vol.dpReplicaNum)
-	if targetHosts, targetPeers, err = c.chooseTargetDataNodes("", nil, nil, int(vol.dpReplicaNum), zoneNum, vol.zoneName); err != nil {
+	replicaNum = vol.dpReplicaNum
+	if ecDataNum > 0 {
+		replicaNum = ecDataNum + ecParityNum
+	}
+	zoneName = vol.zoneName
+	if storageClass == proto.StorageClassCold {
+		zoneName = vol.coldZoneName
+	}
+	errChannel := make(chan error, replicaNum)
+	if targetHosts, targetPeers, err = c.chooseTargetDataNodes("", nil, nil, int(replicaNum), zoneNum, zoneName); err != nil {
 		goto errHandler
 	}
 	if partitionID, err = c.idAlloc.allocateDataPartitionID(); err != nil {
 		goto errHandler
 	}
-	dp = newDataPartition(partitionID, vol.dpReplicaNum, volName, vol.ID)
+	dp = newDataPartition(partitionID, replicaNum, volName, vol.ID)
+	dp.ECDataNum = ecDataNum
+	dp.ECParityNum = ecParityNum
+	dp.StorageClass = storageClass
 	dp.Hosts = targetHosts
 	dp.Peers = targetPeers
 	for _, host := range targetHosts {
@@ -733,7 +768,8 @@ func (c *Cluster) createDataPartition(volName string, zoneNum int) (dp *DataPart
 		goto errHandler
 	}
 	vol.dataPartitions.put(dp)
-	log.LogInfof("action[createDataPartition] success,volName[%v],partitionId[%v]", volName, partitionID)
+	log.LogInfof("action[createDataPartition] success,volName[%v],partitionId[%v],ecDataNum[%v],ecParityNum[%v]",
+		volName, partitionID, ecDataNum, ecParityNum)
 	return
 errHandler:
 	err = fmt.Errorf("action[createDataPartition],clusterID[%v] vol[%v] Err:%v ", c.Name, volName, err.Error())
@@ -1097,12 +1133,19 @@ func (c *Cluster) decommissionDataPartition(offlineAddr string, dp *DataPartitio
 			}
 		}
 	}
-	if err = c.removeDataReplica(dp, offlineAddr, false); err != nil {
-		goto errHandler
-	}
 	newAddr = targetHosts[0]
-	if err = c.addDataReplica(dp, newAddr); err != nil {
-		goto errHandler
+	if dp.isErasureCoded() {
+		// the shard index is the position of the host, so the shard is moved in place
+		if err = c.replaceErasureCodedShard(dp, offlineAddr, newAddr); err != nil {
+			goto errHandler
+		}
+	} else {
+		if err = c.removeDataReplica(dp, offlineAddr, false); err != nil {
+			goto errHandler
+		}
+		if err = c.addDataReplica(dp, newAddr); err != nil {
+			goto errHandler
+		}
 	}
 	dp.Status = proto.ReadOnly
 	dp.isRecover = true
@@ -1132,7 +1175,11 @@ func (c *Cluster) validateDecommissionDataPartition(dp *DataPartition, offlineAd
 		return
 	}
 
-	if err = dp.hasMissingOneReplica(int(vol.dpReplicaNum)); err != nil {
+	replicaNum := int(vol.dpReplicaNum)
+	if dp.isErasureCoded() {
+		replicaNum = int(dp.ReplicaNum)
+	}
+	if err = dp.hasMissingOneReplica(replicaNum); err != nil {
 		return
 	}
 
@@ -1511,6 +1558,14 @@ func (c *Cluster) updateVol(name, authKey string, newArgs *VolVarargs) (err erro
 		oldDescription    string
 		oldDpSelectorName string
 		oldDpSelectorParm string
+		oldTrashDays      uint32
+		oldECDataNum      uint8
+		oldECParityNum    uint8
+		oldECColdDays     uint32
+		oldCompression    string
+		oldS3RequestRate  uint64
+		oldS3Bandwidth    uint64
+		oldColdZoneName   string
 		volUsedSpace      uint64
 	)
 	if vol, err = c.getVol(name); err != nil {
@@ -1554,6 +1609,11 @@ func (c *Cluster) updateVol(name, authKey string, newArgs *VolVarargs) (err erro
 			goto errHandler
 		}
 	}
+	if newArgs.coldZoneName != "" {
+		if _, err = c.t.getZone(newArgs.coldZoneName); err != nil {
+			goto errHandler
+		}
+	}
 
 	oldCapacity = vol.Capacity
 	oldDpReplicaNum = vol.dpReplicaNum
@@ -1564,6 +1624,14 @@ func (c *Cluster) updateVol(name, authKey string, newArgs *VolVarargs) (err erro
 	oldDescription = vol.description
 	oldDpSelectorName = vol.dpSelectorName
 	oldDpSelectorParm = vol.dpSelectorParm
+	oldTrashDays = vol.trashDays
+	oldECDataNum = vol.ecDataNum
+	oldECParityNum = vol.ecParityNum
+	oldECColdDays = vol.ecColdDays
+	oldCompression = vol.compression
+	oldS3RequestRate = vol.s3RequestRate
+	oldS3Bandwidth = vol.s3Bandwidth
+	oldColdZoneName = vol.coldZoneName
 
 	vol.zoneName = newArgs.zoneName
 	vol.Capacity = newArgs.capacity
@@ -1579,6 +1647,14 @@ func (c *Cluster) updateVol(name, authKey string, newArgs *VolVarargs) (err erro
 	}
 	vol.dpSelectorName = newArgs.dpSelectorName
 	vol.dpSelectorParm = newArgs.dpSelectorParm
+	vol.trashDays = newArgs.trashDays
+	vol.ecDataNum = newArgs.ecDataNum
+	vol.ecParityNum = newArgs.ecParityNum
+	vol.ecColdDays = newArgs.ecColdDays
+	vol.compression = newArgs.compression
+	vol.s3RequestRate = newArgs.s3RequestRate
+	vol.s3Bandwidth = newArgs.s3Bandwidth
+	vol.coldZoneName = newArgs.coldZoneName
 
 	if err = c.syncUpdateVol(vol); err != nil {
 		vol.Capacity = oldCapacity
@@ -1590,6 +1666,14 @@ func (c *Cluster) updateVol(name, authKey string, newArgs *VolVarargs) (err erro
 		vol.description = oldDescription
 		vol.dpSelectorName = oldDpSelectorName
 		vol.dpSelectorParm = oldDpSelectorParm
+		vol.trashDays = oldTrashDays
+		vol.ecDataNum = oldECDataNum
+		vol.ecParityNum = oldECParityNum
+		vol.ecColdDays = oldECColdDays
+		vol.compression = oldCompression
+		vol.s3RequestRate = oldS3RequestRate
+		vol.s3Bandwidth = oldS3Bandwidth
+		vol.coldZoneName = oldColdZoneName
 
 		log.LogErrorf("action[updateVol] vol[%v] err[%v]", name, err)
 		err = proto.ErrPersistenceByRaft
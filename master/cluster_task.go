@@ -767,6 +767,9 @@ func (c *Cluster) handleDataNodeTaskResponse(nodeAddr string, task *proto.AdminT
 	case proto.OpDataNodeHeartbeat:
 		response := task.Response.(*proto.DataNodeHeartbeatResponse)
 		err = c.handleDataNodeHeartbeatResp(task.OperatorAddr, response)
+	case proto.OpRepairErasureCodedShard:
+		response := task.Response.(*proto.RepairErasureCodedShardResponse)
+		err = c.handleResponseToRepairErasureCodedShard(task.OperatorAddr, response)
 	default:
 		err = fmt.Errorf(fmt.Sprintf("unknown operate code %v", task.OpCode))
 		goto errHandler
@@ -55,6 +55,22 @@ const (
 	descriptionKey          = "description"
 	dpSelectorNameKey       = "dpSelectorName"
 	dpSelectorParmKey       = "dpSelectorParm"
+	inodeKey                = "inode"
+	pathKey                 = "path"
+	maxFilesKey             = "maxFiles"
+	maxBytesKey             = "maxBytes"
+	trashDaysKey            = "trashDays"
+	snapshotKey             = "snapshot"
+	ecDataNumKey            = "ecDataNum"
+	ecParityNumKey          = "ecParityNum"
+	ecColdDaysKey           = "ecColdDays"
+	compressionKey          = "compression"
+	encryptionKey           = "encryption"
+	s3RequestRateKey        = "s3RequestRate"
+	s3BandwidthKey          = "s3Bandwidth"
+	coldZoneNameKey         = "coldZoneName"
+	taskOwnerKey            = "owner"
+	taskLeaseTTLKey         = "ttl"
 )
 
 const (
@@ -85,6 +101,8 @@ const (
 	spaceAvailableRate                           = 0.90
 	defaultNodeSetCapacity                       = 18
 	minNumOfRWDataPartitions                     = 10
+	minNumOfRWErasureCodedDataPartitions         = 2
+	minNumOfRWColdDataPartitions                 = 2
 	intervalToCheckMissingReplica                = 600
 	intervalToWarnDataPartition                  = 600
 	intervalToLoadDataPartition                  = 12 * 60 * 60
@@ -50,6 +50,9 @@ type DataPartition struct {
 	OfflinePeerID           uint64
 	FileInCoreMap           map[string]*FileInCore
 	FilesWithMissingReplica map[string]int64 // key: file name, value: last time when a missing replica is found
+	ECDataNum               uint8            // the number of data shards if the partition is erasure coded
+	ECParityNum             uint8
+	StorageClass            uint8 // the cold partitions are placed in the cold zone of the vol
 }
 
 func newDataPartition(ID uint64, replicaNum uint8, volName string, volID uint64) (partition *DataPartition) {
@@ -123,8 +126,11 @@ func (partition *DataPartition) createTaskToRemoveRaftMember(removePeer proto.Pe
 
 func (partition *DataPartition) createTaskToCreateDataPartition(addr string, dataPartitionSize uint64, peers []proto.Peer, hosts []string, createType int) (task *proto.AdminTask) {
 
-	task = proto.NewAdminTask(proto.OpCreateDataPartition, addr, newCreateDataPartitionRequest(
-		partition.VolName, partition.PartitionID, peers, int(dataPartitionSize), hosts, createType))
+	request := newCreateDataPartitionRequest(
+		partition.VolName, partition.PartitionID, peers, int(dataPartitionSize), hosts, createType)
+	request.ECDataNum = partition.ECDataNum
+	request.ECParityNum = partition.ECParityNum
+	task = proto.NewAdminTask(proto.OpCreateDataPartition, addr, request)
 	partition.resetTaskID(task)
 	return
 }
@@ -164,7 +170,12 @@ func (partition *DataPartition) canBeOffLine(offlineAddr string) (err error) {
 		}
 	}
 
-	if len(otherLiveReplicas) < int(partition.ReplicaNum/2) {
+	minLiveReplicas := int(partition.ReplicaNum / 2)
+	if partition.isErasureCoded() {
+		// the new shard is rebuilt from the data shard number of the other shards
+		minLiveReplicas = int(partition.ECDataNum)
+	}
+	if len(otherLiveReplicas) < minLiveReplicas {
 		msg = fmt.Sprintf(msg+" err:%v  liveReplicas:%v ", proto.ErrCannotBeOffLine, len(liveReplicas))
 		log.LogError(msg)
 		err = fmt.Errorf(msg)
@@ -269,6 +280,9 @@ func (partition *DataPartition) convertToDataPartitionResponse() (dpr *proto.Dat
 	copy(dpr.Hosts, partition.Hosts)
 	dpr.LeaderAddr = partition.getLeaderAddr()
 	dpr.IsRecover = partition.isRecover
+	dpr.ECDataNum = partition.ECDataNum
+	dpr.ECParityNum = partition.ECParityNum
+	dpr.StorageClass = partition.StorageClass
 	return
 }
 
@@ -383,7 +397,8 @@ func (partition *DataPartition) checkReplicaNum(c *Cluster, vol *Vol) {
 		Warn(c.Name, msg)
 	}
 
-	if vol.dpReplicaNum != partition.ReplicaNum && !vol.NeedToLowerReplica {
+	// the shard number of the erasure coded partition is not related to the replica number of the vol
+	if vol.dpReplicaNum != partition.ReplicaNum && !vol.NeedToLowerReplica && !partition.isErasureCoded() {
 		vol.NeedToLowerReplica = true
 	}
 }
@@ -611,7 +626,7 @@ func (partition *DataPartition) getToBeDecommissionHost(replicaNum int) (host st
 	partition.RLock()
 	defer partition.RUnlock()
 	hostLen := len(partition.Hosts)
-	if hostLen <= 1 || hostLen <= replicaNum {
+	if hostLen <= 1 || hostLen <= replicaNum || partition.isErasureCoded() {
 		return
 	}
 	host = partition.Hosts[hostLen-1]
@@ -681,5 +696,8 @@ func (partition *DataPartition) ToProto(c *Cluster) *proto.DataPartitionInfo {
 		FileInCoreMap:           fileInCoreMap,
 		OfflinePeerID:           partition.OfflinePeerID,
 		FilesWithMissingReplica: partition.FilesWithMissingReplica,
+		ECDataNum:               partition.ECDataNum,
+		ECParityNum:             partition.ECParityNum,
+		StorageClass:            partition.StorageClass,
 	}
 }
@@ -192,6 +192,9 @@ func (partition *DataPartition) checkReplicationTask(clusterID string, dataParti
 		partition.removeReplicaByAddr(excessAddr)
 		partition.Unlock()
 	}
+	if partition.isErasureCoded() {
+		tasks = append(tasks, partition.checkErasureCodedShards()...)
+	}
 	if partition.Status == proto.ReadWrite {
 		return
 	}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// The size of the data key of an encrypted volume, which selects AES-256.
+const volEncryptionKeySize = 32
+
+// The data key of an encrypted volume is generated once when the volume is created, and is persisted
+// wrapped by the master service key which is issued by the keystore of the authnode. The plain data key
+// only leaves the master in the reply encrypted by the session key of an authnode ticket.
+func (c *Cluster) keyEncryptionCipher() (aead cipher.AEAD, err error) {
+	if len(c.MasterSecretKey) == 0 {
+		return nil, fmt.Errorf("master service key is not configured, encrypted volume is unsupported")
+	}
+	var (
+		kek   = sha256.Sum256(c.MasterSecretKey)
+		block cipher.Block
+	)
+	if block, err = aes.NewCipher(kek[:]); err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
+
+func (c *Cluster) wrapVolEncryptionKey(key []byte) (wrapped []byte, err error) {
+	var aead cipher.AEAD
+	if aead, err = c.keyEncryptionCipher(); err != nil {
+		return
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	wrapped = aead.Seal(nonce, nonce, key, nil)
+	return
+}
+
+func (c *Cluster) unwrapVolEncryptionKey(wrapped []byte) (key []byte, err error) {
+	var aead cipher.AEAD
+	if aead, err = c.keyEncryptionCipher(); err != nil {
+		return
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped encryption key is too short")
+	}
+	nonce := wrapped[:aead.NonceSize()]
+	return aead.Open(nil, nonce, wrapped[aead.NonceSize():], nil)
+}
+
+// Generate the data key of a newly created volume. Encryption can not be enabled after data is written,
+// and can never be disabled.
+func (c *Cluster) enableVolEncryption(vol *Vol) (err error) {
+	key := make([]byte, volEncryptionKeySize)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		return
+	}
+	var wrapped []byte
+	if wrapped, err = c.wrapVolEncryptionKey(key); err != nil {
+		return
+	}
+	vol.Lock()
+	defer vol.Unlock()
+	vol.encryptionKey = wrapped
+	if err = c.syncUpdateVol(vol); err != nil {
+		vol.encryptionKey = nil
+		log.LogErrorf("action[enableVolEncryption] vol[%v] err[%v]", vol.Name, err)
+		return proto.ErrPersistenceByRaft
+	}
+	log.LogInfof("action[enableVolEncryption] vol[%v] encryption enabled", vol.Name)
+	return
+}
+
+// Return the plain data key of the volume, the caller is authorized by the ticket of the authnode.
+func (c *Cluster) getVolEncryptionKey(name string) (key []byte, err error) {
+	var vol *Vol
+	if vol, err = c.getVol(name); err != nil {
+		log.LogErrorf("action[getVolEncryptionKey] err[%v]", err)
+		return nil, proto.ErrVolNotExists
+	}
+	vol.RLock()
+	defer vol.RUnlock()
+	if len(vol.encryptionKey) == 0 {
+		return nil, fmt.Errorf("vol[%v] is not encrypted", name)
+	}
+	return c.unwrapVolEncryptionKey(vol.encryptionKey)
+}
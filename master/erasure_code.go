@@ -0,0 +1,161 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+func (partition *DataPartition) isErasureCoded() bool {
+	return partition.ECDataNum > 0
+}
+
+func (vol *Vol) erasureCodedRWCount() (count int) {
+	vol.dataPartitions.RLock()
+	defer vol.dataPartitions.RUnlock()
+	for _, dp := range vol.dataPartitions.partitionMap {
+		if dp.isErasureCoded() && dp.Status == proto.ReadWrite {
+			count++
+		}
+	}
+	return
+}
+
+// Keep a few writable erasure coded partitions for the migration of the cold files
+// if erasure coding is enabled on the vol.
+func (vol *Vol) autoCreateErasureCodedDataPartitions(c *Cluster) {
+	if vol.ecDataNum == 0 {
+		return
+	}
+	for count := vol.erasureCodedRWCount(); count < minNumOfRWErasureCodedDataPartitions; count++ {
+		if _, err := c.doCreateDataPartition(vol.Name, c.decideZoneNum(vol.crossZone), vol.ecDataNum, vol.ecParityNum, proto.StorageClassStandard); err != nil {
+			log.LogErrorf("action[autoCreateErasureCodedDataPartitions] vol[%v] err[%v]", vol.Name, err)
+			return
+		}
+	}
+}
+
+// Find the shards which have less extents or less data than the others, the missing or incomplete extents
+// of them are rebuilt by the data nodes from the other shards. The shards of an extent have the same size,
+// so a shard with a partly written extent has less data even if it has as many extents as the others.
+func (partition *DataPartition) checkErasureCodedShards() (tasks []*proto.AdminTask) {
+	partition.RLock()
+	defer partition.RUnlock()
+	tasks = make([]*proto.AdminTask, 0)
+	if time.Now().Unix()-partition.createTime < 120 {
+		return
+	}
+	liveReplicas := partition.liveReplicas(defaultDataPartitionTimeOutSec)
+	if len(liveReplicas) < int(partition.ECDataNum) {
+		return
+	}
+	var (
+		maxFileCount uint32
+		maxUsed      uint64
+	)
+	for _, replica := range liveReplicas {
+		if replica.FileCount > maxFileCount {
+			maxFileCount = replica.FileCount
+		}
+		if replica.Used > maxUsed {
+			maxUsed = replica.Used
+		}
+	}
+	for _, replica := range liveReplicas {
+		if replica.FileCount < maxFileCount || replica.Used < maxUsed {
+			log.LogWarnf("action[checkErasureCodedShards] partitionID:%v shard on %v has %v extents of %v bytes, the others have %v extents of %v bytes",
+				partition.PartitionID, replica.Addr, replica.FileCount, replica.Used, maxFileCount, maxUsed)
+			tasks = append(tasks, partition.createTaskToRepairErasureCodedShard(replica.Addr))
+		}
+	}
+	return
+}
+
+func (partition *DataPartition) createTaskToRepairErasureCodedShard(addr string) (task *proto.AdminTask) {
+	hosts := make([]string, len(partition.Hosts))
+	copy(hosts, partition.Hosts)
+	task = proto.NewAdminTask(proto.OpRepairErasureCodedShard, addr, &proto.RepairErasureCodedShardRequest{
+		PartitionID: partition.PartitionID,
+		Hosts:       hosts,
+		DataNum:     partition.ECDataNum,
+		ParityNum:   partition.ECParityNum,
+	})
+	partition.resetTaskID(task)
+	return
+}
+
+func (c *Cluster) handleResponseToRepairErasureCodedShard(nodeAddr string, resp *proto.RepairErasureCodedShardResponse) (err error) {
+	if resp.Status != proto.TaskSucceeds {
+		return fmt.Errorf("action[handleResponseToRepairErasureCodedShard] partitionID:%v on %v failed,err[%v]",
+			resp.PartitionID, nodeAddr, resp.Result)
+	}
+	log.LogInfof("action[handleResponseToRepairErasureCodedShard] partitionID:%v on %v repaired %v extents",
+		resp.PartitionID, nodeAddr, resp.RepairedExtent)
+	return
+}
+
+// Replace the shard on the old host by a new shard on the new host at the same position of the hosts,
+// the new shard is empty until it is rebuilt by the repair task.
+func (c *Cluster) replaceErasureCodedShard(dp *DataPartition, oldAddr, newAddr string) (err error) {
+	var (
+		vol      *Vol
+		oldNode  *DataNode
+		newNode  *DataNode
+		diskPath string
+	)
+	if vol, err = c.getVol(dp.VolName); err != nil {
+		return
+	}
+	if oldNode, err = c.dataNode(oldAddr); err != nil {
+		return
+	}
+	if newNode, err = c.dataNode(newAddr); err != nil {
+		return
+	}
+	dp.RLock()
+	hosts := make([]string, len(dp.Hosts))
+	copy(hosts, dp.Hosts)
+	peers := make([]proto.Peer, len(dp.Peers))
+	copy(peers, dp.Peers)
+	dp.RUnlock()
+	for i := range hosts {
+		if hosts[i] == oldAddr {
+			hosts[i] = newAddr
+		}
+	}
+	for i := range peers {
+		if peers[i].Addr == oldAddr {
+			peers[i] = proto.Peer{ID: newNode.ID, Addr: newAddr}
+		}
+	}
+	if diskPath, err = c.syncCreateDataPartitionToDataNode(newAddr, vol.dataPartitionSize, dp, peers, hosts, proto.NormalCreateDataPartition); err != nil {
+		return
+	}
+	dp.Lock()
+	if err = dp.update("replaceErasureCodedShard", dp.VolName, peers, hosts, c); err != nil {
+		dp.Unlock()
+		return
+	}
+	err = dp.afterCreation(newAddr, diskPath, c)
+	dp.Unlock()
+	if err != nil {
+		return
+	}
+	return c.deleteDataReplica(dp, oldNode)
+}
@@ -123,12 +123,36 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminUpdateVol).
 		HandlerFunc(m.updateVol)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminGetVolEncryptionKey).
+		HandlerFunc(m.getVolEncryptionKey)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminAcquireTaskLease).
+		HandlerFunc(m.acquireTaskLease)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolShrink).
 		HandlerFunc(m.volShrink)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolExpand).
 		HandlerFunc(m.volExpand)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSetQuota).
+		HandlerFunc(m.setQuota)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminDeleteQuota).
+		HandlerFunc(m.deleteQuota)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListQuota).
+		HandlerFunc(m.listQuota)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminCreateSnapshot).
+		HandlerFunc(m.createSnapshot)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminDeleteSnapshot).
+		HandlerFunc(m.deleteSnapshot)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListSnapshot).
+		HandlerFunc(m.listSnapshot)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.ClientVol).
 		HandlerFunc(m.getVol)
@@ -264,6 +288,18 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.UserDeleteVolPolicy).
 		HandlerFunc(m.deleteUserVolPolicy)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.UserUpdateIdentityPolicy).
+		HandlerFunc(m.updateUserIdentityPolicy)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.UserRemoveIdentityPolicy).
+		HandlerFunc(m.removeUserIdentityPolicy)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.UserSimulatePolicy).
+		HandlerFunc(m.simulateUserPolicy)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.UserUpdateS3QoS).
+		HandlerFunc(m.updateUserS3QoS)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.UserGetAKInfo).
 		HandlerFunc(m.getUserAKInfo)
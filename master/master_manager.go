@@ -43,6 +43,7 @@ func (m *Server) handleLeaderChange(leader uint64) {
 			m.clusterName, m.leaderInfo.addr))
 		if oldLeaderAddr != m.leaderInfo.addr {
 			m.loadMetadata()
+			m.cluster.taskLeases.reset()
 			m.metaReady = true
 		}
 		m.cluster.checkDataNodeHeartbeat()
@@ -60,6 +60,7 @@ type MetaPartition struct {
 	OfflinePeerID uint64
 	MissNodes     map[string]int64
 	LoadResponse  []*proto.MetaPartitionLoadResponse
+	QuotaUsages   []*proto.QuotaUsage
 	offlineMutex  sync.RWMutex
 	sync.RWMutex
 }
@@ -344,6 +345,9 @@ func (mp *MetaPartition) updateMetaPartition(mgr *proto.MetaPartitionReport, met
 	mp.setMaxInodeID()
 	mp.setInodeCount()
 	mp.setDentryCount()
+	if mgr.IsLeader {
+		mp.QuotaUsages = mgr.QuotaUsages
+	}
 	mp.removeMissingReplica(metaNode.Addr)
 }
 
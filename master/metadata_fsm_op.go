@@ -96,6 +96,9 @@ type dataPartitionValue struct {
 	OfflinePeerID uint64
 	Replicas      []*replicaValue
 	IsRecover     bool
+	ECDataNum     uint8
+	ECParityNum   uint8
+	StorageClass  uint8
 }
 
 type replicaValue struct {
@@ -115,6 +118,9 @@ func newDataPartitionValue(dp *DataPartition) (dpv *dataPartitionValue) {
 		OfflinePeerID: dp.OfflinePeerID,
 		Replicas:      make([]*replicaValue, 0),
 		IsRecover:     dp.isRecover,
+		ECDataNum:     dp.ECDataNum,
+		ECParityNum:   dp.ECParityNum,
+		StorageClass:  dp.StorageClass,
 	}
 	for _, replica := range dp.Replicas {
 		rv := &replicaValue{Addr: replica.Addr, DiskPath: replica.DiskPath}
@@ -143,6 +149,17 @@ type volValue struct {
 	Description       string
 	DpSelectorName    string
 	DpSelectorParm    string
+	Quotas            []*bsProto.QuotaInfo
+	TrashDays         uint32
+	Snapshots         []*bsProto.SnapshotInfo
+	ECDataNum         uint8
+	ECParityNum       uint8
+	ECColdDays        uint32
+	Compression       string
+	EncryptionKey     []byte
+	S3RequestRate     uint64
+	S3Bandwidth       uint64
+	ColdZoneName      string
 }
 
 func (v *volValue) Bytes() (raw []byte, err error) {
@@ -171,6 +188,17 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		Description:       vol.description,
 		DpSelectorName:    vol.dpSelectorName,
 		DpSelectorParm:    vol.dpSelectorParm,
+		Quotas:            vol.quotaList(),
+		TrashDays:         vol.trashDays,
+		Snapshots:         vol.snapshotList(),
+		ECDataNum:         vol.ecDataNum,
+		ECParityNum:       vol.ecParityNum,
+		ECColdDays:        vol.ecColdDays,
+		Compression:       vol.compression,
+		EncryptionKey:     vol.encryptionKey,
+		S3RequestRate:     vol.s3RequestRate,
+		S3Bandwidth:       vol.s3Bandwidth,
+		ColdZoneName:      vol.coldZoneName,
 	}
 	return
 }
@@ -740,6 +768,9 @@ func (c *Cluster) loadDataPartitions() (err error) {
 		dp.Peers = dpv.Peers
 		dp.OfflinePeerID = dpv.OfflinePeerID
 		dp.isRecover = dpv.IsRecover
+		dp.ECDataNum = dpv.ECDataNum
+		dp.ECParityNum = dpv.ECParityNum
+		dp.StorageClass = dpv.StorageClass
 		for _, rv := range dpv.Replicas {
 			if !contains(dp.Hosts, rv.Addr) {
 				continue
@@ -83,6 +83,8 @@ func unmarshalTaskResponse(task *proto.AdminTask) (err error) {
 		response = &proto.DeleteDataPartitionResponse{}
 	case proto.OpLoadDataPartition:
 		response = &proto.LoadDataPartitionResponse{}
+	case proto.OpRepairErasureCodedShard:
+		response = &proto.RepairErasureCodedShardResponse{}
 	case proto.OpDeleteFile:
 		response = &proto.DeleteFileResponse{}
 	case proto.OpMetaNodeHeartbeat:
@@ -0,0 +1,191 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Set the limits of the quota bound to the given directory inode.
+// The quota of the root inode limits the whole volume and always takes the ID RootQuotaID.
+func (c *Cluster) setQuota(name, authKey, path string, inode, maxFiles, maxBytes uint64) (quota *proto.QuotaInfo, err error) {
+	var vol *Vol
+	if vol, err = c.getVol(name); err != nil {
+		log.LogErrorf("action[setQuota] err[%v]", err)
+		err = proto.ErrVolNotExists
+		return
+	}
+	vol.Lock()
+	defer vol.Unlock()
+	if !matchKey(vol.Owner, authKey) {
+		return nil, proto.ErrVolAuthKeyNotMatch
+	}
+
+	vol.quotaLock.Lock()
+	old, ok := vol.findQuota(inode)
+	if ok {
+		quota = &proto.QuotaInfo{}
+		*quota = *old
+	} else {
+		quota = &proto.QuotaInfo{QuotaID: vol.nextQuotaID(inode), RootInode: inode}
+	}
+	quota.Path = path
+	quota.MaxFiles = maxFiles
+	quota.MaxBytes = maxBytes
+	vol.quotas[quota.QuotaID] = quota
+	vol.quotaLock.Unlock()
+
+	if err = c.syncUpdateVol(vol); err != nil {
+		vol.quotaLock.Lock()
+		if ok {
+			vol.quotas[old.QuotaID] = old
+		} else {
+			delete(vol.quotas, quota.QuotaID)
+		}
+		vol.quotaLock.Unlock()
+		log.LogErrorf("action[setQuota] vol[%v] err[%v]", name, err)
+		return nil, proto.ErrPersistenceByRaft
+	}
+	log.LogInfof("action[setQuota] vol[%v] quota[%v] inode[%v] maxFiles[%v] maxBytes[%v]",
+		name, quota.QuotaID, inode, maxFiles, maxBytes)
+	return
+}
+
+func (c *Cluster) deleteQuota(name, authKey string, quotaID uint32) (err error) {
+	var vol *Vol
+	if vol, err = c.getVol(name); err != nil {
+		log.LogErrorf("action[deleteQuota] err[%v]", err)
+		return proto.ErrVolNotExists
+	}
+	vol.Lock()
+	defer vol.Unlock()
+	if !matchKey(vol.Owner, authKey) {
+		return proto.ErrVolAuthKeyNotMatch
+	}
+
+	vol.quotaLock.Lock()
+	old, ok := vol.quotas[quotaID]
+	if !ok {
+		vol.quotaLock.Unlock()
+		return fmt.Errorf("quota[%v] of vol[%v] not found", quotaID, name)
+	}
+	delete(vol.quotas, quotaID)
+	vol.quotaLock.Unlock()
+
+	if err = c.syncUpdateVol(vol); err != nil {
+		vol.quotaLock.Lock()
+		vol.quotas[quotaID] = old
+		vol.quotaLock.Unlock()
+		log.LogErrorf("action[deleteQuota] vol[%v] err[%v]", name, err)
+		return proto.ErrPersistenceByRaft
+	}
+	log.LogInfof("action[deleteQuota] vol[%v] quota[%v]", name, quotaID)
+	return
+}
+
+// Return the IDs of the exceeded quotas of each volume, which are delivered to the meta nodes by heartbeat.
+func (c *Cluster) exceededQuotas() (exceeded map[string][]uint32) {
+	exceeded = make(map[string][]uint32)
+	for name, vol := range c.allVols() {
+		for _, quota := range vol.quotaList() {
+			if quota.Exceeded {
+				exceeded[name] = append(exceeded[name], quota.QuotaID)
+			}
+		}
+	}
+	return
+}
+
+func (vol *Vol) findQuota(inode uint64) (quota *proto.QuotaInfo, ok bool) {
+	for _, quota = range vol.quotas {
+		if quota.RootInode == inode {
+			return quota, true
+		}
+	}
+	return nil, false
+}
+
+func (vol *Vol) nextQuotaID(inode uint64) (quotaID uint32) {
+	if inode == proto.RootIno {
+		return proto.RootQuotaID
+	}
+	for id := range vol.quotas {
+		if id > quotaID {
+			quotaID = id
+		}
+	}
+	return quotaID + 1
+}
+
+func (vol *Vol) quotaList() (quotas []*proto.QuotaInfo) {
+	vol.quotaLock.RLock()
+	defer vol.quotaLock.RUnlock()
+	quotas = make([]*proto.QuotaInfo, 0, len(vol.quotas))
+	for _, quota := range vol.quotas {
+		q := *quota
+		quotas = append(quotas, &q)
+	}
+	return
+}
+
+// Aggregate the quota usages reported by the leaders of the meta partitions.
+// The usage of the volume-wide quota is the inode count of the meta partitions and the used space of the data partitions.
+func (vol *Vol) checkQuotas() {
+	vol.quotaLock.RLock()
+	count := len(vol.quotas)
+	vol.quotaLock.RUnlock()
+	if count == 0 {
+		return
+	}
+
+	usages := make(map[uint32]*proto.QuotaUsage)
+	rootUsage := &proto.QuotaUsage{QuotaID: proto.RootQuotaID, UsedBytes: vol.totalUsedSpace()}
+	vol.mpsLock.RLock()
+	for _, mp := range vol.MetaPartitions {
+		mp.RLock()
+		rootUsage.UsedFiles += mp.InodeCount
+		for _, usage := range mp.QuotaUsages {
+			if usage.QuotaID == proto.RootQuotaID {
+				continue
+			}
+			total, ok := usages[usage.QuotaID]
+			if !ok {
+				total = &proto.QuotaUsage{QuotaID: usage.QuotaID}
+				usages[usage.QuotaID] = total
+			}
+			total.UsedFiles += usage.UsedFiles
+			total.UsedBytes += usage.UsedBytes
+		}
+		mp.RUnlock()
+	}
+	vol.mpsLock.RUnlock()
+	usages[proto.RootQuotaID] = rootUsage
+
+	vol.quotaLock.Lock()
+	defer vol.quotaLock.Unlock()
+	for id, quota := range vol.quotas {
+		usage, ok := usages[id]
+		if !ok {
+			usage = &proto.QuotaUsage{QuotaID: id}
+		}
+		quota.UsedFiles = usage.UsedFiles
+		quota.UsedBytes = usage.UsedBytes
+		quota.Exceeded = (quota.MaxFiles > 0 && quota.UsedFiles >= quota.MaxFiles) ||
+			(quota.MaxBytes > 0 && quota.UsedBytes >= quota.MaxBytes)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Create a read-only snapshot of the volume. Every meta partition freezes its metadata at the apply index
+// of the snapshot request, and keeps the referenced extents from being deleted until the snapshot is deleted.
+// The partitions freeze one after another without a barrier, so the snapshot is consistent within each
+// partition but is not a single point in time of the whole volume.
+func (c *Cluster) createSnapshot(name, authKey, snapshotName string) (snapshot *proto.SnapshotInfo, err error) {
+	var vol *Vol
+	if vol, err = c.getVol(name); err != nil {
+		log.LogErrorf("action[createSnapshot] err[%v]", err)
+		err = proto.ErrVolNotExists
+		return
+	}
+	vol.Lock()
+	defer vol.Unlock()
+	if !matchKey(vol.Owner, authKey) {
+		return nil, proto.ErrVolAuthKeyNotMatch
+	}
+	if _, ok := vol.findSnapshot(snapshotName); ok {
+		return nil, fmt.Errorf("snapshot[%v] of vol[%v] already exists", snapshotName, name)
+	}
+	snapshot = &proto.SnapshotInfo{ID: vol.nextSnapshotID(), Name: snapshotName, CreateTime: time.Now().Unix()}
+
+	created := make([]*MetaPartition, 0)
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		if err = c.syncSendMetaSnapshotTask(mp, proto.OpCreateMetaSnapshot, snapshot.ID); err != nil {
+			log.LogErrorf("action[createSnapshot] vol[%v] snapshot[%v] mp[%v] err[%v]", name, snapshotName, mp.PartitionID, err)
+			c.rollbackMetaSnapshot(created, snapshot.ID)
+			return nil, err
+		}
+		created = append(created, mp)
+	}
+
+	vol.snapshotLock.Lock()
+	vol.snapshots[snapshot.ID] = snapshot
+	vol.snapshotLock.Unlock()
+	if err = c.syncUpdateVol(vol); err != nil {
+		vol.snapshotLock.Lock()
+		delete(vol.snapshots, snapshot.ID)
+		vol.snapshotLock.Unlock()
+		c.rollbackMetaSnapshot(created, snapshot.ID)
+		log.LogErrorf("action[createSnapshot] vol[%v] err[%v]", name, err)
+		return nil, proto.ErrPersistenceByRaft
+	}
+	log.LogInfof("action[createSnapshot] vol[%v] snapshot[%v] id[%v] metaPartitions[%v]",
+		name, snapshotName, snapshot.ID, len(created))
+	return
+}
+
+// Delete the snapshot of the volume. The snapshot is kept if any of the meta partitions fails
+// to delete it, so that the deletion can be retried.
+func (c *Cluster) deleteSnapshot(name, authKey, snapshotName string) (err error) {
+	var vol *Vol
+	if vol, err = c.getVol(name); err != nil {
+		log.LogErrorf("action[deleteSnapshot] err[%v]", err)
+		return proto.ErrVolNotExists
+	}
+	vol.Lock()
+	defer vol.Unlock()
+	if !matchKey(vol.Owner, authKey) {
+		return proto.ErrVolAuthKeyNotMatch
+	}
+	snapshot, ok := vol.findSnapshot(snapshotName)
+	if !ok {
+		return fmt.Errorf("snapshot[%v] of vol[%v] not found", snapshotName, name)
+	}
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		if err = c.syncSendMetaSnapshotTask(mp, proto.OpDeleteMetaSnapshot, snapshot.ID); err != nil {
+			log.LogErrorf("action[deleteSnapshot] vol[%v] snapshot[%v] mp[%v] err[%v]", name, snapshotName, mp.PartitionID, err)
+			return
+		}
+	}
+
+	vol.snapshotLock.Lock()
+	delete(vol.snapshots, snapshot.ID)
+	vol.snapshotLock.Unlock()
+	if err = c.syncUpdateVol(vol); err != nil {
+		vol.snapshotLock.Lock()
+		vol.snapshots[snapshot.ID] = snapshot
+		vol.snapshotLock.Unlock()
+		log.LogErrorf("action[deleteSnapshot] vol[%v] err[%v]", name, err)
+		return proto.ErrPersistenceByRaft
+	}
+	log.LogInfof("action[deleteSnapshot] vol[%v] snapshot[%v] id[%v]", name, snapshotName, snapshot.ID)
+	return
+}
+
+func (c *Cluster) rollbackMetaSnapshot(mps []*MetaPartition, snapshotID uint64) {
+	for _, mp := range mps {
+		if err := c.syncSendMetaSnapshotTask(mp, proto.OpDeleteMetaSnapshot, snapshotID); err != nil {
+			log.LogWarnf("action[rollbackMetaSnapshot] mp[%v] snapshot[%v] err[%v]", mp.PartitionID, snapshotID, err)
+		}
+	}
+}
+
+func (c *Cluster) syncSendMetaSnapshotTask(mp *MetaPartition, opCode uint8, snapshotID uint64) (err error) {
+	mp.RLock()
+	mr, err := mp.getMetaReplicaLeader()
+	mp.RUnlock()
+	if err != nil {
+		return
+	}
+	metaNode, err := c.metaNode(mr.Addr)
+	if err != nil {
+		return
+	}
+	task := proto.NewAdminTask(opCode, mr.Addr, &proto.MetaSnapshotRequest{PartitionID: mp.PartitionID, SnapshotID: snapshotID})
+	_, err = metaNode.Sender.syncSendAdminTask(task)
+	return
+}
+
+func (vol *Vol) findSnapshot(name string) (snapshot *proto.SnapshotInfo, ok bool) {
+	vol.snapshotLock.RLock()
+	defer vol.snapshotLock.RUnlock()
+	for _, snapshot = range vol.snapshots {
+		if snapshot.Name == name {
+			return snapshot, true
+		}
+	}
+	return nil, false
+}
+
+func (vol *Vol) nextSnapshotID() (id uint64) {
+	vol.snapshotLock.RLock()
+	defer vol.snapshotLock.RUnlock()
+	for snapshotID := range vol.snapshots {
+		if snapshotID > id {
+			id = snapshotID
+		}
+	}
+	return id + 1
+}
+
+func (vol *Vol) snapshotList() (snapshots []*proto.SnapshotInfo) {
+	vol.snapshotLock.RLock()
+	defer vol.snapshotLock.RUnlock()
+	snapshots = make([]*proto.SnapshotInfo, 0, len(vol.snapshots))
+	for _, snapshot := range vol.snapshots {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID < snapshots[j].ID })
+	return
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+func (partition *DataPartition) isCold() bool {
+	return partition.StorageClass == proto.StorageClassCold
+}
+
+func (vol *Vol) coldRWCount() (count int) {
+	vol.dataPartitions.RLock()
+	defer vol.dataPartitions.RUnlock()
+	for _, dp := range vol.dataPartitions.partitionMap {
+		if dp.isCold() && dp.Status == proto.ReadWrite {
+			count++
+		}
+	}
+	return
+}
+
+// Keep a few writable partitions in the cold zone for the files of the cold storage class
+// if the cold zone is specified on the vol.
+func (vol *Vol) autoCreateColdDataPartitions(c *Cluster) {
+	if vol.coldZoneName == "" {
+		return
+	}
+	// the partitions would be placed in the other zones if the cold zone is not available
+	if _, err := c.t.getZone(vol.coldZoneName); err != nil {
+		log.LogErrorf("action[autoCreateColdDataPartitions] vol[%v] zone[%v] err[%v]", vol.Name, vol.coldZoneName, err)
+		return
+	}
+	for count := vol.coldRWCount(); count < minNumOfRWColdDataPartitions; count++ {
+		if _, err := c.doCreateDataPartition(vol.Name, 1, 0, 0, proto.StorageClassCold); err != nil {
+			log.LogErrorf("action[autoCreateColdDataPartitions] vol[%v] zone[%v] err[%v]", vol.Name, vol.coldZoneName, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	defaultTaskLeaseTTL = 60
+	maxTaskLeaseTTL     = 600
+)
+
+type taskLease struct {
+	owner  string
+	expire time.Time
+}
+
+// Task leases elect a single owner among the clients for the cluster-wide background tasks.
+// They are kept in the memory of the leader only. A new leader does not know the leases granted
+// by its predecessor, so it grants none until the longest lease could have expired.
+type taskLeaseManager struct {
+	sync.Mutex
+	leases     map[string]*taskLease
+	grantAfter time.Time
+}
+
+func newTaskLeaseManager() *taskLeaseManager {
+	return &taskLeaseManager{leases: make(map[string]*taskLease)}
+}
+
+func (tm *taskLeaseManager) reset() {
+	tm.Lock()
+	defer tm.Unlock()
+	tm.leases = make(map[string]*taskLease)
+	tm.grantAfter = time.Now().Add(maxTaskLeaseTTL * time.Second)
+}
+
+// Grant or renew the lease to the owner if it is free, expired or already held by the owner.
+// Otherwise the current holder is returned.
+func (tm *taskLeaseManager) acquire(name, owner string, ttl int64) *proto.TaskLease {
+	if ttl <= 0 {
+		ttl = defaultTaskLeaseTTL
+	}
+	if ttl > maxTaskLeaseTTL {
+		ttl = maxTaskLeaseTTL
+	}
+	tm.Lock()
+	defer tm.Unlock()
+	now := time.Now()
+	lease, ok := tm.leases[name]
+	if ok && lease.owner != owner && now.Before(lease.expire) {
+		return &proto.TaskLease{Name: name, Owner: lease.owner, TTL: int64(lease.expire.Sub(now) / time.Second)}
+	}
+	if now.Before(tm.grantAfter) {
+		return &proto.TaskLease{Name: name, TTL: int64(tm.grantAfter.Sub(now) / time.Second)}
+	}
+	if !ok || lease.owner != owner {
+		log.LogInfof("action[acquireTaskLease] task[%v] granted to owner[%v]", name, owner)
+	}
+	tm.leases[name] = &taskLease{owner: owner, expire: now.Add(time.Duration(ttl) * time.Second)}
+	return &proto.TaskLease{Name: name, Owner: owner, TTL: ttl, Granted: true}
+}
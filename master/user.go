@@ -305,6 +305,84 @@ func (u *User) removePolicy(params *proto.UserPermRemoveParam) (userInfo *proto.
 	return
 }
 
+func (u *User) updateIdentityPolicy(params *proto.UserIdentityPolicyParam) (userInfo *proto.UserInfo, err error) {
+	if userInfo, err = u.getUserInfo(params.UserID); err != nil {
+		return
+	}
+	userInfo.Mu.Lock()
+	defer userInfo.Mu.Unlock()
+	var formerPolicy = userInfo.Policy.IdentityPolicy
+	userInfo.Policy.SetIdentityPolicy(params.Policy)
+	if err = u.syncUpdateUserInfo(userInfo); err != nil {
+		userInfo.Policy.SetIdentityPolicy(formerPolicy)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	log.LogInfof("action[updateIdentityPolicy], userID: %v, statements: %v", params.UserID, len(params.Policy.Statements))
+	return
+}
+
+func (u *User) removeIdentityPolicy(userID string) (userInfo *proto.UserInfo, err error) {
+	if userInfo, err = u.getUserInfo(userID); err != nil {
+		return
+	}
+	userInfo.Mu.Lock()
+	defer userInfo.Mu.Unlock()
+	var formerPolicy = userInfo.Policy.IdentityPolicy
+	userInfo.Policy.SetIdentityPolicy(nil)
+	if err = u.syncUpdateUserInfo(userInfo); err != nil {
+		userInfo.Policy.SetIdentityPolicy(formerPolicy)
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	log.LogInfof("action[removeIdentityPolicy], userID: %v", userID)
+	return
+}
+
+func (u *User) updateS3QoS(params *proto.UserS3QoSParam) (userInfo *proto.UserInfo, err error) {
+	if userInfo, err = u.getUserInfo(params.UserID); err != nil {
+		return
+	}
+	userInfo.Mu.Lock()
+	defer userInfo.Mu.Unlock()
+	var formerRequestRate, formerBandwidth = userInfo.S3RequestRate, userInfo.S3Bandwidth
+	userInfo.S3RequestRate, userInfo.S3Bandwidth = params.S3RequestRate, params.S3Bandwidth
+	if err = u.syncUpdateUserInfo(userInfo); err != nil {
+		userInfo.S3RequestRate, userInfo.S3Bandwidth = formerRequestRate, formerBandwidth
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	log.LogInfof("action[updateS3QoS], userID: %v, requestRate: %v, bandwidth: %v",
+		params.UserID, params.S3RequestRate, params.S3Bandwidth)
+	return
+}
+
+func (u *User) simulatePolicy(params *proto.UserPolicySimulateParam) (result *proto.UserPolicySimulateResult, err error) {
+	var userInfo *proto.UserInfo
+	if userInfo, err = u.getUserInfo(params.UserID); err != nil {
+		return
+	}
+	var action = proto.ParseIAMAction(params.Action)
+	if action.IsNone() {
+		err = proto.ErrParamError
+		return
+	}
+	var decision = userInfo.Policy.EvaluateIdentityPolicy(action, params.Volume, params.Key, params.Context)
+	result = &proto.UserPolicySimulateResult{
+		UserID:   params.UserID,
+		Action:   action.String(),
+		Resource: proto.IAMResourceARN(params.Volume, params.Key),
+		Decision: decision.String(),
+		Allowed:  userInfo.UserType == proto.UserTypeRoot || userInfo.UserType == proto.UserTypeAdmin,
+	}
+	if !result.Allowed {
+		result.Allowed = userInfo.Policy.IsAllowed(action, params.Volume, params.Key, params.Context)
+	}
+	log.LogInfof("action[simulatePolicy], userID: %v, action: %v, resource: %v, allowed: %v",
+		params.UserID, result.Action, result.Resource, result.Allowed)
+	return
+}
+
 func (u *User) addOwnVol(userID, volName string) (userInfo *proto.UserInfo, err error) {
 	if userInfo, err = u.getUserInfo(userID); err != nil {
 		return
@@ -35,6 +35,14 @@ type VolVarargs struct {
 	enableToken    bool
 	dpSelectorName string
 	dpSelectorParm string
+	trashDays      uint32
+	ecDataNum      uint8
+	ecParityNum    uint8
+	ecColdDays     uint32
+	compression    string
+	s3RequestRate  uint64
+	s3Bandwidth    uint64
+	coldZoneName   string
 }
 
 // Vol represents a set of meta partitionMap and data partitionMap
@@ -58,6 +66,10 @@ type Vol struct {
 	enableToken        bool
 	tokens             map[string]*proto.Token
 	tokensLock         sync.RWMutex
+	quotas             map[uint32]*proto.QuotaInfo
+	quotaLock          sync.RWMutex
+	snapshots          map[uint64]*proto.SnapshotInfo
+	snapshotLock       sync.RWMutex
 	MetaPartitions     map[uint64]*MetaPartition `graphql:"-"`
 	mpsLock            sync.RWMutex
 	dataPartitions     *DataPartitionMap
@@ -69,6 +81,15 @@ type Vol struct {
 	description        string
 	dpSelectorName     string
 	dpSelectorParm     string
+	trashDays          uint32
+	ecDataNum          uint8 // the number of data shards of the new erasure coded partitions, 0 disables erasure coding
+	ecParityNum        uint8
+	ecColdDays         uint32 // the files not modified for such days are migrated into erasure coded partitions
+	compression        string // codec compressing the blocks written to the data partitions, empty means no compression
+	encryptionKey      []byte // data key wrapped by the master service key, empty means the volume is not encrypted
+	s3RequestRate      uint64 // requests per second served by the ObjectNodes, 0 means unlimited
+	s3Bandwidth        uint64 // bytes per second transferred by the ObjectNodes, 0 means unlimited
+	coldZoneName       string // zone of the data partitions holding the files of the cold storage class
 	sync.RWMutex
 }
 
@@ -102,6 +123,8 @@ func newVol(id uint64, name, owner, zoneName string, dpSize, capacity uint64, dp
 	vol.createTime = createTime
 	vol.enableToken = enableToken
 	vol.tokens = make(map[string]*proto.Token, 0)
+	vol.quotas = make(map[uint32]*proto.QuotaInfo, 0)
+	vol.snapshots = make(map[uint64]*proto.SnapshotInfo, 0)
 	vol.description = description
 	return
 }
@@ -127,6 +150,21 @@ func newVolFromVolValue(vv *volValue) (vol *Vol) {
 	vol.Status = vv.Status
 	vol.dpSelectorName = vv.DpSelectorName
 	vol.dpSelectorParm = vv.DpSelectorParm
+	vol.trashDays = vv.TrashDays
+	vol.ecDataNum = vv.ECDataNum
+	vol.ecParityNum = vv.ECParityNum
+	vol.ecColdDays = vv.ECColdDays
+	vol.compression = vv.Compression
+	vol.encryptionKey = vv.EncryptionKey
+	vol.s3RequestRate = vv.S3RequestRate
+	vol.s3Bandwidth = vv.S3Bandwidth
+	vol.coldZoneName = vv.ColdZoneName
+	for _, quota := range vv.Quotas {
+		vol.quotas[quota.QuotaID] = quota
+	}
+	for _, snapshot := range vv.Snapshots {
+		vol.snapshots[snapshot.ID] = snapshot
+	}
 	return vol
 }
 
@@ -249,7 +287,7 @@ func (vol *Vol) checkDataPartitions(c *Cluster) (cnt int) {
 		dp.checkLeader(c.cfg.DataPartitionTimeOutSec)
 		dp.checkMissingReplicas(c.Name, c.leaderInfo.addr, c.cfg.MissingDataPartitionInterval, c.cfg.IntervalToAlarmMissingDataPartition)
 		dp.checkReplicaNum(c, vol)
-		if dp.Status == proto.ReadWrite {
+		if dp.Status == proto.ReadWrite && !dp.isErasureCoded() && !dp.isCold() {
 			cnt++
 		}
 		dp.checkDiskError(c.Name, c.leaderInfo.addr)
@@ -432,6 +470,8 @@ func (vol *Vol) autoCreateDataPartitions(c *Cluster) {
 		log.LogInfof("action[autoCreateDataPartitions] vol[%v] count[%v]", vol.Name, count)
 		c.batchCreateDataPartition(vol, count)
 	}
+	vol.autoCreateErasureCodedDataPartitions(c)
+	vol.autoCreateColdDataPartitions(c)
 }
 
 // Calculate the expansion number (the number of data partitions to be allocated to the given volume)
@@ -460,6 +500,9 @@ func (vol *Vol) updateViewCache(c *Cluster) {
 	view := proto.NewVolView(vol.Name, vol.Status, vol.FollowerRead, vol.createTime)
 	view.SetOwner(vol.Owner)
 	view.SetOSSSecure(vol.OSSAccessKey, vol.OSSSecretKey)
+	view.TrashDays = vol.trashDays
+	view.S3RequestRate = vol.s3RequestRate
+	view.S3Bandwidth = vol.s3Bandwidth
 	mpViews := vol.getMetaPartitionsView()
 	view.MetaPartitions = mpViews
 	mpViewsReply := newSuccessHTTPReply(mpViews)
@@ -526,6 +569,7 @@ func (vol *Vol) checkStatus(c *Cluster) {
 		}
 	}()
 	vol.updateViewCache(c)
+	vol.checkQuotas()
 	vol.Lock()
 	defer vol.Unlock()
 	if vol.Status != markDelete {
@@ -822,5 +866,13 @@ func getVolVarargs(vol *Vol) *VolVarargs {
 		enableToken:    vol.enableToken,
 		dpSelectorName: vol.dpSelectorName,
 		dpSelectorParm: vol.dpSelectorParm,
+		trashDays:      vol.trashDays,
+		ecDataNum:      vol.ecDataNum,
+		ecParityNum:    vol.ecParityNum,
+		ecColdDays:     vol.ecColdDays,
+		compression:    vol.compression,
+		s3RequestRate:  vol.s3RequestRate,
+		s3Bandwidth:    vol.s3Bandwidth,
+		coldZoneName:   vol.coldZoneName,
 	}
 }
@@ -0,0 +1,119 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util/btree"
+)
+
+// ChangeLog records a change of the object, which is consumed by the object node to replicate the object
+// to the destination bucket. The ID is ordered by the time of the change, so that the changes are
+// consumed in the order they are appended to the partition.
+type ChangeLog struct {
+	id         string
+	op         uint8
+	key        string
+	createTime time.Time
+}
+
+func newChangeLogID(t time.Time, seq uint32) string {
+	return fmt.Sprintf("%016x%08x", t.UnixNano(), seq)
+}
+
+func (c *ChangeLog) Less(than btree.Item) bool {
+	tc, is := than.(*ChangeLog)
+	return is && c.id < tc.id
+}
+
+func (c *ChangeLog) Copy() btree.Item {
+	return &ChangeLog{
+		id:         c.id,
+		op:         c.op,
+		key:        c.key,
+		createTime: c.createTime,
+	}
+}
+
+func (c *ChangeLog) Bytes() ([]byte, error) {
+	var n int
+	var buffer = bytes.NewBuffer(nil)
+	var err error
+	tmp := make([]byte, binary.MaxVarintLen64)
+	var marshalStr = func(src string) error {
+		n = binary.PutUvarint(tmp, uint64(len(src)))
+		if _, err = buffer.Write(tmp[:n]); err != nil {
+			return err
+		}
+		if _, err = buffer.WriteString(src); err != nil {
+			return err
+		}
+		return nil
+	}
+	// marshal id
+	if err = marshalStr(c.id); err != nil {
+		return nil, err
+	}
+	// marshal op
+	if err = buffer.WriteByte(c.op); err != nil {
+		return nil, err
+	}
+	// marshal key
+	if err = marshalStr(c.key); err != nil {
+		return nil, err
+	}
+	// marshal create time
+	n = binary.PutVarint(tmp, c.createTime.UnixNano())
+	if _, err = buffer.Write(tmp[:n]); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func ChangeLogFromBytes(raw []byte) *ChangeLog {
+	var unmarshalStr = func(data []byte) (string, int) {
+		var n int
+		var lengthU64 uint64
+		lengthU64, n = binary.Uvarint(data)
+		return string(data[n : n+int(lengthU64)]), n + int(lengthU64)
+	}
+	var offset, n int
+	// decode id
+	var id string
+	id, n = unmarshalStr(raw)
+	offset += n
+	// decode op
+	var op = raw[offset]
+	offset += 1
+	// decode key
+	var key string
+	key, n = unmarshalStr(raw[offset:])
+	offset += n
+	// decode create time
+	var createTimeI64 int64
+	createTimeI64, _ = binary.Varint(raw[offset:])
+
+	var changeLog = &ChangeLog{
+		id:         id,
+		op:         op,
+		key:        key,
+		createTime: time.Unix(0, createTimeI64),
+	}
+	return changeLog
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestChangeLog_Bytes(t *testing.T) {
+	var err error
+	var now = time.Unix(0, time.Now().UnixNano())
+	for i, op := range []uint8{proto.ChangeLogOpPut, proto.ChangeLogOpDelete} {
+		changeLog1 := &ChangeLog{
+			id:         newChangeLogID(now, uint32(i)),
+			op:         op,
+			key:        "a/b/c.txt",
+			createTime: now,
+		}
+		var changeLogBytes []byte
+		if changeLogBytes, err = changeLog1.Bytes(); err != nil {
+			t.Fatalf("get bytes of change log fail cause: %v", err)
+		}
+		changeLog2 := ChangeLogFromBytes(changeLogBytes)
+		if !reflect.DeepEqual(changeLog1, changeLog2) {
+			t.Fatalf("result mismatch:\n\tchangeLog1:%v\n\tchangeLog2:%v", changeLog1, changeLog2)
+		}
+	}
+}
+
+func TestChangeLog_Order(t *testing.T) {
+	var now = time.Now()
+	var ids = []string{
+		newChangeLogID(now, 0),
+		newChangeLogID(now, 1),
+		newChangeLogID(now.Add(time.Nanosecond), 0),
+		newChangeLogID(now.Add(time.Second), 0),
+	}
+	for i := 1; i < len(ids); i++ {
+		prev, next := &ChangeLog{id: ids[i-1]}, &ChangeLog{id: ids[i]}
+		if !prev.Less(next) || next.Less(prev) {
+			t.Fatalf("change log order mismatch: prev(%v) next(%v)", prev.id, next.id)
+		}
+	}
+}
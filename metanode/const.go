@@ -31,6 +31,10 @@ type (
 	CreateInoReq = proto.CreateInodeRequest
 	// MetaNode -> Client create Inode response
 	CreateInoResp = proto.CreateInodeResponse
+	// Client -> MetaNode copy Inode request
+	CopyInoReq = proto.CopyInodeRequest
+	// MetaNode -> Client copy Inode response
+	CopyInoResp = proto.CopyInodeResponse
 	// Client -> MetaNode create Link Request
 	LinkInodeReq = proto.LinkInodeRequest
 	// MetaNode -> Client create Link Response
@@ -45,6 +49,8 @@ type (
 	BatchUnlinkInoResp = proto.BatchUnlinkInodeResponse
 	// Client -> MetaNode create Dentry request struct
 	CreateDentryReq = proto.CreateDentryRequest
+	// MetaNode -> Client create Dentry response
+	CreateDentryResp = proto.CreateDentryResponse
 	// Client -> MetaNode delete Dentry request
 	DeleteDentryReq = proto.DeleteDentryRequest
 	// Client -> MetaNode delete Dentry request
@@ -117,6 +123,30 @@ const (
 	opFSMDeleteDentryBatch
 	opFSMUnlinkInodeBatch
 	opFSMEvictInodeBatch
+
+	opFSMCreateVersion
+	opFSMRemoveVersion
+
+	opFSMTxRenamePrepare
+	opFSMTxRenameCommit
+	opFSMTxRenameAbort
+	opFSMTxRenameRemove
+
+	opFSMCreateMetaSnapshot
+	opFSMDeleteMetaSnapshot
+
+	opFSMCopyInode
+	opFSMInternalFreeInode
+	opExtentRefSnapshot
+
+	opFSMAppendChangeLog
+	opFSMRemoveChangeLog
+
+	opFSMCreateInodeQuota
+	opMetaSnapshotItem
+
+	opFSMReplaceExtents
+	opFSMCopyInodeRange
 )
 
 var (
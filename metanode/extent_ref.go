@@ -0,0 +1,229 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/storage"
+	"github.com/chubaofs/chubaofs/util/btree"
+)
+
+const extentRefLength = 20
+
+// ExtentRef records the extra references to a normal extent which is shared by the inodes copied without
+// copying data. A normal extent is always deleted as a whole by the data node, so the references are counted
+// per extent rather than per extent key, which may be split by overwriting or truncating. Tiny extents are
+// never shared, the inodes referring them are copied with the data instead.
+//
+// Each inode sharing the extent besides the first one adds a reference. An inode which does not refer any part
+// of the extent any longer consumes one instead of deleting the extent, so the extent is deleted only by the
+// last inode referring it. The frozen copy of an inode in a volume snapshot takes over the reference of the
+// inode when the inode releases the extent, and releases it when the snapshot is deleted.
+type ExtentRef struct {
+	partitionID uint64
+	extentID    uint64
+	count       uint32
+}
+
+func newExtentRef(ek *proto.ExtentKey) *ExtentRef {
+	return &ExtentRef{
+		partitionID: ek.PartitionId,
+		extentID:    ek.ExtentId,
+	}
+}
+
+func (r *ExtentRef) Less(than btree.Item) bool {
+	tr, is := than.(*ExtentRef)
+	if !is {
+		return false
+	}
+	if r.partitionID != tr.partitionID {
+		return r.partitionID < tr.partitionID
+	}
+	return r.extentID < tr.extentID
+}
+
+func (r *ExtentRef) Copy() btree.Item {
+	return &ExtentRef{
+		partitionID: r.partitionID,
+		extentID:    r.extentID,
+		count:       r.count,
+	}
+}
+
+func (r *ExtentRef) Bytes() ([]byte, error) {
+	raw := make([]byte, extentRefLength)
+	binary.BigEndian.PutUint64(raw[0:8], r.partitionID)
+	binary.BigEndian.PutUint64(raw[8:16], r.extentID)
+	binary.BigEndian.PutUint32(raw[16:20], r.count)
+	return raw, nil
+}
+
+func ExtentRefFromBytes(raw []byte) (*ExtentRef, error) {
+	if len(raw) < extentRefLength {
+		return nil, fmt.Errorf("extent reference too short: length(%v)", len(raw))
+	}
+	return &ExtentRef{
+		partitionID: binary.BigEndian.Uint64(raw[0:8]),
+		extentID:    binary.BigEndian.Uint64(raw[8:16]),
+		count:       binary.BigEndian.Uint32(raw[16:20]),
+	}, nil
+}
+
+func (r *ExtentRef) String() string {
+	return fmt.Sprintf("ExtentRef{partitionID(%v) extentID(%v) count(%v)}",
+		r.partitionID, r.extentID, r.count)
+}
+
+// Return the distinct normal extents referred by the extent keys.
+func sharedExtentRefs(eks []proto.ExtentKey) []*ExtentRef {
+	refs := make([]*ExtentRef, 0, len(eks))
+	seen := make(map[ExtentRef]struct{}, len(eks))
+	for i := range eks {
+		if storage.IsTinyExtent(eks[i].ExtentId) {
+			continue
+		}
+		ref := newExtentRef(&eks[i])
+		if _, ok := seen[*ref]; ok {
+			continue
+		}
+		seen[*ref] = struct{}{}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// Add a reference for each of the extents referred by the copied inode.
+func (mp *metaPartition) retainExtentRefs(eks []proto.ExtentKey) {
+	for _, ref := range sharedExtentRefs(eks) {
+		if item := mp.extentRefTree.CopyGet(ref); item != nil {
+			item.(*ExtentRef).count++
+			continue
+		}
+		ref.count = 1
+		mp.extentRefTree.ReplaceOrInsert(ref, false)
+	}
+}
+
+// Consume a reference of the extent, it returns false if the extent is not shared and should be deleted.
+func (mp *metaPartition) releaseExtentRef(ref *ExtentRef) bool {
+	if mp.extentRefTree.Len() == 0 {
+		return false
+	}
+	item := mp.extentRefTree.CopyGet(ref)
+	if item == nil {
+		return false
+	}
+	stored := item.(*ExtentRef)
+	if stored.count--; stored.count == 0 {
+		mp.extentRefTree.Delete(stored)
+	}
+	return true
+}
+
+// Check whether any of the extents of the inode is shared with the copied inodes or the volume snapshots,
+// in which case the client writes the new data to new extents instead of overwriting the extents in place.
+func (mp *metaPartition) isSharedInode(ino uint64, eks []proto.ExtentKey) bool {
+	if mp.isMetaSnapshotInode(ino) {
+		return true
+	}
+	if mp.extentRefTree.Len() == 0 {
+		return false
+	}
+	for _, ref := range sharedExtentRefs(eks) {
+		if mp.extentRefTree.Has(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check whether any extent key of the inode refers the extent of the given key. Any part of a normal extent
+// keeps the whole extent, while the ranges of a tiny extent are deleted separately.
+func inodeHoldsExtent(inode *Inode, ek *proto.ExtentKey) (held bool) {
+	tiny := storage.IsTinyExtent(ek.ExtentId)
+	inode.Extents.Range(func(other proto.ExtentKey) bool {
+		held = other.PartitionId == ek.PartitionId && other.ExtentId == ek.ExtentId && (!tiny ||
+			other.ExtentOffset < ek.ExtentOffset+uint64(ek.Size) && ek.ExtentOffset < other.ExtentOffset+uint64(other.Size))
+		return !held
+	})
+	return
+}
+
+// Return the extent keys to delete among the keys released from the inode by overwriting or truncating.
+// The extents still referred by other keys of the inode are kept, the references of the volume snapshots
+// and the copied inodes are handed over or consumed, and only the extents nobody refers are deleted.
+// A normal extent is deleted by one of its keys.
+func (mp *metaPartition) releaseInodeExtents(inode *Inode, eks []proto.ExtentKey) []proto.ExtentKey {
+	released := make([]proto.ExtentKey, 0, len(eks))
+	seen := make(map[ExtentRef]struct{}, len(eks))
+	for i := range eks {
+		ek := &eks[i]
+		if !storage.IsTinyExtent(ek.ExtentId) {
+			ref := newExtentRef(ek)
+			if _, ok := seen[*ref]; ok {
+				continue
+			}
+			seen[*ref] = struct{}{}
+		}
+		if inodeHoldsExtent(inode, ek) || mp.isMetaSnapshotExtent(inode.Inode, ek, 0) {
+			continue
+		}
+		if !storage.IsTinyExtent(ek.ExtentId) && mp.releaseExtentRef(newExtentRef(ek)) {
+			continue
+		}
+		released = append(released, *ek)
+	}
+	return released
+}
+
+// Return the extent keys to delete when the inode is freed, which excludes the extents still referenced
+// by other inodes, as their references are consumed by opFSMInternalFreeInode instead of deleting the extents.
+// The references consumed by the inodes collected before in the same batch are recorded in pending, and the
+// inode is not ready if it shares any of them, since the result depends on whether those inodes are freed.
+func (mp *metaPartition) extentsToDelete(inode *Inode, pending map[ExtentRef]uint32) (eks []proto.ExtentKey, ready bool) {
+	all := inode.Extents.CopyExtents()
+	if mp.extentRefTree.Len() == 0 {
+		return all, true
+	}
+	consumed := make(map[ExtentRef]uint32)
+	eks = make([]proto.ExtentKey, 0, len(all))
+	for i := range all {
+		if storage.IsTinyExtent(all[i].ExtentId) {
+			eks = append(eks, all[i])
+			continue
+		}
+		ref := newExtentRef(&all[i])
+		if _, ok := consumed[*ref]; ok {
+			continue
+		}
+		item := mp.extentRefTree.Get(ref)
+		if item == nil {
+			eks = append(eks, all[i])
+			continue
+		}
+		if _, ok := pending[*ref]; ok {
+			return nil, false
+		}
+		consumed[*ref] = 1
+	}
+	for ref, n := range consumed {
+		pending[ref] = n
+	}
+	return eks, true
+}
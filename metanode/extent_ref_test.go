@@ -0,0 +1,184 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestExtentRef_Bytes(t *testing.T) {
+	var err error
+	ref1 := &ExtentRef{partitionID: 1, extentID: 1025, count: 3}
+	var refBytes []byte
+	if refBytes, err = ref1.Bytes(); err != nil {
+		t.Fatalf("get bytes of extent reference fail cause: %v", err)
+	}
+	var ref2 *ExtentRef
+	if ref2, err = ExtentRefFromBytes(refBytes); err != nil {
+		t.Fatalf("parse extent reference fail cause: %v", err)
+	}
+	if !reflect.DeepEqual(ref1, ref2) {
+		t.Fatalf("result mismatch:\n\tref1:%v\n\tref2:%v", ref1, ref2)
+	}
+}
+
+// The inode copied in a range refers the extents in the range only.
+func TestExtentRef_CopyRange(t *testing.T) {
+	mp := &metaPartition{
+		config:        &MetaPartitionConfig{PartitionId: 1, Start: 1, End: 1000},
+		inodeTree:     NewBtree(),
+		extendTree:    NewBtree(),
+		extentRefTree: NewBtree(),
+		freeList:      newFreeList(),
+	}
+	src := NewInode(10, 0644)
+	src.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 1024})
+	src.Extents.Append(proto.ExtentKey{FileOffset: 1024, PartitionId: 2, ExtentId: 1026, Size: 1024})
+	src.Size = 2048
+	mp.inodeTree.ReplaceOrInsert(src, true)
+
+	if resp := mp.fsmCopyInodeRange(10, 1024, 2048, NewInode(11, 0)); resp.Status != proto.OpArgMismatchErr {
+		t.Fatalf("copy inode beyond the size should fail: status(%v)", resp.Status)
+	}
+	resp := mp.fsmCopyInodeRange(10, 1100, 500, NewInode(11, 0))
+	if resp.Status != proto.OpOk {
+		t.Fatalf("copy inode range fail: status(%v)", resp.Status)
+	}
+	eks := resp.Msg.Extents.CopyExtents()
+	if resp.Msg.Size != 500 || len(eks) != 1 || eks[0].ExtentId != 1026 || eks[0].FileOffset != 0 ||
+		eks[0].ExtentOffset != 76 || eks[0].Size != 500 {
+		t.Fatalf("copied inode mismatch: size(%v) eks(%v)", resp.Msg.Size, eks)
+	}
+	if mp.extentRefTree.Has(&ExtentRef{partitionID: 1, extentID: 1025}) {
+		t.Fatalf("the extent out of the range should not be referred")
+	}
+	item := mp.extentRefTree.Get(&ExtentRef{partitionID: 2, extentID: 1026})
+	if item == nil || item.(*ExtentRef).count != 1 {
+		t.Fatalf("extent reference mismatch: ref(%v)", item)
+	}
+}
+
+func TestExtentRef_CopyAndFree(t *testing.T) {
+	mp := &metaPartition{
+		config:        &MetaPartitionConfig{PartitionId: 1, Start: 1, End: 1000},
+		inodeTree:     NewBtree(),
+		extendTree:    NewBtree(),
+		extentRefTree: NewBtree(),
+		freeList:      newFreeList(),
+	}
+	src := NewInode(10, 0644)
+	src.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 1024})
+	src.Extents.Append(proto.ExtentKey{FileOffset: 1024, PartitionId: 2, ExtentId: 1026, Size: 1024})
+	src.Size = 2048
+	mp.inodeTree.ReplaceOrInsert(src, true)
+
+	for _, ino := range []uint64{11, 12} {
+		if resp := mp.fsmCopyInode(10, NewInode(ino, 0)); resp.Status != proto.OpOk {
+			t.Fatalf("copy inode fail: inode(%v) status(%v)", ino, resp.Status)
+		}
+	}
+	if resp := mp.fsmCopyInode(13, NewInode(14, 0)); resp.Status != proto.OpNotExistErr {
+		t.Fatalf("copy inode should fail: status(%v)", resp.Status)
+	}
+	for _, ek := range src.Extents.CopyExtents() {
+		item := mp.extentRefTree.Get(newExtentRef(&ek))
+		if item == nil || item.(*ExtentRef).count != 2 {
+			t.Fatalf("extent reference mismatch: ek(%v) ref(%v)", ek, item)
+		}
+	}
+
+	// the extents are shared by the other inodes, and none of them should be deleted
+	pending := make(map[ExtentRef]uint32)
+	inode := mp.inodeTree.Get(NewInode(10, 0)).(*Inode)
+	if eks, ready := mp.extentsToDelete(inode, pending); !ready || len(eks) != 0 {
+		t.Fatalf("extents to delete mismatch: ready(%v) eks(%v)", ready, eks)
+	}
+	// the inode shares the references pending in the same batch
+	inode = mp.inodeTree.Get(NewInode(11, 0)).(*Inode)
+	if _, ready := mp.extentsToDelete(inode, pending); ready {
+		t.Fatalf("inode should not be ready since it shares the pending references")
+	}
+
+	var freeInodes = func(inodes ...uint64) {
+		val := make([]byte, 8*len(inodes))
+		for i, ino := range inodes {
+			binary.BigEndian.PutUint64(val[i*8:], ino)
+		}
+		if err := mp.internalFreeInode(val); err != nil {
+			t.Fatalf("free inodes fail: inodes(%v) err(%v)", inodes, err)
+		}
+	}
+	freeInodes(10, 11)
+	if mp.extentRefTree.Len() != 0 {
+		t.Fatalf("all extent references should be consumed: len(%v)", mp.extentRefTree.Len())
+	}
+
+	// the last reference to the extents
+	pending = make(map[ExtentRef]uint32)
+	inode = mp.inodeTree.Get(NewInode(12, 0)).(*Inode)
+	if eks, ready := mp.extentsToDelete(inode, pending); !ready || len(eks) != 2 {
+		t.Fatalf("extents to delete mismatch: ready(%v) eks(%v)", ready, eks)
+	}
+	freeInodes(12)
+	if mp.inodeTree.Len() != 0 {
+		t.Fatalf("all inodes should be freed: len(%v)", mp.inodeTree.Len())
+	}
+}
+
+func TestExtentRef_ReplaceExtents(t *testing.T) {
+	mp := &metaPartition{
+		config:        &MetaPartitionConfig{PartitionId: 1, Start: 1, End: 1000},
+		inodeTree:     NewBtree(),
+		extendTree:    NewBtree(),
+		extentRefTree: NewBtree(),
+		extDelCh:      make(chan []proto.ExtentKey, 1),
+	}
+	inode := NewInode(10, 0644)
+	inode.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 100})
+	inode.Size = 100
+	mp.inodeTree.ReplaceOrInsert(inode, true)
+	old := inode.Extents.CopyExtents()
+	migrated := []proto.ExtentKey{{FileOffset: 0, PartitionId: 2, ExtentId: 1025, Size: 100}}
+
+	if status := mp.fsmReplaceExtents(&proto.ReplaceExtentsRequest{Inode: 10, Prepare: true, MarkTime: time.Now().Unix()}); status != proto.OpOk {
+		t.Fatalf("prepare migration fail: status(%v)", status)
+	}
+	if !mp.isMigratingInode(10) {
+		t.Fatalf("inode should be marked as migrating")
+	}
+
+	// the file is overwritten during the migration
+	overwritten := []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 50}}
+	if status := mp.fsmReplaceExtents(&proto.ReplaceExtentsRequest{Inode: 10, OldExtents: overwritten, NewExtents: migrated}); status != proto.OpArgMismatchErr {
+		t.Fatalf("changed extents should not be replaced: status(%v)", status)
+	}
+	if status := mp.fsmReplaceExtents(&proto.ReplaceExtentsRequest{Inode: 10, OldExtents: old, NewExtents: migrated}); status != proto.OpOk {
+		t.Fatalf("replace extents fail: status(%v)", status)
+	}
+	if eks := <-mp.extDelCh; len(eks) != 1 || eks[0] != old[0] {
+		t.Fatalf("replaced extents should be deleted: %v", eks)
+	}
+	if eks := inode.Extents.CopyExtents(); len(eks) != 1 || eks[0] != migrated[0] {
+		t.Fatalf("extents mismatch: %v", eks)
+	}
+	if mp.isMigratingInode(10) {
+		t.Fatalf("migrating mark should be removed")
+	}
+}
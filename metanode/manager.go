@@ -123,6 +123,10 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet,
 		err = m.opDeleteMetaPartition(conn, p, remoteAddr)
 	case proto.OpUpdateMetaPartition:
 		err = m.opUpdateMetaPartition(conn, p, remoteAddr)
+	case proto.OpCreateMetaSnapshot:
+		err = m.opCreateMetaSnapshot(conn, p, remoteAddr)
+	case proto.OpDeleteMetaSnapshot:
+		err = m.opDeleteMetaSnapshot(conn, p, remoteAddr)
 	case proto.OpLoadMetaPartition:
 		err = m.opLoadMetaPartition(conn, p, remoteAddr)
 	case proto.OpDecommissionMetaPartition:
@@ -148,6 +152,10 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet,
 		err = m.opMetaGetXAttr(conn, p, remoteAddr)
 	case proto.OpMetaBatchGetXAttr:
 		err = m.opMetaBatchGetXAttr(conn, p, remoteAddr)
+	case proto.OpMetaCopyInode:
+		err = m.opMetaCopyInode(conn, p, remoteAddr)
+	case proto.OpMetaExtentsReplace:
+		err = m.opMetaExtentsReplace(conn, p, remoteAddr)
 	case proto.OpMetaRemoveXAttr:
 		err = m.opMetaRemoveXAttr(conn, p, remoteAddr)
 	case proto.OpMetaListXAttr:
@@ -163,6 +171,31 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet,
 		err = m.opAppendMultipart(conn, p, remoteAddr)
 	case proto.OpGetMultipart:
 		err = m.opGetMultipart(conn, p, remoteAddr)
+	// operations for object version
+	case proto.OpCreateVersion:
+		err = m.opCreateVersion(conn, p, remoteAddr)
+	case proto.OpGetVersion:
+		err = m.opGetVersion(conn, p, remoteAddr)
+	case proto.OpRemoveVersion:
+		err = m.opRemoveVersion(conn, p, remoteAddr)
+	case proto.OpListVersions:
+		err = m.opListVersions(conn, p, remoteAddr)
+	// operations for rename transaction
+	case proto.OpMetaTxRenamePrepare:
+		err = m.opTxRenamePrepare(conn, p, remoteAddr)
+	case proto.OpMetaTxRenameCommit:
+		err = m.opTxRenameCommit(conn, p, remoteAddr)
+	case proto.OpMetaTxRenameAbort:
+		err = m.opTxRenameAbort(conn, p, remoteAddr)
+	case proto.OpMetaTxRenameGet:
+		err = m.opTxRenameGet(conn, p, remoteAddr)
+	// operations for object change log
+	case proto.OpAppendChangeLog:
+		err = m.opAppendChangeLog(conn, p, remoteAddr)
+	case proto.OpListChangeLogs:
+		err = m.opListChangeLogs(conn, p, remoteAddr)
+	case proto.OpRemoveChangeLog:
+		err = m.opRemoveChangeLog(conn, p, remoteAddr)
 	default:
 		err = fmt.Errorf("%s unknown Opcode: %d, reqId: %d", remoteAddr,
 			p.Opcode, p.GetReqID())
@@ -0,0 +1,329 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package metanode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// The frozen metadata of the volume snapshot is stored in a separate directory of the partition by
+// the store tick, and is carried by the raft snapshot, so that a replica rebuilt from the raft snapshot
+// keeps the volume snapshots created before.
+const volSnapshotDirPrefix = "vol_snapshot_"
+
+// metaSnapshot is the metadata of the partition frozen at the apply index of a volume snapshot.
+// The inodes, dentries and extended attributes are copy-on-write clones of the trees of the partition.
+// The fsm modifies the items through CopyGet, which copies the items shared with the clones before
+// they are changed, so the frozen items are not affected by the later modifications of the partition.
+//
+// Every partition freezes at the apply index of its own request, and the master sends the requests
+// to the partitions one by one. So a volume snapshot is not a single point in time of the volume,
+// a modification across partitions during the creation may be seen partially in the snapshot.
+type metaSnapshot struct {
+	id      uint64
+	applyID uint64
+	view    *metaPartition // read-only partition serving the frozen trees
+}
+
+func (mp *metaPartition) newMetaSnapshot(id, applyID uint64, inodeTree, dentryTree, extendTree *BTree) *metaSnapshot {
+	return &metaSnapshot{
+		id:      id,
+		applyID: applyID,
+		view: &metaPartition{
+			config:        mp.config,
+			inodeTree:     inodeTree,
+			dentryTree:    dentryTree,
+			extendTree:    extendTree,
+			multipartTree: NewBtree(),
+			versionTree:   NewBtree(),
+			renameTxTree:  NewBtree(),
+			extentRefTree: NewBtree(),
+			changeLogTree: NewBtree(),
+			freeList:      newFreeList(),
+			vol:           mp.vol,
+			manager:       mp.manager,
+		},
+	}
+}
+
+func (mp *metaPartition) getMetaSnapshot(id uint64) (snap *metaSnapshot, ok bool) {
+	var value interface{}
+	if value, ok = mp.metaSnapshots.Load(id); ok {
+		snap = value.(*metaSnapshot)
+	}
+	return
+}
+
+func (mp *metaPartition) rangeMetaSnapshots(f func(snap *metaSnapshot) bool) {
+	mp.metaSnapshots.Range(func(key, value interface{}) bool {
+		return f(value.(*metaSnapshot))
+	})
+}
+
+// Return the volume snapshots of the partition in the order of their IDs.
+func (mp *metaPartition) listMetaSnapshots() (snaps []*metaSnapshot) {
+	mp.rangeMetaSnapshots(func(snap *metaSnapshot) bool {
+		snaps = append(snaps, snap)
+		return true
+	})
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].id < snaps[j].id })
+	return
+}
+
+func (mp *metaPartition) metaSnapshotDir(id uint64) string {
+	return path.Join(mp.config.RootDir, volSnapshotDirPrefix+strconv.FormatUint(id, 10))
+}
+
+// Store the volume snapshots which are not stored yet. The frozen trees never change,
+// so a snapshot is stored only once.
+func (mp *metaPartition) storeMetaSnapshots(snaps []*metaSnapshot) (err error) {
+	for _, snap := range snaps {
+		if _, err = os.Stat(mp.metaSnapshotDir(snap.id)); err == nil {
+			continue
+		}
+		if err = mp.storeMetaSnapshot(snap); err != nil {
+			return
+		}
+		log.LogInfof("storeMetaSnapshots: partitionID(%v) snapshot(%v) stored", mp.config.PartitionId, snap)
+	}
+	return nil
+}
+
+// Remove the directories of the volume snapshots which have been deleted.
+func (mp *metaPartition) removeStaleMetaSnapshots(snaps []*metaSnapshot) (err error) {
+	var fileInfos []os.FileInfo
+	if fileInfos, err = ioutil.ReadDir(mp.config.RootDir); err != nil {
+		return
+	}
+	ids := make(map[uint64]struct{}, len(snaps))
+	for _, snap := range snaps {
+		ids[snap.id] = struct{}{}
+	}
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() || !strings.HasPrefix(fileInfo.Name(), volSnapshotDirPrefix) {
+			continue
+		}
+		id, parseErr := strconv.ParseUint(strings.TrimPrefix(fileInfo.Name(), volSnapshotDirPrefix), 10, 64)
+		if _, ok := ids[id]; ok || parseErr != nil {
+			continue
+		}
+		if err = os.RemoveAll(path.Join(mp.config.RootDir, fileInfo.Name())); err != nil {
+			return
+		}
+		log.LogInfof("removeStaleMetaSnapshots: partitionID(%v) snapshot(%v) removed", mp.config.PartitionId, id)
+	}
+	return
+}
+
+func (mp *metaPartition) storeMetaSnapshot(snap *metaSnapshot) (err error) {
+	dir := mp.metaSnapshotDir(snap.id)
+	tmpDir := path.Join(mp.config.RootDir, "."+path.Base(dir))
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return
+	}
+	if err = os.MkdirAll(tmpDir, 0775); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(tmpDir)
+		}
+	}()
+	sm := &storeMsg{
+		applyIndex: snap.applyID,
+		inodeTree:  snap.view.inodeTree,
+		dentryTree: snap.view.dentryTree,
+		extendTree: snap.view.extendTree,
+	}
+	if _, err = mp.storeInode(tmpDir, sm); err != nil {
+		return
+	}
+	if _, err = mp.storeDentry(tmpDir, sm); err != nil {
+		return
+	}
+	if _, err = mp.storeExtend(tmpDir, sm); err != nil {
+		return
+	}
+	if err = mp.storeApplyID(tmpDir, sm); err != nil {
+		return
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return
+	}
+	return os.Rename(tmpDir, dir)
+}
+
+func (mp *metaPartition) loadMetaSnapshots() (err error) {
+	var fileInfos []os.FileInfo
+	if fileInfos, err = ioutil.ReadDir(mp.config.RootDir); err != nil {
+		return
+	}
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() || !strings.HasPrefix(fileInfo.Name(), volSnapshotDirPrefix) {
+			continue
+		}
+		var id uint64
+		if id, err = strconv.ParseUint(strings.TrimPrefix(fileInfo.Name(), volSnapshotDirPrefix), 10, 64); err != nil {
+			log.LogWarnf("loadMetaSnapshots: partitionID(%v) skip invalid dir(%v)", mp.config.PartitionId, fileInfo.Name())
+			err = nil
+			continue
+		}
+		snap := mp.newMetaSnapshot(id, 0, NewBtree(), NewBtree(), NewBtree())
+		dir := path.Join(mp.config.RootDir, fileInfo.Name())
+		// the view loads the trees by itself, and the inodes to be freed are collected in its own free list
+		if err = snap.view.loadInode(dir); err != nil {
+			return
+		}
+		if err = snap.view.loadDentry(dir); err != nil {
+			return
+		}
+		if err = snap.view.loadExtend(dir); err != nil {
+			return
+		}
+		if err = snap.view.loadApplyID(dir); err != nil {
+			return
+		}
+		snap.applyID = snap.view.applyID
+		mp.metaSnapshots.Store(id, snap)
+		log.LogInfof("loadMetaSnapshots: partitionID(%v) snapshot(%v) applyID(%v)", mp.config.PartitionId, id, snap.applyID)
+	}
+	return
+}
+
+// Check whether the inode is frozen by any of the volume snapshots.
+// The extents of such inode must not be deleted until the snapshots are deleted.
+func (mp *metaPartition) isMetaSnapshotInode(ino uint64) (ok bool) {
+	mp.rangeMetaSnapshots(func(snap *metaSnapshot) bool {
+		ok = snap.view.inodeTree.Has(&Inode{Inode: ino})
+		return !ok
+	})
+	return
+}
+
+// Check whether the extent of the key is referenced by the frozen copies of the inode in the volume snapshots
+// other than the excepted one.
+func (mp *metaPartition) isMetaSnapshotExtent(ino uint64, ek *proto.ExtentKey, except uint64) (referenced bool) {
+	mp.rangeMetaSnapshots(func(snap *metaSnapshot) bool {
+		if snap.id == except {
+			return true
+		}
+		if item := snap.view.inodeTree.Get(&Inode{Inode: ino}); item != nil {
+			referenced = inodeHoldsExtent(item.(*Inode), ek)
+		}
+		return !referenced
+	})
+	return
+}
+
+// metaSnapshotItem is an item of the frozen trees of a volume snapshot carried by the raft snapshot.
+// The item without the frozen tree item declares the snapshot, so that an empty snapshot is carried too.
+type metaSnapshotItem struct {
+	snap *metaSnapshot
+	item BtreeItem
+}
+
+// MetaItem encodes the item as a MetaItem of opMetaSnapshotItem.
+// The key is the snapshot ID and the apply index, and the value is the frozen tree item encoded as
+// the MetaItem of the partition snapshot.
+func (si *metaSnapshotItem) MetaItem() (item *MetaItem, err error) {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], si.snap.id)
+	binary.BigEndian.PutUint64(key[8:], si.snap.applyID)
+	var frozen *MetaItem
+	switch typedItem := si.item.(type) {
+	case nil:
+		return NewMetaItem(opMetaSnapshotItem, key, nil), nil
+	case *Inode:
+		frozen = NewMetaItem(opFSMCreateInode, typedItem.MarshalKey(), typedItem.MarshalValue())
+	case *Dentry:
+		frozen = NewMetaItem(opFSMCreateDentry, typedItem.MarshalKey(), typedItem.MarshalValue())
+	case *Extend:
+		var raw []byte
+		if raw, err = typedItem.Bytes(); err != nil {
+			return
+		}
+		frozen = NewMetaItem(opFSMSetXAttr, nil, raw)
+	default:
+		return nil, fmt.Errorf("unknown meta snapshot item %v", si.item)
+	}
+	var value []byte
+	if value, err = frozen.MarshalBinary(); err != nil {
+		return
+	}
+	return NewMetaItem(opMetaSnapshotItem, key, value), nil
+}
+
+// Decode the item of opMetaSnapshotItem into the volume snapshots rebuilt from the raft snapshot.
+func (mp *metaPartition) applyMetaSnapshotItem(snaps map[uint64]*metaSnapshot, item *MetaItem) (err error) {
+	if len(item.K) != 16 {
+		return fmt.Errorf("invalid meta snapshot item key length %v", len(item.K))
+	}
+	id := binary.BigEndian.Uint64(item.K[:8])
+	snap, ok := snaps[id]
+	if !ok {
+		snap = mp.newMetaSnapshot(id, binary.BigEndian.Uint64(item.K[8:]), NewBtree(), NewBtree(), NewBtree())
+		snaps[id] = snap
+	}
+	if len(item.V) == 0 {
+		return
+	}
+	frozen := NewMetaItem(0, nil, nil)
+	if err = frozen.UnmarshalBinary(item.V); err != nil {
+		return
+	}
+	switch frozen.Op {
+	case opFSMCreateInode:
+		ino := NewInode(0, 0)
+		if err = ino.UnmarshalKey(frozen.K); err != nil {
+			return
+		}
+		if err = ino.UnmarshalValue(frozen.V); err != nil {
+			return
+		}
+		snap.view.inodeTree.ReplaceOrInsert(ino, true)
+	case opFSMCreateDentry:
+		dentry := &Dentry{}
+		if err = dentry.UnmarshalKey(frozen.K); err != nil {
+			return
+		}
+		if err = dentry.UnmarshalValue(frozen.V); err != nil {
+			return
+		}
+		snap.view.dentryTree.ReplaceOrInsert(dentry, true)
+	case opFSMSetXAttr:
+		var extend *Extend
+		if extend, err = NewExtendFromBytes(frozen.V); err != nil {
+			return
+		}
+		snap.view.extendTree.ReplaceOrInsert(extend, true)
+	default:
+		return fmt.Errorf("unknown meta snapshot item op=%d", frozen.Op)
+	}
+	return
+}
+
+func (snap *metaSnapshot) String() string {
+	return fmt.Sprintf("metaSnapshot{id(%v) applyID(%v) inodes(%v) dentries(%v)}",
+		snap.id, snap.applyID, snap.view.inodeTree.Len(), snap.view.dentryTree.Len())
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestMetaSnapshot_FrozenInodeAndExtents(t *testing.T) {
+	mp := &metaPartition{
+		config:        &MetaPartitionConfig{PartitionId: 1, VolName: "ltptest", Start: 1, End: 1000},
+		inodeTree:     NewBtree(),
+		dentryTree:    NewBtree(),
+		extendTree:    NewBtree(),
+		extentRefTree: NewBtree(),
+		extDelCh:      make(chan []proto.ExtentKey, 1),
+	}
+	inode := NewInode(10, 0644)
+	inode.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1, Size: 100})
+	inode.Size = 100
+	mp.inodeTree.ReplaceOrInsert(inode, true)
+
+	snap := mp.newMetaSnapshot(1, 1, mp.inodeTree.GetTree(), mp.dentryTree.GetTree(), mp.extendTree.GetTree())
+	mp.metaSnapshots.Store(snap.id, snap)
+
+	// overwrite the whole file in the live tree, the fsm modifies the inode got by CopyGet
+	inode = mp.inodeTree.CopyGet(&Inode{Inode: 10}).(*Inode)
+	delExtents := inode.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 2, Size: 100})
+	if len(delExtents) != 1 {
+		t.Fatalf("expect 1 deleted extent, got %v", delExtents)
+	}
+	if eks := mp.releaseInodeExtents(inode, delExtents); len(eks) != 0 {
+		t.Fatalf("extents referenced by snapshot must be kept, got %v", eks)
+	}
+	if eks := mp.releaseInodeExtents(NewInode(11, 0644), delExtents); len(eks) != 1 {
+		t.Fatalf("extents of unfrozen inode must be deleted, got %v", eks)
+	}
+	if !mp.isMetaSnapshotInode(10) || mp.isMetaSnapshotInode(11) {
+		t.Fatalf("unexpected frozen inodes")
+	}
+
+	frozen := snap.view.inodeTree.Get(&Inode{Inode: 10}).(*Inode)
+	var extentIDs []uint64
+	frozen.Extents.Range(func(ek proto.ExtentKey) bool {
+		extentIDs = append(extentIDs, ek.ExtentId)
+		return true
+	})
+	if len(extentIDs) != 1 || extentIDs[0] != 1 {
+		t.Fatalf("snapshot inode must not be changed by live writes, got extents %v", extentIDs)
+	}
+
+	// the snapshot takes over the extent released by the live inode and deletes it at last
+	if status := mp.fsmDeleteMetaSnapshot(&proto.MetaSnapshotRequest{SnapshotID: snap.id}); status != proto.OpOk {
+		t.Fatalf("delete snapshot fail: status(%v)", status)
+	}
+	if eks := <-mp.extDelCh; len(eks) != 1 || eks[0].ExtentId != 1 {
+		t.Fatalf("extents must be released after snapshot deleted, got %v", eks)
+	}
+}
+
+func TestMetaSnapshot_SharedExtents(t *testing.T) {
+	mp := &metaPartition{
+		config:        &MetaPartitionConfig{PartitionId: 1, VolName: "ltptest", Start: 1, End: 1000},
+		inodeTree:     NewBtree(),
+		dentryTree:    NewBtree(),
+		extendTree:    NewBtree(),
+		extentRefTree: NewBtree(),
+		extDelCh:      make(chan []proto.ExtentKey, 1),
+	}
+	src := NewInode(10, 0644)
+	src.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 100})
+	src.Size = 100
+	mp.inodeTree.ReplaceOrInsert(src, true)
+	if resp := mp.fsmCopyInode(10, NewInode(11, 0)); resp.Status != proto.OpOk {
+		t.Fatalf("copy inode fail: status(%v)", resp.Status)
+	}
+	snap := mp.newMetaSnapshot(1, 1, mp.inodeTree.GetTree(), mp.dentryTree.GetTree(), mp.extendTree.GetTree())
+	mp.metaSnapshots.Store(snap.id, snap)
+
+	// the copy overwrites the shared extent, the frozen copy takes over the reference of the copy
+	inode := mp.inodeTree.CopyGet(&Inode{Inode: 11}).(*Inode)
+	delExtents := inode.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1026, Size: 100})
+	if eks := mp.releaseInodeExtents(inode, delExtents); len(eks) != 0 {
+		t.Fatalf("shared extents must be kept, got %v", eks)
+	}
+	if item := mp.extentRefTree.Get(&ExtentRef{partitionID: 1, extentID: 1025}); item == nil {
+		t.Fatalf("the reference must be kept by the snapshot")
+	}
+
+	// the snapshot releases the reference, and the source still refers the extent
+	if status := mp.fsmDeleteMetaSnapshot(&proto.MetaSnapshotRequest{SnapshotID: snap.id}); status != proto.OpOk {
+		t.Fatalf("delete snapshot fail: status(%v)", status)
+	}
+	if len(mp.extDelCh) != 0 || mp.extentRefTree.Len() != 0 {
+		t.Fatalf("the reference must be consumed: refs(%v) pending(%v)", mp.extentRefTree.Len(), len(mp.extDelCh))
+	}
+	// a partial overwrite keeps the extent, and the remaining pieces are deleted as a whole at last
+	inode = mp.inodeTree.CopyGet(&Inode{Inode: 10}).(*Inode)
+	delExtents = inode.Extents.Append(proto.ExtentKey{FileOffset: 20, PartitionId: 1, ExtentId: 1027, Size: 20})
+	if eks := mp.releaseInodeExtents(inode, delExtents); len(eks) != 0 {
+		t.Fatalf("partly overwritten extents must be kept, got %v", eks)
+	}
+	eks, ready := mp.extentsToDelete(inode, make(map[ExtentRef]uint32))
+	if !ready || len(eks) != 3 {
+		t.Fatalf("extents to delete mismatch: ready(%v) eks(%v)", ready, eks)
+	}
+}
+
+func TestMetaSnapshot_RaftSnapshotItem(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1, VolName: "ltptest", Start: 1, End: 1000},
+		inodeTree:  NewBtree(),
+		dentryTree: NewBtree(),
+		extendTree: NewBtree(),
+	}
+	inode := NewInode(10, 0644)
+	inode.Extents.Append(proto.ExtentKey{FileOffset: 0, PartitionId: 1, ExtentId: 1, Size: 100})
+	inode.Size = 100
+	mp.inodeTree.ReplaceOrInsert(inode, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "a", Inode: 10, Type: 0644}, true)
+	extend := NewExtend(10)
+	extend.Put([]byte("k"), []byte("v"))
+	mp.extendTree.ReplaceOrInsert(extend, true)
+	snap := mp.newMetaSnapshot(3, 7, mp.inodeTree.GetTree(), mp.dentryTree.GetTree(), mp.extendTree.GetTree())
+	empty := mp.newMetaSnapshot(4, 8, NewBtree(), NewBtree(), NewBtree())
+
+	items := []*metaSnapshotItem{{snap: snap}, {snap: empty}}
+	for _, tree := range []*BTree{snap.view.inodeTree, snap.view.dentryTree, snap.view.extendTree} {
+		tree.Ascend(func(i BtreeItem) bool {
+			items = append(items, &metaSnapshotItem{snap: snap, item: i})
+			return true
+		})
+	}
+	snaps := make(map[uint64]*metaSnapshot)
+	for _, si := range items {
+		item, err := si.MetaItem()
+		if err != nil {
+			t.Fatalf("encode item %v: %v", si, err)
+		}
+		raw, err := item.MarshalBinary()
+		if err != nil {
+			t.Fatalf("marshal item: %v", err)
+		}
+		decoded := NewMetaItem(0, nil, nil)
+		if err = decoded.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("unmarshal item: %v", err)
+		}
+		if err = mp.applyMetaSnapshotItem(snaps, decoded); err != nil {
+			t.Fatalf("apply item: %v", err)
+		}
+	}
+	if len(snaps) != 2 || snaps[4] == nil || snaps[4].applyID != 8 || snaps[4].view.inodeTree.Len() != 0 {
+		t.Fatalf("unexpected snapshots %v", snaps)
+	}
+	rebuilt := snaps[3]
+	if rebuilt.applyID != 7 || rebuilt.view.inodeTree.Len() != 1 || rebuilt.view.dentryTree.Len() != 1 ||
+		rebuilt.view.extendTree.Len() != 1 {
+		t.Fatalf("unexpected rebuilt snapshot %v", rebuilt)
+	}
+	frozen := rebuilt.view.inodeTree.Get(&Inode{Inode: 10}).(*Inode)
+	if !inodeHoldsExtent(frozen, &proto.ExtentKey{PartitionId: 1, ExtentId: 1, Size: 100}) {
+		t.Fatalf("extents of the rebuilt snapshot are lost")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+// The meta partition views of the volumes are cached for checking the object lock of the inodes in other
+// partitions, which is done on every deletion of a dentry whose inode belongs to another partition.
+const volViewsExpiration = time.Minute
+
+type volViews struct {
+	views      []*proto.MetaPartitionView
+	updateTime time.Time
+}
+
+var volViewsCache = struct {
+	sync.Mutex
+	vols map[string]*volViews
+}{vols: make(map[string]*volViews)}
+
+// Return the object lock state of the inode, or nil if the inode is not locked by any means.
+func (mp *metaPartition) getObjectLock(ino uint64) *proto.ObjectLock {
+	item := mp.extendTree.Get(NewExtend(ino))
+	if item == nil {
+		return nil
+	}
+	extend := item.(*Extend)
+	var values = make([]string, len(proto.ObjectLockXAttrKeys))
+	var exist bool
+	for i, key := range proto.ObjectLockXAttrKeys {
+		value, ok := extend.Get([]byte(key))
+		values[i] = string(value)
+		exist = exist || ok
+	}
+	if !exist {
+		return nil
+	}
+	return proto.ParseObjectLock(values[0], values[1], values[2])
+}
+
+// Check whether the inode is protected from being deleted or modified by object lock.
+// It is checked before proposing the operations, so that the result is consistent among the replicas.
+func (mp *metaPartition) isObjectLocked(ino uint64) bool {
+	lock := mp.getObjectLock(ino)
+	return lock != nil && lock.Locked(time.Now())
+}
+
+// Check whether the object lock attribute of the inode can be changed to the value, or removed if the value is nil.
+func (mp *metaPartition) allowObjectLockChange(ino uint64, key string, value []byte) bool {
+	if key != proto.XAttrKeyObjectLockMode && key != proto.XAttrKeyObjectLockRetainUntil {
+		return true
+	}
+	lock := mp.getObjectLock(ino)
+	return lock == nil || lock.AllowXAttrChange(key, value, time.Now())
+}
+
+// Check whether the inode of the dentry is protected by object lock, in which case the dentry can not be
+// deleted or replaced unless the inode is retained as a version, otherwise the locked inode is left without any name.
+// The inode may belong to another meta partition of the volume, whose object lock is queried from that partition.
+func (mp *metaPartition) isDentryObjectLocked(parentID uint64, name string) (locked bool, err error) {
+	dentry, status := mp.getDentry(&Dentry{ParentId: parentID, Name: name})
+	if status != proto.OpOk || !proto.IsRegular(dentry.Type) {
+		return false, nil
+	}
+	if dentry.Inode >= mp.config.Start && dentry.Inode <= mp.config.End {
+		return mp.isObjectLocked(dentry.Inode) && !mp.isVersionRetained(dentry.Inode), nil
+	}
+	return mp.isRemoteObjectLocked(dentry.Inode)
+}
+
+func (mp *metaPartition) isVersionRetained(ino uint64) bool {
+	item := mp.extendTree.Get(NewExtend(ino))
+	if item == nil {
+		return false
+	}
+	value, _ := item.(*Extend).Get([]byte(proto.XAttrKeyObjectVersion))
+	return len(value) > 0
+}
+
+// Return the status replied to the request which deletes or replaces the dentry, which is OpNotPerm if the inode
+// of the dentry is locked, or OpAgain if the object lock can not be checked.
+func (mp *metaPartition) dentryObjectLockStatus(parentID uint64, name string) (status uint8, err error) {
+	locked, err := mp.isDentryObjectLocked(parentID, name)
+	if err != nil {
+		return proto.OpAgain, err
+	}
+	if locked {
+		return proto.OpNotPerm, nil
+	}
+	return proto.OpOk, nil
+}
+
+func (mp *metaPartition) isRemoteObjectLocked(ino uint64) (locked bool, err error) {
+	view, err := mp.getInodeMetaPartitionView(ino)
+	if err != nil {
+		return
+	}
+	addr := view.LeaderAddr
+	if addr == "" && len(view.Members) > 0 {
+		// the request will be forwarded to the leader by the member
+		addr = view.Members[0]
+	}
+	if addr == "" {
+		return false, ErrNoLeader
+	}
+	req := &proto.BatchGetXAttrRequest{
+		VolName:     mp.config.VolName,
+		PartitionId: view.PartitionID,
+		Inodes:      []uint64{ino},
+		Keys:        append([]string{proto.XAttrKeyObjectVersion}, proto.ObjectLockXAttrKeys...),
+	}
+	packet, err := mp.sendToMetaPartition(addr, view.PartitionID, proto.OpMetaBatchGetXAttr, req)
+	if err != nil {
+		return
+	}
+	if packet.ResultCode != proto.OpOk {
+		return false, fmt.Errorf("get object lock of inode %v fail: %v", ino, packet.GetResultMsg())
+	}
+	resp := &proto.BatchGetXAttrResponse{}
+	if err = packet.UnmarshalData(resp); err != nil {
+		return
+	}
+	for _, info := range resp.XAttrs {
+		if info.Inode != ino {
+			continue
+		}
+		lock := proto.ParseObjectLock(info.XAttrs[proto.XAttrKeyObjectLockMode],
+			info.XAttrs[proto.XAttrKeyObjectLockRetainUntil], info.XAttrs[proto.XAttrKeyObjectLockLegalHold])
+		return lock.Locked(time.Now()) && len(info.XAttrs[proto.XAttrKeyObjectVersion]) == 0, nil
+	}
+	return false, nil
+}
+
+// Return the view of the meta partition which the inode belongs to, the cached views are refreshed
+// if they are expired or none of them matches the inode.
+func (mp *metaPartition) getInodeMetaPartitionView(ino uint64) (*proto.MetaPartitionView, error) {
+	var match = func(views []*proto.MetaPartitionView) *proto.MetaPartitionView {
+		for _, view := range views {
+			if view.Start <= ino && ino <= view.End {
+				return view
+			}
+		}
+		return nil
+	}
+	volName := mp.config.VolName
+	volViewsCache.Lock()
+	cached := volViewsCache.vols[volName]
+	volViewsCache.Unlock()
+	if cached != nil && time.Since(cached.updateTime) < volViewsExpiration {
+		if view := match(cached.views); view != nil {
+			return view, nil
+		}
+	}
+	views, err := masterClient.ClientAPI().GetMetaPartitions(volName)
+	if err != nil {
+		return nil, err
+	}
+	volViewsCache.Lock()
+	volViewsCache.vols[volName] = &volViews{views: views, updateTime: time.Now()}
+	volViewsCache.Unlock()
+	if view := match(views); view != nil {
+		return view, nil
+	}
+	return nil, fmt.Errorf("no meta partition of inode %v: volume(%v)", ino, volName)
+}
+
+func errBody(err error) []byte {
+	if err == nil {
+		return nil
+	}
+	return []byte(err.Error())
+}
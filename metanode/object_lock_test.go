@@ -0,0 +1,89 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestObjectLock_Enforce(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1, VolName: "ltptest", Start: 1, End: 1000},
+		inodeTree:  NewBtree(),
+		dentryTree: NewBtree(),
+		extendTree: NewBtree(),
+	}
+	var lock = func(ino uint64, xattrs map[string]string) {
+		extend := NewExtend(ino)
+		for key, value := range xattrs {
+			extend.Put([]byte(key), []byte(value))
+		}
+		mp.extendTree.ReplaceOrInsert(extend, true)
+	}
+	var now = time.Now()
+	var future = now.Add(time.Hour).UTC().Format(time.RFC3339)
+	var past = now.Add(-time.Hour).UTC().Format(time.RFC3339)
+	lock(10, map[string]string{proto.XAttrKeyObjectLockMode: proto.ObjectLockModeCompliance, proto.XAttrKeyObjectLockRetainUntil: future})
+	lock(11, map[string]string{proto.XAttrKeyObjectLockMode: proto.ObjectLockModeGovernance, proto.XAttrKeyObjectLockRetainUntil: future})
+	lock(12, map[string]string{proto.XAttrKeyObjectLockMode: proto.ObjectLockModeCompliance, proto.XAttrKeyObjectLockRetainUntil: past})
+	lock(13, map[string]string{proto.XAttrKeyObjectLockLegalHold: proto.ObjectLockLegalHoldOn})
+	lock(14, map[string]string{proto.XAttrKeyObjectLockRetainUntil: future})
+	lock(15, map[string]string{"user-defined": "value"})
+
+	for ino, expect := range map[uint64]bool{10: true, 11: true, 12: false, 13: true, 14: false, 15: false, 16: false} {
+		if locked := mp.isObjectLocked(ino); locked != expect {
+			t.Fatalf("object lock of inode %v mismatch: expect %v, actual %v", ino, expect, locked)
+		}
+	}
+
+	// the dentry of a locked file can not be deleted or replaced
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "locked", Inode: 10, Type: proto.Mode(0644)}, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "unlocked", Inode: 12, Type: proto.Mode(0644)}, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "dir", Inode: 13, Type: proto.Mode(os.ModeDir | 0755)}, true)
+	// the link of the inode retained as a version is owned by the version record
+	lock(17, map[string]string{proto.XAttrKeyObjectLockLegalHold: proto.ObjectLockLegalHoldOn, proto.XAttrKeyObjectVersion: "1"})
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "version", Inode: 17, Type: proto.Mode(0644)}, true)
+	for name, expect := range map[string]uint8{"locked": proto.OpNotPerm, "unlocked": proto.OpOk, "dir": proto.OpOk, "version": proto.OpOk, "missing": proto.OpOk} {
+		if status, err := mp.dentryObjectLockStatus(1, name); err != nil || status != expect {
+			t.Fatalf("object lock status of dentry %v mismatch: expect %v, actual %v, err %v", name, expect, status, err)
+		}
+	}
+
+	// an active compliance retention can only be extended
+	var later = now.Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	if !mp.allowObjectLockChange(10, proto.XAttrKeyObjectLockRetainUntil, []byte(later)) {
+		t.Fatalf("extending compliance retention is rejected")
+	}
+	if mp.allowObjectLockChange(10, proto.XAttrKeyObjectLockRetainUntil, []byte(past)) {
+		t.Fatalf("shortening compliance retention is allowed")
+	}
+	if mp.allowObjectLockChange(10, proto.XAttrKeyObjectLockMode, []byte(proto.ObjectLockModeGovernance)) {
+		t.Fatalf("changing compliance mode is allowed")
+	}
+	if mp.allowObjectLockChange(10, proto.XAttrKeyObjectLockMode, nil) {
+		t.Fatalf("removing compliance retention is allowed")
+	}
+	// governance retention and expired compliance retention can be changed
+	if !mp.allowObjectLockChange(11, proto.XAttrKeyObjectLockMode, nil) {
+		t.Fatalf("removing governance retention is rejected")
+	}
+	if !mp.allowObjectLockChange(12, proto.XAttrKeyObjectLockRetainUntil, []byte(past)) {
+		t.Fatalf("changing expired compliance retention is rejected")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	"fmt"
@@ -108,6 +109,7 @@ func (c *MetaPartitionConfig) sortPeers() {
 // OpInode defines the interface for the inode operations.
 type OpInode interface {
 	CreateInode(req *CreateInoReq, p *Packet) (err error)
+	CopyInode(req *CopyInoReq, p *Packet) (err error)
 	UnlinkInode(req *UnlinkInoReq, p *Packet) (err error)
 	UnlinkInodeBatch(req *BatchUnlinkInoReq, p *Packet) (err error)
 	InodeGet(req *InodeGetReq, p *Packet) (err error)
@@ -144,6 +146,7 @@ type OpDentry interface {
 type OpExtent interface {
 	ExtentAppend(req *proto.AppendExtentKeyRequest, p *Packet) (err error)
 	ExtentsList(req *proto.GetExtentsRequest, p *Packet) (err error)
+	ExtentsReplace(req *proto.ReplaceExtentsRequest, p *Packet) (err error)
 	ExtentsTruncate(req *ExtentsTruncateReq, p *Packet) (err error)
 	BatchExtentAppend(req *proto.AppendExtentKeysRequest, p *Packet) (err error)
 }
@@ -156,6 +159,26 @@ type OpMultipart interface {
 	ListMultipart(req *proto.ListMultipartRequest, p *Packet) (err error)
 }
 
+type OpVersion interface {
+	CreateVersion(req *proto.CreateVersionRequest, p *Packet) (err error)
+	GetVersion(req *proto.GetVersionRequest, p *Packet) (err error)
+	RemoveVersion(req *proto.RemoveVersionRequest, p *Packet) (err error)
+	ListVersions(req *proto.ListVersionsRequest, p *Packet) (err error)
+}
+
+type OpRenameTx interface {
+	TxRenamePrepare(req *proto.TxRenamePrepareRequest, p *Packet) (err error)
+	TxRenameCommit(req *proto.TxRenameCommitRequest, p *Packet) (err error)
+	TxRenameAbort(req *proto.TxRenameAbortRequest, p *Packet) (err error)
+	TxRenameGet(req *proto.TxRenameGetRequest, p *Packet) (err error)
+}
+
+type OpChangeLog interface {
+	AppendChangeLog(req *proto.AppendChangeLogRequest, p *Packet) (err error)
+	ListChangeLogs(req *proto.ListChangeLogsRequest, p *Packet) (err error)
+	RemoveChangeLog(req *proto.RemoveChangeLogRequest, p *Packet) (err error)
+}
+
 // OpMeta defines the interface for the metadata operations.
 type OpMeta interface {
 	OpInode
@@ -164,6 +187,9 @@ type OpMeta interface {
 	OpPartition
 	OpExtend
 	OpMultipart
+	OpVersion
+	OpRenameTx
+	OpChangeLog
 }
 
 // OpPartition defines the interface for the partition operations.
@@ -181,6 +207,10 @@ type OpPartition interface {
 	TryToLeader(groupID uint64) error
 	CanRemoveRaftMember(peer proto.Peer) error
 	IsEquareCreateMetaPartitionRequst(request *proto.CreateMetaPartitionRequest) (err error)
+	GetQuotaUsages() []*proto.QuotaUsage
+	CreateMetaSnapshot(req *proto.MetaSnapshotRequest, p *Packet) (err error)
+	DeleteMetaSnapshot(req *proto.MetaSnapshotRequest, p *Packet) (err error)
+	MetaSnapshotView(snapshotID uint64) (MetaPartition, error)
 }
 
 // MetaPartition defines the interface for the meta partition operations.
@@ -207,6 +237,11 @@ type metaPartition struct {
 	inodeTree              *BTree // btree for inodes
 	extendTree             *BTree // btree for inode extend (XAttr) management
 	multipartTree          *BTree // collection for multipart management
+	versionTree            *BTree // collection for object version history management
+	renameTxTree           *BTree // collection for intent records of rename transactions
+	renameLockTree         *BTree // index of dentries locked by prepared rename transactions
+	extentRefTree          *BTree // collection for references of the extents shared by copied inodes
+	changeLogTree          *BTree // collection for object changes to be replicated
 	raftPartition          raftstore.Partition
 	stopC                  chan bool
 	storeChan              chan *storeMsg
@@ -218,6 +253,8 @@ type metaPartition struct {
 	vol                    *Vol
 	manager                *metadataManager
 	isLoadingMetaPartition bool
+	quotaUsages            atomic.Value // usages of the directory quotas, refreshed by the leader
+	metaSnapshots          sync.Map     // frozen metadata of the volume snapshots indexed by snapshot ID
 }
 
 func (mp *metaPartition) ForceSetMetaPartitionToLoadding() {
@@ -293,6 +330,8 @@ func (mp *metaPartition) onStart() (err error) {
 			mp.config.PartitionId, err.Error())
 		return
 	}
+	go mp.renameTxResolver()
+	go mp.quotaUsageUpdater()
 	return
 }
 
@@ -375,18 +414,23 @@ func (mp *metaPartition) getRaftPort() (heartbeat, replica int, err error) {
 // NewMetaPartition creates a new meta partition with the specified configuration.
 func NewMetaPartition(conf *MetaPartitionConfig, manager *metadataManager) MetaPartition {
 	mp := &metaPartition{
-		config:        conf,
-		dentryTree:    NewBtree(),
-		inodeTree:     NewBtree(),
-		extendTree:    NewBtree(),
-		multipartTree: NewBtree(),
-		stopC:         make(chan bool),
-		storeChan:     make(chan *storeMsg, 100),
-		freeList:      newFreeList(),
-		extDelCh:      make(chan []proto.ExtentKey, 10000),
-		extReset:      make(chan struct{}),
-		vol:           NewVol(),
-		manager:       manager,
+		config:         conf,
+		dentryTree:     NewBtree(),
+		inodeTree:      NewBtree(),
+		extendTree:     NewBtree(),
+		multipartTree:  NewBtree(),
+		versionTree:    NewBtree(),
+		renameTxTree:   NewBtree(),
+		renameLockTree: NewBtree(),
+		extentRefTree:  NewBtree(),
+		changeLogTree:  NewBtree(),
+		stopC:          make(chan bool),
+		storeChan:      make(chan *storeMsg, 100),
+		freeList:       newFreeList(),
+		extDelCh:       make(chan []proto.ExtentKey, 10000),
+		extReset:       make(chan struct{}),
+		vol:            NewVol(),
+		manager:        manager,
 	}
 	return mp
 }
@@ -446,7 +490,22 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 	if err = mp.loadMultipart(snapshotPath); err != nil {
 		return
 	}
-	err = mp.loadApplyID(snapshotPath)
+	if err = mp.loadVersion(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadRenameTx(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadExtentRef(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadChangeLog(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadApplyID(snapshotPath); err != nil {
+		return
+	}
+	err = mp.loadMetaSnapshots()
 	return
 }
 
@@ -467,7 +526,22 @@ func (mp *metaPartition) load() (err error) {
 	if err = mp.loadMultipart(snapshotPath); err != nil {
 		return
 	}
-	err = mp.loadApplyID(snapshotPath)
+	if err = mp.loadVersion(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadRenameTx(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadExtentRef(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadChangeLog(snapshotPath); err != nil {
+		return
+	}
+	if err = mp.loadApplyID(snapshotPath); err != nil {
+		return
+	}
+	err = mp.loadMetaSnapshots()
 	return
 }
 
@@ -494,6 +568,10 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 		mp.storeDentry,
 		mp.storeExtend,
 		mp.storeMultipart,
+		mp.storeVersion,
+		mp.storeRenameTx,
+		mp.storeExtentRef,
+		mp.storeChangeLog,
 	}
 	for _, storeFunc := range storeFuncs {
 		var crc uint32
@@ -508,6 +586,10 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 	if err = mp.storeApplyID(tmpDir, sm); err != nil {
 		return
 	}
+	// the volume snapshots are stored before the apply index takes effect
+	if err = mp.storeMetaSnapshots(sm.metaSnapshots); err != nil {
+		return
+	}
 	// write crc to file
 	if err = ioutil.WriteFile(path.Join(tmpDir, SnapshotSign), crcBuffer.Bytes(), 0775); err != nil {
 		return
@@ -534,8 +616,11 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 		_ = os.Rename(backupDir, snapshotDir)
 		return
 	}
-	err = os.RemoveAll(backupDir)
-	return
+	if err = os.RemoveAll(backupDir); err != nil {
+		return
+	}
+	// the deleted volume snapshots are removed after the apply index takes effect
+	return mp.removeStaleMetaSnapshots(sm.metaSnapshots)
 }
 
 // UpdatePeers updates the peers.
@@ -658,7 +743,7 @@ func (mp *metaPartition) Reset() (err error) {
 	mp.applyID = 0
 
 	// remove files
-	filenames := []string{applyIDFile, dentryFile, inodeFile, extendFile, multipartFile}
+	filenames := []string{applyIDFile, dentryFile, inodeFile, extendFile, multipartFile, versionFile, renameTxFile, extentRefFile, changeLogFile}
 	for _, filename := range filenames {
 		filepath := path.Join(mp.config.RootDir, filename)
 		if err = os.Remove(filepath); err != nil {
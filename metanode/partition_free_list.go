@@ -145,6 +145,11 @@ func (mp *metaPartition) deleteWorker() {
 					continue
 				}
 			}
+			// the extents of the inode are still referenced by the volume snapshots
+			if mp.isMetaSnapshotInode(ino) {
+				delayDeleteInos = append(delayDeleteInos, ino)
+				continue
+			}
 
 			buffSlice = append(buffSlice, ino)
 		}
@@ -219,14 +224,22 @@ func (mp *metaPartition) deleteMarkedInodes(inoSlice []uint64) {
 	allDeleteExtents := make(map[string]uint64)
 	deleteExtentsByPartition := make(map[uint64][]*proto.ExtentKey)
 	allInodes := make([]*Inode, 0)
+	delayInodes := make([]uint64, 0)
+	pendingRefs := make(map[ExtentRef]uint32)
 	for _, ino := range inoSlice {
 		ref := &Inode{Inode: ino}
 		inode, ok := mp.inodeTree.CopyGet(ref).(*Inode)
 		if !ok {
 			continue
 		}
-		inode.Extents.Range(func(ek proto.ExtentKey) bool {
-			ext := &ek
+		// the extents still shared by other copied inodes are excluded
+		eks, ready := mp.extentsToDelete(inode, pendingRefs)
+		if !ready {
+			delayInodes = append(delayInodes, inode.Inode)
+			continue
+		}
+		for i := range eks {
+			ext := &eks[i]
 			_, ok := allDeleteExtents[ext.GetExtentKey()]
 			if !ok {
 				allDeleteExtents[ext.GetExtentKey()] = inode.Inode
@@ -238,10 +251,12 @@ func (mp *metaPartition) deleteMarkedInodes(inoSlice []uint64) {
 			exts = append(exts, ext)
 			log.LogWritef("mp(%v) ino(%v) deleteExtent(%v)", mp.config.PartitionId, inode.Inode, ext.String())
 			deleteExtentsByPartition[ext.PartitionId] = exts
-			return true
-		})
+		}
 		allInodes = append(allInodes, inode)
 	}
+	for _, ino := range delayInodes {
+		mp.freeList.Push(ino)
+	}
 	shouldCommit,shouldRePushToFreeList = mp.batchDeleteExtentsByPartition(deleteExtentsByPartition, allInodes)
 	bufSlice := make([]byte, 0, 8*len(shouldCommit))
 	for _, inode := range shouldCommit {
@@ -269,7 +284,7 @@ func (mp *metaPartition) syncToRaftFollowersFreeInode(hasDeleteInodes []byte) (e
 	if len(hasDeleteInodes)==0 {
 		return
 	}
-	_, err = mp.submit(opFSMInternalDeleteInode, hasDeleteInodes)
+	_, err = mp.submit(opFSMInternalFreeInode, hasDeleteInodes)
 
 	return
 }
@@ -56,6 +56,47 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			mp.config.Cursor = ino.Inode
 		}
 		resp = mp.fsmCreateInode(ino)
+	case opFSMCreateInodeQuota:
+		var quotaIDs string
+		var raw []byte
+		if quotaIDs, raw, err = unmarshalInodeQuota(msg.V); err != nil {
+			return
+		}
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(raw); err != nil {
+			return
+		}
+		if mp.config.Cursor < ino.Inode {
+			mp.config.Cursor = ino.Inode
+		}
+		resp = mp.fsmCreateInodeQuota(ino, quotaIDs)
+	case opFSMCopyInode:
+		if len(msg.V) < 8 {
+			err = fmt.Errorf("copy inode command too short: length(%v)", len(msg.V))
+			return
+		}
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V[8:]); err != nil {
+			return
+		}
+		if mp.config.Cursor < ino.Inode {
+			mp.config.Cursor = ino.Inode
+		}
+		resp = mp.fsmCopyInode(binary.BigEndian.Uint64(msg.V[:8]), ino)
+	case opFSMCopyInodeRange:
+		if len(msg.V) < 24 {
+			err = fmt.Errorf("copy inode range command too short: length(%v)", len(msg.V))
+			return
+		}
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V[24:]); err != nil {
+			return
+		}
+		if mp.config.Cursor < ino.Inode {
+			mp.config.Cursor = ino.Inode
+		}
+		resp = mp.fsmCopyInodeRange(binary.BigEndian.Uint64(msg.V[:8]), binary.BigEndian.Uint64(msg.V[8:16]),
+			binary.BigEndian.Uint64(msg.V[16:24]), ino)
 	case opFSMUnlinkInode:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -140,6 +181,10 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		dentryTree := mp.getDentryTree()
 		extendTree := mp.extendTree.GetTree()
 		multipartTree := mp.multipartTree.GetTree()
+		versionTree := mp.versionTree.GetTree()
+		renameTxTree := mp.renameTxTree.GetTree()
+		extentRefTree := mp.extentRefTree.GetTree()
+		changeLogTree := mp.changeLogTree.GetTree()
 		msg := &storeMsg{
 			command:       opFSMStoreTick,
 			applyIndex:    index,
@@ -147,12 +192,19 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			dentryTree:    dentryTree,
 			extendTree:    extendTree,
 			multipartTree: multipartTree,
+			versionTree:   versionTree,
+			renameTxTree:  renameTxTree,
+			extentRefTree: extentRefTree,
+			changeLogTree: changeLogTree,
+			metaSnapshots: mp.listMetaSnapshots(),
 		}
 		mp.storeChan <- msg
 	case opFSMInternalDeleteInode:
 		err = mp.internalDelete(msg.V)
 	case opFSMInternalDeleteInodeBatch:
 		err = mp.internalDeleteBatch(msg.V)
+	case opFSMInternalFreeInode:
+		err = mp.internalFreeInode(msg.V)
 	case opFSMInternalDelExtentFile:
 		err = mp.delOldExtentFile(msg.V)
 	case opFSMInternalDelExtentCursor:
@@ -181,6 +233,48 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		var multipart *Multipart
 		multipart = MultipartFromBytes(msg.V)
 		resp = mp.fsmAppendMultipart(multipart)
+	case opFSMCreateVersion:
+		var version *Version
+		version = VersionFromBytes(msg.V)
+		resp = mp.fsmCreateVersion(version)
+	case opFSMRemoveVersion:
+		var version *Version
+		version = VersionFromBytes(msg.V)
+		resp = mp.fsmRemoveVersion(version)
+	case opFSMAppendChangeLog:
+		var changeLog *ChangeLog
+		changeLog = ChangeLogFromBytes(msg.V)
+		resp = mp.fsmAppendChangeLog(changeLog)
+	case opFSMRemoveChangeLog:
+		var changeLog *ChangeLog
+		changeLog = ChangeLogFromBytes(msg.V)
+		resp = mp.fsmRemoveChangeLog(changeLog)
+	case opFSMTxRenamePrepare:
+		resp = mp.fsmTxRenamePrepare(RenameTxFromBytes(msg.V))
+	case opFSMTxRenameCommit:
+		resp = mp.fsmTxRenameCommit(RenameTxFromBytes(msg.V))
+	case opFSMTxRenameAbort:
+		resp = mp.fsmTxRenameAbort(RenameTxFromBytes(msg.V))
+	case opFSMTxRenameRemove:
+		resp = mp.fsmTxRenameRemove(RenameTxFromBytes(msg.V))
+	case opFSMCreateMetaSnapshot:
+		req := &proto.MetaSnapshotRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmCreateMetaSnapshot(req, index)
+	case opFSMDeleteMetaSnapshot:
+		req := &proto.MetaSnapshotRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmDeleteMetaSnapshot(req)
+	case opFSMReplaceExtents:
+		req := &proto.ReplaceExtentsRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmReplaceExtents(req)
 	case opFSMSyncCursor:
 		var cursor uint64
 		cursor = binary.BigEndian.Uint64(msg.V)
@@ -249,6 +343,11 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 		dentryTree    = NewBtree()
 		extendTree    = NewBtree()
 		multipartTree = NewBtree()
+		versionTree   = NewBtree()
+		renameTxTree  = NewBtree()
+		extentRefTree = NewBtree()
+		changeLogTree = NewBtree()
+		metaSnapshots = make(map[uint64]*metaSnapshot)
 	)
 	defer func() {
 		if err == io.EOF {
@@ -257,6 +356,18 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			mp.dentryTree = dentryTree
 			mp.extendTree = extendTree
 			mp.multipartTree = multipartTree
+			mp.versionTree = versionTree
+			mp.renameTxTree = renameTxTree
+			mp.renameLockTree = buildRenameTxLockTree(renameTxTree)
+			mp.extentRefTree = extentRefTree
+			mp.changeLogTree = changeLogTree
+			mp.rangeMetaSnapshots(func(snap *metaSnapshot) bool {
+				mp.metaSnapshots.Delete(snap.id)
+				return true
+			})
+			for id, snap := range metaSnapshots {
+				mp.metaSnapshots.Store(id, snap)
+			}
 			mp.config.Cursor = cursor
 			err = nil
 			// store message
@@ -267,6 +378,11 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				dentryTree:    mp.dentryTree,
 				extendTree:    mp.extendTree,
 				multipartTree: mp.multipartTree,
+				versionTree:   mp.versionTree,
+				renameTxTree:  mp.renameTxTree,
+				extentRefTree: mp.extentRefTree,
+				changeLogTree: mp.changeLogTree,
+				metaSnapshots: mp.listMetaSnapshots(),
 			}
 			mp.extReset <- struct{}{}
 			log.LogDebugf("ApplySnapshot: finish with EOF: partitionID(%v) applyID(%v)", mp.config.PartitionId, mp.applyID)
@@ -323,6 +439,31 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			var multipart = MultipartFromBytes(snap.V)
 			multipartTree.ReplaceOrInsert(multipart, true)
 			log.LogDebugf("ApplySnapshot: create multipart: partitionID(%v) multipart(%v)", mp.config.PartitionId, multipart)
+		case opFSMCreateVersion:
+			var version = VersionFromBytes(snap.V)
+			versionTree.ReplaceOrInsert(version, true)
+			log.LogDebugf("ApplySnapshot: create version: partitionID(%v) version(%v)", mp.config.PartitionId, version)
+		case opFSMTxRenamePrepare:
+			var tx = RenameTxFromBytes(snap.V)
+			renameTxTree.ReplaceOrInsert(tx, true)
+			log.LogDebugf("ApplySnapshot: create rename transaction: partitionID(%v) txID(%v) role(%v)",
+				mp.config.PartitionId, tx.id, tx.role)
+		case opExtentRefSnapshot:
+			var ref *ExtentRef
+			if ref, err = ExtentRefFromBytes(snap.V); err != nil {
+				return
+			}
+			extentRefTree.ReplaceOrInsert(ref, true)
+			log.LogDebugf("ApplySnapshot: create extent reference: partitionID(%v) ref(%v)", mp.config.PartitionId, ref)
+		case opFSMAppendChangeLog:
+			var changeLog = ChangeLogFromBytes(snap.V)
+			changeLogTree.ReplaceOrInsert(changeLog, true)
+			log.LogDebugf("ApplySnapshot: create change log: partitionID(%v) id(%v) path(%v)",
+				mp.config.PartitionId, changeLog.id, changeLog.key)
+		case opMetaSnapshotItem:
+			if err = mp.applyMetaSnapshotItem(metaSnapshots, snap); err != nil {
+				return
+			}
 		case opExtentFileSnapshot:
 			fileName := string(snap.K)
 			fileName = path.Join(mp.config.RootDir, fileName)
@@ -53,6 +53,10 @@ func (mp *metaPartition) fsmCreateDentry(dentry *Dentry,
 			status = proto.OpArgMismatchErr
 			return
 		}
+		if mp.isDentryLocked(dentry.ParentId, dentry.Name) {
+			status = proto.OpAgain
+			return
+		}
 	}
 	if item, ok := mp.dentryTree.ReplaceOrInsert(dentry, false); !ok {
 		//do not allow directories and files to overwrite each
@@ -95,6 +99,10 @@ func (mp *metaPartition) fsmDeleteDentry(dentry *Dentry, checkInode bool) (
 	resp *DentryResponse) {
 	resp = NewDentryResponse()
 	resp.Status = proto.OpOk
+	if mp.isDentryLocked(dentry.ParentId, dentry.Name) {
+		resp.Status = proto.OpAgain
+		return
+	}
 
 	var item interface{}
 	if checkInode {
@@ -144,6 +152,10 @@ func (mp *metaPartition) fsmUpdateDentry(dentry *Dentry) (
 	resp *DentryResponse) {
 	resp = NewDentryResponse()
 	resp.Status = proto.OpOk
+	if mp.isDentryLocked(dentry.ParentId, dentry.Name) {
+		resp.Status = proto.OpAgain
+		return
+	}
 	mp.dentryTree.CopyFind(dentry, func(item BtreeItem) {
 		if item == nil {
 			resp.Status = proto.OpNotExistErr
@@ -164,6 +176,7 @@ func (mp *metaPartition) readDir(req *ReadDirReq) (resp *ReadDirResp) {
 	resp = &ReadDirResp{}
 	begDentry := &Dentry{
 		ParentId: req.ParentID,
+		Name:     req.Marker,
 	}
 	endDentry := &Dentry{
 		ParentId: req.ParentID + 1,
@@ -175,7 +188,7 @@ func (mp *metaPartition) readDir(req *ReadDirReq) (resp *ReadDirResp) {
 			Type:  d.Type,
 			Name:  d.Name,
 		})
-		return true
+		return req.Limit == 0 || uint64(len(resp.Children)) < req.Limit
 	})
 	return
 }
@@ -18,9 +18,11 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/storage"
 	"github.com/chubaofs/chubaofs/util/log"
 )
 
@@ -42,6 +44,68 @@ func (mp *metaPartition) fsmCreateInode(ino *Inode) (status uint8) {
 	return
 }
 
+// Create the inode as a copy of the source inode, which refers the same extents instead of copying the data.
+func (mp *metaPartition) fsmCopyInode(src uint64, ino *Inode) (resp *InodeResponse) {
+	return mp.fsmCopyInodeRange(src, 0, 0, ino)
+}
+
+// Create the inode as a copy of the source inode in range [offset, offset+size), which refers the extents
+// trimmed to the range. The whole source inode is copied if the size is zero.
+func (mp *metaPartition) fsmCopyInodeRange(src, offset, size uint64, ino *Inode) (resp *InodeResponse) {
+	resp = NewInodeResponse()
+	resp.Status = proto.OpOk
+	item := mp.inodeTree.Get(&Inode{Inode: src})
+	if item == nil {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	i := item.(*Inode)
+	if i.ShouldDelete() {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	if !proto.IsRegular(i.Type) {
+		resp.Status = proto.OpArgMismatchErr
+		return
+	}
+	i.DoReadFunc(func() {
+		if size > 0 && offset+size > i.Size {
+			resp.Status = proto.OpArgMismatchErr
+			return
+		}
+		ino.Type = i.Type
+		ino.Uid = i.Uid
+		ino.Gid = i.Gid
+		if size > 0 {
+			ino.Size = size
+			ino.Extents = i.Extents.Slice(offset, size)
+		} else {
+			ino.Size = i.Size
+			ino.Extents = i.Extents.Clone()
+		}
+	})
+	if resp.Status != proto.OpOk {
+		return
+	}
+	// the ranges of the tiny extents are deleted separately and are never shared, the caller copies the data instead
+	var tiny bool
+	ino.Extents.Range(func(ek proto.ExtentKey) bool {
+		tiny = storage.IsTinyExtent(ek.ExtentId)
+		return !tiny
+	})
+	if tiny {
+		resp.Status = proto.OpArgMismatchErr
+		return
+	}
+	if _, ok := mp.inodeTree.ReplaceOrInsert(ino, false); !ok {
+		resp.Status = proto.OpExistErr
+		return
+	}
+	mp.retainExtentRefs(ino.Extents.CopyExtents())
+	resp.Msg = ino
+	return
+}
+
 func (mp *metaPartition) fsmCreateLinkInode(ino *Inode) (resp *InodeResponse) {
 	resp = NewInodeResponse()
 	resp.Status = proto.OpOk
@@ -175,6 +239,34 @@ func (mp *metaPartition) internalDelete(val []byte) (err error) {
 	}
 }
 
+// Free the inodes whose extents have been deleted by the delete worker, and consume the references
+// of the extents which are still shared by other inodes and have not been deleted.
+func (mp *metaPartition) internalFreeInode(val []byte) (err error) {
+	if len(val) == 0 {
+		return
+	}
+	buf := bytes.NewBuffer(val)
+	ino := NewInode(0, 0)
+	for {
+		err = binary.Read(buf, binary.BigEndian, &ino.Inode)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+				return
+			}
+			return
+		}
+		log.LogDebugf("internalFreeInode: received internal free: partitionID(%v) inode(%v)",
+			mp.config.PartitionId, ino.Inode)
+		if item := mp.inodeTree.Get(ino); item != nil {
+			for _, ref := range sharedExtentRefs(item.(*Inode).Extents.CopyExtents()) {
+				mp.releaseExtentRef(ref)
+			}
+		}
+		mp.internalDeleteInode(ino)
+	}
+}
+
 func (mp *metaPartition) internalDeleteBatch(val []byte) error {
 	if len(val) == 0 {
 		return nil
@@ -214,11 +306,76 @@ func (mp *metaPartition) fsmAppendExtents(ino *Inode) (status uint8) {
 	}
 	eks := ino.Extents.CopyExtents()
 	delExtents := ino2.AppendExtents(eks, ino.ModifyTime)
+	delExtents = mp.releaseInodeExtents(ino2, delExtents)
 	log.LogInfof("fsmAppendExtents inode(%v) exts(%v)", ino2.Inode, delExtents)
 	mp.extDelCh <- delExtents
 	return
 }
 
+// Replace the extents of the inode by the migrated extents if the extents are not changed since the migration
+// read them, and release the replaced extents. The inode is marked as being migrated by the request to prepare.
+func (mp *metaPartition) fsmReplaceExtents(req *proto.ReplaceExtentsRequest) (status uint8) {
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	ino := item.(*Inode)
+	if ino.ShouldDelete() {
+		return proto.OpNotExistErr
+	}
+	mark := NewExtend(req.Inode)
+	if req.Prepare {
+		mark.Put([]byte(proto.MigratingXAttrKey), []byte(strconv.FormatInt(req.MarkTime, 10)))
+		mp.fsmSetXAttr(mark)
+		return proto.OpOk
+	}
+
+	var replaced bool
+	ino.DoWriteFunc(func() {
+		eks := ino.Extents.CopyExtents()
+		if len(eks) != len(req.OldExtents) {
+			return
+		}
+		for i := range eks {
+			if eks[i] != req.OldExtents[i] {
+				return
+			}
+		}
+		extents := NewSortedExtents()
+		for _, ek := range req.NewExtents {
+			extents.Append(ek)
+		}
+		ino.Extents = extents
+		ino.Generation++
+		replaced = true
+	})
+	if !replaced {
+		log.LogWarnf("fsmReplaceExtents: extents changed during migration, inode(%v)", req.Inode)
+		return proto.OpArgMismatchErr
+	}
+	mark.Put([]byte(proto.MigratingXAttrKey), nil)
+	mp.fsmRemoveXAttr(mark)
+
+	delExtents := mp.releaseInodeExtents(ino, req.OldExtents)
+	log.LogInfof("fsmReplaceExtents inode(%v) exts(%v)", ino.Inode, delExtents)
+	mp.extDelCh <- delExtents
+	return proto.OpOk
+}
+
+// Check whether the inode is marked as being migrated and the mark has not expired.
+func (mp *metaPartition) isMigratingInode(ino uint64) bool {
+	item := mp.extendTree.Get(NewExtend(ino))
+	if item == nil {
+		return false
+	}
+	value, ok := item.(*Extend).Get([]byte(proto.MigratingXAttrKey))
+	if !ok {
+		return false
+	}
+	markTime, err := strconv.ParseInt(string(value), 10, 64)
+	return err == nil && time.Now().Unix()-markTime < proto.MigratingMarkTTL
+}
+
 func (mp *metaPartition) fsmExtentsTruncate(ino *Inode) (resp *InodeResponse) {
 	resp = NewInodeResponse()
 
@@ -241,6 +398,7 @@ func (mp *metaPartition) fsmExtentsTruncate(ino *Inode) (resp *InodeResponse) {
 	delExtents := i.ExtentsTruncate(ino.Size, ino.ModifyTime)
 
 	// now we should delete the extent
+	delExtents = mp.releaseInodeExtents(i, delExtents)
 	log.LogInfof("fsmExtentsTruncate inode(%v) exts(%v)", i.Inode, delExtents)
 	mp.extDelCh <- delExtents
 	return
@@ -0,0 +1,87 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package metanode
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/storage"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Freeze the metadata of the partition at the apply index. The trees are cloned copy-on-write,
+// so nothing is copied or written in the apply. The frozen trees are stored by the store tick
+// of an apply index no less than this one, before which the request is replayed after the restart.
+func (mp *metaPartition) fsmCreateMetaSnapshot(req *proto.MetaSnapshotRequest, applyID uint64) (status uint8) {
+	if _, ok := mp.getMetaSnapshot(req.SnapshotID); ok {
+		return proto.OpOk
+	}
+	snap := mp.newMetaSnapshot(req.SnapshotID, applyID,
+		mp.inodeTree.GetTree(), mp.dentryTree.GetTree(), mp.extendTree.GetTree())
+	mp.metaSnapshots.Store(snap.id, snap)
+	log.LogInfof("fsmCreateMetaSnapshot: partitionID(%v) snapshot(%v)", mp.config.PartitionId, snap)
+	return proto.OpOk
+}
+
+// Delete the frozen metadata and release the extents which are only referenced by the snapshot.
+// The extents are released the same way as the inode releases them, except that the extents still
+// referred by the live inode are kept. The stored trees are removed by the next store tick.
+func (mp *metaPartition) fsmDeleteMetaSnapshot(req *proto.MetaSnapshotRequest) (status uint8) {
+	snap, ok := mp.getMetaSnapshot(req.SnapshotID)
+	if !ok {
+		return proto.OpOk
+	}
+	mp.metaSnapshots.Delete(snap.id)
+
+	var delExtents []proto.ExtentKey
+	snap.view.inodeTree.Ascend(func(i BtreeItem) bool {
+		ino := i.(*Inode)
+		var live *Inode
+		if item := mp.inodeTree.Get(ino); item != nil {
+			live = item.(*Inode)
+		}
+		delExtents = append(delExtents, mp.releaseFrozenExtents(ino, live, snap.id)...)
+		return true
+	})
+	log.LogInfof("fsmDeleteMetaSnapshot: partitionID(%v) snapshot(%v) released extents(%v)",
+		mp.config.PartitionId, snap, len(delExtents))
+	if len(delExtents) > 0 {
+		mp.extDelCh <- delExtents
+	}
+	return proto.OpOk
+}
+
+// Return the extent keys to delete among the extents of the frozen inode of the deleted snapshot.
+func (mp *metaPartition) releaseFrozenExtents(frozen, live *Inode, snapshotID uint64) []proto.ExtentKey {
+	eks := frozen.Extents.CopyExtents()
+	released := make([]proto.ExtentKey, 0, len(eks))
+	seen := make(map[ExtentRef]struct{}, len(eks))
+	for i := range eks {
+		ek := &eks[i]
+		if !storage.IsTinyExtent(ek.ExtentId) {
+			ref := newExtentRef(ek)
+			if _, ok := seen[*ref]; ok {
+				continue
+			}
+			seen[*ref] = struct{}{}
+		}
+		if live != nil && inodeHoldsExtent(live, ek) || mp.isMetaSnapshotExtent(frozen.Inode, ek, snapshotID) {
+			continue
+		}
+		if !storage.IsTinyExtent(ek.ExtentId) && mp.releaseExtentRef(newExtentRef(ek)) {
+			continue
+		}
+		released = append(released, *ek)
+	}
+	return released
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// isDentryLocked checks whether the specified dentry is locked by a prepared rename transaction.
+func (mp *metaPartition) isDentryLocked(parentID uint64, name string) bool {
+	return mp.renameLockTree.Has(&renameTxLock{parentID: parentID, name: name})
+}
+
+// setRenameTx stores the transaction record and keeps the index of locked dentries in step with it.
+func (mp *metaPartition) setRenameTx(tx *RenameTx) {
+	if tx.state == proto.TxRenameStatePrepared {
+		mp.renameLockTree.ReplaceOrInsert(newRenameTxLock(tx), true)
+	} else {
+		mp.unlockRenameTx(tx)
+	}
+	mp.renameTxTree.ReplaceOrInsert(tx, true)
+}
+
+// deleteRenameTx removes the transaction record and the dentry lock held by it.
+func (mp *metaPartition) deleteRenameTx(tx *RenameTx) {
+	mp.unlockRenameTx(tx)
+	mp.renameTxTree.Delete(tx)
+}
+
+func (mp *metaPartition) unlockRenameTx(tx *RenameTx) {
+	lock := newRenameTxLock(tx)
+	if item := mp.renameLockTree.Get(lock); item != nil {
+		if held := item.(*renameTxLock); held.txID == tx.id && held.role == tx.role {
+			mp.renameLockTree.Delete(lock)
+		}
+	}
+}
+
+// checkRenameDst checks whether the source inode can be applied to the destination dentry,
+// and returns the parent inode of the destination dentry.
+func (mp *metaPartition) checkRenameDst(tx *RenameTx) (parIno *Inode, status uint8) {
+	item := mp.inodeTree.CopyGet(NewInode(tx.dstParent, 0))
+	if item == nil {
+		return nil, proto.OpNotExistErr
+	}
+	parIno = item.(*Inode)
+	if parIno.ShouldDelete() {
+		return nil, proto.OpNotExistErr
+	}
+	if !proto.IsDir(parIno.Type) {
+		return nil, proto.OpArgMismatchErr
+	}
+	if item = mp.dentryTree.Get(&Dentry{ParentId: tx.dstParent, Name: tx.dstName}); item != nil {
+		d := item.(*Dentry)
+		// do not allow directories and files to overwrite each other
+		if proto.OsModeType(d.Type) != proto.OsModeType(tx.mode) {
+			return nil, proto.OpArgMismatchErr
+		}
+		// Note that only regular files are allowed to be overwritten.
+		if !proto.IsRegular(tx.mode) {
+			return nil, proto.OpExistErr
+		}
+	}
+	return parIno, proto.OpOk
+}
+
+// fsmTxRenamePrepare records the intent of a rename transaction and locks the dentry of this participant.
+// The destination participant reserves a link of the destination parent, so that the parent
+// can not be removed before the transaction finishes.
+func (mp *metaPartition) fsmTxRenamePrepare(tx *RenameTx) (status uint8) {
+	if item := mp.renameTxTree.Get(tx); item != nil {
+		if item.(*RenameTx).state == proto.TxRenameStatePrepared {
+			return proto.OpOk
+		}
+		return proto.OpNotPerm
+	}
+	parentID, name := tx.lockedDentry()
+	if mp.isDentryLocked(parentID, name) {
+		return proto.OpAgain
+	}
+	switch tx.role {
+	case proto.TxRenameRoleSrc:
+		item := mp.dentryTree.Get(&Dentry{ParentId: parentID, Name: name})
+		if item == nil || item.(*Dentry).Inode != tx.inode {
+			return proto.OpNotExistErr
+		}
+	case proto.TxRenameRoleDst:
+		var parIno *Inode
+		if parIno, status = mp.checkRenameDst(tx); status != proto.OpOk {
+			return
+		}
+		parIno.IncNLink()
+	default:
+		return proto.OpArgMismatchErr
+	}
+	tx.state = proto.TxRenameStatePrepared
+	mp.setRenameTx(tx)
+	return proto.OpOk
+}
+
+// fsmTxRenameCommit applies the prepared rename transaction to the dentry of this participant.
+// Committing the destination participant is the decision point of the whole transaction, and the
+// source dentry is kept until then, so that the file is always reachable by one of its names.
+func (mp *metaPartition) fsmTxRenameCommit(key *RenameTx) (status uint8) {
+	item := mp.renameTxTree.Get(key)
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	prepared := item.(*RenameTx)
+	switch prepared.state {
+	case proto.TxRenameStateCommitted:
+		return proto.OpOk
+	case proto.TxRenameStateAborted:
+		return proto.OpNotPerm
+	}
+
+	// unlock the dentry before applying the transaction
+	tx := prepared.Copy().(*RenameTx)
+	tx.state = proto.TxRenameStateCommitted
+	mp.setRenameTx(tx)
+
+	if tx.role == proto.TxRenameRoleSrc {
+		resp := mp.fsmDeleteDentry(&Dentry{ParentId: tx.srcParent, Name: tx.srcName, Inode: tx.inode}, true)
+		if status = resp.Status; status != proto.OpOk {
+			log.LogErrorf("fsmTxRenameCommit: delete source dentry fail: partitionID(%v) txID(%v) status(%v)",
+				mp.config.PartitionId, tx.id, status)
+			mp.setRenameTx(prepared)
+			return
+		}
+		// the destination has been committed, nothing is left to resolve for the source
+		mp.deleteRenameTx(tx)
+		return
+	}
+
+	var parIno *Inode
+	if item := mp.inodeTree.CopyGet(NewInode(tx.dstParent, 0)); item != nil {
+		parIno = item.(*Inode)
+	}
+	dentry := &Dentry{ParentId: tx.dstParent, Name: tx.dstName, Inode: tx.inode, Type: tx.mode}
+	// the link of parent has been reserved while preparing
+	status = mp.fsmCreateDentry(dentry, true)
+	if status == proto.OpExistErr {
+		resp := mp.fsmUpdateDentry(dentry)
+		if status = resp.Status; status == proto.OpOk {
+			tx.oldInode = resp.Msg.Inode
+			if parIno != nil {
+				parIno.DecNLink()
+			}
+		}
+	}
+	if status != proto.OpOk {
+		log.LogErrorf("fsmTxRenameCommit: apply destination dentry fail: partitionID(%v) txID(%v) status(%v)",
+			mp.config.PartitionId, tx.id, status)
+		mp.setRenameTx(prepared)
+		return
+	}
+	if parIno != nil {
+		parIno.SetMtime()
+	}
+
+	if tx.oldInode != 0 && tx.oldInode >= mp.config.Start && tx.oldInode <= mp.config.End {
+		// evict old inode to avoid it becomes orphan inode
+		mp.fsmUnlinkInode(NewInode(tx.oldInode, 0))
+		mp.fsmEvictInode(NewInode(tx.oldInode, 0))
+		tx.oldInode = 0
+	}
+	// The record is retained until the source has been committed, and the old inode which belongs
+	// to other partition will be released by the resolver.
+	mp.setRenameTx(tx)
+	return
+}
+
+// fsmTxRenameAbort aborts the rename transaction on this participant. An aborted record is kept as a
+// tombstone, so that a delayed prepare request of the same transaction will be rejected.
+func (mp *metaPartition) fsmTxRenameAbort(key *RenameTx) (status uint8) {
+	var prepared *RenameTx
+	if item := mp.renameTxTree.Get(key); item != nil {
+		prepared = item.(*RenameTx)
+	}
+	if prepared == nil {
+		tombstone := key.Copy().(*RenameTx)
+		tombstone.state = proto.TxRenameStateAborted
+		mp.renameTxTree.ReplaceOrInsert(tombstone, true)
+		return proto.OpOk
+	}
+	switch prepared.state {
+	case proto.TxRenameStateCommitted:
+		return proto.OpNotPerm
+	case proto.TxRenameStateAborted:
+		return proto.OpOk
+	}
+	if prepared.role == proto.TxRenameRoleDst {
+		// release the link of parent reserved while preparing
+		mp.inodeTree.CopyFind(NewInode(prepared.dstParent, 0), func(item BtreeItem) {
+			if item != nil {
+				item.(*Inode).DecNLink()
+			}
+		})
+	}
+	tx := prepared.Copy().(*RenameTx)
+	tx.state = proto.TxRenameStateAborted
+	tx.createTime = key.createTime
+	mp.setRenameTx(tx)
+	return proto.OpOk
+}
+
+// fsmTxRenameRemove removes the record of a finished rename transaction.
+func (mp *metaPartition) fsmTxRenameRemove(key *RenameTx) (status uint8) {
+	item := mp.renameTxTree.Get(key)
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	if item.(*RenameTx).state == proto.TxRenameStatePrepared {
+		return proto.OpNotPerm
+	}
+	mp.deleteRenameTx(item.(*RenameTx))
+	return proto.OpOk
+}
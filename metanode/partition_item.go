@@ -129,6 +129,11 @@ type MetaItemIterator struct {
 	dentryTree    *BTree
 	extendTree    *BTree
 	multipartTree *BTree
+	versionTree   *BTree
+	renameTxTree  *BTree
+	extentRefTree *BTree
+	changeLogTree *BTree
+	metaSnapshots []*metaSnapshot
 
 	filenames []string
 
@@ -148,6 +153,11 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 	si.dentryTree = mp.dentryTree.GetTree()
 	si.extendTree = mp.extendTree.GetTree()
 	si.multipartTree = mp.multipartTree.GetTree()
+	si.versionTree = mp.versionTree.GetTree()
+	si.renameTxTree = mp.renameTxTree.GetTree()
+	si.extentRefTree = mp.extentRefTree.GetTree()
+	si.changeLogTree = mp.changeLogTree.GetTree()
+	si.metaSnapshots = mp.listMetaSnapshots()
 	si.dataCh = make(chan interface{})
 	si.errorCh = make(chan error, 1)
 	si.closeCh = make(chan struct{})
@@ -228,6 +238,48 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 		if checkClose() {
 			return
 		}
+		// process versions
+		iter.versionTree.Ascend(func(i BtreeItem) bool {
+			return produceItem(i)
+		})
+		if checkClose() {
+			return
+		}
+		// process rename transactions
+		iter.renameTxTree.Ascend(func(i BtreeItem) bool {
+			return produceItem(i)
+		})
+		if checkClose() {
+			return
+		}
+		// process extent references
+		iter.extentRefTree.Ascend(func(i BtreeItem) bool {
+			return produceItem(i)
+		})
+		if checkClose() {
+			return
+		}
+		// process change logs
+		iter.changeLogTree.Ascend(func(i BtreeItem) bool {
+			return produceItem(i)
+		})
+		if checkClose() {
+			return
+		}
+		// process volume snapshots, the frozen trees never change
+		for _, snap := range iter.metaSnapshots {
+			if !produceItem(&metaSnapshotItem{snap: snap}) {
+				return
+			}
+			for _, tree := range []*BTree{snap.view.inodeTree, snap.view.dentryTree, snap.view.extendTree} {
+				tree.Ascend(func(i BtreeItem) bool {
+					return produceItem(&metaSnapshotItem{snap: snap, item: i})
+				})
+				if checkClose() {
+					return
+				}
+			}
+		}
 		// process extent del files
 		var err error
 		var raw []byte
@@ -309,6 +361,44 @@ func (si *MetaItemIterator) Next() (data []byte, err error) {
 			return
 		}
 		snap = NewMetaItem(opFSMCreateMultipart, nil, raw)
+	case *Version:
+		var raw []byte
+		if raw, err = typedItem.Bytes(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+		snap = NewMetaItem(opFSMCreateVersion, nil, raw)
+	case *RenameTx:
+		var raw []byte
+		if raw, err = typedItem.Bytes(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+		snap = NewMetaItem(opFSMTxRenamePrepare, nil, raw)
+	case *ExtentRef:
+		var raw []byte
+		if raw, err = typedItem.Bytes(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+		snap = NewMetaItem(opExtentRefSnapshot, nil, raw)
+	case *ChangeLog:
+		var raw []byte
+		if raw, err = typedItem.Bytes(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+		snap = NewMetaItem(opFSMAppendChangeLog, nil, raw)
+	case *metaSnapshotItem:
+		if snap, err = typedItem.MetaItem(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
 	case *fileData:
 		snap = NewMetaItem(opExtentFileSnapshot, []byte(typedItem.filename), typedItem.data)
 	default:
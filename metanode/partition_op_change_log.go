@@ -0,0 +1,112 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func (mp *metaPartition) AppendChangeLog(req *proto.AppendChangeLogRequest, p *Packet) (err error) {
+	var now = time.Now()
+	var changeLogId string
+	for seq := uint32(0); ; seq++ {
+		changeLogId = newChangeLogID(now, seq)
+		if storedItem := mp.changeLogTree.Get(&ChangeLog{id: changeLogId}); storedItem == nil {
+			break
+		}
+	}
+
+	changeLog := &ChangeLog{
+		id:         changeLogId,
+		op:         req.Op,
+		key:        req.Path,
+		createTime: now,
+	}
+	var resp interface{}
+	if resp, err = mp.putChangeLog(opFSMAppendChangeLog, changeLog); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	if status := resp.(uint8); status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
+func (mp *metaPartition) ListChangeLogs(req *proto.ListChangeLogsRequest, p *Packet) (err error) {
+	max := int(req.Max)
+	var matches = make([]*proto.ChangeLogInfo, 0, max)
+	var walkTreeFunc = func(i BtreeItem) bool {
+		changeLog := i.(*ChangeLog)
+		if changeLog.id == req.Marker {
+			// the marker itself has been consumed
+			return true
+		}
+		matches = append(matches, &proto.ChangeLogInfo{
+			PartitionId: mp.config.PartitionId,
+			ID:          changeLog.id,
+			Op:          changeLog.op,
+			Path:        changeLog.key,
+			CreateTime:  changeLog.createTime,
+		})
+		return !(len(matches) >= max)
+	}
+	if len(req.Marker) > 0 {
+		mp.changeLogTree.AscendGreaterOrEqual(&ChangeLog{id: req.Marker}, walkTreeFunc)
+	} else {
+		mp.changeLogTree.Ascend(walkTreeFunc)
+	}
+
+	var reply []byte
+	if reply, err = json.Marshal(&proto.ListChangeLogsResponse{ChangeLogs: matches}); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+func (mp *metaPartition) RemoveChangeLog(req *proto.RemoveChangeLogRequest, p *Packet) (err error) {
+	changeLog := &ChangeLog{
+		id: req.ID,
+	}
+	var resp interface{}
+	if resp, err = mp.putChangeLog(opFSMRemoveChangeLog, changeLog); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	status := resp.(uint8)
+	if status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
+// putChangeLog replicate specified change log operation to raft.
+func (mp *metaPartition) putChangeLog(op uint32, changeLog *ChangeLog) (resp interface{}, err error) {
+	var encoded []byte
+	if encoded, err = changeLog.Bytes(); err != nil {
+		return
+	}
+	resp, err = mp.submit(op, encoded)
+	return
+}
@@ -28,6 +28,10 @@ func (mp *metaPartition) CreateDentry(req *CreateDentryReq, p *Packet) (err erro
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
 		return
 	}
+	if mp.isQuotaExceeded(req.ParentID) {
+		p.PacketErrorWithBody(proto.OpQuotaExceededErr, nil)
+		return
+	}
 
 	dentry := &Dentry{
 		ParentId: req.ParentID,
@@ -45,11 +49,23 @@ func (mp *metaPartition) CreateDentry(req *CreateDentryReq, p *Packet) (err erro
 		return
 	}
 	p.ResultCode = resp.(uint8)
+	if p.ResultCode == proto.OpOk {
+		var reply []byte
+		m := &CreateDentryResp{
+			QuotaIDs: mp.getQuotaIDs(req.ParentID),
+		}
+		reply, err = json.Marshal(m)
+		p.PacketOkWithBody(reply)
+	}
 	return
 }
 
 // DeleteDentry deletes a dentry.
 func (mp *metaPartition) DeleteDentry(req *DeleteDentryReq, p *Packet) (err error) {
+	if status, lockErr := mp.dentryObjectLockStatus(req.ParentID, req.Name); status != proto.OpOk {
+		p.PacketErrorWithBody(status, errBody(lockErr))
+		return
+	}
 	dentry := &Dentry{
 		ParentId: req.ParentID,
 		Name:     req.Name,
@@ -82,8 +98,14 @@ func (mp *metaPartition) DeleteDentry(req *DeleteDentryReq, p *Packet) (err erro
 func (mp *metaPartition) DeleteDentryBatch(req *BatchDeleteDentryReq, p *Packet) (err error) {
 
 	db := make(DentryBatch, 0, len(req.Dens))
+	var locked []*DentryResponse
 
 	for _, d := range req.Dens {
+		if status, _ := mp.dentryObjectLockStatus(req.ParentID, d.Name); status != proto.OpOk {
+			// the dentries of the locked inodes are not deleted
+			locked = append(locked, &DentryResponse{Status: status, Msg: &Dentry{ParentId: req.ParentID, Name: d.Name, Inode: d.Inode}})
+			continue
+		}
 		db = append(db, &Dentry{
 			ParentId: req.ParentID,
 			Name:     d.Name,
@@ -92,18 +114,21 @@ func (mp *metaPartition) DeleteDentryBatch(req *BatchDeleteDentryReq, p *Packet)
 		})
 	}
 
-	val, err := db.Marshal()
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
-		return
-	}
-	r, err := mp.submit(opFSMDeleteDentryBatch, val)
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
-		return err
+	var retMsg []*DentryResponse
+	if len(db) > 0 {
+		var val []byte
+		if val, err = db.Marshal(); err != nil {
+			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+			return
+		}
+		var r interface{}
+		if r, err = mp.submit(opFSMDeleteDentryBatch, val); err != nil {
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			return err
+		}
+		retMsg = r.([]*DentryResponse)
 	}
-
-	retMsg := r.([]*DentryResponse)
+	retMsg = append(retMsg, locked...)
 	p.ResultCode = proto.OpOk
 
 	bddr := &BatchDeleteDentryResp{}
@@ -149,6 +174,10 @@ func (mp *metaPartition) UpdateDentry(req *UpdateDentryReq, p *Packet) (err erro
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
 		return
 	}
+	if status, lockErr := mp.dentryObjectLockStatus(req.ParentID, req.Name); status != proto.OpOk {
+		p.PacketErrorWithBody(status, errBody(lockErr))
+		return
+	}
 
 	dentry := &Dentry{
 		ParentId: req.ParentID,
@@ -170,7 +199,8 @@ func (mp *metaPartition) UpdateDentry(req *UpdateDentryReq, p *Packet) (err erro
 	if msg.Status == proto.OpOk {
 		var reply []byte
 		m := &UpdateDentryResp{
-			Inode: msg.Msg.Inode,
+			Inode:    msg.Msg.Inode,
+			QuotaIDs: mp.getQuotaIDs(req.ParentID),
 		}
 		reply, err = json.Marshal(m)
 		p.PacketOkWithBody(reply)
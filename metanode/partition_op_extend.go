@@ -21,6 +21,10 @@ import (
 )
 
 func (mp *metaPartition) SetXAttr(req *proto.SetXAttrRequest, p *Packet) (err error) {
+	if !mp.allowObjectLockChange(req.Inode, req.Key, []byte(req.Value)) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
 	var extend = NewExtend(req.Inode)
 	extend.Put([]byte(req.Key), []byte(req.Value))
 	if _, err = mp.putExtend(opFSMSetXAttr, extend); err != nil {
@@ -87,6 +91,10 @@ func (mp *metaPartition) BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packe
 }
 
 func (mp *metaPartition) RemoveXAttr(req *proto.RemoveXAttrRequest, p *Packet) (err error) {
+	if !mp.allowObjectLockChange(req.Inode, req.Key, nil) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
 	var extend = NewExtend(req.Inode)
 	extend.Put([]byte(req.Key), nil)
 	if _, err = mp.putExtend(opFSMRemoveXAttr, extend); err != nil {
@@ -17,12 +17,21 @@ package metanode
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/chubaofs/chubaofs/proto"
 )
 
 // ExtentAppend appends an extent.
 func (mp *metaPartition) ExtentAppend(req *proto.AppendExtentKeyRequest, p *Packet) (err error) {
+	if mp.isObjectLocked(req.Inode) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
+	if mp.isQuotaExceeded(req.Inode) {
+		p.PacketErrorWithBody(proto.OpQuotaExceededErr, nil)
+		return
+	}
 	ino := NewInode(req.Inode, 0)
 	ext := req.Extent
 	ino.Extents.Append(ext)
@@ -59,6 +68,9 @@ func (mp *metaPartition) ExtentsList(req *proto.GetExtentsRequest, p *Packet) (e
 				return true
 			})
 		})
+		// the locked inode is written to new extents, which are rejected by appending the extent keys
+		resp.CopyOnWrite = mp.isSharedInode(ino.Inode, resp.Extents) || mp.isMigratingInode(ino.Inode) ||
+			mp.isObjectLocked(ino.Inode)
 		reply, err = json.Marshal(resp)
 		if err != nil {
 			status = proto.OpErr
@@ -69,8 +81,42 @@ func (mp *metaPartition) ExtentsList(req *proto.GetExtentsRequest, p *Packet) (e
 	return
 }
 
+// ExtentsReplace marks the inode as being migrated, or replaces its extents by the migrated extents.
+func (mp *metaPartition) ExtentsReplace(req *proto.ReplaceExtentsRequest, p *Packet) (err error) {
+	if !req.Prepare && extentsEnd(req.OldExtents) != extentsEnd(req.NewExtents) {
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte("new extents mismatch old extents"))
+		return
+	}
+	req.MarkTime = time.Now().Unix()
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMReplaceExtents, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	p.PacketErrorWithBody(resp.(uint8), nil)
+	return
+}
+
+func extentsEnd(eks []proto.ExtentKey) (end uint64) {
+	for _, ek := range eks {
+		if ekEnd := ek.FileOffset + uint64(ek.Size); ekEnd > end {
+			end = ekEnd
+		}
+	}
+	return
+}
+
 // ExtentsTruncate truncates an extent.
 func (mp *metaPartition) ExtentsTruncate(req *ExtentsTruncateReq, p *Packet) (err error) {
+	if mp.isObjectLocked(req.Inode) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
 	ino := NewInode(req.Inode, proto.Mode(os.ModePerm))
 	ino.Size = req.Size
 	val, err := ino.Marshal()
@@ -89,6 +135,14 @@ func (mp *metaPartition) ExtentsTruncate(req *ExtentsTruncateReq, p *Packet) (er
 }
 
 func (mp *metaPartition) BatchExtentAppend(req *proto.AppendExtentKeysRequest, p *Packet) (err error) {
+	if mp.isObjectLocked(req.Inode) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
+	if mp.isQuotaExceeded(req.Inode) {
+		p.PacketErrorWithBody(proto.OpQuotaExceededErr, nil)
+		return
+	}
 	ino := NewInode(req.Inode, 0)
 	extents := req.Extents
 	for _, extent := range extents {
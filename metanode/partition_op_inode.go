@@ -47,6 +47,10 @@ func replyInfo(info *proto.InodeInfo, ino *Inode) bool {
 
 // CreateInode returns a new inode.
 func (mp *metaPartition) CreateInode(req *CreateInoReq, p *Packet) (err error) {
+	if exceededQuotas.isExceeded(mp.config.VolName, append(req.QuotaIDs, proto.RootQuotaID)) {
+		p.PacketErrorWithBody(proto.OpQuotaExceededErr, nil)
+		return
+	}
 	inoID, err := mp.nextInodeID()
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpInodeFullErr, []byte(err.Error()))
@@ -61,7 +65,12 @@ func (mp *metaPartition) CreateInode(req *CreateInoReq, p *Packet) (err error) {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	resp, err := mp.submit(opFSMCreateInode, val)
+	var op = opFSMCreateInode
+	if len(req.QuotaIDs) > 0 {
+		// the inode is tagged with the quotas in the same command, so it is accounted as soon as it exists
+		op, val = opFSMCreateInodeQuota, marshalInodeQuota(proto.QuotaIDsToXAttr(req.QuotaIDs), val)
+	}
+	resp, err := mp.submit(op, val)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return
@@ -87,8 +96,69 @@ func (mp *metaPartition) CreateInode(req *CreateInoReq, p *Packet) (err error) {
 	return
 }
 
+// CopyInode creates a new inode which refers the extents of the specified inode without copying the data.
+// If the size of the request is not zero, only the extents in the requested range are referred.
+func (mp *metaPartition) CopyInode(req *CopyInoReq, p *Packet) (err error) {
+	if exceededQuotas.isExceeded(mp.config.VolName, []uint32{proto.RootQuotaID}) {
+		p.PacketErrorWithBody(proto.OpQuotaExceededErr, nil)
+		return
+	}
+	inoID, err := mp.nextInodeID()
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpInodeFullErr, []byte(err.Error()))
+		return
+	}
+	ino := NewInode(inoID, 0)
+	raw, err := ino.Marshal()
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	var (
+		op  uint32
+		val []byte
+	)
+	if req.Size == 0 {
+		op = opFSMCopyInode
+		val = make([]byte, 8+len(raw))
+		binary.BigEndian.PutUint64(val[:8], req.Inode)
+		copy(val[8:], raw)
+	} else {
+		op = opFSMCopyInodeRange
+		val = make([]byte, 24+len(raw))
+		binary.BigEndian.PutUint64(val[:8], req.Inode)
+		binary.BigEndian.PutUint64(val[8:16], req.Offset)
+		binary.BigEndian.PutUint64(val[16:24], req.Size)
+		copy(val[24:], raw)
+	}
+	r, err := mp.submit(op, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	msg := r.(*InodeResponse)
+	status := msg.Status
+	var reply []byte
+	if status == proto.OpOk {
+		resp := &CopyInoResp{
+			Info: &proto.InodeInfo{},
+		}
+		replyInfo(resp.Info, msg.Msg)
+		if reply, err = json.Marshal(resp); err != nil {
+			status = proto.OpErr
+			reply = []byte(err.Error())
+		}
+	}
+	p.PacketErrorWithBody(status, reply)
+	return
+}
+
 // DeleteInode deletes an inode.
 func (mp *metaPartition) UnlinkInode(req *UnlinkInoReq, p *Packet) (err error) {
+	if mp.isObjectLocked(req.Inode) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
 	ino := NewInode(req.Inode, 0)
 	val, err := ino.Marshal()
 	if err != nil {
@@ -125,25 +195,38 @@ func (mp *metaPartition) UnlinkInodeBatch(req *BatchUnlinkInoReq, p *Packet) (er
 	}
 
 	var inodes InodeBatch
+	var locked []uint64
 
 	for _, id := range req.Inodes {
+		if mp.isObjectLocked(id) {
+			locked = append(locked, id)
+			continue
+		}
 		inodes = append(inodes, NewInode(id, 0))
 	}
 
-	val, err := inodes.Marshal()
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
-		return
+	var responses []*InodeResponse
+	if len(inodes) > 0 {
+		var val []byte
+		if val, err = inodes.Marshal(); err != nil {
+			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+			return
+		}
+		var r interface{}
+		if r, err = mp.submit(opFSMUnlinkInodeBatch, val); err != nil {
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			return
+		}
+		responses = r.([]*InodeResponse)
 	}
-	r, err := mp.submit(opFSMUnlinkInodeBatch, val)
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
-		return
+	// the locked inodes are not unlinked
+	for _, id := range locked {
+		responses = append(responses, &InodeResponse{Status: proto.OpNotPerm, Msg: NewInode(id, 0)})
 	}
 
 	result := &BatchUnlinkInoResp{}
 	status := proto.OpOk
-	for _, ir := range r.([]*InodeResponse) {
+	for _, ir := range responses {
 		if ir.Status != proto.OpOk {
 			status = ir.Status
 		}
@@ -321,6 +404,10 @@ func (mp *metaPartition) GetInodeTree() *BTree {
 }
 
 func (mp *metaPartition) DeleteInode(req *proto.DeleteInodeRequest, p *Packet) (err error) {
+	if mp.isObjectLocked(req.Inode) {
+		p.PacketErrorWithBody(proto.OpNotPerm, nil)
+		return
+	}
 	var bytes = make([]byte, 8)
 	binary.BigEndian.PutUint64(bytes, req.Inode)
 	_, err = mp.submit(opFSMInternalDeleteInode, bytes)
@@ -338,10 +425,19 @@ func (mp *metaPartition) DeleteInodeBatch(req *proto.DeleteInodeBatchRequest, p
 	}
 
 	var inodes InodeBatch
+	var status = proto.OpOk
 
 	for _, id := range req.Inodes {
+		if mp.isObjectLocked(id) {
+			status = proto.OpNotPerm
+			continue
+		}
 		inodes = append(inodes, NewInode(id, 0))
 	}
+	if len(inodes) == 0 {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
 
 	encoded, err := inodes.Marshal()
 	if err != nil {
@@ -353,6 +449,6 @@ func (mp *metaPartition) DeleteInodeBatch(req *proto.DeleteInodeBatchRequest, p
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return
 	}
-	p.PacketOkReply()
+	p.PacketErrorWithBody(status, nil)
 	return
 }
@@ -0,0 +1,63 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+// CreateMetaSnapshot freezes the metadata of the partition for the volume snapshot.
+func (mp *metaPartition) CreateMetaSnapshot(req *proto.MetaSnapshotRequest, p *Packet) (err error) {
+	return mp.submitMetaSnapshot(opFSMCreateMetaSnapshot, req, p)
+}
+
+// DeleteMetaSnapshot deletes the frozen metadata of the volume snapshot.
+func (mp *metaPartition) DeleteMetaSnapshot(req *proto.MetaSnapshotRequest, p *Packet) (err error) {
+	return mp.submitMetaSnapshot(opFSMDeleteMetaSnapshot, req, p)
+}
+
+func (mp *metaPartition) submitMetaSnapshot(op uint32, req *proto.MetaSnapshotRequest, p *Packet) (err error) {
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(op, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	if status := resp.(uint8); status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
+// MetaSnapshotView returns the read-only partition serving the metadata frozen by the volume snapshot,
+// or the partition itself if the snapshot ID is 0.
+func (mp *metaPartition) MetaSnapshotView(snapshotID uint64) (MetaPartition, error) {
+	if snapshotID == 0 {
+		return mp, nil
+	}
+	snap, ok := mp.getMetaSnapshot(snapshotID)
+	if !ok {
+		return nil, fmt.Errorf("snapshot(%v) of partition(%v) not found", snapshotID, mp.config.PartitionId)
+	}
+	return snap.view, nil
+}
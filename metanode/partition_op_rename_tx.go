@@ -0,0 +1,375 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// A rename across meta partitions is performed as a transaction with two participants, the source
+// partition which holds the source dentry and the destination partition which holds the destination dentry.
+// The client prepares the destination and the source, then commits the destination and the source in order.
+// Once the destination has been committed, the transaction must be rolled forward, otherwise it can be rolled back.
+// The source dentry is kept until the destination has been committed, so the file never loses all of its names.
+// Transactions abandoned by clients are resolved by the leader of the participants.
+const (
+	renameTxResolveInterval = 30 * time.Second
+	renameTxTimeout         = 2 * 60  // seconds, prepared transactions older than this are considered abandoned
+	renameTxTombstoneTTL    = 60 * 60 // seconds, aborted records are kept to reject delayed requests
+)
+
+func (mp *metaPartition) TxRenamePrepare(req *proto.TxRenamePrepareRequest, p *Packet) (err error) {
+	tx := &RenameTx{
+		id:         req.TxID,
+		role:       req.Role,
+		srcPid:     req.SrcPartitionID,
+		dstPid:     req.DstPartitionID,
+		srcParent:  req.SrcParentID,
+		srcName:    req.SrcName,
+		dstParent:  req.DstParentID,
+		dstName:    req.DstName,
+		inode:      req.Inode,
+		mode:       req.Mode,
+		createTime: time.Now().Unix(),
+	}
+	if tx.role == proto.TxRenameRoleDst {
+		// the destination dentry of a locked inode can not be replaced
+		if status, lockErr := mp.dentryObjectLockStatus(tx.dstParent, tx.dstName); status != proto.OpOk {
+			p.PacketErrorWithBody(status, errBody(lockErr))
+			return
+		}
+	}
+	return mp.submitRenameTxPacket(opFSMTxRenamePrepare, tx, p)
+}
+
+func (mp *metaPartition) TxRenameCommit(req *proto.TxRenameCommitRequest, p *Packet) (err error) {
+	tx := &RenameTx{
+		id:   req.TxID,
+		role: req.Role,
+	}
+	return mp.submitRenameTxPacket(opFSMTxRenameCommit, tx, p)
+}
+
+func (mp *metaPartition) TxRenameAbort(req *proto.TxRenameAbortRequest, p *Packet) (err error) {
+	tx := &RenameTx{
+		id:         req.TxID,
+		role:       req.Role,
+		createTime: time.Now().Unix(),
+	}
+	return mp.submitRenameTxPacket(opFSMTxRenameAbort, tx, p)
+}
+
+func (mp *metaPartition) TxRenameGet(req *proto.TxRenameGetRequest, p *Packet) (err error) {
+	item := mp.renameTxTree.Get(&RenameTx{id: req.TxID, role: req.Role})
+	if item == nil {
+		p.PacketErrorWithBody(proto.OpNotExistErr, nil)
+		return
+	}
+	tx := item.(*RenameTx)
+	var reply []byte
+	if reply, err = json.Marshal(&proto.TxRenameGetResponse{State: tx.state, OldInode: tx.oldInode}); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+func (mp *metaPartition) submitRenameTxPacket(op uint32, tx *RenameTx, p *Packet) (err error) {
+	var status uint8
+	if status, err = mp.putRenameTx(op, tx); err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	if status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
+// putRenameTx replicate specified rename transaction operation to raft.
+func (mp *metaPartition) putRenameTx(op uint32, tx *RenameTx) (status uint8, err error) {
+	var encoded []byte
+	if encoded, err = tx.Bytes(); err != nil {
+		return
+	}
+	var resp interface{}
+	if resp, err = mp.submit(op, encoded); err != nil {
+		return
+	}
+	status = resp.(uint8)
+	return
+}
+
+func (mp *metaPartition) renameTxResolver() {
+	t := time.NewTicker(renameTxResolveInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-mp.stopC:
+			log.LogDebugf("renameTxResolver: stop partition: partitionID(%v)", mp.config.PartitionId)
+			return
+		case <-t.C:
+		}
+		if _, isLeader := mp.IsLeader(); !isLeader {
+			continue
+		}
+		mp.resolveRenameTxs()
+	}
+}
+
+func (mp *metaPartition) resolveRenameTxs() {
+	var txs = make([]*RenameTx, 0)
+	var now = time.Now().Unix()
+	mp.renameTxTree.Ascend(func(i BtreeItem) bool {
+		tx := i.(*RenameTx)
+		var expired bool
+		switch {
+		case tx.state == proto.TxRenameStateAborted:
+			expired = now-tx.createTime > renameTxTombstoneTTL
+		case tx.state == proto.TxRenameStateCommitted && tx.role == proto.TxRenameRoleDst:
+			expired = true
+		default:
+			expired = now-tx.createTime > renameTxTimeout
+		}
+		if expired {
+			txs = append(txs, tx.Copy().(*RenameTx))
+		}
+		return true
+	})
+	for _, tx := range txs {
+		if err := mp.resolveRenameTx(tx); err != nil {
+			log.LogWarnf("resolveRenameTxs: resolve rename transaction fail: partitionID(%v) txID(%v) role(%v) state(%v) err(%v)",
+				mp.config.PartitionId, tx.id, tx.role, tx.state, err)
+		}
+	}
+}
+
+func (mp *metaPartition) resolveRenameTx(tx *RenameTx) (err error) {
+	if tx.state == proto.TxRenameStateAborted || (tx.state == proto.TxRenameStateCommitted && tx.role == proto.TxRenameRoleSrc) {
+		return mp.finishRenameTx(opFSMTxRenameRemove, tx)
+	}
+
+	var peerState uint8
+	if peerState, err = mp.getPeerRenameTxState(tx); err != nil {
+		return
+	}
+	log.LogInfof("resolveRenameTx: resolve rename transaction: partitionID(%v) txID(%v) role(%v) state(%v) peerState(%v)",
+		mp.config.PartitionId, tx.id, tx.role, tx.state, peerState)
+
+	if tx.role == proto.TxRenameRoleDst {
+		if tx.state == proto.TxRenameStateCommitted {
+			// roll forward the source, the record will be removed in next round
+			if peerState == proto.TxRenameStatePrepared {
+				return mp.sendToRenameTxPeer(tx, proto.OpMetaTxRenameCommit)
+			}
+			if peerState == proto.TxRenameStateAborted {
+				return fmt.Errorf("source aborted after destination committed")
+			}
+			// the record of source is removed once it has been committed
+			if tx.oldInode != 0 {
+				if err = mp.releaseRenameTxOldInode(tx); err != nil {
+					return
+				}
+			}
+			return mp.finishRenameTx(opFSMTxRenameRemove, tx)
+		}
+		// the destination decides the transaction, so abort itself before the source
+		if err = mp.finishRenameTx(opFSMTxRenameAbort, tx); err != nil {
+			return
+		}
+		return mp.sendToRenameTxPeer(tx, proto.OpMetaTxRenameAbort)
+	}
+
+	if peerState == proto.TxRenameStateCommitted {
+		return mp.finishRenameTx(opFSMTxRenameCommit, tx)
+	}
+	// abort the destination first, which fails if the destination has been committed in the meantime
+	if err = mp.sendToRenameTxPeer(tx, proto.OpMetaTxRenameAbort); err != nil {
+		return
+	}
+	return mp.finishRenameTx(opFSMTxRenameAbort, tx)
+}
+
+func (mp *metaPartition) finishRenameTx(op uint32, tx *RenameTx) (err error) {
+	var key = &RenameTx{
+		id:         tx.id,
+		role:       tx.role,
+		createTime: time.Now().Unix(),
+	}
+	var status uint8
+	if status, err = mp.putRenameTx(op, key); err != nil {
+		return
+	}
+	if status != proto.OpOk && status != proto.OpNotExistErr {
+		return fmt.Errorf("status(%v)", status)
+	}
+	return
+}
+
+// releaseRenameTxOldInode releases the inode replaced by the destination dentry, which belongs to other partition.
+func (mp *metaPartition) releaseRenameTxOldInode(tx *RenameTx) (err error) {
+	var partitionID uint64
+	var addr string
+	if partitionID, addr, err = mp.lookupVolMetaPartition(func(view *proto.MetaPartitionView) bool {
+		return tx.oldInode >= view.Start && tx.oldInode <= view.End
+	}); err != nil {
+		return
+	}
+	var packet *proto.Packet
+	unlinkReq := &proto.UnlinkInodeRequest{
+		VolName:     mp.config.VolName,
+		PartitionID: partitionID,
+		Inode:       tx.oldInode,
+	}
+	if packet, err = mp.sendToMetaPartition(addr, partitionID, proto.OpMetaUnlinkInode, unlinkReq); err != nil {
+		return
+	}
+	if packet.ResultCode != proto.OpOk && packet.ResultCode != proto.OpNotExistErr {
+		return fmt.Errorf("unlink inode(%v) fail: %v", tx.oldInode, packet.GetResultMsg())
+	}
+	// evict old inode to avoid it becomes orphan inode
+	evictReq := &proto.EvictInodeRequest{
+		VolName:     mp.config.VolName,
+		PartitionID: partitionID,
+		Inode:       tx.oldInode,
+	}
+	if packet, err = mp.sendToMetaPartition(addr, partitionID, proto.OpMetaEvictInode, evictReq); err != nil {
+		return
+	}
+	if packet.ResultCode != proto.OpOk && packet.ResultCode != proto.OpNotExistErr {
+		return fmt.Errorf("evict inode(%v) fail: %v", tx.oldInode, packet.GetResultMsg())
+	}
+	return
+}
+
+// getPeerRenameTxState returns the state of the transaction on the other participant,
+// or zero if the other participant has no record of it.
+func (mp *metaPartition) getPeerRenameTxState(tx *RenameTx) (state uint8, err error) {
+	peerPid, peerRole := tx.peer()
+	var addr string
+	if _, addr, err = mp.lookupVolMetaPartition(func(view *proto.MetaPartitionView) bool {
+		return view.PartitionID == peerPid
+	}); err != nil {
+		return
+	}
+	req := &proto.TxRenameGetRequest{
+		VolName:     mp.config.VolName,
+		PartitionID: peerPid,
+		TxID:        tx.id,
+		Role:        peerRole,
+	}
+	var packet *proto.Packet
+	if packet, err = mp.sendToMetaPartition(addr, peerPid, proto.OpMetaTxRenameGet, req); err != nil {
+		return
+	}
+	switch packet.ResultCode {
+	case proto.OpOk:
+	case proto.OpNotExistErr:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("get peer transaction fail: %v", packet.GetResultMsg())
+	}
+	resp := &proto.TxRenameGetResponse{}
+	if err = packet.UnmarshalData(resp); err != nil {
+		return
+	}
+	return resp.State, nil
+}
+
+// sendToRenameTxPeer sends commit or abort request of the transaction to the other participant.
+func (mp *metaPartition) sendToRenameTxPeer(tx *RenameTx, opcode uint8) (err error) {
+	peerPid, peerRole := tx.peer()
+	var addr string
+	if _, addr, err = mp.lookupVolMetaPartition(func(view *proto.MetaPartitionView) bool {
+		return view.PartitionID == peerPid
+	}); err != nil {
+		return
+	}
+	var req interface{}
+	switch opcode {
+	case proto.OpMetaTxRenameCommit:
+		req = &proto.TxRenameCommitRequest{VolName: mp.config.VolName, PartitionID: peerPid, TxID: tx.id, Role: peerRole}
+	default:
+		req = &proto.TxRenameAbortRequest{VolName: mp.config.VolName, PartitionID: peerPid, TxID: tx.id, Role: peerRole}
+	}
+	var packet *proto.Packet
+	if packet, err = mp.sendToMetaPartition(addr, peerPid, opcode, req); err != nil {
+		return
+	}
+	if packet.ResultCode != proto.OpOk && packet.ResultCode != proto.OpNotExistErr {
+		return fmt.Errorf("%v fail: %v", packet.GetOpMsg(), packet.GetResultMsg())
+	}
+	return
+}
+
+// lookupVolMetaPartition returns the ID and the leader address of the first meta partition
+// of the volume which matches the specified condition.
+func (mp *metaPartition) lookupVolMetaPartition(match func(view *proto.MetaPartitionView) bool) (partitionID uint64, addr string, err error) {
+	var views []*proto.MetaPartitionView
+	if views, err = masterClient.ClientAPI().GetMetaPartitions(mp.config.VolName); err != nil {
+		return
+	}
+	for _, view := range views {
+		if !match(view) {
+			continue
+		}
+		addr = view.LeaderAddr
+		if addr == "" && len(view.Members) > 0 {
+			// the request will be forwarded to the leader by the member
+			addr = view.Members[0]
+		}
+		if addr == "" {
+			return 0, "", ErrNoLeader
+		}
+		return view.PartitionID, addr, nil
+	}
+	return 0, "", fmt.Errorf("no matched meta partition: volume(%v)", mp.config.VolName)
+}
+
+func (mp *metaPartition) sendToMetaPartition(addr string, partitionID uint64, opcode uint8, req interface{}) (packet *proto.Packet, err error) {
+	packet = proto.NewPacketReqID()
+	packet.Opcode = opcode
+	packet.PartitionID = partitionID
+	if err = packet.MarshalData(req); err != nil {
+		return
+	}
+	var conn *net.TCPConn
+	if conn, err = mp.config.ConnPool.GetConnect(addr); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			mp.config.ConnPool.PutConnect(conn, ForceClosedConnect)
+		} else {
+			mp.config.ConnPool.PutConnect(conn, NoClosedConnect)
+		}
+	}()
+	if err = packet.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = packet.ReadFromConn(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	return
+}
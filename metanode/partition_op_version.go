@@ -0,0 +1,162 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util"
+)
+
+func convertVersionInfo(version *Version) *proto.VersionInfo {
+	return &proto.VersionInfo{
+		ID:           version.id,
+		Path:         version.key,
+		Inode:        version.inode,
+		Size:         version.size,
+		ETag:         version.etag,
+		ModifyTime:   version.modifyTime,
+		DeleteMarker: version.deleteMarker,
+		Null:         util.IsNullVersionID(version.id),
+	}
+}
+
+func (mp *metaPartition) CreateVersion(req *proto.CreateVersionRequest, p *Packet) (err error) {
+	var versionId string
+	var modifyTime = req.ModifyTime
+	if modifyTime.IsZero() {
+		modifyTime = time.Now()
+	}
+	for {
+		// Version ID shares the same layout with multipart ID, which carries the meta partition ID.
+		// It is ordered by the modify time, so that versions of the same key are stored from the newest.
+		versionId = util.CreateVersionID(mp.config.PartitionId, modifyTime, req.Null).String()
+		storedItem := mp.versionTree.Get(&Version{key: req.Path, id: versionId})
+		if storedItem == nil {
+			break
+		}
+	}
+
+	version := &Version{
+		id:           versionId,
+		key:          req.Path,
+		inode:        req.Inode,
+		size:         req.Size,
+		etag:         req.ETag,
+		modifyTime:   modifyTime,
+		deleteMarker: req.DeleteMarker,
+	}
+	var resp interface{}
+	if resp, err = mp.putVersion(opFSMCreateVersion, version); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	if status := resp.(uint8); status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+
+	var reply []byte
+	if reply, err = json.Marshal(&proto.CreateVersionResponse{Info: convertVersionInfo(version)}); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+func (mp *metaPartition) GetVersion(req *proto.GetVersionRequest, p *Packet) (err error) {
+	item := mp.versionTree.Get(&Version{key: req.Path, id: req.VersionId})
+	if item == nil {
+		p.PacketErrorWithBody(proto.OpNotExistErr, nil)
+		return
+	}
+	var reply []byte
+	if reply, err = json.Marshal(&proto.GetVersionResponse{Info: convertVersionInfo(item.(*Version))}); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+func (mp *metaPartition) RemoveVersion(req *proto.RemoveVersionRequest, p *Packet) (err error) {
+	version := &Version{
+		id:  req.VersionId,
+		key: req.Path,
+	}
+	var resp interface{}
+	if resp, err = mp.putVersion(opFSMRemoveVersion, version); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	status := resp.(uint8)
+	if status != proto.OpOk {
+		p.PacketErrorWithBody(status, nil)
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
+// ListVersions lists versions in the order of key and the newest version first. The listing starts
+// after the version ID marker of the key marker, or after all versions of the key marker if the
+// version ID marker is not specified.
+func (mp *metaPartition) ListVersions(req *proto.ListVersionsRequest, p *Packet) (err error) {
+	max := int(req.Max)
+	prefix := req.Prefix
+	var matches = make([]*proto.VersionInfo, 0, max)
+	var start = &Version{key: prefix}
+	if len(req.Marker) > 0 && req.Marker >= prefix {
+		start = &Version{key: req.Marker, id: req.VersionIdMarker}
+		if len(req.VersionIdMarker) == 0 {
+			start.key = req.Marker + "\x00"
+		}
+	}
+	var walkTreeFunc = func(i BtreeItem) bool {
+		version := i.(*Version)
+		if len(prefix) > 0 && !strings.HasPrefix(version.key, prefix) {
+			// versions are ordered by key, so there is no more matches
+			return false
+		}
+		if version.key == req.Marker && version.id == req.VersionIdMarker {
+			return true
+		}
+		matches = append(matches, convertVersionInfo(version))
+		return !(len(matches) >= max)
+	}
+	mp.versionTree.AscendGreaterOrEqual(start, walkTreeFunc)
+
+	var reply []byte
+	if reply, err = json.Marshal(&proto.ListVersionsResponse{Versions: matches}); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+// putVersion replicate specified version operation to raft.
+func (mp *metaPartition) putVersion(op uint32, version *Version) (resp interface{}, err error) {
+	var encoded []byte
+	if encoded, err = version.Bytes(); err != nil {
+		return
+	}
+	resp, err = mp.submit(op, encoded)
+	return
+}
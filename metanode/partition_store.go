@@ -42,6 +42,10 @@ const (
 	dentryFile      = "dentry"
 	extendFile      = "extend"
 	multipartFile   = "multipart"
+	versionFile     = "version"
+	renameTxFile    = "renametx"
+	extentRefFile   = "extentref"
+	changeLogFile   = "changelog"
 	applyIDFile     = "apply"
 	SnapshotSign    = ".sign"
 	metadataFile    = "meta"
@@ -295,6 +299,175 @@ func (mp *metaPartition) loadMultipart(rootDir string) error {
 	return nil
 }
 
+func (mp *metaPartition) loadVersion(rootDir string) error {
+	var err error
+	filename := path.Join(rootDir, versionFile)
+	if _, err = os.Stat(filename); err != nil {
+		return nil
+	}
+	fp, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+	var mem mmap.MMap
+	if mem, err = mmap.Map(fp, mmap.RDONLY, 0); err != nil {
+		return err
+	}
+	defer func() {
+		_ = mem.Unmap()
+	}()
+	var offset, n int
+	// read number of versions
+	var numVersions uint64
+	numVersions, n = binary.Uvarint(mem)
+	offset += n
+	for i := uint64(0); i < numVersions; i++ {
+		// read length
+		var numBytes uint64
+		numBytes, n = binary.Uvarint(mem[offset:])
+		offset += n
+		var version *Version
+		version = VersionFromBytes(mem[offset : offset+int(numBytes)])
+		log.LogDebugf("loadVersion: create version from bytes: partitionID(%v) versionID(%v)", mp.config.PartitionId, version.id)
+		mp.fsmCreateVersion(version)
+		offset += int(numBytes)
+	}
+	log.LogInfof("loadVersion: load complete: partitionID(%v) numVersions(%v) filename(%v)",
+		mp.config.PartitionId, numVersions, filename)
+	return nil
+}
+
+func (mp *metaPartition) loadRenameTx(rootDir string) error {
+	var err error
+	filename := path.Join(rootDir, renameTxFile)
+	if _, err = os.Stat(filename); err != nil {
+		return nil
+	}
+	fp, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+	var mem mmap.MMap
+	if mem, err = mmap.Map(fp, mmap.RDONLY, 0); err != nil {
+		return err
+	}
+	defer func() {
+		_ = mem.Unmap()
+	}()
+	var offset, n int
+	// read number of rename transactions
+	var numTxs uint64
+	numTxs, n = binary.Uvarint(mem)
+	offset += n
+	for i := uint64(0); i < numTxs; i++ {
+		// read length
+		var numBytes uint64
+		numBytes, n = binary.Uvarint(mem[offset:])
+		offset += n
+		var tx *RenameTx
+		tx = RenameTxFromBytes(mem[offset : offset+int(numBytes)])
+		log.LogDebugf("loadRenameTx: create rename transaction from bytes: partitionID(%v) txID(%v) role(%v)",
+			mp.config.PartitionId, tx.id, tx.role)
+		mp.renameTxTree.ReplaceOrInsert(tx, true)
+		offset += int(numBytes)
+	}
+	mp.renameLockTree = buildRenameTxLockTree(mp.renameTxTree)
+	log.LogInfof("loadRenameTx: load complete: partitionID(%v) numTxs(%v) filename(%v)",
+		mp.config.PartitionId, numTxs, filename)
+	return nil
+}
+
+func (mp *metaPartition) loadExtentRef(rootDir string) error {
+	var err error
+	filename := path.Join(rootDir, extentRefFile)
+	if _, err = os.Stat(filename); err != nil {
+		return nil
+	}
+	fp, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+	var mem mmap.MMap
+	if mem, err = mmap.Map(fp, mmap.RDONLY, 0); err != nil {
+		return err
+	}
+	defer func() {
+		_ = mem.Unmap()
+	}()
+	var offset, n int
+	// read number of extent references
+	var numRefs uint64
+	numRefs, n = binary.Uvarint(mem)
+	offset += n
+	for i := uint64(0); i < numRefs; i++ {
+		// read length
+		var numBytes uint64
+		numBytes, n = binary.Uvarint(mem[offset:])
+		offset += n
+		var ref *ExtentRef
+		if ref, err = ExtentRefFromBytes(mem[offset : offset+int(numBytes)]); err != nil {
+			return err
+		}
+		log.LogDebugf("loadExtentRef: create extent reference from bytes: partitionID(%v) ref(%v)",
+			mp.config.PartitionId, ref)
+		mp.extentRefTree.ReplaceOrInsert(ref, true)
+		offset += int(numBytes)
+	}
+	log.LogInfof("loadExtentRef: load complete: partitionID(%v) numRefs(%v) filename(%v)",
+		mp.config.PartitionId, numRefs, filename)
+	return nil
+}
+
+func (mp *metaPartition) loadChangeLog(rootDir string) error {
+	var err error
+	filename := path.Join(rootDir, changeLogFile)
+	if _, err = os.Stat(filename); err != nil {
+		return nil
+	}
+	fp, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+	var mem mmap.MMap
+	if mem, err = mmap.Map(fp, mmap.RDONLY, 0); err != nil {
+		return err
+	}
+	defer func() {
+		_ = mem.Unmap()
+	}()
+	var offset, n int
+	// read number of change logs
+	var numChangeLogs uint64
+	numChangeLogs, n = binary.Uvarint(mem)
+	offset += n
+	for i := uint64(0); i < numChangeLogs; i++ {
+		// read length
+		var numBytes uint64
+		numBytes, n = binary.Uvarint(mem[offset:])
+		offset += n
+		changeLog := ChangeLogFromBytes(mem[offset : offset+int(numBytes)])
+		log.LogDebugf("loadChangeLog: create change log from bytes: partitionID(%v) id(%v) path(%v)",
+			mp.config.PartitionId, changeLog.id, changeLog.key)
+		mp.changeLogTree.ReplaceOrInsert(changeLog, true)
+		offset += int(numBytes)
+	}
+	log.LogInfof("loadChangeLog: load complete: partitionID(%v) numChangeLogs(%v) filename(%v)",
+		mp.config.PartitionId, numChangeLogs, filename)
+	return nil
+}
+
 func (mp *metaPartition) loadApplyID(rootDir string) (err error) {
 	filename := path.Join(rootDir, applyIDFile)
 	if _, err = os.Stat(filename); err != nil {
@@ -604,3 +777,263 @@ func (mp *metaPartition) storeMultipart(rootDir string, sm *storeMsg) (crc uint3
 		mp.config.PartitionId, mp.config.VolName, multipartTree.Len(), crc)
 	return
 }
+
+func (mp *metaPartition) storeVersion(rootDir string, sm *storeMsg) (crc uint32, err error) {
+	var versionTree = sm.versionTree
+	var fp = path.Join(rootDir, versionFile)
+	var f *os.File
+	f, err = os.OpenFile(fp, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.O_CREATE, 0755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		closeErr := f.Close()
+		if err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	var writer = bufio.NewWriterSize(f, 4*1024*1024)
+	var crc32 = crc32.NewIEEE()
+	var varintTmp = make([]byte, binary.MaxVarintLen64)
+	var n int
+	// write number of versions
+	n = binary.PutUvarint(varintTmp, uint64(versionTree.Len()))
+	if _, err = writer.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	if _, err = crc32.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	versionTree.Ascend(func(i BtreeItem) bool {
+		v := i.(*Version)
+		var raw []byte
+		if raw, err = v.Bytes(); err != nil {
+			return false
+		}
+		// write length
+		n = binary.PutUvarint(varintTmp, uint64(len(raw)))
+		if _, err = writer.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		// write raw
+		if _, err = writer.Write(raw); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(raw); err != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return
+	}
+
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	crc = crc32.Sum32()
+	log.LogInfof("storeVersion: store complete: partitoinID(%v) volume(%v) numVersions(%v) crc(%v)",
+		mp.config.PartitionId, mp.config.VolName, versionTree.Len(), crc)
+	return
+}
+
+func (mp *metaPartition) storeRenameTx(rootDir string, sm *storeMsg) (crc uint32, err error) {
+	var renameTxTree = sm.renameTxTree
+	var fp = path.Join(rootDir, renameTxFile)
+	var f *os.File
+	f, err = os.OpenFile(fp, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.O_CREATE, 0755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		closeErr := f.Close()
+		if err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	var writer = bufio.NewWriterSize(f, 4*1024*1024)
+	var crc32 = crc32.NewIEEE()
+	var varintTmp = make([]byte, binary.MaxVarintLen64)
+	var n int
+	// write number of rename transactions
+	n = binary.PutUvarint(varintTmp, uint64(renameTxTree.Len()))
+	if _, err = writer.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	if _, err = crc32.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	renameTxTree.Ascend(func(i BtreeItem) bool {
+		tx := i.(*RenameTx)
+		var raw []byte
+		if raw, err = tx.Bytes(); err != nil {
+			return false
+		}
+		// write length
+		n = binary.PutUvarint(varintTmp, uint64(len(raw)))
+		if _, err = writer.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		// write raw
+		if _, err = writer.Write(raw); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(raw); err != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return
+	}
+
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	crc = crc32.Sum32()
+	log.LogInfof("storeRenameTx: store complete: partitoinID(%v) volume(%v) numTxs(%v) crc(%v)",
+		mp.config.PartitionId, mp.config.VolName, renameTxTree.Len(), crc)
+	return
+}
+
+func (mp *metaPartition) storeExtentRef(rootDir string, sm *storeMsg) (crc uint32, err error) {
+	var extentRefTree = sm.extentRefTree
+	var fp = path.Join(rootDir, extentRefFile)
+	var f *os.File
+	f, err = os.OpenFile(fp, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.O_CREATE, 0755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		closeErr := f.Close()
+		if err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	var writer = bufio.NewWriterSize(f, 4*1024*1024)
+	var crc32 = crc32.NewIEEE()
+	var varintTmp = make([]byte, binary.MaxVarintLen64)
+	var n int
+	// write number of extent references
+	n = binary.PutUvarint(varintTmp, uint64(extentRefTree.Len()))
+	if _, err = writer.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	if _, err = crc32.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	extentRefTree.Ascend(func(i BtreeItem) bool {
+		ref := i.(*ExtentRef)
+		var raw []byte
+		if raw, err = ref.Bytes(); err != nil {
+			return false
+		}
+		// write length
+		n = binary.PutUvarint(varintTmp, uint64(len(raw)))
+		if _, err = writer.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		// write raw
+		if _, err = writer.Write(raw); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(raw); err != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return
+	}
+
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	crc = crc32.Sum32()
+	log.LogInfof("storeExtentRef: store complete: partitoinID(%v) volume(%v) numRefs(%v) crc(%v)",
+		mp.config.PartitionId, mp.config.VolName, extentRefTree.Len(), crc)
+	return
+}
+
+func (mp *metaPartition) storeChangeLog(rootDir string, sm *storeMsg) (crc uint32, err error) {
+	var changeLogTree = sm.changeLogTree
+	var fp = path.Join(rootDir, changeLogFile)
+	var f *os.File
+	f, err = os.OpenFile(fp, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.O_CREATE, 0755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		closeErr := f.Close()
+		if err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	var writer = bufio.NewWriterSize(f, 4*1024*1024)
+	var crc32 = crc32.NewIEEE()
+	var varintTmp = make([]byte, binary.MaxVarintLen64)
+	var n int
+	// write number of change logs
+	n = binary.PutUvarint(varintTmp, uint64(changeLogTree.Len()))
+	if _, err = writer.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	if _, err = crc32.Write(varintTmp[:n]); err != nil {
+		return
+	}
+	changeLogTree.Ascend(func(i BtreeItem) bool {
+		changeLog := i.(*ChangeLog)
+		var raw []byte
+		if raw, err = changeLog.Bytes(); err != nil {
+			return false
+		}
+		// write length
+		n = binary.PutUvarint(varintTmp, uint64(len(raw)))
+		if _, err = writer.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(varintTmp[:n]); err != nil {
+			return false
+		}
+		// write raw
+		if _, err = writer.Write(raw); err != nil {
+			return false
+		}
+		if _, err = crc32.Write(raw); err != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return
+	}
+
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	crc = crc32.Sum32()
+	log.LogInfof("storeChangeLog: store complete: partitoinID(%v) volume(%v) numChangeLogs(%v) crc(%v)",
+		mp.config.PartitionId, mp.config.VolName, changeLogTree.Len(), crc)
+	return
+}
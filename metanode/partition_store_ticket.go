@@ -31,6 +31,11 @@ type storeMsg struct {
 	dentryTree    *BTree
 	extendTree    *BTree
 	multipartTree *BTree
+	versionTree   *BTree
+	renameTxTree  *BTree
+	extentRefTree *BTree
+	changeLogTree *BTree
+	metaSnapshots []*metaSnapshot
 }
 
 func (mp *metaPartition) startSchedule(curIndex uint64) {
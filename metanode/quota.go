@@ -0,0 +1,180 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	quotaUsageUpdateInterval = time.Minute
+)
+
+// The exceeded quotas are aggregated by the master and delivered to every meta node by heartbeat.
+var exceededQuotas = &quotaRegistry{vols: make(map[string]map[uint32]struct{})}
+
+type quotaRegistry struct {
+	vols map[string]map[uint32]struct{}
+	sync.RWMutex
+}
+
+func (r *quotaRegistry) update(exceeded map[string][]uint32) {
+	vols := make(map[string]map[uint32]struct{}, len(exceeded))
+	for volName, ids := range exceeded {
+		quotas := make(map[uint32]struct{}, len(ids))
+		for _, id := range ids {
+			quotas[id] = struct{}{}
+		}
+		vols[volName] = quotas
+	}
+	r.Lock()
+	r.vols = vols
+	r.Unlock()
+}
+
+func (r *quotaRegistry) isExceeded(volName string, ids []uint32) bool {
+	r.RLock()
+	defer r.RUnlock()
+	quotas, ok := r.vols[volName]
+	if !ok {
+		return false
+	}
+	for _, id := range ids {
+		if _, ok = quotas[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the IDs of the directory quotas the inode is accounted to.
+func (mp *metaPartition) getQuotaIDs(ino uint64) []uint32 {
+	item := mp.extendTree.Get(NewExtend(ino))
+	if item == nil {
+		return nil
+	}
+	value, exist := item.(*Extend).Get([]byte(proto.QuotaXAttrKey))
+	if !exist {
+		return nil
+	}
+	return proto.QuotaIDsFromXAttr(string(value))
+}
+
+// marshalInodeQuota encodes the command creating an inode together with its quota IDs.
+func marshalInodeQuota(quotaIDs string, inode []byte) []byte {
+	val := make([]byte, 4+len(quotaIDs)+len(inode))
+	binary.BigEndian.PutUint32(val[:4], uint32(len(quotaIDs)))
+	copy(val[4:], quotaIDs)
+	copy(val[4+len(quotaIDs):], inode)
+	return val
+}
+
+func unmarshalInodeQuota(val []byte) (quotaIDs string, inode []byte, err error) {
+	if len(val) < 4 || len(val) < 4+int(binary.BigEndian.Uint32(val[:4])) {
+		return "", nil, fmt.Errorf("create inode with quota command too short: length(%v)", len(val))
+	}
+	length := 4 + int(binary.BigEndian.Uint32(val[:4]))
+	return string(val[4:length]), val[length:], nil
+}
+
+// fsmCreateInodeQuota creates the inode and tags it with the quota IDs.
+func (mp *metaPartition) fsmCreateInodeQuota(ino *Inode, quotaIDs string) (status uint8) {
+	if status = mp.fsmCreateInode(ino); status != proto.OpOk {
+		return
+	}
+	extend := NewExtend(ino.Inode)
+	extend.Put([]byte(proto.QuotaXAttrKey), []byte(quotaIDs))
+	if err := mp.fsmSetXAttr(extend); err != nil {
+		log.LogErrorf("fsmCreateInodeQuota: set quota IDs fail: partitionID(%v) inode(%v) quotaIDs(%v) err(%v)",
+			mp.config.PartitionId, ino.Inode, quotaIDs, err)
+	}
+	return
+}
+
+// Check whether the volume-wide quota or any quota of the inode has been exceeded.
+func (mp *metaPartition) isQuotaExceeded(ino uint64) bool {
+	ids := append(mp.getQuotaIDs(ino), proto.RootQuotaID)
+	return exceededQuotas.isExceeded(mp.config.VolName, ids)
+}
+
+// GetQuotaUsages returns the quota usages of the partition which are reported to the master.
+func (mp *metaPartition) GetQuotaUsages() []*proto.QuotaUsage {
+	if usages, ok := mp.quotaUsages.Load().([]*proto.QuotaUsage); ok {
+		return usages
+	}
+	return nil
+}
+
+func (mp *metaPartition) quotaUsageUpdater() {
+	t := time.NewTicker(quotaUsageUpdateInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-mp.stopC:
+			log.LogDebugf("quotaUsageUpdater: stop partition: partitionID(%v)", mp.config.PartitionId)
+			return
+		case <-t.C:
+		}
+		if _, isLeader := mp.IsLeader(); !isLeader {
+			continue
+		}
+		mp.updateQuotaUsages()
+	}
+}
+
+// Sum up the inodes and bytes of the inodes carrying quota IDs in their extend attributes.
+func (mp *metaPartition) updateQuotaUsages() {
+	var usages = make(map[uint32]*proto.QuotaUsage)
+	mp.extendTree.Ascend(func(i BtreeItem) bool {
+		extend := i.(*Extend)
+		value, exist := extend.Get([]byte(proto.QuotaXAttrKey))
+		if !exist {
+			return true
+		}
+		item := mp.inodeTree.Get(NewInode(extend.inode, 0))
+		if item == nil {
+			return true
+		}
+		ino := item.(*Inode)
+		if ino.ShouldDelete() {
+			return true
+		}
+		var size uint64
+		ino.DoReadFunc(func() {
+			size = ino.Size
+		})
+		for _, id := range proto.QuotaIDsFromXAttr(string(value)) {
+			usage, ok := usages[id]
+			if !ok {
+				usage = &proto.QuotaUsage{QuotaID: id}
+				usages[id] = usage
+			}
+			usage.UsedFiles++
+			usage.UsedBytes += size
+		}
+		return true
+	})
+	var list = make([]*proto.QuotaUsage, 0, len(usages))
+	for _, usage := range usages {
+		list = append(list, usage)
+	}
+	mp.quotaUsages.Store(list)
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestQuota_UsageAndExceeded(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1, VolName: "ltptest", Start: 1, End: 1000},
+		inodeTree:  NewBtree(),
+		extendTree: NewBtree(),
+	}
+	var tag = func(ino uint64, size uint64, ids ...uint32) {
+		inode := NewInode(ino, 0644)
+		inode.Size = size
+		mp.inodeTree.ReplaceOrInsert(inode, true)
+		if len(ids) == 0 {
+			return
+		}
+		extend := NewExtend(ino)
+		extend.Put([]byte(proto.QuotaXAttrKey), []byte(proto.QuotaIDsToXAttr(ids)))
+		mp.extendTree.ReplaceOrInsert(extend, true)
+	}
+	tag(10, 100, 1)
+	tag(11, 200, 1, 2)
+	tag(12, 400)
+
+	mp.updateQuotaUsages()
+	var usages = make(map[uint32]*proto.QuotaUsage)
+	for _, usage := range mp.GetQuotaUsages() {
+		usages[usage.QuotaID] = usage
+	}
+	if len(usages) != 2 {
+		t.Fatalf("quota usage count mismatch: expect 2, actual %v", len(usages))
+	}
+	if u := usages[1]; u.UsedFiles != 2 || u.UsedBytes != 300 {
+		t.Fatalf("usage of quota 1 mismatch: files(%v) bytes(%v)", u.UsedFiles, u.UsedBytes)
+	}
+	if u := usages[2]; u.UsedFiles != 1 || u.UsedBytes != 200 {
+		t.Fatalf("usage of quota 2 mismatch: files(%v) bytes(%v)", u.UsedFiles, u.UsedBytes)
+	}
+
+	defer exceededQuotas.update(nil)
+	exceededQuotas.update(map[string][]uint32{"ltptest": {2}})
+	if mp.isQuotaExceeded(10) || mp.isQuotaExceeded(12) {
+		t.Fatalf("inodes out of quota 2 are rejected")
+	}
+	if !mp.isQuotaExceeded(11) {
+		t.Fatalf("inode of quota 2 is not rejected")
+	}
+	exceededQuotas.update(map[string][]uint32{"ltptest": {proto.RootQuotaID}})
+	if !mp.isQuotaExceeded(12) {
+		t.Fatalf("inode is not rejected by the volume quota")
+	}
+}
+
+func TestQuota_CreateInodeQuota(t *testing.T) {
+	mp := &metaPartition{
+		config:     &MetaPartitionConfig{PartitionId: 1, VolName: "ltptest", Start: 1, End: 1000},
+		inodeTree:  NewBtree(),
+		extendTree: NewBtree(),
+	}
+	raw, err := NewInode(10, 0644).Marshal()
+	if err != nil {
+		t.Fatalf("marshal inode fail: %v", err)
+	}
+	quotaIDs, inode, err := unmarshalInodeQuota(marshalInodeQuota(proto.QuotaIDsToXAttr([]uint32{1, 2}), raw))
+	if err != nil {
+		t.Fatalf("unmarshal command fail: %v", err)
+	}
+	ino := NewInode(0, 0)
+	if err = ino.Unmarshal(inode); err != nil {
+		t.Fatalf("unmarshal inode fail: %v", err)
+	}
+	if status := mp.fsmCreateInodeQuota(ino, quotaIDs); status != proto.OpOk {
+		t.Fatalf("create inode fail: status(%v)", status)
+	}
+	if ids := mp.getQuotaIDs(10); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("quota IDs of created inode mismatch: %v", ids)
+	}
+	if _, _, err = unmarshalInodeQuota([]byte{0, 0, 0, 8, '1'}); err == nil {
+		t.Fatalf("truncated command is accepted")
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/btree"
+)
+
+// RenameTx defined the intent record of a rename transaction on one of its participants.
+// The source participant holds the source dentry and the destination participant holds the
+// destination dentry, both of them record the same transaction with different roles.
+// While a record is prepared, the dentry it refers is locked against other modifications.
+type RenameTx struct {
+	id         string
+	role       uint8
+	state      uint8
+	srcPid     uint64
+	dstPid     uint64
+	srcParent  uint64
+	srcName    string
+	dstParent  uint64
+	dstName    string
+	inode      uint64
+	mode       uint32
+	oldInode   uint64 // inode replaced by the destination dentry which has not been released yet
+	createTime int64
+}
+
+func (tx *RenameTx) Less(than btree.Item) bool {
+	ttx, is := than.(*RenameTx)
+	return is && ((tx.id < ttx.id) || ((tx.id == ttx.id) && (tx.role < ttx.role)))
+}
+
+func (tx *RenameTx) Copy() btree.Item {
+	var newTx = *tx
+	return &newTx
+}
+
+// lockedDentry returns the parent ID and name of the dentry locked by this record.
+func (tx *RenameTx) lockedDentry() (parent uint64, name string) {
+	if tx.role == proto.TxRenameRoleSrc {
+		return tx.srcParent, tx.srcName
+	}
+	return tx.dstParent, tx.dstName
+}
+
+// renameTxLock indexes the dentry locked by a prepared rename transaction by parent ID and name,
+// so that checking a dentry does not walk through all transaction records.
+type renameTxLock struct {
+	parentID uint64
+	name     string
+	txID     string
+	role     uint8
+}
+
+func (l *renameTxLock) Less(than btree.Item) bool {
+	tl, is := than.(*renameTxLock)
+	return is && ((l.parentID < tl.parentID) || ((l.parentID == tl.parentID) && (l.name < tl.name)))
+}
+
+func (l *renameTxLock) Copy() btree.Item {
+	var newLock = *l
+	return &newLock
+}
+
+func newRenameTxLock(tx *RenameTx) *renameTxLock {
+	parent, name := tx.lockedDentry()
+	return &renameTxLock{parentID: parent, name: name, txID: tx.id, role: tx.role}
+}
+
+// buildRenameTxLockTree builds the index of locked dentries from the prepared transaction records.
+func buildRenameTxLockTree(renameTxTree *BTree) *BTree {
+	var lockTree = NewBtree()
+	renameTxTree.Ascend(func(i BtreeItem) bool {
+		if tx := i.(*RenameTx); tx.state == proto.TxRenameStatePrepared {
+			lockTree.ReplaceOrInsert(newRenameTxLock(tx), true)
+		}
+		return true
+	})
+	return lockTree
+}
+
+// peer returns the ID of partition and the role of the other participant.
+func (tx *RenameTx) peer() (partitionID uint64, role uint8) {
+	if tx.role == proto.TxRenameRoleSrc {
+		return tx.dstPid, proto.TxRenameRoleDst
+	}
+	return tx.srcPid, proto.TxRenameRoleSrc
+}
+
+func (tx *RenameTx) Bytes() ([]byte, error) {
+	var n int
+	var buffer = bytes.NewBuffer(nil)
+	var err error
+	tmp := make([]byte, binary.MaxVarintLen64)
+	var marshalStr = func(src string) error {
+		n = binary.PutUvarint(tmp, uint64(len(src)))
+		if _, err = buffer.Write(tmp[:n]); err != nil {
+			return err
+		}
+		if _, err = buffer.WriteString(src); err != nil {
+			return err
+		}
+		return nil
+	}
+	var marshalUint = func(val uint64) error {
+		n = binary.PutUvarint(tmp, val)
+		_, err = buffer.Write(tmp[:n])
+		return err
+	}
+	// marshal id
+	if err = marshalStr(tx.id); err != nil {
+		return nil, err
+	}
+	// marshal role and state
+	if err = buffer.WriteByte(tx.role); err != nil {
+		return nil, err
+	}
+	if err = buffer.WriteByte(tx.state); err != nil {
+		return nil, err
+	}
+	// marshal partitions
+	if err = marshalUint(tx.srcPid); err != nil {
+		return nil, err
+	}
+	if err = marshalUint(tx.dstPid); err != nil {
+		return nil, err
+	}
+	// marshal source dentry
+	if err = marshalUint(tx.srcParent); err != nil {
+		return nil, err
+	}
+	if err = marshalStr(tx.srcName); err != nil {
+		return nil, err
+	}
+	// marshal destination dentry
+	if err = marshalUint(tx.dstParent); err != nil {
+		return nil, err
+	}
+	if err = marshalStr(tx.dstName); err != nil {
+		return nil, err
+	}
+	// marshal inode, mode and old inode
+	if err = marshalUint(tx.inode); err != nil {
+		return nil, err
+	}
+	if err = marshalUint(uint64(tx.mode)); err != nil {
+		return nil, err
+	}
+	if err = marshalUint(tx.oldInode); err != nil {
+		return nil, err
+	}
+	// marshal create time
+	n = binary.PutVarint(tmp, tx.createTime)
+	if _, err = buffer.Write(tmp[:n]); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func RenameTxFromBytes(raw []byte) *RenameTx {
+	var unmarshalStr = func(data []byte) (string, int) {
+		var n int
+		var lengthU64 uint64
+		lengthU64, n = binary.Uvarint(data)
+		return string(data[n : n+int(lengthU64)]), n + int(lengthU64)
+	}
+	var tx = &RenameTx{}
+	var offset, n int
+	var val uint64
+	// decode id
+	tx.id, n = unmarshalStr(raw)
+	offset += n
+	// decode role and state
+	tx.role = raw[offset]
+	tx.state = raw[offset+1]
+	offset += 2
+	// decode partitions
+	tx.srcPid, n = binary.Uvarint(raw[offset:])
+	offset += n
+	tx.dstPid, n = binary.Uvarint(raw[offset:])
+	offset += n
+	// decode source dentry
+	tx.srcParent, n = binary.Uvarint(raw[offset:])
+	offset += n
+	tx.srcName, n = unmarshalStr(raw[offset:])
+	offset += n
+	// decode destination dentry
+	tx.dstParent, n = binary.Uvarint(raw[offset:])
+	offset += n
+	tx.dstName, n = unmarshalStr(raw[offset:])
+	offset += n
+	// decode inode, mode and old inode
+	tx.inode, n = binary.Uvarint(raw[offset:])
+	offset += n
+	val, n = binary.Uvarint(raw[offset:])
+	tx.mode = uint32(val)
+	offset += n
+	tx.oldInode, n = binary.Uvarint(raw[offset:])
+	offset += n
+	// decode create time
+	tx.createTime, _ = binary.Varint(raw[offset:])
+	return tx
+}
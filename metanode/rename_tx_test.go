@@ -0,0 +1,129 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util"
+)
+
+func TestRenameTx_Bytes(t *testing.T) {
+	var err error
+	tx1 := &RenameTx{
+		id:         util.CreateMultipartID(1).String(),
+		role:       proto.TxRenameRoleDst,
+		state:      proto.TxRenameStateCommitted,
+		srcPid:     1,
+		dstPid:     2,
+		srcParent:  1,
+		srcName:    "a.tmp",
+		dstParent:  16777217,
+		dstName:    "a.txt",
+		inode:      12345,
+		mode:       uint32(0644),
+		oldInode:   23456,
+		createTime: time.Now().Unix(),
+	}
+	var txBytes []byte
+	if txBytes, err = tx1.Bytes(); err != nil {
+		t.Fatalf("get bytes of rename transaction fail cause: %v", err)
+	}
+	tx2 := RenameTxFromBytes(txBytes)
+	if !reflect.DeepEqual(tx1, tx2) {
+		t.Fatalf("result mismatch:\n\ttx1:%v\n\ttx2:%v", tx1, tx2)
+	}
+}
+
+func TestRenameTx_LocalCommit(t *testing.T) {
+	mp := &metaPartition{
+		config:         &MetaPartitionConfig{PartitionId: 1, Start: 1, End: 1000},
+		inodeTree:      NewBtree(),
+		dentryTree:     NewBtree(),
+		renameTxTree:   NewBtree(),
+		renameLockTree: NewBtree(),
+		freeList:       newFreeList(),
+	}
+	var mode = uint32(os.ModeDir | 0755)
+	for _, ino := range []uint64{1, 2} {
+		dir := NewInode(ino, mode)
+		dir.NLink = 2
+		mp.inodeTree.ReplaceOrInsert(dir, true)
+	}
+	mp.inodeTree.ReplaceOrInsert(NewInode(10, 0644), true)
+	mp.inodeTree.ReplaceOrInsert(NewInode(11, 0644), true)
+	mp.fsmCreateDentry(&Dentry{ParentId: 1, Name: "a.tmp", Inode: 10, Type: 0644}, false)
+	mp.fsmCreateDentry(&Dentry{ParentId: 2, Name: "a.txt", Inode: 11, Type: 0644}, false)
+
+	txID := util.CreateMultipartID(1).String()
+	var newTx = func(role uint8) *RenameTx {
+		return &RenameTx{id: txID, role: role, srcPid: 1, dstPid: 1, srcParent: 1, srcName: "a.tmp",
+			dstParent: 2, dstName: "a.txt", inode: 10, mode: 0644}
+	}
+	if status := mp.fsmTxRenamePrepare(newTx(proto.TxRenameRoleDst)); status != proto.OpOk {
+		t.Fatalf("prepare destination fail: status(%v)", status)
+	}
+	if status := mp.fsmTxRenamePrepare(newTx(proto.TxRenameRoleSrc)); status != proto.OpOk {
+		t.Fatalf("prepare source fail: status(%v)", status)
+	}
+	// prepared dentries are locked
+	if resp := mp.fsmDeleteDentry(&Dentry{ParentId: 1, Name: "a.tmp"}, false); resp.Status != proto.OpAgain {
+		t.Fatalf("delete locked dentry: expect status(%v) actual(%v)", proto.OpAgain, resp.Status)
+	}
+	if status := mp.fsmCreateDentry(&Dentry{ParentId: 2, Name: "a.txt", Inode: 12, Type: 0644}, false); status != proto.OpAgain {
+		t.Fatalf("create locked dentry: expect status(%v) actual(%v)", proto.OpAgain, status)
+	}
+	// a committed transaction can not be aborted
+	if status := mp.fsmTxRenameCommit(newTx(proto.TxRenameRoleDst)); status != proto.OpOk {
+		t.Fatalf("commit destination fail: status(%v)", status)
+	}
+	if status := mp.fsmTxRenameAbort(newTx(proto.TxRenameRoleDst)); status != proto.OpNotPerm {
+		t.Fatalf("abort committed destination: expect status(%v) actual(%v)", proto.OpNotPerm, status)
+	}
+	// the source dentry is kept and locked until the source is committed
+	if dentry, status := mp.getDentry(&Dentry{ParentId: 1, Name: "a.tmp"}); status != proto.OpOk || dentry.Inode != 10 {
+		t.Fatalf("source dentry mismatch before source committed: status(%v) dentry(%v)", status, dentry)
+	}
+	if !mp.isDentryLocked(1, "a.tmp") || mp.isDentryLocked(2, "a.txt") {
+		t.Fatalf("locked dentries mismatch after destination committed")
+	}
+	if status := mp.fsmTxRenameCommit(newTx(proto.TxRenameRoleSrc)); status != proto.OpOk {
+		t.Fatalf("commit source fail: status(%v)", status)
+	}
+
+	if _, status := mp.getDentry(&Dentry{ParentId: 1, Name: "a.tmp"}); status != proto.OpNotExistErr {
+		t.Fatalf("source dentry still exists")
+	}
+	dentry, status := mp.getDentry(&Dentry{ParentId: 2, Name: "a.txt"})
+	if status != proto.OpOk || dentry.Inode != 10 {
+		t.Fatalf("destination dentry mismatch: status(%v) dentry(%v)", status, dentry)
+	}
+	for ino, nlink := range map[uint64]uint32{1: 2, 2: 3, 11: 0} {
+		if actual := mp.inodeTree.Get(NewInode(ino, 0)).(*Inode).GetNLink(); actual != nlink {
+			t.Fatalf("nlink of inode(%v) mismatch: expect(%v) actual(%v)", ino, nlink, actual)
+		}
+	}
+	// only the record of destination is retained until it is resolved
+	if mp.renameTxTree.Len() != 1 {
+		t.Fatalf("number of rename transaction records mismatch: expect(1) actual(%v)", mp.renameTxTree.Len())
+	}
+	if mp.renameLockTree.Len() != 0 {
+		t.Fatalf("number of locked dentries mismatch: expect(0) actual(%v)", mp.renameLockTree.Len())
+	}
+}
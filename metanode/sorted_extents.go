@@ -85,27 +85,49 @@ func (se *SortedExtents) Append(ek proto.ExtentKey) (deleteExtents []proto.Exten
 		return
 	}
 
-	var startIndex, endIndex int
-
+	// The keys partially overlapped by the appended key are split, and the parts out of its range are kept,
+	// so that the file data is the same as what the client sees after overwriting in a new extent.
+	eks := make([]proto.ExtentKey, 0, len(se.eks)+2)
 	invalidExtents := make([]proto.ExtentKey, 0)
-	for idx, key := range se.eks {
-		if ek.FileOffset > key.FileOffset {
-			startIndex = idx + 1
+	inserted := false
+	for _, key := range se.eks {
+		keyEnd := key.FileOffset + uint64(key.Size)
+		if keyEnd <= ek.FileOffset {
+			eks = append(eks, key)
 			continue
 		}
-		if endOffset >= key.FileOffset+uint64(key.Size) {
-			invalidExtents = append(invalidExtents, key)
+		if key.FileOffset >= endOffset {
+			if !inserted {
+				eks = append(eks, ek)
+				inserted = true
+			}
+			eks = append(eks, key)
 			continue
 		}
-		break
+		if key.FileOffset < ek.FileOffset {
+			head := key
+			head.Size = uint32(ek.FileOffset - key.FileOffset)
+			eks = append(eks, head)
+		}
+		if !inserted {
+			eks = append(eks, ek)
+			inserted = true
+		}
+		if keyEnd > endOffset {
+			tail := key
+			tail.FileOffset = endOffset
+			tail.ExtentOffset = key.ExtentOffset + (endOffset - key.FileOffset)
+			tail.Size = uint32(keyEnd - endOffset)
+			eks = append(eks, tail)
+		}
+		if key.FileOffset >= ek.FileOffset && keyEnd <= endOffset {
+			invalidExtents = append(invalidExtents, key)
+		}
 	}
-
-	endIndex = startIndex + len(invalidExtents)
-	upperExtents := make([]proto.ExtentKey, len(se.eks)-endIndex)
-	copy(upperExtents, se.eks[endIndex:])
-	se.eks = se.eks[:startIndex]
-	se.eks = append(se.eks, ek)
-	se.eks = append(se.eks, upperExtents...)
+	if !inserted {
+		eks = append(eks, ek)
+	}
+	se.eks = eks
 	// check if ek and key are the same extent file with size extented
 	deleteExtents = make([]proto.ExtentKey, 0, len(invalidExtents))
 	for _, key := range invalidExtents {
@@ -187,6 +209,34 @@ func (se *SortedExtents) Clone() *SortedExtents {
 	return newSe
 }
 
+// Slice returns the extents in range [offset, offset+size) as the extents of a file starting at the offset.
+// The extent keys across the boundaries of the range are trimmed.
+func (se *SortedExtents) Slice(offset, size uint64) *SortedExtents {
+	newSe := NewSortedExtents()
+
+	se.RLock()
+	defer se.RUnlock()
+
+	end := offset + size
+	for _, ek := range se.eks {
+		ekEnd := ek.FileOffset + uint64(ek.Size)
+		if ekEnd <= offset || ek.FileOffset >= end {
+			continue
+		}
+		if ek.FileOffset < offset {
+			ek.ExtentOffset += offset - ek.FileOffset
+			ek.FileOffset = offset
+		}
+		if ekEnd > end {
+			ekEnd = end
+		}
+		ek.Size = uint32(ekEnd - ek.FileOffset)
+		ek.FileOffset -= offset
+		newSe.eks = append(newSe.eks, ek)
+	}
+	return newSe
+}
+
 func (se *SortedExtents) CopyExtents() []proto.ExtentKey {
 	se.RLock()
 	defer se.RUnlock()
@@ -84,3 +84,45 @@ func TestTruncate01(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// The partially overlapped key is split when a range in the middle is overwritten in a new extent.
+func TestAppend05(t *testing.T) {
+	se := NewSortedExtents()
+	se.Append(proto.ExtentKey{FileOffset: 0, Size: 3000, PartitionId: 1, ExtentId: 1, ExtentOffset: 100})
+	delExtents := se.Append(proto.ExtentKey{FileOffset: 1000, Size: 1000, PartitionId: 1, ExtentId: 2})
+	t.Logf("\ndel: %v\neks: %v", delExtents, se.eks)
+	if len(delExtents) != 0 || len(se.eks) != 3 || se.Size() != 3000 {
+		t.Fatalf("unexpected extents: del(%v) eks(%v)", delExtents, se.eks)
+	}
+	head, ek, tail := se.eks[0], se.eks[1], se.eks[2]
+	if head.ExtentId != 1 || head.FileOffset != 0 || head.Size != 1000 || head.ExtentOffset != 100 ||
+		ek.ExtentId != 2 || ek.FileOffset != 1000 ||
+		tail.ExtentId != 1 || tail.FileOffset != 2000 || tail.Size != 1000 || tail.ExtentOffset != 2100 {
+		t.Fatalf("unexpected split: eks(%v)", se.eks)
+	}
+	delExtents = se.Append(proto.ExtentKey{FileOffset: 2000, Size: 1000, PartitionId: 1, ExtentId: 3})
+	if len(delExtents) != 1 || delExtents[0].ExtentId != 1 || delExtents[0].FileOffset != 2000 {
+		t.Fatalf("the covered tail should be returned: del(%v)", delExtents)
+	}
+}
+
+// The keys across the boundaries of the range are trimmed, and the offsets start at the range.
+func TestSlice(t *testing.T) {
+	se := NewSortedExtents()
+	se.Append(proto.ExtentKey{FileOffset: 0, Size: 1000, PartitionId: 1, ExtentId: 1, ExtentOffset: 100})
+	se.Append(proto.ExtentKey{FileOffset: 1000, Size: 1000, PartitionId: 1, ExtentId: 2})
+	se.Append(proto.ExtentKey{FileOffset: 2000, Size: 1000, PartitionId: 1, ExtentId: 3})
+	slice := se.Slice(500, 1000)
+	t.Logf("\neks: %v", slice.eks)
+	if len(slice.eks) != 2 || slice.Size() != 1000 {
+		t.Fatalf("unexpected slice: eks(%v)", slice.eks)
+	}
+	head, tail := slice.eks[0], slice.eks[1]
+	if head.ExtentId != 1 || head.FileOffset != 0 || head.Size != 500 || head.ExtentOffset != 600 ||
+		tail.ExtentId != 2 || tail.FileOffset != 500 || tail.Size != 500 || tail.ExtentOffset != 0 {
+		t.Fatalf("unexpected trimming: eks(%v)", slice.eks)
+	}
+	if len(se.eks) != 3 || se.Size() != 3000 {
+		t.Fatalf("the source extents should not be changed: eks(%v)", se.eks)
+	}
+}
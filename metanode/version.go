@@ -0,0 +1,160 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util/btree"
+)
+
+// Version defined necessary fields for object version history management.
+// Each version refers an inode which is retained after the object has been
+// overwritten or deleted, or marks the object as deleted if it is a delete marker.
+type Version struct {
+	id           string
+	key          string
+	inode        uint64
+	size         uint64
+	etag         string
+	modifyTime   time.Time
+	deleteMarker bool
+}
+
+func (v *Version) Less(than btree.Item) bool {
+	tv, is := than.(*Version)
+	return is && ((v.key < tv.key) || ((v.key == tv.key) && (v.id < tv.id)))
+}
+
+func (v *Version) Copy() btree.Item {
+	return &Version{
+		id:           v.id,
+		key:          v.key,
+		inode:        v.inode,
+		size:         v.size,
+		etag:         v.etag,
+		modifyTime:   v.modifyTime,
+		deleteMarker: v.deleteMarker,
+	}
+}
+
+func (v *Version) ID() string {
+	return v.id
+}
+
+func (v *Version) Bytes() ([]byte, error) {
+	var n int
+	var buffer = bytes.NewBuffer(nil)
+	var err error
+	tmp := make([]byte, binary.MaxVarintLen64)
+	var marshalStr = func(src string) error {
+		n = binary.PutUvarint(tmp, uint64(len(src)))
+		if _, err = buffer.Write(tmp[:n]); err != nil {
+			return err
+		}
+		if _, err = buffer.WriteString(src); err != nil {
+			return err
+		}
+		return nil
+	}
+	// marshal id
+	if err = marshalStr(v.id); err != nil {
+		return nil, err
+	}
+	// marshal key
+	if err = marshalStr(v.key); err != nil {
+		return nil, err
+	}
+	// marshal inode
+	n = binary.PutUvarint(tmp, v.inode)
+	if _, err = buffer.Write(tmp[:n]); err != nil {
+		return nil, err
+	}
+	// marshal size
+	n = binary.PutUvarint(tmp, v.size)
+	if _, err = buffer.Write(tmp[:n]); err != nil {
+		return nil, err
+	}
+	// marshal etag
+	if err = marshalStr(v.etag); err != nil {
+		return nil, err
+	}
+	// marshal modify time
+	n = binary.PutVarint(tmp, v.modifyTime.UnixNano())
+	if _, err = buffer.Write(tmp[:n]); err != nil {
+		return nil, err
+	}
+	// marshal delete marker flag
+	var flag byte
+	if v.deleteMarker {
+		flag = 1
+	}
+	if err = buffer.WriteByte(flag); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func VersionFromBytes(raw []byte) *Version {
+	var unmarshalStr = func(data []byte) (string, int) {
+		var n int
+		var lengthU64 uint64
+		lengthU64, n = binary.Uvarint(data)
+		return string(data[n : n+int(lengthU64)]), n + int(lengthU64)
+	}
+	var offset, n int
+	// decode id
+	var id string
+	id, n = unmarshalStr(raw)
+	offset += n
+	// decode key
+	var key string
+	key, n = unmarshalStr(raw[offset:])
+	offset += n
+	// decode inode
+	var inode uint64
+	inode, n = binary.Uvarint(raw[offset:])
+	offset += n
+	// decode size
+	var size uint64
+	size, n = binary.Uvarint(raw[offset:])
+	offset += n
+	// decode etag
+	var etag string
+	etag, n = unmarshalStr(raw[offset:])
+	offset += n
+	// decode modify time
+	var modifyTimeI64 int64
+	modifyTimeI64, n = binary.Varint(raw[offset:])
+	offset += n
+	// decode delete marker flag
+	var deleteMarker bool
+	if offset < len(raw) {
+		deleteMarker = raw[offset] == 1
+	}
+
+	var version = &Version{
+		id:           id,
+		key:          key,
+		inode:        inode,
+		size:         size,
+		etag:         etag,
+		modifyTime:   time.Unix(0, modifyTimeI64),
+		deleteMarker: deleteMarker,
+	}
+	return version
+}
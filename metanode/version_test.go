@@ -0,0 +1,61 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util"
+)
+
+func TestVersion_Bytes(t *testing.T) {
+	var err error
+	for _, deleteMarker := range []bool{false, true} {
+		version1 := &Version{
+			id:           util.CreateMultipartID(1).String(),
+			key:          "/a/b/c.txt",
+			inode:        12345,
+			size:         65536,
+			etag:         util.RandomString(16, util.LowerLetter|util.Numeric),
+			modifyTime:   time.Unix(0, time.Now().UnixNano()),
+			deleteMarker: deleteMarker,
+		}
+		var versionBytes []byte
+		if versionBytes, err = version1.Bytes(); err != nil {
+			t.Fatalf("get bytes of version fail cause: %v", err)
+		}
+		version2 := VersionFromBytes(versionBytes)
+		if !reflect.DeepEqual(version1, version2) {
+			t.Fatalf("result mismatch:\n\tversion1:%v\n\tversion2:%v", version1, version2)
+		}
+	}
+}
+
+func TestVersion_Order(t *testing.T) {
+	var now = time.Now()
+	older := &Version{key: "/a.txt", id: util.CreateVersionID(1, now, true).String()}
+	newer := &Version{key: "/a.txt", id: util.CreateVersionID(2, now.Add(time.Nanosecond), false).String()}
+	if !newer.Less(older) {
+		t.Fatalf("newer version should be ordered first: newer(%v) older(%v)", newer.id, older.id)
+	}
+	if !util.IsNullVersionID(older.id) || util.IsNullVersionID(newer.id) {
+		t.Fatalf("null version flag mismatch: older(%v) newer(%v)", older.id, newer.id)
+	}
+	if mpId, found := util.MultipartIDFromString(newer.id).PartitionID(); !found || mpId != 2 {
+		t.Fatalf("partition ID mismatch: id(%v) found(%v) partitionID(%v)", newer.id, found, mpId)
+	}
+}
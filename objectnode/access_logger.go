@@ -0,0 +1,324 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Interval to write the buffered access log records if it is not configured.
+	defaultAccessLogInterval = 5 * time.Minute
+	// Size of the buffered records of a target at which they are written before the interval expires.
+	accessLogBatchSize = 4 * 1024 * 1024
+	// Maximum size of all buffered records, new records are dropped if it is exceeded.
+	accessLogBufferLimit = 64 * 1024 * 1024
+
+	accessLogTimeLayout = "[02/Jan/2006:15:04:05 -0700]"
+	accessLogKeyLayout  = "2006-01-02-15-04-05"
+	accessLogNone       = "-"
+)
+
+// AccessLogRecord is a request record in the server access log of a bucket.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/dev/LogFormat.html
+type AccessLogRecord struct {
+	BucketOwner      string
+	Bucket           string
+	Time             time.Time
+	RemoteIP         string
+	Requester        string
+	RequestID        string
+	Operation        string
+	Key              string
+	RequestURI       string
+	HTTPStatus       int
+	ErrorCode        string
+	BytesSent        int64
+	ObjectSize       int64 // negative if unknown
+	TotalTime        time.Duration
+	Referer          string
+	UserAgent        string
+	SignatureVersion string
+	AuthType         string
+	Host             string
+}
+
+// String formats the record as a line of the S3 server access log.
+func (r *AccessLogRecord) String() string {
+	var orNone = func(s string) string {
+		if len(s) == 0 {
+			return accessLogNone
+		}
+		return s
+	}
+	var quoted = func(s string) string {
+		if len(s) == 0 {
+			return accessLogNone
+		}
+		return strconv.Quote(s)
+	}
+	var objectSize = accessLogNone
+	if r.ObjectSize >= 0 {
+		objectSize = strconv.FormatInt(r.ObjectSize, 10)
+	}
+	var bytesSent = accessLogNone
+	if r.BytesSent > 0 {
+		bytesSent = strconv.FormatInt(r.BytesSent, 10)
+	}
+	var key = accessLogNone
+	if len(r.Key) > 0 {
+		key = encodeKey(r.Key, "url")
+	}
+	var fields = []string{
+		orNone(r.BucketOwner),
+		orNone(r.Bucket),
+		r.Time.UTC().Format(accessLogTimeLayout),
+		orNone(r.RemoteIP),
+		orNone(r.Requester),
+		orNone(r.RequestID),
+		orNone(r.Operation),
+		key,
+		quoted(r.RequestURI),
+		strconv.Itoa(r.HTTPStatus),
+		orNone(r.ErrorCode),
+		bytesSent,
+		objectSize,
+		strconv.FormatInt(int64(r.TotalTime/time.Millisecond), 10),
+		accessLogNone, // turn-around time
+		quoted(r.Referer),
+		quoted(r.UserAgent),
+		accessLogNone, // version id
+		accessLogNone, // host id
+		orNone(r.SignatureVersion),
+		accessLogNone, // cipher suite
+		orNone(r.AuthType),
+		orNone(r.Host),
+	}
+	return strings.Join(fields, " ")
+}
+
+type accessLogBatchKey struct {
+	target string
+	prefix string
+}
+
+// AccessLogger buffers the access log records of buckets and writes them in batches as
+// objects into the target buckets of their logging configurations.
+// The delivery is best effort, records are lost if they can not be written.
+type AccessLogger struct {
+	vm       *VolumeManager
+	interval time.Duration
+	put      func(target, key string, data []byte) error
+
+	mu       sync.Mutex
+	batches  map[accessLogBatchKey]*bytes.Buffer
+	buffered int
+
+	flushC   chan struct{}
+	stopC    chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewAccessLogger(vm *VolumeManager, interval time.Duration) *AccessLogger {
+	if interval <= 0 {
+		interval = defaultAccessLogInterval
+	}
+	var l = &AccessLogger{
+		vm:       vm,
+		interval: interval,
+		batches:  make(map[accessLogBatchKey]*bytes.Buffer),
+		flushC:   make(chan struct{}, 1),
+		stopC:    make(chan struct{}),
+	}
+	l.put = l.putObject
+	return l
+}
+
+func (l *AccessLogger) Start() {
+	l.wg.Add(1)
+	go l.run()
+	log.LogInfof("AccessLogger: started: interval(%v)", l.interval)
+}
+
+// Stop writes all buffered records and stops the logger.
+func (l *AccessLogger) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stopC)
+		l.wg.Wait()
+	})
+}
+
+// Log buffers the record for the target bucket of the logging configuration.
+func (l *AccessLogger) Log(logging *LoggingEnabled, record *AccessLogRecord) {
+	var line = record.String() + "\n"
+	var key = accessLogBatchKey{target: logging.TargetBucket, prefix: logging.TargetPrefix}
+
+	l.mu.Lock()
+	if l.buffered+len(line) > accessLogBufferLimit {
+		l.mu.Unlock()
+		log.LogWarnf("AccessLogger: buffer is full, drop record: bucket(%v) requestID(%v)",
+			record.Bucket, record.RequestID)
+		return
+	}
+	var batch, has = l.batches[key]
+	if !has {
+		batch = new(bytes.Buffer)
+		l.batches[key] = batch
+	}
+	batch.WriteString(line)
+	l.buffered += len(line)
+	var full = batch.Len() >= accessLogBatchSize
+	l.mu.Unlock()
+
+	if full {
+		select {
+		case l.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *AccessLogger) run() {
+	defer l.wg.Done()
+	var ticker = time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopC:
+			l.flush()
+			return
+		case <-ticker.C:
+			l.flush()
+		case <-l.flushC:
+			l.flush()
+		}
+	}
+}
+
+// flush writes the buffered records of each target as a new log object.
+func (l *AccessLogger) flush() {
+	l.mu.Lock()
+	var batches = l.batches
+	l.batches = make(map[accessLogBatchKey]*bytes.Buffer)
+	l.buffered = 0
+	l.mu.Unlock()
+
+	for key, batch := range batches {
+		if batch.Len() == 0 {
+			continue
+		}
+		var objectKey = accessLogObjectKey(key.prefix, time.Now())
+		if err := l.put(key.target, objectKey, batch.Bytes()); err != nil {
+			log.LogErrorf("AccessLogger: write log object fail: target(%v) key(%v) size(%v) err(%v)",
+				key.target, objectKey, batch.Len(), err)
+			continue
+		}
+		log.LogDebugf("AccessLogger: write log object: target(%v) key(%v) size(%v)",
+			key.target, objectKey, batch.Len())
+	}
+}
+
+func (l *AccessLogger) putObject(target, key string, data []byte) (err error) {
+	var vol *Volume
+	if vol, err = l.vm.Volume(target); err != nil {
+		return
+	}
+	_, err = vol.PutObject(key, bytes.NewReader(data), &PutFileOption{MIMEType: HeaderValueContentTypeText})
+	return
+}
+
+// accessLogObjectKey returns the key of a log object in the format
+// "TargetPrefixYYYY-mm-DD-HH-MM-SS-UniqueString".
+func accessLogObjectKey(prefix string, now time.Time) string {
+	var unique = strings.ReplaceAll(uuid.New().String(), "-", "")
+	return fmt.Sprintf("%s%s-%s", prefix, now.UTC().Format(accessLogKeyLayout), strings.ToUpper(unique[:16]))
+}
+
+// logAccess writes the access log record of the request if the access logging of the requested bucket is enabled.
+func (o *ObjectNode) logAccess(r *http.Request, w *responseRecorder, action proto.Action, startTime time.Time) {
+	if o.accessLog == nil {
+		return
+	}
+	var vars = mux.Vars(r)
+	var bucket = vars["bucket"]
+	if len(bucket) == 0 {
+		return
+	}
+	var vol, err = o.vm.Volume(bucket)
+	if err != nil {
+		return
+	}
+	var logging *BucketLoggingStatus
+	if logging, err = vol.metaLoader.loadLogging(); err != nil || !logging.IsEnabled() {
+		return
+	}
+
+	var record = &AccessLogRecord{
+		BucketOwner: vol.Owner(),
+		Bucket:      bucket,
+		Time:        startTime,
+		RemoteIP:    getRequestIP(r),
+		RequestID:   GetRequestID(r),
+		Operation:   fmt.Sprintf("REST.%s.%s", r.Method, action.Name()),
+		Key:         vars["object"],
+		RequestURI:  fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto),
+		HTTPStatus:  w.StatusCode(),
+		ErrorCode:   getResponseErrorCode(r),
+		BytesSent:   w.bytesSent,
+		ObjectSize:  -1,
+		TotalTime:   time.Since(startTime),
+		Referer:     r.Referer(),
+		UserAgent:   r.UserAgent(),
+		Host:        r.Host,
+	}
+	if (r.Method == http.MethodPut || r.Method == http.MethodPost) && len(record.Key) > 0 && r.ContentLength >= 0 {
+		record.ObjectSize = r.ContentLength
+	}
+
+	var auth = parseRequestAuthInfo(r)
+	switch auth.authType {
+	case SignatrueV2:
+		record.SignatureVersion, record.AuthType = "SigV2", "AuthHeader"
+	case SignatrueV4:
+		record.SignatureVersion, record.AuthType = "SigV4", "AuthHeader"
+	case PresignedV2:
+		record.SignatureVersion, record.AuthType = "SigV2", "QueryString"
+	case PresignedV4:
+		record.SignatureVersion, record.AuthType = "SigV4", "QueryString"
+	}
+	// The requester is the user authenticated by the auth middleware or the handler rather than the
+	// access key the request is signed with, which may be temporary credentials.
+	if accessKey := GetRequesterFromContext(r); len(accessKey) > 0 {
+		record.Requester = accessKey
+		if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+			record.Requester = userInfo.UserID
+		}
+	}
+
+	o.accessLog.Log(logging.LoggingEnabled, record)
+}
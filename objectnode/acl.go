@@ -50,6 +50,7 @@ var (
 		},
 		WritePermission: {
 			proto.OSSPutObjectAction,
+			proto.OSSPostObjectAction,
 			proto.OSSDeleteObjectAction,
 			proto.OSSDeleteObjectsAction,
 		},
@@ -64,6 +65,7 @@ var (
 			proto.OSSListObjectVersionsAction,
 			proto.OSSListMultipartUploadsAction,
 			proto.OSSPutObjectAction,
+			proto.OSSPostObjectAction,
 			proto.OSSDeleteObjectAction,
 			proto.OSSDeleteObjectsAction,
 			proto.OSSGetBucketAclAction,
@@ -74,6 +76,7 @@ var (
 		ReadPermission: {
 			proto.OSSGetObjectAction,
 			proto.OSSGetObjectTorrentAction,
+			proto.OSSSelectObjectContentAction,
 		},
 		WritePermission: {},
 		ReadACPPermission: {
@@ -84,6 +87,7 @@ var (
 		FullControlPermission: {
 			proto.OSSGetObjectAction,
 			proto.OSSGetObjectTorrentAction,
+			proto.OSSSelectObjectContentAction,
 			proto.OSSGetObjectAclAction,
 			proto.OSSPutObjectAclAction,
 		},
@@ -189,7 +193,8 @@ func (acp *AccessControlPolicy) Validate(bucket string) (bool, error) {
 	return true, nil
 }
 
-func (acp *AccessControlPolicy) IsAllowed(param *RequestParam, isOwner bool) bool {
+// IsAllowed checks whether the ACL allows the request, public grants are skipped if ignorePublic is set.
+func (acp *AccessControlPolicy) IsAllowed(param *RequestParam, isOwner, ignorePublic bool) bool {
 	log.LogDebugf("acl is allowed: %v param: %v", acp, param)
 	if len(acp.Acl.Grants) == 0 {
 		return true
@@ -198,6 +203,9 @@ func (acp *AccessControlPolicy) IsAllowed(param *RequestParam, isOwner bool) boo
 		return true
 	}
 	for _, grant := range acp.Acl.Grants {
+		if ignorePublic && grant.IsPublic() {
+			continue
+		}
 		if grant.IsAllowed(param) {
 			return true
 		}
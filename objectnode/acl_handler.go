@@ -96,7 +96,9 @@ func (o *ObjectNode) putBucketACLHandler(w http.ResponseWriter, r *http.Request)
 		err error
 		ec  *ErrorCode
 	)
-	defer o.errorResponse(w, r, err, ec)
+	defer func() {
+		o.errorResponse(w, r, err, ec)
+	}()
 
 	log.LogInfof("Put bucket acl")
 
@@ -117,26 +119,41 @@ func (o *ObjectNode) putBucketACLHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var acp *AccessControlPolicy
-	if acp, err = ParseACL(bytes, param.Bucket()); err != nil {
-		return
-	}
-	if acp == nil {
-		return
+	var acp = &AccessControlPolicy{}
+	if len(bytes) > 0 {
+		if acp, err = ParseACL(bytes, param.Bucket()); err != nil {
+			log.LogErrorf("putBucketACLHandler: parse ACL fail: requestID(%v) err(%v)", GetRequestID(r), err)
+			ec = MalformedACL
+			return
+		}
 	}
 
 	//add standard acl request header
 	// https://docs.aws.amazon.com/zh_cn/AmazonS3/latest/dev/acl-overview.html
-	if standardAcls, found := r.Header["x-amz-acl"]; found {
-		acp.SetBucketStandardACL(param, standardAcls[0])
+	if standardAcl := r.Header.Get(HeaderNameXAmzACL); standardAcl != "" {
+		acp.SetBucketStandardACL(param, standardAcl)
 	} else {
 		for grant, permission := range aclGrantKeyPermissionMap {
-			if _, found2 := r.Header[grant]; found2 {
+			if r.Header.Get(grant) != "" {
 				acp.SetBucketGrantACL(param, permission)
 			}
 		}
 	}
 
+	var publicAccessBlock *PublicAccessBlockConfiguration
+	if publicAccessBlock, err = o.publicAccessBlock(vol); err != nil {
+		log.LogErrorf("putBucketACLHandler: load public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		ec = InternalErrorCode(err)
+		return
+	}
+	if publicAccessBlock.BlockPublicAcls && acp.IsPublic() {
+		log.LogWarnf("putBucketACLHandler: public ACL is blocked: requestID(%v) volume(%v)",
+			GetRequestID(r), param.Bucket())
+		ec = PublicAccessBlocked
+		return
+	}
+
 	var newBytes []byte
 	if newBytes, err = acp.Marshal(); err != nil {
 		return
@@ -15,6 +15,7 @@
 package objectnode
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -23,11 +24,19 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// contextKey is the key of values which can not be stored in the route variables.
+type contextKey string
+
+const contextKeySession contextKey = "ctx_session"
+
 const (
 	ContextKeyRequestID     = "ctx_request_id"
 	ContextKeyRequestAction = "ctx_request_action"
+	ContextKeyAccessKey     = "ctx_access_key"
+	ContextKeyRequester     = "ctx_requester"
 	ContextKeyStatusCode    = "status_code"
 	ContextKeyErrorMessage  = "error_message"
+	ContextKeyErrorCode     = "error_code"
 )
 
 func SetRequestID(r *http.Request, requestID string) {
@@ -46,6 +55,44 @@ func GetActionFromContext(r *http.Request) (action proto.Action) {
 	return proto.ParseAction(mux.Vars(r)[ContextKeyRequestAction])
 }
 
+// SetRequestAccessKey stores the access key of the request authenticated by the handler
+// itself, such as the POST object request signed in the form fields.
+func SetRequestAccessKey(r *http.Request, accessKey string) {
+	mux.Vars(r)[ContextKeyAccessKey] = accessKey
+}
+
+func GetAccessKeyFromContext(r *http.Request) string {
+	return mux.Vars(r)[ContextKeyAccessKey]
+}
+
+// SetRequester stores the access key of the user who the request is authenticated as by the auth middleware.
+// The route variables are shared by the shallow copies of the request, so that the outer middlewares see it.
+func SetRequester(r *http.Request, accessKey string) {
+	mux.Vars(r)[ContextKeyRequester] = accessKey
+}
+
+// GetRequesterFromContext returns the access key of the authenticated user of the request, it is empty
+// if the request is anonymous or fails to be authenticated.
+func GetRequesterFromContext(r *http.Request) string {
+	if accessKey := mux.Vars(r)[ContextKeyRequester]; len(accessKey) > 0 {
+		return accessKey
+	}
+	return GetAccessKeyFromContext(r)
+}
+
+// WithRequestSession returns a shallow copy of the request which carries the temporary credentials
+// it is signed by.
+func WithRequestSession(r *http.Request, session *Session) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), contextKeySession, session))
+}
+
+// GetRequestSession returns the temporary credentials the request is signed by, or nil if the
+// request is signed by permanent credentials.
+func GetRequestSession(r *http.Request) *Session {
+	session, _ := r.Context().Value(contextKeySession).(*Session)
+	return session
+}
+
 func SetResponseStatusCode(r *http.Request, code ErrorCode) {
 	mux.Vars(r)[ContextKeyStatusCode] = strconv.Itoa(code.StatusCode)
 }
@@ -65,3 +112,11 @@ func SetResponseErrorMessage(r *http.Request, message string) {
 func getResponseErrorMessage(r *http.Request) string {
 	return mux.Vars(r)[ContextKeyErrorMessage]
 }
+
+func SetResponseErrorCode(r *http.Request, code string) {
+	mux.Vars(r)[ContextKeyErrorCode] = code
+}
+
+func getResponseErrorCode(r *http.Request) string {
+	return mux.Vars(r)[ContextKeyErrorCode]
+}
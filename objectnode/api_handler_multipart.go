@@ -18,6 +18,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
@@ -26,6 +27,10 @@ import (
 	"github.com/chubaofs/chubaofs/util/log"
 )
 
+var (
+	copySourceRangeRegexp = regexp.MustCompile("^bytes=(\\d+)-(\\d+)$")
+)
+
 // Create multipart upload
 // API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CreateMultipartUpload.html
 func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.Request) {
@@ -58,6 +63,19 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Check server side encryption headers, multipart upload with the customer key is not supported.
+	if errorCode = checkServerSideEncryption(r.Header, vol); errorCode != nil {
+		return
+	}
+	var sseKey *SSECustomerKey
+	if sseKey, errorCode = ParseSSECustomerKey(r.Header); errorCode != nil {
+		return
+	}
+	if sseKey != nil {
+		errorCode = NotImplemented
+		return
+	}
+
 	// system metadata
 	// Get the requested content-type.
 	// In addition to being used to manage data types, it is used to distinguish
@@ -89,6 +107,13 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+
+	// Check object lock headers
+	var objectLock map[string]string
+	if objectLock, errorCode = ParseObjectLockHeaders(r.Header); errorCode != nil {
+		return
+	}
+
 	var opt = &PutFileOption{
 		MIMEType:     contentType,
 		Disposition:  contentDisposition,
@@ -96,6 +121,8 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 		Metadata:     metadata,
 		CacheControl: cacheControl,
 		Expires:      expires,
+		Replica:      r.Header.Get(HeaderNameXAmzReplicationStatus) == ReplicationStatusReplica,
+		ObjectLock:   objectLock,
 	}
 
 	var uploadID string
@@ -131,6 +158,18 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 	return
 }
 
+// parsePartNumber parses the part number of the part upload requests, which must be in [1, MaxPartNumber].
+func parsePartNumber(raw string) (partNumber uint16, errorCode *ErrorCode) {
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, InvalidArgument
+	}
+	if value < 1 || value > MaxPartNumber {
+		return 0, InvalidPartNumber
+	}
+	return uint16(value), nil
+}
+
 // Upload part
 // Uploads a part in a multipart upload.
 // API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPart.html .
@@ -160,11 +199,10 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var partNumberInt uint64
-	if partNumberInt, err = strconv.ParseUint(partNumber, 10, 64); err != nil {
-		log.LogErrorf("uploadPartHandler: parse part number fail, requestID(%v) raw(%v) err(%v)",
-			GetRequestID(r), partNumber, err)
-		errorCode = InvalidArgument
+	var partNumberInt uint16
+	if partNumberInt, errorCode = parsePartNumber(partNumber); errorCode != nil {
+		log.LogErrorf("uploadPartHandler: invalid part number, requestID(%v) raw(%v)",
+			GetRequestID(r), partNumber)
 		return
 	}
 
@@ -184,14 +222,22 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = NoSuchBucket
 		return
 	}
+	if r.Header.Get(HeaderNameXAmzSSECustomerAlgorithm) != "" {
+		errorCode = NotImplemented
+		return
+	}
 
 	// handle exception
 	var fsFileInfo *FSFileInfo
-	fsFileInfo, err = vol.WritePart(param.Object(), uploadId, uint16(partNumberInt), r.Body)
+	fsFileInfo, err = vol.WritePart(param.Object(), uploadId, partNumberInt, r.Body)
 	if err == syscall.ENOENT {
 		errorCode = NoSuchUpload
 		return
 	}
+	if err == syscall.EDQUOT {
+		errorCode = QuotaExceeded
+		return
+	}
 	if err == io.ErrUnexpectedEOF {
 		log.LogWarnf("uploadPartHandler: write part fail cause unexpected EOF: requestID(%v) volume(%v) path(%v) uploadId(%v) part(%v) remote(%v) err(%v)",
 			GetRequestID(r), vol.Name(), param.Object(), uploadId, partNumberInt, getRequestIP(r), err)
@@ -215,6 +261,189 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// Upload part copy
+// Uploads a part by copying data from an existing object as data source.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPartCopy.html
+func (o *ObjectNode) uploadPartCopyHandler(w http.ResponseWriter, r *http.Request) {
+
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+
+	// get upload id and part number
+	uploadId := param.GetVar(ParamUploadId)
+	partNumber := param.GetVar(ParamPartNumber)
+	if uploadId == "" || partNumber == "" {
+		log.LogErrorf("uploadPartCopyHandler: illegal uploadID or partNumber, requestID(%v)", GetRequestID(r))
+		errorCode = InvalidArgument
+		return
+	}
+
+	var partNumberInt uint16
+	if partNumberInt, errorCode = parsePartNumber(partNumber); errorCode != nil {
+		log.LogErrorf("uploadPartCopyHandler: invalid part number, requestID(%v) raw(%v)",
+			GetRequestID(r), partNumber)
+		return
+	}
+
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("uploadPartCopyHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = NoSuchBucket
+		return
+	}
+	if r.Header.Get(HeaderNameXAmzSSECustomerAlgorithm) != "" {
+		errorCode = NotImplemented
+		return
+	}
+
+	sourceBucket, sourceObject := parseCopySourceInfo(r)
+	if sourceBucket == "" || sourceObject == "" {
+		errorCode = InvalidArgument
+		return
+	}
+
+	// check permission, must have read permission to source bucket
+	var userInfo *proto.UserInfo
+	if userInfo, err = o.getUserInfoByAccessKey(param.AccessKey()); err != nil {
+		log.LogErrorf("uploadPartCopyHandler: get user info from master error: requestID(%v), accessKey(%v), err(%v)",
+			GetRequestID(r), param.AccessKey(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if !userInfo.Policy.IsAllowed(proto.OSSUploadPartCopyAction, sourceBucket, sourceObject, param.IAMContext()) {
+		log.LogErrorf("uploadPartCopyHandler: no permission to copy from source bucket, requestID(%v), source bucket(%v), source file(%v), target bucket(%v), target file(%v)",
+			GetRequestID(r), sourceBucket, sourceObject, param.Bucket(), param.Object())
+		errorCode = AccessDenied
+		return
+	}
+
+	var sourceVol *Volume
+	if sourceVol, err = o.getVol(sourceBucket); err != nil {
+		log.LogErrorf("uploadPartCopyHandler: load source volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), sourceBucket, err)
+		errorCode = NoSuchBucket
+		return
+	}
+	var fileInfo *FSFileInfo
+	if fileInfo, err = sourceVol.ObjectMeta(sourceObject); err != nil {
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+			return
+		}
+		log.LogErrorf("uploadPartCopyHandler: get source file info fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), sourceBucket, sourceObject, err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if fileInfo.Mode.IsDir() {
+		errorCode = InvalidArgument
+		return
+	}
+	if len(fileInfo.SSECustomerKeyMD5) > 0 {
+		errorCode = NotImplemented
+		return
+	}
+	if errorCode = checkCopySourceConditions(r, fileInfo); errorCode != nil {
+		return
+	}
+
+	// copy the whole source object if no range specified
+	var offset, size = uint64(0), uint64(fileInfo.Size)
+	if rangeOpt := r.Header.Get(HeaderNameXAmzCopySourceRange); rangeOpt != "" {
+		if offset, size, errorCode = parseCopySourceRange(rangeOpt, uint64(fileInfo.Size)); errorCode != nil {
+			log.LogErrorf("uploadPartCopyHandler: illegal copy source range: requestID(%v) range(%v) size(%v)",
+				GetRequestID(r), rangeOpt, fileInfo.Size)
+			return
+		}
+	}
+	if size > MaxCopyObjectSize {
+		errorCode = CopySourceSizeTooLarge
+		return
+	}
+
+	var fsFileInfo *FSFileInfo
+	fsFileInfo, err = vol.CopyPart(sourceVol, sourceObject, fileInfo.Inode, param.Object(), uploadId, partNumberInt, offset, size)
+	if err == syscall.ENOENT {
+		errorCode = NoSuchUpload
+		return
+	}
+	if err == syscall.EDQUOT {
+		errorCode = QuotaExceeded
+		return
+	}
+	if err != nil {
+		log.LogErrorf("uploadPartCopyHandler: copy part fail: requestID(%v) volume(%v) path(%v) uploadId(%v) part(%v) source(%v/%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), uploadId, partNumberInt, sourceBucket, sourceObject, err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	log.LogDebugf("uploadPartCopyHandler: copy part success: requestID(%v) volume(%v) path(%v) uploadId(%v) part(%v) fsFileInfo(%v)",
+		GetRequestID(r), vol.Name(), param.Object(), uploadId, partNumberInt, fsFileInfo)
+
+	copyResult := CopyPartResult{
+		ETag:         fsFileInfo.ETag,
+		LastModified: formatTimeISO(fsFileInfo.ModifyTime),
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(copyResult); err != nil {
+		log.LogErrorf("uploadPartCopyHandler: marshal xml entity fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	// set response header
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// parseCopySourceRange parses the value of header 'x-amz-copy-source-range' in format 'bytes=first-last',
+// both of the positions are required and inclusive.
+func parseCopySourceRange(rangeOpt string, fileSize uint64) (offset, size uint64, errorCode *ErrorCode) {
+	var matches = copySourceRangeRegexp.FindStringSubmatch(rangeOpt)
+	if len(matches) != 3 {
+		return 0, 0, InvalidArgument
+	}
+	var first, last uint64
+	var err error
+	if first, err = strconv.ParseUint(matches[1], 10, 64); err != nil {
+		return 0, 0, InvalidArgument
+	}
+	if last, err = strconv.ParseUint(matches[2], 10, 64); err != nil {
+		return 0, 0, InvalidArgument
+	}
+	if last < first {
+		return 0, 0, InvalidArgument
+	}
+	if last >= fileSize {
+		return 0, 0, InvalidRange
+	}
+	return first, last - first + 1, nil
+}
+
 // List parts
 // API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListParts.html
 func (o *ObjectNode) listPartsHandler(w http.ResponseWriter, r *http.Request) {
@@ -466,6 +695,14 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 		errorCode = ObjectModeConflict
 		return
 	}
+	if err == syscall.EDQUOT {
+		errorCode = QuotaExceeded
+		return
+	}
+	if err == syscall.EPERM {
+		errorCode = ObjectLocked
+		return
+	}
 	if err != nil {
 		log.LogErrorf("completeMultipartUploadHandler: complete multipart fail, requestID(%v) uploadID(%v) err(%v)",
 			GetRequestID(r), uploadId, err)
@@ -474,6 +711,7 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 	}
 	log.LogDebugf("completeMultipartUploadHandler: complete multipart, requestID(%v) uploadID(%v) path(%v)",
 		GetRequestID(r), uploadId, param.Object())
+	o.notifyEvent(r, param, vol, EventObjectCreatedComplete, newNotificationObject(param.Object(), fsFileInfo))
 
 	// write response
 	completeResult := CompleteMultipartResult{
@@ -493,6 +731,9 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 	// set response header
 	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
 	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	if len(fsFileInfo.VersionId) > 0 {
+		w.Header()[HeaderNameXAmzVersionId] = []string{fsFileInfo.VersionId}
+	}
 	if _, err = w.Write(bytes); err != nil {
 		log.LogErrorf("completeMultipartUploadHandler: write response body fail, requestID(%v) err(%v)", GetRequestID(r), err)
 		return
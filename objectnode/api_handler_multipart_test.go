@@ -0,0 +1,67 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import "testing"
+
+func TestParseCopySourceRange(t *testing.T) {
+	type sample struct {
+		rangeOpt  string
+		fileSize  uint64
+		offset    uint64
+		size      uint64
+		errorCode *ErrorCode
+	}
+	var samples = []sample{
+		{rangeOpt: "bytes=0-0", fileSize: 1, offset: 0, size: 1},
+		{rangeOpt: "bytes=0-1023", fileSize: 4096, offset: 0, size: 1024},
+		{rangeOpt: "bytes=1024-4095", fileSize: 4096, offset: 1024, size: 3072},
+		{rangeOpt: "bytes=1024-4096", fileSize: 4096, errorCode: InvalidRange},
+		{rangeOpt: "bytes=100-99", fileSize: 4096, errorCode: InvalidArgument},
+		{rangeOpt: "bytes=100-", fileSize: 4096, errorCode: InvalidArgument},
+		{rangeOpt: "bytes=-100", fileSize: 4096, errorCode: InvalidArgument},
+		{rangeOpt: "0-100", fileSize: 4096, errorCode: InvalidArgument},
+	}
+	for _, s := range samples {
+		offset, size, errorCode := parseCopySourceRange(s.rangeOpt, s.fileSize)
+		if errorCode != s.errorCode || offset != s.offset || size != s.size {
+			t.Fatalf("result mismatch: range(%v) fileSize(%v): expect(%v, %v, %v) actual(%v, %v, %v)",
+				s.rangeOpt, s.fileSize, s.offset, s.size, s.errorCode, offset, size, errorCode)
+		}
+	}
+}
+
+func TestParsePartNumber(t *testing.T) {
+	var samples = []struct {
+		raw        string
+		partNumber uint16
+		errorCode  *ErrorCode
+	}{
+		{raw: "1", partNumber: 1},
+		{raw: "10000", partNumber: 10000},
+		{raw: "0", errorCode: InvalidPartNumber},
+		{raw: "10001", errorCode: InvalidPartNumber},
+		{raw: "65537", errorCode: InvalidPartNumber},
+		{raw: "-1", errorCode: InvalidArgument},
+		{raw: "abc", errorCode: InvalidArgument},
+	}
+	for _, s := range samples {
+		partNumber, errorCode := parsePartNumber(s.raw)
+		if partNumber != s.partNumber || errorCode != s.errorCode {
+			t.Fatalf("parse part number %v mismatch: expect(%v %v) actual(%v %v)",
+				s.raw, s.partNumber, s.errorCode, partNumber, errorCode)
+		}
+	}
+}
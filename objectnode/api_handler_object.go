@@ -21,8 +21,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -32,10 +32,6 @@ import (
 	"github.com/chubaofs/chubaofs/util/log"
 )
 
-var (
-	rangeRegexp = regexp.MustCompile("^bytes=(\\d)+-(\\d)*$")
-)
-
 // Get object
 // API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObject.html
 func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -68,55 +64,9 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = NoSuchBucket
 		return
 	}
-	// parse http range option
-	var rangeOpt = strings.TrimSpace(r.Header.Get(HeaderNameRange))
-	var rangeLower uint64
-	var rangeUpper uint64
-	var isRangeRead bool
-	var partSize uint64
-	var partCount uint64
-	if len(rangeOpt) > 0 && rangeRegexp.MatchString(rangeOpt) {
-
-		var hyphenIndex = strings.Index(rangeOpt, "-")
-		if hyphenIndex < 0 {
-			errorCode = InvalidArgument
-			return
-		}
-
-		var lowerPart = rangeOpt[len("bytes="):hyphenIndex]
-		var upperPart = ""
-		if hyphenIndex+1 < len(rangeOpt) {
-			upperPart = rangeOpt[hyphenIndex+1:]
-		}
-
-		if len(lowerPart) > 0 {
-			if rangeLower, err = strconv.ParseUint(lowerPart, 10, 64); err != nil {
-				log.LogErrorf("getObjectHandler: parse range lower fail: requestID(%v) rangeOpt(%v) err(%v)",
-					GetRequestID(r), rangeOpt, err)
-				ServeInternalStaticErrorResponse(w, r)
-				return
-			}
-		}
-		if len(upperPart) > 0 {
-			if rangeUpper, err = strconv.ParseUint(upperPart, 10, 64); err != nil {
-				log.LogErrorf("getObjectHandler: parse range upper fail: requestID(%v) rangeOpt(%v) err(%v)",
-					GetRequestID(r), rangeOpt, err)
-				ServeInternalStaticErrorResponse(w, r)
-				return
-			}
-		}
-		if rangeUpper > 0 && rangeUpper < rangeLower {
-			// upper enabled and lower than lower side
-			if err = InvalidArgument.ServeResponse(w, r); err != nil {
-				log.LogErrorf("getObjectHandler: serve response fail: requestID(%v) err(%v)",
-					GetRequestID(r), err)
-				return
-			}
-		}
-
-		isRangeRead = true
-		log.LogDebugf("getObjectHandler: parse range option: requestID(%v) rangeOpt(%v) rangeLower(%v) rangeUpper(%v)",
-			GetRequestID(r), rangeOpt, rangeLower, rangeUpper)
+	var sseKey *SSECustomerKey
+	if sseKey, errorCode = ParseSSECustomerKey(r.Header); errorCode != nil {
+		return
 	}
 
 	responseCacheControl := r.URL.Query().Get(ParamResponseCacheControl)
@@ -131,12 +81,28 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	responseContentType := r.URL.Query().Get(ParamResponseContentType)
 	responseContentDisposition := r.URL.Query().Get(ParamResponseContentDisposition)
+	responseContentLanguage := r.URL.Query().Get(ParamResponseContentLanguage)
+	responseContentEncoding := r.URL.Query().Get(ParamResponseContentEncoding)
 
 	// get object meta
 	var fileInfo *FSFileInfo
-	fileInfo, err = vol.ObjectMeta(param.Object())
+	var versionId = r.URL.Query().Get(ParamVersionId)
+	if len(versionId) > 0 {
+		fileInfo, err = vol.ObjectVersionMeta(param.Object(), versionId)
+	} else {
+		fileInfo, err = vol.ObjectMeta(param.Object())
+	}
 	if err == syscall.ENOENT {
 		errorCode = NoSuchKey
+		if len(versionId) > 0 {
+			errorCode = NoSuchVersion
+		}
+		return
+	}
+	if err == nil && fileInfo.DeleteMarker {
+		w.Header()[HeaderNameXAmzDeleteMarker] = []string{"true"}
+		w.Header()[HeaderNameXAmzVersionId] = []string{fileInfo.VersionId}
+		errorCode = MethodNotAllowed
 		return
 	}
 	if err != nil {
@@ -145,75 +111,36 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = InternalErrorCode(err)
 		return
 	}
-
-	// parse request header
-	match := r.Header.Get(HeaderNameIfMatch)
-	noneMatch := r.Header.Get(HeaderNameIfNoneMatch)
-	modified := r.Header.Get(HeaderNameIfModifiedSince)
-	unmodified := r.Header.Get(HeaderNameIfUnmodifiedSince)
-
-	// Checking precondition: If-Match
-	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObject.html#API_GetObject_RequestSyntax
-	if match != "" {
-		if matchEag := strings.Trim(match, "\""); matchEag != fileInfo.ETag {
-			log.LogDebugf("getObjectHandler: object eTag(%s) not match If-Match header value(%s), requestId(%v)",
-				fileInfo.ETag, matchEag, GetRequestID(r))
-			errorCode = PreconditionFailed
-			return
-		}
-	}
-	// Checking precondition: If-Modified-Since
-	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObject.html#API_GetObject_RequestSyntax
-	if modified != "" {
-		fileModTime := fileInfo.ModifyTime
-		modifiedTime, err := parseTimeRFC1123(modified)
-		if err != nil {
-			log.LogErrorf("getObjectHandler: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
-			errorCode = InvalidArgument
-			return
-		}
-		if !fileModTime.After(modifiedTime) {
-			log.LogInfof("getObjectHandler: file modified time not after than specified time: requestID(%v)", GetRequestID(r))
-			errorCode = NotModified
-			return
-		}
+	if errorCode = checkSSECustomerKey(sseKey, fileInfo); errorCode != nil {
+		return
 	}
-	// Checking precondition: If-None-Match
+
+	// Checking preconditions: If-Match, If-Unmodified-Since, If-None-Match and If-Modified-Since
 	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObject.html#API_GetObject_RequestSyntax
-	if noneMatch != "" {
-		if noneMatchEtag := strings.Trim(noneMatch, "\""); noneMatchEtag == fileInfo.ETag {
-			log.LogErrorf("getObjectHandler: object eTag(%s) match If-None-Match header value(%s), requestId(%v)",
-				fileInfo.ETag, noneMatchEtag, GetRequestID(r))
-			errorCode = NotModified
-			return
+	if errorCode = checkPreconditions(r.Header, fileInfo.ETag, fileInfo.ModifyTime); errorCode != nil {
+		log.LogDebugf("getObjectHandler: precondition not hold: requestID(%v) volume(%v) path(%v) eTag(%v) status(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), fileInfo.ETag, errorCode.StatusCode)
+		if errorCode == NotModified {
+			// The validators are still sent in the not modified response.
+			w.Header()[HeaderNameETag] = []string{wrapUnescapedQuot(fileInfo.ETag)}
+			w.Header()[HeaderNameLastModified] = []string{formatTimeRFC1123(fileInfo.ModifyTime)}
 		}
+		return
 	}
-	// Checking precondition: If-Unmodified-Since
-	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObject.html#API_GetObject_RequestSyntax
-	if unmodified != "" && match == "" {
-		fileModTime := fileInfo.ModifyTime
-		modifiedTime, err := parseTimeRFC1123(unmodified)
-		if err != nil {
-			log.LogErrorf("getObjectHandler: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
-			errorCode = InvalidArgument
-			return
-		}
-		if fileModTime.After(modifiedTime) {
-			log.LogInfof("getObjectHandler: file modified time after than specified time: requestID(%v)", GetRequestID(r))
-			errorCode = PreconditionFailed
+
+	// Parse the byte ranges, which are ignored for the part download or if If-Range does not match.
+	var ranges []httpRange
+	var partNumber = r.URL.Query().Get(ParamPartNumber)
+	var rangeOpt = strings.TrimSpace(r.Header.Get(HeaderNameRange))
+	if len(rangeOpt) > 0 && len(partNumber) == 0 && !fileInfo.Mode.IsDir() &&
+		checkIfRange(r.Header, fileInfo.ETag, fileInfo.ModifyTime) {
+		if ranges, err = parseRange(rangeOpt, fileInfo.Size); err != nil {
+			w.Header()[HeaderNameContentRange] = []string{fmt.Sprintf("bytes */%d", fileInfo.Size)}
+			errorCode = InvalidRange
 			return
 		}
-	}
-
-	// validate and fix range
-	if isRangeRead && rangeUpper > uint64(fileInfo.Size)-1 {
-		rangeUpper = uint64(fileInfo.Size) - 1
-	}
-
-	// compute content length
-	var contentLength = uint64(fileInfo.Size)
-	if isRangeRead {
-		contentLength = rangeUpper - rangeLower + 1
+		log.LogDebugf("getObjectHandler: parse range option: requestID(%v) rangeOpt(%v) ranges(%v)",
+			GetRequestID(r), rangeOpt, ranges)
 	}
 
 	// get object tagging size
@@ -233,14 +160,23 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	// set response header for GetObject
 	w.Header()[HeaderNameAcceptRange] = []string{HeaderValueAcceptRange}
+	setServerSideEncryptionHeaders(w, vol, fileInfo.SSECustomerKeyMD5)
+	if len(fileInfo.VersionId) > 0 {
+		w.Header()[HeaderNameXAmzVersionId] = []string{fileInfo.VersionId}
+	}
+	if len(fileInfo.ReplicationStatus) > 0 {
+		w.Header()[HeaderNameXAmzReplicationStatus] = []string{fileInfo.ReplicationStatus}
+	}
+	setStorageClassHeader(w, fileInfo)
+	setObjectLockHeaders(w, fileInfo)
 	w.Header()[HeaderNameLastModified] = []string{formatTimeRFC1123(fileInfo.ModifyTime)}
+	var contentType = HeaderValueTypeStream
 	if len(responseContentType) > 0 {
-		w.Header()[HeaderNameContentType] = []string{responseContentType}
+		contentType = responseContentType
 	} else if len(fileInfo.MIMEType) > 0 {
-		w.Header()[HeaderNameContentType] = []string{fileInfo.MIMEType}
-	} else {
-		w.Header()[HeaderNameContentType] = []string{HeaderValueTypeStream}
+		contentType = fileInfo.MIMEType
 	}
+	w.Header()[HeaderNameContentType] = []string{contentType}
 	if len(responseContentDisposition) > 0 {
 		w.Header()[HeaderNameContentDisposition] = []string{responseContentDisposition}
 	} else if len(fileInfo.Disposition) > 0 {
@@ -256,9 +192,18 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	} else if len(fileInfo.Expires) > 0 {
 		w.Header()[HeaderNameExpires] = []string{fileInfo.Expires}
 	}
+	if len(responseContentLanguage) > 0 {
+		w.Header()[HeaderNameContentLanguage] = []string{responseContentLanguage}
+	}
+	if len(responseContentEncoding) > 0 {
+		w.Header()[HeaderNameContentEnc] = []string{responseContentEncoding}
+	}
 
+	var offset uint64
+	var size = uint64(fileInfo.Size)
+	var statusCode = http.StatusOK
+	var boundary string
 	//check request is whether contain param : partNumber
-	partNumber := r.URL.Query().Get(ParamPartNumber)
 	if len(partNumber) > 0 && fileInfo.Size >= MinParallelDownloadFileSize {
 		partNumberInt, err := strconv.ParseUint(partNumber, 10, 64)
 		if err != nil {
@@ -266,7 +211,7 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 			errorCode = InvalidArgument
 			return
 		}
-		partSize, partCount, rangeLower, rangeUpper, err = parsePartInfo(partNumberInt, uint64(fileInfo.Size))
+		partSize, partCount, rangeLower, rangeUpper, err := parsePartInfo(partNumberInt, uint64(fileInfo.Size))
 		log.LogDebugf("getObjectHandler: parsed partSize(%d), partCount(%d), rangeLower(%d), rangeUpper(%d)", partSize, partCount, rangeLower, rangeUpper)
 		if err != nil {
 			errorCode = InternalErrorCode(err)
@@ -285,14 +230,24 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		if len(fileInfo.ETag) > 0 && !strings.Contains(fileInfo.ETag, "-") {
 			w.Header()[HeaderNameETag] = []string{fmt.Sprintf("%s-%d", fileInfo.ETag, partCount)}
 		}
+		offset, size = rangeLower, rangeUpper-rangeLower+1
 	} else {
-		w.Header()[HeaderNameContentLength] = []string{strconv.FormatUint(contentLength, 10)}
 		if len(fileInfo.ETag) > 0 {
 			w.Header()[HeaderNameETag] = []string{wrapUnescapedQuot(fileInfo.ETag)}
 		}
-		if isRangeRead {
-			w.Header()[HeaderNameContentRange] = []string{fmt.Sprintf("bytes %d-%d/%d", rangeLower, rangeUpper, fileInfo.Size)}
+		switch {
+		case len(ranges) == 1:
+			w.Header()[HeaderNameContentRange] = []string{ranges[0].contentRange(fileInfo.Size)}
+			offset, size = uint64(ranges[0].start), uint64(ranges[0].length)
+			statusCode = http.StatusPartialContent
+		case len(ranges) > 1:
+			// Multiple ranges are served in a multipart/byteranges payload.
+			boundary = multipart.NewWriter(ioutil.Discard).Boundary()
+			w.Header()[HeaderNameContentType] = []string{HeaderValueMultipartByteRanges + boundary}
+			size = uint64(multipartRangesLength(ranges, contentType, fileInfo.Size, boundary))
+			statusCode = http.StatusPartialContent
 		}
+		w.Header()[HeaderNameContentLength] = []string{strconv.FormatUint(size, 10)}
 	}
 
 	// User-defined metadata
@@ -305,17 +260,41 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// get object content
-	var offset = rangeLower
-	var size = uint64(fileInfo.Size)
-	if isRangeRead || len(partNumber) > 0 {
-		if rangeUpper == 0 {
-			size = uint64(fileInfo.Size) - rangeLower
-		} else {
-			size = rangeUpper - rangeLower + 1
+	var readRange = func(writer io.Writer, offset, size uint64) error {
+		if sseKey != nil {
+			if writer, err = newSSECustomerWriter(writer, sseKey, fileInfo.SSECustomerIV, offset); err != nil {
+				return err
+			}
+		}
+		if len(versionId) > 0 {
+			return vol.ReadInode(param.Object(), fileInfo.Inode, writer, offset, size)
 		}
+		return vol.ReadFile(param.Object(), writer, offset, size)
 	}
-	err = vol.ReadFile(param.Object(), w, offset, size)
-	if err == syscall.ENOENT {
+	if statusCode != http.StatusOK {
+		w.WriteHeader(statusCode)
+	}
+	if len(ranges) > 1 {
+		var mw = multipart.NewWriter(w)
+		_ = mw.SetBoundary(boundary)
+		for _, rg := range ranges {
+			var part io.Writer
+			if part, err = mw.CreatePart(rg.partHeader(contentType, fileInfo.Size)); err == nil {
+				err = readRange(part, uint64(rg.start), uint64(rg.length))
+			}
+			if err != nil {
+				log.LogErrorf("getObjectHandler: read range from Volume fail: requestId(%v) volume(%v) path(%v) range(%v) err(%v)",
+					GetRequestID(r), param.Bucket(), param.Object(), rg, err)
+				return
+			}
+		}
+		if err = mw.Close(); err != nil {
+			log.LogErrorf("getObjectHandler: close multipart writer fail: requestId(%v) err(%v)", GetRequestID(r), err)
+		}
+		return
+	}
+	err = readRange(w, offset, size)
+	if err == syscall.ENOENT && statusCode == http.StatusOK {
 		errorCode = NoSuchKey
 		return
 	}
@@ -361,12 +340,30 @@ func (o *ObjectNode) headObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = NoSuchBucket
 		return
 	}
+	var sseKey *SSECustomerKey
+	if sseKey, errorCode = ParseSSECustomerKey(r.Header); errorCode != nil {
+		return
+	}
 
 	// get object meta
 	var fileInfo *FSFileInfo
-	fileInfo, err = vol.ObjectMeta(param.Object())
+	var versionId = r.URL.Query().Get(ParamVersionId)
+	if len(versionId) > 0 {
+		fileInfo, err = vol.ObjectVersionMeta(param.Object(), versionId)
+	} else {
+		fileInfo, err = vol.ObjectMeta(param.Object())
+	}
 	if err == syscall.ENOENT {
 		errorCode = NoSuchKey
+		if len(versionId) > 0 {
+			errorCode = NoSuchVersion
+		}
+		return
+	}
+	if err == nil && fileInfo.DeleteMarker {
+		w.Header()[HeaderNameXAmzDeleteMarker] = []string{"true"}
+		w.Header()[HeaderNameXAmzVersionId] = []string{fileInfo.VersionId}
+		errorCode = MethodNotAllowed
 		return
 	}
 	if err != nil {
@@ -375,70 +372,35 @@ func (o *ObjectNode) headObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = InternalErrorCode(err)
 		return
 	}
-
-	// parse request header
-	match := r.Header.Get(HeaderNameIfMatch)
-	noneMatch := r.Header.Get(HeaderNameIfNoneMatch)
-	modified := r.Header.Get(HeaderNameIfModifiedSince)
-	unmodified := r.Header.Get(HeaderNameIfUnmodifiedSince)
-
-	// Checking precondition: If-Match
-	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_HeadObject.html#API_HeadObject_RequestSyntax
-	if match != "" {
-		if matchEag := strings.Trim(match, "\""); matchEag != fileInfo.ETag {
-			log.LogDebugf("headObjectHandler: object eTag(%s) not match If-Match header value(%s), requestId(%v)",
-				fileInfo.ETag, matchEag, GetRequestID(r))
-			errorCode = PreconditionFailed
-			return
-		}
-	}
-	// Checking precondition: If-Modified-Since
-	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_HeadObject.html#API_HeadObject_RequestSyntax
-	if modified != "" {
-		fileModTime := fileInfo.ModifyTime
-		modifiedTime, err := parseTimeRFC1123(modified)
-		if err != nil {
-			log.LogDebugf("headObjectHandler: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
-			errorCode = InvalidArgument
-			return
-		}
-		if !fileModTime.After(modifiedTime) {
-			log.LogDebugf("headObjectHandler: file modified time not after than specified time: requestID(%v)", GetRequestID(r))
-			errorCode = NotModified
-			return
-		}
-	}
-	// Checking precondition: If-None-Match
-	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_HeadObject.html#API_HeadObject_RequestSyntax
-	if noneMatch != "" {
-		if noneMatchEtag := strings.Trim(noneMatch, "\""); noneMatchEtag == fileInfo.ETag {
-			log.LogDebugf("headObjectHandler: object eTag(%s) match If-None-Match header value(%s), requestId(%v)",
-				fileInfo.ETag, noneMatchEtag, GetRequestID(r))
-			errorCode = NotModified
-			return
-		}
+	if errorCode = checkSSECustomerKey(sseKey, fileInfo); errorCode != nil {
+		return
 	}
-	// Checking precondition: If-Unmodified-Since
+
+	// Checking preconditions: If-Match, If-Unmodified-Since, If-None-Match and If-Modified-Since
 	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_HeadObject.html#API_HeadObject_RequestSyntax
-	if unmodified != "" && match == "" {
-		fileModTime := fileInfo.ModifyTime
-		modifiedTime, err := parseTimeRFC1123(unmodified)
-		if err != nil {
-			log.LogDebugf("headObjectHandler: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
-			errorCode = InvalidArgument
-			return
-		}
-		if fileModTime.After(modifiedTime) {
-			log.LogDebugf("headObjectHandler: file modified time after than specified time: requestID(%v)", GetRequestID(r))
-			errorCode = PreconditionFailed
-			return
+	if errorCode = checkPreconditions(r.Header, fileInfo.ETag, fileInfo.ModifyTime); errorCode != nil {
+		log.LogDebugf("headObjectHandler: precondition not hold: requestID(%v) volume(%v) path(%v) eTag(%v) status(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), fileInfo.ETag, errorCode.StatusCode)
+		if errorCode == NotModified {
+			w.Header()[HeaderNameETag] = []string{wrapUnescapedQuot(fileInfo.ETag)}
+			w.Header()[HeaderNameLastModified] = []string{formatTimeRFC1123(fileInfo.ModifyTime)}
 		}
+		return
 	}
 
 	// set response header
 	w.Header()[HeaderNameAcceptRange] = []string{HeaderValueAcceptRange}
 	w.Header()[HeaderNameLastModified] = []string{formatTimeRFC1123(fileInfo.ModifyTime)}
 	w.Header()[HeaderNameContentMD5] = []string{EmptyContentMD5String}
+	setServerSideEncryptionHeaders(w, vol, fileInfo.SSECustomerKeyMD5)
+	if len(fileInfo.VersionId) > 0 {
+		w.Header()[HeaderNameXAmzVersionId] = []string{fileInfo.VersionId}
+	}
+	if len(fileInfo.ReplicationStatus) > 0 {
+		w.Header()[HeaderNameXAmzReplicationStatus] = []string{fileInfo.ReplicationStatus}
+	}
+	setStorageClassHeader(w, fileInfo)
+	setObjectLockHeaders(w, fileInfo)
 	if len(fileInfo.MIMEType) > 0 {
 		w.Header()[HeaderNameContentType] = []string{fileInfo.MIMEType}
 	} else {
@@ -565,20 +527,49 @@ func (o *ObjectNode) deleteObjectsHandler(w http.ResponseWriter, r *http.Request
 		return deleteReq.Objects[i].Key > deleteReq.Objects[j].Key
 	})
 
+	var bypassGovernance = o.allowBypassGovernance(r, param, vol)
 	var objectKeys = make([]string, 0, len(deleteReq.Objects))
 	for _, object := range deleteReq.Objects {
 		objectKeys = append(objectKeys, object.Key)
-		err = vol.DeletePath(object.Key)
-		log.LogWarnf("deleteObjectsHandler: delete: requestID(%v) volume(%v) path(%v)",
-			GetRequestID(r), vol.Name(), object.Key)
-		if err != nil {
-			deletedErrors = append(deletedErrors, Error{Key: object.Key, Message: err.Error()})
+		var deleted = Deleted{Key: object.Key, VersionId: object.VersionId}
+		if bypassGovernance {
+			if err = vol.releaseGovernanceRetention(object.Key, object.VersionId); err != nil && err != syscall.ENOENT {
+				log.LogErrorf("deleteObjectsHandler: release governance retention fail: requestID(%v) volume(%v) path(%v) versionID(%v) err(%v)",
+					GetRequestID(r), vol.Name(), object.Key, object.VersionId, err)
+			}
+		}
+		if len(object.VersionId) > 0 {
+			var deleteMarker bool
+			if deleteMarker, err = vol.DeleteVersion(object.Key, object.VersionId); err == syscall.ENOENT {
+				err = nil
+			}
+			if deleteMarker {
+				deleted.DeleteMarker = "true"
+				deleted.DeleteMarkerVersionId = object.VersionId
+			}
+		} else {
+			var markerVersionId string
+			if markerVersionId, err = vol.DeleteObject(object.Key); len(markerVersionId) > 0 {
+				deleted.DeleteMarker = "true"
+				deleted.DeleteMarkerVersionId = markerVersionId
+			}
+		}
+		log.LogWarnf("deleteObjectsHandler: delete: requestID(%v) volume(%v) path(%v) versionID(%v)",
+			GetRequestID(r), vol.Name(), object.Key, object.VersionId)
+		if err == syscall.EPERM {
+			deletedErrors = append(deletedErrors, Error{Key: object.Key, VersionId: object.VersionId,
+				Code: ObjectLocked.ErrorCode, Message: ObjectLocked.ErrorMessage})
+			log.LogWarnf("deleteObjectsHandler: object locked: requestID(%v) volume(%v) path(%v) versionID(%v)",
+				GetRequestID(r), vol.Name(), object.Key, object.VersionId)
+		} else if err != nil {
+			deletedErrors = append(deletedErrors, Error{Key: object.Key, VersionId: object.VersionId, Message: err.Error()})
 			log.LogErrorf("deleteObjectsHandler: delete object failed: requestID(%v) volume(%v) path(%v) err(%v)",
 				GetRequestID(r), vol.Name(), object.Key, err)
 		} else {
-			deletedObjects = append(deletedObjects, Deleted{Key: object.Key})
+			deletedObjects = append(deletedObjects, deleted)
 			log.LogDebugf("deleteObjectsHandler: delete object success: requestID(%v) volume(%v) path(%v)", GetRequestID(r),
 				vol.Name(), object.Key)
+			o.notifyDeleteEvent(r, param, vol, object.Key, object.VersionId, deleted.DeleteMarkerVersionId)
 		}
 	}
 
@@ -629,6 +620,49 @@ func parseCopySourceInfo(r *http.Request) (sourceBucket, sourceObject string) {
 	return
 }
 
+// checkCopySourceConditions checks the 'x-amz-copy-source-if-*' conditions of the copy request against the source object.
+func checkCopySourceConditions(r *http.Request, fileInfo *FSFileInfo) *ErrorCode {
+	copyMatch := r.Header.Get(HeaderNameXAmzCopyMatch)
+	noneMatch := r.Header.Get(HeaderNameXAmzCopyNoneMatch)
+	modified := r.Header.Get(HeaderNameXAmzCopyModified)
+	unModified := r.Header.Get(HeaderNameXAmzCopyUnModified)
+
+	// response 412
+	if modified != "" {
+		fileModTime := fileInfo.ModifyTime
+		modifiedTime, err := parseTimeRFC1123(modified)
+		if err != nil {
+			log.LogErrorf("checkCopySourceConditions: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
+			return InvalidArgument
+		}
+		if fileModTime.Before(modifiedTime) {
+			log.LogInfof("checkCopySourceConditions: file modified time not after than specified time: requestID(%v)", GetRequestID(r))
+			return PreconditionFailed
+		}
+	}
+	if unModified != "" {
+		fileModTime := fileInfo.ModifyTime
+		unmodifiedTime, err := parseTimeRFC1123(unModified)
+		if err != nil {
+			log.LogErrorf("checkCopySourceConditions: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
+			return InvalidArgument
+		}
+		if fileModTime.After(unmodifiedTime) {
+			log.LogInfof("checkCopySourceConditions: file modified time not before than specified time: requestID(%v)", GetRequestID(r))
+			return PreconditionFailed
+		}
+	}
+	if copyMatch != "" && fileInfo.ETag != copyMatch {
+		log.LogInfof("checkCopySourceConditions: eTag mismatched with specified: requestID(%v)", GetRequestID(r))
+		return PreconditionFailed
+	}
+	if noneMatch != "" && fileInfo.ETag == noneMatch {
+		log.LogInfof("checkCopySourceConditions: eTag same with specified: requestID(%v)", GetRequestID(r))
+		return PreconditionFailed
+	}
+	return nil
+}
+
 // Copy object
 // API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CopyObject.html .
 func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -659,9 +693,24 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check server side encryption headers, copying with the customer key is not supported.
+	if errorCode = checkServerSideEncryption(r.Header, vol); errorCode != nil {
+		return
+	}
+	if r.Header.Get(HeaderNameXAmzSSECustomerAlgorithm) != "" {
+		errorCode = NotImplemented
+		return
+	}
+
 	// Checking user-defined metadata
 	var metadata = ParseUserDefinedMetadata(r.Header)
 
+	// Check 'x-amz-storage-class' header
+	var storageClass string
+	if storageClass, errorCode = ParseStorageClass(r.Header, vol.ec.ColdStorageEnabled()); errorCode != nil {
+		return
+	}
+
 	// client can reset these system metadata: Content-Type, Content-Disposition
 	contentType := r.Header.Get(HeaderNameContentType)
 	contentDisposition := r.Header.Get(HeaderNameContentDisposition)
@@ -688,6 +737,7 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Metadata:     metadata,
 		CacheControl: cacheControl,
 		Expires:      expires,
+		StorageClass: storageClass,
 	}
 
 	sourceBucket, sourceObject := parseCopySourceInfo(r)
@@ -701,7 +751,7 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !userInfo.Policy.IsAuthorized(sourceBucket, "", proto.OSSCopyObjectAction) {
+	if !userInfo.Policy.IsAllowed(proto.OSSCopyObjectAction, sourceBucket, sourceObject, param.IAMContext()) {
 		log.LogErrorf("copyObjectHandler: no permission to copy from source bucket, requestID(%v), source bucket(%v), source file(%v), target bucket(%v), target file(%v)",
 			GetRequestID(r), sourceBucket, sourceObject, param.bucket, param.object)
 		errorCode = AccessDenied
@@ -721,49 +771,7 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// get header
-	copyMatch := r.Header.Get(HeaderNameXAmzCopyMatch)
-	noneMatch := r.Header.Get(HeaderNameXAmzCopyNoneMatch)
-	modified := r.Header.Get(HeaderNameXAmzCopyModified)
-	unModified := r.Header.Get(HeaderNameXAmzCopyUnModified)
-
-	// response 412
-	if modified != "" {
-		fileModTime := fileInfo.ModifyTime
-		modifiedTime, err := parseTimeRFC1123(modified)
-		if err != nil {
-			log.LogErrorf("copyObjectHandler: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
-			errorCode = InvalidArgument
-			return
-		}
-		if fileModTime.Before(modifiedTime) {
-			log.LogInfof("copyObjectHandler: file modified time not after than specified time: requestID(%v)", GetRequestID(r))
-			errorCode = PreconditionFailed
-			return
-		}
-	}
-	if unModified != "" {
-		fileModTime := fileInfo.ModifyTime
-		unmodifiedTime, err := parseTimeRFC1123(unModified)
-		if err != nil {
-			log.LogErrorf("copyObjectHandler: parse RFC1123 time fail: requestID(%v) err(%v)", GetRequestID(r), err)
-			errorCode = InvalidArgument
-			return
-		}
-		if fileModTime.After(unmodifiedTime) {
-			log.LogInfof("copyObjectHandler: file modified time not before than specified time: requestID(%v)", GetRequestID(r))
-			errorCode = PreconditionFailed
-			return
-		}
-	}
-	if copyMatch != "" && fileInfo.ETag != copyMatch {
-		log.LogInfof("copyObjectHandler: eTag mismatched with specified: requestID(%v)", GetRequestID(r))
-		errorCode = PreconditionFailed
-		return
-	}
-	if noneMatch != "" && fileInfo.ETag == noneMatch {
-		log.LogInfof("copyObjectHandler: eTag same with specified: requestID(%v)", GetRequestID(r))
-		errorCode = PreconditionFailed
+	if errorCode = checkCopySourceConditions(r, fileInfo); errorCode != nil {
 		return
 	}
 
@@ -775,8 +783,27 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = NoSuchBucket
 		return
 	}
+	var sseInfo *proto.XAttrInfo
+	if sseInfo, err = sourceVol.GetXAttr(sourceObject, XAttrKeyOSSSSECKeyMD5); err != nil && err != syscall.ENOENT {
+		log.LogErrorf("copyObjectHandler: load source SSE-C key MD5 fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), sourceBucket, sourceObject, err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if sseInfo != nil && len(sseInfo.Get(XAttrKeyOSSSSECKeyMD5)) > 0 {
+		errorCode = NotImplemented
+		return
+	}
 
 	fsFileInfo, err := vol.CopyFile(sourceVol, sourceObject, param.Object(), metadataDirective, opt)
+	if err == syscall.EDQUOT {
+		errorCode = QuotaExceeded
+		return
+	}
+	if err == syscall.EPERM {
+		errorCode = ObjectLocked
+		return
+	}
 	if err != nil && err != syscall.EINVAL && err != syscall.EFBIG {
 		log.LogErrorf("copyObjectHandler: Volume copy file fail: requestID(%v) Volume(%v) source(%v) target(%v) err(%v)",
 			GetRequestID(r), param.Bucket(), sourceObject, param.Object(), err)
@@ -796,6 +823,8 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	o.notifyEvent(r, param, vol, EventObjectCreatedCopy, newNotificationObject(param.Object(), fsFileInfo))
+
 	copyResult := CopyResult{
 		ETag:         fsFileInfo.ETag,
 		LastModified: formatTimeISO(fsFileInfo.ModifyTime),
@@ -811,6 +840,9 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	// set response header
 	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
 	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	if len(fsFileInfo.VersionId) > 0 {
+		w.Header()[HeaderNameXAmzVersionId] = []string{fsFileInfo.VersionId}
+	}
 	_, _ = w.Write(bytes)
 	return
 }
@@ -899,7 +931,7 @@ func (o *ObjectNode) getBucketV1Handler(w http.ResponseWriter, r *http.Request)
 			LastModified: formatTimeISO(file.ModifyTime),
 			ETag:         wrapUnescapedQuot(file.ETag),
 			Size:         int(file.Size),
-			StorageClass: StorageClassStandard,
+			StorageClass: objectStorageClass(file),
 			Owner:        bucketOwner,
 		}
 		contents = append(contents, content)
@@ -1046,7 +1078,7 @@ func (o *ObjectNode) getBucketV2Handler(w http.ResponseWriter, r *http.Request)
 				LastModified: formatTimeISO(file.ModifyTime),
 				ETag:         wrapUnescapedQuot(file.ETag),
 				Size:         int(file.Size),
-				StorageClass: StorageClassStandard,
+				StorageClass: objectStorageClass(file),
 				Owner:        bucketOwner,
 			}
 			contents = append(contents, content)
@@ -1120,6 +1152,16 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check server side encryption headers
+	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html#API_PutObject_RequestSyntax
+	if errorCode = checkServerSideEncryption(r.Header, vol); errorCode != nil {
+		return
+	}
+	var sseKey *SSECustomerKey
+	if sseKey, errorCode = ParseSSECustomerKey(r.Header); errorCode != nil {
+		return
+	}
+
 	// Check 'x-amz-tagging' header
 	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html#API_PutObject_RequestSyntax
 	var tagging *Tagging
@@ -1138,6 +1180,18 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 	// Checking user-defined metadata
 	var metadata = ParseUserDefinedMetadata(r.Header)
 
+	// Check 'x-amz-storage-class' header
+	var storageClass string
+	if storageClass, errorCode = ParseStorageClass(r.Header, vol.ec.ColdStorageEnabled()); errorCode != nil {
+		return
+	}
+
+	// Check object lock headers
+	var objectLock map[string]string
+	if objectLock, errorCode = ParseObjectLockHeaders(r.Header); errorCode != nil {
+		return
+	}
+
 	// Get request MD5, if request MD5 is not empty, compute and verify it.
 	requestMD5 := r.Header.Get(HeaderNameContentMD5)
 
@@ -1177,12 +1231,24 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Metadata:     metadata,
 		CacheControl: cacheControl,
 		Expires:      expires,
+		SSECustomer:  sseKey,
+		Replica:      r.Header.Get(HeaderNameXAmzReplicationStatus) == ReplicationStatusReplica,
+		ObjectLock:   objectLock,
+		StorageClass: storageClass,
 	}
 	fsFileInfo, err = vol.PutObject(param.Object(), r.Body, opt)
 	if err == syscall.EINVAL {
 		errorCode = ObjectModeConflict
 		return
 	}
+	if err == syscall.EPERM {
+		errorCode = ObjectLocked
+		return
+	}
+	if err == syscall.EDQUOT {
+		errorCode = QuotaExceeded
+		return
+	}
 	if err == io.ErrUnexpectedEOF {
 		log.LogWarnf("putObjectHandler: put object fail cause unexpected EOF: requestID(%v) volume(%v) path(%v) remote(%v) err(%v)",
 			GetRequestID(r), vol.Name(), param.Object(), getRequestIP(r), err)
@@ -1217,9 +1283,15 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	o.notifyEvent(r, param, vol, EventObjectCreatedPut, newNotificationObject(param.Object(), fsFileInfo))
+
 	// set response header
 	w.Header()[HeaderNameETag] = []string{wrapUnescapedQuot(fsFileInfo.ETag)}
 	w.Header()[HeaderNameContentLength] = []string{"0"}
+	setServerSideEncryptionHeaders(w, vol, fsFileInfo.SSECustomerKeyMD5)
+	if len(fsFileInfo.VersionId) > 0 {
+		w.Header()[HeaderNameXAmzVersionId] = []string{fsFileInfo.VersionId}
+	}
 	return
 }
 
@@ -1262,13 +1334,47 @@ func (o *ObjectNode) deleteObjectHandler(w http.ResponseWriter, r *http.Request)
 	log.LogInfof("Audit: delete object: requestID(%v) remote(%v) volume(%v) path(%v)",
 		GetRequestID(r), getRequestIP(r), vol.Name(), param.Object())
 
-	err = vol.DeletePath(param.Object())
+	var versionId = r.URL.Query().Get(ParamVersionId)
+	if o.allowBypassGovernance(r, param, vol) {
+		if err = vol.releaseGovernanceRetention(param.Object(), versionId); err != nil && err != syscall.ENOENT {
+			log.LogErrorf("deleteObjectHandler: release governance retention fail: "+
+				"requestID(%v) volume(%v) path(%v) versionID(%v) err(%v)",
+				GetRequestID(r), vol.Name(), param.Object(), versionId, err)
+			errorCode = InternalErrorCode(err)
+			return
+		}
+	}
+	var markerVersionId string
+	if len(versionId) > 0 {
+		var deleteMarker bool
+		deleteMarker, err = vol.DeleteVersion(param.Object(), versionId)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchVersion
+			return
+		}
+		if deleteMarker {
+			w.Header()[HeaderNameXAmzDeleteMarker] = []string{"true"}
+		}
+		w.Header()[HeaderNameXAmzVersionId] = []string{versionId}
+	} else {
+		markerVersionId, err = vol.DeleteObject(param.Object())
+		if len(markerVersionId) > 0 {
+			w.Header()[HeaderNameXAmzDeleteMarker] = []string{"true"}
+			w.Header()[HeaderNameXAmzVersionId] = []string{markerVersionId}
+		}
+	}
+	if err == syscall.EPERM {
+		errorCode = ObjectLocked
+		return
+	}
 	if err != nil {
 		log.LogErrorf("deleteObjectHandler: Volume delete file fail: "+
-			"requestID(%v) volume(%v) path(%v) err(%v)", GetRequestID(r), vol.Name(), param.Object(), err)
+			"requestID(%v) volume(%v) path(%v) versionID(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), versionId, err)
 		errorCode = InternalErrorCode(err)
 		return
 	}
+	o.notifyDeleteEvent(r, param, vol, param.Object(), versionId, markerVersionId)
 
 	w.WriteHeader(http.StatusNoContent)
 	return
@@ -60,6 +60,42 @@ func generateWarnDetail(r *http.Request, errorInfo string) string {
 		statusCode, requestID, action.Name(), bucket, object, errorInfo)
 }
 
+// responseRecorder records the status code and the number of bytes of the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	bytesSent  int64
+}
+
+func (w *responseRecorder) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseRecorder) Write(b []byte) (n int, err error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	n, err = w.ResponseWriter.Write(b)
+	w.bytesSent += int64(n)
+	return
+}
+
+func (w *responseRecorder) Flush() {
+	if flusher, is := w.ResponseWriter.(http.Flusher); is {
+		flusher.Flush()
+	}
+}
+
+func (w *responseRecorder) StatusCode() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+	return w.statusCode
+}
+
 // TraceMiddleware returns a middleware handler to trace request.
 // After receiving the request, the handler will assign a unique RequestID to
 // the request and record the processing time of the request.
@@ -101,16 +137,22 @@ func (o *ObjectNode) traceMiddleware(next http.Handler) http.Handler {
 		metric := exporter.NewTPCnt(fmt.Sprintf("action_%v", action.Name()))
 		defer metric.Set(err)
 
+		// record the status and size of the response for the access log
+		var recorder = &responseRecorder{ResponseWriter: w}
+
 		// Check action is whether enabled.
 		if !action.IsNone() && !o.disabledActions.Contains(action) {
 			// next
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(recorder, r)
 		} else {
 			// If current action is disabled, return access denied in response.
 			log.LogDebugf("traceMiddleware: disabled action: requestID(%v) action(%v)", requestID, action.Name())
-			_ = AccessDenied.ServeResponse(w, r)
+			_ = AccessDenied.ServeResponse(recorder, r)
 		}
 
+		// write the bucket access log
+		o.logAccess(r, recorder, action, startTime)
+
 		// failed request monitor
 		var statusCode = GetStatusCodeFromContext(r)
 		if IsMonitoredStatusCode(statusCode) {
@@ -149,11 +191,32 @@ func (o *ObjectNode) authMiddleware(next http.Handler) http.Handler {
 				next.ServeHTTP(w, r)
 				return
 			}
+			// The requests of the website endpoint are anonymous and checked by the website handler.
+			if o.isWebsiteRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// The POST object requests are signed in the form fields and authenticated by the handler.
+			// The STS requests are signed for the STS service and authenticated by the handler too.
+			if currentAction == proto.OSSPostObjectAction || currentAction == proto.OSSSTSAction {
+				next.ServeHTTP(w, r)
+				return
+			}
 
 			var (
 				pass bool
 				err  error
 			)
+			// The requests signed by temporary credentials are validated with the secret key in the session token.
+			if token := getRequestSessionToken(r); len(token) > 0 {
+				var session *Session
+				var ec *ErrorCode
+				if session, ec = o.loadRequestSession(r, token); ec != nil {
+					_ = ec.ServeResponse(w, r)
+					return
+				}
+				r = WithRequestSession(r, session)
+			}
 			//  check auth type
 			if isHeaderUsingSignatureAlgorithmV4(r) {
 				// using signature algorithm version 4 in header
@@ -183,10 +246,60 @@ func (o *ObjectNode) authMiddleware(next http.Handler) http.Handler {
 				return
 			}
 
+			// the requests signed by temporary credentials are authenticated as the user who they are issued for
+			SetRequester(r, parseRequestAuthInfo(r).accessKey)
 			next.ServeHTTP(w, r)
 		})
 }
 
+// QoSMiddleware returns a middleware handler to limit the requests of buckets and users.
+// The requests exceeding the request rate or bandwidth limits configured in the master are
+// rejected with SlowDown, and the bodies of the admitted requests and responses are throttled
+// to the bandwidth limits.
+// Workflow:
+//   request → [limits check] → [throttled next handler] → response
+func (o *ObjectNode) qosMiddleware(next http.Handler) http.Handler {
+	var handlerFunc http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		if o.qos == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var limiters = make([]*qosLimiter, 0, 2)
+		var bucket = mux.Vars(r)["bucket"]
+		if len(bucket) > 0 {
+			if vol, err := o.vm.Volume(bucket); err == nil {
+				var requestRate, bandwidth = vol.S3QoS()
+				if limiter := o.qos.bucketLimiter(bucket, requestRate, bandwidth); limiter != nil {
+					limiters = append(limiters, limiter)
+				}
+			}
+		}
+		var userID string
+		if accessKey := parseRequestAuthInfo(r).accessKey; len(accessKey) > 0 {
+			if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+				userID = userInfo.UserID
+				if limiter := o.qos.userLimiter(userID, userInfo.S3RequestRate, userInfo.S3Bandwidth); limiter != nil {
+					limiters = append(limiters, limiter)
+				}
+			}
+		}
+		if len(limiters) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !o.qos.admit(limiters) {
+			log.LogWarnf("qosMiddleware: request throttled: requestID(%v) volume(%v) userID(%v)",
+				GetRequestID(r), bucket, userID)
+			_ = SlowDown.ServeResponse(w, r)
+			return
+		}
+		r.Body = &qosReader{ReadCloser: r.Body, ctx: r.Context(), limiters: limiters}
+		next.ServeHTTP(&qosResponseWriter{ResponseWriter: w, ctx: r.Context(), limiters: limiters}, r)
+	}
+	return handlerFunc
+}
+
 // PolicyCheckMiddleware returns a pre-handle middleware handler to process policy check.
 // If action is configured in signatureIgnoreActions, then skip policy check.
 func (o *ObjectNode) policyCheckMiddleware(next http.Handler) http.Handler {
@@ -197,6 +310,10 @@ func (o *ObjectNode) policyCheckMiddleware(next http.Handler) http.Handler {
 				next.ServeHTTP(w, r)
 				return
 			}
+			if o.isWebsiteRequest(r) || action == proto.OSSPostObjectAction || action == proto.OSSSTSAction {
+				next.ServeHTTP(w, r)
+				return
+			}
 			wrappedNext := o.policyCheck(next.ServeHTTP)
 			wrappedNext.ServeHTTP(w, r)
 			return
@@ -25,6 +25,7 @@ const (
 	SignatrueV4          = "signature_v4"
 	PresignedV2          = "presigned_v2"
 	PresignedV4          = "presigned_v4"
+	PostForm             = "post_form"
 )
 
 type RequestAuthInfo struct {
@@ -58,6 +59,13 @@ func parseRequestAuthInfo(r *http.Request) *RequestAuthInfo {
 		if ai != nil {
 			auth.accessKey = ai.Credential.AccessKey
 		}
+	} else if accessKey := GetAccessKeyFromContext(r); len(accessKey) > 0 {
+		auth.authType = PostForm
+		auth.accessKey = accessKey
+	}
+	// The requests signed by temporary credentials act as the user who the credentials are issued for.
+	if session := GetRequestSession(r); session != nil {
+		auth.accessKey = session.ParentAccessKey
 	}
 
 	return auth
@@ -64,6 +64,8 @@ var SignatureV2WhiteQueries = map[string]struct{}{
 	"response-content-language":    struct{}{},
 	"response-content-type":        struct{}{},
 	"response-expires":             struct{}{},
+	"select":                       struct{}{},
+	"select-type":                  struct{}{},
 	"torrent":                      struct{}{},
 	"uploadId":                     struct{}{},
 	"uploads":                      struct{}{},
@@ -181,7 +183,9 @@ func (o *ObjectNode) validateHeaderBySignatureAlgorithmV2(r *http.Request) (bool
 	var accessKey = authInfo.accessKeyId
 	var secretKey string
 	var bucket = mux.Vars(r)["bucket"]
-	if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+	if session := GetRequestSession(r); session != nil {
+		secretKey = session.SecretKey
+	} else if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
 		secretKey = userInfo.SecretKey
 	} else if (err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists) &&
 		len(bucket) > 0 && GetActionFromContext(r) != proto.OSSCreateBucketAction {
@@ -295,7 +299,9 @@ func (o *ObjectNode) validateUrlBySignatureAlgorithmV2(r *http.Request) (bool, e
 
 	var secretKey string
 	var bucket = mux.Vars(r)["bucket"]
-	if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+	if session := GetRequestSession(r); session != nil {
+		secretKey = session.SecretKey
+	} else if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
 		secretKey = userInfo.SecretKey
 	} else if (err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists) &&
 		len(bucket) > 0 && GetActionFromContext(r) != proto.OSSCreateBucketAction {
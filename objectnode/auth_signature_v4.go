@@ -46,6 +46,7 @@ const (
 	XAmzAlgorithm     = "X-Amz-Algorithm"
 	XAmzDate          = "X-Amz-Date"
 	XAmzExpires       = "X-Amz-Expires"
+	XAmzSecurityToken = "X-Amz-Security-Token"
 
 	SignatureV4Algorithm = "AWS4-HMAC-SHA256"
 	SignatureV4Request   = "aws4-request"
@@ -114,7 +115,9 @@ func (o *ObjectNode) validateHeaderBySignatureAlgorithmV4(r *http.Request) (bool
 	var accessKey = req.Credential.AccessKey
 	var secretKey string
 	var bucket = mux.Vars(r)["bucket"]
-	if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+	if session := GetRequestSession(r); session != nil {
+		secretKey = session.SecretKey
+	} else if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
 		secretKey = userInfo.SecretKey
 	} else if (err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists) &&
 		len(bucket) > 0 && GetActionFromContext(r) != proto.OSSCreateBucketAction {
@@ -137,7 +140,7 @@ func (o *ObjectNode) validateHeaderBySignatureAlgorithmV4(r *http.Request) (bool
 		return false, err
 	}
 
-	newSignature := calculateSignatureV4(r, req.Credential, secretKey, req.SignedHeaders)
+	newSignature := calculateSignatureV4(r, req.Credential, secretKey, req.SignedHeaders, SERVICE, getContentHash(r.Header))
 	if req.Signature != newSignature {
 		log.LogDebugf("validateHeaderBySignatureAlgorithmV4: invalid signature: requestID(%v) client(%v) server(%v)",
 			GetRequestID(r), req.Signature, newSignature)
@@ -174,7 +177,9 @@ func (o *ObjectNode) validateUrlBySignatureAlgorithmV4(r *http.Request) (pass bo
 	var accessKey = req.Credential.AccessKey
 	var secretKey string
 	var bucket = mux.Vars(r)["bucket"]
-	if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+	if session := GetRequestSession(r); session != nil {
+		secretKey = session.SecretKey
+	} else if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
 		secretKey = userInfo.SecretKey
 	} else if (err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists) &&
 		len(bucket) > 0 && GetActionFromContext(r) != proto.OSSCreateBucketAction {
@@ -448,6 +453,10 @@ func createCanonicalQueryV4(req *signatureRequestV4) string {
 		if strings.Contains(key, "x-amz-server-side-") {
 			newQuery.Set(k, v[0])
 		}
+		if key == strings.ToLower(XAmzSecurityToken) {
+			newQuery.Set(k, v[0])
+			continue
+		}
 		if strings.HasPrefix(key, "x-amz") {
 			continue
 		}
@@ -480,21 +489,20 @@ func getEncodeQuery(r *http.Request) string {
 	return r.URL.Query().Encode()
 }
 
-// calculete signature v4
-func calculateSignatureV4(r *http.Request, cred credential, secretKey string, signedHeaders []string) string {
+// calculete signature v4 of the service with the hash of the payload
+func calculateSignatureV4(r *http.Request, cred credential, secretKey string, signedHeaders []string, service, contentHash string) string {
 	headers := r.Header
 
 	// get request start time in ISO8601 type
 	canonicalHeaderString := buildCanonicalHeaderString(r.Host, headers, signedHeaders)
 	headerNames := getCanonicalHeaderNames(signedHeaders)
-	contentHash := getContentHash(headers)
 	encodeQuery := getEncodeQuery(r)
 	canonicalURI := getCanonicalURI(r)
 	canonicalRequest := createCanonicalRequestString(
 		r.Method, canonicalURI, encodeQuery, canonicalHeaderString, headerNames, contentHash)
 
-	signingKey := buildSigningKey(SCHEME, secretKey, cred.Date, cred.Region, SERVICE, TERMINATOR)
-	scope := buildScope(cred.Date, cred.Region, SERVICE, TERMINATOR)
+	signingKey := buildSigningKey(SCHEME, secretKey, cred.Date, cred.Region, service, TERMINATOR)
+	scope := buildScope(cred.Date, cred.Region, service, TERMINATOR)
 
 	var timestamp = getStartTime(headers)
 	stringToSign := buildStringToSign(SignatureV4Algorithm, timestamp, scope, canonicalRequest)
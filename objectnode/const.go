@@ -14,7 +14,11 @@
 
 package objectnode
 
-import "os"
+import (
+	"os"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
 
 const (
 	MaxRetry = 3
@@ -37,9 +41,11 @@ const (
 	HeaderNameRange              = "Range"
 	HeaderNameExpect             = "Expect"
 	HeaderNameXForwardedExpect   = "X-Forwarded-Expect"
+	HeaderNameXForwardedProto    = "X-Forwarded-Proto"
 	HeaderNameLocation           = "Location"
 	HeaderNameCacheControl       = "Cache-Control"
 	HeaderNameExpires            = "Expires"
+	HeaderNameContentLanguage    = "Content-Language"
 
 	// Headers for CORS validation
 	Origin                                = "Origin"
@@ -59,6 +65,7 @@ const (
 	HeaderNameXAmzCopyNoneMatch       = "x-amz-copy-source-if-none-match"
 	HeaderNameXAmzCopyModified        = "x-amz-copy-source-if-modified-since"
 	HeaderNameXAmzCopyUnModified      = "x-amz-copy-source-if-unmodified-since"
+	HeaderNameXAmzCopySourceRange     = "x-amz-copy-source-range"
 	HeaderNameXAmzDecodeContentLength = "x-amz-decoded-content-length"
 	HeaderNameXAmzTagging             = "x-amz-tagging"
 	HeaderNameXAmzMetaPrefix          = "x-amz-meta-"
@@ -66,11 +73,28 @@ const (
 	HeaderNameXAmzMetadataDirective   = "x-amz-metadata-directive"
 	HeaderNameXAmzBucketRegion        = "x-amz-bucket-region"
 	HeaderNameXAmzTaggingCount        = "x-amz-tagging-count"
+	HeaderNameXAmzVersionId           = "x-amz-version-id"
+	HeaderNameXAmzDeleteMarker        = "x-amz-delete-marker"
+	HeaderNameXAmzReplicationStatus   = "x-amz-replication-status"
+	HeaderNameXAmzACL                 = "x-amz-acl"
+	HeaderNameXAmzSecurityToken       = "x-amz-security-token"
+	HeaderNameXAmzStorageClass        = "x-amz-storage-class"
+
+	HeaderNameXAmzObjectLockMode            = "x-amz-object-lock-mode"
+	HeaderNameXAmzObjectLockRetainUntilDate = "x-amz-object-lock-retain-until-date"
+	HeaderNameXAmzObjectLockLegalHold       = "x-amz-object-lock-legal-hold"
+	HeaderNameXAmzBypassGovernanceRetention = "x-amz-bypass-governance-retention"
+
+	HeaderNameXAmzServerSideEncryption = "x-amz-server-side-encryption"
+	HeaderNameXAmzSSECustomerAlgorithm = "x-amz-server-side-encryption-customer-algorithm"
+	HeaderNameXAmzSSECustomerKey       = "x-amz-server-side-encryption-customer-key"
+	HeaderNameXAmzSSECustomerKeyMD5    = "x-amz-server-side-encryption-customer-key-MD5"
 
 	HeaderNameIfMatch           = "If-Match"
 	HeaderNameIfNoneMatch       = "If-None-Match"
 	HeaderNameIfModifiedSince   = "If-Modified-Since"
 	HeaderNameIfUnmodifiedSince = "If-Unmodified-Since"
+	HeaderNameIfRange           = "If-Range"
 )
 
 const (
@@ -79,6 +103,7 @@ const (
 	HeaderValueTypeStream           = "application/octet-stream"
 	HeaderValueContentTypeXML       = "application/xml"
 	HeaderValueContentTypeDirectory = "application/directory"
+	HeaderValueContentTypeText      = "text/plain"
 )
 
 const (
@@ -97,6 +122,7 @@ const (
 	ParamMaxKeys    = "max-keys"
 	ParamStartAfter = "start-after"
 	ParamKey        = "key"
+	ParamVersionId  = "versionId"
 
 	ParamMaxParts       = "max-parts"
 	ParamUploadIdMarker = "upload-id-marker"
@@ -104,21 +130,33 @@ const (
 	ParamPartMaxUploads = "max-uploads"
 	ParamPartDelimiter  = "delimiter"
 	ParamEncodingType   = "encoding-type"
+	ParamVersionMarker  = "version-id-marker"
 
 	ParamResponseCacheControl       = "response-cache-control"
 	ParamResponseContentType        = "response-content-type"
 	ParamResponseContentDisposition = "response-content-disposition"
 	ParamResponseExpires            = "response-expires"
+	ParamResponseContentLanguage    = "response-content-language"
+	ParamResponseContentEncoding    = "response-content-encoding"
 )
 
 const (
 	MaxKeys    = 1000
 	MaxParts   = 1000
 	MaxUploads = 1000
+
+	// Part numbers of multipart uploads are in [1, MaxPartNumber].
+	MaxPartNumber = 10000
 )
 
 const (
 	StorageClassStandard = "Standard"
+	StorageClassCold     = "Cold"
+)
+
+const (
+	SSEAlgorithmAES256 = "AES256"
+	SSEAlgorithmKMS    = "aws:kms"
 )
 
 // XAttr keys for ObjectNode compatible feature
@@ -132,6 +170,18 @@ const (
 	XAttrKeyOSSCORS         = "oss:cors"
 	XAttrKeyOSSCacheControl = "oss:cache"
 	XAttrKeyOSSExpires      = "oss:expires"
+	XAttrKeyOSSVersioning   = "oss:versioning"
+	XAttrKeyOSSVersion      = proto.XAttrKeyObjectVersion
+	XAttrKeyOSSLifecycle    = "oss:lifecycle"
+	XAttrKeyOSSSSECKeyMD5   = "oss:sse-c-key-md5"
+	XAttrKeyOSSSSECIV       = "oss:sse-c-iv"
+	XAttrKeyOSSReplication  = "oss:replication"
+	XAttrKeyOSSReplStatus   = "oss:replication-status"
+	XAttrKeyOSSWebsite      = "oss:website"
+	XAttrKeyOSSNotification = "oss:notification"
+	XAttrKeyOSSPublicAccess = "oss:public-access-block"
+	XAttrKeyOSSLogging      = "oss:logging"
+	XAttrKeyOSSStorageClass = "oss:storage-class"
 
 	// Deprecated
 	XAttrKeyOSSETagDeprecated = "oss:tag"
@@ -168,6 +218,14 @@ const (
 	MetadataDirectiveReplace = "REPLACE"
 )
 
+const (
+	VersioningStatusEnabled   = "Enabled"
+	VersioningStatusSuspended = "Suspended"
+
+	// NullVersionId is the version ID of objects which are stored while versioning is not enabled.
+	NullVersionId = "null"
+)
+
 const (
 	TaggingCounts         = 10
 	TaggingKeyMaxLength   = 128
@@ -0,0 +1,166 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get bucket encryption
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketEncryption.html
+func (o *ObjectNode) getBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketEncryptionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+	if !vol.Encrypted() {
+		errorCode = NoSuchEncryptionConfiguration
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(NewServerSideEncryptionConfiguration()); err != nil {
+		log.LogErrorf("getBucketEncryptionHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket encryption
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketEncryption.html
+// The encryption of the volume is decided on creation, so only the configuration which is consistent with
+// the volume is accepted.
+func (o *ObjectNode) putBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketEncryptionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketEncryptionHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var config *ServerSideEncryptionConfiguration
+	if config, err = parseEncryptionConfig(bytes); err != nil {
+		log.LogErrorf("putBucketEncryptionHandler: parse encryption configuration fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidEncryptionConfiguration
+		return
+	}
+	switch config.Rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm {
+	case SSEAlgorithmAES256:
+	case SSEAlgorithmKMS:
+		errorCode = NotImplemented
+		return
+	default:
+		errorCode = InvalidEncryptionAlgorithm
+		return
+	}
+	if !vol.Encrypted() {
+		errorCode = ServerSideEncryptionNotEnabled
+		return
+	}
+
+	log.LogInfof("putBucketEncryptionHandler: put bucket encryption: requestID(%v) volume(%v)",
+		GetRequestID(r), param.Bucket())
+	return
+}
+
+// Delete bucket encryption
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketEncryption.html
+func (o *ObjectNode) deleteBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketEncryptionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+	if vol.Encrypted() {
+		errorCode = ServerSideEncryptionNotDisabled
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
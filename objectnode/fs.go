@@ -33,6 +33,17 @@ type FSFileInfo struct {
 	CacheControl string
 	Expires      string
 	Metadata   map[string]string `graphql:"-"` // User-defined metadata
+	VersionId    string
+	DeleteMarker bool
+	ReplicationStatus string
+	StorageClass      string
+
+	ObjectLockMode            string
+	ObjectLockRetainUntilDate string
+	ObjectLockLegalHold       string
+
+	SSECustomerKeyMD5 string
+	SSECustomerIV     []byte `graphql:"-"`
 }
 
 type Prefixes []string
@@ -15,6 +15,7 @@
 package objectnode
 
 import (
+	"crypto/cipher"
 	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
@@ -33,6 +34,7 @@ import (
 	"github.com/chubaofs/chubaofs/proto"
 	"github.com/chubaofs/chubaofs/sdk/data/stream"
 	"github.com/chubaofs/chubaofs/sdk/meta"
+	"github.com/chubaofs/chubaofs/util/auth"
 	"github.com/chubaofs/chubaofs/util/exporter"
 	"github.com/chubaofs/chubaofs/util/log"
 
@@ -76,6 +78,16 @@ type VolumeConfig struct {
 
 	// Get OSSMeta from the MetaNode every time if it is set true.
 	MetaStrict bool
+
+	// Ticket of the ObjectNode issued by the authnode, which is required by the encrypted volumes.
+	// This is a optional configuration item.
+	Ticket *AuthnodeTicket
+}
+
+// AuthnodeTicket is the configuration of the ticket issued by the authnode to the ObjectNode.
+type AuthnodeTicket struct {
+	ClientID string
+	auth.TicketMess
 }
 
 type PutFileOption struct {
@@ -85,6 +97,10 @@ type PutFileOption struct {
 	Metadata     map[string]string
 	CacheControl string
 	Expires      string
+	SSECustomer  *SSECustomerKey
+	Replica      bool              // written by the replication of another bucket
+	ObjectLock   map[string]string // extend attributes of the object lock
+	StorageClass string            // the data of the cold storage class is written to the cold data partitions
 }
 
 type ListFilesV1Option struct {
@@ -174,6 +190,52 @@ func (v *Volume) loadOSSMeta() {
 		return
 	}
 	v.metaLoader.storeCors(cors)
+
+	var versioning *VersioningConfiguration
+	if versioning, err = v.loadBucketVersioning(); err != nil {
+		return
+	}
+	v.metaLoader.storeVersioning(versioning)
+
+	// A broken lifecycle configuration does not stop the other configurations from being loaded,
+	// the lifecycle scanner loads it from store directly anyway.
+	var lifecycle *LifecycleConfiguration
+	if lifecycle, err = v.loadBucketLifecycle(); err != nil { // lifecycle needs to be cleared manually when deleting.
+		log.LogErrorf("loadOSSMeta: load lifecycle fail: volume(%v) err(%v)", v.name, err)
+		err = nil
+	} else {
+		v.metaLoader.storeLifecycle(lifecycle)
+	}
+
+	var replication *ReplicationConfiguration
+	if replication, err = v.loadBucketReplication(); err != nil {
+		return
+	}
+	v.metaLoader.storeReplication(replication)
+
+	var website *WebsiteConfiguration
+	if website, err = v.loadBucketWebsite(); err != nil {
+		return
+	}
+	v.metaLoader.storeWebsite(website)
+
+	var notification *NotificationConfiguration
+	if notification, err = v.loadBucketNotification(); err != nil {
+		return
+	}
+	v.metaLoader.storeNotification(notification)
+
+	var publicAccessBlock *PublicAccessBlockConfiguration
+	if publicAccessBlock, err = v.loadBucketPublicAccessBlock(); err != nil {
+		return
+	}
+	v.metaLoader.storePublicAccessBlock(publicAccessBlock)
+
+	var logging *BucketLoggingStatus
+	if logging, err = v.loadBucketLogging(); err != nil {
+		return
+	}
+	v.metaLoader.storeLogging(logging)
 }
 
 func (v *Volume) Name() string {
@@ -184,6 +246,16 @@ func (v *Volume) Owner() string {
 	return v.mw.Owner()
 }
 
+// Encrypted returns whether the data of the volume is encrypted at rest (SSE-S3).
+func (v *Volume) Encrypted() bool {
+	return v.ec.Encrypted()
+}
+
+// S3QoS returns the limits of requests per second and bytes per second of the volume, 0 means unlimited.
+func (v *Volume) S3QoS() (requestRate, bandwidth uint64) {
+	return v.mw.S3QoS()
+}
+
 func (v *Volume) CreateTime() time.Time {
 	return time.Unix(v.createTime, 0)
 }
@@ -236,6 +308,111 @@ func (v *Volume) loadBucketCors() (configuration *CORSConfiguration, err error)
 	return configuration, nil
 }
 
+func (v *Volume) loadBucketVersioning() (configuration *VersioningConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSVersioning); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &VersioningConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketLifecycle() (configuration *LifecycleConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSLifecycle); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &LifecycleConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketReplication() (configuration *ReplicationConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSReplication); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &ReplicationConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketWebsite() (configuration *WebsiteConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSWebsite); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &WebsiteConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketNotification() (configuration *NotificationConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSNotification); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &NotificationConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketPublicAccessBlock() (configuration *PublicAccessBlockConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSPublicAccess); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &PublicAccessBlockConfiguration{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketLogging() (configuration *BucketLoggingStatus, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSLogging); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &BucketLoggingStatus{}
+	if err = json.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
 func (v *Volume) getInodeFromPath(path string) (inode uint64, err error) {
 	if path == "/" {
 		return volumeRootInode, nil
@@ -472,8 +649,9 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 	}
 
 	// check file
+	var lookupInode uint64
 	var lookupMode uint32
-	_, lookupMode, err = v.mw.Lookup_ll(parentId, lastPathItem.Name)
+	lookupInode, lookupMode, err = v.mw.Lookup_ll(parentId, lastPathItem.Name)
 	if err != nil && err != syscall.ENOENT {
 		return
 	}
@@ -481,12 +659,18 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		err = syscall.EINVAL
 		return
 	}
+	// an object protected by object lock can not be overwritten unless it is retained as a version
+	if err == nil && !v.versioningEnabled() {
+		if err = v.checkObjectLock(lookupInode); err != nil {
+			return
+		}
+	}
 
 	// Intermediate data during the writing of new versions is managed through invisible files.
 	// This file has only inode but no dentry. In this way, this temporary file can be made invisible
 	// in the true sense. In order to avoid the adverse impact of other user operations on temporary data.
 	var invisibleTempDataInode *proto.InodeInfo
-	if invisibleTempDataInode, err = v.mw.InodeCreate_ll(DefaultFileMode, 0, 0, nil); err != nil {
+	if invisibleTempDataInode, err = v.mw.InodeCreateInDir_ll(parentId, DefaultFileMode, 0, 0, nil); err != nil {
 		return
 	}
 	defer func() {
@@ -509,12 +693,31 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 				v.name, invisibleTempDataInode.Inode, closeErr)
 		}
 	}()
+	if opt != nil && opt.StorageClass == StorageClassCold {
+		if err = v.ec.SetStorageClass(invisibleTempDataInode.Inode, proto.StorageClassCold); err != nil {
+			return
+		}
+	}
+
+	// The data is encrypted with the key provided by the client (SSE-C) and a random IV of the object.
+	var (
+		sseIV     []byte
+		sseStream cipher.Stream
+	)
+	if opt != nil && opt.SSECustomer != nil {
+		if sseIV, err = newSSECustomerIV(); err != nil {
+			return
+		}
+		if sseStream, err = opt.SSECustomer.NewStream(sseIV, 0); err != nil {
+			return
+		}
+	}
 
 	var (
 		md5Hash  = md5.New()
 		md5Value string
 	)
-	if _, err = v.streamWrite(invisibleTempDataInode.Inode, reader, md5Hash); err != nil {
+	if _, err = v.streamWrite(invisibleTempDataInode.Inode, reader, md5Hash, sseStream); err != nil {
 		return
 	}
 	// compute file md5
@@ -545,6 +748,19 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 			v.name, path, invisibleTempDataInode.Inode, XAttrKeyOSSETag, md5Value, err)
 		return nil, err
 	}
+	// Save the digest of the customer key and the IV of the object encrypted with the customer key.
+	if opt != nil && opt.SSECustomer != nil {
+		if err = v.mw.XAttrSet_ll(invisibleTempDataInode.Inode, []byte(XAttrKeyOSSSSECKeyMD5), []byte(opt.SSECustomer.KeyMD5)); err != nil {
+			log.LogErrorf("PutObject: store SSE-C key MD5 fail: volume(%v) path(%v) inode(%v) err(%v)",
+				v.name, path, invisibleTempDataInode.Inode, err)
+			return nil, err
+		}
+		if err = v.mw.XAttrSet_ll(invisibleTempDataInode.Inode, []byte(XAttrKeyOSSSSECIV), []byte(hex.EncodeToString(sseIV))); err != nil {
+			log.LogErrorf("PutObject: store SSE-C IV fail: volume(%v) path(%v) inode(%v) err(%v)",
+				v.name, path, invisibleTempDataInode.Inode, err)
+			return nil, err
+		}
+	}
 	// If MIME information is valid, use extended attributes for storage.
 	if opt != nil && opt.MIMEType != "" {
 		if err = v.mw.XAttrSet_ll(invisibleTempDataInode.Inode, []byte(XAttrKeyOSSMIME), []byte(opt.MIMEType)); err != nil {
@@ -569,6 +785,14 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 			return nil, err
 		}
 	}
+	// The objects of the standard storage class have no storage class attribute.
+	if opt != nil && opt.StorageClass == StorageClassCold {
+		if err = v.mw.XAttrSet_ll(invisibleTempDataInode.Inode, []byte(XAttrKeyOSSStorageClass), []byte(opt.StorageClass)); err != nil {
+			log.LogErrorf("PutObject: store storage class fail: volume(%v) path(%v) inode(%v) value(%v) err(%v)",
+				v.name, path, invisibleTempDataInode.Inode, opt.StorageClass, err)
+			return nil, err
+		}
+	}
 	// If request contain cache-control header, store it to xattr
 	if opt != nil && len(opt.CacheControl) > 0 {
 		if err = v.mw.XAttrSet_ll(invisibleTempDataInode.Inode, []byte(XAttrKeyOSSCacheControl), []byte(opt.CacheControl)); err != nil {
@@ -610,6 +834,22 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		ETag:       etagValue.ETag(),
 		Inode:      finalInode.Inode,
 	}
+	if opt != nil && opt.SSECustomer != nil {
+		fsInfo.SSECustomerKeyMD5 = opt.SSECustomer.KeyMD5
+		fsInfo.SSECustomerIV = sseIV
+	}
+
+	// record new inode in the change log if it is to be replicated
+	if err = v.prepareReplication(path, finalInode.Inode, opt != nil && opt.Replica); err != nil {
+		return nil, err
+	}
+
+	// record new inode as a version of the object if versioning is enabled
+	if fsInfo.VersionId, err = v.recordVersion(path, fsInfo); err != nil {
+		log.LogErrorf("PutObject: record version fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, fsInfo.Inode, err)
+		return nil, err
+	}
 
 	// apply new inode to dentry
 	err = v.applyInodeToDEntry(parentId, lastPathItem.Name, invisibleTempDataInode.Inode)
@@ -618,6 +858,11 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 			parentId, lastPathItem.Name, invisibleTempDataInode.Inode, err)
 		return
 	}
+	if opt != nil && len(opt.ObjectLock) > 0 {
+		if err = v.applyObjectLock(path, finalInode.Inode, opt.ObjectLock); err != nil {
+			return nil, err
+		}
+	}
 	return fsInfo, nil
 }
 
@@ -686,10 +931,13 @@ func (v *Volume) DeletePath(path string) (err error) {
 	if mode.IsDir() {
 		// Check if the directory is empty and cannot delete non-empty directories.
 		var dentries []proto.Dentry
-		dentries, err = v.mw.ReadDir_ll(ino)
+		dentries, err = v.mw.ReadDirLimit_ll(ino, "", 1)
 		if err != nil || len(dentries) > 0 {
 			return
 		}
+	} else if err = v.checkObjectLock(ino); err != nil {
+		// The object protected by object lock can not be deleted.
+		return
 	}
 	log.LogWarnf("DeletePath: delete: volume(%v) path(%v) inode(%v)", v.name, path, ino)
 	if _, err = v.mw.Delete_ll(parent, name, mode.IsDir()); err != nil {
@@ -741,6 +989,16 @@ func (v *Volume) InitMultipart(path string, opt *PutFileOption) (multipartID str
 		var encoded = opt.Tagging.Encode()
 		extend[XAttrKeyOSSTagging] = encoded
 	}
+	// The completed object is marked as a replica while the upload is initiated by replication.
+	if opt != nil && opt.Replica {
+		extend[XAttrKeyOSSReplStatus] = ReplicationStatusReplica
+	}
+	// The object lock is applied to the object when the upload is completed.
+	if opt != nil {
+		for key, value := range opt.ObjectLock {
+			extend[key] = value
+		}
+	}
 
 	// Iterate all the meta partition to create multipart id
 	multipartID, err = v.mw.InitMultipart_ll(path, extend)
@@ -802,7 +1060,7 @@ func (v *Volume) WritePart(path string, multipartId string, partId uint16, reade
 		etag    string
 		md5Hash = md5.New()
 	)
-	if size, err = v.streamWrite(tempInodeInfo.Inode, reader, md5Hash); err != nil {
+	if size, err = v.streamWrite(tempInodeInfo.Inode, reader, md5Hash, nil); err != nil {
 		return nil, err
 	}
 	// compute file md5
@@ -840,6 +1098,118 @@ func (v *Volume) WritePart(path string, multipartId string, partId uint16, reade
 	return fInfo, nil
 }
 
+// CopyPart writes the data of the source object in range [offset, offset+size) as a part of the multipart upload.
+// Within the same volume the part inode shares the extents of the source object in the range instead of copying
+// the data, like CopyFile does. The data is streamed from the source object if the extents can not be shared.
+func (v *Volume) CopyPart(sv *Volume, sourcePath string, sourceInode uint64, path string, multipartId string, partId uint16,
+	offset, size uint64) (*FSFileInfo, error) {
+	if v.name == sv.name && !v.ec.Encrypted() && size > 0 {
+		storageClass, err := sv.inodeStorageClass(sourceInode)
+		if err != nil {
+			log.LogErrorf("CopyPart: get source storage class fail: volume(%v) path(%v) inode(%v) err(%v)",
+				sv.name, sourcePath, sourceInode, err)
+			return nil, err
+		}
+		// the parts are written in the standard storage class
+		if storageClass == StorageClassStandard {
+			if fInfo, err := v.sharePart(sourceInode, path, multipartId, partId, offset, size); fInfo != nil || err != nil {
+				return fInfo, err
+			}
+		}
+	}
+
+	var reader, writer = io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(sv.ReadInode(sourcePath, sourceInode, writer, offset, size))
+	}()
+	defer func() {
+		_ = reader.Close()
+	}()
+	return v.WritePart(path, multipartId, partId, reader)
+}
+
+// sharePart creates the part inode sharing the extents of the source inode in range [offset, offset+size).
+// The ETag of the source object is reused if the part covers the whole object, otherwise the MD5 of the part
+// is computed by reading the part data. It returns nil if the meta node fails to copy the inode, and then the
+// caller falls back to copying the data.
+func (v *Volume) sharePart(sInode uint64, path string, multipartId string, partId uint16, offset, size uint64) (fInfo *FSFileInfo, err error) {
+	var exist bool
+	var sInodeInfo *proto.InodeInfo
+	if sInodeInfo, err = v.mw.InodeGet_ll(sInode); err != nil {
+		log.LogErrorf("sharePart: get source inode fail: volume(%v) inode(%v) err(%v)", v.name, sInode, err)
+		return
+	}
+	var sourceETag ETagValue
+	if offset == 0 && size == sInodeInfo.Size {
+		var xattr *proto.XAttrInfo
+		if xattr, err = v.mw.XAttrGet_ll(sInode, XAttrKeyOSSETag); err != nil {
+			log.LogErrorf("sharePart: get source ETag fail: volume(%v) inode(%v) err(%v)", v.name, sInode, err)
+			return
+		}
+		sourceETag = ParseETagValue(string(xattr.Get(XAttrKeyOSSETag)))
+	}
+
+	var partInodeInfo *proto.InodeInfo
+	if partInodeInfo, err = v.mw.InodeCopyRange_ll(sInode, offset, size); err != nil {
+		log.LogWarnf("sharePart: copy inode fail: volume(%v) inode(%v) offset(%v) size(%v) err(%v)",
+			v.name, sInode, offset, size, err)
+		return nil, nil
+	}
+	defer func() {
+		// An error has caused the entire process to fail. Delete the inode and release the shared extents.
+		if err != nil || exist || fInfo == nil {
+			log.LogWarnf("sharePart: unlink part inode: volume(%v) path(%v) multipartID(%v) partID(%v) inode(%v)",
+				v.name, path, multipartId, partId, partInodeInfo.Inode)
+			_, _ = v.mw.InodeUnlink_ll(partInodeInfo.Inode)
+			_ = v.mw.Evict(partInodeInfo.Inode)
+		}
+	}()
+	// the meta node which does not know the range copies the whole inode
+	if partInodeInfo.Size != size {
+		log.LogWarnf("sharePart: copied inode size mismatch: volume(%v) inode(%v) size(%v) copied(%v)",
+			v.name, sInode, size, partInodeInfo.Size)
+		return nil, nil
+	}
+
+	var etag string
+	if sourceETag.Valid() && sourceETag.PartNum == 0 && !sourceETag.TS.Before(sInodeInfo.ModifyTime) {
+		etag = sourceETag.Value
+	} else {
+		var md5Hash = md5.New()
+		if err = v.ReadInode(path, partInodeInfo.Inode, md5Hash, 0, size); err != nil {
+			log.LogErrorf("sharePart: read part data fail: volume(%v) inode(%v) err(%v)",
+				v.name, partInodeInfo.Inode, err)
+			return
+		}
+		etag = hex.EncodeToString(md5Hash.Sum(nil))
+	}
+
+	err = v.mw.AddMultipartPart_ll(path, multipartId, partId, size, etag, partInodeInfo.Inode)
+	if err == syscall.EEXIST {
+		// Result success but cleanup data.
+		err = nil
+		exist = true
+	}
+	if err != nil {
+		log.LogErrorf("sharePart: meta add multipart part fail: volume(%v) path(%v) multipartID(%v) partID(%v) inode(%v) size(%v) MD5(%v) err(%v)",
+			v.name, path, multipartId, partId, partInodeInfo.Inode, size, etag, err)
+		return
+	}
+	log.LogDebugf("sharePart: meta add multipart part: volume(%v) path(%v) multipartID(%v) partID(%v) inode(%v) size(%v) MD5(%v)",
+		v.name, path, multipartId, partId, partInodeInfo.Inode, size, etag)
+	_, fileName := splitPath(path)
+	fInfo = &FSFileInfo{
+		Path:       fileName,
+		Size:       int64(size),
+		Mode:       os.FileMode(DefaultFileMode),
+		ModifyTime: time.Now(),
+		CreateTime: partInodeInfo.CreateTime,
+		ETag:       etag,
+		Inode:      partInodeInfo.Inode,
+	}
+	return
+}
+
 func (v *Volume) AbortMultipart(path string, multipartID string) (err error) {
 	defer func() {
 		log.LogInfof("Audit: AbortMultipart: volume(%v) path(%v) multipartID(%v) err(%v)",
@@ -887,40 +1257,42 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 			v.name, path, multipartID, err)
 	}()
 
-	parts := multipartInfo.Parts
-	sort.SliceStable(parts, func(i, j int) bool { return parts[i].ID < parts[j].ID })
-
-	// create inode for complete data
-	var completeInodeInfo *proto.InodeInfo
-	if completeInodeInfo, err = v.mw.InodeCreate_ll(DefaultFileMode, 0, 0, nil); err != nil {
-		log.LogErrorf("CompleteMultipart: meta inode create fail: volume(%v) path(%v) multipartID(%v) err(%v)",
-			v.name, path, multipartID, err)
+	// an object protected by object lock can not be overwritten, check it before parts are merged
+	if err = v.checkOverwrite(path); err != nil {
 		return
 	}
-	log.LogDebugf("CompleteMultipart: meta inode create: volume(%v) path(%v) multipartID(%v) inode(%v)",
-		v.name, path, multipartID, completeInodeInfo.Inode)
-	defer func() {
-		if err != nil {
-			log.LogWarnf("CompleteMultipart: destroy inode: volume(%v) path(%v) multipartID(%v) inode(%v)",
-				v.name, path, multipartID, completeInodeInfo.Inode)
-			if deleteErr := v.mw.InodeDelete_ll(completeInodeInfo.Inode); deleteErr != nil {
-				log.LogErrorf("CompleteMultipart: meta delete complete inode fail: volume(%v) path(%v) multipartID(%v) inode(%v) err(%v)",
-					v.name, path, multipartID, completeInodeInfo.Inode, err)
-			}
-		}
-	}()
+
+	parts := multipartInfo.Parts
+	sort.SliceStable(parts, func(i, j int) bool { return parts[i].ID < parts[j].ID })
 
 	// merge complete extent keys
-	var size uint64
-	var completeExtentKeys = make([]proto.ExtentKey, 0)
-	var fileOffset uint64
+	var (
+		size               uint64
+		completeExtentKeys = make([]proto.ExtentKey, 0)
+		fileOffset         uint64
+		sharedPart         uint64
+		// The data of the parts is encrypted with the inodes of the parts, it can not be referred by the
+		// extent keys of the complete inode and is rewritten instead.
+		rewrite = v.ec.Encrypted()
+	)
 	for _, part := range parts {
 		var eks []proto.ExtentKey
-		if _, _, eks, err = v.mw.GetExtents(part.Inode); err != nil {
+		var cow bool
+		if _, _, eks, cow, err = v.mw.GetExtentsCOW(part.Inode); err != nil {
 			log.LogErrorf("CompleteMultipart: meta get extents fail: volume(%v) path(%v) multipartID(%v) partID(%v) inode(%v) err(%v)",
 				v.name, path, multipartID, part.ID, part.Inode, err)
 			return
 		}
+		// The references of the extents shared by the parts copied from other objects are counted by the
+		// meta partitions of the parts. The complete inode is created in the same meta partition to take
+		// over the references, and the data is rewritten if the shared parts are in different partitions.
+		if cow {
+			if sharedPart == 0 {
+				sharedPart = part.Inode
+			} else if !v.mw.SameInodePartition(sharedPart, part.Inode) {
+				rewrite = true
+			}
+		}
 		// recompute offsets of extent keys
 		for _, ek := range eks {
 			ek.FileOffset = fileOffset
@@ -930,6 +1302,35 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 		size += part.Size
 	}
 
+	// create inode for complete data
+	var completeInodeInfo *proto.InodeInfo
+	if sharedPart != 0 && !rewrite {
+		if completeInodeInfo, err = v.mw.InodeCreateNear_ll(sharedPart, DefaultFileMode, 0, 0, nil); err != nil {
+			log.LogWarnf("CompleteMultipart: meta inode create near shared part fail, rewrite data instead: volume(%v) path(%v) multipartID(%v) partInode(%v) err(%v)",
+				v.name, path, multipartID, sharedPart, err)
+			rewrite = true
+		}
+	}
+	if completeInodeInfo == nil {
+		if completeInodeInfo, err = v.mw.InodeCreate_ll(DefaultFileMode, 0, 0, nil); err != nil {
+			log.LogErrorf("CompleteMultipart: meta inode create fail: volume(%v) path(%v) multipartID(%v) err(%v)",
+				v.name, path, multipartID, err)
+			return
+		}
+	}
+	log.LogDebugf("CompleteMultipart: meta inode create: volume(%v) path(%v) multipartID(%v) inode(%v)",
+		v.name, path, multipartID, completeInodeInfo.Inode)
+	defer func() {
+		if err != nil {
+			log.LogWarnf("CompleteMultipart: destroy inode: volume(%v) path(%v) multipartID(%v) inode(%v)",
+				v.name, path, multipartID, completeInodeInfo.Inode)
+			if deleteErr := v.mw.InodeDelete_ll(completeInodeInfo.Inode); deleteErr != nil {
+				log.LogErrorf("CompleteMultipart: meta delete complete inode fail: volume(%v) path(%v) multipartID(%v) inode(%v) err(%v)",
+					v.name, path, multipartID, completeInodeInfo.Inode, err)
+			}
+		}
+	}()
+
 	// compute md5 hash
 	var md5Val string
 	if len(parts) == 1 {
@@ -944,7 +1345,13 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 	log.LogDebugf("CompleteMultipart: merge parts: volume(%v) path(%v) multipartID(%v) numParts(%v) MD5(%v)",
 		v.name, path, multipartID, len(parts), md5Val)
 
-	if err = v.mw.AppendExtentKeys(completeInodeInfo.Inode, completeExtentKeys); err != nil {
+	if rewrite {
+		if err = v.rewritePartData(completeInodeInfo.Inode, parts); err != nil {
+			log.LogErrorf("CompleteMultipart: rewrite part data fail: volume(%v) path(%v) multipartID(%v) inode(%v) err(%v)",
+				v.name, path, multipartID, completeInodeInfo.Inode, err)
+			return
+		}
+	} else if err = v.mw.AppendExtentKeys(completeInodeInfo.Inode, completeExtentKeys); err != nil {
 		log.LogErrorf("CompleteMultipart: meta append extent keys fail: volume(%v) path(%v) multipartID(%v) inode(%v) err(%v)",
 			v.name, path, multipartID, completeInodeInfo.Inode, err)
 		return
@@ -980,8 +1387,13 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 	}
 	// set user modified system metadata, self defined metadata and tag
 	extend := multipartInfo.Extend
+	var objectLock = make(map[string]string)
 	if len(extend) > 0 {
 		for key, value := range extend {
+			if isObjectLockXAttr(key) {
+				objectLock[key] = value
+				continue
+			}
 			if err = v.mw.XAttrSet_ll(completeInodeInfo.Inode, []byte(key), []byte(value)); err != nil {
 				log.LogErrorf("CompleteMultipart: store multipart extend fail: volume(%v) path(%v) inode(%v) key(%v) value(%v) err(%v)",
 					v.name, path, completeInodeInfo.Inode, key, value, err)
@@ -1001,10 +1413,15 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 			v.name, multipartID, path, err)
 		return nil, err
 	}
-	// delete part inodes
+	// delete part inodes, the data of the rewritten parts is released since it is not referred by the complete inode
 	for _, part := range parts {
 		log.LogWarnf("CompleteMultipart: destroy part inode: volume(%v) multipartID(%v) partID(%v) inode(%v)",
 			v.name, multipartID, part.ID, part.Inode)
+		if rewrite {
+			_, _ = v.mw.InodeUnlink_ll(part.Inode)
+			_ = v.mw.Evict(part.Inode)
+			continue
+		}
 		if err = v.mw.InodeDelete_ll(part.Inode); err != nil {
 			log.LogErrorf("CompleteMultipart: destroy part inode fail: volume(%v) multipartID(%v) partID(%v) inode(%v) err(%v)",
 				v.name, multipartID, part.ID, part.Inode, err)
@@ -1025,16 +1442,36 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 		Inode:      finalInode.Inode,
 	}
 
+	// record new inode in the change log if it is to be replicated
+	if err = v.prepareReplication(path, finalInode.Inode, extend[XAttrKeyOSSReplStatus] == ReplicationStatusReplica); err != nil {
+		return nil, err
+	}
+
+	// record new inode as a version of the object if versioning is enabled
+	if fInfo.VersionId, err = v.recordVersion(path, fInfo); err != nil {
+		log.LogErrorf("CompleteMultipart: record version fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, fInfo.Inode, err)
+		return nil, err
+	}
+
 	// apply new inode to dentry
 	err = v.applyInodeToDEntry(parentId, filename, completeInodeInfo.Inode)
 	if err != nil {
 		log.LogErrorf("CompleteMultipart: apply new inode to dentry fail, parent id (%v), file name(%v), inode(%v)",
 			parentId, filename, completeInodeInfo.Inode)
+		return fInfo, nil
+	}
+	if len(objectLock) > 0 {
+		if err = v.applyObjectLock(path, completeInodeInfo.Inode, objectLock); err != nil {
+			return nil, err
+		}
 	}
 	return fInfo, nil
 }
 
-func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash) (size uint64, err error) {
+// streamWrite writes the data read from the reader to the inode, the hash is computed over the data read,
+// which is encrypted by the stream before writing if the stream is not nil.
+func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash, stream cipher.Stream) (size uint64, err error) {
 	var (
 		buf                   = make([]byte, 2*util.BlockSize)
 		readN, writeN, offset int
@@ -1046,6 +1483,10 @@ func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash) (size
 			return
 		}
 		if readN > 0 {
+			copy(hashBuf, buf[:readN])
+			if stream != nil {
+				stream.XORKeyStream(buf[:readN], buf[:readN])
+			}
 			if writeN, err = v.ec.Write(inode, offset, buf[:readN], 0); err != nil {
 				log.LogErrorf("streamWrite: data write tmp file fail, inode(%v) offset(%v) err(%v)", inode, offset, err)
 				exporter.Warning(fmt.Sprintf("write data fail: volume(%v) inode(%v) offset(%v) size(%v) err(%v)",
@@ -1055,7 +1496,6 @@ func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash) (size
 			offset += writeN
 			// copy to md5 buffer, and then write to md5
 			size += uint64(writeN)
-			copy(hashBuf, buf[:readN])
 			if h != nil {
 				h.Write(hashBuf[:readN])
 			}
@@ -1068,6 +1508,56 @@ func (v *Volume) streamWrite(inode uint64, reader io.Reader, h hash.Hash) (size
 	return
 }
 
+// rewritePartData reads the data of the parts in order and writes it to the complete inode.
+func (v *Volume) rewritePartData(inode uint64, parts []*proto.MultipartPartInfo) (err error) {
+	if err = v.ec.OpenStream(inode); err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := v.ec.CloseStream(inode); closeErr != nil {
+			log.LogWarnf("rewritePartData: data close stream fail: inode(%v) err(%v)", inode, closeErr)
+		}
+	}()
+
+	var (
+		buf    = make([]byte, 2*util.BlockSize)
+		offset int
+	)
+	for _, part := range parts {
+		if err = v.ec.OpenStream(part.Inode); err != nil {
+			return
+		}
+		var n int
+		for partOffset := 0; uint64(partOffset) < part.Size; partOffset += n {
+			var size = len(buf)
+			if rest := int(part.Size) - partOffset; size > rest {
+				size = rest
+			}
+			if n, err = v.ec.Read(part.Inode, buf, partOffset, size); err != nil && err != io.EOF {
+				break
+			}
+			if n == 0 {
+				err = io.ErrUnexpectedEOF
+				break
+			}
+			if _, err = v.ec.Write(inode, offset, buf[:n], 0); err != nil {
+				break
+			}
+			offset += n
+		}
+		if closeErr := v.ec.CloseStream(part.Inode); closeErr != nil {
+			log.LogWarnf("rewritePartData: data close stream fail: inode(%v) err(%v)", part.Inode, closeErr)
+		}
+		if err != nil && err != io.EOF {
+			log.LogErrorf("rewritePartData: rewrite part fail: inode(%v) partID(%v) partInode(%v) err(%v)",
+				inode, part.ID, part.Inode, err)
+			return
+		}
+		err = nil
+	}
+	return v.ec.Flush(inode)
+}
+
 func (v *Volume) appendInodeHash(h hash.Hash, inode uint64, total uint64, preAllocatedBuf []byte) (err error) {
 	if err = v.ec.OpenStream(inode); err != nil {
 		log.LogErrorf("appendInodeHash: data open stream fail: inode(%v) err(%v)",
@@ -1135,6 +1625,12 @@ func (v *Volume) applyInodeToExistDentry(parentID uint64, name string, inode uin
 		return
 	}
 
+	// The old inode is retained as a version of the object, its link is owned by the version record.
+	if v.isVersionRetained(oldInode) {
+		log.LogDebugf("applyInodeToExistDentry: retain inode as version: volume(%v) inode(%v)", v.name, oldInode)
+		return
+	}
+
 	// unlink and evict old inode
 	log.LogWarnf("applyInodeToExistDentry: unlink inode: volume(%v) inode(%v)", v.name, oldInode)
 	if _, err = v.mw.InodeUnlink_ll(oldInode); err != nil {
@@ -1191,6 +1687,12 @@ func (v *Volume) ReadFile(path string, writer io.Writer, offset, size uint64) er
 	if mode.IsDir() {
 		return nil
 	}
+	return v.ReadInode(path, ino, writer, offset, size)
+}
+
+// ReadInode reads data of the specified inode which is referred by the path or by a version of the path.
+func (v *Volume) ReadInode(path string, ino uint64, writer io.Writer, offset, size uint64) error {
+	var err error
 
 	// read file data
 	var inoInfo *proto.InodeInfo
@@ -1269,13 +1771,25 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, err error) {
 		}
 		break
 	}
+	return v.inodeMeta(path, inoInfo, mode)
+}
 
+// inodeMeta builds the object meta information from the specified inode which is referred by the path
+// or by a version of the path.
+func (v *Volume) inodeMeta(path string, inoInfo *proto.InodeInfo, mode os.FileMode) (info *FSFileInfo, err error) {
+	var inode = inoInfo.Inode
 	var (
 		etagValue    ETagValue
 		mimeType     string
 		disposition  string
 		cacheControl string
 		expires      string
+		versionId    string
+		replStatus   string
+		storageClass string
+		objectLock   *proto.ObjectLock
+		sseKeyMD5    string
+		sseIV        []byte
 	)
 
 	if mode.IsDir() {
@@ -1289,7 +1803,9 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, err error) {
 		// 2. MIME type
 		var xattrs []*proto.XAttrInfo
 		var xattrKeys = []string{XAttrKeyOSSETag, XAttrKeyOSSETagDeprecated, XAttrKeyOSSMIME, XAttrKeyOSSDISPOSITION,
-			XAttrKeyOSSCacheControl, XAttrKeyOSSExpires}
+			XAttrKeyOSSCacheControl, XAttrKeyOSSExpires, XAttrKeyOSSVersion, XAttrKeyOSSReplStatus, XAttrKeyOSSSSECKeyMD5, XAttrKeyOSSSSECIV,
+			XAttrKeyOSSStorageClass}
+		xattrKeys = append(xattrKeys, proto.ObjectLockXAttrKeys...)
 		if xattrs, err = v.mw.BatchGetXAttr([]uint64{inode}, xattrKeys); err != nil {
 			log.LogErrorf("ObjectMeta: meta get xattr fail, volume(%v) inode(%v) path(%v) keys(%v) err(%v)",
 				v.name, inode, path, strings.Join(xattrKeys, ","), err)
@@ -1309,6 +1825,19 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, err error) {
 			disposition = string(xattr.Get(XAttrKeyOSSDISPOSITION))
 			cacheControl = string(xattr.Get(XAttrKeyOSSCacheControl))
 			expires = string(xattr.Get(XAttrKeyOSSExpires))
+			versionId = displayVersionID(string(xattr.Get(XAttrKeyOSSVersion)))
+			replStatus = string(xattr.Get(XAttrKeyOSSReplStatus))
+			storageClass = string(xattr.Get(XAttrKeyOSSStorageClass))
+			objectLock = proto.ParseObjectLock(
+				string(xattr.Get(proto.XAttrKeyObjectLockMode)),
+				string(xattr.Get(proto.XAttrKeyObjectLockRetainUntil)),
+				string(xattr.Get(proto.XAttrKeyObjectLockLegalHold)))
+			sseKeyMD5 = string(xattr.Get(XAttrKeyOSSSSECKeyMD5))
+			if sseIV, err = hex.DecodeString(string(xattr.Get(XAttrKeyOSSSSECIV))); err != nil {
+				log.LogErrorf("ObjectMeta: decode SSE-C IV fail: volume(%v) inode(%v) path(%v) err(%v)",
+					v.name, inode, path, err)
+				return
+			}
 		}
 	}
 
@@ -1344,6 +1873,21 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, err error) {
 		CacheControl: cacheControl,
 		Expires:      expires,
 		Metadata:     metadata,
+		VersionId:    versionId,
+
+		ReplicationStatus: replStatus,
+		StorageClass:      storageClass,
+		SSECustomerKeyMD5: sseKeyMD5,
+		SSECustomerIV:     sseIV,
+	}
+	if objectLock != nil {
+		if len(objectLock.Mode) > 0 && !objectLock.RetainUntil.IsZero() {
+			info.ObjectLockMode = objectLock.Mode
+			info.ObjectLockRetainUntilDate = objectLock.RetainUntil.UTC().Format(time.RFC3339)
+		}
+		if objectLock.LegalHold {
+			info.ObjectLockLegalHold = proto.ObjectLockLegalHoldOn
+		}
 	}
 	return
 }
@@ -1689,77 +2233,137 @@ func (v *Volume) recursiveScan(fileInfos []*FSFileInfo, prefixMap PrefixMap, par
 	// parallel operations that may delete the current directory.
 	// If got the syscall.ENOENT error when invoke readdir, it means that the above situation has occurred.
 	// At this time, stops process and returns success.
-	var children []proto.Dentry
-	children, err = v.mw.ReadDir_ll(parentId)
-	if err != nil && err != syscall.ENOENT {
-		return fileInfos, prefixMap, "", 0, err
-	}
-	if err == syscall.ENOENT {
-		return fileInfos, prefixMap, "", 0, nil
-	}
-
-	for _, child := range children {
-		var path = strings.Join(append(dirs, child.Name), pathSep)
-		if os.FileMode(child.Type).IsDir() {
-			path += pathSep
-		}
-		if prefix != "" && !strings.HasPrefix(path, prefix) {
-			continue
-		}
+	var prefixName, startName = scanStartName(dirs, prefix, marker)
+	var done bool
+	var scanChildren = func(children []proto.Dentry) bool {
+		for _, child := range children {
+			// Children are sorted by name, there is no more child matches the prefix.
+			if prefixName != "" && child.Name > prefixName && !strings.HasPrefix(child.Name, prefixName) {
+				return false
+			}
 
-		if marker != "" {
-			if !os.FileMode(child.Type).IsDir() && path < marker {
+			var path = strings.Join(append(dirs, child.Name), pathSep)
+			if os.FileMode(child.Type).IsDir() {
+				path += pathSep
+			}
+			if prefix != "" && !strings.HasPrefix(path, prefix) {
 				continue
 			}
-			if os.FileMode(child.Type).IsDir() && path < marker {
-				fileInfos, prefixMap, nextMarker, rc, err = v.recursiveScan(fileInfos, prefixMap, child.Inode, maxKeys, rc, append(dirs, child.Name), prefix, marker, delimiter)
-				if err != nil {
-					return fileInfos, prefixMap, nextMarker, rc, err
+
+			if marker != "" {
+				if !os.FileMode(child.Type).IsDir() && path < marker {
+					continue
 				}
-				if rc >= maxKeys && nextMarker != "" {
-					return fileInfos, prefixMap, nextMarker, rc, err
+				if os.FileMode(child.Type).IsDir() && path < marker {
+					fileInfos, prefixMap, nextMarker, rc, err = v.recursiveScan(fileInfos, prefixMap, child.Inode, maxKeys, rc, append(dirs, child.Name), prefix, marker, delimiter)
+					if err != nil {
+						done = true
+						return false
+					}
+					if rc >= maxKeys && nextMarker != "" {
+						done = true
+						return false
+					}
+					continue
 				}
-				continue
 			}
-		}
 
-		if delimiter != "" {
-			var nonPrefixPart = strings.Replace(path, prefix, "", 1)
-			if idx := strings.Index(nonPrefixPart, delimiter); idx >= 0 {
-				var commonPrefix = prefix + util.SubString(nonPrefixPart, 0, idx) + delimiter
-				if prefixMap.contain(commonPrefix) {
+			if delimiter != "" {
+				var nonPrefixPart = strings.Replace(path, prefix, "", 1)
+				if idx := strings.Index(nonPrefixPart, delimiter); idx >= 0 {
+					var commonPrefix = prefix + util.SubString(nonPrefixPart, 0, idx) + delimiter
+					if prefixMap.contain(commonPrefix) {
+						continue
+					}
+					if rc >= maxKeys {
+						nextMarker = commonPrefix
+						done = true
+						return false
+					}
+					prefixMap.AddPrefix(commonPrefix)
+					rc++
 					continue
 				}
-				if rc >= maxKeys {
-					return fileInfos, prefixMap, commonPrefix, rc, nil
+			}
+
+			fileInfo := &FSFileInfo{
+				Inode: child.Inode,
+				Path:  path,
+			}
+			if rc >= maxKeys {
+				nextMarker = path
+				done = true
+				return false
+			}
+			fileInfos = append(fileInfos, fileInfo)
+			rc++
+
+			if os.FileMode(child.Type).IsDir() {
+				fileInfos, prefixMap, nextMarker, rc, err = v.recursiveScan(fileInfos, prefixMap, child.Inode, maxKeys, rc, append(dirs, child.Name), prefix, marker, delimiter)
+				if err != nil {
+					done = true
+					return false
+				}
+				if rc >= maxKeys && nextMarker != "" {
+					done = true
+					return false
 				}
-				prefixMap.AddPrefix(commonPrefix)
-				rc++
-				continue
 			}
 		}
+		return true
+	}
 
-		fileInfo := &FSFileInfo{
-			Inode: child.Inode,
-			Path:  path,
-		}
-		if rc >= maxKeys {
-			return fileInfos, prefixMap, path, rc, nil
-		}
-		fileInfos = append(fileInfos, fileInfo)
-		rc++
+	// During the process of scanning the child nodes of the current directory, there may be other
+	// parallel operations that may delete the current directory.
+	// If got the syscall.ENOENT error when invoke readdir, it means that the above situation has occurred.
+	// At this time, stops process and returns success.
+	// The directory is read page by page, so that large directories can be scanned with bounded memory.
+	var readErr = v.mw.ReadDirPages_ll(parentId, startName, scanChildren)
+	if done {
+		return fileInfos, prefixMap, nextMarker, rc, err
+	}
+	if readErr != nil && readErr != syscall.ENOENT {
+		return fileInfos, prefixMap, "", 0, readErr
+	}
+	if readErr == syscall.ENOENT {
+		return fileInfos, prefixMap, "", 0, nil
+	}
+	return fileInfos, prefixMap, nextMarker, rc, nil
+}
 
-		if os.FileMode(child.Type).IsDir() {
-			fileInfos, prefixMap, nextMarker, rc, err = v.recursiveScan(fileInfos, prefixMap, child.Inode, maxKeys, rc, append(dirs, child.Name), prefix, marker, delimiter)
-			if err != nil {
-				return fileInfos, prefixMap, nextMarker, rc, err
-			}
-			if rc >= maxKeys && nextMarker != "" {
-				return fileInfos, prefixMap, nextMarker, rc, err
+// scanStartName returns the name of the child to start scanning the directory specified by dirs with,
+// since children whose name is less than it neither match the prefix nor greater than the marker.
+// The prefix name returned is the part of prefix which all matching children names must start with.
+func scanStartName(dirs []string, prefix, marker string) (prefixName, startName string) {
+	var base string
+	if len(dirs) > 0 {
+		base = strings.Join(dirs, pathSep) + pathSep
+	}
+	if len(prefix) > len(base) && strings.HasPrefix(prefix, base) {
+		prefixName = prefix[len(base):]
+		if idx := strings.Index(prefixName, pathSep); idx >= 0 {
+			prefixName = prefixName[:idx]
+		}
+	}
+	startName = prefixName
+	if len(marker) > len(base) && strings.HasPrefix(marker, base) {
+		var markerName = marker[len(base):]
+		if idx := strings.Index(markerName, pathSep); idx >= 0 {
+			markerName = markerName[:idx]
+		}
+		// A directory whose name is a prefix of the marker name may still contain paths greater
+		// than the marker if the following character of the marker name sorts before the path separator.
+		for i := 0; i < len(markerName); i++ {
+			if markerName[i] < pathSep[0] {
+				markerName = markerName[:i]
+				break
 			}
 		}
+		if markerName > startName {
+			startName = markerName
+		}
 	}
-	return fileInfos, prefixMap, nextMarker, rc, nil
+	return
 }
 
 // This method is used to supplement file metadata. Supplement the specified file
@@ -1787,8 +2391,8 @@ func (v *Volume) supplyListFileInfo(fileInfos []*FSFileInfo) (err error) {
 		}
 	}
 
-	// Get MD5 information in batches, then update to fileInfos
-	keys := []string{XAttrKeyOSSETag, XAttrKeyOSSETagDeprecated}
+	// Get MD5 and storage class information in batches, then update to fileInfos
+	keys := []string{XAttrKeyOSSETag, XAttrKeyOSSETagDeprecated, XAttrKeyOSSStorageClass}
 	xattrs, err := v.mw.BatchGetXAttr(inodes, keys)
 	if err != nil {
 		log.LogErrorf("supplyListFileInfo: batch get xattr fail, inodes(%v), err(%v)", inodes, err)
@@ -1815,6 +2419,7 @@ func (v *Volume) supplyListFileInfo(fileInfos []*FSFileInfo) (err error) {
 			if len(rawETag) > 0 {
 				etagValue = ParseETagValue(rawETag)
 			}
+			fileInfo.StorageClass = string(xattr.Get(XAttrKeyOSSStorageClass))
 		}
 		if !etagValue.Valid() || etagValue.TS.Before(fileInfo.ModifyTime) {
 			// The ETag is invalid or outdated then generate a new ETag and make update.
@@ -2032,6 +2637,12 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			log.LogInfof("CopyFile: target path is equal with source path, replace metadata, source path(%v) target path(%v) opt(%v)",
 				sourcePath, targetPath, opt)
 		}
+		// The object is transitioned to the cold storage class by copying it to itself.
+		if opt != nil && opt.StorageClass == StorageClassCold {
+			if err = v.transitionInode(sInode); err != nil {
+				return
+			}
+		}
 		return sv.ObjectMeta(sourcePath)
 	}
 
@@ -2044,7 +2655,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		pathItems  []PathItem
 		tLastName  string
 	)
-	if _, _, _, tMode, err = v.recursiveLookupTarget(targetPath); err != nil && err != syscall.ENOENT {
+	if _, tInode, _, tMode, err = v.recursiveLookupTarget(targetPath); err != nil && err != syscall.ENOENT {
 		log.LogErrorf("CopyFile: look up target path failed, target path(%v), err(%v)", targetPath, err)
 		return
 	}
@@ -2054,6 +2665,12 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			"target path(%v), target inode(%v), source path(%v), source inode(%v)", targetPath, tInode, sourcePath, sInode)
 		return nil, syscall.EINVAL
 	}
+	// an object protected by object lock can not be overwritten unless it is retained as a version
+	if err == nil && !tMode.IsDir() && !v.versioningEnabled() {
+		if err = v.checkObjectLock(tInode); err != nil {
+			return
+		}
+	}
 	// if source file mode is directory, return OK, and need't create target directory
 	if sMode == DefaultDirMode {
 		// create target directory
@@ -2097,10 +2714,33 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 	}
 	tLastName = pathItems[len(pathItems)-1].Name
 
-	// create target file inode and set target inode to be source file inode
-	if tInodeInfo, err = v.mw.InodeCreate_ll(uint32(sMode), 0, 0, nil); err != nil {
+	// Within the same volume the target inode shares the extents of source file instead of copying the data,
+	// the shared extents are reference counted by the meta node. It does not apply to the encrypted volume
+	// since the data is encrypted with the IV derived from the inode, nor to the target of another storage class.
+	var (
+		fileSize           = sInodeInfo.Size
+		etagValue          ETagValue
+		sourceStorageClass string
+		targetStorageClass = StorageClassStandard
+	)
+	if opt != nil && opt.StorageClass != "" {
+		targetStorageClass = opt.StorageClass
+	}
+	if sourceStorageClass, err = sv.inodeStorageClass(sInode); err != nil {
+		log.LogErrorf("CopyFile: get source storage class fail: volume(%v) path(%v) inode(%v) err(%v)",
+			sv.name, sourcePath, sInode, err)
 		return
 	}
+	if v.name == sv.name && !v.ec.Encrypted() && sourceStorageClass == targetStorageClass {
+		tInodeInfo, etagValue = v.shareFileExtents(sInode, sInodeInfo)
+	}
+
+	// create target file inode and set target inode to be source file inode
+	if tInodeInfo == nil {
+		if tInodeInfo, err = v.mw.InodeCreateInDir_ll(tParentId, uint32(sMode), 0, 0, nil); err != nil {
+			return
+		}
+	}
 	defer func() {
 		// An error has caused the entire process to fail. Delete the inode and release the written data.
 		if err != nil {
@@ -2112,84 +2752,25 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			_ = v.mw.Evict(tInodeInfo.Inode)
 		}
 	}()
-	if err = v.ec.OpenStream(tInodeInfo.Inode); err != nil {
-		return
-	}
-	defer func() {
-		if closeErr := v.ec.CloseStream(tInodeInfo.Inode); closeErr != nil {
-			log.LogErrorf("CopyFile: close target path stream fail: volume(%v) path(%v) inode(%v) err(%v)",
-				v.name, targetPath, tInodeInfo.Inode, closeErr)
-		}
-	}()
-
-	// write data to invisibleTempDataInode from source object
-	var (
-		fileSize    = sInodeInfo.Size
-		md5Hash     = md5.New()
-		md5Value    string
-		readN       int
-		writeN      int
-		readOffset  int
-		writeOffset int
-		readSize    int
-		buf         = make([]byte, 2*util.BlockSize)
-		hashBuf     = make([]byte, 2*util.BlockSize)
-	)
-	for {
-		readSize = len(buf)
-		if (int(fileSize) - readOffset) <= 0 {
-			break
-		}
-		if (int(fileSize) - readOffset) < len(buf) {
-			readSize = int(fileSize) - readOffset
-		}
-		readN, err = sv.ec.Read(sInode, buf, readOffset, readSize)
-		if err != nil && err != io.EOF {
+	if !etagValue.Valid() {
+		if etagValue, err = v.copyFileData(sv, sInode, tInodeInfo.Inode, fileSize, targetPath, targetStorageClass); err != nil {
 			return
 		}
-		if readN > 0 {
-			if writeN, err = v.ec.Write(tInodeInfo.Inode, writeOffset, buf[:readN], 0); err != nil {
-				log.LogErrorf("CopyFile: write target path from source fail, volume(%v) path(%v) inode(%v) target offset(%v) err(%v)",
-					v.name, targetPath, tInodeInfo.Inode, writeOffset, err)
-				return
-			}
-			readOffset += readN
-			writeOffset += writeN
-			// copy to md5 buffer, and then write to md5
-			copy(hashBuf, buf[:readN])
-			md5Hash.Write(hashBuf[:readN])
-		}
-		if err == io.EOF {
-			err = nil
-			break
-		}
-	}
-	// flush
-	if err = v.ec.Flush(tInodeInfo.Inode); err != nil {
-		log.LogErrorf("CopyFile: data flush inode fail, volume(%v) inode(%v), path (%v) err(%v)", v.name, tInodeInfo.Inode, targetPath, err)
-		return
-	}
-	md5Value = hex.EncodeToString(md5Hash.Sum(nil))
-	log.LogDebugf("Audit: copy file: write file finished, volume(%v), path(%v), etag(%v)", v.name, targetPath, md5Value)
-
-	var finalInode *proto.InodeInfo
-	if finalInode, err = v.mw.InodeGet_ll(tInodeInfo.Inode); err != nil {
-		log.LogErrorf("CopyFile: get finished target path final inode fail: volume(%v) path(%v) inode(%v) err(%v)",
-			v.name, targetPath, tInodeInfo.Inode, err)
-		return
-	}
-	var etagValue = ETagValue{
-		Value:   md5Value,
-		PartNum: 0,
-		TS:      finalInode.ModifyTime,
 	}
 
 	// Save target file ETag
-	if err = v.mw.XAttrSet_ll(finalInode.Inode, []byte(XAttrKeyOSSETag), []byte(etagValue.Encode())); err != nil {
+	if err = v.mw.XAttrSet_ll(tInodeInfo.Inode, []byte(XAttrKeyOSSETag), []byte(etagValue.Encode())); err != nil {
 		log.LogErrorf("CopyFile: store target file ETag fail: volume(%v) path(%v) inode(%v) key(%v) val(%v) err(%v)",
-			v.name, targetPath, tInodeInfo.Inode, XAttrKeyOSSETag, md5Value, err)
+			v.name, targetPath, tInodeInfo.Inode, XAttrKeyOSSETag, etagValue.Value, err)
 		return
 	}
+	if targetStorageClass == StorageClassCold {
+		if err = v.mw.XAttrSet_ll(tInodeInfo.Inode, []byte(XAttrKeyOSSStorageClass), []byte(targetStorageClass)); err != nil {
+			log.LogErrorf("CopyFile: store target storage class fail: volume(%v) path(%v) inode(%v) err(%v)",
+				v.name, targetPath, tInodeInfo.Inode, err)
+			return
+		}
+	}
 
 	// copy source file metadata to write target file metadata
 	if metaDirective != MetadataDirectiveReplace {
@@ -2215,7 +2796,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		// set tar xattr
 		if len(xattrs) > 0 {
 			for xk, xv := range xattrs[0].XAttrs {
-				if xk == XAttrKeyOSSETag {
+				if xk == XAttrKeyOSSETag || xk == XAttrKeyOSSVersion || xk == XAttrKeyOSSReplStatus || xk == XAttrKeyOSSStorageClass || isObjectLockXAttr(xk) {
 					continue
 				}
 				if err = v.mw.XAttrSet_ll(tInodeInfo.Inode, []byte(xk), []byte(xv)); err != nil {
@@ -2274,10 +2855,22 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		Mode:       sMode,
 		ModifyTime: tInodeInfo.ModifyTime,
 		CreateTime: tInodeInfo.CreateTime,
-		ETag:       md5Value,
+		ETag:       etagValue.ETag(),
 		Inode:      tInodeInfo.Inode,
 	}
 
+	// record new inode in the change log if it is to be replicated
+	if err = v.prepareReplication(targetPath, tInodeInfo.Inode, false); err != nil {
+		return nil, err
+	}
+
+	// record new inode as a version of the object if versioning is enabled
+	if info.VersionId, err = v.recordVersion(targetPath, info); err != nil {
+		log.LogErrorf("CopyFile: record version fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, targetPath, info.Inode, err)
+		return nil, err
+	}
+
 	// apply new inode to dentry
 	err = v.applyInodeToDEntry(tParentId, tLastName, tInodeInfo.Inode)
 	if err != nil {
@@ -2287,6 +2880,112 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 	return
 }
 
+// shareFileExtents creates an inode sharing the extents of the source inode. The ETag of the source file is
+// reused since the content is the same. It returns nil if the source ETag is not trustworthy or the meta node
+// fails to copy the inode, and then the caller falls back to copying the data.
+func (v *Volume) shareFileExtents(sInode uint64, sInodeInfo *proto.InodeInfo) (info *proto.InodeInfo, etagValue ETagValue) {
+	xattr, err := v.mw.XAttrGet_ll(sInode, XAttrKeyOSSETag)
+	if err != nil {
+		log.LogWarnf("shareFileExtents: get source ETag fail: volume(%v) inode(%v) err(%v)", v.name, sInode, err)
+		return nil, ETagValue{}
+	}
+	sourceETag := ParseETagValue(string(xattr.Get(XAttrKeyOSSETag)))
+	if !sourceETag.Valid() || sourceETag.TS.Before(sInodeInfo.ModifyTime) {
+		return nil, ETagValue{}
+	}
+	if info, err = v.mw.InodeCopy_ll(sInode); err != nil {
+		log.LogWarnf("shareFileExtents: copy inode fail: volume(%v) inode(%v) err(%v)", v.name, sInode, err)
+		return nil, ETagValue{}
+	}
+	etagValue = ETagValue{
+		Value:   sourceETag.Value,
+		PartNum: sourceETag.PartNum,
+		TS:      info.ModifyTime,
+	}
+	log.LogDebugf("shareFileExtents: volume(%v) source inode(%v) target inode(%v) etagValue(%v)",
+		v.name, sInode, info.Inode, etagValue)
+	return
+}
+
+// copyFileData reads the data of the source inode and writes it to the target inode, it returns the ETag of the data.
+func (v *Volume) copyFileData(sv *Volume, sInode, tInode uint64, fileSize uint64, targetPath, storageClass string) (etagValue ETagValue, err error) {
+	if err = v.ec.OpenStream(tInode); err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := v.ec.CloseStream(tInode); closeErr != nil {
+			log.LogErrorf("copyFileData: close target path stream fail: volume(%v) path(%v) inode(%v) err(%v)",
+				v.name, targetPath, tInode, closeErr)
+		}
+	}()
+	if storageClass == StorageClassCold {
+		if err = v.ec.SetStorageClass(tInode, proto.StorageClassCold); err != nil {
+			return
+		}
+	}
+
+	var (
+		md5Hash     = md5.New()
+		md5Value    string
+		readN       int
+		writeN      int
+		readOffset  int
+		writeOffset int
+		readSize    int
+		buf         = make([]byte, 2*util.BlockSize)
+		hashBuf     = make([]byte, 2*util.BlockSize)
+	)
+	for {
+		readSize = len(buf)
+		if (int(fileSize) - readOffset) <= 0 {
+			break
+		}
+		if (int(fileSize) - readOffset) < len(buf) {
+			readSize = int(fileSize) - readOffset
+		}
+		readN, err = sv.ec.Read(sInode, buf, readOffset, readSize)
+		if err != nil && err != io.EOF {
+			return
+		}
+		if readN > 0 {
+			if writeN, err = v.ec.Write(tInode, writeOffset, buf[:readN], 0); err != nil {
+				log.LogErrorf("copyFileData: write target path from source fail, volume(%v) path(%v) inode(%v) target offset(%v) err(%v)",
+					v.name, targetPath, tInode, writeOffset, err)
+				return
+			}
+			readOffset += readN
+			writeOffset += writeN
+			// copy to md5 buffer, and then write to md5
+			copy(hashBuf, buf[:readN])
+			md5Hash.Write(hashBuf[:readN])
+		}
+		if err == io.EOF {
+			err = nil
+			break
+		}
+	}
+	// flush
+	if err = v.ec.Flush(tInode); err != nil {
+		log.LogErrorf("copyFileData: data flush inode fail, volume(%v) inode(%v), path (%v) err(%v)", v.name, tInode, targetPath, err)
+		return
+	}
+	md5Value = hex.EncodeToString(md5Hash.Sum(nil))
+	log.LogDebugf("Audit: copy file: write file finished, volume(%v), path(%v), etag(%v)", v.name, targetPath, md5Value)
+
+	var finalInode *proto.InodeInfo
+	if finalInode, err = v.mw.InodeGet_ll(tInode); err != nil {
+		log.LogErrorf("copyFileData: get finished target path final inode fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, targetPath, tInode, err)
+		return
+	}
+	etagValue = ETagValue{
+		Value:   md5Value,
+		PartNum: 0,
+		TS:      finalInode.ModifyTime,
+	}
+	return
+}
+
 func (v *Volume) copyFile(parentID uint64, newFileName string, sourceFileInode uint64, mode uint32) (info *proto.InodeInfo, err error) {
 
 	if err = v.mw.DentryCreate_ll(parentID, newFileName, sourceFileInode, mode); err != nil {
@@ -2309,6 +3008,11 @@ func NewVolume(config *VolumeConfig) (*Volume, error) {
 			config.OnAsyncTaskError.OnError(err)
 		},
 	}
+	if config.Ticket != nil {
+		metaConfig.Authenticate = true
+		metaConfig.ClientID = config.Ticket.ClientID
+		metaConfig.TicketMess = config.Ticket.TicketMess
+	}
 
 	var metaWrapper *meta.MetaWrapper
 	if metaWrapper, err = meta.NewMetaWrapper(metaConfig); err != nil {
@@ -2320,12 +3024,15 @@ func NewVolume(config *VolumeConfig) (*Volume, error) {
 		}
 	}()
 	var extentConfig = &stream.ExtentConfig{
-		Volume:            config.Volume,
-		Masters:           config.Masters,
-		FollowerRead:      true,
-		OnAppendExtentKey: metaWrapper.AppendExtentKey,
-		OnGetExtents:      metaWrapper.GetExtents,
-		OnTruncate:        metaWrapper.Truncate,
+		Volume:             config.Volume,
+		Masters:            config.Masters,
+		FollowerRead:       true,
+		OnAppendExtentKey:  metaWrapper.AppendExtentKey,
+		OnGetExtents:       metaWrapper.GetExtentsCOW,
+		OnTruncate:         metaWrapper.Truncate,
+		OnPrepareMigration: metaWrapper.PrepareMigration,
+		OnReplaceExtents:   metaWrapper.ReplaceExtents,
+		OnGetEncryptionKey: metaWrapper.GetEncryptionKey,
 	}
 	var extentClient *stream.ExtentClient
 	if extentClient, err = stream.NewExtentClient(extentConfig); err != nil {
@@ -0,0 +1,221 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// The object lock of an object is kept in the extend attributes of its inode. Legal hold and compliance retention
+// are enforced by the meta node, so the locked objects can not be deleted or modified through any client.
+// Governance retention is enforced by the meta node as well, while it can be shortened or removed through
+// the ObjectNode by the bucket owner with header 'x-amz-bypass-governance-retention'.
+
+func (v *Volume) loadObjectLock(inode uint64) (lock *proto.ObjectLock, err error) {
+	var xattrs []*proto.XAttrInfo
+	if xattrs, err = v.mw.BatchGetXAttr([]uint64{inode}, proto.ObjectLockXAttrKeys); err != nil {
+		log.LogErrorf("loadObjectLock: meta get xattr fail: volume(%v) inode(%v) keys(%v) err(%v)",
+			v.name, inode, strings.Join(proto.ObjectLockXAttrKeys, ","), err)
+		return
+	}
+	if len(xattrs) == 0 || xattrs[0].Inode != inode {
+		return proto.ParseObjectLock("", "", ""), nil
+	}
+	var xattr = xattrs[0]
+	lock = proto.ParseObjectLock(
+		string(xattr.Get(proto.XAttrKeyObjectLockMode)),
+		string(xattr.Get(proto.XAttrKeyObjectLockRetainUntil)),
+		string(xattr.Get(proto.XAttrKeyObjectLockLegalHold)))
+	return
+}
+
+// checkObjectLock returns syscall.EPERM if the inode is protected by object lock.
+func (v *Volume) checkObjectLock(inode uint64) (err error) {
+	var lock *proto.ObjectLock
+	if lock, err = v.loadObjectLock(inode); err != nil {
+		return
+	}
+	if lock.Locked(time.Now()) {
+		return syscall.EPERM
+	}
+	return
+}
+
+// checkOverwrite returns syscall.EPERM if the object of the path exists and is protected by object lock.
+// The object can be overwritten while versioning is enabled, since it is retained as a version.
+func (v *Volume) checkOverwrite(path string) (err error) {
+	var ino uint64
+	var mode os.FileMode
+	if _, ino, _, mode, err = v.recursiveLookupTarget(path); err == syscall.ENOENT {
+		return nil
+	}
+	if err != nil || mode.IsDir() || v.versioningEnabled() {
+		return
+	}
+	return v.checkObjectLock(ino)
+}
+
+// applyObjectLock stores the object lock of a new object. It is applied after the inode has been applied to
+// the path, since a locked inode can not be released if the object fails to be created.
+func (v *Volume) applyObjectLock(path string, inode uint64, xattrs map[string]string) (err error) {
+	for key, value := range xattrs {
+		if err = v.mw.XAttrSet_ll(inode, []byte(key), []byte(value)); err != nil {
+			log.LogErrorf("applyObjectLock: store object lock fail: volume(%v) path(%v) inode(%v) key(%v) value(%v) err(%v)",
+				v.name, path, inode, key, value, err)
+			return
+		}
+	}
+	return
+}
+
+// releaseGovernanceRetention removes the governance retention of the object or the specified version, it is used
+// to delete the object with header 'x-amz-bypass-governance-retention'.
+func (v *Volume) releaseGovernanceRetention(path, versionId string) (err error) {
+	var ino uint64
+	if len(versionId) > 0 {
+		var info *FSFileInfo
+		if info, err = v.ObjectVersionMeta(path, versionId); err != nil || info.DeleteMarker {
+			return
+		}
+		ino = info.Inode
+	} else if ino, err = v.lookupObject(path); err != nil {
+		return
+	}
+	var lock *proto.ObjectLock
+	if lock, err = v.loadObjectLock(ino); err != nil {
+		return
+	}
+	if lock.Mode != proto.ObjectLockModeGovernance {
+		return
+	}
+	return v.removeRetention(path, ino)
+}
+
+func (v *Volume) removeRetention(path string, inode uint64) (err error) {
+	for _, key := range []string{proto.XAttrKeyObjectLockMode, proto.XAttrKeyObjectLockRetainUntil} {
+		if err = v.mw.XAttrDel_ll(inode, key); err != nil {
+			log.LogErrorf("removeRetention: remove object retention fail: volume(%v) path(%v) inode(%v) key(%v) err(%v)",
+				v.name, path, inode, key, err)
+			return
+		}
+	}
+	return
+}
+
+func (v *Volume) lookupObject(path string) (ino uint64, err error) {
+	var mode os.FileMode
+	if _, ino, _, mode, err = v.recursiveLookupTarget(path); err != nil {
+		return
+	}
+	if mode.IsDir() {
+		return 0, syscall.ENOENT
+	}
+	return
+}
+
+// GetObjectRetention returns the retention of the object, or nil if the retention is not configured.
+func (v *Volume) GetObjectRetention(path string) (retention *ObjectRetention, err error) {
+	var ino uint64
+	if ino, err = v.lookupObject(path); err != nil {
+		return
+	}
+	var lock *proto.ObjectLock
+	if lock, err = v.loadObjectLock(ino); err != nil {
+		return
+	}
+	if len(lock.Mode) == 0 || lock.RetainUntil.IsZero() {
+		return nil, nil
+	}
+	retention = &ObjectRetention{
+		Mode:            lock.Mode,
+		RetainUntilDate: lock.RetainUntil.UTC().Format(time.RFC3339),
+	}
+	return
+}
+
+// PutObjectRetention places the retention on the object. An active compliance retention can only be extended.
+// An active governance retention can only be shortened or removed with bypassGovernance.
+func (v *Volume) PutObjectRetention(path string, retention *ObjectRetention, bypassGovernance bool) (err error) {
+	defer func() {
+		log.LogInfof("Audit: PutObjectRetention: volume(%v) path(%v) retention(%v) err(%v)", v.name, path, retention, err)
+	}()
+	var ino uint64
+	if ino, err = v.lookupObject(path); err != nil {
+		return
+	}
+	var lock *proto.ObjectLock
+	if lock, err = v.loadObjectLock(ino); err != nil {
+		return
+	}
+	if lock.Retained(time.Now()) {
+		var extended = !retention.empty() && !retention.retainUntil().Before(lock.RetainUntil)
+		switch lock.Mode {
+		case proto.ObjectLockModeCompliance:
+			if !extended || retention.Mode != proto.ObjectLockModeCompliance {
+				return syscall.EPERM
+			}
+		case proto.ObjectLockModeGovernance:
+			if !extended && !bypassGovernance {
+				return syscall.EPERM
+			}
+		}
+	}
+	if retention.empty() {
+		return v.removeRetention(path, ino)
+	}
+	return v.applyObjectLock(path, ino, map[string]string{
+		proto.XAttrKeyObjectLockMode:        retention.Mode,
+		proto.XAttrKeyObjectLockRetainUntil: retention.RetainUntilDate,
+	})
+}
+
+// GetObjectLegalHold returns the legal hold status of the object.
+func (v *Volume) GetObjectLegalHold(path string) (legalHold *ObjectLegalHold, err error) {
+	var ino uint64
+	if ino, err = v.lookupObject(path); err != nil {
+		return
+	}
+	var lock *proto.ObjectLock
+	if lock, err = v.loadObjectLock(ino); err != nil {
+		return
+	}
+	legalHold = &ObjectLegalHold{Status: proto.ObjectLockLegalHoldOff}
+	if lock.LegalHold {
+		legalHold.Status = proto.ObjectLockLegalHoldOn
+	}
+	return
+}
+
+// PutObjectLegalHold places or removes the legal hold on the object.
+func (v *Volume) PutObjectLegalHold(path string, legalHold *ObjectLegalHold) (err error) {
+	defer func() {
+		log.LogInfof("Audit: PutObjectLegalHold: volume(%v) path(%v) status(%v) err(%v)", v.name, path, legalHold.Status, err)
+	}()
+	var ino uint64
+	if ino, err = v.lookupObject(path); err != nil {
+		return
+	}
+	if err = v.mw.XAttrSet_ll(ino, []byte(proto.XAttrKeyObjectLockLegalHold), []byte(legalHold.Status)); err != nil {
+		log.LogErrorf("PutObjectLegalHold: store legal hold fail: volume(%v) path(%v) inode(%v) status(%v) err(%v)",
+			v.name, path, ino, legalHold.Status, err)
+	}
+	return
+}
@@ -23,6 +23,20 @@ type ossMetaLoader interface {
 	storePolicy(p *Policy)
 	storeACL(p *AccessControlPolicy)
 	storeCors(cors *CORSConfiguration)
+	loadVersioning() (config *VersioningConfiguration, err error)
+	storeVersioning(config *VersioningConfiguration)
+	loadLifecycle() (config *LifecycleConfiguration, err error)
+	storeLifecycle(config *LifecycleConfiguration)
+	loadReplication() (config *ReplicationConfiguration, err error)
+	storeReplication(config *ReplicationConfiguration)
+	loadWebsite() (config *WebsiteConfiguration, err error)
+	storeWebsite(config *WebsiteConfiguration)
+	loadNotification() (config *NotificationConfiguration, err error)
+	storeNotification(config *NotificationConfiguration)
+	loadPublicAccessBlock() (config *PublicAccessBlockConfiguration, err error)
+	storePublicAccessBlock(config *PublicAccessBlockConfiguration)
+	loadLogging() (config *BucketLoggingStatus, err error)
+	storeLogging(config *BucketLoggingStatus)
 }
 
 type strictMetaLoader struct {
@@ -38,9 +52,23 @@ type OSSMeta struct {
 	policy     *Policy
 	acl        *AccessControlPolicy
 	corsConfig *CORSConfiguration
+	versioning *VersioningConfiguration
+	lifecycle  *LifecycleConfiguration
+	replConfig *ReplicationConfiguration
+	website    *WebsiteConfiguration
+	notifyConf *NotificationConfiguration
+	pabConfig  *PublicAccessBlockConfiguration
+	logging    *BucketLoggingStatus
 	policyLock sync.RWMutex
 	aclLock    sync.RWMutex
 	corsLock   sync.RWMutex
+	verLock    sync.RWMutex
+	lcLock     sync.RWMutex
+	replLock   sync.RWMutex
+	webLock    sync.RWMutex
+	notifyLock sync.RWMutex
+	pabLock    sync.RWMutex
+	logLock    sync.RWMutex
 }
 
 func (c *cacheMetaLoader) loadPolicy() (p *Policy, err error) {
@@ -85,6 +113,104 @@ func (c *cacheMetaLoader) storeCors(cors *CORSConfiguration) {
 	return
 }
 
+func (c *cacheMetaLoader) loadVersioning() (config *VersioningConfiguration, err error) {
+	c.om.verLock.RLock()
+	config = c.om.versioning
+	c.om.verLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storeVersioning(config *VersioningConfiguration) {
+	c.om.verLock.Lock()
+	c.om.versioning = config
+	c.om.verLock.Unlock()
+	return
+}
+
+func (c *cacheMetaLoader) loadLifecycle() (config *LifecycleConfiguration, err error) {
+	c.om.lcLock.RLock()
+	config = c.om.lifecycle
+	c.om.lcLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storeLifecycle(config *LifecycleConfiguration) {
+	c.om.lcLock.Lock()
+	c.om.lifecycle = config
+	c.om.lcLock.Unlock()
+	return
+}
+
+func (c *cacheMetaLoader) loadReplication() (config *ReplicationConfiguration, err error) {
+	c.om.replLock.RLock()
+	config = c.om.replConfig
+	c.om.replLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storeReplication(config *ReplicationConfiguration) {
+	c.om.replLock.Lock()
+	c.om.replConfig = config
+	c.om.replLock.Unlock()
+	return
+}
+
+func (c *cacheMetaLoader) loadWebsite() (config *WebsiteConfiguration, err error) {
+	c.om.webLock.RLock()
+	config = c.om.website
+	c.om.webLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storeWebsite(config *WebsiteConfiguration) {
+	c.om.webLock.Lock()
+	c.om.website = config
+	c.om.webLock.Unlock()
+	return
+}
+
+func (c *cacheMetaLoader) loadNotification() (config *NotificationConfiguration, err error) {
+	c.om.notifyLock.RLock()
+	config = c.om.notifyConf
+	c.om.notifyLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storeNotification(config *NotificationConfiguration) {
+	c.om.notifyLock.Lock()
+	c.om.notifyConf = config
+	c.om.notifyLock.Unlock()
+	return
+}
+
+func (c *cacheMetaLoader) loadPublicAccessBlock() (config *PublicAccessBlockConfiguration, err error) {
+	c.om.pabLock.RLock()
+	config = c.om.pabConfig
+	c.om.pabLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storePublicAccessBlock(config *PublicAccessBlockConfiguration) {
+	c.om.pabLock.Lock()
+	c.om.pabConfig = config
+	c.om.pabLock.Unlock()
+	return
+}
+
+func (c *cacheMetaLoader) loadLogging() (config *BucketLoggingStatus, err error) {
+	c.om.logLock.RLock()
+	config = c.om.logging
+	c.om.logLock.RUnlock()
+	return
+}
+
+func (c *cacheMetaLoader) storeLogging(config *BucketLoggingStatus) {
+	c.om.logLock.Lock()
+	c.om.logging = config
+	c.om.logLock.Unlock()
+	return
+}
+
 func (s *strictMetaLoader) loadPolicy() (p *Policy, err error) {
 	return s.v.loadBucketPolicy()
 }
@@ -102,3 +228,45 @@ func (s *strictMetaLoader) loadCors() (cors *CORSConfiguration, err error) {
 }
 
 func (s *strictMetaLoader) storeCors(cors *CORSConfiguration) {}
+
+func (s *strictMetaLoader) loadVersioning() (config *VersioningConfiguration, err error) {
+	return s.v.loadBucketVersioning()
+}
+
+func (s *strictMetaLoader) storeVersioning(config *VersioningConfiguration) {}
+
+func (s *strictMetaLoader) loadLifecycle() (config *LifecycleConfiguration, err error) {
+	return s.v.loadBucketLifecycle()
+}
+
+func (s *strictMetaLoader) storeLifecycle(config *LifecycleConfiguration) {}
+
+func (s *strictMetaLoader) loadReplication() (config *ReplicationConfiguration, err error) {
+	return s.v.loadBucketReplication()
+}
+
+func (s *strictMetaLoader) storeReplication(config *ReplicationConfiguration) {}
+
+func (s *strictMetaLoader) loadWebsite() (config *WebsiteConfiguration, err error) {
+	return s.v.loadBucketWebsite()
+}
+
+func (s *strictMetaLoader) storeWebsite(config *WebsiteConfiguration) {}
+
+func (s *strictMetaLoader) loadNotification() (config *NotificationConfiguration, err error) {
+	return s.v.loadBucketNotification()
+}
+
+func (s *strictMetaLoader) storeNotification(config *NotificationConfiguration) {}
+
+func (s *strictMetaLoader) loadPublicAccessBlock() (config *PublicAccessBlockConfiguration, err error) {
+	return s.v.loadBucketPublicAccessBlock()
+}
+
+func (s *strictMetaLoader) storePublicAccessBlock(config *PublicAccessBlockConfiguration) {}
+
+func (s *strictMetaLoader) loadLogging() (config *BucketLoggingStatus, err error) {
+	return s.v.loadBucketLogging()
+}
+
+func (s *strictMetaLoader) storeLogging(config *BucketLoggingStatus) {}
@@ -0,0 +1,85 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Objects are replicated asynchronously. Before an object is applied to its path, a change is appended to the
+// change log kept by the meta partitions and the inode is marked PENDING in extend attribute 'oss:replication-status'.
+// The Replicator consumes the change log and replicates the current state of the path to the destination bucket,
+// so that the changes of the same path are not required to be consumed in order.
+
+func (v *Volume) replicationConfig() *ReplicationConfiguration {
+	config, err := v.metaLoader.loadReplication()
+	if err != nil {
+		log.LogErrorf("replicationConfig: load replication configuration fail: volume(%v) err(%v)", v.name, err)
+		return nil
+	}
+	return config
+}
+
+// prepareReplication records the object which is going to be applied to the path in the change log if it matches
+// a replication rule. The object which is written by the replication of another bucket is marked as REPLICA
+// and never replicated again.
+func (v *Volume) prepareReplication(path string, inode uint64, replica bool) (err error) {
+	var status string
+	switch {
+	case replica:
+		status = ReplicationStatusReplica
+	case v.replicationConfig().MatchRule(path) != nil:
+		status = ReplicationStatusPending
+	default:
+		return
+	}
+	if err = v.mw.XAttrSet_ll(inode, []byte(XAttrKeyOSSReplStatus), []byte(status)); err != nil {
+		log.LogErrorf("prepareReplication: store replication status fail: volume(%v) path(%v) inode(%v) status(%v) err(%v)",
+			v.name, path, inode, status, err)
+		return
+	}
+	if replica {
+		return
+	}
+	if err = v.mw.AppendChangeLog_ll(proto.ChangeLogOpPut, path); err != nil {
+		log.LogErrorf("prepareReplication: append change log fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, inode, err)
+		return
+	}
+	return
+}
+
+// recordDeletion records the deletion of the object in the change log if the matched replication rule
+// replicates deletions.
+func (v *Volume) recordDeletion(path string) (err error) {
+	var rule = v.replicationConfig().MatchRule(path)
+	if rule == nil || !rule.DeleteReplicated() {
+		return
+	}
+	if err = v.mw.AppendChangeLog_ll(proto.ChangeLogOpDelete, path); err != nil {
+		log.LogErrorf("recordDeletion: append change log fail: volume(%v) path(%v) err(%v)", v.name, path, err)
+		return
+	}
+	return
+}
+
+// setReplicationStatus updates the replication status of the object after the replication is finished.
+func (v *Volume) setReplicationStatus(path string, inode uint64, status string) {
+	if err := v.mw.XAttrSet_ll(inode, []byte(XAttrKeyOSSReplStatus), []byte(status)); err != nil {
+		log.LogWarnf("setReplicationStatus: store replication status fail: volume(%v) path(%v) inode(%v) status(%v) err(%v)",
+			v.name, path, inode, status, err)
+	}
+}
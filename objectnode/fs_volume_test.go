@@ -13,3 +13,33 @@
 // permissions and limitations under the License.
 
 package objectnode
+
+import "testing"
+
+func TestScanStartName(t *testing.T) {
+	type sample struct {
+		dirs       []string
+		prefix     string
+		marker     string
+		prefixName string
+		startName  string
+	}
+	var samples = []sample{
+		{dirs: nil, prefix: "", marker: "", prefixName: "", startName: ""},
+		{dirs: nil, prefix: "logs/2020", marker: "", prefixName: "logs", startName: "logs"},
+		{dirs: []string{"logs"}, prefix: "logs/2020", marker: "", prefixName: "2020", startName: "2020"},
+		{dirs: []string{"logs", "2020"}, prefix: "logs/2020", marker: "", prefixName: "", startName: ""},
+		{dirs: nil, prefix: "", marker: "part-0100", prefixName: "", startName: "part"},
+		{dirs: []string{"data"}, prefix: "", marker: "data/part0100/a", prefixName: "", startName: "part0100"},
+		{dirs: []string{"data"}, prefix: "data/p", marker: "data/a", prefixName: "p", startName: "p"},
+		{dirs: []string{"data"}, prefix: "data/p", marker: "data/q", prefixName: "p", startName: "q"},
+		{dirs: []string{"other"}, prefix: "", marker: "data/q", prefixName: "", startName: ""},
+	}
+	for _, s := range samples {
+		prefixName, startName := scanStartName(s.dirs, s.prefix, s.marker)
+		if prefixName != s.prefixName || startName != s.startName {
+			t.Fatalf("result mismatch: dirs(%v) prefix(%v) marker(%v): expect(%v, %v) actual(%v, %v)",
+				s.dirs, s.prefix, s.marker, s.prefixName, s.startName, prefixName, startName)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Object versions are stored as version records in meta partitions. Each record refers an inode which
+// carries the version ID in extend attribute 'oss:version'. A retained inode always holds exactly one link,
+// which is owned by the dentry while the version is the current version of the object, or is owned by the
+// version record after the object has been overwritten or deleted.
+
+func (v *Volume) versioningEnabled() bool {
+	config, err := v.metaLoader.loadVersioning()
+	if err != nil {
+		log.LogErrorf("versioningEnabled: load versioning configuration fail: volume(%v) err(%v)", v.name, err)
+		return false
+	}
+	return config.Enabled()
+}
+
+// isVersionRetained checks whether the specified inode is retained as a version of an object.
+func (v *Volume) isVersionRetained(inode uint64) bool {
+	info, err := v.mw.XAttrGet_ll(inode, XAttrKeyOSSVersion)
+	if err != nil {
+		log.LogWarnf("isVersionRetained: meta get xattr fail: volume(%v) inode(%v) err(%v)", v.name, inode, err)
+		return false
+	}
+	return len(info.Get(XAttrKeyOSSVersion)) > 0
+}
+
+func (v *Volume) createVersion(path string, info *FSFileInfo, deleteMarker, null bool) (versionId string, err error) {
+	var versionInfo = &proto.VersionInfo{
+		Path:         path,
+		ModifyTime:   info.ModifyTime,
+		DeleteMarker: deleteMarker,
+		Null:         null,
+	}
+	if !deleteMarker {
+		versionInfo.Inode = info.Inode
+		versionInfo.Size = uint64(info.Size)
+		versionInfo.ETag = info.ETag
+	}
+	if versionId, err = v.mw.CreateVersion_ll(versionInfo); err != nil {
+		log.LogErrorf("createVersion: meta create version fail: volume(%v) path(%v) inode(%v) err(%v)",
+			v.name, path, info.Inode, err)
+		return
+	}
+	if deleteMarker {
+		return
+	}
+	if err = v.mw.XAttrSet_ll(info.Inode, []byte(XAttrKeyOSSVersion), []byte(versionId)); err != nil {
+		log.LogErrorf("createVersion: store version ID fail: volume(%v) path(%v) inode(%v) versionID(%v) err(%v)",
+			v.name, path, info.Inode, versionId, err)
+		_ = v.mw.RemoveVersion_ll(path, versionId)
+		return "", err
+	}
+	return
+}
+
+// retainCurrentVersion records the current object of the path as the null version if it has not been versioned yet,
+// so that it will not be released while it is overwritten or deleted. The null version retained before is replaced.
+func (v *Volume) retainCurrentVersion(path string) (err error) {
+	var info *FSFileInfo
+	if info, err = v.ObjectMeta(path); err == syscall.ENOENT {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if info.Mode.IsDir() || len(info.VersionId) > 0 {
+		return
+	}
+	var previous *proto.VersionInfo
+	if previous, err = v.nullVersion(path); err != nil {
+		return
+	}
+	if previous != nil {
+		if _, err = v.DeleteVersion(path, previous.ID); err != nil {
+			return
+		}
+	}
+	var versionId string
+	if versionId, err = v.createVersion(path, info, false, true); err != nil {
+		return
+	}
+	log.LogDebugf("retainCurrentVersion: retain current object: volume(%v) path(%v) inode(%v) versionID(%v)",
+		v.name, path, info.Inode, versionId)
+	return
+}
+
+// recordVersion records the object which is going to be applied to the path as a new version
+// if versioning is enabled for the volume. It returns an empty version ID if versioning is disabled.
+func (v *Volume) recordVersion(path string, info *FSFileInfo) (versionId string, err error) {
+	if !v.versioningEnabled() {
+		return
+	}
+	if err = v.retainCurrentVersion(path); err != nil {
+		return
+	}
+	return v.createVersion(path, info, false, false)
+}
+
+// DeleteObject deletes the object of the specified path with versioning considered.
+// If versioning is enabled, the current object is retained as a version and a delete marker will be created,
+// and the version ID of the delete marker returns.
+// If the object is a retained version, only the dentry will be removed.
+// Otherwise, it works same as DeletePath.
+func (v *Volume) DeleteObject(path string) (markerVersionId string, err error) {
+	defer func() {
+		log.LogInfof("Audit: DeleteObject: volume(%v) path(%v) markerVersionID(%v) err(%v)",
+			v.name, path, markerVersionId, err)
+	}()
+
+	var enabled = v.versioningEnabled()
+	if enabled {
+		if err = v.retainCurrentVersion(path); err != nil {
+			return
+		}
+	}
+
+	var parent, ino uint64
+	var name string
+	var mode os.FileMode
+	parent, ino, name, mode, err = v.recursiveLookupTarget(path)
+	if err != nil && err != syscall.ENOENT {
+		return
+	}
+	if err == nil && mode.IsDir() {
+		return "", v.DeletePath(path)
+	}
+	if err == nil {
+		if err = v.recordDeletion(path); err != nil {
+			return
+		}
+		if !v.isVersionRetained(ino) {
+			if err = v.DeletePath(path); err != nil {
+				return
+			}
+		} else if err = v.detachVersion(parent, name, ino); err != nil {
+			return
+		}
+	}
+	err = nil
+
+	if !enabled {
+		return
+	}
+	var marker = &FSFileInfo{
+		Path:       path,
+		ModifyTime: time.Now(),
+	}
+	if markerVersionId, err = v.createVersion(path, marker, true, false); err != nil {
+		return
+	}
+	return
+}
+
+// detachVersion removes the dentry which refers a retained version,
+// and the link of the inode is handed over to the version record.
+func (v *Volume) detachVersion(parent uint64, name string, ino uint64) (err error) {
+	if _, err = v.mw.InodeLink_ll(ino); err != nil {
+		log.LogErrorf("detachVersion: meta link inode fail: volume(%v) inode(%v) err(%v)", v.name, ino, err)
+		return
+	}
+	if _, err = v.mw.Delete_ll(parent, name, false); err != nil {
+		log.LogErrorf("detachVersion: meta delete dentry fail: volume(%v) parentID(%v) name(%v) err(%v)",
+			v.name, parent, name, err)
+		_, _ = v.mw.InodeUnlink_ll(ino)
+		return
+	}
+	if err = v.ec.EvictStream(ino); err != nil {
+		log.LogWarnf("detachVersion: evict stream fail: volume(%v) inode(%v) err(%v)", v.name, ino, err)
+	}
+	return nil
+}
+
+// DeleteVersion permanently deletes the specified version of the object.
+// If the deleted version is the current version of the object, the latest remaining version
+// will be applied as the current version.
+func (v *Volume) DeleteVersion(path, versionId string) (deleteMarker bool, err error) {
+	defer func() {
+		log.LogInfof("Audit: DeleteVersion: volume(%v) path(%v) versionID(%v) err(%v)",
+			v.name, path, versionId, err)
+	}()
+
+	var current *FSFileInfo
+	if current, err = v.ObjectMeta(path); err != nil && err != syscall.ENOENT {
+		return
+	}
+	if err == syscall.ENOENT {
+		current = nil
+	}
+
+	if versionId == NullVersionId && current != nil && len(current.VersionId) == 0 {
+		// The null version is the object which is not versioned.
+		return false, v.DeletePath(path)
+	}
+
+	var versionInfo *proto.VersionInfo
+	if versionInfo, err = v.versionByID(path, versionId); err != nil {
+		return
+	}
+	versionId = versionInfo.ID
+	deleteMarker = versionInfo.DeleteMarker
+	// The version protected by object lock can not be deleted.
+	if !versionInfo.DeleteMarker {
+		if err = v.checkObjectLock(versionInfo.Inode); err != nil {
+			return
+		}
+	}
+	if err = v.mw.RemoveVersion_ll(path, versionId); err != nil {
+		return
+	}
+
+	if !versionInfo.DeleteMarker {
+		if current != nil && current.Inode == versionInfo.Inode {
+			// The link is owned by the dentry.
+			if err = v.DeletePath(path); err != nil {
+				return
+			}
+		} else {
+			log.LogWarnf("DeleteVersion: unlink inode: volume(%v) path(%v) inode(%v)", v.name, path, versionInfo.Inode)
+			if _, err = v.mw.InodeUnlink_ll(versionInfo.Inode); err != nil {
+				log.LogWarnf("DeleteVersion: unlink inode fail: volume(%v) inode(%v) err(%v)",
+					v.name, versionInfo.Inode, err)
+			}
+			if err = v.mw.Evict(versionInfo.Inode); err != nil {
+				log.LogWarnf("DeleteVersion: evict inode fail: volume(%v) inode(%v) err(%v)",
+					v.name, versionInfo.Inode, err)
+			}
+			err = nil
+			return
+		}
+	}
+	if current != nil && current.Inode != versionInfo.Inode {
+		return
+	}
+	err = v.restoreLatestVersion(path)
+	return
+}
+
+// restoreLatestVersion applies the latest remaining version of the object to the path
+// if there is no current object, and the link of the inode is handed over to the dentry.
+func (v *Volume) restoreLatestVersion(path string) (err error) {
+	var latest *proto.VersionInfo
+	if latest, err = v.latestVersion(path); err != nil || latest == nil || latest.DeleteMarker {
+		return
+	}
+	dirs, filename := splitPath(path)
+	var parentId uint64
+	if parentId, err = v.lookupDirectories(dirs, true); err != nil {
+		log.LogErrorf("restoreLatestVersion: lookup directories fail: volume(%v) path(%v) err(%v)", v.name, path, err)
+		return
+	}
+	if err = v.applyInodeToNewDentry(parentId, filename, latest.Inode); err != nil && err != syscall.EEXIST {
+		return
+	}
+	log.LogDebugf("restoreLatestVersion: restore version: volume(%v) path(%v) versionID(%v) inode(%v)",
+		v.name, path, latest.ID, latest.Inode)
+	return nil
+}
+
+func (v *Volume) latestVersion(path string) (latest *proto.VersionInfo, err error) {
+	var versions []*proto.VersionInfo
+	if versions, err = v.mw.ListVersions_ll(path, "", "", 1); err != nil {
+		return
+	}
+	for _, version := range versions {
+		if version.Path == path {
+			return version, nil
+		}
+	}
+	return
+}
+
+// nullVersion returns the retained null version of the object, or nil if it does not exist.
+func (v *Volume) nullVersion(path string) (null *proto.VersionInfo, err error) {
+	var keyMarker, versionIdMarker string
+	for {
+		var versions []*proto.VersionInfo
+		if versions, err = v.mw.ListVersions_ll(path, keyMarker, versionIdMarker, MaxKeys); err != nil {
+			return
+		}
+		for _, version := range versions {
+			if version.Path != path {
+				return nil, nil
+			}
+			if version.Null {
+				return version, nil
+			}
+			keyMarker, versionIdMarker = version.Path, version.ID
+		}
+		if len(versions) <= MaxKeys {
+			return nil, nil
+		}
+	}
+}
+
+// versionByID returns the version record of the object, the null version ID is resolved
+// to the retained null version.
+func (v *Volume) versionByID(path, versionId string) (info *proto.VersionInfo, err error) {
+	if versionId != NullVersionId {
+		return v.mw.GetVersion_ll(path, versionId)
+	}
+	if info, err = v.nullVersion(path); err != nil {
+		return
+	}
+	if info == nil {
+		return nil, syscall.ENOENT
+	}
+	return
+}
+
+// displayVersionID returns the version ID shown to the user, the null version is always shown as 'null'.
+func displayVersionID(versionId string) string {
+	if util.IsNullVersionID(versionId) {
+		return NullVersionId
+	}
+	return versionId
+}
+
+// ObjectVersionMeta returns the meta information of the specified version of the object.
+// If the version is a delete marker, the result only contains the version information.
+func (v *Volume) ObjectVersionMeta(path, versionId string) (info *FSFileInfo, err error) {
+	if versionId == NullVersionId {
+		if info, err = v.ObjectMeta(path); err != nil && err != syscall.ENOENT {
+			return
+		}
+		if err == nil && (len(info.VersionId) == 0 || info.VersionId == NullVersionId) {
+			info.VersionId = NullVersionId
+			return
+		}
+	}
+
+	var versionInfo *proto.VersionInfo
+	if versionInfo, err = v.versionByID(path, versionId); err != nil {
+		return
+	}
+	if versionInfo.DeleteMarker {
+		info = &FSFileInfo{
+			Path:         path,
+			ModifyTime:   versionInfo.ModifyTime,
+			VersionId:    displayVersionID(versionInfo.ID),
+			DeleteMarker: true,
+		}
+		return
+	}
+
+	var inoInfo *proto.InodeInfo
+	if inoInfo, err = v.mw.InodeGet_ll(versionInfo.Inode); err != nil {
+		log.LogErrorf("ObjectVersionMeta: get inode fail: volume(%v) path(%v) versionID(%v) inode(%v) err(%v)",
+			v.name, path, versionId, versionInfo.Inode, err)
+		return
+	}
+	if info, err = v.inodeMeta(path, inoInfo, os.FileMode(inoInfo.Mode)); err != nil {
+		return
+	}
+	info.VersionId = displayVersionID(versionInfo.ID)
+	return
+}
+
+// ListVersions lists versions of objects in the order of key and the newest version first.
+// The version ID marker 'null' continues the listing after the retained null version of the key marker.
+func (v *Volume) ListVersions(prefix, keyMarker, versionIdMarker string, maxKeys uint64) (versions []*proto.VersionInfo, err error) {
+	if versionIdMarker == NullVersionId {
+		var null *proto.VersionInfo
+		if null, err = v.nullVersion(keyMarker); err != nil {
+			return
+		}
+		versionIdMarker = ""
+		if null != nil {
+			versionIdMarker = null.ID
+		}
+	}
+	if versions, err = v.mw.ListVersions_ll(prefix, keyMarker, versionIdMarker, maxKeys); err != nil {
+		log.LogErrorf("ListVersions: meta list versions fail: volume(%v) prefix(%v) keyMarker(%v) versionIdMarker(%v) err(%v)",
+			v.name, prefix, keyMarker, versionIdMarker, err)
+		return
+	}
+	return
+}
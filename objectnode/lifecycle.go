@@ -0,0 +1,291 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/object-lifecycle-mgmt.html
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+const (
+	LifecycleStatusEnabled  = "Enabled"
+	LifecycleStatusDisabled = "Disabled"
+
+	MaxLifecycleRules = 1000
+)
+
+type LifecycleConfiguration struct {
+	XMLName xml.Name         `xml:"LifecycleConfiguration" json:"-"`
+	Rules   []*LifecycleRule `xml:"Rule" json:"rules"`
+}
+
+type LifecycleRule struct {
+	ID                             string                          `xml:"ID,omitempty" json:"id"`
+	Status                         string                          `xml:"Status" json:"status"`
+	Prefix                         string                          `xml:"Prefix,omitempty" json:"prefix,omitempty"` // Deprecated, use Filter instead
+	Filter                         *LifecycleFilter                `xml:"Filter,omitempty" json:"filter,omitempty"`
+	Expiration                     *LifecycleExpiration            `xml:"Expiration,omitempty" json:"expiration,omitempty"`
+	Transition                     *LifecycleTransition            `xml:"Transition,omitempty" json:"transition,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty" json:"abort_mpu,omitempty"`
+}
+
+type LifecycleFilter struct {
+	Prefix string              `xml:"Prefix,omitempty" json:"prefix,omitempty"`
+	Tag    *Tag                `xml:"Tag,omitempty" json:"tag,omitempty"`
+	And    *LifecycleFilterAnd `xml:"And,omitempty" json:"and,omitempty"`
+}
+
+type LifecycleFilterAnd struct {
+	Prefix string `xml:"Prefix,omitempty" json:"prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag,omitempty" json:"tags,omitempty"`
+}
+
+type LifecycleExpiration struct {
+	Days int    `xml:"Days,omitempty" json:"days,omitempty"`
+	Date string `xml:"Date,omitempty" json:"date,omitempty"` // ISO 8601 format at midnight UTC
+}
+
+// LifecycleTransition moves the objects into the cold data partitions of the volume after the specified days
+// or at the specified date.
+type LifecycleTransition struct {
+	Days         int    `xml:"Days,omitempty" json:"days,omitempty"`
+	Date         string `xml:"Date,omitempty" json:"date,omitempty"` // ISO 8601 format at midnight UTC
+	StorageClass string `xml:"StorageClass" json:"storage_class"`
+}
+
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation" json:"days"`
+}
+
+func (rule *LifecycleRule) Enabled() bool {
+	return rule.Status == LifecycleStatusEnabled
+}
+
+// KeyPrefix returns the key prefix which objects must match to apply this rule.
+func (rule *LifecycleRule) KeyPrefix() string {
+	if rule.Filter == nil {
+		return rule.Prefix
+	}
+	if rule.Filter.And != nil {
+		return rule.Filter.And.Prefix
+	}
+	return rule.Filter.Prefix
+}
+
+// MatchPrefix checks whether the object key matches the prefix of this rule.
+func (rule *LifecycleRule) MatchPrefix(key string) bool {
+	return strings.HasPrefix(key, rule.KeyPrefix())
+}
+
+// Tags returns the tags which objects must have to apply this rule.
+func (rule *LifecycleRule) Tags() []Tag {
+	if rule.Filter == nil {
+		return nil
+	}
+	if rule.Filter.And != nil {
+		return rule.Filter.And.Tags
+	}
+	if rule.Filter.Tag != nil {
+		return []Tag{*rule.Filter.Tag}
+	}
+	return nil
+}
+
+// MatchTags checks whether the specified object tagging contains all tags of this rule.
+func (rule *LifecycleRule) MatchTags(tagging *Tagging) bool {
+	var tags = rule.Tags()
+	if len(tags) == 0 {
+		return true
+	}
+	if tagging == nil {
+		return false
+	}
+	for _, tag := range tags {
+		var found bool
+		for _, objectTag := range tagging.TagSet {
+			if objectTag.Key == tag.Key && objectTag.Value == tag.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Expired checks whether the object modified at the specified time is expired at the moment.
+func (rule *LifecycleRule) Expired(modifyTime, now time.Time) bool {
+	if rule.Expiration == nil {
+		return false
+	}
+	return lifecycleDue(rule.Expiration.Days, rule.Expiration.Date, modifyTime, now)
+}
+
+// Transitioned checks whether the object modified at the specified time should be transitioned at the moment.
+func (rule *LifecycleRule) Transitioned(modifyTime, now time.Time) bool {
+	if rule.Transition == nil {
+		return false
+	}
+	return lifecycleDue(rule.Transition.Days, rule.Transition.Date, modifyTime, now)
+}
+
+func lifecycleDue(days int, date string, modifyTime, now time.Time) bool {
+	if days > 0 {
+		return now.Sub(modifyTime) >= time.Duration(days)*24*time.Hour
+	}
+	if len(date) > 0 {
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return false
+		}
+		return !now.Before(t)
+	}
+	return false
+}
+
+func validateLifecycleDays(days int, date string) bool {
+	if (days > 0) == (len(date) > 0) || days < 0 {
+		return false
+	}
+	if len(date) > 0 {
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil || !t.Equal(t.Truncate(24*time.Hour)) {
+			return false
+		}
+	}
+	return true
+}
+
+// UploadExpired checks whether the multipart upload initiated at the specified time should be aborted at the moment.
+func (rule *LifecycleRule) UploadExpired(initTime, now time.Time) bool {
+	if rule.AbortIncompleteMultipartUpload == nil {
+		return false
+	}
+	var days = rule.AbortIncompleteMultipartUpload.DaysAfterInitiation
+	return now.Sub(initTime) >= time.Duration(days)*24*time.Hour
+}
+
+func (rule *LifecycleRule) validate() bool {
+	if rule.Status != LifecycleStatusEnabled && rule.Status != LifecycleStatusDisabled {
+		return false
+	}
+	if len(rule.ID) > 255 {
+		return false
+	}
+	if rule.Expiration == nil && rule.Transition == nil && rule.AbortIncompleteMultipartUpload == nil {
+		return false
+	}
+	if rule.Expiration != nil && !validateLifecycleDays(rule.Expiration.Days, rule.Expiration.Date) {
+		return false
+	}
+	if rule.Transition != nil {
+		// Only the cold storage class can be transitioned to.
+		if !validateLifecycleDays(rule.Transition.Days, rule.Transition.Date) ||
+			!strings.EqualFold(rule.Transition.StorageClass, StorageClassCold) {
+			return false
+		}
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		if rule.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+			return false
+		}
+		// Abort incomplete multipart upload cannot be specified with tag based filter.
+		if len(rule.Tags()) > 0 {
+			return false
+		}
+	}
+	if rule.Filter != nil {
+		var filter = rule.Filter
+		var count int
+		if len(filter.Prefix) > 0 {
+			count++
+		}
+		if filter.Tag != nil {
+			count++
+		}
+		if filter.And != nil {
+			count++
+		}
+		if count > 1 || (len(rule.Prefix) > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (config *LifecycleConfiguration) validate() bool {
+	if len(config.Rules) == 0 || len(config.Rules) > MaxLifecycleRules {
+		return false
+	}
+	var ids = make(map[string]struct{})
+	for _, rule := range config.Rules {
+		if !rule.validate() {
+			return false
+		}
+		if len(rule.ID) > 0 {
+			if _, exist := ids[rule.ID]; exist {
+				return false
+			}
+			ids[rule.ID] = struct{}{}
+		}
+	}
+	return true
+}
+
+// EnabledRules returns all enabled rules in this configuration.
+func (config *LifecycleConfiguration) EnabledRules() []*LifecycleRule {
+	if config == nil {
+		return nil
+	}
+	var rules = make([]*LifecycleRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		if rule.Enabled() {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func parseLifecycleConfig(bytes []byte) (config *LifecycleConfiguration, err error) {
+	config = &LifecycleConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return
+	}
+	if ok := config.validate(); !ok {
+		return nil, errors.New("invalid lifecycle configuration")
+	}
+	return
+}
+
+func storeBucketLifecycle(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSLifecycle, bytes); err != nil {
+		return
+	}
+	return nil
+}
+
+func deleteBucketLifecycle(vol *Volume) (err error) {
+	if err = vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSLifecycle); err != nil {
+		return err
+	}
+	return nil
+}
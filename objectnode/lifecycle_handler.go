@@ -0,0 +1,177 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get bucket lifecycle
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLifecycleConfiguration.html
+func (o *ObjectNode) getBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketLifecycleHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var lifecycle *LifecycleConfiguration
+	if lifecycle, err = vol.metaLoader.loadLifecycle(); err != nil {
+		log.LogErrorf("getBucketLifecycleHandler: load lifecycle fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if lifecycle == nil || len(lifecycle.Rules) == 0 {
+		errorCode = NoSuchLifecycleConfiguration
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(lifecycle); err != nil {
+		log.LogErrorf("getBucketLifecycleHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket lifecycle
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLifecycleConfiguration.html
+func (o *ObjectNode) putBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketLifecycleHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketLifecycleHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var lifecycle *LifecycleConfiguration
+	if lifecycle, err = parseLifecycleConfig(bytes); err != nil {
+		log.LogErrorf("putBucketLifecycleHandler: parse lifecycle configuration fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidLifecycleConfiguration
+		return
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(lifecycle); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketLifecycle(newBytes, vol); err != nil {
+		log.LogErrorf("putBucketLifecycleHandler: store lifecycle fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeLifecycle(lifecycle)
+
+	log.LogInfof("putBucketLifecycleHandler: put bucket lifecycle: requestID(%v) volume(%v) rules(%v)",
+		GetRequestID(r), param.Bucket(), len(lifecycle.Rules))
+	return
+}
+
+// Delete bucket lifecycle
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketLifecycle.html
+func (o *ObjectNode) deleteBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketLifecycleHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	if err = deleteBucketLifecycle(vol); err != nil {
+		log.LogErrorf("deleteBucketLifecycleHandler: delete lifecycle fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeLifecycle(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
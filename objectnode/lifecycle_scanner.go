@@ -0,0 +1,280 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// The lifecycle rules of a volume are applied by the ObjectNode holding the lifecycle lease of the volume.
+const lifecycleLeaseTTL = 5 * time.Minute
+
+// LifecycleScanner periodically walks through all volumes and applies the lifecycle rules of them.
+// Objects which are expired by rules will be deleted, objects which are due to transition will be moved
+// into the cold data partitions, and incomplete multipart uploads which are older than specified days
+// will be aborted.
+// Every ObjectNode runs the scanner, and each volume is scanned by the one holding its lifecycle lease.
+type LifecycleScanner struct {
+	vm       *VolumeManager
+	mc       *master.MasterClient
+	interval time.Duration
+	leases   map[string]*master.TaskLeaseHolder
+	stopOnce sync.Once
+	stopC    chan struct{}
+}
+
+func NewLifecycleScanner(vm *VolumeManager, mc *master.MasterClient, interval time.Duration) *LifecycleScanner {
+	return &LifecycleScanner{
+		vm:       vm,
+		mc:       mc,
+		interval: interval,
+		leases:   make(map[string]*master.TaskLeaseHolder),
+		stopC:    make(chan struct{}),
+	}
+}
+
+func (s *LifecycleScanner) Start() {
+	go s.scheduleScan()
+	log.LogInfof("LifecycleScanner: started: interval(%v)", s.interval)
+}
+
+func (s *LifecycleScanner) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopC)
+	})
+}
+
+func (s *LifecycleScanner) stopped() bool {
+	select {
+	case <-s.stopC:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *LifecycleScanner) scheduleScan() {
+	var timer = time.NewTimer(s.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			s.scanAll()
+			timer.Reset(s.interval)
+		case <-s.stopC:
+			return
+		}
+	}
+}
+
+func (s *LifecycleScanner) scanAll() {
+	var err error
+	var volInfos []*proto.VolInfo
+	if volInfos, err = s.mc.AdminAPI().ListVols(""); err != nil {
+		log.LogErrorf("LifecycleScanner: list volumes fail: err(%v)", err)
+		return
+	}
+	var start = time.Now()
+	for _, volInfo := range volInfos {
+		if s.stopped() {
+			return
+		}
+		var lease = s.lease(volInfo.Name)
+		if !lease.Hold() {
+			continue
+		}
+		var vol *Volume
+		if vol, err = s.vm.Volume(volInfo.Name); err != nil {
+			log.LogWarnf("LifecycleScanner: load volume fail: volume(%v) err(%v)", volInfo.Name, err)
+			continue
+		}
+		s.scanVolume(vol, lease)
+	}
+	log.LogInfof("LifecycleScanner: scan finished: volumes(%v) cost(%v)", len(volInfos), time.Since(start))
+}
+
+// lease returns the lifecycle lease of the volume, which is only accessed by the scanning goroutine.
+func (s *LifecycleScanner) lease(volName string) *master.TaskLeaseHolder {
+	if lease, ok := s.leases[volName]; ok {
+		return lease
+	}
+	var lease = master.NewTaskLeaseHolder(s.mc, "lifecycle."+volName, lifecycleLeaseTTL)
+	s.leases[volName] = lease
+	return lease
+}
+
+// scanVolume applies the lifecycle rules of the volume while the lifecycle lease is held,
+// the lease is renewed before each batch of objects.
+func (s *LifecycleScanner) scanVolume(vol *Volume, lease *master.TaskLeaseHolder) {
+	// Load configuration from store directly, the cached one may not be synchronized yet.
+	var err error
+	var lifecycle *LifecycleConfiguration
+	if lifecycle, err = vol.loadBucketLifecycle(); err != nil {
+		log.LogErrorf("LifecycleScanner: load lifecycle fail: volume(%v) err(%v)", vol.Name(), err)
+		return
+	}
+	for _, rule := range lifecycle.EnabledRules() {
+		if s.stopped() {
+			return
+		}
+		if rule.Expiration != nil {
+			s.expireObjects(vol, rule, lease)
+		}
+		if rule.Transition != nil {
+			s.transitionObjects(vol, rule, lease)
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			s.abortUploads(vol, rule, lease)
+		}
+	}
+}
+
+func (s *LifecycleScanner) expireObjects(vol *Volume, rule *LifecycleRule, lease *master.TaskLeaseHolder) {
+	var err error
+	var now = time.Now()
+	var option = &ListFilesV2Option{
+		Prefix:  rule.KeyPrefix(),
+		MaxKeys: MaxKeys,
+	}
+	var expired int
+	for !s.stopped() && lease.Hold() {
+		var result *ListFilesV2Result
+		if result, err = vol.ListFilesV2(option); err != nil {
+			log.LogErrorf("LifecycleScanner: list files fail: volume(%v) rule(%v) prefix(%v) err(%v)",
+				vol.Name(), rule.ID, option.Prefix, err)
+			return
+		}
+		for _, file := range result.Files {
+			if file.Mode.IsDir() || !rule.Expired(file.ModifyTime, now) {
+				continue
+			}
+			if !s.matchTags(vol, rule, file) {
+				continue
+			}
+			if _, err = vol.DeleteObject(file.Path); err != nil {
+				log.LogErrorf("LifecycleScanner: expire object fail: volume(%v) rule(%v) path(%v) err(%v)",
+					vol.Name(), rule.ID, file.Path, err)
+				continue
+			}
+			expired++
+		}
+		if !result.Truncated {
+			break
+		}
+		option.ContToken = result.NextToken
+	}
+	log.LogInfof("LifecycleScanner: expire objects: volume(%v) rule(%v) prefix(%v) expired(%v)",
+		vol.Name(), rule.ID, option.Prefix, expired)
+}
+
+func (s *LifecycleScanner) transitionObjects(vol *Volume, rule *LifecycleRule, lease *master.TaskLeaseHolder) {
+	var err error
+	var now = time.Now()
+	var option = &ListFilesV2Option{
+		Prefix:  rule.KeyPrefix(),
+		MaxKeys: MaxKeys,
+	}
+	var transitioned int
+	for !s.stopped() && lease.Hold() {
+		var result *ListFilesV2Result
+		if result, err = vol.ListFilesV2(option); err != nil {
+			log.LogErrorf("LifecycleScanner: list files fail: volume(%v) rule(%v) prefix(%v) err(%v)",
+				vol.Name(), rule.ID, option.Prefix, err)
+			return
+		}
+		for _, file := range result.Files {
+			if file.Mode.IsDir() || file.StorageClass == StorageClassCold || !rule.Transitioned(file.ModifyTime, now) {
+				continue
+			}
+			if !s.matchTags(vol, rule, file) {
+				continue
+			}
+			if err = vol.TransitionObject(file.Path); err != nil {
+				log.LogErrorf("LifecycleScanner: transition object fail: volume(%v) rule(%v) path(%v) err(%v)",
+					vol.Name(), rule.ID, file.Path, err)
+				continue
+			}
+			transitioned++
+		}
+		if !result.Truncated {
+			break
+		}
+		option.ContToken = result.NextToken
+	}
+	log.LogInfof("LifecycleScanner: transition objects: volume(%v) rule(%v) prefix(%v) transitioned(%v)",
+		vol.Name(), rule.ID, option.Prefix, transitioned)
+}
+
+// matchTags checks whether the tagging of the file matches the tags of the rule.
+func (s *LifecycleScanner) matchTags(vol *Volume, rule *LifecycleRule, file *FSFileInfo) bool {
+	if len(rule.Tags()) == 0 {
+		return true
+	}
+	xattr, err := vol.GetXAttr(file.Path, XAttrKeyOSSTagging)
+	if err != nil {
+		if err != syscall.ENOENT {
+			log.LogErrorf("LifecycleScanner: get tagging fail: volume(%v) path(%v) err(%v)",
+				vol.Name(), file.Path, err)
+		}
+		return false
+	}
+	tagging, _ := ParseTagging(string(xattr.Get(XAttrKeyOSSTagging)))
+	return rule.MatchTags(tagging)
+}
+
+func (s *LifecycleScanner) abortUploads(vol *Volume, rule *LifecycleRule, lease *master.TaskLeaseHolder) {
+	var err error
+	var now = time.Now()
+	var prefix = rule.KeyPrefix()
+	var keyMarker, multipartIdMarker string
+	var aborted int
+	for !s.stopped() && lease.Hold() {
+		var sessions []*proto.MultipartInfo
+		if sessions, err = vol.mw.ListMultipart_ll(prefix, "", keyMarker, multipartIdMarker, MaxUploads); err != nil {
+			log.LogErrorf("LifecycleScanner: list multipart uploads fail: volume(%v) rule(%v) prefix(%v) err(%v)",
+				vol.Name(), rule.ID, prefix, err)
+			return
+		}
+		var truncated bool
+		if len(sessions) > MaxUploads {
+			keyMarker = sessions[MaxUploads].Path
+			multipartIdMarker = sessions[MaxUploads].ID
+			sessions = sessions[:MaxUploads]
+			truncated = true
+		}
+		for _, session := range sessions {
+			if !rule.UploadExpired(session.InitTime, now) {
+				continue
+			}
+			if err = vol.AbortMultipart(session.Path, session.ID); err != nil {
+				log.LogErrorf("LifecycleScanner: abort multipart upload fail: volume(%v) rule(%v) path(%v) multipartID(%v) err(%v)",
+					vol.Name(), rule.ID, session.Path, session.ID, err)
+				continue
+			}
+			aborted++
+		}
+		if !truncated {
+			break
+		}
+	}
+	log.LogInfof("LifecycleScanner: abort multipart uploads: volume(%v) rule(%v) prefix(%v) aborted(%v)",
+		vol.Name(), rule.ID, prefix, aborted)
+}
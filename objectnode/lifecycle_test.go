@@ -0,0 +1,132 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycle_Parse(t *testing.T) {
+	var valid = `<LifecycleConfiguration>
+	<Rule>
+		<ID>expire-logs</ID>
+		<Filter>
+			<And>
+				<Prefix>logs/</Prefix>
+				<Tag><Key>type</Key><Value>tmp</Value></Tag>
+			</And>
+		</Filter>
+		<Status>Enabled</Status>
+		<Expiration><Days>30</Days></Expiration>
+	</Rule>
+	<Rule>
+		<ID>archive-data</ID>
+		<Filter><Tag><Key>tier</Key><Value>archive</Value></Tag></Filter>
+		<Status>Disabled</Status>
+		<Transition><Days>90</Days><StorageClass>COLD</StorageClass></Transition>
+	</Rule>
+	<Rule>
+		<ID>abort-uploads</ID>
+		<Filter><Prefix>uploads/</Prefix></Filter>
+		<Status>Disabled</Status>
+		<AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload>
+	</Rule>
+</LifecycleConfiguration>`
+	config, err := parseLifecycleConfig([]byte(valid))
+	if err != nil {
+		t.Fatalf("parse lifecycle configuration fail: err(%v)", err)
+	}
+	if len(config.Rules) != 3 {
+		t.Fatalf("rule count mismatch: expect(3) actual(%v)", len(config.Rules))
+	}
+	if transition := config.Rules[1].Transition; transition == nil || transition.Days != 90 {
+		t.Fatalf("rule transition mismatch: %v", transition)
+	}
+	if prefix := config.Rules[0].KeyPrefix(); prefix != "logs/" {
+		t.Fatalf("rule prefix mismatch: expect(logs/) actual(%v)", prefix)
+	}
+	if rules := config.EnabledRules(); len(rules) != 1 || rules[0].ID != "expire-logs" {
+		t.Fatalf("enabled rules mismatch: %v", rules)
+	}
+
+	var invalids = []string{
+		// without action
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status></Rule></LifecycleConfiguration>`,
+		// invalid status
+		`<LifecycleConfiguration><Rule><Status>On</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`,
+		// both days and date
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Expiration><Days>1</Days><Date>2020-01-01T00:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`,
+		// date not at midnight
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Expiration><Date>2020-01-01T08:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`,
+		// transition to the unknown storage class
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Transition><Days>1</Days><StorageClass>GLACIER</StorageClass></Transition></Rule></LifecycleConfiguration>`,
+		// transition without days or date
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Transition><StorageClass>COLD</StorageClass></Transition></Rule></LifecycleConfiguration>`,
+		// abort multipart upload with tag filter
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Tag><Key>k</Key><Value>v</Value></Tag></Filter><AbortIncompleteMultipartUpload><DaysAfterInitiation>1</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>`,
+	}
+	for i, invalid := range invalids {
+		if _, err = parseLifecycleConfig([]byte(invalid)); err == nil {
+			t.Fatalf("invalid configuration %v passed validation", i)
+		}
+	}
+}
+
+func TestLifecycleRule_Match(t *testing.T) {
+	var now = time.Now()
+	var rule = &LifecycleRule{
+		Status: LifecycleStatusEnabled,
+		Filter: &LifecycleFilter{
+			And: &LifecycleFilterAnd{
+				Prefix: "logs/",
+				Tags:   []Tag{{Key: "type", Value: "tmp"}},
+			},
+		},
+		Expiration:                     &LifecycleExpiration{Days: 1},
+		AbortIncompleteMultipartUpload: &AbortIncompleteMultipartUpload{DaysAfterInitiation: 2},
+	}
+	if !rule.MatchPrefix("logs/2020/01.log") || rule.MatchPrefix("data/01.log") {
+		t.Fatalf("match prefix result mismatch")
+	}
+	if !rule.MatchTags(&Tagging{TagSet: []Tag{{Key: "type", Value: "tmp"}, {Key: "owner", Value: "a"}}}) {
+		t.Fatalf("match tags result mismatch")
+	}
+	if rule.MatchTags(&Tagging{TagSet: []Tag{{Key: "type", Value: "log"}}}) || rule.MatchTags(nil) {
+		t.Fatalf("match tags result mismatch")
+	}
+	if !rule.Expired(now.Add(-25*time.Hour), now) || rule.Expired(now.Add(-23*time.Hour), now) {
+		t.Fatalf("expired result mismatch")
+	}
+	if !rule.UploadExpired(now.Add(-49*time.Hour), now) || rule.UploadExpired(now.Add(-47*time.Hour), now) {
+		t.Fatalf("upload expired result mismatch")
+	}
+	if rule.Transitioned(now.Add(-49*time.Hour), now) {
+		t.Fatalf("transitioned result mismatch")
+	}
+	rule.Transition = &LifecycleTransition{Days: 3, StorageClass: StorageClassCold}
+	if !rule.Transitioned(now.Add(-73*time.Hour), now) || rule.Transitioned(now.Add(-71*time.Hour), now) {
+		t.Fatalf("transitioned result mismatch")
+	}
+
+	var dateRule = &LifecycleRule{
+		Status:     LifecycleStatusEnabled,
+		Expiration: &LifecycleExpiration{Date: "2020-01-01T00:00:00Z"},
+	}
+	var date, _ = time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	if !dateRule.Expired(now, date) || dateRule.Expired(now, date.Add(-time.Second)) {
+		t.Fatalf("expired by date result mismatch")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+const maxLoggingTargetPrefixLength = 512
+
+// BucketLoggingStatus is the access logging configuration of a bucket. The access logging is
+// disabled if LoggingEnabled is absent.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_BucketLoggingStatus.html
+type BucketLoggingStatus struct {
+	XMLName        xml.Name        `xml:"BucketLoggingStatus" json:"-"`
+	LoggingEnabled *LoggingEnabled `xml:"LoggingEnabled,omitempty" json:"logging_enabled,omitempty"`
+}
+
+// LoggingEnabled describes where the access log records of the bucket are stored.
+// The log objects are put into the target bucket with keys prefixed by TargetPrefix.
+type LoggingEnabled struct {
+	TargetBucket string `xml:"TargetBucket" json:"target_bucket"`
+	TargetPrefix string `xml:"TargetPrefix" json:"target_prefix"`
+}
+
+func (s *BucketLoggingStatus) IsEnabled() bool {
+	return s != nil && s.LoggingEnabled != nil
+}
+
+func parseBucketLoggingStatus(bytes []byte) (status *BucketLoggingStatus, err error) {
+	status = &BucketLoggingStatus{}
+	if err = xml.Unmarshal(bytes, status); err != nil {
+		return nil, err
+	}
+	if status.LoggingEnabled != nil {
+		if len(status.LoggingEnabled.TargetBucket) == 0 {
+			return nil, errors.New("target bucket is empty")
+		}
+		if len(status.LoggingEnabled.TargetPrefix) > maxLoggingTargetPrefixLength {
+			return nil, errors.New("target prefix is too long")
+		}
+	}
+	return status, nil
+}
+
+func storeBucketLogging(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSLogging, bytes); err != nil {
+		return
+	}
+	return nil
+}
+
+func deleteBucketLogging(vol *Volume) (err error) {
+	if err = vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSLogging); err != nil {
+		return err
+	}
+	return nil
+}
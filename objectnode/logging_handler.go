@@ -0,0 +1,167 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get bucket logging
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLogging.html
+func (o *ObjectNode) getBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketLoggingHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var status *BucketLoggingStatus
+	if status, err = vol.metaLoader.loadLogging(); err != nil {
+		log.LogErrorf("getBucketLoggingHandler: load bucket logging fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if status == nil {
+		// An empty status means access logging is disabled for this bucket.
+		status = &BucketLoggingStatus{}
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(status); err != nil {
+		log.LogErrorf("getBucketLoggingHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket logging
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+func (o *ObjectNode) putBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketLoggingHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var status *BucketLoggingStatus
+	if status, err = parseBucketLoggingStatus(bytes); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: parse bucket logging fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidBucketLoggingStatus
+		return
+	}
+
+	// A status without LoggingEnabled disables access logging of the bucket.
+	if !status.IsEnabled() {
+		if err = deleteBucketLogging(vol); err != nil {
+			log.LogErrorf("putBucketLoggingHandler: delete bucket logging fail: requestID(%v) volume(%v) err(%v)",
+				GetRequestID(r), param.Bucket(), err)
+			errorCode = InternalErrorCode(err)
+			return
+		}
+		vol.metaLoader.storeLogging(nil)
+		log.LogInfof("putBucketLoggingHandler: disable bucket logging: requestID(%v) volume(%v)",
+			GetRequestID(r), param.Bucket())
+		return
+	}
+
+	// The target bucket must exist and be owned by the owner of the source bucket.
+	var targetVol *Volume
+	if targetVol, err = o.getVol(status.LoggingEnabled.TargetBucket); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: load target volume fail: requestID(%v) volume(%v) target(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), status.LoggingEnabled.TargetBucket, err)
+		errorCode = InvalidTargetBucketForLogging
+		return
+	}
+	if targetVol.Owner() != vol.Owner() {
+		log.LogErrorf("putBucketLoggingHandler: target volume owner mismatch: requestID(%v) volume(%v) owner(%v) target(%v) targetOwner(%v)",
+			GetRequestID(r), param.Bucket(), vol.Owner(), targetVol.Name(), targetVol.Owner())
+		errorCode = InvalidTargetBucketForLogging
+		return
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(status); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketLogging(newBytes, vol); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: store bucket logging fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeLogging(status)
+
+	log.LogInfof("putBucketLoggingHandler: put bucket logging: requestID(%v) volume(%v) target(%v) prefix(%v)",
+		GetRequestID(r), param.Bucket(), status.LoggingEnabled.TargetBucket, status.LoggingEnabled.TargetPrefix)
+	return
+}
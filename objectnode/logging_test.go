@@ -0,0 +1,115 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseBucketLoggingStatus(t *testing.T) {
+	var cases = []struct {
+		raw     string
+		enabled bool
+		valid   bool
+	}{
+		{`<BucketLoggingStatus><LoggingEnabled><TargetBucket>logs</TargetBucket><TargetPrefix>access/</TargetPrefix></LoggingEnabled></BucketLoggingStatus>`, true, true},
+		{`<BucketLoggingStatus xmlns="http://doc.s3.amazonaws.com/2006-03-01"/>`, false, true},
+		{`<BucketLoggingStatus><LoggingEnabled><TargetPrefix>access/</TargetPrefix></LoggingEnabled></BucketLoggingStatus>`, false, false},
+		{`<BucketLoggingStatus><LoggingEnabled>`, false, false},
+	}
+	for i, c := range cases {
+		var status, err = parseBucketLoggingStatus([]byte(c.raw))
+		if (err == nil) != c.valid {
+			t.Fatalf("case %v: validity mismatch: expect(%v) err(%v)", i, c.valid, err)
+		}
+		if err == nil && status.IsEnabled() != c.enabled {
+			t.Fatalf("case %v: enabled mismatch: expect(%v) actual(%v)", i, c.enabled, status.IsEnabled())
+		}
+	}
+}
+
+func TestAccessLogRecordString(t *testing.T) {
+	var record = &AccessLogRecord{
+		BucketOwner:      "owner",
+		Bucket:           "bucket",
+		Time:             time.Date(2020, 6, 1, 8, 30, 0, 0, time.UTC),
+		RemoteIP:         "10.0.0.1",
+		Requester:        "user",
+		RequestID:        "1234",
+		Operation:        "REST.GET.GetObject",
+		Key:              "dir/a b.txt",
+		RequestURI:       "GET /bucket/dir/a%20b.txt HTTP/1.1",
+		HTTPStatus:       200,
+		BytesSent:        1024,
+		ObjectSize:       -1,
+		TotalTime:        15 * time.Millisecond,
+		UserAgent:        "aws-cli",
+		SignatureVersion: "SigV4",
+		AuthType:         "AuthHeader",
+		Host:             "bucket.s3.example.com",
+	}
+	var expect = `owner bucket [01/Jun/2020:08:30:00 +0000] 10.0.0.1 user 1234 REST.GET.GetObject dir/a+b.txt ` +
+		`"GET /bucket/dir/a%20b.txt HTTP/1.1" 200 - 1024 - 15 - - "aws-cli" - - SigV4 - AuthHeader bucket.s3.example.com`
+	if actual := record.String(); actual != expect {
+		t.Fatalf("record mismatch:\nexpect(%v)\nactual(%v)", expect, actual)
+	}
+}
+
+func TestAccessLoggerFlush(t *testing.T) {
+	var logger = NewAccessLogger(nil, time.Hour)
+	var mu sync.Mutex
+	var objects = make(map[string]string)
+	logger.put = func(target, key string, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		objects[target+"/"+key] = string(data)
+		return nil
+	}
+
+	var logging = &LoggingEnabled{TargetBucket: "logs", TargetPrefix: "access/"}
+	for i := 0; i < 3; i++ {
+		logger.Log(logging, &AccessLogRecord{Bucket: "bucket", HTTPStatus: 200, ObjectSize: -1})
+	}
+	logger.Log(&LoggingEnabled{TargetBucket: "other"}, &AccessLogRecord{Bucket: "bucket", HTTPStatus: 404, ObjectSize: -1})
+
+	// The records are written when the logger is stopped.
+	logger.Start()
+	logger.Stop()
+
+	if len(objects) != 2 {
+		t.Fatalf("log object count mismatch: expect(2) actual(%v)", len(objects))
+	}
+	for name, content := range objects {
+		var lines = strings.Count(content, "\n")
+		switch {
+		case strings.HasPrefix(name, "logs/access/"):
+			if lines != 3 {
+				t.Fatalf("log object %v line count mismatch: expect(3) actual(%v)", name, lines)
+			}
+			if len(strings.TrimPrefix(name, "logs/access/")) != len(accessLogKeyLayout)+17 {
+				t.Fatalf("log object key malformed: %v", name)
+			}
+		case strings.HasPrefix(name, "other/"):
+			if lines != 1 {
+				t.Fatalf("log object %v line count mismatch: expect(1) actual(%v)", name, lines)
+			}
+		default:
+			t.Fatalf("unexpected log object: %v", name)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/NotificationHowTo.html
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	MaxNotificationConfigurations = 100
+
+	EventObjectCreatedAll      = "s3:ObjectCreated:*"
+	EventObjectCreatedPut      = "s3:ObjectCreated:Put"
+	EventObjectCreatedPost     = "s3:ObjectCreated:Post"
+	EventObjectCreatedCopy     = "s3:ObjectCreated:Copy"
+	EventObjectCreatedComplete = "s3:ObjectCreated:CompleteMultipartUpload"
+	EventObjectRemovedAll      = "s3:ObjectRemoved:*"
+	EventObjectRemovedDelete   = "s3:ObjectRemoved:Delete"
+	EventObjectRemovedMarker   = "s3:ObjectRemoved:DeleteMarkerCreated"
+
+	NotificationFilterPrefix = "prefix"
+	NotificationFilterSuffix = "suffix"
+
+	// The notification target is referred by ARN "arn:chubaofs:sqs::{name}:webhook",
+	// where name is the name of a notification target configured on the ObjectNode.
+	notificationARNPrefix     = "arn:"
+	notificationARNTargetType = "webhook"
+)
+
+var supportedNotificationEvents = map[string]bool{
+	EventObjectCreatedAll:      true,
+	EventObjectCreatedPut:      true,
+	EventObjectCreatedPost:     true,
+	EventObjectCreatedCopy:     true,
+	EventObjectCreatedComplete: true,
+	EventObjectRemovedAll:      true,
+	EventObjectRemovedDelete:   true,
+	EventObjectRemovedMarker:   true,
+}
+
+// NotificationConfiguration configures the events of a bucket to be published to the notification targets.
+// Queue and topic configurations are handled in the same way since both of them refer to a target by ARN.
+type NotificationConfiguration struct {
+	XMLName                     xml.Name                 `xml:"NotificationConfiguration" json:"-"`
+	TopicConfigurations         []*NotificationRule      `xml:"TopicConfiguration,omitempty" json:"topics,omitempty"`
+	QueueConfigurations         []*NotificationRule      `xml:"QueueConfiguration,omitempty" json:"queues,omitempty"`
+	CloudFunctionConfigurations []*CloudFunctionRuleNode `xml:"CloudFunctionConfiguration,omitempty" json:"-"`
+}
+
+// CloudFunctionRuleNode is only used to reject the unsupported cloud function configurations.
+type CloudFunctionRuleNode struct {
+	CloudFunction string `xml:"CloudFunction"`
+}
+
+type NotificationRule struct {
+	Id     string              `xml:"Id,omitempty" json:"id,omitempty"`
+	Topic  string              `xml:"Topic,omitempty" json:"topic,omitempty"`
+	Queue  string              `xml:"Queue,omitempty" json:"queue,omitempty"`
+	Events []string            `xml:"Event" json:"events"`
+	Filter *NotificationFilter `xml:"Filter,omitempty" json:"filter,omitempty"`
+}
+
+type NotificationFilter struct {
+	FilterRules []*NotificationFilterRule `xml:"S3Key>FilterRule" json:"rules,omitempty"`
+}
+
+type NotificationFilterRule struct {
+	Name  string `xml:"Name" json:"name"`
+	Value string `xml:"Value" json:"value"`
+}
+
+// parseNotificationARN returns the name of the notification target referred by the ARN.
+func parseNotificationARN(arn string) (target string, ok bool) {
+	if !strings.HasPrefix(arn, notificationARNPrefix) {
+		return
+	}
+	var parts = strings.Split(arn, ":")
+	if len(parts) != 6 || parts[5] != notificationARNTargetType || len(parts[4]) == 0 {
+		return
+	}
+	return parts[4], true
+}
+
+// Target returns the ARN of the target referred by the rule.
+func (rule *NotificationRule) Target() string {
+	if len(rule.Queue) > 0 {
+		return rule.Queue
+	}
+	return rule.Topic
+}
+
+func (rule *NotificationRule) validate(hasTarget func(name string) bool) bool {
+	if len(rule.Queue) > 0 && len(rule.Topic) > 0 {
+		return false
+	}
+	if target, ok := parseNotificationARN(rule.Target()); !ok || !hasTarget(target) {
+		return false
+	}
+	if len(rule.Events) == 0 {
+		return false
+	}
+	for _, event := range rule.Events {
+		if !supportedNotificationEvents[event] {
+			return false
+		}
+	}
+	if rule.Filter != nil {
+		var names = make(map[string]bool)
+		for _, filterRule := range rule.Filter.FilterRules {
+			var name = strings.ToLower(filterRule.Name)
+			if name != NotificationFilterPrefix && name != NotificationFilterSuffix || names[name] {
+				return false
+			}
+			names[name] = true
+		}
+	}
+	return true
+}
+
+// Match returns whether the event of the key is configured by the rule.
+func (rule *NotificationRule) Match(eventName, key string) bool {
+	var matched bool
+	for _, event := range rule.Events {
+		if event == eventName || strings.HasSuffix(event, ":*") && strings.HasPrefix(eventName, strings.TrimSuffix(event, "*")) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	if rule.Filter != nil {
+		for _, filterRule := range rule.Filter.FilterRules {
+			switch strings.ToLower(filterRule.Name) {
+			case NotificationFilterPrefix:
+				matched = strings.HasPrefix(key, filterRule.Value)
+			case NotificationFilterSuffix:
+				matched = strings.HasSuffix(key, filterRule.Value)
+			}
+			if !matched {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Rules returns both the topic and queue configurations.
+func (config *NotificationConfiguration) Rules() []*NotificationRule {
+	var rules = make([]*NotificationRule, 0, len(config.TopicConfigurations)+len(config.QueueConfigurations))
+	rules = append(rules, config.TopicConfigurations...)
+	return append(rules, config.QueueConfigurations...)
+}
+
+// IsEmpty returns whether no event is configured, which turns off the notifications of the bucket.
+func (config *NotificationConfiguration) IsEmpty() bool {
+	return len(config.TopicConfigurations) == 0 && len(config.QueueConfigurations) == 0
+}
+
+func (config *NotificationConfiguration) validate(hasTarget func(name string) bool) bool {
+	if len(config.CloudFunctionConfigurations) > 0 {
+		return false
+	}
+	var rules = config.Rules()
+	if len(rules) > MaxNotificationConfigurations {
+		return false
+	}
+	var ids = make(map[string]bool)
+	for _, rule := range rules {
+		if len(rule.Id) > 0 && ids[rule.Id] {
+			return false
+		}
+		ids[rule.Id] = true
+		if !rule.validate(hasTarget) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNotificationConfig(bytes []byte, hasTarget func(name string) bool) (config *NotificationConfiguration, err error) {
+	config = &NotificationConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return
+	}
+	if ok := config.validate(hasTarget); !ok {
+		return nil, errors.New("invalid notification configuration")
+	}
+	// Generate an ID for the rule without ID like Amazon S3.
+	for _, rule := range config.Rules() {
+		if len(rule.Id) == 0 {
+			rule.Id = strings.ReplaceAll(uuid.New().String(), "-", "")
+		}
+	}
+	return
+}
+
+func storeBucketNotification(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSNotification, bytes); err != nil {
+		return
+	}
+	return nil
+}
+
+func deleteBucketNotification(vol *Volume) (err error) {
+	if err = vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSNotification); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NotificationEvent is the message delivered to the notification targets, which is compatible with
+// the event message structure of Amazon S3.
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/dev/notification-content-structure.html
+type NotificationEvent struct {
+	Records []*NotificationRecord `json:"Records"`
+}
+
+type NotificationRecord struct {
+	EventVersion      string               `json:"eventVersion"`
+	EventSource       string               `json:"eventSource"`
+	AwsRegion         string               `json:"awsRegion"`
+	EventTime         string               `json:"eventTime"`
+	EventName         string               `json:"eventName"`
+	UserIdentity      NotificationIdentity `json:"userIdentity"`
+	RequestParameters map[string]string    `json:"requestParameters"`
+	ResponseElements  map[string]string    `json:"responseElements"`
+	S3                NotificationS3Entity `json:"s3"`
+}
+
+type NotificationIdentity struct {
+	PrincipalId string `json:"principalId"`
+}
+
+type NotificationS3Entity struct {
+	SchemaVersion   string             `json:"s3SchemaVersion"`
+	ConfigurationId string             `json:"configurationId"`
+	Bucket          NotificationBucket `json:"bucket"`
+	Object          NotificationObject `json:"object"`
+}
+
+type NotificationBucket struct {
+	Name          string               `json:"name"`
+	OwnerIdentity NotificationIdentity `json:"ownerIdentity"`
+	Arn           string               `json:"arn"`
+}
+
+type NotificationObject struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionId string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+func newNotificationObject(key string, fileInfo *FSFileInfo) NotificationObject {
+	return NotificationObject{
+		Key:       key,
+		Size:      fileInfo.Size,
+		ETag:      fileInfo.ETag,
+		VersionId: fileInfo.VersionId,
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get bucket notification configuration
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketNotificationConfiguration.html
+func (o *ObjectNode) getBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketNotificationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var notification *NotificationConfiguration
+	if notification, err = vol.metaLoader.loadNotification(); err != nil {
+		log.LogErrorf("getBucketNotificationHandler: load notification fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	// An empty configuration is returned if the notification is not configured.
+	if notification == nil {
+		notification = &NotificationConfiguration{}
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(notification); err != nil {
+		log.LogErrorf("getBucketNotificationHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket notification configuration
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketNotificationConfiguration.html
+func (o *ObjectNode) putBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketNotificationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketNotificationHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var notification *NotificationConfiguration
+	if notification, err = parseNotificationConfig(bytes, o.notifier.HasTarget); err != nil {
+		log.LogErrorf("putBucketNotificationHandler: parse notification configuration fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidNotificationConfiguration
+		return
+	}
+
+	// An empty configuration turns off the notifications of the bucket.
+	if notification.IsEmpty() {
+		if err = deleteBucketNotification(vol); err != nil {
+			log.LogErrorf("putBucketNotificationHandler: delete notification fail: requestID(%v) volume(%v) err(%v)",
+				GetRequestID(r), param.Bucket(), err)
+			errorCode = InternalErrorCode(err)
+			return
+		}
+		vol.metaLoader.storeNotification(nil)
+		return
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(notification); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketNotification(newBytes, vol); err != nil {
+		log.LogErrorf("putBucketNotificationHandler: store notification fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeNotification(notification)
+
+	log.LogInfof("putBucketNotificationHandler: put bucket notification: requestID(%v) volume(%v)",
+		GetRequestID(r), param.Bucket())
+	return
+}
+
+// notifyEvent publishes the event of the object if the bucket is configured with notifications.
+// The event is queued and delivered asynchronously, so it never fails the request.
+func (o *ObjectNode) notifyEvent(r *http.Request, param *RequestParam, vol *Volume, eventName string, object NotificationObject) {
+	var notification, err = vol.metaLoader.loadNotification()
+	if err != nil {
+		log.LogErrorf("notifyEvent: load notification fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if notification == nil {
+		return
+	}
+	o.notifier.Notify(vol, notification, eventName, object, param.AccessKey(), getRequestIP(r), GetRequestID(r))
+}
+
+// notifyDeleteEvent publishes the event of deleting an object. It is a delete marker creation if the object
+// is deleted without version ID while versioning is enabled, whose version ID is markerVersionId.
+func (o *ObjectNode) notifyDeleteEvent(r *http.Request, param *RequestParam, vol *Volume, key, versionId, markerVersionId string) {
+	if len(versionId) == 0 && len(markerVersionId) > 0 {
+		o.notifyEvent(r, param, vol, EventObjectRemovedMarker, NotificationObject{Key: key, VersionId: markerVersionId})
+		return
+	}
+	o.notifyEvent(r, param, vol, EventObjectRemovedDelete, NotificationObject{Key: key, VersionId: versionId})
+}
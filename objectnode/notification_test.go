@@ -0,0 +1,249 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testHasTarget(name string) bool {
+	return name == "audit"
+}
+
+func TestNotification_Parse(t *testing.T) {
+	var valid = `<NotificationConfiguration>
+	<QueueConfiguration>
+		<Id>images</Id>
+		<Queue>arn:chubaofs:sqs::audit:webhook</Queue>
+		<Event>s3:ObjectCreated:*</Event>
+		<Event>s3:ObjectRemoved:Delete</Event>
+		<Filter><S3Key>
+			<FilterRule><Name>prefix</Name><Value>images/</Value></FilterRule>
+			<FilterRule><Name>Suffix</Name><Value>.jpg</Value></FilterRule>
+		</S3Key></Filter>
+	</QueueConfiguration>
+	<TopicConfiguration>
+		<Topic>arn:chubaofs:sns::audit:webhook</Topic>
+		<Event>s3:ObjectRemoved:*</Event>
+	</TopicConfiguration>
+</NotificationConfiguration>`
+	config, err := parseNotificationConfig([]byte(valid), testHasTarget)
+	if err != nil {
+		t.Fatalf("parse notification configuration fail: err(%v)", err)
+	}
+	var rules = config.Rules()
+	if len(rules) != 2 || len(rules[1].Filter.FilterRules) != 2 {
+		t.Fatalf("rules mismatch: rules(%v)", rules)
+	}
+	if rules[0].Id == "" || rules[1].Id != "images" {
+		t.Fatalf("rule ID mismatch: id(%v, %v)", rules[0].Id, rules[1].Id)
+	}
+
+	config, err = parseNotificationConfig([]byte(`<NotificationConfiguration></NotificationConfiguration>`), testHasTarget)
+	if err != nil || !config.IsEmpty() {
+		t.Fatalf("parse empty notification configuration fail: err(%v)", err)
+	}
+
+	var invalids = []string{
+		// unknown target
+		`<NotificationConfiguration><QueueConfiguration>
+			<Queue>arn:chubaofs:sqs::unknown:webhook</Queue><Event>s3:ObjectCreated:*</Event>
+		</QueueConfiguration></NotificationConfiguration>`,
+		// unsupported event
+		`<NotificationConfiguration><QueueConfiguration>
+			<Queue>arn:chubaofs:sqs::audit:webhook</Queue><Event>s3:ObjectRestore:*</Event>
+		</QueueConfiguration></NotificationConfiguration>`,
+		// duplicated filter rule
+		`<NotificationConfiguration><QueueConfiguration>
+			<Queue>arn:chubaofs:sqs::audit:webhook</Queue><Event>s3:ObjectCreated:*</Event>
+			<Filter><S3Key>
+				<FilterRule><Name>prefix</Name><Value>a</Value></FilterRule>
+				<FilterRule><Name>prefix</Name><Value>b</Value></FilterRule>
+			</S3Key></Filter>
+		</QueueConfiguration></NotificationConfiguration>`,
+		// duplicated ID
+		`<NotificationConfiguration>
+			<QueueConfiguration><Id>a</Id><Queue>arn:chubaofs:sqs::audit:webhook</Queue><Event>s3:ObjectCreated:*</Event></QueueConfiguration>
+			<QueueConfiguration><Id>a</Id><Queue>arn:chubaofs:sqs::audit:webhook</Queue><Event>s3:ObjectRemoved:*</Event></QueueConfiguration>
+		</NotificationConfiguration>`,
+		// unsupported cloud function
+		`<NotificationConfiguration><CloudFunctionConfiguration>
+			<CloudFunction>arn:aws:lambda:us-west-2:35667example:function:CreateThumbnail</CloudFunction><Event>s3:ObjectCreated:*</Event>
+		</CloudFunctionConfiguration></NotificationConfiguration>`,
+	}
+	for _, invalid := range invalids {
+		if _, err = parseNotificationConfig([]byte(invalid), testHasTarget); err == nil {
+			t.Fatalf("parse invalid notification configuration expect error: config(%v)", invalid)
+		}
+	}
+}
+
+func TestNotification_Match(t *testing.T) {
+	var rule = &NotificationRule{
+		Events: []string{EventObjectCreatedAll, EventObjectRemovedDelete},
+		Filter: &NotificationFilter{FilterRules: []*NotificationFilterRule{
+			{Name: "prefix", Value: "images/"},
+			{Name: "suffix", Value: ".jpg"},
+		}},
+	}
+	var cases = []struct {
+		event  string
+		key    string
+		expect bool
+	}{
+		{EventObjectCreatedPut, "images/a.jpg", true},
+		{EventObjectCreatedComplete, "images/b/c.jpg", true},
+		{EventObjectRemovedDelete, "images/a.jpg", true},
+		{EventObjectRemovedMarker, "images/a.jpg", false},
+		{EventObjectCreatedPut, "docs/a.jpg", false},
+		{EventObjectCreatedPut, "images/a.png", false},
+	}
+	for _, c := range cases {
+		if actual := rule.Match(c.event, c.key); actual != c.expect {
+			t.Fatalf("match result mismatch: event(%v) key(%v) expect(%v) actual(%v)", c.event, c.key, c.expect, actual)
+		}
+	}
+}
+
+func TestNotification_Queue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notification")
+	if err != nil {
+		t.Fatalf("create temp dir fail: err(%v)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	var received []string
+	var failures = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get(HeaderNameAuthorization) != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// the target is unavailable at first
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		received = append(received, string(data))
+	}))
+	defer server.Close()
+
+	var target = &NotificationTarget{Name: "audit", Endpoint: server.URL, AuthToken: "token", QueueLimit: 3}
+	notifier, err := NewNotifier(dir, []*NotificationTarget{target})
+	if err != nil {
+		t.Fatalf("new notifier fail: err(%v)", err)
+	}
+	var queue = notifier.queues["audit"]
+	// events are queued on disk before the notifier is started
+	for _, event := range []string{"1", "2", "3", "4"} {
+		err = queue.enqueue([]byte(event))
+		if event == "4" && err == nil {
+			t.Fatalf("enqueue to full queue expect error")
+		}
+		if event != "4" && err != nil {
+			t.Fatalf("enqueue fail: event(%v) err(%v)", event, err)
+		}
+	}
+
+	// the queued events survive restarts
+	if notifier, err = NewNotifier(dir, []*NotificationTarget{target}); err != nil {
+		t.Fatalf("new notifier fail: err(%v)", err)
+	}
+	if count := notifier.queues["audit"].count; count != 3 {
+		t.Fatalf("queued events mismatch: expect(3) actual(%v)", count)
+	}
+	notifier.Start()
+	defer notifier.Stop()
+	var deadline = time.Now().Add(10 * time.Second)
+	for {
+		mu.Lock()
+		var done = len(received) == 3
+		mu.Unlock()
+		// the event is removed after the target has received it
+		if names, _ := notifier.queues["audit"].list(); done && len(names) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("events not delivered or removed: received(%v)", received)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if received[0] != "1" || received[1] != "2" || received[2] != "3" {
+		t.Fatalf("events order mismatch: received(%v)", received)
+	}
+}
+
+func TestNotification_DeadLetter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notification")
+	if err != nil {
+		t.Fatalf("create temp dir fail: err(%v)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		// the target always rejects the poison event
+		if string(data) == "poison" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, string(data))
+	}))
+	defer server.Close()
+
+	var target = &NotificationTarget{Name: "audit", Endpoint: server.URL, MaxRetries: 2}
+	notifier, err := NewNotifier(dir, []*NotificationTarget{target})
+	if err != nil {
+		t.Fatalf("new notifier fail: err(%v)", err)
+	}
+	var queue = notifier.queues["audit"]
+	for _, event := range []string{"poison", "1"} {
+		if err = queue.enqueue([]byte(event)); err != nil {
+			t.Fatalf("enqueue fail: event(%v) err(%v)", event, err)
+		}
+	}
+	// the poison event blocks the queue until it fails after the max retries
+	for i := 0; i < target.MaxRetries; i++ {
+		if err = queue.deliver(); err == nil {
+			t.Fatalf("deliver poison event expect error: retry(%v)", i)
+		}
+	}
+	if err = queue.deliver(); err != nil {
+		t.Fatalf("deliver fail: err(%v)", err)
+	}
+	if len(received) != 1 || received[0] != "1" {
+		t.Fatalf("events after poison event not delivered: received(%v)", received)
+	}
+	if names, _ := queue.list(); len(names) != 0 || queue.count != 0 {
+		t.Fatalf("events remain in queue: events(%v) count(%v)", names, queue.count)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "audit", notificationDeadLetterFileName))
+	if err != nil || string(data) != "poison\n" {
+		t.Fatalf("dead letter mismatch: data(%q) err(%v)", data, err)
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	notificationEventVersion  = "2.1"
+	notificationEventSource   = "chubaofs:s3"
+	notificationSchemaVersion = "1.0"
+
+	// Default maximum number of events in the queue of a target, new events are dropped if the queue is full.
+	defaultNotificationQueueLimit = 100000
+	// Default maximum number of retries of an event, the event is moved to the dead letter file of the target
+	// once it has been retried so many times, so that it does not block the following events forever.
+	defaultNotificationMaxRetries = 20
+
+	notificationSendTimeout = 10 * time.Second
+	notificationMinBackoff  = time.Second
+	notificationMaxBackoff  = time.Minute
+	// Interval to check the queue in case of missing signals.
+	notificationScanInterval = 10 * time.Second
+
+	notificationEventFileSuffix = ".event"
+	notificationTempFileSuffix  = ".tmp"
+	// Every line of the dead letter file is an event which has not been delivered.
+	notificationDeadLetterFileName = "dead-letter"
+)
+
+// NotificationTarget is a webhook which receives the event notifications of buckets.
+// It is referred by the ARN "arn:chubaofs:sqs::{name}:webhook" in the notification configuration.
+type NotificationTarget struct {
+	Name       string `json:"name"`
+	Endpoint   string `json:"endpoint"`
+	AuthToken  string `json:"authToken"`
+	QueueLimit int    `json:"queueLimit"`
+	MaxRetries int    `json:"maxRetries"`
+}
+
+// notificationQueue is the on-disk queue of the events to be delivered to a target.
+// Every event is stored as a file named by its enqueue time, and it is removed after
+// it has been delivered. So the events are delivered in order and survive restarts,
+// and a slow or unavailable target never blocks the requests which publish the events.
+type notificationQueue struct {
+	target  *NotificationTarget
+	dir     string
+	client  *http.Client
+	mu      sync.Mutex
+	seq     uint64
+	count   int
+	signalC chan struct{}
+
+	failedName string // the event at the head of the queue which failed to be delivered
+	retries    int    // number of retries of the failed event
+}
+
+func newNotificationQueue(target *NotificationTarget, dir string, client *http.Client) (queue *notificationQueue, err error) {
+	queue = &notificationQueue{
+		target:  target,
+		dir:     dir,
+		client:  client,
+		signalC: make(chan struct{}, 1),
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	var names []string
+	if names, err = queue.list(); err != nil {
+		return
+	}
+	queue.count = len(names)
+	return
+}
+
+// list returns the names of the queued events in order.
+func (q *notificationQueue) list() (names []string, err error) {
+	var infos []os.FileInfo
+	if infos, err = ioutil.ReadDir(q.dir); err != nil {
+		return
+	}
+	for _, info := range infos {
+		if strings.HasSuffix(info.Name(), notificationEventFileSuffix) {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+func (q *notificationQueue) enqueue(data []byte) (err error) {
+	q.mu.Lock()
+	if q.count >= q.target.QueueLimit {
+		q.mu.Unlock()
+		return errors.NewErrorf("queue of target %v is full", q.target.Name)
+	}
+	q.seq++
+	var name = fmt.Sprintf("%020d-%010d", time.Now().UnixNano(), q.seq)
+	q.count++
+	q.mu.Unlock()
+
+	// Write to a temporary file first so that the worker never reads a partial event.
+	var tempPath = filepath.Join(q.dir, name+notificationTempFileSuffix)
+	if err = ioutil.WriteFile(tempPath, data, 0644); err == nil {
+		err = os.Rename(tempPath, filepath.Join(q.dir, name+notificationEventFileSuffix))
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		q.mu.Lock()
+		q.count--
+		q.mu.Unlock()
+		return
+	}
+	select {
+	case q.signalC <- struct{}{}:
+	default:
+	}
+	return
+}
+
+func (q *notificationQueue) send(data []byte) (err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodPost, q.target.Endpoint, bytes.NewReader(data)); err != nil {
+		return
+	}
+	req.Header.Set(HeaderNameContentType, "application/json")
+	if len(q.target.AuthToken) > 0 {
+		req.Header.Set(HeaderNameAuthorization, "Bearer "+q.target.AuthToken)
+	}
+	var resp *http.Response
+	if resp, err = q.client.Do(req); err != nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.NewErrorf("unexpected status code %v", resp.StatusCode)
+	}
+	return
+}
+
+// deliver sends the queued events in order until the queue is empty or an event fails to be sent.
+// The event which has failed more than the maximum retries is moved to the dead letter file.
+func (q *notificationQueue) deliver() (err error) {
+	var names []string
+	if names, err = q.list(); err != nil {
+		return
+	}
+	for _, name := range names {
+		var path = filepath.Join(q.dir, name)
+		var data []byte
+		if data, err = ioutil.ReadFile(path); err != nil {
+			return
+		}
+		if err = q.send(data); err != nil {
+			if name != q.failedName {
+				q.failedName, q.retries = name, 0
+			}
+			if q.retries < q.target.MaxRetries {
+				q.retries++
+				return
+			}
+			log.LogErrorf("notificationQueue: event exceeds max retries and moves to dead letter: target(%v) event(%v) retries(%v) err(%v)",
+				q.target.Name, name, q.retries, err)
+			if err = q.appendDeadLetter(data); err != nil {
+				return
+			}
+		}
+		if err = os.Remove(path); err != nil {
+			return
+		}
+		q.mu.Lock()
+		q.count--
+		q.mu.Unlock()
+	}
+	return
+}
+
+func (q *notificationQueue) appendDeadLetter(data []byte) (err error) {
+	var file *os.File
+	if file, err = os.OpenFile(filepath.Join(q.dir, notificationDeadLetterFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		return
+	}
+	return file.Sync()
+}
+
+func (q *notificationQueue) run(stopC chan struct{}) {
+	var backoff = notificationMinBackoff
+	var ticker = time.NewTicker(notificationScanInterval)
+	defer ticker.Stop()
+	for {
+		if err := q.deliver(); err != nil {
+			// retry the failed event after backing off while the target is unavailable
+			log.LogWarnf("notificationQueue: deliver events fail: target(%v) backoff(%v) err(%v)",
+				q.target.Name, backoff, err)
+			select {
+			case <-stopC:
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > notificationMaxBackoff {
+				backoff = notificationMaxBackoff
+			}
+			continue
+		}
+		backoff = notificationMinBackoff
+		select {
+		case <-stopC:
+			return
+		case <-q.signalC:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Notifier publishes the events of objects to the notification targets by the notification
+// configurations of buckets. The events are queued on the local disk and delivered by a worker of each target.
+type Notifier struct {
+	region   string
+	queues   map[string]*notificationQueue
+	stopOnce sync.Once
+	stopC    chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewNotifier(dir string, targets []*NotificationTarget) (n *Notifier, err error) {
+	n = &Notifier{
+		queues: make(map[string]*notificationQueue),
+		stopC:  make(chan struct{}),
+	}
+	var client = &http.Client{Timeout: notificationSendTimeout}
+	for _, target := range targets {
+		if target.QueueLimit <= 0 {
+			target.QueueLimit = defaultNotificationQueueLimit
+		}
+		if target.MaxRetries <= 0 {
+			target.MaxRetries = defaultNotificationMaxRetries
+		}
+		var queue *notificationQueue
+		if queue, err = newNotificationQueue(target, filepath.Join(dir, target.Name), client); err != nil {
+			return
+		}
+		n.queues[target.Name] = queue
+	}
+	return
+}
+
+// HasTarget returns whether the target is configured.
+func (n *Notifier) HasTarget(name string) bool {
+	_, has := n.queues[name]
+	return has
+}
+
+func (n *Notifier) Start() {
+	for _, queue := range n.queues {
+		n.wg.Add(1)
+		go func(queue *notificationQueue) {
+			defer n.wg.Done()
+			queue.run(n.stopC)
+		}(queue)
+	}
+	log.LogInfof("Notifier: started: targets(%v)", len(n.queues))
+}
+
+func (n *Notifier) Stop() {
+	n.stopOnce.Do(func() {
+		close(n.stopC)
+		n.wg.Wait()
+	})
+}
+
+// Notify publishes the event of the object to the targets of the rules in the configuration which match the event.
+func (n *Notifier) Notify(vol *Volume, config *NotificationConfiguration, eventName string, object NotificationObject,
+	principalId, sourceIP, requestID string) {
+	var now = time.Now().UTC()
+	object.Sequencer = fmt.Sprintf("%016X", now.UnixNano())
+	for _, rule := range config.Rules() {
+		if !rule.Match(eventName, object.Key) {
+			continue
+		}
+		var target, _ = parseNotificationARN(rule.Target())
+		var queue, has = n.queues[target]
+		if !has {
+			log.LogWarnf("Notifier: target not found: volume(%v) rule(%v) target(%v)", vol.Name(), rule.Id, target)
+			continue
+		}
+		var record = &NotificationRecord{
+			EventVersion:      notificationEventVersion,
+			EventSource:       notificationEventSource,
+			AwsRegion:         n.region,
+			EventTime:         now.Format("2006-01-02T15:04:05.000Z"),
+			EventName:         strings.TrimPrefix(eventName, "s3:"),
+			UserIdentity:      NotificationIdentity{PrincipalId: principalId},
+			RequestParameters: map[string]string{"sourceIPAddress": sourceIP},
+			ResponseElements:  map[string]string{"x-amz-request-id": requestID},
+			S3: NotificationS3Entity{
+				SchemaVersion:   notificationSchemaVersion,
+				ConfigurationId: rule.Id,
+				Bucket: NotificationBucket{
+					Name:          vol.Name(),
+					OwnerIdentity: NotificationIdentity{PrincipalId: vol.Owner()},
+					Arn:           "arn:aws:s3:::" + vol.Name(),
+				},
+				Object: object,
+			},
+		}
+		record.S3.Object.Key = url.QueryEscape(object.Key)
+		var data, err = json.Marshal(&NotificationEvent{Records: []*NotificationRecord{record}})
+		if err != nil {
+			log.LogErrorf("Notifier: marshal event fail: volume(%v) key(%v) err(%v)", vol.Name(), object.Key, err)
+			continue
+		}
+		if err = queue.enqueue(data); err != nil {
+			log.LogErrorf("Notifier: enqueue event fail: volume(%v) key(%v) event(%v) target(%v) err(%v)",
+				vol.Name(), object.Key, eventName, target, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/object-lock.html
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+// ObjectRetention is the retention period of an object, the object can not be deleted or overwritten
+// until the retain-until date. A retention without mode and date removes the retention.
+type ObjectRetention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Mode            string   `xml:"Mode,omitempty"`
+	RetainUntilDate string   `xml:"RetainUntilDate,omitempty"`
+}
+
+// ObjectLegalHold prevents an object from being deleted or overwritten until it is removed.
+type ObjectLegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string   `xml:"Status"`
+}
+
+func (r *ObjectRetention) empty() bool {
+	return len(r.Mode) == 0 && len(r.RetainUntilDate) == 0
+}
+
+func (r *ObjectRetention) retainUntil() time.Time {
+	retainUntil, _ := time.Parse(time.RFC3339, r.RetainUntilDate)
+	return retainUntil
+}
+
+func (r *ObjectRetention) validate(now time.Time) bool {
+	if r.empty() {
+		return true
+	}
+	if r.Mode != proto.ObjectLockModeGovernance && r.Mode != proto.ObjectLockModeCompliance {
+		return false
+	}
+	retainUntil, err := time.Parse(time.RFC3339, r.RetainUntilDate)
+	if err != nil || !retainUntil.After(now) {
+		return false
+	}
+	// store the date in a uniform format
+	r.RetainUntilDate = retainUntil.UTC().Format(time.RFC3339)
+	return true
+}
+
+func validLegalHoldStatus(status string) bool {
+	return status == proto.ObjectLockLegalHoldOn || status == proto.ObjectLockLegalHoldOff
+}
+
+func parseObjectRetention(bytes []byte) (retention *ObjectRetention, err error) {
+	retention = &ObjectRetention{}
+	if err = xml.Unmarshal(bytes, retention); err != nil {
+		return
+	}
+	if !retention.validate(time.Now()) {
+		return nil, errors.New("invalid object retention")
+	}
+	return
+}
+
+func parseObjectLegalHold(bytes []byte) (legalHold *ObjectLegalHold, err error) {
+	legalHold = &ObjectLegalHold{}
+	if err = xml.Unmarshal(bytes, legalHold); err != nil {
+		return
+	}
+	if !validLegalHoldStatus(legalHold.Status) {
+		return nil, errors.New("invalid object legal hold")
+	}
+	return
+}
+
+// ParseObjectLockHeaders parses the object lock specified by the headers of PutObject and CreateMultipartUpload,
+// and returns the extend attributes to store it.
+func ParseObjectLockHeaders(header http.Header) (xattrs map[string]string, errorCode *ErrorCode) {
+	xattrs = make(map[string]string)
+	var retention = &ObjectRetention{
+		Mode:            header.Get(HeaderNameXAmzObjectLockMode),
+		RetainUntilDate: header.Get(HeaderNameXAmzObjectLockRetainUntilDate),
+	}
+	if !retention.empty() {
+		if len(retention.Mode) == 0 || len(retention.RetainUntilDate) == 0 || !retention.validate(time.Now()) {
+			return nil, InvalidObjectLockHeaders
+		}
+		xattrs[proto.XAttrKeyObjectLockMode] = retention.Mode
+		xattrs[proto.XAttrKeyObjectLockRetainUntil] = retention.RetainUntilDate
+	}
+	if legalHold := header.Get(HeaderNameXAmzObjectLockLegalHold); len(legalHold) > 0 {
+		if !validLegalHoldStatus(legalHold) {
+			return nil, InvalidArgument
+		}
+		xattrs[proto.XAttrKeyObjectLockLegalHold] = legalHold
+	}
+	return
+}
+
+func isObjectLockXAttr(key string) bool {
+	for _, lockKey := range proto.ObjectLockXAttrKeys {
+		if key == lockKey {
+			return true
+		}
+	}
+	return false
+}
+
+func bypassGovernanceRetention(header http.Header) bool {
+	return strings.ToLower(header.Get(HeaderNameXAmzBypassGovernanceRetention)) == "true"
+}
+
+func setObjectLockHeaders(w http.ResponseWriter, fileInfo *FSFileInfo) {
+	if len(fileInfo.ObjectLockMode) > 0 {
+		w.Header()[HeaderNameXAmzObjectLockMode] = []string{fileInfo.ObjectLockMode}
+		w.Header()[HeaderNameXAmzObjectLockRetainUntilDate] = []string{fileInfo.ObjectLockRetainUntilDate}
+	}
+	if len(fileInfo.ObjectLockLegalHold) > 0 {
+		w.Header()[HeaderNameXAmzObjectLockLegalHold] = []string{fileInfo.ObjectLockLegalHold}
+	}
+}
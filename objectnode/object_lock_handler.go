@@ -0,0 +1,281 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"syscall"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// allowBypassGovernance returns whether the request bypasses the governance retention,
+// which is only allowed for the bucket owner.
+func (o *ObjectNode) allowBypassGovernance(r *http.Request, param *RequestParam, vol *Volume) bool {
+	if !bypassGovernanceRetention(r.Header) {
+		return false
+	}
+	userInfo, err := o.getUserInfoByAccessKey(param.AccessKey())
+	if err != nil {
+		log.LogErrorf("allowBypassGovernance: get user info fail: requestID(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), param.AccessKey(), err)
+		return false
+	}
+	return userInfo.UserID == vol.Owner()
+}
+
+// Get object retention
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectRetention.html
+func (o *ObjectNode) getObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getObjectRetentionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var retention *ObjectRetention
+	if retention, err = vol.GetObjectRetention(param.Object()); err == syscall.ENOENT {
+		errorCode = NoSuchKey
+		return
+	}
+	if err != nil {
+		log.LogErrorf("getObjectRetentionHandler: get object retention fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), param.Object(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if retention == nil {
+		errorCode = NoSuchObjectLockConfiguration
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(retention); err != nil {
+		log.LogErrorf("getObjectRetentionHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put object retention
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectRetention.html
+func (o *ObjectNode) putObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putObjectRetentionHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putObjectRetentionHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var retention *ObjectRetention
+	if retention, err = parseObjectRetention(bytes); err != nil {
+		log.LogErrorf("putObjectRetentionHandler: parse object retention fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidObjectLockConfiguration
+		return
+	}
+
+	err = vol.PutObjectRetention(param.Object(), retention, o.allowBypassGovernance(r, param, vol))
+	if err == syscall.ENOENT {
+		errorCode = NoSuchKey
+		return
+	}
+	if err == syscall.EPERM {
+		errorCode = ObjectLocked
+		return
+	}
+	if err != nil {
+		log.LogErrorf("putObjectRetentionHandler: put object retention fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), param.Object(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	return
+}
+
+// Get object legal hold
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectLegalHold.html
+func (o *ObjectNode) getObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getObjectLegalHoldHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var legalHold *ObjectLegalHold
+	if legalHold, err = vol.GetObjectLegalHold(param.Object()); err == syscall.ENOENT {
+		errorCode = NoSuchKey
+		return
+	}
+	if err != nil {
+		log.LogErrorf("getObjectLegalHoldHandler: get object legal hold fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), param.Object(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(legalHold); err != nil {
+		log.LogErrorf("getObjectLegalHoldHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put object legal hold
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectLegalHold.html
+func (o *ObjectNode) putObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putObjectLegalHoldHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var legalHold *ObjectLegalHold
+	if legalHold, err = parseObjectLegalHold(bytes); err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: parse object legal hold fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidObjectLockConfiguration
+		return
+	}
+
+	if err = vol.PutObjectLegalHold(param.Object(), legalHold); err == syscall.ENOENT {
+		errorCode = NoSuchKey
+		return
+	}
+	if err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: put object legal hold fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), param.Object(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	return
+}
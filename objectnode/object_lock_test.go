@@ -0,0 +1,79 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestObjectLock_ParseRetention(t *testing.T) {
+	var future = time.Now().Add(time.Hour).UTC()
+	var valid = `<Retention><Mode>COMPLIANCE</Mode><RetainUntilDate>` +
+		future.Format("2006-01-02T15:04:05.000Z") + `</RetainUntilDate></Retention>`
+	retention, err := parseObjectRetention([]byte(valid))
+	if err != nil {
+		t.Fatalf("parse retention fail: err(%v)", err)
+	}
+	if retention.Mode != proto.ObjectLockModeCompliance {
+		t.Fatalf("retention mode mismatch: expect(COMPLIANCE) actual(%v)", retention.Mode)
+	}
+	if expect := future.Format(time.RFC3339); retention.RetainUntilDate != expect {
+		t.Fatalf("retain until date mismatch: expect(%v) actual(%v)", expect, retention.RetainUntilDate)
+	}
+	if retention, err = parseObjectRetention([]byte(`<Retention></Retention>`)); err != nil || !retention.empty() {
+		t.Fatalf("parse empty retention fail: retention(%v) err(%v)", retention, err)
+	}
+
+	var invalids = []string{
+		`<Retention><Mode>UNKNOWN</Mode><RetainUntilDate>` + future.Format(time.RFC3339) + `</RetainUntilDate></Retention>`,
+		`<Retention><Mode>GOVERNANCE</Mode><RetainUntilDate>2000-01-01T00:00:00Z</RetainUntilDate></Retention>`,
+		`<Retention><Mode>GOVERNANCE</Mode></Retention>`,
+	}
+	for _, invalid := range invalids {
+		if _, err = parseObjectRetention([]byte(invalid)); err == nil {
+			t.Fatalf("invalid retention is accepted: %v", invalid)
+		}
+	}
+}
+
+func TestObjectLock_ParseLegalHold(t *testing.T) {
+	legalHold, err := parseObjectLegalHold([]byte(`<LegalHold><Status>ON</Status></LegalHold>`))
+	if err != nil || legalHold.Status != proto.ObjectLockLegalHoldOn {
+		t.Fatalf("parse legal hold fail: legalHold(%v) err(%v)", legalHold, err)
+	}
+	if _, err = parseObjectLegalHold([]byte(`<LegalHold><Status>on</Status></LegalHold>`)); err == nil {
+		t.Fatalf("invalid legal hold is accepted")
+	}
+}
+
+func TestObjectLock_ParseHeaders(t *testing.T) {
+	var header = make(http.Header)
+	header.Set(HeaderNameXAmzObjectLockMode, proto.ObjectLockModeGovernance)
+	header.Set(HeaderNameXAmzObjectLockRetainUntilDate, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	header.Set(HeaderNameXAmzObjectLockLegalHold, proto.ObjectLockLegalHoldOn)
+	xattrs, errorCode := ParseObjectLockHeaders(header)
+	if errorCode != nil || len(xattrs) != 3 {
+		t.Fatalf("parse object lock headers fail: xattrs(%v) errorCode(%v)", xattrs, errorCode)
+	}
+
+	header.Del(HeaderNameXAmzObjectLockRetainUntilDate)
+	if _, errorCode = ParseObjectLockHeaders(header); errorCode != InvalidObjectLockHeaders {
+		t.Fatalf("object lock mode without retain until date is accepted")
+	}
+}
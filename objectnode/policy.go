@@ -163,9 +163,9 @@ func (p Policy) Validate(bucket string) (bool, error) {
 	return true, nil
 }
 
-// check policy is allowed for request
+// check policy is allowed for request, public statements do not allow anything if restrictPublic is set
 // https://docs.aws.amazon.com/zh_cn/IAM/latest/UserGuide/reference_policies_evaluation-logic.html
-func (p *Policy) IsAllowed(params *RequestParam, isOwner bool) bool {
+func (p *Policy) IsAllowed(params *RequestParam, isOwner, restrictPublic bool) bool {
 	for _, s := range p.Statements {
 		if s.Effect == Deny {
 			if !s.IsAllowed(params) {
@@ -182,6 +182,9 @@ func (p *Policy) IsAllowed(params *RequestParam, isOwner bool) bool {
 
 	for _, s := range p.Statements {
 		if s.Effect == Allow {
+			if restrictPublic && s.IsPublic() {
+				continue
+			}
 			if s.IsAllowed(params) {
 				log.LogDebugf("policy allow cause of %v, %v", s, params)
 				return true
@@ -194,6 +197,24 @@ func (p *Policy) IsAllowed(params *RequestParam, isOwner bool) bool {
 	return false
 }
 
+// identityPolicyCheck checks the identity policy of the user for the actions which are not restricted by
+// the volume permissions, so only an explicit deny of the policy rejects the request.
+func (o *ObjectNode) identityPolicyCheck(param *RequestParam) (allowed bool, err error) {
+	var userInfo *proto.UserInfo
+	if userInfo, err = o.getUserInfoByAccessKey(param.AccessKey()); err != nil {
+		// the request is not made by a user, which has no identity policy
+		if err == proto.ErrAccessKeyNotExists || err == proto.ErrUserNotExists {
+			return true, nil
+		}
+		return false, err
+	}
+	if userInfo.UserType == proto.UserTypeRoot || userInfo.UserType == proto.UserTypeAdmin || userInfo.Policy == nil {
+		return true, nil
+	}
+	var decision = userInfo.Policy.EvaluateIdentityPolicy(param.Action(), param.Bucket(), param.Object(), param.IAMContext())
+	return decision != proto.IAMDecisionDeny, nil
+}
+
 func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -214,15 +235,24 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 
 		param := ParseRequestParam(r)
 
-		if param.Bucket() == "" {
-			log.LogDebugf("policyCheck: no bucket specified: requestID(%v)", GetRequestID(r))
-			allowed = true
-			return
+		// The temporary credentials with a session policy can only do what the policy allows,
+		// whatever the user who the credentials are issued for is allowed to do.
+		if session := GetRequestSession(r); session != nil && session.Policy != nil {
+			if !session.Policy.IsAllowed(param, false, false) {
+				log.LogDebugf("policyCheck: session policy not allowed: requestID(%v) accessKey(%v) volume(%v) action(%v)",
+					GetRequestID(r), session.AccessKey, param.Bucket(), param.Action())
+				allowed = false
+				return
+			}
 		}
 
-		// A create bucket action do not need to check any user policy and volume policy.
-		if param.action == proto.OSSCreateBucketAction {
-			allowed = true
+		// The actions without a bucket and the create bucket action do not need to check the volume permissions
+		// and volume policy, but an explicit deny of the identity policy of the user still works.
+		if param.Bucket() == "" || param.action == proto.OSSCreateBucketAction {
+			if allowed, err = o.identityPolicyCheck(param); !allowed {
+				log.LogDebugf("policyCheck: identity policy not allowed: requestID(%v) accessKey(%v) volume(%v) action(%v) err(%v)",
+					GetRequestID(r), param.AccessKey(), param.Bucket(), param.Action(), err)
+			}
 			return
 		}
 
@@ -249,9 +279,11 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 				allowed = true
 				return
 			}
+			// The identity policy of the user is checked together with the volume permissions,
+			// an explicit deny of it works even if the user owns the volume.
 			var userPolicy = userInfo.Policy
 			isOwner = userPolicy.IsOwn(param.Bucket())
-			if !isOwner && !userPolicy.IsAuthorized(param.Bucket(), "", param.Action()) {
+			if !userPolicy.IsAllowed(param.Action(), param.Bucket(), param.Object(), param.IAMContext()) {
 				log.LogDebugf("policyCheck: user no permission: requestID(%v) userID(%v) accessKey(%v) volume(%v) action(%v)",
 					GetRequestID(r), userInfo.UserID, param.AccessKey(), param.Bucket(), param.Action())
 				allowed = false
@@ -273,6 +305,7 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 		var vol *Volume
 		var acl *AccessControlPolicy
 		var policy *Policy
+		var publicAccessBlock *PublicAccessBlockConfiguration
 		var loadBucketMeta = func(bucket string) (err error) {
 			if vol, err = o.getVol(bucket); err != nil {
 				return
@@ -283,6 +316,9 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 			if policy, err = vol.metaLoader.loadPolicy(); err != nil {
 				return
 			}
+			if publicAccessBlock, err = o.publicAccessBlock(vol); err != nil {
+				return
+			}
 			return
 		}
 		if err = loadBucketMeta(param.Bucket()); err != nil {
@@ -293,7 +329,7 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 		}
 
 		if vol != nil && policy != nil && !policy.IsEmpty() {
-			allowed = policy.IsAllowed(param, isOwner)
+			allowed = policy.IsAllowed(param, isOwner, publicAccessBlock.RestrictPublicBuckets)
 			if !allowed {
 				log.LogWarnf("policyCheck: bucket policy not allowed: requestID(%v) userID(%v) accessKey(%v) volume(%v) action(%v)",
 					GetRequestID(r), userInfo, param.AccessKey(), param.Bucket(), param.Action())
@@ -302,7 +338,7 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 		}
 
 		if vol != nil && acl != nil && !acl.IsAclEmpty() {
-			allowed = acl.IsAllowed(param, isOwner)
+			allowed = acl.IsAllowed(param, isOwner, publicAccessBlock.IgnorePublicAcls)
 			if !allowed {
 				log.LogWarnf("policyCheck: bucket ACL not allowed: requestID(%v) userID(%v) accessKey(%v) volume(%v) action(%v)",
 					GetRequestID(r), userInfo, param.AccessKey(), param.Bucket(), param.Action())
@@ -25,6 +25,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
 )
 
 //https://docs.aws.amazon.com/zh_cn/AmazonS3/latest/dev/example-bucket-policies.html
@@ -136,19 +138,29 @@ var ConditionKeyType = map[ConditionKey]ConditionTypeSet{
 }
 
 var ConditionFuncMap = map[ConditionType]ConditionFunc{
-	IpAddress:             IpAddressFunc,
-	NotIpAddress:          NotIpAddressFunc,
-	StringLike:            StringLikeFunc,
-	StringNotLike:         StringNotLikeFunc,
-	StringEquals:          StringEqualsFunc,
-	StringNotEquals:       StringNotEqualsFunc,
-	Bool:                  BoolFunc,
-	DateEquals:            DateEqualsFunc,
-	DateNotEquals:         DateNotEqualsFunc,
-	DateLessThan:          DateLessThanFunc,
-	DateLessThanEquals:    DateLessThanEqualsFunc,
-	DateGreaterThan:       DateGreaterThanFunc,
-	DateGreaterThanEquals: DateGreaterThanEqualsFunc,
+	IpAddress:                IpAddressFunc,
+	NotIpAddress:             NotIpAddressFunc,
+	StringLike:               StringLikeFunc,
+	StringNotLike:            StringNotLikeFunc,
+	StringEquals:             StringEqualsFunc,
+	StringNotEquals:          StringNotEqualsFunc,
+	Bool:                     BoolFunc,
+	DateEquals:               DateEqualsFunc,
+	DateNotEquals:            DateNotEqualsFunc,
+	DateLessThan:             DateLessThanFunc,
+	DateLessThanEquals:       DateLessThanEqualsFunc,
+	DateGreaterThan:          DateGreaterThanFunc,
+	DateGreaterThanEquals:    DateGreaterThanEqualsFunc,
+	NumericEquals:            NumericEqualsFunc,
+	NumericNotEquals:         NumericNotEqualsFunc,
+	NumericLessThan:          NumericLessThanFunc,
+	NumericLessThanEquals:    NumericLessThanEqualsFunc,
+	NumericGreaterThan:       NumericGreaterThanFunc,
+	NumericGreaterThanEquals: NumericGreaterThanEqualsFunc,
+	ArnEquals:                ArnEqualsFunc,
+	ArnNotEquals:             ArnNotEqualsFunc,
+	ArnLike:                  ArnLikeFunc,
+	ArnNotLike:               ArnNotLikeFunc,
 }
 
 type ConditionFunc func(p *RequestParam, values ConditionValues) bool
@@ -176,14 +188,15 @@ func getCondtionValues(r *http.Request) map[string][]string {
 		principalType = "Anonymous"
 	}
 	values := map[string][]string{
-		"SourceIp":      {getRequestIP(r)},
-		"UserAgent":     {r.UserAgent()},
-		"Referer":       {r.Referer()},
-		"CurrentTime":   {currentTime.Format(AMZTimeFormat)},
-		"EpochTime":     {fmt.Sprintf("%d", currentTime.Unix())},
-		"userid":        {accessKey},
-		"username":      {accessKey},
-		"PrincipalType": {principalType},
+		"SourceIp":        {getRequestIP(r)},
+		"UserAgent":       {r.UserAgent()},
+		"Referer":         {r.Referer()},
+		"CurrentTime":     {currentTime.Format(AMZTimeFormat)},
+		"EpochTime":       {fmt.Sprintf("%d", currentTime.Unix())},
+		"userid":          {accessKey},
+		"username":        {accessKey},
+		"PrincipalType":   {principalType},
+		"SecureTransport": {strconv.FormatBool(r.TLS != nil)},
 	}
 
 	for k, v := range r.Header {
@@ -205,6 +218,68 @@ func getCondtionValues(r *http.Request) map[string][]string {
 	return values
 }
 
+// Condition keys of S3 taken from the query or the headers of the request for identity policies.
+var iamS3ConditionKeys = []string{
+	"prefix",
+	"delimiter",
+	"max-keys",
+	"x-amz-acl",
+	"x-amz-copy-source",
+	"x-amz-metadata-directive",
+	"x-amz-server-side-encryption",
+	"x-amz-storage-class",
+}
+
+// IAMContext returns the condition keys of the request which identity policies are evaluated with.
+// Unlike the condition values of bucket policies, global condition keys such as "aws:SourceIp"
+// are never taken from the query or the headers, so that they can not be forged by the client.
+func (p *RequestParam) IAMContext() proto.IAMContext {
+	var currentTime = time.Now().UTC()
+	var principalType = "User"
+	if p.accessKey == "" {
+		principalType = "Anonymous"
+	}
+	var context = proto.IAMContext{
+		"aws:SourceIp":        {p.sourceIP},
+		"aws:CurrentTime":     {currentTime.Format(AMZTimeFormat)},
+		"aws:EpochTime":       {strconv.FormatInt(currentTime.Unix(), 10)},
+		"aws:SecureTransport": {strconv.FormatBool(p.r.TLS != nil)},
+		"aws:UserAgent":       {p.r.UserAgent()},
+		"aws:Referer":         {p.r.Referer()},
+		"aws:userid":          {p.accessKey},
+		"aws:username":        {p.accessKey},
+		"aws:PrincipalType":   {principalType},
+	}
+	var query = p.r.URL.Query()
+	for _, key := range iamS3ConditionKeys {
+		if values, exist := query[key]; exist {
+			context["s3:"+key] = values
+		} else if values := p.r.Header[http.CanonicalHeaderKey(key)]; len(values) > 0 {
+			context["s3:"+key] = values
+		}
+	}
+	return context
+}
+
+// matchProtoCondition tests the condition values of the request with the condition operator
+// implemented by the identity policy engine.
+func matchProtoCondition(operator ConditionType, p *RequestParam, values ConditionValues) bool {
+	for k, storeVals := range values {
+		var policyValues = make([]string, 0, len(storeVals.values))
+		for v := range storeVals.values {
+			policyValues = append(policyValues, v)
+		}
+		reqVals, present := p.conditionVars[http.CanonicalHeaderKey(TrimAwsPrefixKey(k))]
+		if !present {
+			reqVals, present = p.conditionVars[TrimAwsPrefixKey(k)]
+		}
+		if !proto.MatchIAMCondition(string(operator), policyValues, reqVals, present) {
+			return false
+		}
+	}
+	return true
+}
+
 func IpAddressFunc(p *RequestParam, value ConditionValues) bool {
 	key := TrimAwsPrefixKey(AwsSourceIp)
 	canonicalKey := http.CanonicalHeaderKey(key)
@@ -270,7 +345,24 @@ func StringEqualsFunc(reqParam *RequestParam, storeCondVals ConditionValues) boo
 }
 
 func StringNotEqualsFunc(p *RequestParam, values ConditionValues) bool {
-	return !StringNotEqualsFunc(p, values)
+	return !StringEqualsFunc(p, values)
+}
+
+// StringStartsWithFunc checks whether the request value starts with one of the condition values.
+// It is used by the "starts-with" conditions of the POST object policy documents.
+func StringStartsWithFunc(reqParam *RequestParam, storeCondVals ConditionValues) bool {
+	for k, storeVals := range storeCondVals {
+		canonicalKey := http.CanonicalHeaderKey(TrimAwsPrefixKey(k))
+		for _, rv := range reqParam.conditionVars[canonicalKey] {
+			for sv := range storeVals.values {
+				if strings.HasPrefix(rv, sv) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
 }
 
 // check statement conditions
@@ -387,49 +479,41 @@ func DateGreaterThanEqualsFunc(p *RequestParam, value ConditionValues) bool {
 }
 
 func NumericEqualsFunc(p *RequestParam, value ConditionValues) bool {
-	//TODO: numeric equal implements
-
-	return true
+	return matchProtoCondition(NumericEquals, p, value)
 }
 
 func NumericNotEqualsFunc(p *RequestParam, value ConditionValues) bool {
-	return !NumericEqualsFunc(p, value)
+	return matchProtoCondition(NumericNotEquals, p, value)
 }
 
 func NumericLessThanFunc(p *RequestParam, value ConditionValues) bool {
-	//TODO: numeric less than implements
-
-	return true
+	return matchProtoCondition(NumericLessThan, p, value)
 }
 
 func NumericLessThanEqualsFunc(p *RequestParam, value ConditionValues) bool {
-	return NumericLessThanFunc(p, value) || NumericEqualsFunc(p, value)
+	return matchProtoCondition(NumericLessThanEquals, p, value)
 }
 
 func NumericGreaterThanFunc(p *RequestParam, value ConditionValues) bool {
-	return !NumericLessThanEqualsFunc(p, value)
+	return matchProtoCondition(NumericGreaterThan, p, value)
 }
 
 func NumericGreaterThanEqualsFunc(p *RequestParam, value ConditionValues) bool {
-	return !NumericLessThanFunc(p, value)
+	return matchProtoCondition(NumericGreaterThanEquals, p, value)
 }
 
 func ArnEqualsFunc(p *RequestParam, value ConditionValues) bool {
-	//TODO: numeric equal implements
-
-	return true
+	return matchProtoCondition(ArnEquals, p, value)
 }
 
 func ArnNotEqualsFunc(p *RequestParam, value ConditionValues) bool {
-	return !ArnEqualsFunc(p, value)
+	return matchProtoCondition(ArnNotEquals, p, value)
 }
 
 func ArnLikeFunc(p *RequestParam, value ConditionValues) bool {
-	//TODO: numeric equal implements
-
-	return true
+	return matchProtoCondition(ArnLike, p, value)
 }
 
 func ArnNotLikeFunc(p *RequestParam, value ConditionValues) bool {
-	return !ArnLikeFunc(p, value)
+	return matchProtoCondition(ArnNotLike, p, value)
 }
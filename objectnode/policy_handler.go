@@ -19,6 +19,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/chubaofs/chubaofs/util/log"
 )
@@ -67,7 +68,9 @@ func (o *ObjectNode) putBucketPolicyHandler(w http.ResponseWriter, r *http.Reque
 		err error
 		ec  *ErrorCode
 	)
-	defer o.errorResponse(w, r, err, ec)
+	defer func() {
+		o.errorResponse(w, r, err, ec)
+	}()
 
 	var param = ParseRequestParam(r)
 	if param.Bucket() == "" {
@@ -99,6 +102,23 @@ func (o *ObjectNode) putBucketPolicyHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	var publicAccessBlock *PublicAccessBlockConfiguration
+	if publicAccessBlock, err = o.publicAccessBlock(vol); err != nil {
+		log.LogErrorf("putBucketPolicyHandler: load public access block fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		ec = InternalErrorCode(err)
+		return
+	}
+	if publicAccessBlock.BlockPublicPolicy {
+		var newPolicy = &Policy{}
+		if err = json.Unmarshal(bytes, newPolicy); err == nil && newPolicy.IsPublic() {
+			log.LogWarnf("putBucketPolicyHandler: public policy is blocked: requestID(%v) volume(%v)",
+				GetRequestID(r), param.Bucket())
+			ec = PublicAccessBlocked
+			return
+		}
+	}
+
 	var policy *Policy
 	policy, err = storeBucketPolicy(bytes, vol)
 	if err != nil {
@@ -138,3 +158,46 @@ func (o *ObjectNode) deleteBucketPolicyHandler(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusNoContent)
 	return
 }
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicyStatus.html
+func (o *ObjectNode) getBucketPolicyStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err error
+		ec  *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, ec)
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		ec = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketPolicyStatusHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		ec = NoSuchBucket
+		return
+	}
+	var policy *Policy
+	if policy, err = vol.metaLoader.loadPolicy(); err != nil {
+		ec = InternalErrorCode(err)
+		return
+	}
+
+	var status = &PolicyStatus{
+		IsPublic: policy != nil && policy.IsPublic(),
+	}
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(status); err != nil {
+		ec = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
@@ -14,6 +14,19 @@
 
 package objectnode
 
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/gorilla/mux"
+)
+
 /*
 
 https://docs.aws.amazon.com/zh_cn/AmazonS3/latest/dev/example-bucket-policies.html
@@ -66,3 +79,58 @@ https://docs.aws.amazon.com/zh_cn/IAM/latest/UserGuide/reference_policies_elemen
 }
 
 */
+
+func TestPolicyCheck_IdentityPolicyWithoutBucket(t *testing.T) {
+	deny, err := proto.ParseIAMPolicy([]byte(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Deny", "Action": ["s3:ListBuckets", "s3:CreateBucket"], "Resource": "*"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("parse identity policy fail: err(%v)", err)
+	}
+	var denied = &proto.UserPolicy{}
+	denied.SetIdentityPolicy(deny)
+	var o = &ObjectNode{
+		userStore: stubUserInfoStore{
+			"AK00000000DENIED": {UserID: "denied", AccessKey: "AK00000000DENIED", UserType: proto.UserTypeNormal, Policy: denied},
+			"AK0000000000USER": {UserID: "user", AccessKey: "AK0000000000USER", UserType: proto.UserTypeNormal, Policy: &proto.UserPolicy{}},
+		},
+	}
+
+	var router = mux.NewRouter()
+	var passed bool
+	var handler = o.policyCheck(func(w http.ResponseWriter, r *http.Request) {
+		passed = true
+	})
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSListBucketsAction)).Methods(http.MethodGet).Path("/").HandlerFunc(handler)
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSCreateBucketAction)).Methods(http.MethodPut).Path("/{bucket}").HandlerFunc(handler)
+	var serve = func(method, url, accessKey string) bool {
+		req := httptest.NewRequest(method, url, nil)
+		var signer = v4.NewSigner(credentials.NewStaticCredentials(accessKey, "secret", ""))
+		if _, err := signer.Sign(req, bytes.NewReader(nil), "s3", "cfs_default", time.Now()); err != nil {
+			t.Fatalf("sign request fail: err(%v)", err)
+		}
+		passed = false
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return passed
+	}
+
+	for _, c := range []struct {
+		method    string
+		url       string
+		accessKey string
+		expect    bool
+	}{
+		{http.MethodGet, "http://127.0.0.1/", "AK0000000000USER", true},
+		{http.MethodGet, "http://127.0.0.1/", "AK00000000DENIED", false},
+		{http.MethodPut, "http://127.0.0.1/bucket", "AK0000000000USER", true},
+		{http.MethodPut, "http://127.0.0.1/bucket", "AK00000000DENIED", false},
+	} {
+		if allowed := serve(c.method, c.url, c.accessKey); allowed != c.expect {
+			t.Fatalf("policy check mismatch: method(%v) url(%v) accessKey(%v) expect(%v) actual(%v)",
+				c.method, c.url, c.accessKey, c.expect, allowed)
+		}
+	}
+}
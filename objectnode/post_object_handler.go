@@ -0,0 +1,367 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+type PostResponse struct {
+	XMLName  xml.Name `xml:"PostResponse"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// Post object
+// The browser-based upload using HTML forms. The request is not signed in the header but in the
+// form fields, so it is authenticated here instead of the authentication middleware.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectPOST.html
+func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("postObjectHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var reader *multipart.Reader
+	if reader, err = r.MultipartReader(); err != nil {
+		log.LogErrorf("postObjectHandler: parse multipart form fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = MalformedPOSTRequest
+		return
+	}
+	var form http.Header
+	var file *multipart.Part
+	if form, file, errorCode = readPostForm(reader); errorCode != nil {
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var accessKey string
+	if accessKey, errorCode = o.authenticatePostForm(r, vol, form); errorCode != nil {
+		return
+	}
+	var policy *PostPolicy
+	if policy, err = ParsePostPolicy(form.Get(PostFormFieldPolicy)); err != nil {
+		log.LogErrorf("postObjectHandler: parse policy fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InvalidPolicyDocument
+		return
+	}
+	if policy.IsExpired() {
+		errorCode = PostPolicyExpired
+		return
+	}
+	if err = policy.Match(param.Bucket(), form); err != nil {
+		log.LogWarnf("postObjectHandler: policy not matched: requestID(%v) volume(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), vol.Name(), accessKey, err)
+		errorCode = PostPolicyNotMatched
+		return
+	}
+
+	// The upload is authorized as the user signing the policy.
+	SetRequestAccessKey(r, accessKey)
+	o.policyCheck(func(w http.ResponseWriter, r *http.Request) {
+		o.postObject(w, r, vol, form, file, policy)
+	})(w, r)
+}
+
+// readPostForm reads the form fields until the file, which must be the last field of the form.
+// The fields after the file are ignored. A field must not appear more than once, since the policy
+// conditions and the upload must see the same value.
+func readPostForm(reader *multipart.Reader) (form http.Header, file *multipart.Part, errorCode *ErrorCode) {
+	form = make(http.Header)
+	var size int
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, nil, IncorrectNumberOfFilesInPostRequest
+		}
+		if err != nil {
+			return nil, nil, MalformedPOSTRequest
+		}
+		var name = part.FormName()
+		if name == PostFormFieldFile {
+			return form, part, nil
+		}
+		value, err := ioutil.ReadAll(io.LimitReader(part, int64(MaxPostFormSize-size+1)))
+		_ = part.Close()
+		if err != nil {
+			return nil, nil, MalformedPOSTRequest
+		}
+		if size += len(value); size > MaxPostFormSize {
+			return nil, nil, MaxPostPreDataLengthExceeded
+		}
+		if len(name) > 0 {
+			if _, exist := form[textproto.CanonicalMIMEHeaderKey(name)]; exist {
+				return nil, nil, MalformedPOSTRequest
+			}
+			form.Add(name, string(value))
+		}
+	}
+}
+
+// authenticatePostForm checks the signature of the policy document in the form fields,
+// which is signed by either signature algorithm version 4 or version 2.
+func (o *ObjectNode) authenticatePostForm(r *http.Request, vol *Volume, form http.Header) (accessKey string, errorCode *ErrorCode) {
+	var policy = form.Get(PostFormFieldPolicy)
+	if len(policy) == 0 {
+		return "", AccessDenied
+	}
+
+	var signature string
+	var calculate func(secretKey string) string
+	if algorithm := form.Get(XAmzAlgorithm); len(algorithm) > 0 {
+		if algorithm != SignatureV4Algorithm {
+			return "", InvalidArgument
+		}
+		var req = new(signatureRequestV4)
+		if err := req.parseCredential(form.Get(XAmzCredential)); err != nil {
+			return "", InvalidArgument
+		}
+		accessKey, signature = req.Credential.AccessKey, form.Get(XAmzSignature)
+		calculate = func(secretKey string) string {
+			signingKey := buildSigningKey(SCHEME, secretKey, req.Credential.Date, req.Credential.Region, SERVICE, TERMINATOR)
+			return hex.EncodeToString(sign(policy, signingKey))
+		}
+	} else {
+		accessKey, signature = form.Get(PostFormFieldAccessKeyId), form.Get(PostFormFieldSignature)
+		calculate = func(secretKey string) string {
+			hm := hmac.New(sha1.New, []byte(secretKey))
+			hm.Write([]byte(policy))
+			return base64.StdEncoding.EncodeToString(hm.Sum(nil))
+		}
+	}
+	if len(accessKey) == 0 || len(signature) == 0 {
+		return "", AccessDenied
+	}
+
+	var secretKey string
+	if userInfo, err := o.getUserInfoByAccessKey(accessKey); err == nil {
+		secretKey = userInfo.SecretKey
+	} else if err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists {
+		// Compatible with the access key and secret key bound in the volume information.
+		var ak, sk = vol.OSSSecure()
+		if ak != accessKey {
+			return "", AccessDenied
+		}
+		secretKey = sk
+	} else {
+		log.LogErrorf("authenticatePostForm: get secretKey from master fail: requestID(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), accessKey, err)
+		return "", InternalErrorCode(err)
+	}
+
+	if newSignature := calculate(secretKey); signature != newSignature {
+		log.LogDebugf("authenticatePostForm: invalid signature: requestID(%v) client(%v) server(%v)",
+			GetRequestID(r), signature, newSignature)
+		return "", SignatureDoesNotMatch
+	}
+	return accessKey, nil
+}
+
+func (o *ObjectNode) postObject(w http.ResponseWriter, r *http.Request, vol *Volume, form http.Header, file *multipart.Part, policy *PostPolicy) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	var key = form.Get(PostFormFieldKey)
+	if len(key) == 0 {
+		errorCode = InvalidKey
+		return
+	}
+	key = strings.Replace(key, PostFileNameVariable, file.FileName(), -1)
+
+	if errorCode = checkServerSideEncryption(form, vol); errorCode != nil {
+		return
+	}
+	var sseKey *SSECustomerKey
+	if sseKey, errorCode = ParseSSECustomerKey(form); errorCode != nil {
+		return
+	}
+
+	// The tagging of the form is in the XML format, which is different from the 'x-amz-tagging' header.
+	var tagging *Tagging
+	if taggingXML := form.Get(PostFormFieldTagging); len(taggingXML) > 0 {
+		tagging = new(Tagging)
+		if err = UnmarshalXMLEntity([]byte(taggingXML), tagging); err != nil {
+			errorCode = InvalidArgument
+			return
+		}
+		var validateRes bool
+		if validateRes, errorCode = tagging.Validate(); !validateRes {
+			return
+		}
+	}
+
+	var objectLock map[string]string
+	if objectLock, errorCode = ParseObjectLockHeaders(form); errorCode != nil {
+		return
+	}
+
+	cacheControl := form.Get(HeaderNameCacheControl)
+	if len(cacheControl) > 0 && !ValidateCacheControl(cacheControl) {
+		errorCode = InvalidCacheArgument
+		return
+	}
+	expires := form.Get(HeaderNameExpires)
+	if len(expires) > 0 && !ValidateCacheExpires(expires) {
+		errorCode = InvalidCacheArgument
+		return
+	}
+
+	// Audit file write
+	log.LogInfof("Audit: post object: requestID(%v) remote(%v) volume(%v) path(%v) accessKey(%v)",
+		GetRequestID(r), getRequestIP(r), vol.Name(), key, param.AccessKey())
+
+	var opt = &PutFileOption{
+		MIMEType:     form.Get(HeaderNameContentType),
+		Disposition:  form.Get(HeaderNameContentDisposition),
+		Tagging:      tagging,
+		Metadata:     ParseUserDefinedMetadata(form),
+		CacheControl: cacheControl,
+		Expires:      expires,
+		SSECustomer:  sseKey,
+		ObjectLock:   objectLock,
+	}
+	var reader = &postObjectReader{reader: file, min: policy.MinContentLength, max: policy.MaxContentLength}
+	var fsFileInfo *FSFileInfo
+	fsFileInfo, err = vol.PutObject(key, reader, opt)
+	switch err {
+	case nil:
+	case errPostObjectTooLarge:
+		errorCode = EntityTooLarge
+		return
+	case errPostObjectTooSmall, io.ErrUnexpectedEOF:
+		errorCode = EntityTooSmall
+		return
+	case syscall.EINVAL:
+		errorCode = ObjectModeConflict
+		return
+	case syscall.EPERM:
+		errorCode = ObjectLocked
+		return
+	case syscall.EDQUOT:
+		errorCode = QuotaExceeded
+		return
+	default:
+		log.LogErrorf("postObjectHandler: put object fail: requestId(%v) volume(%v) path(%v) remote(%v) err(%v)",
+			GetRequestID(r), vol.Name(), key, getRequestIP(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	o.notifyEvent(r, param, vol, EventObjectCreatedPost, newNotificationObject(key, fsFileInfo))
+
+	var etag = wrapUnescapedQuot(fsFileInfo.ETag)
+	var location = postObjectLocation(r, key)
+	w.Header()[HeaderNameETag] = []string{etag}
+	w.Header()[HeaderNameLocation] = []string{location}
+	setServerSideEncryptionHeaders(w, vol, fsFileInfo.SSECustomerKeyMD5)
+	if len(fsFileInfo.VersionId) > 0 {
+		w.Header()[HeaderNameXAmzVersionId] = []string{fsFileInfo.VersionId}
+	}
+
+	var redirect = form.Get(PostFormFieldSuccessActionRedirect)
+	if len(redirect) == 0 {
+		redirect = form.Get(PostFormFieldRedirect)
+	}
+	if redirectURL, parseErr := url.Parse(redirect); len(redirect) > 0 && parseErr == nil {
+		query := redirectURL.Query()
+		query.Set(PostFormFieldBucket, vol.Name())
+		query.Set(PostFormFieldKey, key)
+		query.Set("etag", etag)
+		redirectURL.RawQuery = query.Encode()
+		http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
+		return
+	}
+
+	switch status, _ := strconv.Atoi(form.Get(PostFormFieldSuccessActionStatus)); status {
+	case http.StatusOK:
+		w.Header()[HeaderNameContentLength] = []string{"0"}
+		w.WriteHeader(http.StatusOK)
+	case http.StatusCreated:
+		var bytes []byte
+		if bytes, err = MarshalXMLEntity(&PostResponse{
+			Location: location,
+			Bucket:   vol.Name(),
+			Key:      key,
+			ETag:     etag,
+		}); err != nil {
+			log.LogErrorf("postObjectHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+			errorCode = InternalErrorCode(err)
+			return
+		}
+		w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+		w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+		w.WriteHeader(http.StatusCreated)
+		if _, err = w.Write(bytes); err != nil {
+			log.LogErrorf("postObjectHandler: write response body fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		}
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func postObjectLocation(r *http.Request, key string) string {
+	var location = url.URL{
+		Scheme: websiteRequestProtocol(r),
+		Host:   r.Host,
+		Path:   strings.TrimSuffix(r.URL.Path, pathSep) + pathSep + key,
+	}
+	return location.String()
+}
@@ -0,0 +1,247 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+// Policy document of the browser-based uploads using POST.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html
+
+const (
+	PostPolicyConditionEq                 = "eq"
+	PostPolicyConditionStartsWith         = "starts-with"
+	PostPolicyConditionContentLengthRange = "content-length-range"
+
+	PostFormFieldFile                  = "file"
+	PostFormFieldKey                   = "key"
+	PostFormFieldPolicy                = "policy"
+	PostFormFieldSignature             = "signature"
+	PostFormFieldAccessKeyId           = "AWSAccessKeyId"
+	PostFormFieldTagging               = "tagging"
+	PostFormFieldSuccessActionRedirect = "success_action_redirect"
+	PostFormFieldRedirect              = "redirect"
+	PostFormFieldSuccessActionStatus   = "success_action_status"
+	PostFormFieldIgnorePrefix          = "x-ignore-"
+	PostFormFieldBucket                = "bucket"
+
+	PostFileNameVariable = "${filename}"
+
+	// The total size of the form fields preceding the file.
+	MaxPostFormSize = 20 * 1024
+)
+
+var postPolicyConditionFuncs = map[string]ConditionFunc{
+	PostPolicyConditionEq:         StringEqualsFunc,
+	PostPolicyConditionStartsWith: StringStartsWithFunc,
+}
+
+// The form fields which are not required to be covered by the conditions of the policy document.
+var postPolicyIgnoredFields = []string{
+	PostFormFieldFile,
+	PostFormFieldPolicy,
+	PostFormFieldSignature,
+	PostFormFieldAccessKeyId,
+	XAmzSignature,
+}
+
+var (
+	errPostObjectTooLarge = errors.New("post object exceeds the content length range")
+	errPostObjectTooSmall = errors.New("post object is smaller than the content length range")
+)
+
+type PostPolicyCondition struct {
+	Operator string
+	Field    string // name of the form field without the leading '$'
+	Value    string
+}
+
+type PostPolicy struct {
+	Expiration time.Time
+	Conditions []PostPolicyCondition
+	// The range of the uploaded file size, which is checked while the file is being written.
+	MinContentLength int64
+	MaxContentLength int64
+}
+
+// ParsePostPolicy decodes the base64 encoded policy document sent in the "policy" form field.
+// Each condition is either an object of exact matches or an array of the form
+// [operator, "$field", value] or ["content-length-range", min, max].
+func ParsePostPolicy(encoded string) (*PostPolicy, error) {
+	var raw []byte
+	var err error
+	if raw, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+		return nil, errors.NewErrorf("decode policy fail: %v", err)
+	}
+	var document = struct {
+		Expiration string        `json:"expiration"`
+		Conditions []interface{} `json:"conditions"`
+	}{}
+	var decoder = json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err = decoder.Decode(&document); err != nil {
+		return nil, errors.NewErrorf("unmarshal policy fail: %v", err)
+	}
+
+	var policy = &PostPolicy{MinContentLength: 0, MaxContentLength: -1}
+	if policy.Expiration, err = time.Parse(time.RFC3339, document.Expiration); err != nil {
+		return nil, errors.NewErrorf("invalid policy expiration: %v", document.Expiration)
+	}
+	for _, condition := range document.Conditions {
+		switch typed := condition.(type) {
+		case map[string]interface{}:
+			for field, value := range typed {
+				str, is := value.(string)
+				if !is {
+					return nil, errors.NewErrorf("invalid policy condition value: %v", value)
+				}
+				policy.Conditions = append(policy.Conditions, PostPolicyCondition{
+					Operator: PostPolicyConditionEq,
+					Field:    field,
+					Value:    str,
+				})
+			}
+		case []interface{}:
+			if len(typed) != 3 {
+				return nil, errors.NewErrorf("invalid policy condition: %v", typed)
+			}
+			operator, _ := typed[0].(string)
+			operator = strings.ToLower(operator)
+			if operator == PostPolicyConditionContentLengthRange {
+				var min, max int64
+				if min, err = parsePostPolicyNumber(typed[1]); err != nil {
+					return nil, err
+				}
+				if max, err = parsePostPolicyNumber(typed[2]); err != nil {
+					return nil, err
+				}
+				if min < 0 || min > max {
+					return nil, errors.NewErrorf("invalid content length range: %v-%v", min, max)
+				}
+				policy.MinContentLength, policy.MaxContentLength = min, max
+				continue
+			}
+			if _, supported := postPolicyConditionFuncs[operator]; !supported {
+				return nil, errors.NewErrorf("unsupported policy condition operator: %v", typed[0])
+			}
+			field, _ := typed[1].(string)
+			value, is := typed[2].(string)
+			if !strings.HasPrefix(field, "$") || !is {
+				return nil, errors.NewErrorf("invalid policy condition: %v", typed)
+			}
+			policy.Conditions = append(policy.Conditions, PostPolicyCondition{
+				Operator: operator,
+				Field:    strings.TrimPrefix(field, "$"),
+				Value:    value,
+			})
+		default:
+			return nil, errors.NewErrorf("invalid policy condition: %v", condition)
+		}
+	}
+	return policy, nil
+}
+
+func parsePostPolicyNumber(value interface{}) (int64, error) {
+	var str string
+	switch typed := value.(type) {
+	case json.Number:
+		str = typed.String()
+	case string:
+		str = typed
+	}
+	number, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, errors.NewErrorf("invalid content length: %v", value)
+	}
+	return number, nil
+}
+
+func (p *PostPolicy) IsExpired() bool {
+	return p.Expiration.Before(time.Now())
+}
+
+// Match checks the form fields against the conditions of the policy document. The conditions are
+// evaluated by the condition functions of the bucket policy with the form fields as the condition
+// variables, and every form field except the ignored ones must be covered by a condition.
+func (p *PostPolicy) Match(bucket string, form http.Header) error {
+	var vars = make(map[string][]string, len(form)+1)
+	for name, values := range form {
+		vars[name] = values
+	}
+	vars[http.CanonicalHeaderKey(PostFormFieldBucket)] = []string{bucket}
+	var param = &RequestParam{bucket: bucket, conditionVars: vars}
+
+	var covered = make(map[string]bool)
+	for _, condition := range p.Conditions {
+		var values = ConditionValues{
+			condition.Field: StringSet{values: map[string]null{condition.Value: void}},
+		}
+		if !postPolicyConditionFuncs[condition.Operator](param, values) {
+			return errors.NewErrorf("condition failed: [%v, $%v, %v]", condition.Operator, condition.Field, condition.Value)
+		}
+		covered[http.CanonicalHeaderKey(condition.Field)] = true
+	}
+
+	for name := range form {
+		if covered[name] || isPostPolicyIgnoredField(name) {
+			continue
+		}
+		return errors.NewErrorf("extra input fields: %v", name)
+	}
+	return nil
+}
+
+func isPostPolicyIgnoredField(name string) bool {
+	if strings.HasPrefix(strings.ToLower(name), PostFormFieldIgnorePrefix) {
+		return true
+	}
+	for _, ignored := range postPolicyIgnoredFields {
+		if http.CanonicalHeaderKey(ignored) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// postObjectReader checks the size of the uploaded file against the content length range of
+// the policy document, so the file is not created if the size is out of the range.
+type postObjectReader struct {
+	reader io.Reader
+	min    int64
+	max    int64
+	size   int64
+}
+
+func (r *postObjectReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.size += int64(n)
+	if r.max >= 0 && r.size > r.max {
+		return n, errPostObjectTooLarge
+	}
+	if err == io.EOF && r.size < r.min {
+		return n, errPostObjectTooSmall
+	}
+	return
+}
@@ -0,0 +1,187 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func encodePostPolicy(document string) string {
+	return base64.StdEncoding.EncodeToString([]byte(document))
+}
+
+func TestPostPolicy_Parse(t *testing.T) {
+	var document = `{"expiration": "2030-01-01T00:00:00.000Z",
+		"conditions": [
+			{"bucket": "photos"},
+			["starts-with", "$key", "user/eric/"],
+			{"success_action_status": "201"},
+			["content-length-range", 1, "1048576"]
+		]}`
+	policy, err := ParsePostPolicy(encodePostPolicy(document))
+	if err != nil {
+		t.Fatalf("parse policy fail: err(%v)", err)
+	}
+	if policy.IsExpired() {
+		t.Fatalf("policy expired: expiration(%v)", policy.Expiration)
+	}
+	if len(policy.Conditions) != 3 {
+		t.Fatalf("condition count mismatch: expect(3) actual(%v)", len(policy.Conditions))
+	}
+	if policy.MinContentLength != 1 || policy.MaxContentLength != 1048576 {
+		t.Fatalf("content length range mismatch: min(%v) max(%v)", policy.MinContentLength, policy.MaxContentLength)
+	}
+	if c := policy.Conditions[1]; c.Operator != PostPolicyConditionStartsWith || c.Field != "key" || c.Value != "user/eric/" {
+		t.Fatalf("condition mismatch: %v", c)
+	}
+
+	var invalids = []string{
+		`not json`,
+		`{"expiration": "tomorrow", "conditions": []}`,
+		`{"expiration": "2030-01-01T00:00:00Z", "conditions": [["ends-with", "$key", "a"]]}`,
+		`{"expiration": "2030-01-01T00:00:00Z", "conditions": [["eq", "key", "a"]]}`,
+		`{"expiration": "2030-01-01T00:00:00Z", "conditions": [["content-length-range", 10, 1]]}`,
+		`{"expiration": "2030-01-01T00:00:00Z", "conditions": [{"key": 1}]}`,
+	}
+	for _, invalid := range invalids {
+		if _, err = ParsePostPolicy(encodePostPolicy(invalid)); err == nil {
+			t.Fatalf("invalid policy parsed: %v", invalid)
+		}
+	}
+}
+
+func TestPostPolicy_Match(t *testing.T) {
+	var document = `{"expiration": "2030-01-01T00:00:00Z",
+		"conditions": [
+			{"bucket": "photos"},
+			["starts-with", "$key", "user/eric/"],
+			["eq", "$success_action_status", "201"],
+			["starts-with", "$Content-Type", "image/"],
+			["starts-with", "$x-amz-meta-tag", ""]
+		]}`
+	policy, err := ParsePostPolicy(encodePostPolicy(document))
+	if err != nil {
+		t.Fatalf("parse policy fail: err(%v)", err)
+	}
+	var newForm = func() http.Header {
+		var form = make(http.Header)
+		form.Set("key", "user/eric/${filename}")
+		form.Set("success_action_status", "201")
+		form.Set("content-type", "image/jpeg")
+		form.Set("x-amz-meta-tag", "anything")
+		form.Set("policy", "ignored")
+		form.Set("AWSAccessKeyId", "ignored")
+		form.Set("signature", "ignored")
+		form.Set("x-ignore-comment", "ignored")
+		return form
+	}
+	if err = policy.Match("photos", newForm()); err != nil {
+		t.Fatalf("form not matched: err(%v)", err)
+	}
+	if err = policy.Match("videos", newForm()); err == nil {
+		t.Fatalf("form of another bucket matched")
+	}
+
+	var mismatches = []func(form http.Header){
+		func(form http.Header) { form.Set("key", "user/bob/a.jpg") },
+		func(form http.Header) { form.Set("success_action_status", "200") },
+		func(form http.Header) { form.Set("content-type", "text/plain") },
+		func(form http.Header) { form.Del("x-amz-meta-tag") },
+		func(form http.Header) { form.Set("acl", "public-read") },
+	}
+	for i, mismatch := range mismatches {
+		var form = newForm()
+		mismatch(form)
+		if err = policy.Match("photos", form); err == nil {
+			t.Fatalf("mismatched form %v matched", i)
+		}
+	}
+}
+
+func TestPostPolicy_Reader(t *testing.T) {
+	var cases = []struct {
+		size     int
+		min, max int64
+		err      error
+	}{
+		{size: 10, min: 0, max: -1},
+		{size: 10, min: 10, max: 10},
+		{size: 10, min: 11, max: 20, err: errPostObjectTooSmall},
+		{size: 10, min: 0, max: 9, err: errPostObjectTooLarge},
+	}
+	for i, c := range cases {
+		var reader = &postObjectReader{reader: strings.NewReader(strings.Repeat("a", c.size)), min: c.min, max: c.max}
+		if _, err := ioutil.ReadAll(reader); err != c.err {
+			t.Fatalf("case %v: error mismatch: expect(%v) actual(%v)", i, c.err, err)
+		}
+	}
+}
+
+func TestPostPolicy_ReadForm(t *testing.T) {
+	var body = new(bytes.Buffer)
+	var writer = multipart.NewWriter(body)
+	_ = writer.WriteField("key", "uploads/${filename}")
+	_ = writer.WriteField("Content-Type", "text/plain")
+	file, _ := writer.CreateFormFile("file", "hello.txt")
+	_, _ = file.Write([]byte("hello"))
+	_ = writer.WriteField("after", "ignored")
+	_ = writer.Close()
+
+	form, part, errorCode := readPostForm(multipart.NewReader(bytes.NewReader(body.Bytes()), writer.Boundary()))
+	if errorCode != nil {
+		t.Fatalf("read form fail: err(%v)", errorCode)
+	}
+	if form.Get("key") != "uploads/${filename}" || form.Get("content-type") != "text/plain" || len(form) != 2 {
+		t.Fatalf("form fields mismatch: %v", form)
+	}
+	if part.FileName() != "hello.txt" {
+		t.Fatalf("file name mismatch: %v", part.FileName())
+	}
+	if data, _ := ioutil.ReadAll(part); string(data) != "hello" {
+		t.Fatalf("file content mismatch: %v", string(data))
+	}
+
+	body.Reset()
+	writer = multipart.NewWriter(body)
+	_ = writer.WriteField("key", "a")
+	_ = writer.Close()
+	if _, _, errorCode = readPostForm(multipart.NewReader(bytes.NewReader(body.Bytes()), writer.Boundary())); errorCode != IncorrectNumberOfFilesInPostRequest {
+		t.Fatalf("form without file read: err(%v)", errorCode)
+	}
+
+	body.Reset()
+	writer = multipart.NewWriter(body)
+	_ = writer.WriteField("x-ignore-padding", strings.Repeat("a", MaxPostFormSize+1))
+	_ = writer.Close()
+	if _, _, errorCode = readPostForm(multipart.NewReader(bytes.NewReader(body.Bytes()), writer.Boundary())); errorCode != MaxPostPreDataLengthExceeded {
+		t.Fatalf("oversize form read: err(%v)", errorCode)
+	}
+
+	body.Reset()
+	writer = multipart.NewWriter(body)
+	_ = writer.WriteField("key", "user/eric/a")
+	_ = writer.WriteField("Key", "other/b")
+	file, _ = writer.CreateFormFile("file", "hello.txt")
+	_ = writer.Close()
+	if _, _, errorCode = readPostForm(multipart.NewReader(bytes.NewReader(body.Bytes()), writer.Boundary())); errorCode != MalformedPOSTRequest {
+		t.Fatalf("duplicate form field read: err(%v)", errorCode)
+	}
+}
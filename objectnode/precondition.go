@@ -0,0 +1,89 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Conditional requests evaluated against the ETag and the last modified time of the object.
+// Reference: https://tools.ietf.org/html/rfc7232
+
+// matchETag reports whether one of the entity tags in the list of the header matches the ETag of the object.
+// The weak entity tags only match in the weak comparison.
+func matchETag(list, etag string, weak bool) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[len("W/"):]
+		}
+		if strings.Trim(tag, "\"") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions evaluates the conditional headers of GET and HEAD requests in the order of
+// the section 6 of RFC 7232. If-Unmodified-Since is ignored if If-Match is present, and so is
+// If-Modified-Since if If-None-Match is present. Invalid dates are ignored.
+func checkPreconditions(header http.Header, etag string, modTime time.Time) *ErrorCode {
+	// The HTTP dates are in the precision of seconds.
+	var lastModified = modTime.Truncate(time.Second)
+
+	if match := header.Get(HeaderNameIfMatch); len(match) > 0 {
+		if !matchETag(match, etag, false) {
+			return PreconditionFailed
+		}
+	} else if unmodified, err := http.ParseTime(header.Get(HeaderNameIfUnmodifiedSince)); err == nil {
+		if lastModified.After(unmodified) {
+			return PreconditionFailed
+		}
+	}
+
+	if noneMatch := header.Get(HeaderNameIfNoneMatch); len(noneMatch) > 0 {
+		if matchETag(noneMatch, etag, true) {
+			return NotModified
+		}
+	} else if modified, err := http.ParseTime(header.Get(HeaderNameIfModifiedSince)); err == nil {
+		if !lastModified.After(modified) {
+			return NotModified
+		}
+	}
+	return nil
+}
+
+// checkIfRange reports whether the Range header should be served. The If-Range header is either
+// an entity tag or a HTTP date, which must exactly match the current object.
+// Reference: https://tools.ietf.org/html/rfc7233#section-3.2
+func checkIfRange(header http.Header, etag string, modTime time.Time) bool {
+	var ifRange = strings.TrimSpace(header.Get(HeaderNameIfRange))
+	if len(ifRange) == 0 {
+		return true
+	}
+	if strings.HasPrefix(ifRange, "\"") || strings.HasPrefix(ifRange, "W/") {
+		return matchETag(ifRange, etag, false)
+	}
+	date, err := http.ParseTime(ifRange)
+	return err == nil && modTime.Truncate(time.Second).Equal(date)
+}
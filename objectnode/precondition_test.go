@@ -0,0 +1,82 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPrecondition_Check(t *testing.T) {
+	var etag = "d41d8cd98f00b204e9800998ecf8427e"
+	var modTime = time.Date(2020, 5, 12, 7, 32, 31, 500, time.UTC)
+	var before = modTime.Add(-time.Hour).Format(http.TimeFormat)
+	var after = modTime.Add(time.Hour).Format(http.TimeFormat)
+	var exact = modTime.Format(http.TimeFormat)
+
+	var cases = []struct {
+		header    map[string]string
+		errorCode *ErrorCode
+	}{
+		{header: map[string]string{}},
+		{header: map[string]string{HeaderNameIfMatch: `"` + etag + `"`}},
+		{header: map[string]string{HeaderNameIfMatch: `"other", "` + etag + `"`}},
+		{header: map[string]string{HeaderNameIfMatch: `*`}},
+		{header: map[string]string{HeaderNameIfMatch: `"other"`}, errorCode: PreconditionFailed},
+		{header: map[string]string{HeaderNameIfMatch: `W/"` + etag + `"`}, errorCode: PreconditionFailed},
+		{header: map[string]string{HeaderNameIfUnmodifiedSince: before}, errorCode: PreconditionFailed},
+		{header: map[string]string{HeaderNameIfUnmodifiedSince: exact}},
+		{header: map[string]string{HeaderNameIfMatch: `"` + etag + `"`, HeaderNameIfUnmodifiedSince: before}},
+		{header: map[string]string{HeaderNameIfUnmodifiedSince: "invalid date"}},
+		{header: map[string]string{HeaderNameIfNoneMatch: `"` + etag + `"`}, errorCode: NotModified},
+		{header: map[string]string{HeaderNameIfNoneMatch: `W/"` + etag + `"`}, errorCode: NotModified},
+		{header: map[string]string{HeaderNameIfNoneMatch: `"other"`}},
+		{header: map[string]string{HeaderNameIfModifiedSince: exact}, errorCode: NotModified},
+		{header: map[string]string{HeaderNameIfModifiedSince: after}, errorCode: NotModified},
+		{header: map[string]string{HeaderNameIfModifiedSince: before}},
+		{header: map[string]string{HeaderNameIfNoneMatch: `"other"`, HeaderNameIfModifiedSince: after}},
+		{header: map[string]string{HeaderNameIfMatch: `"other"`, HeaderNameIfNoneMatch: `"` + etag + `"`}, errorCode: PreconditionFailed},
+	}
+	for i, c := range cases {
+		var header = make(http.Header)
+		for name, value := range c.header {
+			header.Set(name, value)
+		}
+		if errorCode := checkPreconditions(header, etag, modTime); errorCode != c.errorCode {
+			t.Fatalf("case %v: result mismatch: header(%v) expect(%v) actual(%v)", i, c.header, c.errorCode, errorCode)
+		}
+	}
+}
+
+func TestPrecondition_IfRange(t *testing.T) {
+	var etag = "d41d8cd98f00b204e9800998ecf8427e"
+	var modTime = time.Date(2020, 5, 12, 7, 32, 31, 500, time.UTC)
+	var cases = map[string]bool{
+		"":                              true,
+		`"` + etag + `"`:                true,
+		`W/"` + etag + `"`:              false,
+		`"other"`:                       false,
+		modTime.Format(http.TimeFormat): true,
+		modTime.Add(time.Hour).Format(http.TimeFormat): false,
+	}
+	for ifRange, expect := range cases {
+		var header = make(http.Header)
+		header.Set(HeaderNameIfRange, ifRange)
+		if actual := checkIfRange(header, etag, modTime); actual != expect {
+			t.Fatalf("If-Range(%v) result mismatch: expect(%v) actual(%v)", ifRange, expect, actual)
+		}
+	}
+}
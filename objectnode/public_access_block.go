@@ -0,0 +1,177 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// PublicAccessBlockConfiguration prevents a bucket from being exposed to anonymous users by its ACL
+// or bucket policy.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PublicAccessBlockConfiguration.html
+type PublicAccessBlockConfiguration struct {
+	XMLName               xml.Name `xml:"PublicAccessBlockConfiguration" json:"-"`
+	BlockPublicAcls       bool     `xml:"BlockPublicAcls" json:"block_acls"`
+	IgnorePublicAcls      bool     `xml:"IgnorePublicAcls" json:"ignore_acls"`
+	BlockPublicPolicy     bool     `xml:"BlockPublicPolicy" json:"block_policy"`
+	RestrictPublicBuckets bool     `xml:"RestrictPublicBuckets" json:"restrict_buckets"`
+}
+
+// enforcedPublicAccessBlock is the effective configuration of all buckets when the public access
+// block is enforced by the ObjectNode.
+var enforcedPublicAccessBlock = &PublicAccessBlockConfiguration{
+	BlockPublicAcls:       true,
+	IgnorePublicAcls:      true,
+	BlockPublicPolicy:     true,
+	RestrictPublicBuckets: true,
+}
+
+// PolicyStatus is the result of GetBucketPolicyStatus.
+type PolicyStatus struct {
+	XMLName  xml.Name `xml:"PolicyStatus"`
+	IsPublic bool     `xml:"IsPublic"`
+}
+
+var (
+	// Condition keys which restrict a statement to fixed principals or networks, so that the
+	// statement does not grant access to everyone.
+	publicRestrictConditionKeys = []string{
+		AwsSourceIp, AwsVpcSourceIp, AwsSourceVpc, AwsSourceVpce, AwsSourceArn, AwsSourceAccout,
+		AwsPrincipalAccount, AwsPrincipalArn, AwsPrincipalOrgID, AwsUserId, AwsUserName,
+	}
+	// Condition operators which match the restricting condition keys against fixed values.
+	publicRestrictConditionTypes = []ConditionType{
+		IpAddress, StringEquals, StringLike, ArnEquals, ArnLike,
+	}
+	// Network ranges which cover all the addresses.
+	publicNetworks = []string{"0.0.0.0/0", "::/0"}
+)
+
+func parsePublicAccessBlockConfig(bytes []byte) (config *PublicAccessBlockConfiguration, err error) {
+	config = &PublicAccessBlockConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func storeBucketPublicAccessBlock(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSPublicAccess, bytes); err != nil {
+		return
+	}
+	return nil
+}
+
+func deleteBucketPublicAccessBlock(vol *Volume) (err error) {
+	if err = vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSPublicAccess); err != nil {
+		return err
+	}
+	return nil
+}
+
+// publicAccessBlock returns the effective public access block configuration of the bucket.
+// The returned configuration is never nil.
+func (o *ObjectNode) publicAccessBlock(vol *Volume) (config *PublicAccessBlockConfiguration, err error) {
+	if o.enforcePublicAccessBlock {
+		return enforcedPublicAccessBlock, nil
+	}
+	if config, err = vol.metaLoader.loadPublicAccessBlock(); err != nil {
+		return
+	}
+	if config == nil {
+		config = &PublicAccessBlockConfiguration{}
+	}
+	return
+}
+
+// IsPublic returns whether the grant allows access to everyone.
+func (g *Grant) IsPublic() bool {
+	return g.Grantee.URI == aclRoleURIMap[allUsersRole]
+}
+
+// IsPublic returns whether any grant of the ACL allows access to everyone.
+func (acp *AccessControlPolicy) IsPublic() bool {
+	for i := range acp.Acl.Grants {
+		if acp.Acl.Grants[i].IsPublic() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublic returns whether the statement allows access to everyone, that is an allow statement
+// with a wildcard principal and without any condition restricting it to fixed principals or networks.
+func (s Statement) IsPublic() bool {
+	if s.Effect != Allow {
+		return false
+	}
+	if len(s.Principal) != 0 {
+		var wildcard bool
+		for _, principal := range s.Principal {
+			if principal.Contains("*") {
+				wildcard = true
+				break
+			}
+		}
+		if !wildcard {
+			return false
+		}
+	}
+	for _, conditionType := range publicRestrictConditionTypes {
+		for key, values := range s.Condition[conditionType] {
+			if isPublicRestrictConditionKey(key) && !isPublicConditionValues(values) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsPublic returns whether any statement of the policy allows access to everyone.
+func (p *Policy) IsPublic() bool {
+	for _, s := range p.Statements {
+		if s.IsPublic() {
+			return true
+		}
+	}
+	return false
+}
+
+func isPublicRestrictConditionKey(key string) bool {
+	for _, restrictKey := range publicRestrictConditionKeys {
+		if strings.EqualFold(key, restrictKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPublicConditionValues(values StringSet) bool {
+	if values.Empty() {
+		return true
+	}
+	for value := range values.values {
+		if strings.Contains(value, "*") {
+			return true
+		}
+		for _, network := range publicNetworks {
+			if value == network {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get public access block
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetPublicAccessBlock.html
+func (o *ObjectNode) getPublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getPublicAccessBlockHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var config *PublicAccessBlockConfiguration
+	if config, err = vol.metaLoader.loadPublicAccessBlock(); err != nil {
+		log.LogErrorf("getPublicAccessBlockHandler: load public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if config == nil {
+		errorCode = NoSuchPublicAccessBlock
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(config); err != nil {
+		log.LogErrorf("getPublicAccessBlockHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put public access block
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutPublicAccessBlock.html
+func (o *ObjectNode) putPublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putPublicAccessBlockHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var config *PublicAccessBlockConfiguration
+	if config, err = parsePublicAccessBlockConfig(bytes); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: parse public access block fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidPublicAccessBlock
+		return
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(config); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketPublicAccessBlock(newBytes, vol); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: store public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storePublicAccessBlock(config)
+
+	log.LogInfof("putPublicAccessBlockHandler: put public access block: requestID(%v) volume(%v) config(%v)",
+		GetRequestID(r), param.Bucket(), config)
+	return
+}
+
+// Delete public access block
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeletePublicAccessBlock.html
+func (o *ObjectNode) deletePublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deletePublicAccessBlockHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	if err = deleteBucketPublicAccessBlock(vol); err != nil {
+		log.LogErrorf("deletePublicAccessBlockHandler: delete public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storePublicAccessBlock(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chubaofs/chubaofs/proto"
+)
+
+func TestPolicyIsPublic(t *testing.T) {
+	var cases = []struct {
+		policy string
+		public bool
+	}{
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["action:oss:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`, true},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["action:oss:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`, true},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["user1"]},"Action":["action:oss:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`, false},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["*"]},"Action":["action:oss:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`, false},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["action:oss:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`, false},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["action:oss:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"IpAddress":{"aws:SourceIp":["0.0.0.0/0"]}}}]}`, true},
+	}
+	for i, c := range cases {
+		var policy = &Policy{}
+		if err := json.Unmarshal([]byte(c.policy), policy); err != nil {
+			t.Fatalf("case %v: unmarshal policy fail: err(%v)", i, err)
+		}
+		if public := policy.IsPublic(); public != c.public {
+			t.Fatalf("case %v: public mismatch: expect(%v) actual(%v)", i, c.public, public)
+		}
+	}
+}
+
+func TestPublicAccessBlockRestrict(t *testing.T) {
+	var raw = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["action:oss:GetObject"],"Resource":["bucket/*"]}]}`
+	var policy = &Policy{}
+	if err := json.Unmarshal([]byte(raw), policy); err != nil {
+		t.Fatalf("unmarshal policy fail: err(%v)", err)
+	}
+	var param = &RequestParam{action: proto.OSSGetObjectAction, resource: "bucket/key"}
+	if !policy.IsAllowed(param, false, false) {
+		t.Fatalf("public policy should allow anonymous request")
+	}
+	if policy.IsAllowed(param, false, true) {
+		t.Fatalf("restricted public policy should not allow anonymous request")
+	}
+	if !policy.IsAllowed(param, true, true) {
+		t.Fatalf("restricted public policy should allow owner")
+	}
+
+	var acp = &AccessControlPolicy{}
+	acp.SetBucketStandardACL(&RequestParam{accessKey: "owner"}, string(PublicReadACL))
+	if !acp.IsPublic() {
+		t.Fatalf("public-read ACL should be public")
+	}
+	var private = &AccessControlPolicy{}
+	private.SetBucketStandardACL(&RequestParam{accessKey: "owner"}, string(PrivateACL))
+	if private.IsPublic() {
+		t.Fatalf("private ACL should not be public")
+	}
+	if acp.IsAllowed(param, false, true) {
+		t.Fatalf("ignored public ACL should not allow anonymous request")
+	}
+}
+
+func TestParsePublicAccessBlockConfig(t *testing.T) {
+	var raw = `<PublicAccessBlockConfiguration><BlockPublicAcls>true</BlockPublicAcls><RestrictPublicBuckets>true</RestrictPublicBuckets></PublicAccessBlockConfiguration>`
+	config, err := parsePublicAccessBlockConfig([]byte(raw))
+	if err != nil {
+		t.Fatalf("parse config fail: err(%v)", err)
+	}
+	if !config.BlockPublicAcls || config.IgnorePublicAcls || config.BlockPublicPolicy || !config.RestrictPublicBuckets {
+		t.Fatalf("config mismatch: %+v", config)
+	}
+}
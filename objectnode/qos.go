@@ -0,0 +1,195 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// Maximum time the transfers of a bucket or user may fall behind its bandwidth limit,
+	// new requests are rejected with SlowDown once it is exceeded.
+	qosMaxBandwidthDelay = time.Second
+	// Minimum burst of the bandwidth limiters, so that small limits do not split the transfers into tiny pieces.
+	qosMinBandwidthBurst = 64 * 1024
+)
+
+// qosLimiter limits the requests of a bucket or user with token buckets of requests and bytes.
+type qosLimiter struct {
+	requestRate uint64
+	bandwidth   uint64
+	requests    *rate.Limiter // nil if the request rate is unlimited
+	bytes       *rate.Limiter // nil if the bandwidth is unlimited
+}
+
+func newQoSLimiter(requestRate, bandwidth uint64) *qosLimiter {
+	var l = &qosLimiter{requestRate: requestRate, bandwidth: bandwidth}
+	if requestRate > 0 {
+		l.requests = rate.NewLimiter(rate.Limit(requestRate), int(minUint64(requestRate, math.MaxInt32)))
+	}
+	if bandwidth > 0 {
+		var burst = minUint64(bandwidth, math.MaxInt32)
+		if burst < qosMinBandwidthBurst {
+			burst = qosMinBandwidthBurst
+		}
+		l.bytes = rate.NewLimiter(rate.Limit(bandwidth), int(burst))
+	}
+	return l
+}
+
+// waitBytes blocks until the bandwidth limit allows n bytes to be transferred.
+func (l *qosLimiter) waitBytes(ctx context.Context, n int) error {
+	if l.bytes == nil {
+		return nil
+	}
+	var burst = l.bytes.Burst()
+	for n > 0 {
+		var chunk = n
+		if chunk > burst {
+			chunk = burst
+		}
+		if err := l.bytes.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// QoSManager keeps the limiters of the buckets and users which have limits configured in the master.
+// The limits are reloaded with the volume views and the user information, and the limiter is renewed
+// once its limits change.
+type QoSManager struct {
+	mu       sync.Mutex
+	limiters map[string]*qosLimiter // mapping: bucket or user key -> limiter
+}
+
+func NewQoSManager() *QoSManager {
+	return &QoSManager{limiters: make(map[string]*qosLimiter)}
+}
+
+// limiter returns the limiter of the key with the current limits, or nil if both limits are unlimited.
+func (m *QoSManager) limiter(key string, requestRate, bandwidth uint64) *qosLimiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var l, has = m.limiters[key]
+	if requestRate == 0 && bandwidth == 0 {
+		if has {
+			delete(m.limiters, key)
+		}
+		return nil
+	}
+	if !has || l.requestRate != requestRate || l.bandwidth != bandwidth {
+		l = newQoSLimiter(requestRate, bandwidth)
+		m.limiters[key] = l
+	}
+	return l
+}
+
+func (m *QoSManager) bucketLimiter(bucket string, requestRate, bandwidth uint64) *qosLimiter {
+	return m.limiter("bucket:"+bucket, requestRate, bandwidth)
+}
+
+func (m *QoSManager) userLimiter(userID string, requestRate, bandwidth uint64) *qosLimiter {
+	return m.limiter("user:"+userID, requestRate, bandwidth)
+}
+
+// admit takes a request token from each limiter. The request is rejected without taking any token if
+// any request rate is exceeded or any bandwidth limit is behind more than qosMaxBandwidthDelay.
+func (m *QoSManager) admit(limiters []*qosLimiter) bool {
+	var now = time.Now()
+	var reservations = make([]*rate.Reservation, 0, len(limiters))
+	var cancel = func() {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+	}
+	for _, l := range limiters {
+		if l.bytes != nil && l.bytes.ReserveN(now, 0).DelayFrom(now) > qosMaxBandwidthDelay {
+			cancel()
+			return false
+		}
+		if l.requests != nil {
+			var reservation = l.requests.ReserveN(now, 1)
+			if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+				reservation.CancelAt(now)
+				cancel()
+				return false
+			}
+			reservations = append(reservations, reservation)
+		}
+	}
+	return true
+}
+
+func waitQoSBytes(ctx context.Context, limiters []*qosLimiter, n int) (err error) {
+	for _, l := range limiters {
+		if err = l.waitBytes(ctx, n); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// qosReader limits the bandwidth of the request body.
+type qosReader struct {
+	io.ReadCloser
+	ctx      context.Context
+	limiters []*qosLimiter
+}
+
+func (r *qosReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := waitQoSBytes(r.ctx, r.limiters, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return
+}
+
+// qosResponseWriter limits the bandwidth of the response body.
+type qosResponseWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	limiters []*qosLimiter
+}
+
+func (w *qosResponseWriter) Write(p []byte) (n int, err error) {
+	if err = waitQoSBytes(w.ctx, w.limiters, len(p)); err != nil {
+		return
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *qosResponseWriter) Flush() {
+	if flusher, is := w.ResponseWriter.(http.Flusher); is {
+		flusher.Flush()
+	}
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
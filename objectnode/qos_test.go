@@ -0,0 +1,87 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQoSManagerLimiter(t *testing.T) {
+	var m = NewQoSManager()
+	if l := m.bucketLimiter("bucket", 0, 0); l != nil {
+		t.Fatalf("unlimited bucket should have no limiter")
+	}
+	var l = m.bucketLimiter("bucket", 10, 0)
+	if l == nil || l.requests == nil || l.bytes != nil {
+		t.Fatalf("limiter mismatch: %v", l)
+	}
+	if m.bucketLimiter("bucket", 10, 0) != l {
+		t.Fatalf("limiter should be reused while the limits are unchanged")
+	}
+	if renewed := m.bucketLimiter("bucket", 20, 1024); renewed == l || renewed.bytes == nil {
+		t.Fatalf("limiter should be renewed once the limits change")
+	}
+	if m.userLimiter("bucket", 10, 0) == m.bucketLimiter("bucket", 10, 0) {
+		t.Fatalf("limiters of users and buckets should be separated")
+	}
+	m.bucketLimiter("bucket", 0, 0)
+	if _, has := m.limiters["bucket:bucket"]; has {
+		t.Fatalf("limiter should be released once the limits are removed")
+	}
+}
+
+func TestQoSManagerAdmitRequestRate(t *testing.T) {
+	var m = NewQoSManager()
+	var bucket = m.bucketLimiter("bucket", 2, 0)
+	var user = m.userLimiter("user", 1, 0)
+	if !m.admit([]*qosLimiter{bucket, user}) {
+		t.Fatalf("first request should be admitted")
+	}
+	if m.admit([]*qosLimiter{bucket, user}) {
+		t.Fatalf("second request should exceed the request rate of the user")
+	}
+	// The rejected request takes no token of the bucket.
+	if !m.admit([]*qosLimiter{bucket}) {
+		t.Fatalf("request of another user should be admitted")
+	}
+	if m.admit([]*qosLimiter{bucket}) {
+		t.Fatalf("request should exceed the request rate of the bucket")
+	}
+}
+
+func TestQoSManagerAdmitBandwidth(t *testing.T) {
+	var m = NewQoSManager()
+	var l = m.bucketLimiter("bucket", 0, qosMinBandwidthBurst)
+	if !m.admit([]*qosLimiter{l}) {
+		t.Fatalf("first request should be admitted")
+	}
+	// Reserve transfers of 3 seconds, which is behind the limit more than qosMaxBandwidthDelay.
+	var now = time.Now()
+	for i := 0; i < 4; i++ {
+		l.bytes.ReserveN(now, qosMinBandwidthBurst)
+	}
+	if m.admit([]*qosLimiter{l}) {
+		t.Fatalf("request should be rejected while the transfers are behind the bandwidth limit")
+	}
+
+	// The waiting of a canceled request is interrupted.
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := l.waitBytes(ctx, 1); err == nil {
+		t.Fatalf("waiting of canceled request should fail")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+// Range requests of byte ranges, which are served in a multipart/byteranges payload if multiple
+// ranges are requested.
+// Reference: https://tools.ietf.org/html/rfc7233
+
+const (
+	rangeUnitBytes = "bytes="
+
+	// MaxRangeCount limits the number of ranges in a single request.
+	MaxRangeCount = 100
+
+	HeaderValueMultipartByteRanges = "multipart/byteranges; boundary="
+)
+
+var errUnsatisfiableRange = errors.New("unsatisfiable range")
+
+type httpRange struct {
+	start  int64
+	length int64
+}
+
+func (r httpRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+func (r httpRange) partHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		HeaderNameContentType:  {contentType},
+		HeaderNameContentRange: {r.contentRange(size)},
+	}
+}
+
+// parseRange parses the byte ranges of the Range header against the size of the object.
+// The header is ignored if it is not a valid byte ranges specifier, or the ranges exceed the
+// object in total. The ranges which start beyond the end of the object are skipped, and
+// errUnsatisfiableRange is returned if none of the ranges is satisfiable.
+func parseRange(header string, size int64) ([]httpRange, error) {
+	if !strings.HasPrefix(header, rangeUnitBytes) {
+		return nil, nil
+	}
+	var ranges []httpRange
+	var specified bool
+	for _, spec := range strings.Split(header[len(rangeUnitBytes):], ",") {
+		spec = strings.TrimSpace(spec)
+		if len(spec) == 0 {
+			continue
+		}
+		specified = true
+		var hyphenIndex = strings.Index(spec, "-")
+		if hyphenIndex < 0 {
+			return nil, nil
+		}
+		var first, last = strings.TrimSpace(spec[:hyphenIndex]), strings.TrimSpace(spec[hyphenIndex+1:])
+		if len(first) == 0 {
+			// The suffix range of the last bytes.
+			suffix, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || suffix < 0 {
+				return nil, nil
+			}
+			if suffix == 0 || size == 0 {
+				continue
+			}
+			if suffix > size {
+				suffix = size
+			}
+			ranges = append(ranges, httpRange{start: size - suffix, length: suffix})
+			continue
+		}
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil || start < 0 {
+			return nil, nil
+		}
+		var end = size - 1
+		if len(last) > 0 {
+			if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+				return nil, nil
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+		if start >= size {
+			continue
+		}
+		ranges = append(ranges, httpRange{start: start, length: end - start + 1})
+	}
+	if !specified {
+		return nil, nil
+	}
+	if len(ranges) == 0 {
+		return nil, errUnsatisfiableRange
+	}
+	if len(ranges) > MaxRangeCount {
+		return nil, nil
+	}
+	var total int64
+	for _, r := range ranges {
+		total += r.length
+	}
+	if total > size {
+		// Overlapping ranges larger than the object are served as the entire object.
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// multipartRangesLength computes the length of the multipart/byteranges payload in advance,
+// since the content of the ranges is streamed from the volume.
+func multipartRangesLength(ranges []httpRange, contentType string, size int64, boundary string) int64 {
+	var counter countingWriter
+	var mw = multipart.NewWriter(&counter)
+	_ = mw.SetBoundary(boundary)
+	for _, r := range ranges {
+		_, _ = mw.CreatePart(r.partHeader(contentType, size))
+		counter += countingWriter(r.length)
+	}
+	_ = mw.Close()
+	return int64(counter)
+}
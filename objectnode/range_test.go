@@ -0,0 +1,84 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestRange_Parse(t *testing.T) {
+	var cases = []struct {
+		header string
+		size   int64
+		ranges []httpRange
+		err    error
+	}{
+		{header: "bytes=0-99", size: 1000, ranges: []httpRange{{start: 0, length: 100}}},
+		{header: "bytes=900-", size: 1000, ranges: []httpRange{{start: 900, length: 100}}},
+		{header: "bytes=-100", size: 1000, ranges: []httpRange{{start: 900, length: 100}}},
+		{header: "bytes=-2000", size: 1000, ranges: []httpRange{{start: 0, length: 1000}}},
+		{header: "bytes=990-2000", size: 1000, ranges: []httpRange{{start: 990, length: 10}}},
+		{header: "bytes=0-9, 20-29,-5", size: 1000, ranges: []httpRange{{start: 0, length: 10}, {start: 20, length: 10}, {start: 995, length: 5}}},
+		{header: "bytes=0-9,1000-1999", size: 1000, ranges: []httpRange{{start: 0, length: 10}}},
+		{header: "bytes=1000-1999", size: 1000, err: errUnsatisfiableRange},
+		{header: "bytes=-0", size: 1000, err: errUnsatisfiableRange},
+		{header: "bytes=0-", size: 0, err: errUnsatisfiableRange},
+		{header: "bytes=10-5", size: 1000},
+		{header: "bytes=a-b", size: 1000},
+		{header: "bytes=", size: 1000},
+		{header: "items=0-9", size: 1000},
+		{header: "bytes=0-999,0-999", size: 1000},
+	}
+	for _, c := range cases {
+		ranges, err := parseRange(c.header, c.size)
+		if err != c.err {
+			t.Fatalf("range(%v) error mismatch: expect(%v) actual(%v)", c.header, c.err, err)
+		}
+		if len(ranges) != len(c.ranges) {
+			t.Fatalf("range(%v) count mismatch: expect(%v) actual(%v)", c.header, c.ranges, ranges)
+		}
+		for i := range ranges {
+			if ranges[i] != c.ranges[i] {
+				t.Fatalf("range(%v) mismatch: expect(%v) actual(%v)", c.header, c.ranges, ranges)
+			}
+		}
+	}
+}
+
+func TestRange_MultipartLength(t *testing.T) {
+	var content = strings.Repeat("0123456789", 100)
+	var ranges = []httpRange{{start: 0, length: 10}, {start: 500, length: 100}, {start: 995, length: 5}}
+	var buffer = new(bytes.Buffer)
+	var mw = multipart.NewWriter(buffer)
+	for _, r := range ranges {
+		part, err := mw.CreatePart(r.partHeader("text/plain", int64(len(content))))
+		if err != nil {
+			t.Fatalf("create part fail: err(%v)", err)
+		}
+		_, _ = part.Write([]byte(content[r.start : r.start+r.length]))
+	}
+	_ = mw.Close()
+
+	var length = multipartRangesLength(ranges, "text/plain", int64(len(content)), mw.Boundary())
+	if length != int64(buffer.Len()) {
+		t.Fatalf("payload length mismatch: expect(%v) actual(%v)", buffer.Len(), length)
+	}
+	if !strings.Contains(buffer.String(), "Content-Range: bytes 500-599/1000") {
+		t.Fatalf("content range not found in payload: %v", buffer.String())
+	}
+}
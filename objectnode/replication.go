@@ -0,0 +1,240 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/replication.html
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+const (
+	ReplicationStatusEnabled  = "Enabled"
+	ReplicationStatusDisabled = "Disabled"
+
+	// Values of the x-amz-replication-status header.
+	ReplicationStatusPending   = "PENDING"
+	ReplicationStatusCompleted = "COMPLETED"
+	ReplicationStatusFailed    = "FAILED"
+	ReplicationStatusReplica   = "REPLICA"
+
+	MaxReplicationRules = 1000
+
+	replicationBucketARNPrefix = "arn:aws:s3:::"
+)
+
+// ReplicationConfiguration defines the rules to replicate objects to the destination buckets.
+// The destination bucket is identified by its ARN, and the Account of the destination names the
+// replication endpoint configured on the ObjectNode, which is the ObjectNode of another cluster.
+type ReplicationConfiguration struct {
+	XMLName xml.Name           `xml:"ReplicationConfiguration" json:"-"`
+	Role    string             `xml:"Role" json:"role"`
+	Rules   []*ReplicationRule `xml:"Rule" json:"rules"`
+}
+
+type ReplicationRule struct {
+	ID                      string                   `xml:"ID,omitempty" json:"id"`
+	Priority                int                      `xml:"Priority,omitempty" json:"priority,omitempty"`
+	Status                  string                   `xml:"Status" json:"status"`
+	Prefix                  string                   `xml:"Prefix,omitempty" json:"prefix,omitempty"` // Deprecated, use Filter instead
+	Filter                  *ReplicationFilter       `xml:"Filter,omitempty" json:"filter,omitempty"`
+	DeleteMarkerReplication *DeleteMarkerReplication `xml:"DeleteMarkerReplication,omitempty" json:"dmr,omitempty"`
+	Destination             *ReplicationDestination  `xml:"Destination" json:"dest"`
+}
+
+type ReplicationFilter struct {
+	Prefix string                `xml:"Prefix,omitempty" json:"prefix,omitempty"`
+	Tag    *Tag                  `xml:"Tag,omitempty" json:"tag,omitempty"`
+	And    *ReplicationFilterAnd `xml:"And,omitempty" json:"and,omitempty"`
+}
+
+type ReplicationFilterAnd struct {
+	Prefix string `xml:"Prefix,omitempty" json:"prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag,omitempty" json:"tags,omitempty"`
+}
+
+type DeleteMarkerReplication struct {
+	Status string `xml:"Status" json:"status"`
+}
+
+type ReplicationDestination struct {
+	Bucket       string `xml:"Bucket" json:"bucket"`
+	Account      string `xml:"Account,omitempty" json:"account,omitempty"`
+	StorageClass string `xml:"StorageClass,omitempty" json:"sc,omitempty"`
+}
+
+func (rule *ReplicationRule) Enabled() bool {
+	return rule.Status == ReplicationStatusEnabled
+}
+
+// DeleteReplicated returns whether the deletion of objects is replicated by this rule.
+func (rule *ReplicationRule) DeleteReplicated() bool {
+	return rule.DeleteMarkerReplication != nil && rule.DeleteMarkerReplication.Status == ReplicationStatusEnabled
+}
+
+// KeyPrefix returns the key prefix which objects must match to apply this rule.
+func (rule *ReplicationRule) KeyPrefix() string {
+	if rule.Filter == nil {
+		return rule.Prefix
+	}
+	if rule.Filter.And != nil {
+		return rule.Filter.And.Prefix
+	}
+	return rule.Filter.Prefix
+}
+
+// Tags returns the tags which objects must have to apply this rule.
+func (rule *ReplicationRule) Tags() []Tag {
+	if rule.Filter == nil {
+		return nil
+	}
+	if rule.Filter.And != nil {
+		return rule.Filter.And.Tags
+	}
+	if rule.Filter.Tag != nil {
+		return []Tag{*rule.Filter.Tag}
+	}
+	return nil
+}
+
+// MatchTags checks whether the specified object tagging contains all tags of this rule.
+func (rule *ReplicationRule) MatchTags(tagging *Tagging) bool {
+	var tags = rule.Tags()
+	if len(tags) == 0 {
+		return true
+	}
+	if tagging == nil {
+		return false
+	}
+	for _, tag := range tags {
+		var found bool
+		for _, objectTag := range tagging.TagSet {
+			if objectTag.Key == tag.Key && objectTag.Value == tag.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// DestinationBucket returns the name of the destination bucket.
+func (rule *ReplicationRule) DestinationBucket() string {
+	return strings.TrimPrefix(rule.Destination.Bucket, replicationBucketARNPrefix)
+}
+
+func (rule *ReplicationRule) validate() bool {
+	if rule.Status != ReplicationStatusEnabled && rule.Status != ReplicationStatusDisabled {
+		return false
+	}
+	if len(rule.ID) > 255 || rule.Priority < 0 {
+		return false
+	}
+	if rule.Destination == nil || !strings.HasPrefix(rule.Destination.Bucket, replicationBucketARNPrefix) ||
+		len(rule.DestinationBucket()) == 0 {
+		return false
+	}
+	if rule.DeleteMarkerReplication != nil {
+		var status = rule.DeleteMarkerReplication.Status
+		if status != ReplicationStatusEnabled && status != ReplicationStatusDisabled {
+			return false
+		}
+	}
+	if rule.Filter != nil {
+		var filter = rule.Filter
+		var count int
+		if len(filter.Prefix) > 0 {
+			count++
+		}
+		if filter.Tag != nil {
+			count++
+		}
+		if filter.And != nil {
+			count++
+		}
+		if count > 1 || (len(rule.Prefix) > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (config *ReplicationConfiguration) validate() bool {
+	if len(config.Rules) == 0 || len(config.Rules) > MaxReplicationRules {
+		return false
+	}
+	var ids = make(map[string]struct{})
+	for _, rule := range config.Rules {
+		if !rule.validate() {
+			return false
+		}
+		if len(rule.ID) > 0 {
+			if _, exist := ids[rule.ID]; exist {
+				return false
+			}
+			ids[rule.ID] = struct{}{}
+		}
+	}
+	return true
+}
+
+// MatchRule returns the enabled rule with the highest priority whose prefix matches the object key,
+// or nil if the object is not to be replicated. The tags of rules are not checked here.
+func (config *ReplicationConfiguration) MatchRule(key string) *ReplicationRule {
+	if config == nil {
+		return nil
+	}
+	var matched *ReplicationRule
+	for _, rule := range config.Rules {
+		if !rule.Enabled() || !strings.HasPrefix(key, rule.KeyPrefix()) {
+			continue
+		}
+		if matched == nil || rule.Priority > matched.Priority {
+			matched = rule
+		}
+	}
+	return matched
+}
+
+func parseReplicationConfig(bytes []byte) (config *ReplicationConfiguration, err error) {
+	config = &ReplicationConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return
+	}
+	if ok := config.validate(); !ok {
+		return nil, errors.New("invalid replication configuration")
+	}
+	return
+}
+
+func storeBucketReplication(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSReplication, bytes); err != nil {
+		return
+	}
+	return nil
+}
+
+func deleteBucketReplication(vol *Volume) (err error) {
+	if err = vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSReplication); err != nil {
+		return err
+	}
+	return nil
+}
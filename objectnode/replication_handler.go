@@ -0,0 +1,186 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get bucket replication
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketReplication.html
+func (o *ObjectNode) getBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketReplicationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var replication *ReplicationConfiguration
+	if replication, err = vol.metaLoader.loadReplication(); err != nil {
+		log.LogErrorf("getBucketReplicationHandler: load replication fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if replication == nil || len(replication.Rules) == 0 {
+		errorCode = NoSuchReplicationConfiguration
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(replication); err != nil {
+		log.LogErrorf("getBucketReplicationHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket replication
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketReplication.html
+func (o *ObjectNode) putBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketReplicationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketReplicationHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var replication *ReplicationConfiguration
+	if replication, err = parseReplicationConfig(bytes); err != nil {
+		log.LogErrorf("putBucketReplicationHandler: parse replication configuration fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidReplicationConfiguration
+		return
+	}
+	// The destinations must refer to the replication endpoints configured on the ObjectNode.
+	for _, rule := range replication.Rules {
+		if o.replicator.endpoint(rule.Destination.Account) == nil {
+			log.LogErrorf("putBucketReplicationHandler: unknown replication endpoint: requestID(%v) rule(%v) account(%v)",
+				GetRequestID(r), rule.ID, rule.Destination.Account)
+			errorCode = InvalidReplicationDestination
+			return
+		}
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(replication); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketReplication(newBytes, vol); err != nil {
+		log.LogErrorf("putBucketReplicationHandler: store replication fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeReplication(replication)
+
+	log.LogInfof("putBucketReplicationHandler: put bucket replication: requestID(%v) volume(%v) rules(%v)",
+		GetRequestID(r), param.Bucket(), len(replication.Rules))
+	return
+}
+
+// Delete bucket replication
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketReplication.html
+func (o *ObjectNode) deleteBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketReplicationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	if err = deleteBucketReplication(vol); err != nil {
+		log.LogErrorf("deleteBucketReplicationHandler: delete replication fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeReplication(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"testing"
+)
+
+func TestReplication_Parse(t *testing.T) {
+	var valid = `<ReplicationConfiguration>
+	<Role>arn:aws:iam::backup:role/replication</Role>
+	<Rule>
+		<ID>replicate-logs</ID>
+		<Priority>2</Priority>
+		<Filter>
+			<And>
+				<Prefix>logs/</Prefix>
+				<Tag><Key>type</Key><Value>important</Value></Tag>
+			</And>
+		</Filter>
+		<Status>Enabled</Status>
+		<DeleteMarkerReplication><Status>Enabled</Status></DeleteMarkerReplication>
+		<Destination><Bucket>arn:aws:s3:::logs-backup</Bucket><Account>backup</Account></Destination>
+	</Rule>
+	<Rule>
+		<ID>replicate-all</ID>
+		<Priority>1</Priority>
+		<Filter><Prefix></Prefix></Filter>
+		<Status>Enabled</Status>
+		<Destination><Bucket>arn:aws:s3:::backup</Bucket></Destination>
+	</Rule>
+</ReplicationConfiguration>`
+	config, err := parseReplicationConfig([]byte(valid))
+	if err != nil {
+		t.Fatalf("parse replication configuration fail: err(%v)", err)
+	}
+	if len(config.Rules) != 2 {
+		t.Fatalf("rule count mismatch: expect(2) actual(%v)", len(config.Rules))
+	}
+	var rule = config.Rules[0]
+	if prefix := rule.KeyPrefix(); prefix != "logs/" {
+		t.Fatalf("rule prefix mismatch: expect(logs/) actual(%v)", prefix)
+	}
+	if bucket := rule.DestinationBucket(); bucket != "logs-backup" {
+		t.Fatalf("destination bucket mismatch: expect(logs-backup) actual(%v)", bucket)
+	}
+	if !rule.DeleteReplicated() || config.Rules[1].DeleteReplicated() {
+		t.Fatalf("delete replicated result mismatch")
+	}
+
+	var invalids = []string{
+		// without rules
+		`<ReplicationConfiguration></ReplicationConfiguration>`,
+		// without destination
+		`<ReplicationConfiguration><Rule><Status>Enabled</Status></Rule></ReplicationConfiguration>`,
+		// invalid status
+		`<ReplicationConfiguration><Rule><Status>On</Status><Destination><Bucket>arn:aws:s3:::b</Bucket></Destination></Rule></ReplicationConfiguration>`,
+		// destination bucket is not an ARN
+		`<ReplicationConfiguration><Rule><Status>Enabled</Status><Destination><Bucket>b</Bucket></Destination></Rule></ReplicationConfiguration>`,
+		// both prefix and filter
+		`<ReplicationConfiguration><Rule><Status>Enabled</Status><Prefix>a</Prefix><Filter><Prefix>b</Prefix></Filter><Destination><Bucket>arn:aws:s3:::b</Bucket></Destination></Rule></ReplicationConfiguration>`,
+		// duplicated rule ID
+		`<ReplicationConfiguration><Rule><ID>r</ID><Status>Enabled</Status><Destination><Bucket>arn:aws:s3:::a</Bucket></Destination></Rule><Rule><ID>r</ID><Status>Enabled</Status><Destination><Bucket>arn:aws:s3:::b</Bucket></Destination></Rule></ReplicationConfiguration>`,
+	}
+	for i, invalid := range invalids {
+		if _, err = parseReplicationConfig([]byte(invalid)); err == nil {
+			t.Fatalf("invalid configuration %v passed validation", i)
+		}
+	}
+}
+
+func TestReplication_MatchRule(t *testing.T) {
+	var destination = &ReplicationDestination{Bucket: "arn:aws:s3:::backup"}
+	var config = &ReplicationConfiguration{
+		Rules: []*ReplicationRule{
+			{ID: "all", Priority: 1, Status: ReplicationStatusEnabled, Destination: destination},
+			{ID: "logs", Priority: 2, Status: ReplicationStatusEnabled, Destination: destination,
+				Filter: &ReplicationFilter{Prefix: "logs/"}},
+			{ID: "tmp", Priority: 3, Status: ReplicationStatusDisabled, Destination: destination,
+				Filter: &ReplicationFilter{Prefix: "logs/tmp/"}},
+		},
+	}
+	var cases = []struct {
+		key    string
+		expect string
+	}{
+		{key: "data/01.dat", expect: "all"},
+		{key: "logs/01.log", expect: "logs"},
+		{key: "logs/tmp/01.log", expect: "logs"},
+	}
+	for _, c := range cases {
+		if rule := config.MatchRule(c.key); rule == nil || rule.ID != c.expect {
+			t.Fatalf("matched rule mismatch: key(%v) expect(%v) actual(%v)", c.key, c.expect, rule)
+		}
+	}
+	var nilConfig *ReplicationConfiguration
+	if nilConfig.MatchRule("logs/01.log") != nil {
+		t.Fatalf("nil configuration matched rule")
+	}
+
+	var rule = &ReplicationRule{
+		Filter: &ReplicationFilter{Tag: &Tag{Key: "type", Value: "important"}},
+	}
+	if !rule.MatchTags(&Tagging{TagSet: []Tag{{Key: "type", Value: "important"}, {Key: "owner", Value: "a"}}}) {
+		t.Fatalf("match tags result mismatch")
+	}
+	if rule.MatchTags(&Tagging{TagSet: []Tag{{Key: "type", Value: "tmp"}}}) || rule.MatchTags(nil) {
+		t.Fatalf("match tags result mismatch")
+	}
+}
+
+func TestReplicator_Endpoint(t *testing.T) {
+	var single = NewReplicator(nil, nil, []*ReplicationEndpoint{{Name: "backup", Endpoint: "127.0.0.1:8080"}}, 0)
+	if endpoint := single.endpoint(""); endpoint == nil || endpoint.Name != "backup" {
+		t.Fatalf("default endpoint mismatch: %v", endpoint)
+	}
+	if single.endpoint("other") != nil {
+		t.Fatalf("unknown endpoint resolved")
+	}
+	var multiple = NewReplicator(nil, nil, []*ReplicationEndpoint{
+		{Name: "a", Endpoint: "127.0.0.1:8080"},
+		{Name: "b", Endpoint: "127.0.0.1:8081"},
+	}, 0)
+	if multiple.endpoint("") != nil {
+		t.Fatalf("endpoint resolved without account")
+	}
+	if endpoint := multiple.endpoint("b"); endpoint == nil || endpoint.Endpoint != "127.0.0.1:8081" {
+		t.Fatalf("endpoint mismatch: %v", endpoint)
+	}
+}
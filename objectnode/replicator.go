@@ -0,0 +1,487 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	// The number of change logs fetched from each meta partition at a time.
+	replicationBatchSize = 1000
+	// Objects larger than the part size are replicated by multipart upload.
+	replicationPartSize = 16 * 1024 * 1024
+	// The default interval of the replicator, which runs on every ObjectNode.
+	defaultReplicationInterval = time.Minute
+	// The change log of a volume is consumed by the ObjectNode holding the replication lease of the volume.
+	replicationLeaseTTL = 5 * time.Minute
+)
+
+// ReplicationEndpoint is the ObjectNode of another cluster which hosts the destination buckets of replication.
+// It is referred by the Account of replication destinations with its name.
+type ReplicationEndpoint struct {
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	Region    string `json:"region"`
+}
+
+// Replicator periodically consumes the change logs of volumes and replicates the changed objects
+// to the destination buckets by the replication rules of the volumes.
+// A change is removed from the change log once it has been replicated, otherwise it is retried next time.
+// Every ObjectNode runs the replicator, and each volume is replicated by the one holding its replication lease.
+type Replicator struct {
+	vm        *VolumeManager
+	mc        *master.MasterClient
+	endpoints map[string]*ReplicationEndpoint
+	interval  time.Duration
+	clientMu  sync.Mutex
+	clients   map[string]*s3.S3
+	leases    map[string]*master.TaskLeaseHolder
+	stopOnce  sync.Once
+	stopC     chan struct{}
+}
+
+func NewReplicator(vm *VolumeManager, mc *master.MasterClient, endpoints []*ReplicationEndpoint, interval time.Duration) *Replicator {
+	var r = &Replicator{
+		vm:        vm,
+		mc:        mc,
+		endpoints: make(map[string]*ReplicationEndpoint),
+		interval:  interval,
+		clients:   make(map[string]*s3.S3),
+		leases:    make(map[string]*master.TaskLeaseHolder),
+		stopC:     make(chan struct{}),
+	}
+	for _, endpoint := range endpoints {
+		r.endpoints[endpoint.Name] = endpoint
+	}
+	return r
+}
+
+func (r *Replicator) Start() {
+	go r.scheduleReplicate()
+	log.LogInfof("Replicator: started: interval(%v) endpoints(%v)", r.interval, len(r.endpoints))
+}
+
+func (r *Replicator) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopC)
+	})
+}
+
+func (r *Replicator) stopped() bool {
+	select {
+	case <-r.stopC:
+		return true
+	default:
+		return false
+	}
+}
+
+// endpoint returns the endpoint referred by the account of a replication destination.
+// The only endpoint is used if the account is not specified.
+func (r *Replicator) endpoint(account string) *ReplicationEndpoint {
+	if len(account) == 0 && len(r.endpoints) == 1 {
+		for _, endpoint := range r.endpoints {
+			return endpoint
+		}
+	}
+	return r.endpoints[account]
+}
+
+func (r *Replicator) client(endpoint *ReplicationEndpoint) *s3.S3 {
+	r.clientMu.Lock()
+	defer r.clientMu.Unlock()
+	if client, ok := r.clients[endpoint.Name]; ok {
+		return client
+	}
+	var region = endpoint.Region
+	if len(region) == 0 {
+		region = "default"
+	}
+	var ac = aws.NewConfig()
+	ac.Endpoint = aws.String(endpoint.Endpoint)
+	ac.DisableSSL = aws.Bool(!strings.HasPrefix(endpoint.Endpoint, "https://"))
+	ac.Region = aws.String(region)
+	ac.Credentials = credentials.NewStaticCredentials(endpoint.AccessKey, endpoint.SecretKey, "")
+	ac.S3ForcePathStyle = aws.Bool(true)
+	var client = s3.New(session.Must(session.NewSession()), ac)
+	r.clients[endpoint.Name] = client
+	return client
+}
+
+func (r *Replicator) scheduleReplicate() {
+	var timer = time.NewTimer(r.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			r.replicateAll()
+			timer.Reset(r.interval)
+		case <-r.stopC:
+			return
+		}
+	}
+}
+
+func (r *Replicator) replicateAll() {
+	var err error
+	var volInfos []*proto.VolInfo
+	if volInfos, err = r.mc.AdminAPI().ListVols(""); err != nil {
+		log.LogErrorf("Replicator: list volumes fail: err(%v)", err)
+		return
+	}
+	var start = time.Now()
+	for _, volInfo := range volInfos {
+		if r.stopped() {
+			return
+		}
+		var lease = r.lease(volInfo.Name)
+		if !lease.Hold() {
+			continue
+		}
+		var vol *Volume
+		if vol, err = r.vm.Volume(volInfo.Name); err != nil {
+			log.LogWarnf("Replicator: load volume fail: volume(%v) err(%v)", volInfo.Name, err)
+			continue
+		}
+		r.replicateVolume(vol, lease)
+	}
+	log.LogInfof("Replicator: replicate finished: volumes(%v) cost(%v)", len(volInfos), time.Since(start))
+}
+
+// lease returns the replication lease of the volume, which is only accessed by the replicating goroutine.
+func (r *Replicator) lease(volName string) *master.TaskLeaseHolder {
+	if lease, ok := r.leases[volName]; ok {
+		return lease
+	}
+	var lease = master.NewTaskLeaseHolder(r.mc, "replication."+volName, replicationLeaseTTL)
+	r.leases[volName] = lease
+	return lease
+}
+
+// replicateVolume consumes the change log of the volume while the replication lease is held,
+// the lease is renewed before each batch of changes.
+func (r *Replicator) replicateVolume(vol *Volume, lease *master.TaskLeaseHolder) {
+	// Load configuration from store directly, the cached one may not be synchronized yet.
+	// The change logs are still consumed without configuration in order to clean up the remaining ones.
+	var err error
+	var config *ReplicationConfiguration
+	if config, err = vol.loadBucketReplication(); err != nil {
+		log.LogErrorf("Replicator: load replication fail: volume(%v) err(%v)", vol.Name(), err)
+		return
+	}
+	var markers = make(map[uint64]string)
+	var replicated, failed int
+	for !r.stopped() && lease.Hold() {
+		var changeLogs []*proto.ChangeLogInfo
+		if changeLogs, err = vol.mw.ListChangeLogs_ll(markers, replicationBatchSize); err != nil {
+			log.LogErrorf("Replicator: list change logs fail: volume(%v) err(%v)", vol.Name(), err)
+			return
+		}
+		if len(changeLogs) == 0 {
+			break
+		}
+		for _, changeLog := range changeLogs {
+			if r.stopped() {
+				return
+			}
+			markers[changeLog.PartitionId] = changeLog.ID
+			if err = r.replicateChange(vol, config, changeLog); err != nil {
+				log.LogWarnf("Replicator: replicate change fail: volume(%v) partitionID(%v) id(%v) op(%v) path(%v) err(%v)",
+					vol.Name(), changeLog.PartitionId, changeLog.ID, changeLog.Op, changeLog.Path, err)
+				failed++
+				continue
+			}
+			if err = vol.mw.RemoveChangeLog_ll(changeLog.PartitionId, changeLog.ID); err != nil {
+				log.LogWarnf("Replicator: remove change log fail: volume(%v) partitionID(%v) id(%v) err(%v)",
+					vol.Name(), changeLog.PartitionId, changeLog.ID, err)
+			}
+			replicated++
+		}
+	}
+	if replicated > 0 || failed > 0 {
+		log.LogInfof("Replicator: replicate volume: volume(%v) replicated(%v) failed(%v)",
+			vol.Name(), replicated, failed)
+	}
+}
+
+// replicateChange replicates the current state of the changed path. It returns an error if the change
+// should be retried later.
+func (r *Replicator) replicateChange(vol *Volume, config *ReplicationConfiguration, changeLog *proto.ChangeLogInfo) (err error) {
+	var rule = config.MatchRule(changeLog.Path)
+	if rule == nil {
+		return
+	}
+	var endpoint = r.endpoint(rule.Destination.Account)
+	if endpoint == nil {
+		log.LogErrorf("Replicator: unknown endpoint: volume(%v) rule(%v) account(%v)",
+			vol.Name(), rule.ID, rule.Destination.Account)
+		return syscall.ENOENT
+	}
+	var client = r.client(endpoint)
+	switch changeLog.Op {
+	case proto.ChangeLogOpPut:
+		return r.replicateObject(vol, rule, client, changeLog.Path)
+	case proto.ChangeLogOpDelete:
+		if !rule.DeleteReplicated() {
+			return
+		}
+		return r.replicateDeletion(vol, rule, client, changeLog.Path)
+	default:
+		log.LogWarnf("Replicator: unknown change: volume(%v) path(%v) op(%v)", vol.Name(), changeLog.Path, changeLog.Op)
+	}
+	return
+}
+
+func (r *Replicator) replicateObject(vol *Volume, rule *ReplicationRule, client *s3.S3, path string) (err error) {
+	var info *FSFileInfo
+	if info, err = vol.ObjectMeta(path); err == syscall.ENOENT {
+		// The object has been deleted, and the deletion is replicated by its own change.
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if info.Mode.IsDir() || info.ReplicationStatus == ReplicationStatusCompleted ||
+		info.ReplicationStatus == ReplicationStatusReplica {
+		return
+	}
+	var tagging *Tagging
+	var xattr *proto.XAttrInfo
+	if xattr, err = vol.mw.XAttrGet_ll(info.Inode, XAttrKeyOSSTagging); err != nil {
+		return
+	}
+	if raw := string(xattr.Get(XAttrKeyOSSTagging)); len(raw) > 0 {
+		tagging, _ = ParseTagging(raw)
+	}
+	if !rule.MatchTags(tagging) {
+		if err = vol.mw.XAttrDel_ll(info.Inode, XAttrKeyOSSReplStatus); err != nil {
+			log.LogWarnf("Replicator: clear replication status fail: volume(%v) path(%v) inode(%v) err(%v)",
+				vol.Name(), path, info.Inode, err)
+		}
+		return nil
+	}
+	if len(info.SSECustomerKeyMD5) > 0 {
+		// The data encrypted with customer provided keys can not be read without the keys.
+		log.LogWarnf("Replicator: skip SSE-C object: volume(%v) path(%v) inode(%v)", vol.Name(), path, info.Inode)
+		vol.setReplicationStatus(path, info.Inode, ReplicationStatusFailed)
+		return nil
+	}
+
+	var upload = &replicationUpload{
+		vol:     vol,
+		client:  client,
+		bucket:  rule.DestinationBucket(),
+		info:    info,
+		tagging: tagging,
+		sc:      rule.Destination.StorageClass,
+	}
+	if info.Size <= replicationPartSize {
+		err = upload.put()
+	} else {
+		err = upload.multipartPut()
+	}
+	if err != nil {
+		vol.setReplicationStatus(path, info.Inode, ReplicationStatusFailed)
+		return
+	}
+	vol.setReplicationStatus(path, info.Inode, ReplicationStatusCompleted)
+	log.LogDebugf("Replicator: replicate object: volume(%v) path(%v) inode(%v) destination(%v)",
+		vol.Name(), path, info.Inode, upload.bucket)
+	return
+}
+
+func (r *Replicator) replicateDeletion(vol *Volume, rule *ReplicationRule, client *s3.S3, path string) (err error) {
+	if _, err = vol.ObjectMeta(path); err == nil {
+		// The object has been created again after deletion, which is replicated by its own change.
+		return nil
+	}
+	if err != syscall.ENOENT {
+		return
+	}
+	var input = &s3.DeleteObjectInput{
+		Bucket: aws.String(rule.DestinationBucket()),
+		Key:    aws.String(path),
+	}
+	if _, err = client.DeleteObject(input); err != nil {
+		return
+	}
+	log.LogDebugf("Replicator: replicate deletion: volume(%v) path(%v) destination(%v)",
+		vol.Name(), path, rule.DestinationBucket())
+	return
+}
+
+// replicationUpload uploads an object to the destination bucket. The uploaded object is marked as
+// a replica by header 'x-amz-replication-status', so it will not be replicated by the destination again.
+type replicationUpload struct {
+	vol     *Volume
+	client  *s3.S3
+	bucket  string
+	info    *FSFileInfo
+	tagging *Tagging
+	sc      string
+}
+
+func (u *replicationUpload) markReplica(req *request.Request) {
+	req.HTTPRequest.Header.Set(HeaderNameXAmzReplicationStatus, ReplicationStatusReplica)
+}
+
+func (u *replicationUpload) metadata() map[string]*string {
+	var metadata = make(map[string]*string)
+	for key, value := range u.info.Metadata {
+		metadata[key] = aws.String(value)
+	}
+	return metadata
+}
+
+func (u *replicationUpload) expires() *time.Time {
+	if len(u.info.Expires) == 0 {
+		return nil
+	}
+	expires, err := http.ParseTime(u.info.Expires)
+	if err != nil {
+		return nil
+	}
+	return &expires
+}
+
+func (u *replicationUpload) optionalString(value string) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	return aws.String(value)
+}
+
+func (u *replicationUpload) encodedTagging() *string {
+	if u.tagging == nil || len(u.tagging.TagSet) == 0 {
+		return nil
+	}
+	return aws.String(u.tagging.Encode())
+}
+
+func (u *replicationUpload) read(offset, size uint64) (data []byte, err error) {
+	var buf = bytes.NewBuffer(make([]byte, 0, size))
+	if err = u.vol.ReadInode(u.info.Path, u.info.Inode, buf, offset, size); err != nil {
+		return
+	}
+	return buf.Bytes(), nil
+}
+
+func (u *replicationUpload) put() (err error) {
+	var data []byte
+	if data, err = u.read(0, uint64(u.info.Size)); err != nil {
+		return
+	}
+	var input = &s3.PutObjectInput{
+		Bucket:             aws.String(u.bucket),
+		Key:                aws.String(u.info.Path),
+		Body:               bytes.NewReader(data),
+		ContentType:        u.optionalString(u.info.MIMEType),
+		ContentDisposition: u.optionalString(u.info.Disposition),
+		CacheControl:       u.optionalString(u.info.CacheControl),
+		Expires:            u.expires(),
+		Metadata:           u.metadata(),
+		Tagging:            u.encodedTagging(),
+		StorageClass:       u.optionalString(u.sc),
+	}
+	_, err = u.client.PutObjectWithContext(aws.BackgroundContext(), input, u.markReplica)
+	return
+}
+
+func (u *replicationUpload) multipartPut() (err error) {
+	var createInput = &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(u.bucket),
+		Key:                aws.String(u.info.Path),
+		ContentType:        u.optionalString(u.info.MIMEType),
+		ContentDisposition: u.optionalString(u.info.Disposition),
+		CacheControl:       u.optionalString(u.info.CacheControl),
+		Expires:            u.expires(),
+		Metadata:           u.metadata(),
+		Tagging:            u.encodedTagging(),
+		StorageClass:       u.optionalString(u.sc),
+	}
+	var createOutput *s3.CreateMultipartUploadOutput
+	if createOutput, err = u.client.CreateMultipartUploadWithContext(aws.BackgroundContext(), createInput, u.markReplica); err != nil {
+		return
+	}
+	var uploadId = createOutput.UploadId
+	defer func() {
+		if err != nil {
+			var abortInput = &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(u.bucket),
+				Key:      aws.String(u.info.Path),
+				UploadId: uploadId,
+			}
+			if _, abortErr := u.client.AbortMultipartUpload(abortInput); abortErr != nil {
+				log.LogWarnf("Replicator: abort multipart upload fail: bucket(%v) path(%v) uploadID(%v) err(%v)",
+					u.bucket, u.info.Path, aws.StringValue(uploadId), abortErr)
+			}
+		}
+	}()
+
+	var parts = make([]*s3.CompletedPart, 0)
+	var size = uint64(u.info.Size)
+	for offset, partNumber := uint64(0), int64(1); offset < size; partNumber++ {
+		var partSize = uint64(replicationPartSize)
+		if partSize > size-offset {
+			partSize = size - offset
+		}
+		var data []byte
+		if data, err = u.read(offset, partSize); err != nil {
+			return
+		}
+		var partInput = &s3.UploadPartInput{
+			Bucket:     aws.String(u.bucket),
+			Key:        aws.String(u.info.Path),
+			UploadId:   uploadId,
+			PartNumber: aws.Int64(partNumber),
+			Body:       bytes.NewReader(data),
+		}
+		var partOutput *s3.UploadPartOutput
+		if partOutput, err = u.client.UploadPart(partInput); err != nil {
+			return
+		}
+		parts = append(parts, &s3.CompletedPart{
+			ETag:       partOutput.ETag,
+			PartNumber: aws.Int64(partNumber),
+		})
+		offset += partSize
+	}
+
+	var completeInput = &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(u.bucket),
+		Key:             aws.String(u.info.Path),
+		UploadId:        uploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	}
+	_, err = u.client.CompleteMultipartUpload(completeInput)
+	return
+}
@@ -215,6 +215,12 @@ type CopyResult struct {
 	ETag         string   `xml:"ETag,omitempty"`
 }
 
+type CopyPartResult struct {
+	XMLName      xml.Name `xml:"CopyPartResult"`
+	LastModified string   `xml:"LastModified,omitempty"`
+	ETag         string   `xml:"ETag,omitempty"`
+}
+
 type ListBucketResultV2 struct {
 	XMLName        xml.Name        `xml:"ListBucketResult"`
 	Name           string          `xml:"Name"`
@@ -318,3 +324,38 @@ type CompleteMultipartUploadRequest struct {
 	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
 	Parts   []*PartRequest `xml:"Part"`
 }
+
+type ObjectVersion struct {
+	XMLName      xml.Name     `xml:"Version"`
+	Key          string       `xml:"Key"`
+	VersionId    string       `xml:"VersionId"`
+	IsLatest     bool         `xml:"IsLatest"`
+	LastModified string       `xml:"LastModified"`
+	ETag         string       `xml:"ETag"`
+	Size         int          `xml:"Size"`
+	StorageClass string       `xml:"StorageClass"`
+	Owner        *BucketOwner `xml:"Owner,omitempty"`
+}
+
+type DeleteMarkerEntry struct {
+	XMLName      xml.Name     `xml:"DeleteMarker"`
+	Key          string       `xml:"Key"`
+	VersionId    string       `xml:"VersionId"`
+	IsLatest     bool         `xml:"IsLatest"`
+	LastModified string       `xml:"LastModified"`
+	Owner        *BucketOwner `xml:"Owner,omitempty"`
+}
+
+type ListVersionsResult struct {
+	XMLName             xml.Name             `xml:"ListVersionsResult"`
+	Name                string               `xml:"Name"`
+	Prefix              string               `xml:"Prefix"`
+	KeyMarker           string               `xml:"KeyMarker"`
+	VersionIdMarker     string               `xml:"VersionIdMarker"`
+	NextKeyMarker       string               `xml:"NextKeyMarker,omitempty"`
+	NextVersionIdMarker string               `xml:"NextVersionIdMarker,omitempty"`
+	MaxKeys             int                  `xml:"MaxKeys"`
+	IsTruncated         bool                 `xml:"IsTruncated"`
+	Versions            []*ObjectVersion     `xml:"Version"`
+	DeleteMarkers       []*DeleteMarkerEntry `xml:"DeleteMarker"`
+}
@@ -31,6 +31,7 @@ func (code ErrorCode) ServeResponse(w http.ResponseWriter, r *http.Request) erro
 	// traceMiddleWare send exception request to prometheus via status code
 	SetResponseStatusCode(r, code)
 	SetResponseErrorMessage(r, code.ErrorMessage)
+	SetResponseErrorCode(r, code.ErrorCode)
 
 	var err error
 	var marshaled []byte
@@ -99,11 +100,64 @@ var (
 	OverMaxRecordSize                   = &ErrorCode{ErrorCode: "OverMaxRecordSize", ErrorMessage: "The length of a record in the input or result is greater than maxCharsPerRecord of 1 MB.", StatusCode: http.StatusBadRequest}
 	CopySourceSizeTooLarge              = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "The specified copy source is larger than the maximum allowable size for a copy source: 5368709120", StatusCode: http.StatusBadRequest}
 	InvalidPartOrder                    = &ErrorCode{ErrorCode: "InvalidPartOrder", ErrorMessage: "The list of parts was not in ascending order. Parts list must be specified in order by part number.", StatusCode: http.StatusBadRequest}
+	InvalidPartNumber                   = &ErrorCode{ErrorCode: "InvalidPartNumber", ErrorMessage: "The requested partnumber is not satisfiable.", StatusCode: http.StatusBadRequest}
 	InvalidPart                         = &ErrorCode{ErrorCode: "InvalidPart", ErrorMessage: "One or more of the specified parts could not be found. The part might not have been uploaded, or the specified entity tag might not have matched the part's entity tag.", StatusCode: http.StatusBadRequest}
 	InvalidCacheArgument                = &ErrorCode{ErrorCode: "InvalidCacheArgument", ErrorMessage: "Invalid Cache-Control or Expires Argument", StatusCode: http.StatusBadRequest}
 	TagsGreaterThen10                   = &ErrorCode{ErrorCode: "BadRequest", ErrorMessage: "Object tags cannot be greater than 10", StatusCode: http.StatusBadRequest}
 	InvalidTagKey                       = &ErrorCode{ErrorCode: "InvalidTag", ErrorMessage: "The TagKey you have provided is invalid", StatusCode: http.StatusBadRequest}
 	InvalidTagValue                     = &ErrorCode{ErrorCode: "InvalidTag", ErrorMessage: "The TagValue you have provided is invalid", StatusCode: http.StatusBadRequest}
+	NoSuchVersion                       = &ErrorCode{ErrorCode: "NoSuchVersion", ErrorMessage: "The specified version does not exist.", StatusCode: http.StatusNotFound}
+	MethodNotAllowed                    = &ErrorCode{ErrorCode: "MethodNotAllowed", ErrorMessage: "The specified method is not allowed against this resource.", StatusCode: http.StatusMethodNotAllowed}
+	IllegalVersioningConfiguration      = &ErrorCode{ErrorCode: "IllegalVersioningConfigurationException", ErrorMessage: "The versioning configuration specified in the request is invalid.", StatusCode: http.StatusBadRequest}
+	NoSuchLifecycleConfiguration        = &ErrorCode{ErrorCode: "NoSuchLifecycleConfiguration", ErrorMessage: "The lifecycle configuration does not exist.", StatusCode: http.StatusNotFound}
+	InvalidLifecycleConfiguration       = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	NoSuchEncryptionConfiguration       = &ErrorCode{ErrorCode: "ServerSideEncryptionConfigurationNotFoundError", ErrorMessage: "The server side encryption configuration was not found.", StatusCode: http.StatusNotFound}
+	InvalidEncryptionConfiguration      = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	InvalidEncryptionAlgorithm          = &ErrorCode{ErrorCode: "InvalidEncryptionAlgorithmError", ErrorMessage: "The encryption request you specified is not valid. The valid value is AES256.", StatusCode: http.StatusBadRequest}
+	ServerSideEncryptionNotEnabled      = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Server side encryption is not enabled on the bucket and can not be enabled after creation.", StatusCode: http.StatusBadRequest}
+	ServerSideEncryptionNotDisabled     = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Server side encryption of the bucket can not be disabled.", StatusCode: http.StatusBadRequest}
+	InvalidSSECustomerKey               = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The secret key was invalid for the specified algorithm.", StatusCode: http.StatusBadRequest}
+	SSECustomerKeyMD5Mismatch           = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The calculated MD5 hash of the key did not match the hash that was provided.", StatusCode: http.StatusBadRequest}
+	SSECustomerKeyRequired              = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.", StatusCode: http.StatusBadRequest}
+	SSECustomerKeyNotApplicable         = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "The encryption parameters are not applicable to this object.", StatusCode: http.StatusBadRequest}
+	NotImplemented                      = &ErrorCode{ErrorCode: "NotImplemented", ErrorMessage: "A header you provided implies functionality that is not implemented.", StatusCode: http.StatusNotImplemented}
+	QuotaExceeded                       = &ErrorCode{ErrorCode: "QuotaExceeded", ErrorMessage: "The quota of the bucket or the directory has been exceeded.", StatusCode: http.StatusForbidden}
+	NoSuchReplicationConfiguration      = &ErrorCode{ErrorCode: "ReplicationConfigurationNotFoundError", ErrorMessage: "The replication configuration was not found.", StatusCode: http.StatusNotFound}
+	InvalidReplicationConfiguration     = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	InvalidReplicationDestination       = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "The destination of the replication rule is not a configured replication endpoint.", StatusCode: http.StatusBadRequest}
+	NoSuchObjectLockConfiguration       = &ErrorCode{ErrorCode: "NoSuchObjectLockConfiguration", ErrorMessage: "The specified object does not have a ObjectLock configuration.", StatusCode: http.StatusNotFound}
+	InvalidObjectLockConfiguration      = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	NoSuchWebsiteConfiguration          = &ErrorCode{ErrorCode: "NoSuchWebsiteConfiguration", ErrorMessage: "The specified bucket does not have a website configuration.", StatusCode: http.StatusNotFound}
+	InvalidWebsiteConfiguration         = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	InvalidObjectLockHeaders            = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "x-amz-object-lock-retain-until-date and x-amz-object-lock-mode must both be supplied with a retain-until date in the future.", StatusCode: http.StatusBadRequest}
+	InvalidNotificationConfiguration    = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The notification configuration is invalid or refers to an unknown notification target.", StatusCode: http.StatusBadRequest}
+	InvalidSelectRequest                = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	MissingSelectExpression             = &ErrorCode{ErrorCode: "MissingRequiredParameter", ErrorMessage: "The SelectRequest entity is missing a required parameter: Expression.", StatusCode: http.StatusBadRequest}
+	InvalidSelectExpressionType         = &ErrorCode{ErrorCode: "InvalidExpressionType", ErrorMessage: "The ExpressionType is invalid. Only SQL expressions are supported.", StatusCode: http.StatusBadRequest}
+	InvalidSelectCompression            = &ErrorCode{ErrorCode: "InvalidCompressionFormat", ErrorMessage: "The file is not in a supported compression format. Only GZIP is supported.", StatusCode: http.StatusBadRequest}
+	InvalidSelectExpression             = &ErrorCode{ErrorCode: "ParseUnsupportedSyntax", ErrorMessage: "The SQL expression contains unsupported syntax.", StatusCode: http.StatusBadRequest}
+	MalformedPOSTRequest                = &ErrorCode{ErrorCode: "MalformedPOSTRequest", ErrorMessage: "The body of your POST request is not well-formed multipart/form-data.", StatusCode: http.StatusBadRequest}
+	MaxPostPreDataLengthExceeded        = &ErrorCode{ErrorCode: "MaxPostPreDataLengthExceededError", ErrorMessage: "Your POST request fields preceding the upload file were too large.", StatusCode: http.StatusBadRequest}
+	InvalidPolicyDocument               = &ErrorCode{ErrorCode: "InvalidPolicyDocument", ErrorMessage: "The content of the form does not meet the conditions specified in the policy document.", StatusCode: http.StatusBadRequest}
+	SignatureDoesNotMatch               = &ErrorCode{ErrorCode: "SignatureDoesNotMatch", ErrorMessage: "The request signature we calculated does not match the signature you provided.", StatusCode: http.StatusForbidden}
+	PostPolicyExpired                   = &ErrorCode{ErrorCode: "AccessDenied", ErrorMessage: "Invalid according to Policy: Policy expired.", StatusCode: http.StatusForbidden}
+	PostPolicyNotMatched                = &ErrorCode{ErrorCode: "AccessDenied", ErrorMessage: "Invalid according to Policy: Policy Condition failed.", StatusCode: http.StatusForbidden}
+	ObjectLocked                        = &ErrorCode{ErrorCode: "AccessDenied", ErrorMessage: "Access Denied because object protected by object lock.", StatusCode: http.StatusForbidden}
+	NoSuchPublicAccessBlock             = &ErrorCode{ErrorCode: "NoSuchPublicAccessBlockConfiguration", ErrorMessage: "The public access block configuration was not found.", StatusCode: http.StatusNotFound}
+	InvalidPublicAccessBlock            = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	PublicAccessBlocked                 = &ErrorCode{ErrorCode: "AccessDenied", ErrorMessage: "Access Denied because the public access block of the bucket does not allow public ACLs or policies.", StatusCode: http.StatusForbidden}
+	MalformedACL                        = &ErrorCode{ErrorCode: "MalformedACLError", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	InvalidToken                        = &ErrorCode{ErrorCode: "InvalidToken", ErrorMessage: "The provided token is malformed or otherwise invalid.", StatusCode: http.StatusBadRequest}
+	ExpiredToken                        = &ErrorCode{ErrorCode: "ExpiredToken", ErrorMessage: "The provided token has expired.", StatusCode: http.StatusBadRequest}
+	STSDisabled                         = &ErrorCode{ErrorCode: "NotImplemented", ErrorMessage: "The security token service is not enabled.", StatusCode: http.StatusNotImplemented}
+	InvalidSTSAction                    = &ErrorCode{ErrorCode: "InvalidAction", ErrorMessage: "The action or operation requested is invalid.", StatusCode: http.StatusBadRequest}
+	InvalidSTSParameter                 = &ErrorCode{ErrorCode: "InvalidParameterValue", ErrorMessage: "An invalid or out-of-range value was supplied for the input parameter.", StatusCode: http.StatusBadRequest}
+	MalformedPolicyDocument             = &ErrorCode{ErrorCode: "MalformedPolicyDocument", ErrorMessage: "The request was rejected because the policy document was malformed.", StatusCode: http.StatusBadRequest}
+	RequestTimeTooSkewed                = &ErrorCode{ErrorCode: "RequestTimeTooSkewed", ErrorMessage: "The difference between the request time and the server's time is too large.", StatusCode: http.StatusForbidden}
+	InvalidTargetBucketForLogging       = &ErrorCode{ErrorCode: "InvalidTargetBucketForLogging", ErrorMessage: "The target bucket for logging does not exist or is not owned by the owner of the bucket.", StatusCode: http.StatusBadRequest}
+	InvalidBucketLoggingStatus          = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
+	SlowDown                            = &ErrorCode{ErrorCode: "SlowDown", ErrorMessage: "Please reduce your request rate.", StatusCode: http.StatusServiceUnavailable}
+	InvalidStorageClass                 = &ErrorCode{ErrorCode: "InvalidStorageClass", ErrorMessage: "The storage class you specified is not valid.", StatusCode: http.StatusBadRequest}
 )
 
 func HttpStatusErrorCode(code int) *ErrorCode {
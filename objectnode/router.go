@@ -25,6 +25,24 @@ import (
 // register api routers
 func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
+	// Website endpoints serve the buckets as static websites to anonymous users.
+	// They are registered in front of the API routers, and other methods are rejected.
+	for _, d := range o.webDomains {
+		for _, host := range []string{"{bucket:.+}." + d + ":{port:[0-9]+}", "{bucket:.+}." + d} {
+			var wRouter = router.Host(host).Subrouter()
+			wRouter.NewRoute().Name(ActionToUniqueRouteName(proto.OSSHeadObjectAction)).
+				Methods(http.MethodHead).
+				HandlerFunc(o.websiteHandler)
+			wRouter.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectAction)).
+				Methods(http.MethodGet).
+				HandlerFunc(o.websiteHandler)
+			wRouter.NewRoute().Name(ActionToUniqueRouteName(proto.OSSOptionsObjectAction)).
+				Methods(http.MethodOptions).
+				HandlerFunc(o.optionsObjectHandler)
+			wRouter.NewRoute().HandlerFunc(o.unsupportedOperationHandler)
+		}
+	}
+
 	var bucketRouters []*mux.Router
 	bRouter := router.PathPrefix("/").Subrouter()
 	for _, d := range o.domains {
@@ -91,21 +109,19 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get object legal hold
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectLegalHold.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectLegalHoldAction)).
 			Methods(http.MethodGet).
 			Path("/{object:.+}").
 			Queries("legal-hold", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getObjectLegalHoldHandler)
 
 		// Get object retention
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectRetention.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectRetentionAction)).
 			Methods(http.MethodGet).
 			Path("/{object:.+}").
 			Queries("retention", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getObjectRetentionHandler)
 
 		// Get object torrent
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectTorrent.html
@@ -153,11 +169,10 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get bucket policy status
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicyStatus.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketPolicyStatusAction)).
 			Methods(http.MethodGet).
 			Queries("policyStatus", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketPolicyStatusHandler)
 
 		// Get bucket acl
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketAcl.html
@@ -175,11 +190,10 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get bucket encryption
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketEncryption.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketEncryptionAction)).
 			Methods(http.MethodGet).
 			Queries("encryption", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketEncryptionHandler)
 
 		// Get bucket cors
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketCors.html
@@ -191,19 +205,31 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get bucket website
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketWebsite.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketWebsiteAction)).
 			Methods(http.MethodGet).
 			Queries("website", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketWebsiteHandler)
+
+		// Get bucket notification configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketNotificationConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketNotificationAction)).
+			Methods(http.MethodGet).
+			Queries("notification", "").
+			HandlerFunc(o.getBucketNotificationHandler)
 
 		// Get public access block
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetPublicAccessBlock.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetPublicAccessBlockAction)).
 			Methods(http.MethodGet).
 			Queries("publicAccessBlock", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getPublicAccessBlockHandler)
+
+		// Get bucket logging
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLogging.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketLoggingAction)).
+			Methods(http.MethodGet).
+			Queries("logging", "").
+			HandlerFunc(o.getBucketLoggingHandler)
 
 		// Get bucket request payment
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketRequestPayment.html
@@ -215,35 +241,31 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get bucket replication
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketReplication.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketReplicationAction)).
 			Methods(http.MethodGet).
 			Queries("replication", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketReplicationHandler)
 
 		// Get bucket lifecycle
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLifecycle.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketLifecycleAction)).
 			Methods(http.MethodGet).
 			Queries("lifecycle", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketLifecycleHandler)
 
 		// Get bucket versioning
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketVersioning.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketVersioningAction)).
 			Methods(http.MethodGet).
 			Queries("versioning", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketVersioningHandler)
 
 		// List object versions
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectVersions.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSListObjectVersionsAction)).
 			Methods(http.MethodGet).
 			Queries("versions", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.listObjectVersionsHandler)
 
 		// List objects version 1
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjects.html
@@ -269,6 +291,14 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 			Queries("uploadId", "{uploadId:.*}").
 			HandlerFunc(o.completeMultipartUploadHandler)
 
+		// Select object content
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSSelectObjectContentAction)).
+			Methods(http.MethodPost).
+			Path("/{object:.+}").
+			Queries("select", "", "select-type", "2").
+			HandlerFunc(o.selectObjectContentHandler)
+
 		// Restore object
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_RestoreObject.html
 		// Notes: unsupported operation
@@ -284,19 +314,25 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 			Methods(http.MethodPost).
 			Queries("delete", "").
 			HandlerFunc(o.deleteObjectsHandler)
+
+		// Post object (browser-based upload using HTML forms)
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectPOST.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPostObjectAction)).
+			Methods(http.MethodPost).
+			HeadersRegexp(HeaderNameContentType, "^multipart/form-data").
+			HandlerFunc(o.postObjectHandler)
 	}
 
 	var registerBucketHttpPutRouters = func(r *mux.Router) {
 
 		// Upload part copy
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPartCopy.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSUploadPartCopyAction)).
 			Methods(http.MethodPut).
 			Path("/{object:.+}").
 			HeadersRegexp(HeaderNameXAmzCopySource, ".*?(\\/|%2F).*?").
 			Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.uploadPartCopyHandler)
 
 		// Upload part
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPart.html .
@@ -340,21 +376,19 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Put object legal hold
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectLegalHold.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutObjectLegalHoldAction)).
 			Methods(http.MethodPut).
 			Path("/{object:.+}").
 			Queries("legal-hold", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putObjectLegalHoldHandler)
 
 		// Put object retention
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectRetention.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutObjectRetentionAction)).
 			Methods(http.MethodPut).
 			Path("/{object:.+}").
 			Queries("retention", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putObjectRetentionHandler)
 
 		// Put object
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html
@@ -386,11 +420,10 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Put bucket encryption
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketEncryption.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketEncryptionAction)).
 			Methods(http.MethodPut).
 			Queries("encryption", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putBucketEncryptionHandler)
 
 		// Put bucket cors
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketCors.html
@@ -402,19 +435,31 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Put bucket website
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketWebsiteAction)).
 			Methods(http.MethodPut).
 			Queries("website", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putBucketWebsiteHandler)
+
+		// Put bucket notification configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketNotificationConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketNotificationAction)).
+			Methods(http.MethodPut).
+			Queries("notification", "").
+			HandlerFunc(o.putBucketNotificationHandler)
 
 		// Put public access block
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutPublicAccessBlock.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutPublicAccessBlockAction)).
 			Methods(http.MethodPut).
 			Queries("publicAccessBlock", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putPublicAccessBlockHandler)
+
+		// Put bucket logging
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketLoggingAction)).
+			Methods(http.MethodPut).
+			Queries("logging", "").
+			HandlerFunc(o.putBucketLoggingHandler)
 
 		// Put bucket request payment
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketRequestPayment.html
@@ -426,27 +471,24 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Put bucket replication
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketReplication.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketReplicationAction)).
 			Methods(http.MethodPut).
 			Queries("replication", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putBucketReplicationHandler)
 
 		// Put bucket lifecycle
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLifecycle.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketLifecycleAction)).
 			Methods(http.MethodPut).
 			Queries("lifecycle", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putBucketLifecycleHandler)
 
 		// Put bucket versioning
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketVersioning.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketVersioningAction)).
 			Methods(http.MethodPut).
 			Queries("versioning", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putBucketVersioningHandler)
 
 		// Create bucket
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CreateBucket.html
@@ -503,11 +545,10 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Delete bucket encryption
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketEncryption.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketEncryptionAction)).
 			Methods(http.MethodDelete).
 			Queries("encryption", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deleteBucketEncryptionHandler)
 
 		// Delete bucket cors
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketCors.html
@@ -519,35 +560,31 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Delete bucket website
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketWebsite.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketWebsiteAction)).
 			Methods(http.MethodDelete).
 			Queries("website", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deleteBucketWebsiteHandler)
 
 		// Delete public access block
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeletePublicAccessBlock.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeletePublicAccessBlockAction)).
 			Methods(http.MethodDelete).
 			Queries("publicAccessBlock", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deletePublicAccessBlockHandler)
 
 		// Delete bucket replication
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketReplication.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketReplicationAction)).
 			Methods(http.MethodDelete).
 			Queries("replication", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deleteBucketReplicationHandler)
 
 		// Delete bucket lifecycle
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketLifecycle.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketLifecycleAction)).
 			Methods(http.MethodDelete).
 			Queries("lifecycle", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deleteBucketLifecycleHandler)
 
 		// Delete bucket
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucket.html
@@ -581,6 +618,14 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 		Methods(http.MethodGet).
 		HandlerFunc(o.listBucketsHandler)
 
+	// Security token service (AssumeRole and GetSessionToken)
+	// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_Operations.html
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSSTSAction)).
+		Methods(http.MethodPost).
+		Path("/").
+		HeadersRegexp(HeaderNameContentType, "^application/x-www-form-urlencoded").
+		HandlerFunc(o.stsHandler)
+
 	// Unsupported operation
 	router.NotFoundHandler = http.HandlerFunc(o.unsupportedOperationHandler)
 }
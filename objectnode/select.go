@@ -0,0 +1,507 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	selectTableName = "S3Object"
+
+	SelectExpressionTypeSQL = "SQL"
+
+	SelectCompressionNone = "NONE"
+	SelectCompressionGzip = "GZIP"
+
+	SelectFileHeaderUse    = "USE"
+	SelectFileHeaderIgnore = "IGNORE"
+	SelectFileHeaderNone   = "NONE"
+
+	SelectJSONTypeDocument = "DOCUMENT"
+	SelectJSONTypeLines    = "LINES"
+
+	SelectQuoteFieldsAlways   = "ALWAYS"
+	SelectQuoteFieldsAsNeeded = "ASNEEDED"
+
+	MaxSelectRequestSize = 256 << 10
+)
+
+type SelectObjectContentRequest struct {
+	XMLName             xml.Name                  `xml:"SelectObjectContentRequest"`
+	Expression          string                    `xml:"Expression"`
+	ExpressionType      string                    `xml:"ExpressionType"`
+	InputSerialization  SelectInputSerialization  `xml:"InputSerialization"`
+	OutputSerialization SelectOutputSerialization `xml:"OutputSerialization"`
+	RequestProgress     *SelectRequestProgress    `xml:"RequestProgress,omitempty"`
+}
+
+type SelectRequestProgress struct {
+	Enabled bool `xml:"Enabled"`
+}
+
+type SelectInputSerialization struct {
+	CompressionType string           `xml:"CompressionType,omitempty"`
+	CSV             *SelectCSVInput  `xml:"CSV,omitempty"`
+	JSON            *SelectJSONInput `xml:"JSON,omitempty"`
+}
+
+type SelectCSVInput struct {
+	FileHeaderInfo  string `xml:"FileHeaderInfo,omitempty"`
+	Comments        string `xml:"Comments,omitempty"`
+	FieldDelimiter  string `xml:"FieldDelimiter,omitempty"`
+	RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+	QuoteCharacter  string `xml:"QuoteCharacter,omitempty"`
+}
+
+type SelectJSONInput struct {
+	Type string `xml:"Type"`
+}
+
+type SelectOutputSerialization struct {
+	CSV  *SelectCSVOutput  `xml:"CSV,omitempty"`
+	JSON *SelectJSONOutput `xml:"JSON,omitempty"`
+}
+
+type SelectCSVOutput struct {
+	QuoteFields     string `xml:"QuoteFields,omitempty"`
+	FieldDelimiter  string `xml:"FieldDelimiter,omitempty"`
+	RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+	QuoteCharacter  string `xml:"QuoteCharacter,omitempty"`
+}
+
+type SelectJSONOutput struct {
+	RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+}
+
+// validSelectDelimiter checks whether the delimiter is empty (default) or a single character.
+func validSelectDelimiter(delimiter string) bool {
+	return len(delimiter) == 0 || utf8.RuneCountInString(delimiter) == 1
+}
+
+func (request *SelectObjectContentRequest) validate() *ErrorCode {
+	if len(strings.TrimSpace(request.Expression)) == 0 {
+		return MissingSelectExpression
+	}
+	if request.ExpressionType != SelectExpressionTypeSQL {
+		return InvalidSelectExpressionType
+	}
+	var input = request.InputSerialization
+	switch strings.ToUpper(input.CompressionType) {
+	case "", SelectCompressionNone, SelectCompressionGzip:
+	default:
+		return InvalidSelectCompression
+	}
+	if (input.CSV == nil) == (input.JSON == nil) {
+		return InvalidSelectRequest
+	}
+	if csvInput := input.CSV; csvInput != nil {
+		switch strings.ToUpper(csvInput.FileHeaderInfo) {
+		case "", SelectFileHeaderUse, SelectFileHeaderIgnore, SelectFileHeaderNone:
+		default:
+			return InvalidSelectRequest
+		}
+		// The CSV reader only supports the double quote as quote character.
+		if !validSelectDelimiter(csvInput.FieldDelimiter) || len(csvInput.RecordDelimiter) > 1 &&
+			csvInput.RecordDelimiter != "\r\n" || !validSelectDelimiter(csvInput.Comments) ||
+			len(csvInput.QuoteCharacter) > 0 && csvInput.QuoteCharacter != "\"" {
+			return InvalidSelectRequest
+		}
+	}
+	if jsonInput := input.JSON; jsonInput != nil {
+		switch strings.ToUpper(jsonInput.Type) {
+		case SelectJSONTypeDocument, SelectJSONTypeLines:
+		default:
+			return InvalidSelectRequest
+		}
+	}
+	var output = request.OutputSerialization
+	if (output.CSV == nil) == (output.JSON == nil) {
+		return InvalidSelectRequest
+	}
+	if csvOutput := output.CSV; csvOutput != nil {
+		switch strings.ToUpper(csvOutput.QuoteFields) {
+		case "", SelectQuoteFieldsAlways, SelectQuoteFieldsAsNeeded:
+		default:
+			return InvalidSelectRequest
+		}
+		if !validSelectDelimiter(csvOutput.FieldDelimiter) || !validSelectDelimiter(csvOutput.QuoteCharacter) {
+			return InvalidSelectRequest
+		}
+	}
+	return nil
+}
+
+func (request *SelectObjectContentRequest) gzipped() bool {
+	return strings.ToUpper(request.InputSerialization.CompressionType) == SelectCompressionGzip
+}
+
+func parseSelectObjectContentRequest(data []byte) (request *SelectObjectContentRequest, errorCode *ErrorCode) {
+	request = &SelectObjectContentRequest{}
+	if err := xml.Unmarshal(data, request); err != nil {
+		return nil, InvalidSelectRequest
+	}
+	if errorCode = request.validate(); errorCode != nil {
+		return nil, errorCode
+	}
+	return
+}
+
+// selectRecord is a row of a CSV object or a top level value of a JSON object.
+type selectRecord struct {
+	names  []string
+	values []interface{}
+}
+
+// get returns the value of the column. Columns can also be referenced by
+// position (_1, _2, ...) if there is no column with the name.
+func (record *selectRecord) get(ident sqlIdent) (interface{}, bool) {
+	for i, name := range record.names {
+		if ident.match(name) {
+			return record.values[i], true
+		}
+	}
+	if len(ident.name) > 1 && ident.name[0] == '_' {
+		if index, err := strconv.Atoi(ident.name[1:]); err == nil && index > 0 && index <= len(record.values) {
+			return record.values[index-1], true
+		}
+	}
+	return nil, false
+}
+
+// positionalNames returns the names _1, _2, ... _n.
+func positionalNames(n int) []string {
+	var names = make([]string, n)
+	for i := range names {
+		names[i] = "_" + strconv.Itoa(i+1)
+	}
+	return names
+}
+
+type selectRecordReader interface {
+	// Read returns the next record or io.EOF at the end of the input.
+	Read() (*selectRecord, error)
+}
+
+type csvRecordReader struct {
+	reader     *csv.Reader
+	fileHeader string
+	header     []string
+}
+
+// delimiterReader replaces a custom record delimiter with '\n' for the CSV reader.
+type delimiterReader struct {
+	reader    io.Reader
+	delimiter byte
+}
+
+func (r *delimiterReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == r.delimiter {
+			p[i] = '\n'
+		}
+	}
+	return
+}
+
+func newCSVRecordReader(input *SelectCSVInput, reader io.Reader) *csvRecordReader {
+	if len(input.RecordDelimiter) == 1 && input.RecordDelimiter != "\n" {
+		reader = &delimiterReader{reader: reader, delimiter: input.RecordDelimiter[0]}
+	}
+	var csvReader = csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	if len(input.FieldDelimiter) > 0 {
+		csvReader.Comma, _ = utf8.DecodeRuneInString(input.FieldDelimiter)
+	}
+	if len(input.Comments) > 0 {
+		csvReader.Comment, _ = utf8.DecodeRuneInString(input.Comments)
+	}
+	return &csvRecordReader{reader: csvReader, fileHeader: strings.ToUpper(input.FileHeaderInfo)}
+}
+
+func (r *csvRecordReader) Read() (record *selectRecord, err error) {
+	var fields []string
+	if fields, err = r.reader.Read(); err != nil {
+		return
+	}
+	if r.header == nil {
+		switch r.fileHeader {
+		case SelectFileHeaderUse:
+			r.header = fields
+			return r.Read()
+		case SelectFileHeaderIgnore:
+			r.header = []string{}
+			return r.Read()
+		default:
+			r.header = []string{}
+		}
+	}
+	record = &selectRecord{names: r.header, values: make([]interface{}, len(fields))}
+	if len(r.header) != len(fields) {
+		record.names = positionalNames(len(fields))
+		for i := 0; i < len(fields) && i < len(r.header); i++ {
+			record.names[i] = r.header[i]
+		}
+	}
+	for i, field := range fields {
+		record.values[i] = field
+	}
+	return
+}
+
+type jsonRecordReader struct {
+	decoder *json.Decoder
+}
+
+func newJSONRecordReader(reader io.Reader) *jsonRecordReader {
+	var decoder = json.NewDecoder(reader)
+	decoder.UseNumber()
+	return &jsonRecordReader{decoder: decoder}
+}
+
+func (r *jsonRecordReader) Read() (record *selectRecord, err error) {
+	var raw json.RawMessage
+	if err = r.decoder.Decode(&raw); err != nil {
+		return
+	}
+	return decodeJSONRecord(raw)
+}
+
+// decodeJSONRecord decodes a top level JSON value and keeps the order of the
+// fields of an object. Other values are returned as a record with the single column _1.
+func decodeJSONRecord(data []byte) (record *selectRecord, err error) {
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var token json.Token
+	if token, err = decoder.Token(); err != nil {
+		return
+	}
+	if delim, is := token.(json.Delim); !is || delim != '{' {
+		var value interface{}
+		decoder = json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err = decoder.Decode(&value); err != nil {
+			return
+		}
+		return &selectRecord{names: []string{"_1"}, values: []interface{}{value}}, nil
+	}
+	record = &selectRecord{}
+	for decoder.More() {
+		if token, err = decoder.Token(); err != nil {
+			return
+		}
+		var value interface{}
+		if err = decoder.Decode(&value); err != nil {
+			return
+		}
+		record.names = append(record.names, token.(string))
+		record.values = append(record.values, value)
+	}
+	return
+}
+
+// formatSelectValue formats a value as a CSV field.
+func formatSelectValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	var data, _ = json.Marshal(value)
+	return string(data)
+}
+
+type selectRecordWriter interface {
+	// Append appends the serialized record to buf.
+	Append(buf []byte, names []string, values []interface{}) []byte
+}
+
+type csvRecordWriter struct {
+	fieldDelimiter  string
+	recordDelimiter string
+	quote           string
+	always          bool
+}
+
+func newCSVRecordWriter(output *SelectCSVOutput) *csvRecordWriter {
+	var writer = &csvRecordWriter{
+		fieldDelimiter:  ",",
+		recordDelimiter: "\n",
+		quote:           "\"",
+		always:          strings.ToUpper(output.QuoteFields) == SelectQuoteFieldsAlways,
+	}
+	if len(output.FieldDelimiter) > 0 {
+		writer.fieldDelimiter = output.FieldDelimiter
+	}
+	if len(output.RecordDelimiter) > 0 {
+		writer.recordDelimiter = output.RecordDelimiter
+	}
+	if len(output.QuoteCharacter) > 0 {
+		writer.quote = output.QuoteCharacter
+	}
+	return writer
+}
+
+func (w *csvRecordWriter) Append(buf []byte, names []string, values []interface{}) []byte {
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, w.fieldDelimiter...)
+		}
+		var field = formatSelectValue(value)
+		if w.always || strings.Contains(field, w.fieldDelimiter) || strings.Contains(field, w.quote) ||
+			strings.Contains(field, w.recordDelimiter) || strings.ContainsAny(field, "\r\n") {
+			field = w.quote + strings.Replace(field, w.quote, w.quote+w.quote, -1) + w.quote
+		}
+		buf = append(buf, field...)
+	}
+	return append(buf, w.recordDelimiter...)
+}
+
+type jsonRecordWriter struct {
+	recordDelimiter string
+}
+
+func newJSONRecordWriter(output *SelectJSONOutput) *jsonRecordWriter {
+	var writer = &jsonRecordWriter{recordDelimiter: "\n"}
+	if len(output.RecordDelimiter) > 0 {
+		writer.recordDelimiter = output.RecordDelimiter
+	}
+	return writer
+}
+
+func (w *jsonRecordWriter) Append(buf []byte, names []string, values []interface{}) []byte {
+	buf = append(buf, '{')
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		var name, _ = json.Marshal(names[i])
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		var data, err = json.Marshal(value)
+		if err != nil {
+			data, _ = json.Marshal(formatSelectValue(value))
+		}
+		buf = append(buf, data...)
+	}
+	buf = append(buf, '}')
+	return append(buf, w.recordDelimiter...)
+}
+
+// countingReader counts the bytes read from the underlying reader and keeps the read error.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+	err    error
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.count += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return
+}
+
+// executeSelect evaluates the query over the object data read from input and writes
+// the result to output as an event stream. Errors which happen after the response
+// has been started are reported to the client with an error message and returned.
+func executeSelect(request *SelectObjectContentRequest, query *SelectQuery, input io.Reader, output io.Writer) (err error) {
+	var scanned = &countingReader{reader: bufio.NewReader(input)}
+	var processed = &countingReader{reader: scanned}
+	var events = newSelectEventWriter(output)
+	if request.RequestProgress != nil && request.RequestProgress.Enabled {
+		events.progress = func() (int64, int64) {
+			return scanned.count, processed.count
+		}
+	}
+
+	if request.gzipped() {
+		var gzipReader *gzip.Reader
+		if gzipReader, err = gzip.NewReader(scanned); err != nil {
+			_ = events.writeError("InvalidCompressionFormat", "The file is not in a supported compression format.")
+			return
+		}
+		defer gzipReader.Close()
+		processed.reader = gzipReader
+	}
+
+	var records selectRecordReader
+	var parsingErrorCode = "CSVParsingError"
+	if request.InputSerialization.CSV != nil {
+		records = newCSVRecordReader(request.InputSerialization.CSV, processed)
+	} else {
+		records = newJSONRecordReader(processed)
+		parsingErrorCode = "JSONParsingError"
+	}
+	var writer selectRecordWriter
+	if request.OutputSerialization.CSV != nil {
+		writer = newCSVRecordWriter(request.OutputSerialization.CSV)
+	} else {
+		writer = newJSONRecordWriter(request.OutputSerialization.JSON)
+	}
+
+	var buf []byte
+	var count int64
+	for query.limit < 0 || count < query.limit {
+		var record *selectRecord
+		if record, err = records.Read(); err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil && scanned.err != nil {
+			// failed to read the object rather than to parse it
+			_ = events.writeError("InternalError", "We encountered an internal error. Please try again.")
+			return
+		}
+		if err != nil {
+			_ = events.writeError(parsingErrorCode, err.Error())
+			return
+		}
+		if !query.match(record) {
+			continue
+		}
+		var names, values = query.project(record)
+		buf = writer.Append(buf[:0], names, values)
+		if err = events.writeRecord(buf); err != nil {
+			return
+		}
+		count++
+	}
+	return events.finish(scanned.count, processed.count)
+}
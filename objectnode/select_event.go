@@ -0,0 +1,177 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"net/http"
+)
+
+// Response of SelectObjectContent is a stream of messages in the AWS event stream encoding:
+// https://docs.aws.amazon.com/AmazonS3/latest/API/RESTSelectObjectAppendix.html
+//
+//   +------------------+------------------+-------------+---------+---------+-------------+
+//   | total length (4) | header length(4) | prelude CRC | headers | payload | message CRC |
+//   +------------------+------------------+-------------+---------+---------+-------------+
+//
+// Both CRCs are CRC32 (IEEE). Every header is encoded as name length (1), name,
+// value type (1, always 7 for string), value length (2) and value.
+
+const (
+	selectEventHeaderTypeString = 7
+
+	selectMessageTypeEvent = "event"
+	selectMessageTypeError = "error"
+
+	selectEventRecords  = "Records"
+	selectEventStats    = "Stats"
+	selectEventProgress = "Progress"
+	selectEventEnd      = "End"
+
+	// Records are buffered and sent in messages of about this size.
+	selectRecordsMessageSize = 128 << 10
+)
+
+type selectEventHeader struct {
+	name  string
+	value string
+}
+
+// encodeSelectMessage encodes a message with the headers and payload.
+func encodeSelectMessage(headers []selectEventHeader, payload []byte) []byte {
+	var headerBuffer bytes.Buffer
+	for _, header := range headers {
+		headerBuffer.WriteByte(byte(len(header.name)))
+		headerBuffer.WriteString(header.name)
+		headerBuffer.WriteByte(selectEventHeaderTypeString)
+		_ = binary.Write(&headerBuffer, binary.BigEndian, uint16(len(header.value)))
+		headerBuffer.WriteString(header.value)
+	}
+	var totalLength = 12 + headerBuffer.Len() + len(payload) + 4
+	var message = make([]byte, 12, totalLength)
+	binary.BigEndian.PutUint32(message[0:4], uint32(totalLength))
+	binary.BigEndian.PutUint32(message[4:8], uint32(headerBuffer.Len()))
+	binary.BigEndian.PutUint32(message[8:12], crc32.ChecksumIEEE(message[0:8]))
+	message = append(message, headerBuffer.Bytes()...)
+	message = append(message, payload...)
+	var crc [4]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(message))
+	return append(message, crc[:]...)
+}
+
+func selectEventHeaders(eventType, contentType string) []selectEventHeader {
+	var headers = []selectEventHeader{
+		{name: ":event-type", value: eventType},
+	}
+	if len(contentType) > 0 {
+		headers = append(headers, selectEventHeader{name: ":content-type", value: contentType})
+	}
+	return append(headers, selectEventHeader{name: ":message-type", value: selectMessageTypeEvent})
+}
+
+// SelectStats is the payload of Stats and Progress events.
+type SelectStats struct {
+	XMLName        xml.Name
+	BytesScanned   int64 `xml:"BytesScanned"`
+	BytesProcessed int64 `xml:"BytesProcessed"`
+	BytesReturned  int64 `xml:"BytesReturned"`
+}
+
+// selectEventWriter writes the events of a SelectObjectContent response.
+type selectEventWriter struct {
+	writer   io.Writer
+	records  bytes.Buffer
+	returned int64
+	// progress is called for the current number of scanned and processed bytes,
+	// Progress events are only sent if it is not nil.
+	progress func() (scanned, processed int64)
+}
+
+func newSelectEventWriter(writer io.Writer) *selectEventWriter {
+	return &selectEventWriter{writer: writer}
+}
+
+func (w *selectEventWriter) writeMessage(headers []selectEventHeader, payload []byte) error {
+	if _, err := w.writer.Write(encodeSelectMessage(headers, payload)); err != nil {
+		return err
+	}
+	if flusher, is := w.writer.(http.Flusher); is {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// writeRecord buffers a serialized record, a Records event is sent once enough records are buffered.
+func (w *selectEventWriter) writeRecord(record []byte) error {
+	w.records.Write(record)
+	w.returned += int64(len(record))
+	if w.records.Len() < selectRecordsMessageSize {
+		return nil
+	}
+	return w.flushRecords()
+}
+
+func (w *selectEventWriter) flushRecords() (err error) {
+	if w.records.Len() == 0 {
+		return
+	}
+	if err = w.writeMessage(selectEventHeaders(selectEventRecords, HeaderValueTypeStream), w.records.Bytes()); err != nil {
+		return
+	}
+	w.records.Reset()
+	if w.progress != nil {
+		var scanned, processed = w.progress()
+		err = w.writeStats(selectEventProgress, scanned, processed)
+	}
+	return
+}
+
+func (w *selectEventWriter) writeStats(eventType string, scanned, processed int64) error {
+	var stats = &SelectStats{
+		XMLName:        xml.Name{Local: eventType},
+		BytesScanned:   scanned,
+		BytesProcessed: processed,
+		BytesReturned:  w.returned,
+	}
+	var payload, err = MarshalXMLEntity(stats)
+	if err != nil {
+		return err
+	}
+	return w.writeMessage(selectEventHeaders(eventType, HeaderValueContentTypeXML), payload)
+}
+
+// finish sends the remaining records followed by the Stats and End events.
+func (w *selectEventWriter) finish(scanned, processed int64) (err error) {
+	if err = w.flushRecords(); err != nil {
+		return
+	}
+	if err = w.writeStats(selectEventStats, scanned, processed); err != nil {
+		return
+	}
+	return w.writeMessage(selectEventHeaders(selectEventEnd, ""), nil)
+}
+
+// writeError sends an error message which terminates the response.
+func (w *selectEventWriter) writeError(code, message string) error {
+	return w.writeMessage([]selectEventHeader{
+		{name: ":error-code", value: code},
+		{name: ":error-message", value: message},
+		{name: ":message-type", value: selectMessageTypeError},
+	}, nil)
+}
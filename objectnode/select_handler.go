@@ -0,0 +1,132 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"syscall"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Select object content
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+func (o *ObjectNode) selectObjectContentHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("selectObjectContentHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+	var sseKey *SSECustomerKey
+	if sseKey, errorCode = ParseSSECustomerKey(r.Header); errorCode != nil {
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(io.LimitReader(r.Body, MaxSelectRequestSize+1)); err != nil && err != io.EOF {
+		log.LogErrorf("selectObjectContentHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if len(bytes) > MaxSelectRequestSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	var request *SelectObjectContentRequest
+	if request, errorCode = parseSelectObjectContentRequest(bytes); errorCode != nil {
+		log.LogErrorf("selectObjectContentHandler: invalid request: requestID(%v) err(%v)",
+			GetRequestID(r), errorCode.ErrorMessage)
+		return
+	}
+	var query *SelectQuery
+	if query, err = ParseSelectQuery(request.Expression); err != nil {
+		log.LogErrorf("selectObjectContentHandler: parse expression fail: requestID(%v) expression(%v) err(%v)",
+			GetRequestID(r), request.Expression, err)
+		errorCode = InvalidSelectExpression
+		return
+	}
+
+	var fileInfo *FSFileInfo
+	if fileInfo, err = vol.ObjectMeta(param.Object()); err == syscall.ENOENT {
+		errorCode = NoSuchKey
+		return
+	}
+	if err != nil {
+		log.LogErrorf("selectObjectContentHandler: get file meta fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if fileInfo.Mode.IsDir() || fileInfo.DeleteMarker {
+		errorCode = NoSuchKey
+		return
+	}
+	if errorCode = checkSSECustomerKey(sseKey, fileInfo); errorCode != nil {
+		return
+	}
+
+	// The object is read by a separate goroutine and streamed through the query.
+	var reader, writer = io.Pipe()
+	defer func() {
+		_ = reader.Close()
+	}()
+	var dataWriter io.Writer = writer
+	if sseKey != nil {
+		if dataWriter, err = newSSECustomerWriter(writer, sseKey, fileInfo.SSECustomerIV, 0); err != nil {
+			log.LogErrorf("selectObjectContentHandler: init SSE-C writer fail: requestID(%v) volume(%v) path(%v) err(%v)",
+				GetRequestID(r), vol.Name(), param.Object(), err)
+			errorCode = InternalErrorCode(err)
+			return
+		}
+	}
+	go func() {
+		var readErr = vol.ReadFile(param.Object(), dataWriter, 0, uint64(fileInfo.Size))
+		_ = writer.CloseWithError(readErr)
+	}()
+
+	w.WriteHeader(http.StatusOK)
+	if err = executeSelect(request, query, reader, w); err != nil {
+		log.LogErrorf("selectObjectContentHandler: execute select fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		return
+	}
+	log.LogDebugf("selectObjectContentHandler: select object content: requestID(%v) volume(%v) path(%v) expression(%v)",
+		GetRequestID(r), vol.Name(), param.Object(), request.Expression)
+}
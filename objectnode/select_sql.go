@@ -0,0 +1,875 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+// A small SQL dialect used by SelectObjectContent:
+//
+//   SELECT * | expr [[AS] name], ... FROM S3Object[[*]] [[AS] alias] [WHERE condition] [LIMIT n]
+//
+// Conditions support comparisons (=, !=, <>, <, <=, >, >=), AND, OR, NOT, IS [NOT] NULL,
+// [NOT] LIKE, [NOT] IN, [NOT] BETWEEN and CAST(expr AS type). Columns are referenced by
+// name, by position (_1, _2, ...) or by a path into JSON objects (s.a.b). Unquoted
+// identifiers match case-insensitively and double-quoted identifiers match exactly.
+
+type sqlTokenKind int
+
+const (
+	sqlTokenEOF sqlTokenKind = iota
+	sqlTokenIdent
+	sqlTokenQuotedIdent
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenOperator
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+	pos  int
+}
+
+var sqlOperators = []string{"<=", ">=", "<>", "!=", "=", "<", ">", "(", ")", ",", ".", "*", "[", "]", "-"}
+
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "LIMIT": true, "AS": true, "AND": true, "OR": true,
+	"NOT": true, "IS": true, "NULL": true, "LIKE": true, "IN": true, "BETWEEN": true, "TRUE": true,
+	"FALSE": true, "CAST": true,
+}
+
+func isSQLIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSQLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// readSQLQuoted reads a literal enclosed by quote starting at pos, where a doubled quote escapes itself.
+func readSQLQuoted(expr string, pos int, quote byte) (value string, end int, err error) {
+	var builder strings.Builder
+	for i := pos + 1; i < len(expr); i++ {
+		if expr[i] != quote {
+			builder.WriteByte(expr[i])
+			continue
+		}
+		if i+1 < len(expr) && expr[i+1] == quote {
+			builder.WriteByte(quote)
+			i++
+			continue
+		}
+		return builder.String(), i + 1, nil
+	}
+	return "", 0, errors.NewErrorf("unterminated literal at position %v", pos)
+}
+
+func tokenizeSQL(expr string) (tokens []sqlToken, err error) {
+	var pos = 0
+	for pos < len(expr) {
+		var c = expr[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			pos++
+		case isSQLIdentStart(c):
+			var end = pos + 1
+			for end < len(expr) && (isSQLIdentStart(expr[end]) || isSQLDigit(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenIdent, text: expr[pos:end], pos: pos})
+			pos = end
+		case c == '"' || c == '\'':
+			var value string
+			var end int
+			if value, end, err = readSQLQuoted(expr, pos, c); err != nil {
+				return
+			}
+			var kind = sqlTokenString
+			if c == '"' {
+				kind = sqlTokenQuotedIdent
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: value, pos: pos})
+			pos = end
+		case isSQLDigit(c) || (c == '.' && pos+1 < len(expr) && isSQLDigit(expr[pos+1])):
+			var end = pos
+			for end < len(expr) && (isSQLDigit(expr[end]) || expr[end] == '.') {
+				end++
+			}
+			if end < len(expr) && (expr[end] == 'e' || expr[end] == 'E') {
+				end++
+				if end < len(expr) && (expr[end] == '+' || expr[end] == '-') {
+					end++
+				}
+				for end < len(expr) && isSQLDigit(expr[end]) {
+					end++
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenNumber, text: expr[pos:end], pos: pos})
+			pos = end
+		default:
+			var matched bool
+			for _, op := range sqlOperators {
+				if strings.HasPrefix(expr[pos:], op) {
+					tokens = append(tokens, sqlToken{kind: sqlTokenOperator, text: op, pos: pos})
+					pos += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, errors.NewErrorf("unexpected character %q at position %v", c, pos)
+			}
+		}
+	}
+	tokens = append(tokens, sqlToken{kind: sqlTokenEOF, pos: len(expr)})
+	return
+}
+
+// sqlExpr is a node of the parsed expression tree which evaluates against a single record.
+// A nil result stands for SQL NULL (or MISSING).
+type sqlExpr interface {
+	eval(record *selectRecord) interface{}
+}
+
+type sqlLiteral struct {
+	value interface{}
+}
+
+func (e *sqlLiteral) eval(record *selectRecord) interface{} {
+	return e.value
+}
+
+type sqlIdent struct {
+	name   string
+	quoted bool
+}
+
+func (ident sqlIdent) match(name string) bool {
+	if ident.quoted {
+		return ident.name == name
+	}
+	return strings.EqualFold(ident.name, name)
+}
+
+// sqlScope holds the table alias which is declared by the FROM clause after
+// the select list has been parsed.
+type sqlScope struct {
+	alias string
+}
+
+type sqlColumn struct {
+	scope *sqlScope
+	path  []sqlIdent
+}
+
+func (e *sqlColumn) eval(record *selectRecord) interface{} {
+	var path = e.path
+	if len(path) > 1 && (path[0].match(e.scope.alias) || strings.EqualFold(path[0].name, selectTableName)) {
+		path = path[1:]
+	}
+	var value, found = record.get(path[0])
+	for _, ident := range path[1:] {
+		if !found {
+			return nil
+		}
+		var object, is = value.(map[string]interface{})
+		if !is {
+			return nil
+		}
+		found = false
+		for name, child := range object {
+			if ident.match(name) {
+				value, found = child, true
+				if ident.quoted || name == ident.name {
+					break
+				}
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return value
+}
+
+// name returns the name used for the column in the output when no alias is given.
+func (e *sqlColumn) name() string {
+	return e.path[len(e.path)-1].name
+}
+
+type sqlLogical struct {
+	and         bool
+	left, right sqlExpr
+}
+
+func (e *sqlLogical) eval(record *selectRecord) interface{} {
+	var left = sqlTruth(e.left.eval(record))
+	if e.and && left == false || !e.and && left == true {
+		return left
+	}
+	var right = sqlTruth(e.right.eval(record))
+	if e.and && right == false || !e.and && right == true {
+		return right
+	}
+	if left == nil || right == nil {
+		return nil
+	}
+	return left
+}
+
+type sqlNot struct {
+	expr sqlExpr
+}
+
+func (e *sqlNot) eval(record *selectRecord) interface{} {
+	if value, is := sqlTruth(e.expr.eval(record)).(bool); is {
+		return !value
+	}
+	return nil
+}
+
+type sqlCompare struct {
+	op          string
+	left, right sqlExpr
+}
+
+func (e *sqlCompare) eval(record *selectRecord) interface{} {
+	return sqlCompareValues(e.op, e.left.eval(record), e.right.eval(record))
+}
+
+type sqlIsNull struct {
+	expr sqlExpr
+	not  bool
+}
+
+func (e *sqlIsNull) eval(record *selectRecord) interface{} {
+	return (e.expr.eval(record) == nil) != e.not
+}
+
+type sqlLike struct {
+	expr    sqlExpr
+	pattern sqlExpr
+	regexp  *regexp.Regexp // compiled at parse time for literal patterns
+	not     bool
+}
+
+func (e *sqlLike) eval(record *selectRecord) interface{} {
+	var value, is = e.expr.eval(record).(string)
+	if !is {
+		return nil
+	}
+	var re = e.regexp
+	if re == nil {
+		var pattern, is = e.pattern.eval(record).(string)
+		if !is {
+			return nil
+		}
+		re = compileSQLLike(pattern)
+	}
+	return re.MatchString(value) != e.not
+}
+
+type sqlIn struct {
+	expr sqlExpr
+	list []sqlExpr
+	not  bool
+}
+
+func (e *sqlIn) eval(record *selectRecord) interface{} {
+	var value = e.expr.eval(record)
+	if value == nil {
+		return nil
+	}
+	for _, item := range e.list {
+		if sqlCompareValues("=", value, item.eval(record)) == true {
+			return !e.not
+		}
+	}
+	return e.not
+}
+
+type sqlBetween struct {
+	expr         sqlExpr
+	lower, upper sqlExpr
+	not          bool
+}
+
+func (e *sqlBetween) eval(record *selectRecord) interface{} {
+	var value = e.expr.eval(record)
+	var lower = sqlTruth(sqlCompareValues(">=", value, e.lower.eval(record)))
+	var upper = sqlTruth(sqlCompareValues("<=", value, e.upper.eval(record)))
+	if lower == nil || upper == nil {
+		return nil
+	}
+	return (lower == true && upper == true) != e.not
+}
+
+type sqlCast struct {
+	expr     sqlExpr
+	typeName string
+}
+
+func (e *sqlCast) eval(record *selectRecord) interface{} {
+	var value = e.expr.eval(record)
+	if value == nil {
+		return nil
+	}
+	switch e.typeName {
+	case "INT", "INTEGER":
+		if str, is := value.(string); is {
+			if i, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err == nil {
+				return i
+			}
+		}
+		if f, ok := sqlNumber(value); ok {
+			return int64(f)
+		}
+	case "FLOAT", "DECIMAL", "NUMERIC":
+		if f, ok := sqlNumber(value); ok {
+			return f
+		}
+	case "STRING", "VARCHAR":
+		return formatSelectValue(value)
+	case "BOOL", "BOOLEAN":
+		switch v := value.(type) {
+		case bool:
+			return v
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+var sqlCastTypes = map[string]bool{
+	"INT": true, "INTEGER": true, "FLOAT": true, "DECIMAL": true, "NUMERIC": true,
+	"STRING": true, "VARCHAR": true, "BOOL": true, "BOOLEAN": true,
+}
+
+// sqlTruth converts a value to a SQL boolean, anything which is not a boolean is unknown (nil).
+func sqlTruth(value interface{}) interface{} {
+	if b, is := value.(bool); is {
+		return b
+	}
+	return nil
+}
+
+// sqlNumber converts a value to a float64. Strings are converted if they are numeric.
+func sqlNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func isSQLNumeric(value interface{}) bool {
+	switch value.(type) {
+	case float64, int64, json.Number:
+		return true
+	}
+	return false
+}
+
+func sqlCompareResult(op string, cmp int) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=", "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// sqlCompareValues compares two values. The comparison is numeric if either side is a number
+// and the other side can be converted to a number, otherwise strings are compared lexically.
+// The result is nil if either side is null or the values are not comparable.
+func sqlCompareValues(op string, left, right interface{}) interface{} {
+	if left == nil || right == nil {
+		return nil
+	}
+	if isSQLNumeric(left) || isSQLNumeric(right) {
+		var l, lok = sqlNumber(left)
+		var r, rok = sqlNumber(right)
+		if !lok || !rok || math.IsNaN(l) || math.IsNaN(r) {
+			return nil
+		}
+		var cmp = 0
+		if l < r {
+			cmp = -1
+		} else if l > r {
+			cmp = 1
+		}
+		return sqlCompareResult(op, cmp)
+	}
+	var ls, lok = left.(string)
+	var rs, rok = right.(string)
+	if lok && rok {
+		return sqlCompareResult(op, strings.Compare(ls, rs))
+	}
+	var lb, lbok = left.(bool)
+	var rb, rbok = right.(bool)
+	if lbok && rbok && (op == "=" || op == "!=" || op == "<>") {
+		return (lb == rb) == (op == "=")
+	}
+	return nil
+}
+
+// compileSQLLike converts a LIKE pattern, in which '%' matches any sequence and '_' matches
+// any single character, to an anchored regular expression.
+func compileSQLLike(pattern string) *regexp.Regexp {
+	var builder strings.Builder
+	builder.WriteString("^(?s:")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			builder.WriteString(".*")
+		case '_':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	builder.WriteString(")$")
+	return regexp.MustCompile(builder.String())
+}
+
+type sqlProjection struct {
+	expr sqlExpr
+	name string
+}
+
+// SelectQuery is a parsed SQL expression of SelectObjectContent.
+type SelectQuery struct {
+	projections []*sqlProjection // nil means all columns (SELECT *)
+	scope       *sqlScope
+	where       sqlExpr
+	limit       int64 // negative means no limit
+}
+
+// match returns whether the record satisfies the WHERE clause.
+func (q *SelectQuery) match(record *selectRecord) bool {
+	return q.where == nil || q.where.eval(record) == true
+}
+
+// project evaluates the select list against the record.
+func (q *SelectQuery) project(record *selectRecord) (names []string, values []interface{}) {
+	if q.projections == nil {
+		return record.names, record.values
+	}
+	names = make([]string, len(q.projections))
+	values = make([]interface{}, len(q.projections))
+	for i, projection := range q.projections {
+		names[i] = projection.name
+		values[i] = projection.expr.eval(record)
+	}
+	return
+}
+
+type sqlParser struct {
+	tokens []sqlToken
+	pos    int
+	scope  *sqlScope
+}
+
+func (p *sqlParser) peek() sqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) next() sqlToken {
+	var token = p.tokens[p.pos]
+	if token.kind != sqlTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *sqlParser) isKeyword(token sqlToken, keyword string) bool {
+	return token.kind == sqlTokenIdent && strings.EqualFold(token.text, keyword)
+}
+
+// keyword consumes the next token if it is the keyword.
+func (p *sqlParser) keyword(keyword string) bool {
+	if p.isKeyword(p.peek(), keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// operator consumes the next token if it is the operator.
+func (p *sqlParser) operator(op string) bool {
+	var token = p.peek()
+	if token.kind == sqlTokenOperator && token.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) unexpected() error {
+	var token = p.peek()
+	if token.kind == sqlTokenEOF {
+		return errors.New("unexpected end of expression")
+	}
+	return errors.NewErrorf("unexpected token %q at position %v", token.text, token.pos)
+}
+
+func (p *sqlParser) expectKeyword(keyword string) error {
+	if !p.keyword(keyword) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *sqlParser) expectOperator(op string) error {
+	if !p.operator(op) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+// ident consumes an identifier which is not a reserved keyword.
+func (p *sqlParser) ident() (ident sqlIdent, ok bool) {
+	var token = p.peek()
+	switch {
+	case token.kind == sqlTokenQuotedIdent:
+		ident = sqlIdent{name: token.text, quoted: true}
+	case token.kind == sqlTokenIdent && !sqlKeywords[strings.ToUpper(token.text)]:
+		ident = sqlIdent{name: token.text}
+	default:
+		return
+	}
+	p.pos++
+	return ident, true
+}
+
+// ParseSelectQuery parses the SQL expression of a SelectObjectContent request.
+func ParseSelectQuery(expression string) (query *SelectQuery, err error) {
+	var tokens []sqlToken
+	if tokens, err = tokenizeSQL(expression); err != nil {
+		return
+	}
+	var p = &sqlParser{tokens: tokens, scope: &sqlScope{}}
+	query = &SelectQuery{scope: p.scope, limit: -1}
+	if err = p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	if query.projections, err = p.parseSelectList(); err != nil {
+		return nil, err
+	}
+	if err = p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if err = p.parseSource(); err != nil {
+		return nil, err
+	}
+	if p.keyword("WHERE") {
+		if query.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.keyword("LIMIT") {
+		var token = p.next()
+		if token.kind != sqlTokenNumber {
+			return nil, errors.NewErrorf("invalid limit at position %v", token.pos)
+		}
+		if query.limit, err = strconv.ParseInt(token.text, 10, 64); err != nil || query.limit < 0 {
+			return nil, errors.NewErrorf("invalid limit %q", token.text)
+		}
+	}
+	if p.peek().kind != sqlTokenEOF {
+		return nil, p.unexpected()
+	}
+	return
+}
+
+func (p *sqlParser) parseSelectList() (projections []*sqlProjection, err error) {
+	if p.operator("*") {
+		return nil, nil
+	}
+	// alias.*
+	if len(p.tokens) > p.pos+2 && p.tokens[p.pos].kind == sqlTokenIdent &&
+		p.tokens[p.pos+1].kind == sqlTokenOperator && p.tokens[p.pos+1].text == "." &&
+		p.tokens[p.pos+2].kind == sqlTokenOperator && p.tokens[p.pos+2].text == "*" {
+		p.pos += 3
+		return nil, nil
+	}
+	for {
+		var projection = &sqlProjection{}
+		if projection.expr, err = p.parseOr(); err != nil {
+			return
+		}
+		if p.keyword("AS") {
+			var ident, ok = p.ident()
+			if !ok {
+				return nil, p.unexpected()
+			}
+			projection.name = ident.name
+		} else if ident, ok := p.ident(); ok {
+			projection.name = ident.name
+		} else if column, is := projection.expr.(*sqlColumn); is {
+			projection.name = column.name()
+		} else {
+			projection.name = "_" + strconv.Itoa(len(projections)+1)
+		}
+		projections = append(projections, projection)
+		if !p.operator(",") {
+			return
+		}
+	}
+}
+
+func (p *sqlParser) parseSource() error {
+	var ident, ok = p.ident()
+	if !ok || !strings.EqualFold(ident.name, selectTableName) {
+		return p.unexpected()
+	}
+	if p.operator("[") {
+		if !p.operator("*") || !p.operator("]") {
+			return p.unexpected()
+		}
+	}
+	var aliasRequired = p.keyword("AS")
+	if alias, ok := p.ident(); ok {
+		p.scope.alias = alias.name
+	} else if aliasRequired {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *sqlParser) parseOr() (expr sqlExpr, err error) {
+	if expr, err = p.parseAnd(); err != nil {
+		return
+	}
+	for p.keyword("OR") {
+		var right sqlExpr
+		if right, err = p.parseAnd(); err != nil {
+			return
+		}
+		expr = &sqlLogical{and: false, left: expr, right: right}
+	}
+	return
+}
+
+func (p *sqlParser) parseAnd() (expr sqlExpr, err error) {
+	if expr, err = p.parseNot(); err != nil {
+		return
+	}
+	for p.keyword("AND") {
+		var right sqlExpr
+		if right, err = p.parseNot(); err != nil {
+			return
+		}
+		expr = &sqlLogical{and: true, left: expr, right: right}
+	}
+	return
+}
+
+func (p *sqlParser) parseNot() (expr sqlExpr, err error) {
+	if p.keyword("NOT") {
+		if expr, err = p.parseNot(); err != nil {
+			return
+		}
+		return &sqlNot{expr: expr}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *sqlParser) parsePredicate() (expr sqlExpr, err error) {
+	if expr, err = p.parseOperand(); err != nil {
+		return
+	}
+	var token = p.peek()
+	if token.kind == sqlTokenOperator {
+		switch token.text {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.pos++
+			var right sqlExpr
+			if right, err = p.parseOperand(); err != nil {
+				return
+			}
+			return &sqlCompare{op: token.text, left: expr, right: right}, nil
+		}
+		return
+	}
+	if p.keyword("IS") {
+		var not = p.keyword("NOT")
+		if err = p.expectKeyword("NULL"); err != nil {
+			return
+		}
+		return &sqlIsNull{expr: expr, not: not}, nil
+	}
+	var not = p.keyword("NOT")
+	switch {
+	case p.keyword("LIKE"):
+		var like = &sqlLike{expr: expr, not: not}
+		if like.pattern, err = p.parseOperand(); err != nil {
+			return
+		}
+		if literal, is := like.pattern.(*sqlLiteral); is {
+			if pattern, is := literal.value.(string); is {
+				like.regexp = compileSQLLike(pattern)
+			}
+		}
+		return like, nil
+	case p.keyword("IN"):
+		var in = &sqlIn{expr: expr, not: not}
+		if err = p.expectOperator("("); err != nil {
+			return
+		}
+		for {
+			var item sqlExpr
+			if item, err = p.parseOperand(); err != nil {
+				return
+			}
+			in.list = append(in.list, item)
+			if !p.operator(",") {
+				break
+			}
+		}
+		if err = p.expectOperator(")"); err != nil {
+			return
+		}
+		return in, nil
+	case p.keyword("BETWEEN"):
+		var between = &sqlBetween{expr: expr, not: not}
+		if between.lower, err = p.parseOperand(); err != nil {
+			return
+		}
+		if err = p.expectKeyword("AND"); err != nil {
+			return
+		}
+		if between.upper, err = p.parseOperand(); err != nil {
+			return
+		}
+		return between, nil
+	}
+	if not {
+		return nil, p.unexpected()
+	}
+	return
+}
+
+func (p *sqlParser) parseOperand() (expr sqlExpr, err error) {
+	var token = p.peek()
+	switch {
+	case token.kind == sqlTokenNumber:
+		p.pos++
+		return parseSQLNumber(token, false)
+	case token.kind == sqlTokenString:
+		p.pos++
+		return &sqlLiteral{value: token.text}, nil
+	case token.kind == sqlTokenOperator && token.text == "-":
+		p.pos++
+		if number := p.next(); number.kind == sqlTokenNumber {
+			return parseSQLNumber(number, true)
+		}
+		return nil, errors.NewErrorf("expected number at position %v", token.pos+1)
+	case token.kind == sqlTokenOperator && token.text == "(":
+		p.pos++
+		if expr, err = p.parseOr(); err != nil {
+			return
+		}
+		if err = p.expectOperator(")"); err != nil {
+			return
+		}
+		return
+	case p.isKeyword(token, "NULL"):
+		p.pos++
+		return &sqlLiteral{value: nil}, nil
+	case p.isKeyword(token, "TRUE"), p.isKeyword(token, "FALSE"):
+		p.pos++
+		return &sqlLiteral{value: strings.EqualFold(token.text, "TRUE")}, nil
+	case p.isKeyword(token, "CAST"):
+		p.pos++
+		var cast = &sqlCast{}
+		if err = p.expectOperator("("); err != nil {
+			return
+		}
+		if cast.expr, err = p.parseOr(); err != nil {
+			return
+		}
+		if err = p.expectKeyword("AS"); err != nil {
+			return
+		}
+		var typeToken = p.next()
+		cast.typeName = strings.ToUpper(typeToken.text)
+		if typeToken.kind != sqlTokenIdent || !sqlCastTypes[cast.typeName] {
+			return nil, errors.NewErrorf("unsupported cast type %q at position %v", typeToken.text, typeToken.pos)
+		}
+		if err = p.expectOperator(")"); err != nil {
+			return
+		}
+		return cast, nil
+	}
+	return p.parseColumn()
+}
+
+func (p *sqlParser) parseColumn() (expr sqlExpr, err error) {
+	var column = &sqlColumn{scope: p.scope}
+	for {
+		var ident, ok = p.ident()
+		if !ok {
+			return nil, p.unexpected()
+		}
+		column.path = append(column.path, ident)
+		if !p.operator(".") {
+			return column, nil
+		}
+	}
+}
+
+func parseSQLNumber(token sqlToken, negative bool) (expr sqlExpr, err error) {
+	var text = token.text
+	if negative {
+		text = "-" + text
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return &sqlLiteral{value: i}, nil
+	}
+	var f float64
+	if f, err = strconv.ParseFloat(text, 64); err != nil {
+		return nil, errors.NewErrorf("invalid number %q at position %v", token.text, token.pos)
+	}
+	return &sqlLiteral{value: f}, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+type testSelectMessage struct {
+	headers map[string]string
+	payload []byte
+}
+
+// decodeTestSelectMessages decodes an event stream and verifies the CRCs of every message.
+func decodeTestSelectMessages(t *testing.T, data []byte) (messages []*testSelectMessage) {
+	for len(data) > 0 {
+		if len(data) < 16 {
+			t.Fatalf("truncated message: length(%v)", len(data))
+		}
+		var totalLength = int(binary.BigEndian.Uint32(data[0:4]))
+		var headerLength = int(binary.BigEndian.Uint32(data[4:8]))
+		if crc32.ChecksumIEEE(data[0:8]) != binary.BigEndian.Uint32(data[8:12]) {
+			t.Fatalf("prelude CRC mismatch")
+		}
+		if crc32.ChecksumIEEE(data[:totalLength-4]) != binary.BigEndian.Uint32(data[totalLength-4:totalLength]) {
+			t.Fatalf("message CRC mismatch")
+		}
+		var message = &testSelectMessage{headers: make(map[string]string)}
+		var headers = data[12 : 12+headerLength]
+		for len(headers) > 0 {
+			var nameLength = int(headers[0])
+			var name = string(headers[1 : 1+nameLength])
+			if headers[1+nameLength] != selectEventHeaderTypeString {
+				t.Fatalf("header value type mismatch: header(%v)", name)
+			}
+			var valueLength = int(binary.BigEndian.Uint16(headers[2+nameLength:]))
+			message.headers[name] = string(headers[4+nameLength : 4+nameLength+valueLength])
+			headers = headers[4+nameLength+valueLength:]
+		}
+		message.payload = data[12+headerLength : totalLength-4]
+		messages = append(messages, message)
+		data = data[totalLength:]
+	}
+	return
+}
+
+// runTestSelect executes the query and returns the records and the last event type.
+func runTestSelect(t *testing.T, request *SelectObjectContentRequest, data []byte) (records string, last *testSelectMessage) {
+	if errorCode := request.validate(); errorCode != nil {
+		t.Fatalf("invalid request: err(%v)", errorCode.ErrorMessage)
+	}
+	query, err := ParseSelectQuery(request.Expression)
+	if err != nil {
+		t.Fatalf("parse expression fail: expression(%v) err(%v)", request.Expression, err)
+	}
+	var output bytes.Buffer
+	_ = executeSelect(request, query, bytes.NewReader(data), &output)
+	var messages = decodeTestSelectMessages(t, output.Bytes())
+	for _, message := range messages {
+		if message.headers[":event-type"] == selectEventRecords {
+			records += string(message.payload)
+		}
+	}
+	return records, messages[len(messages)-1]
+}
+
+func newTestCSVSelectRequest(expression string) *SelectObjectContentRequest {
+	return &SelectObjectContentRequest{
+		Expression:          expression,
+		ExpressionType:      SelectExpressionTypeSQL,
+		InputSerialization:  SelectInputSerialization{CSV: &SelectCSVInput{FileHeaderInfo: SelectFileHeaderUse}},
+		OutputSerialization: SelectOutputSerialization{CSV: &SelectCSVOutput{}},
+	}
+}
+
+func TestSelect_ParseQuery(t *testing.T) {
+	var valids = []string{
+		"SELECT * FROM S3Object",
+		"select s.* from s3object s",
+		"SELECT s._1, s._3 AS total FROM S3Object AS s WHERE s._2 = 'a' LIMIT 10",
+		"SELECT name FROM S3Object[*] WHERE age >= 18 AND NOT (city IN ('x', 'y') OR city IS NULL)",
+		"SELECT \"Name\" FROM S3Object WHERE CAST(score AS INT) BETWEEN -1 AND 2.5 AND name NOT LIKE 'a%'",
+	}
+	for _, expression := range valids {
+		if _, err := ParseSelectQuery(expression); err != nil {
+			t.Fatalf("parse valid expression fail: expression(%v) err(%v)", expression, err)
+		}
+	}
+	var invalids = []string{
+		"",
+		"SELECT FROM S3Object",
+		"SELECT * FROM table",
+		"SELECT * FROM S3Object WHERE",
+		"SELECT * FROM S3Object WHERE a = 'b",
+		"SELECT * FROM S3Object LIMIT -1",
+		"SELECT * FROM S3Object LIMIT 1 OFFSET 2",
+		"SELECT CAST(a AS DATE) FROM S3Object",
+		"SELECT * FROM S3Object WHERE a NOT = 1",
+	}
+	for _, expression := range invalids {
+		if _, err := ParseSelectQuery(expression); err == nil {
+			t.Fatalf("parse invalid expression expect error: expression(%v)", expression)
+		}
+	}
+}
+
+func TestSelect_CSV(t *testing.T) {
+	var data = []byte("name,age,city\nalice,30,\"New York, NY\"\nbob,17,Paris\ncarol,45,Berlin\ndave,,Paris\n")
+	var cases = []struct {
+		expression string
+		expect     string
+	}{
+		{"SELECT * FROM S3Object", "alice,30,\"New York, NY\"\nbob,17,Paris\ncarol,45,Berlin\ndave,,Paris\n"},
+		{"SELECT name FROM S3Object WHERE age > 20", "alice\ncarol\n"},
+		{"SELECT s.name, s._3 FROM S3Object s WHERE s.city = 'Paris'", "bob,Paris\ndave,Paris\n"},
+		{"SELECT NAME FROM S3Object WHERE age BETWEEN 18 AND 40 OR city LIKE 'B%'", "alice\ncarol\n"},
+		{"SELECT name FROM S3Object WHERE age = ''", "dave\n"},
+		{"SELECT name FROM S3Object WHERE city NOT IN ('Paris', 'Berlin')", "alice\n"},
+		{"SELECT name FROM S3Object WHERE CAST(age AS INT) < 18", "bob\n"},
+		{"SELECT name FROM S3Object LIMIT 2", "alice\nbob\n"},
+		{"SELECT name FROM S3Object WHERE missing IS NULL LIMIT 1", "alice\n"},
+	}
+	for _, c := range cases {
+		records, last := runTestSelect(t, newTestCSVSelectRequest(c.expression), data)
+		if records != c.expect {
+			t.Fatalf("records mismatch: expression(%v) expect(%q) actual(%q)", c.expression, c.expect, records)
+		}
+		if last.headers[":event-type"] != selectEventEnd {
+			t.Fatalf("last event mismatch: expression(%v) headers(%v)", c.expression, last.headers)
+		}
+	}
+
+	// no header line, tab separated input and JSON output
+	var request = newTestCSVSelectRequest("SELECT _2 AS age, _1 FROM S3Object WHERE _2 >= 30")
+	request.InputSerialization.CSV = &SelectCSVInput{FieldDelimiter: "\t"}
+	request.OutputSerialization = SelectOutputSerialization{JSON: &SelectJSONOutput{}}
+	records, _ := runTestSelect(t, request, []byte("alice\t30\nbob\t17\n"))
+	if expect := "{\"age\":\"30\",\"_1\":\"alice\"}\n"; records != expect {
+		t.Fatalf("records mismatch: expect(%q) actual(%q)", expect, records)
+	}
+}
+
+func TestSelect_JSON(t *testing.T) {
+	var data = []byte(`{"name":"alice","age":30,"address":{"city":"Paris"},"tags":["a","b"]}
+{"name":"bob","age":17,"address":{"city":"Berlin"}}
+{"name":"carol","age":45.5,"active":true}
+`)
+	var cases = []struct {
+		expression string
+		expect     string
+	}{
+		{"SELECT * FROM S3Object s WHERE s.age < 20", "{\"name\":\"bob\",\"age\":17,\"address\":{\"city\":\"Berlin\"}}\n"},
+		{"SELECT s.name, s.address.city FROM S3Object s WHERE s.address.city IS NOT NULL", "{\"name\":\"alice\",\"city\":\"Paris\"}\n{\"name\":\"bob\",\"city\":\"Berlin\"}\n"},
+		{"SELECT name FROM S3Object[*] WHERE active = TRUE", "{\"name\":\"carol\"}\n"},
+		{"SELECT name, age > 40 FROM S3Object WHERE age > 40.1", "{\"name\":\"carol\",\"_2\":true}\n"},
+	}
+	for _, c := range cases {
+		var request = &SelectObjectContentRequest{
+			Expression:          c.expression,
+			ExpressionType:      SelectExpressionTypeSQL,
+			InputSerialization:  SelectInputSerialization{JSON: &SelectJSONInput{Type: SelectJSONTypeLines}},
+			OutputSerialization: SelectOutputSerialization{JSON: &SelectJSONOutput{}},
+		}
+		if records, _ := runTestSelect(t, request, data); records != c.expect {
+			t.Fatalf("records mismatch: expression(%v) expect(%q) actual(%q)", c.expression, c.expect, records)
+		}
+	}
+}
+
+func TestSelect_Gzip(t *testing.T) {
+	var buffer bytes.Buffer
+	var writer = gzip.NewWriter(&buffer)
+	_, _ = writer.Write([]byte(strings.Repeat("a,1\nb,2\n", 1000)))
+	_ = writer.Close()
+
+	var request = newTestCSVSelectRequest("SELECT _1 FROM S3Object WHERE _2 = 2 LIMIT 3")
+	request.InputSerialization.CSV.FileHeaderInfo = SelectFileHeaderNone
+	request.InputSerialization.CompressionType = SelectCompressionGzip
+	if records, _ := runTestSelect(t, request, buffer.Bytes()); records != "b\nb\nb\n" {
+		t.Fatalf("records mismatch: actual(%q)", records)
+	}
+
+	// uncompressed data is reported with an error message
+	_, last := runTestSelect(t, request, []byte("a,1\n"))
+	if last.headers[":message-type"] != selectMessageTypeError || last.headers[":error-code"] != "InvalidCompressionFormat" {
+		t.Fatalf("error message mismatch: headers(%v)", last.headers)
+	}
+}
+
+func TestSelect_ParseRequest(t *testing.T) {
+	var valid = `<SelectObjectContentRequest>
+	<Expression>SELECT * FROM S3Object</Expression>
+	<ExpressionType>SQL</ExpressionType>
+	<InputSerialization><CompressionType>GZIP</CompressionType><CSV><FileHeaderInfo>USE</FileHeaderInfo></CSV></InputSerialization>
+	<OutputSerialization><JSON><RecordDelimiter>,</RecordDelimiter></JSON></OutputSerialization>
+	<RequestProgress><Enabled>true</Enabled></RequestProgress>
+</SelectObjectContentRequest>`
+	request, errorCode := parseSelectObjectContentRequest([]byte(valid))
+	if errorCode != nil {
+		t.Fatalf("parse valid request fail: err(%v)", errorCode.ErrorMessage)
+	}
+	if !request.gzipped() || request.InputSerialization.CSV == nil || request.OutputSerialization.JSON == nil ||
+		!request.RequestProgress.Enabled {
+		t.Fatalf("request mismatch: request(%v)", request)
+	}
+
+	var invalids = map[string]*ErrorCode{
+		`<SelectObjectContentRequest><ExpressionType>SQL</ExpressionType></SelectObjectContentRequest>`:                                                  MissingSelectExpression,
+		`<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>XPath</ExpressionType></SelectObjectContentRequest>`: InvalidSelectExpressionType,
+		`<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>
+			<InputSerialization><CompressionType>ZIP</CompressionType><CSV></CSV></InputSerialization>
+			<OutputSerialization><CSV></CSV></OutputSerialization></SelectObjectContentRequest>`: InvalidSelectCompression,
+		`<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>
+			<InputSerialization><CSV></CSV><JSON><Type>LINES</Type></JSON></InputSerialization>
+			<OutputSerialization><CSV></CSV></OutputSerialization></SelectObjectContentRequest>`: InvalidSelectRequest,
+		`<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>
+			<InputSerialization><JSON><Type>ARRAY</Type></JSON></InputSerialization>
+			<OutputSerialization><CSV></CSV></OutputSerialization></SelectObjectContentRequest>`: InvalidSelectRequest,
+	}
+	for data, expect := range invalids {
+		if _, errorCode = parseSelectObjectContentRequest([]byte(data)); errorCode != expect {
+			t.Fatalf("error code mismatch: request(%v) expect(%v) actual(%v)", data, expect, errorCode)
+		}
+	}
+}
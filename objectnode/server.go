@@ -16,12 +16,14 @@ package objectnode
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/chubaofs/chubaofs/util/config"
 	"github.com/chubaofs/chubaofs/util/exporter"
@@ -77,8 +79,130 @@ const (
 	// The configuration in the example will allow ObjectNode to automatically resolve "* .object.chubao.io".
 	configDomains = "domains"
 
+	// String array configuration item, used to configure the domain names of the website endpoints.
+	// The bucket with website configuration is served as a static website on "{bucket}.{domain}" to
+	// anonymous users. The website domains should be different from the domains of the API endpoints.
+	// Example:
+	//		{
+	//			"websiteDomains": [
+	//				"website.chubao.io"
+	//			]
+	//		}
+	configWebsiteDomains = "websiteDomains"
+
 	disabledActions               = "disabledActions"
 	configSignatureIgnoredActions = "signatureIgnoredActions"
+
+	// Integer type configuration item, used to configure the interval in seconds of the background lifecycle
+	// scanner, which expires objects and aborts incomplete multipart uploads by bucket lifecycle rules.
+	// The scanner is disabled if it is not set or set to 0. It is recommended to enable it on only one ObjectNode.
+	// Example:
+	//		{
+	//			"lifecycleScanInterval": 86400
+	//		}
+	configLifecycleScanInterval = "lifecycleScanInterval"
+
+	// Object array configuration item, used to configure the ObjectNodes of other clusters which host the
+	// destination buckets of bucket replication. The Account of a replication destination refers to the name
+	// of an endpoint, and it can be omitted if there is only one endpoint. It should be configured on all
+	// ObjectNodes since destinations are validated against it when the replication configuration is put.
+	// Example:
+	//		{
+	//			"replicationEndpoints": [
+	//				{
+	//					"name": "backup",
+	//					"endpoint": "object.backup.chubao.io",
+	//					"accessKey": "...",
+	//					"secretKey": "...",
+	//					"region": "backup"
+	//				}
+	//			]
+	//		}
+	configReplicationEndpoints = "replicationEndpoints"
+
+	// Integer type configuration item, used to configure the interval in seconds of the background replicator,
+	// which replicates the changed objects to the destination buckets by bucket replication rules.
+	// It is 60 seconds if it is not set, and the replicator is disabled on the ObjectNode if it is negative.
+	// The change log of each volume is consumed by one of the ObjectNodes running the replicator at a time,
+	// which must be enabled on at least one ObjectNode, otherwise the change logs are never cleaned up.
+	// Example:
+	//		{
+	//			"replicationInterval": 60
+	//		}
+	configReplicationInterval = "replicationInterval"
+
+	// Object array configuration item, used to configure the webhooks which receive the event notifications
+	// of buckets. A notification configuration refers to a target by ARN "arn:chubaofs:sqs::{name}:webhook".
+	// The optional authToken is sent as a bearer token, and the optional queueLimit limits the number of
+	// events queued for the target. An event is moved to the dead letter file in the queue directory of the
+	// target once it has been retried maxRetries times, 20 by default. It should be configured on all
+	// ObjectNodes since the events are published by the ObjectNode which serves the request.
+	// Example:
+	//		{
+	//			"notificationTargets": [
+	//				{
+	//					"name": "audit",
+	//					"endpoint": "http://audit.chubao.io/events",
+	//					"authToken": "...",
+	//					"queueLimit": 100000,
+	//					"maxRetries": 20
+	//				}
+	//			]
+	//		}
+	configNotificationTargets = "notificationTargets"
+
+	// String type configuration item, used to configure the directory of the on-disk queues of events
+	// which are to be delivered to the notification targets. It is required if any target is configured.
+	// Example:
+	//		{
+	//			"notificationQueueDir": "/cfs/objectnode/notification"
+	//		}
+	configNotificationQueueDir = "notificationQueueDir"
+
+	// Bool type configuration item, used to enforce the public access block on all buckets. If it is
+	// set, no bucket can be exposed to anonymous users whatever its ACL, policy or public access block
+	// configuration says.
+	// Example:
+	//		{
+	//			"enforcePublicAccessBlock": true
+	//		}
+	configEnforcePublicAccessBlock = "enforcePublicAccessBlock"
+
+	// String type configuration item, used to configure the secret key which encrypts the session
+	// tokens of the temporary credentials issued by the security token service (STS). The STS is
+	// disabled if it is not set. It must be the same on all ObjectNodes of the cluster.
+	// Example:
+	//		{
+	//			"stsSecretKey": "..."
+	//		}
+	configSTSSecretKey = "stsSecretKey"
+
+	// Integer type configuration item, used to configure the interval in seconds at which the buffered
+	// access log records are written into the target buckets of the bucket logging configurations.
+	// The default is 300 seconds.
+	// Example:
+	//		{
+	//			"accessLogInterval": 300
+	//		}
+	configAccessLogInterval = "accessLogInterval"
+
+	// String type configuration items, used to configure the ticket of the ObjectNode issued by the authnode,
+	// which is required to access the encrypted volumes. The client ID must be granted the access to the
+	// volumes in the authnode. The ticket hosts are separated by commas, and the ticket is disabled if they
+	// are not set. The optional enableHTTPS and certFile configure the connection to the authnode.
+	// Example:
+	//		{
+	//			"authClientID": "objectnode",
+	//			"authClientKey": "...",
+	//			"ticketHost": "authnode1.chubao.io:8080,authnode2.chubao.io:8080",
+	//			"enableHTTPS": true,
+	//			"certFile": "/cfs/objectnode/server.crt"
+	//		}
+	configAuthClientID  = "authClientID"
+	configAuthClientKey = "authClientKey"
+	configTicketHost    = "ticketHost"
+	configEnableHTTPS   = "enableHTTPS"
+	configCertFile      = "certFile"
 )
 
 // Default of configuration value
@@ -95,9 +219,16 @@ var (
 type ObjectNode struct {
 	domains    []string
 	wildcards  Wildcards
+	webDomains []string
+	webWilds   Wildcards
 	listen     string
 	region     string
 	httpServer *http.Server
+	lcScanner  *LifecycleScanner
+	replicator *Replicator
+	notifier   *Notifier
+	accessLog  *AccessLogger
+	qos        *QoSManager
 	vm         *VolumeManager
 	mc         *master.MasterClient
 	state      uint32
@@ -107,6 +238,9 @@ type ObjectNode struct {
 	signatureIgnoredActions proto.Actions // signature ignored actions
 	disabledActions         proto.Actions // disabled actions
 
+	enforcePublicAccessBlock bool          // enforce public access block on all buckets
+	sessionCodec             *SessionCodec // codec of STS session tokens, nil if STS is disabled
+
 	encodedRegion []byte
 
 	control common.Control
@@ -145,6 +279,14 @@ func (o *ObjectNode) loadConfig(cfg *config.Config) (err error) {
 	}
 	log.LogInfof("loadConfig: setup config: %v(%v)", configDomains, domains)
 
+	// parse website domain
+	webDomains := cfg.GetStringSlice(configWebsiteDomains)
+	o.webDomains = webDomains
+	if o.webWilds, err = NewWildcards(webDomains); err != nil {
+		return
+	}
+	log.LogInfof("loadConfig: setup config: %v(%v)", configWebsiteDomains, webDomains)
+
 	// parse master config
 	masters := cfg.GetStringSlice(configMasterAddr)
 	if len(masters) == 0 {
@@ -176,9 +318,101 @@ func (o *ObjectNode) loadConfig(cfg *config.Config) (err error) {
 	strict := cfg.GetBool(configStrict)
 	log.LogInfof("loadConfig: strict: %v", strict)
 
+	// parse authnode ticket config
+	var ticket *AuthnodeTicket
+	if hosts := cfg.GetString(configTicketHost); len(hosts) > 0 {
+		ticket = &AuthnodeTicket{ClientID: cfg.GetString(configAuthClientID)}
+		ticket.TicketHosts = strings.Split(hosts, ",")
+		ticket.ClientKey = cfg.GetString(configAuthClientKey)
+		ticket.EnableHTTPS = cfg.GetBool(configEnableHTTPS)
+		ticket.CertFile = cfg.GetString(configCertFile)
+		if len(ticket.ClientID) == 0 || len(ticket.ClientKey) == 0 {
+			return config.NewIllegalConfigError(configAuthClientID)
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v) %v(%v)", configAuthClientID, ticket.ClientID, configTicketHost, hosts)
+	}
+
 	o.mc = master.NewMasterClient(masters, false)
-	o.vm = NewVolumeManager(masters, strict)
+	o.vm = NewVolumeManager(masters, strict, ticket)
 	o.userStore = NewUserInfoStore(masters, strict)
+	o.qos = NewQoSManager()
+
+	// parse lifecycle scanner config
+	if interval := cfg.GetInt64(configLifecycleScanInterval); interval > 0 {
+		o.lcScanner = NewLifecycleScanner(o.vm, o.mc, time.Duration(interval)*time.Second)
+		log.LogInfof("loadConfig: setup config: %v(%v)", configLifecycleScanInterval, interval)
+	}
+
+	// parse replication config
+	var endpoints = make([]*ReplicationEndpoint, 0)
+	if raw := cfg.GetSlice(configReplicationEndpoints); len(raw) > 0 {
+		var encoded []byte
+		if encoded, err = json.Marshal(raw); err != nil {
+			return
+		}
+		if err = json.Unmarshal(encoded, &endpoints); err != nil {
+			return config.NewIllegalConfigError(configReplicationEndpoints)
+		}
+		for _, endpoint := range endpoints {
+			if len(endpoint.Name) == 0 || len(endpoint.Endpoint) == 0 {
+				return config.NewIllegalConfigError(configReplicationEndpoints)
+			}
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configReplicationEndpoints, len(endpoints))
+	}
+	var replicationInterval = defaultReplicationInterval
+	if interval := cfg.GetInt64(configReplicationInterval); interval != 0 {
+		replicationInterval = time.Duration(interval) * time.Second
+		log.LogInfof("loadConfig: setup config: %v(%v)", configReplicationInterval, interval)
+	}
+	o.replicator = NewReplicator(o.vm, o.mc, endpoints, replicationInterval)
+
+	// parse notification config
+	var targets = make([]*NotificationTarget, 0)
+	if raw := cfg.GetSlice(configNotificationTargets); len(raw) > 0 {
+		var encoded []byte
+		if encoded, err = json.Marshal(raw); err != nil {
+			return
+		}
+		if err = json.Unmarshal(encoded, &targets); err != nil {
+			return config.NewIllegalConfigError(configNotificationTargets)
+		}
+		for _, target := range targets {
+			if len(target.Name) == 0 || strings.ContainsAny(target.Name, ":/") || len(target.Endpoint) == 0 {
+				return config.NewIllegalConfigError(configNotificationTargets)
+			}
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configNotificationTargets, len(targets))
+	}
+	var queueDir = cfg.GetString(configNotificationQueueDir)
+	if len(targets) > 0 && len(queueDir) == 0 {
+		return config.NewIllegalConfigError(configNotificationQueueDir)
+	}
+	if o.notifier, err = NewNotifier(queueDir, targets); err != nil {
+		return
+	}
+	if len(queueDir) > 0 {
+		log.LogInfof("loadConfig: setup config: %v(%v)", configNotificationQueueDir, queueDir)
+	}
+
+	// parse public access block config
+	o.enforcePublicAccessBlock = cfg.GetBool(configEnforcePublicAccessBlock)
+	log.LogInfof("loadConfig: setup config: %v(%v)", configEnforcePublicAccessBlock, o.enforcePublicAccessBlock)
+
+	// parse STS config
+	if secretKey := cfg.GetString(configSTSSecretKey); len(secretKey) > 0 {
+		if o.sessionCodec, err = NewSessionCodec(secretKey); err != nil {
+			return
+		}
+		log.LogInfof("loadConfig: setup config: %v", configSTSSecretKey)
+	}
+
+	// parse access log config
+	var accessLogInterval = cfg.GetInt64(configAccessLogInterval)
+	o.accessLog = NewAccessLogger(o.vm, time.Duration(accessLogInterval)*time.Second)
+	if accessLogInterval > 0 {
+		log.LogInfof("loadConfig: setup config: %v(%v)", configAccessLogInterval, accessLogInterval)
+	}
 
 	return
 }
@@ -205,6 +439,7 @@ func handleStart(s common.Server, cfg *config.Config) (err error) {
 		return
 	}
 	o.updateRegion(ci.Cluster)
+	o.notifier.region = o.region
 	log.LogInfof("handleStart: get cluster information: region(%v)", o.region)
 
 	// start rest api
@@ -213,6 +448,22 @@ func handleStart(s common.Server, cfg *config.Config) (err error) {
 		return
 	}
 
+	// start lifecycle scanner
+	if o.lcScanner != nil {
+		o.lcScanner.Start()
+	}
+
+	// start replicator
+	if o.replicator.interval > 0 {
+		o.replicator.Start()
+	}
+
+	// start notifier
+	o.notifier.Start()
+
+	// start access logger
+	o.accessLog.Start()
+
 	exporter.Init(cfg.GetString("role"), cfg)
 	exporter.RegistConsul(ci.Cluster, cfg.GetString("role"), cfg)
 
@@ -226,6 +477,12 @@ func handleShutdown(s common.Server) {
 		return
 	}
 	o.shutdownRestAPI()
+	if o.lcScanner != nil {
+		o.lcScanner.Stop()
+	}
+	o.replicator.Stop()
+	o.notifier.Stop()
+	o.accessLog.Stop()
 }
 
 func (o *ObjectNode) startMuxRestAPI() (err error) {
@@ -236,6 +493,7 @@ func (o *ObjectNode) startMuxRestAPI() (err error) {
 		o.corsMiddleware,
 		o.traceMiddleware,
 		o.authMiddleware,
+		o.qosMiddleware,
 		o.policyCheckMiddleware,
 		o.contentMiddleware,
 	)
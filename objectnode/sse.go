@@ -0,0 +1,203 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/serv-side-encryption.html
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+// The data of an encrypted volume is encrypted by the data SDK with the key of the volume (SSE-S3), so the
+// encryption configuration of a bucket follows the volume and can neither be enabled nor disabled by S3 APIs.
+type ServerSideEncryptionConfiguration struct {
+	XMLName xml.Name                    `xml:"ServerSideEncryptionConfiguration"`
+	Rules   []*ServerSideEncryptionRule `xml:"Rule"`
+}
+
+type ServerSideEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault *ServerSideEncryptionByDefault `xml:"ApplyServerSideEncryptionByDefault"`
+}
+
+type ServerSideEncryptionByDefault struct {
+	SSEAlgorithm   string `xml:"SSEAlgorithm"`
+	KMSMasterKeyID string `xml:"KMSMasterKeyID,omitempty"`
+}
+
+func NewServerSideEncryptionConfiguration() *ServerSideEncryptionConfiguration {
+	return &ServerSideEncryptionConfiguration{
+		Rules: []*ServerSideEncryptionRule{
+			{ApplyServerSideEncryptionByDefault: &ServerSideEncryptionByDefault{SSEAlgorithm: SSEAlgorithmAES256}},
+		},
+	}
+}
+
+func parseEncryptionConfig(bytes []byte) (config *ServerSideEncryptionConfiguration, err error) {
+	config = &ServerSideEncryptionConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return
+	}
+	if len(config.Rules) != 1 || config.Rules[0].ApplyServerSideEncryptionByDefault == nil {
+		return nil, errors.New("invalid server side encryption configuration")
+	}
+	return
+}
+
+// The x-amz-server-side-encryption header of a write request is accepted only if the volume is encrypted.
+func checkServerSideEncryption(header http.Header, vol *Volume) *ErrorCode {
+	switch header.Get(HeaderNameXAmzServerSideEncryption) {
+	case "":
+		return nil
+	case SSEAlgorithmAES256:
+		if !vol.Encrypted() {
+			return ServerSideEncryptionNotEnabled
+		}
+		return nil
+	case SSEAlgorithmKMS:
+		return NotImplemented
+	default:
+		return InvalidEncryptionAlgorithm
+	}
+}
+
+// SSECustomerKey is the key provided by the client to encrypt the object (SSE-C). The key is never stored,
+// only its MD5 digest is kept with the object to verify the key provided by the following requests.
+type SSECustomerKey struct {
+	Key    []byte
+	KeyMD5 string // base64 encoded MD5 digest of the key
+}
+
+// ParseSSECustomerKey parses the SSE-C headers of the request, it returns nil if the headers are absent.
+func ParseSSECustomerKey(header http.Header) (*SSECustomerKey, *ErrorCode) {
+	var (
+		algorithm = header.Get(HeaderNameXAmzSSECustomerAlgorithm)
+		encoded   = header.Get(HeaderNameXAmzSSECustomerKey)
+		keyMD5    = header.Get(HeaderNameXAmzSSECustomerKeyMD5)
+	)
+	if algorithm == "" && encoded == "" && keyMD5 == "" {
+		return nil, nil
+	}
+	if algorithm != SSEAlgorithmAES256 {
+		return nil, InvalidEncryptionAlgorithm
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, InvalidSSECustomerKey
+	}
+	digest := md5.Sum(key)
+	if keyMD5 != base64.StdEncoding.EncodeToString(digest[:]) {
+		return nil, SSECustomerKeyMD5Mismatch
+	}
+	return &SSECustomerKey{Key: key, KeyMD5: keyMD5}, nil
+}
+
+// The object encrypted with the customer key is readable only with the same key, and the key is required.
+func checkSSECustomerKey(key *SSECustomerKey, fileInfo *FSFileInfo) *ErrorCode {
+	if len(fileInfo.SSECustomerKeyMD5) == 0 {
+		if key != nil {
+			return SSECustomerKeyNotApplicable
+		}
+		return nil
+	}
+	if key == nil {
+		return SSECustomerKeyRequired
+	}
+	if key.KeyMD5 != fileInfo.SSECustomerKeyMD5 {
+		return AccessDenied
+	}
+	return nil
+}
+
+func newSSECustomerIV() (iv []byte, err error) {
+	iv = make([]byte, aes.BlockSize)
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// NewStream returns the AES-CTR key stream of the object positioned at the offset, the random IV of the
+// object is the initial counter block.
+func (key *SSECustomerKey) NewStream(iv []byte, offset uint64) (stream cipher.Stream, err error) {
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid SSE-C IV")
+	}
+	var block cipher.Block
+	if block, err = aes.NewCipher(key.Key); err != nil {
+		return
+	}
+	var (
+		counter = make([]byte, aes.BlockSize)
+		high    = binary.BigEndian.Uint64(iv[:8])
+		low     = binary.BigEndian.Uint64(iv[8:])
+		blocks  = offset / aes.BlockSize
+	)
+	if low+blocks < low {
+		high++
+	}
+	binary.BigEndian.PutUint64(counter[:8], high)
+	binary.BigEndian.PutUint64(counter[8:], low+blocks)
+	stream = cipher.NewCTR(block, counter)
+	if skip := offset % aes.BlockSize; skip > 0 {
+		var discard [aes.BlockSize]byte
+		stream.XORKeyStream(discard[:skip], discard[:skip])
+	}
+	return
+}
+
+// sseCustomerWriter decrypts the data of the object encrypted with the customer key before writing it.
+type sseCustomerWriter struct {
+	writer io.Writer
+	stream cipher.Stream
+	buf    []byte
+}
+
+func newSSECustomerWriter(writer io.Writer, key *SSECustomerKey, iv []byte, offset uint64) (*sseCustomerWriter, error) {
+	stream, err := key.NewStream(iv, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &sseCustomerWriter{writer: writer, stream: stream}, nil
+}
+
+func (w *sseCustomerWriter) Write(p []byte) (n int, err error) {
+	if cap(w.buf) < len(p) {
+		w.buf = make([]byte, len(p))
+	}
+	var buf = w.buf[:len(p)]
+	w.stream.XORKeyStream(buf, p)
+	return w.writer.Write(buf)
+}
+
+// setServerSideEncryptionHeaders sets the encryption headers of the response about the object.
+func setServerSideEncryptionHeaders(w http.ResponseWriter, vol *Volume, sseCustomerKeyMD5 string) {
+	if vol.Encrypted() {
+		w.Header()[HeaderNameXAmzServerSideEncryption] = []string{SSEAlgorithmAES256}
+	}
+	if len(sseCustomerKeyMD5) > 0 {
+		w.Header()[HeaderNameXAmzSSECustomerAlgorithm] = []string{SSEAlgorithmAES256}
+		w.Header()[HeaderNameXAmzSSECustomerKeyMD5] = []string{sseCustomerKeyMD5}
+	}
+}
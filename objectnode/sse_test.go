@@ -0,0 +1,103 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestSSECustomerKey_Parse(t *testing.T) {
+	var key = make([]byte, 32)
+	_, _ = rand.Read(key)
+	var digest = md5.Sum(key)
+	var header = make(http.Header)
+	if parsed, errorCode := ParseSSECustomerKey(header); parsed != nil || errorCode != nil {
+		t.Fatalf("parse absent headers: key(%v) errorCode(%v)", parsed, errorCode)
+	}
+
+	header.Set(HeaderNameXAmzSSECustomerAlgorithm, SSEAlgorithmAES256)
+	header.Set(HeaderNameXAmzSSECustomerKey, base64.StdEncoding.EncodeToString(key))
+	header.Set(HeaderNameXAmzSSECustomerKeyMD5, base64.StdEncoding.EncodeToString(digest[:]))
+	parsed, errorCode := ParseSSECustomerKey(header)
+	if errorCode != nil || !bytes.Equal(parsed.Key, key) {
+		t.Fatalf("parse valid headers: key(%v) errorCode(%v)", parsed, errorCode)
+	}
+
+	header.Set(HeaderNameXAmzSSECustomerKeyMD5, base64.StdEncoding.EncodeToString(key[:16]))
+	if _, errorCode = ParseSSECustomerKey(header); errorCode != SSECustomerKeyMD5Mismatch {
+		t.Fatalf("parse mismatched key MD5: errorCode(%v)", errorCode)
+	}
+	header.Set(HeaderNameXAmzSSECustomerKey, base64.StdEncoding.EncodeToString(key[:16]))
+	if _, errorCode = ParseSSECustomerKey(header); errorCode != InvalidSSECustomerKey {
+		t.Fatalf("parse short key: errorCode(%v)", errorCode)
+	}
+	header.Set(HeaderNameXAmzSSECustomerAlgorithm, SSEAlgorithmKMS)
+	if _, errorCode = ParseSSECustomerKey(header); errorCode != InvalidEncryptionAlgorithm {
+		t.Fatalf("parse invalid algorithm: errorCode(%v)", errorCode)
+	}
+}
+
+func TestSSECustomerKey_StreamOffset(t *testing.T) {
+	var key = &SSECustomerKey{Key: make([]byte, 32)}
+	_, _ = rand.Read(key.Key)
+	var plain = make([]byte, 4096)
+	_, _ = rand.Read(plain)
+
+	var ivs = [][]byte{make([]byte, 16), bytes.Repeat([]byte{math.MaxUint8}, 16)}
+	_, _ = rand.Read(ivs[0])
+	for _, iv := range ivs {
+		stream, err := key.NewStream(iv, 0)
+		if err != nil {
+			t.Fatalf("new stream fail: err(%v)", err)
+		}
+		var encrypted = make([]byte, len(plain))
+		stream.XORKeyStream(encrypted, plain)
+
+		// decrypting any range of the object must be consistent with the whole object
+		for _, offset := range []uint64{0, 1, 15, 16, 17, 1000, 4095} {
+			var decrypted = new(bytes.Buffer)
+			writer, err := newSSECustomerWriter(decrypted, key, iv, offset)
+			if err != nil {
+				t.Fatalf("new writer fail: err(%v)", err)
+			}
+			_, _ = writer.Write(encrypted[offset:])
+			if !bytes.Equal(decrypted.Bytes(), plain[offset:]) {
+				t.Fatalf("decrypted data mismatch: iv(%x) offset(%v)", iv, offset)
+			}
+		}
+	}
+}
+
+func TestEncryption_Parse(t *testing.T) {
+	var valid = `<ServerSideEncryptionConfiguration>
+	<Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>AES256</SSEAlgorithm></ApplyServerSideEncryptionByDefault></Rule>
+</ServerSideEncryptionConfiguration>`
+	config, err := parseEncryptionConfig([]byte(valid))
+	if err != nil {
+		t.Fatalf("parse encryption configuration fail: err(%v)", err)
+	}
+	if algorithm := config.Rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm; algorithm != SSEAlgorithmAES256 {
+		t.Fatalf("algorithm mismatch: expect(%v) actual(%v)", SSEAlgorithmAES256, algorithm)
+	}
+	if _, err = parseEncryptionConfig([]byte(`<ServerSideEncryptionConfiguration></ServerSideEncryptionConfiguration>`)); err == nil {
+		t.Fatalf("parse configuration without rule should fail")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// The archive classes of Amazon S3 are mapped to the cold class, the other classes of Amazon S3 are stored in the
+// standard class, so that the requests of the S3 clients specifying them are not rejected.
+var (
+	coldStorageClassAliases     = []string{"GLACIER", "GLACIER_IR", "DEEP_ARCHIVE"}
+	standardStorageClassAliases = []string{"REDUCED_REDUNDANCY", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "OUTPOSTS"}
+)
+
+// ParseStorageClass parses the storage class specified by the 'x-amz-storage-class' header, the name of the
+// class is case insensitive and the standard class is used if the header is absent. The cold class is only
+// valid if the cold data partitions are enabled on the volume, while the archive classes of Amazon S3 fall
+// back to the standard class if they are not.
+func ParseStorageClass(header http.Header, coldStorageEnabled bool) (storageClass string, errorCode *ErrorCode) {
+	var value = header.Get(HeaderNameXAmzStorageClass)
+	var matchAny = func(aliases []string) bool {
+		for _, alias := range aliases {
+			if strings.EqualFold(value, alias) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case value == "" || strings.EqualFold(value, StorageClassStandard) || matchAny(standardStorageClassAliases):
+		return StorageClassStandard, nil
+	case strings.EqualFold(value, StorageClassCold) && coldStorageEnabled:
+		return StorageClassCold, nil
+	case matchAny(coldStorageClassAliases):
+		if coldStorageEnabled {
+			return StorageClassCold, nil
+		}
+		return StorageClassStandard, nil
+	default:
+		return "", InvalidStorageClass
+	}
+}
+
+// objectStorageClass returns the storage class of the object shown in the responses,
+// the objects without the storage class attribute are of the standard class.
+func objectStorageClass(info *FSFileInfo) string {
+	if len(info.StorageClass) == 0 {
+		return StorageClassStandard
+	}
+	return info.StorageClass
+}
+
+// The storage class header is only returned for the objects which are not of the standard class.
+func setStorageClassHeader(w http.ResponseWriter, info *FSFileInfo) {
+	if storageClass := objectStorageClass(info); storageClass != StorageClassStandard {
+		w.Header()[HeaderNameXAmzStorageClass] = []string{storageClass}
+	}
+}
+
+func (v *Volume) inodeStorageClass(inode uint64) (storageClass string, err error) {
+	var xattr *proto.XAttrInfo
+	if xattr, err = v.mw.XAttrGet_ll(inode, XAttrKeyOSSStorageClass); err != nil {
+		return
+	}
+	if storageClass = string(xattr.Get(XAttrKeyOSSStorageClass)); len(storageClass) == 0 {
+		storageClass = StorageClassStandard
+	}
+	return
+}
+
+// TransitionObject moves the data of the object into the cold data partitions of the volume and marks
+// the object as the cold storage class. The transition fails if the object is modified meanwhile.
+func (v *Volume) TransitionObject(path string) (err error) {
+	var inode uint64
+	var mode os.FileMode
+	if _, inode, _, mode, err = v.recursiveLookupTarget(path); err != nil {
+		return
+	}
+	if mode.IsDir() {
+		return syscall.EISDIR
+	}
+	return v.transitionInode(inode)
+}
+
+func (v *Volume) transitionInode(inode uint64) (err error) {
+	if !v.ec.ColdStorageEnabled() {
+		return syscall.ENOTSUP
+	}
+	if err = v.ec.MigrateToCold(inode); err != nil {
+		log.LogErrorf("transitionInode: migrate to cold fail: volume(%v) inode(%v) err(%v)", v.name, inode, err)
+		return
+	}
+	if err = v.mw.XAttrSet_ll(inode, []byte(XAttrKeyOSSStorageClass), []byte(StorageClassCold)); err != nil {
+		log.LogErrorf("transitionInode: store storage class fail: volume(%v) inode(%v) err(%v)", v.name, inode, err)
+		return
+	}
+	log.LogInfof("transitionInode: volume(%v) inode(%v) storage class(%v)", v.name, inode, StorageClassCold)
+	return
+}
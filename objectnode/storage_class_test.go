@@ -0,0 +1,66 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseStorageClass(t *testing.T) {
+	var cases = []struct {
+		value        string
+		coldEnabled  bool
+		storageClass string
+		errorCode    *ErrorCode
+	}{
+		{"", false, StorageClassStandard, nil},
+		{"STANDARD", false, StorageClassStandard, nil},
+		{"Standard", true, StorageClassStandard, nil},
+		{"STANDARD_IA", false, StorageClassStandard, nil},
+		{"onezone_ia", true, StorageClassStandard, nil},
+		{"COLD", true, StorageClassCold, nil},
+		{"cold", false, "", InvalidStorageClass},
+		{"GLACIER", true, StorageClassCold, nil},
+		{"DEEP_ARCHIVE", true, StorageClassCold, nil},
+		{"GLACIER", false, StorageClassStandard, nil},
+		{"UNKNOWN", true, "", InvalidStorageClass},
+	}
+	for _, c := range cases {
+		var header = make(http.Header)
+		if c.value != "" {
+			header.Set(HeaderNameXAmzStorageClass, c.value)
+		}
+		storageClass, errorCode := ParseStorageClass(header, c.coldEnabled)
+		if storageClass != c.storageClass || errorCode != c.errorCode {
+			t.Fatalf("parse storage class %v (cold enabled %v) mismatch: expect(%v %v) actual(%v %v)",
+				c.value, c.coldEnabled, c.storageClass, c.errorCode, storageClass, errorCode)
+		}
+	}
+}
+
+func TestStorageClassHeader(t *testing.T) {
+	var w = httptest.NewRecorder()
+	setStorageClassHeader(w, &FSFileInfo{})
+	if value := w.Header().Get(HeaderNameXAmzStorageClass); value != "" {
+		t.Fatalf("unexpected storage class header of standard object: %v", value)
+	}
+	w = httptest.NewRecorder()
+	setStorageClassHeader(w, &FSFileInfo{StorageClass: StorageClassCold})
+	if value := w.Header()[HeaderNameXAmzStorageClass]; len(value) != 1 || value[0] != StorageClassCold {
+		t.Fatalf("storage class header mismatch: expect(%v) actual(%v)", StorageClassCold, value)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+// The security token service (STS) issues temporary credentials, which consist of an access key,
+// a secret key and a session token. The requests signed by the temporary credentials carry the
+// session token in header or query X-Amz-Security-Token, and act as the user who the credentials
+// are issued for, but only what the session policy allows if there is one.
+//
+// The session token is self-contained: it is the temporary credentials encrypted by the STS secret
+// key of the ObjectNodes, so any ObjectNode configured with the same secret key can validate it
+// without storing any session.
+// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/welcome.html
+
+const (
+	STSActionAssumeRole      = "AssumeRole"
+	STSActionGetSessionToken = "GetSessionToken"
+
+	STSParamAction          = "Action"
+	STSParamRoleArn         = "RoleArn"
+	STSParamRoleSessionName = "RoleSessionName"
+	STSParamPolicy          = "Policy"
+	STSParamDurationSeconds = "DurationSeconds"
+
+	STSResponseXmlns = "https://sts.amazonaws.com/doc/2011-06-15/"
+
+	MinSessionDuration          = 15 * time.Minute
+	MaxAssumeRoleDuration       = 12 * time.Hour
+	MaxSessionTokenDuration     = 36 * time.Hour
+	DefaultAssumeRoleDuration   = time.Hour
+	DefaultSessionTokenDuration = 12 * time.Hour
+	MaxSessionPolicySize        = 2048
+	MinRoleSessionNameLength    = 2
+	MaxRoleSessionNameLength    = 64
+
+	sessionAccessKeyPrefix       = "TMP"
+	sessionAccessKeyRandomLength = 13
+	sessionSecretKeyLength       = 32
+	sessionCredentialCharacters  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	errInvalidSessionToken = errors.New("invalid session token")
+	errInvalidRoleArn      = errors.New("invalid role ARN")
+)
+
+// Session is the temporary credentials issued by the STS, which is encoded into the session token.
+type Session struct {
+	AccessKey       string  `json:"ak"`
+	SecretKey       string  `json:"sk"`
+	ParentAccessKey string  `json:"pak"`            // access key of the user who the credentials are issued for
+	Name            string  `json:"name,omitempty"` // role session name
+	Expiration      int64   `json:"exp"`
+	Policy          *Policy `json:"policy,omitempty"`
+}
+
+func (s *Session) IsExpired() bool {
+	return time.Now().Unix() >= s.Expiration
+}
+
+type STSCredentials struct {
+	AccessKeyId     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+	Expiration      string `xml:"Expiration"`
+}
+
+type AssumedRoleUser struct {
+	Arn           string `xml:"Arn"`
+	AssumedRoleId string `xml:"AssumedRoleId"`
+}
+
+type STSResponseMetadata struct {
+	RequestId string `xml:"RequestId"`
+}
+
+type AssumeRoleResponse struct {
+	XMLName          xml.Name            `xml:"AssumeRoleResponse"`
+	Xmlns            string              `xml:"xmlns,attr"`
+	Credentials      STSCredentials      `xml:"AssumeRoleResult>Credentials"`
+	AssumedRoleUser  AssumedRoleUser     `xml:"AssumeRoleResult>AssumedRoleUser"`
+	ResponseMetadata STSResponseMetadata `xml:"ResponseMetadata"`
+}
+
+type GetSessionTokenResponse struct {
+	XMLName          xml.Name            `xml:"GetSessionTokenResponse"`
+	Xmlns            string              `xml:"xmlns,attr"`
+	Credentials      STSCredentials      `xml:"GetSessionTokenResult>Credentials"`
+	ResponseMetadata STSResponseMetadata `xml:"ResponseMetadata"`
+}
+
+// SessionCodec encrypts the temporary credentials into session tokens and decrypts them with AES-GCM.
+type SessionCodec struct {
+	aead cipher.AEAD
+}
+
+func NewSessionCodec(secretKey string) (codec *SessionCodec, err error) {
+	var key = sha256.Sum256([]byte(secretKey))
+	var block cipher.Block
+	if block, err = aes.NewCipher(key[:]); err != nil {
+		return
+	}
+	var aead cipher.AEAD
+	if aead, err = cipher.NewGCM(block); err != nil {
+		return
+	}
+	return &SessionCodec{aead: aead}, nil
+}
+
+func (c *SessionCodec) Encode(session *Session) (token string, err error) {
+	var plaintext []byte
+	if plaintext, err = json.Marshal(session); err != nil {
+		return
+	}
+	var nonce = make([]byte, c.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	var sealed = c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (c *SessionCodec) Decode(token string) (session *Session, err error) {
+	var sealed []byte
+	if sealed, err = base64.RawURLEncoding.DecodeString(token); err != nil {
+		return nil, errInvalidSessionToken
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errInvalidSessionToken
+	}
+	var nonce, ciphertext = sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	var plaintext []byte
+	if plaintext, err = c.aead.Open(nil, nonce, ciphertext, nil); err != nil {
+		return nil, errInvalidSessionToken
+	}
+	session = &Session{}
+	if err = json.Unmarshal(plaintext, session); err != nil {
+		return nil, errInvalidSessionToken
+	}
+	return session, nil
+}
+
+// NewSession generates random temporary credentials of the user, which expire after the duration.
+func NewSession(parentAccessKey string, duration time.Duration) (session *Session, err error) {
+	session = &Session{
+		ParentAccessKey: parentAccessKey,
+		Expiration:      time.Now().Add(duration).Unix(),
+	}
+	var random string
+	if random, err = randomCredential(sessionAccessKeyRandomLength); err != nil {
+		return
+	}
+	session.AccessKey = sessionAccessKeyPrefix + strings.ToUpper(random)
+	if session.SecretKey, err = randomCredential(sessionSecretKeyLength); err != nil {
+		return
+	}
+	return
+}
+
+func randomCredential(length int) (str string, err error) {
+	var sb = strings.Builder{}
+	var max = big.NewInt(int64(len(sessionCredentialCharacters)))
+	for i := 0; i < length; i++ {
+		var n *big.Int
+		if n, err = rand.Int(rand.Reader, max); err != nil {
+			return
+		}
+		sb.WriteByte(sessionCredentialCharacters[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// getRequestSessionToken returns the session token of the request signed by temporary credentials.
+func getRequestSessionToken(r *http.Request) string {
+	if token := r.Header.Get(HeaderNameXAmzSecurityToken); len(token) > 0 {
+		return token
+	}
+	return r.URL.Query().Get(XAmzSecurityToken)
+}
+
+// parseRoleArn parses the user ID from the ARN of the role to be assumed.
+// Each user is a role which can be assumed by the user itself, or by root and admin users, and the
+// ARN is formatted as arn:PARTITION:iam::USER_ID:role/NAME.
+func parseRoleArn(arn string) (userID string, err error) {
+	var parts = strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || len(parts[4]) == 0 ||
+		!strings.HasPrefix(parts[5], "role/") {
+		return "", errInvalidRoleArn
+	}
+	return parts[4], nil
+}
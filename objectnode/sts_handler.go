@@ -0,0 +1,310 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	STSService        = "sts"
+	MaxSTSRequestSize = 16 * 1024
+)
+
+// Security token service
+// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_Operations.html
+func (o *ObjectNode) stsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	if o.sessionCodec == nil {
+		errorCode = STSDisabled
+		return
+	}
+
+	var body []byte
+	if body, err = ioutil.ReadAll(io.LimitReader(r.Body, MaxSTSRequestSize+1)); err != nil {
+		log.LogErrorf("stsHandler: read request body fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if len(body) > MaxSTSRequestSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	var form url.Values
+	if form, err = url.ParseQuery(string(body)); err != nil {
+		log.LogErrorf("stsHandler: parse request form fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InvalidSTSParameter
+		return
+	}
+
+	var userInfo *proto.UserInfo
+	if userInfo, errorCode = o.authenticateSTSRequest(r, body); errorCode != nil {
+		return
+	}
+
+	switch action := form.Get(STSParamAction); action {
+	case STSActionAssumeRole:
+		errorCode = o.assumeRole(w, r, userInfo, form)
+	case STSActionGetSessionToken:
+		errorCode = o.getSessionToken(w, r, userInfo, form)
+	default:
+		log.LogDebugf("stsHandler: invalid action: requestID(%v) action(%v)", GetRequestID(r), action)
+		errorCode = InvalidSTSAction
+	}
+	return
+}
+
+// authenticateSTSRequest validates the signature V4 of the STS request, which must be signed by the
+// permanent credentials of a user, and returns the user.
+func (o *ObjectNode) authenticateSTSRequest(r *http.Request, body []byte) (userInfo *proto.UserInfo, errorCode *ErrorCode) {
+	if !isHeaderUsingSignatureAlgorithmV4(r) {
+		return nil, AccessDenied
+	}
+	var err error
+	var req *signatureRequestV4
+	if req, err = parseRequestV4(r); err != nil {
+		log.LogDebugf("authenticateSTSRequest: parse signature fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		return nil, AccessDenied
+	}
+	var signatureTime time.Time
+	if signatureTime, err = time.Parse(DateFormatISO8601, getStartTime(r.Header)); err != nil {
+		return nil, AccessDenied
+	}
+	if skew := time.Since(signatureTime); skew > MaxSkewTime || skew < -MaxSkewTime {
+		log.LogDebugf("authenticateSTSRequest: request time too skewed: requestID(%v) time(%v)",
+			GetRequestID(r), signatureTime)
+		return nil, RequestTimeTooSkewed
+	}
+
+	if userInfo, err = o.getUserInfoByAccessKey(req.Credential.AccessKey); err != nil {
+		log.LogDebugf("authenticateSTSRequest: load user fail: requestID(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), req.Credential.AccessKey, err)
+		if err == proto.ErrUserNotExists || err == proto.ErrAccessKeyNotExists {
+			return nil, AccessDenied
+		}
+		return nil, InternalErrorCode(err)
+	}
+
+	var contentHash = getContentHash(r.Header)
+	if len(contentHash) == 0 {
+		var sum = sha256.Sum256(body)
+		contentHash = hex.EncodeToString(sum[:])
+	}
+	var signature = calculateSignatureV4(r, req.Credential, userInfo.SecretKey, req.SignedHeaders, req.Credential.Service, contentHash)
+	if req.Credential.Service != STSService || signature != req.Signature {
+		log.LogDebugf("authenticateSTSRequest: invalid signature: requestID(%v) service(%v) client(%v) server(%v)",
+			GetRequestID(r), req.Credential.Service, req.Signature, signature)
+		return nil, SignatureDoesNotMatch
+	}
+	return userInfo, nil
+}
+
+// Assume role
+// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+func (o *ObjectNode) assumeRole(w http.ResponseWriter, r *http.Request, userInfo *proto.UserInfo, form url.Values) *ErrorCode {
+	var err error
+	var roleUserID string
+	if roleUserID, err = parseRoleArn(form.Get(STSParamRoleArn)); err != nil {
+		return InvalidSTSParameter
+	}
+	var sessionName = form.Get(STSParamRoleSessionName)
+	if len(sessionName) < MinRoleSessionNameLength || len(sessionName) > MaxRoleSessionNameLength {
+		return InvalidSTSParameter
+	}
+	var duration time.Duration
+	var errorCode *ErrorCode
+	if duration, errorCode = parseSessionDuration(form, DefaultAssumeRoleDuration, MaxAssumeRoleDuration); errorCode != nil {
+		return errorCode
+	}
+	var policy *Policy
+	if policy, errorCode = parseSessionPolicy(form); errorCode != nil {
+		return errorCode
+	}
+
+	// Users can assume their own roles, and root and admin users can assume the roles of any user.
+	var roleUser = userInfo
+	if roleUserID != userInfo.UserID {
+		if userInfo.UserType != proto.UserTypeRoot && userInfo.UserType != proto.UserTypeAdmin {
+			log.LogWarnf("assumeRole: assume role of other user denied: requestID(%v) userID(%v) role(%v)",
+				GetRequestID(r), userInfo.UserID, roleUserID)
+			return AccessDenied
+		}
+		if roleUser, err = o.mc.UserAPI().GetUserInfo(roleUserID); err != nil {
+			log.LogErrorf("assumeRole: load role user fail: requestID(%v) role(%v) err(%v)",
+				GetRequestID(r), roleUserID, err)
+			if err == proto.ErrUserNotExists {
+				return InvalidSTSParameter
+			}
+			return InternalErrorCode(err)
+		}
+	}
+
+	var session *Session
+	if session, err = NewSession(roleUser.AccessKey, duration); err != nil {
+		return InternalErrorCode(err)
+	}
+	session.Name = sessionName
+	session.Policy = policy
+	var credentials *STSCredentials
+	if credentials, err = o.sessionCredentials(session); err != nil {
+		return InternalErrorCode(err)
+	}
+
+	var response = &AssumeRoleResponse{
+		Xmlns:       STSResponseXmlns,
+		Credentials: *credentials,
+		AssumedRoleUser: AssumedRoleUser{
+			Arn:           form.Get(STSParamRoleArn) + "/" + sessionName,
+			AssumedRoleId: roleUser.UserID + ":" + sessionName,
+		},
+		ResponseMetadata: STSResponseMetadata{RequestId: GetRequestID(r)},
+	}
+	log.LogInfof("assumeRole: issue temporary credentials: requestID(%v) userID(%v) role(%v) session(%v) accessKey(%v) expiration(%v)",
+		GetRequestID(r), userInfo.UserID, roleUser.UserID, sessionName, session.AccessKey, credentials.Expiration)
+	return writeSTSResponse(w, r, response)
+}
+
+// Get session token
+// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_GetSessionToken.html
+func (o *ObjectNode) getSessionToken(w http.ResponseWriter, r *http.Request, userInfo *proto.UserInfo, form url.Values) *ErrorCode {
+	var err error
+	var duration time.Duration
+	var errorCode *ErrorCode
+	if duration, errorCode = parseSessionDuration(form, DefaultSessionTokenDuration, MaxSessionTokenDuration); errorCode != nil {
+		return errorCode
+	}
+
+	var session *Session
+	if session, err = NewSession(userInfo.AccessKey, duration); err != nil {
+		return InternalErrorCode(err)
+	}
+	var credentials *STSCredentials
+	if credentials, err = o.sessionCredentials(session); err != nil {
+		return InternalErrorCode(err)
+	}
+
+	var response = &GetSessionTokenResponse{
+		Xmlns:            STSResponseXmlns,
+		Credentials:      *credentials,
+		ResponseMetadata: STSResponseMetadata{RequestId: GetRequestID(r)},
+	}
+	log.LogInfof("getSessionToken: issue temporary credentials: requestID(%v) userID(%v) accessKey(%v) expiration(%v)",
+		GetRequestID(r), userInfo.UserID, session.AccessKey, credentials.Expiration)
+	return writeSTSResponse(w, r, response)
+}
+
+func (o *ObjectNode) sessionCredentials(session *Session) (credentials *STSCredentials, err error) {
+	var token string
+	if token, err = o.sessionCodec.Encode(session); err != nil {
+		return
+	}
+	credentials = &STSCredentials{
+		AccessKeyId:     session.AccessKey,
+		SecretAccessKey: session.SecretKey,
+		SessionToken:    token,
+		Expiration:      time.Unix(session.Expiration, 0).UTC().Format(time.RFC3339),
+	}
+	return
+}
+
+// loadRequestSession decodes the session token of the request, and checks whether the request is
+// signed by the temporary credentials in it.
+func (o *ObjectNode) loadRequestSession(r *http.Request, token string) (session *Session, errorCode *ErrorCode) {
+	if o.sessionCodec == nil {
+		return nil, STSDisabled
+	}
+	var err error
+	if session, err = o.sessionCodec.Decode(token); err != nil {
+		log.LogDebugf("loadRequestSession: decode session token fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		return nil, InvalidToken
+	}
+	if session.IsExpired() {
+		return nil, ExpiredToken
+	}
+	if auth := parseRequestAuthInfo(r); auth.accessKey != session.AccessKey {
+		log.LogDebugf("loadRequestSession: access key mismatch: requestID(%v) request(%v) session(%v)",
+			GetRequestID(r), auth.accessKey, session.AccessKey)
+		return nil, InvalidToken
+	}
+	return session, nil
+}
+
+func parseSessionDuration(form url.Values, defaultDuration, maxDuration time.Duration) (duration time.Duration, errorCode *ErrorCode) {
+	var raw = form.Get(STSParamDurationSeconds)
+	if len(raw) == 0 {
+		return defaultDuration, nil
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, InvalidSTSParameter
+	}
+	duration = time.Duration(seconds) * time.Second
+	if duration < MinSessionDuration || duration > maxDuration {
+		return 0, InvalidSTSParameter
+	}
+	return duration, nil
+}
+
+// parseSessionPolicy parses the inline session policy, which is in the same language as bucket policies.
+func parseSessionPolicy(form url.Values) (policy *Policy, errorCode *ErrorCode) {
+	var raw = form.Get(STSParamPolicy)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if len(raw) > MaxSessionPolicySize {
+		return nil, MalformedPolicyDocument
+	}
+	policy = &Policy{}
+	if err := json.Unmarshal([]byte(raw), policy); err != nil {
+		return nil, MalformedPolicyDocument
+	}
+	if ok, _ := policy.Validate(""); !ok || policy.IsEmpty() {
+		return nil, MalformedPolicyDocument
+	}
+	return policy, nil
+}
+
+func writeSTSResponse(w http.ResponseWriter, r *http.Request, response interface{}) *ErrorCode {
+	bytes, err := MarshalXMLEntity(response)
+	if err != nil {
+		log.LogErrorf("writeSTSResponse: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		return InternalErrorCode(err)
+	}
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/gorilla/mux"
+)
+
+type stubUserInfoStore map[string]*proto.UserInfo
+
+func (s stubUserInfoStore) LoadUser(accessKey string) (*proto.UserInfo, error) {
+	if userInfo, exist := s[accessKey]; exist {
+		return userInfo, nil
+	}
+	return nil, proto.ErrAccessKeyNotExists
+}
+
+func newSTSTestNode(t *testing.T) *ObjectNode {
+	codec, err := NewSessionCodec("test-secret")
+	if err != nil {
+		t.Fatalf("new session codec fail: err(%v)", err)
+	}
+	return &ObjectNode{
+		userStore: stubUserInfoStore{
+			"AK0000000000USER": {UserID: "user", AccessKey: "AK0000000000USER", SecretKey: "user-secret", UserType: proto.UserTypeNormal},
+		},
+		sessionCodec: codec,
+	}
+}
+
+func TestSessionCodec(t *testing.T) {
+	codec, err := NewSessionCodec("test-secret")
+	if err != nil {
+		t.Fatalf("new session codec fail: err(%v)", err)
+	}
+	session, err := NewSession("AK0000000000USER", time.Hour)
+	if err != nil {
+		t.Fatalf("new session fail: err(%v)", err)
+	}
+	if len(session.AccessKey) != 16 || len(session.SecretKey) != sessionSecretKeyLength {
+		t.Fatalf("invalid credentials: %v %v", session.AccessKey, session.SecretKey)
+	}
+	token, err := codec.Encode(session)
+	if err != nil {
+		t.Fatalf("encode session fail: err(%v)", err)
+	}
+	decoded, err := codec.Decode(token)
+	if err != nil {
+		t.Fatalf("decode session fail: err(%v)", err)
+	}
+	if *decoded != *session {
+		t.Fatalf("session mismatch: expect(%v) actual(%v)", session, decoded)
+	}
+
+	var tampered = []byte(token)
+	tampered[len(tampered)/2] ^= 1
+	if _, err = codec.Decode(string(tampered)); err != errInvalidSessionToken {
+		t.Fatalf("tampered token should be invalid")
+	}
+	other, _ := NewSessionCodec("other-secret")
+	if _, err = other.Decode(token); err != errInvalidSessionToken {
+		t.Fatalf("token of other secret key should be invalid")
+	}
+}
+
+func TestParseRoleArn(t *testing.T) {
+	if userID, err := parseRoleArn("arn:chubaofs:iam::ci:role/build"); err != nil || userID != "ci" {
+		t.Fatalf("parse role ARN fail: userID(%v) err(%v)", userID, err)
+	}
+	for _, arn := range []string{"", "arn:aws:s3:::bucket", "arn:aws:iam:::role/build", "arn:aws:iam::ci:user/build"} {
+		if _, err := parseRoleArn(arn); err == nil {
+			t.Fatalf("role ARN should be invalid: %v", arn)
+		}
+	}
+}
+
+func TestSTSTemporaryCredentials(t *testing.T) {
+	var o = newSTSTestNode(t)
+
+	// issue temporary credentials
+	var form = url.Values{}
+	form.Set(STSParamAction, STSActionGetSessionToken)
+	form.Set(STSParamDurationSeconds, "900")
+	var body = form.Encode()
+	req := httptest.NewRequest(http.MethodPost, "http://127.0.0.1/", strings.NewReader(body))
+	req.Header.Set(HeaderNameContentType, "application/x-www-form-urlencoded")
+	var signer = v4.NewSigner(credentials.NewStaticCredentials("AK0000000000USER", "user-secret", ""))
+	if _, err := signer.Sign(req, strings.NewReader(body), STSService, "cfs_default", time.Now()); err != nil {
+		t.Fatalf("sign STS request fail: err(%v)", err)
+	}
+	req = mux.SetURLVars(req, map[string]string{})
+	var recorder = httptest.NewRecorder()
+	o.stsHandler(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("get session token fail: status(%v) body(%v)", recorder.Code, recorder.Body.String())
+	}
+	var response = &GetSessionTokenResponse{}
+	if err := xml.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("unmarshal response fail: err(%v)", err)
+	}
+	var cred = response.Credentials
+
+	// sign an object request with the temporary credentials
+	var router = mux.NewRouter()
+	var authenticated bool
+	var requester string
+	var inner = o.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = parseRequestAuthInfo(r).accessKey == "AK0000000000USER" && GetRequestSession(r) != nil
+	}))
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectAction)).
+		Path("/{bucket}/{object:.+}").
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the access log is written by the outer middleware with the requester resolved by authentication
+			inner.ServeHTTP(w, r)
+			requester = GetRequesterFromContext(r)
+		}))
+	var serve = func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/bucket/key", nil)
+		var signer = v4.NewSigner(credentials.NewStaticCredentials(cred.AccessKeyId, cred.SecretAccessKey, token))
+		if _, err := signer.Sign(req, bytes.NewReader(nil), "s3", "cfs_default", time.Now()); err != nil {
+			t.Fatalf("sign object request fail: err(%v)", err)
+		}
+		var recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	if code := serve(cred.SessionToken); code != http.StatusOK || !authenticated {
+		t.Fatalf("request signed by temporary credentials should be authenticated as the user: status(%v)", code)
+	}
+	if requester != "AK0000000000USER" {
+		t.Fatalf("requester should be the user rather than the temporary access key: requester(%v)", requester)
+	}
+	authenticated = false
+	if code := serve(cred.SessionToken[1:]); code != http.StatusBadRequest || authenticated || requester != "" {
+		t.Fatalf("request with invalid session token should be rejected: status(%v) requester(%v)", code, requester)
+	}
+}
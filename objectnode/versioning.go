@@ -0,0 +1,64 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/Versioning.html
+
+import (
+	"encoding/xml"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+type VersioningConfiguration struct {
+	XMLName   xml.Name `xml:"VersioningConfiguration" json:"-"`
+	Status    string   `xml:"Status,omitempty" json:"status"`
+	MfaDelete string   `xml:"MfaDelete,omitempty" json:"mfa_delete"`
+}
+
+func (config *VersioningConfiguration) Enabled() bool {
+	return config != nil && config.Status == VersioningStatusEnabled
+}
+
+func (config *VersioningConfiguration) validate() bool {
+	switch config.Status {
+	case VersioningStatusEnabled, VersioningStatusSuspended:
+	default:
+		return false
+	}
+	// MFA delete is not supported.
+	if config.MfaDelete != "" && config.MfaDelete != "Disabled" {
+		return false
+	}
+	return true
+}
+
+func parseVersioningConfig(bytes []byte) (config *VersioningConfiguration, err error) {
+	config = &VersioningConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return
+	}
+	if ok := config.validate(); !ok {
+		return nil, errors.New("invalid versioning configuration")
+	}
+	return
+}
+
+func storeBucketVersioning(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSVersioning, bytes); err != nil {
+		return
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Get bucket versioning
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketVersioning.html
+func (o *ObjectNode) getBucketVersioningHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketVersioningHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var output = &VersioningConfiguration{}
+	var config *VersioningConfiguration
+	if config, err = vol.metaLoader.loadVersioning(); err != nil {
+		log.LogErrorf("getBucketVersioningHandler: load versioning fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if config != nil {
+		output.Status = config.Status
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(output); err != nil {
+		log.LogErrorf("getBucketVersioningHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket versioning
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketVersioning.html
+func (o *ObjectNode) putBucketVersioningHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketVersioningHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketVersioningHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var config *VersioningConfiguration
+	if config, err = parseVersioningConfig(bytes); err != nil {
+		log.LogErrorf("putBucketVersioningHandler: parse versioning configuration fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = IllegalVersioningConfiguration
+		return
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(config); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketVersioning(newBytes, vol); err != nil {
+		log.LogErrorf("putBucketVersioningHandler: store versioning fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeVersioning(config)
+
+	log.LogInfof("putBucketVersioningHandler: put bucket versioning: requestID(%v) volume(%v) status(%v)",
+		GetRequestID(r), param.Bucket(), config.Status)
+	return
+}
+
+// List object versions
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectVersions.html
+func (o *ObjectNode) listObjectVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("listObjectVersionsHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	prefix := r.URL.Query().Get(ParamPrefix)
+	keyMarker := r.URL.Query().Get(ParamKeyMarker)
+	versionIdMarker := r.URL.Query().Get(ParamVersionMarker)
+	maxKeys := r.URL.Query().Get(ParamMaxKeys)
+
+	var maxKeysInt uint64
+	if maxKeys != "" {
+		if maxKeysInt, err = strconv.ParseUint(maxKeys, 10, 16); err != nil {
+			log.LogErrorf("listObjectVersionsHandler: parse max keys fail: requestID(%v) err(%v)", GetRequestID(r), err)
+			errorCode = InvalidArgument
+			return
+		}
+		if maxKeysInt > MaxKeys {
+			maxKeysInt = MaxKeys
+		}
+	} else {
+		maxKeysInt = uint64(MaxKeys)
+	}
+	if len(versionIdMarker) > 0 && len(keyMarker) == 0 {
+		errorCode = InvalidArgument
+		return
+	}
+
+	var versions []*proto.VersionInfo
+	if versions, err = vol.ListVersions(prefix, keyMarker, versionIdMarker, maxKeysInt); err != nil {
+		log.LogErrorf("listObjectVersionsHandler: list versions fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var result = &ListVersionsResult{
+		Name:            param.Bucket(),
+		Prefix:          prefix,
+		KeyMarker:       keyMarker,
+		VersionIdMarker: versionIdMarker,
+		MaxKeys:         int(maxKeysInt),
+		Versions:        make([]*ObjectVersion, 0),
+		DeleteMarkers:   make([]*DeleteMarkerEntry, 0),
+	}
+	var bucketOwner = NewBucketOwner(vol)
+	var count uint64
+	var lastKey string
+	for _, version := range versions {
+		if count >= maxKeysInt {
+			result.IsTruncated = true
+			break
+		}
+		// The first version of each key is the latest one, except for the version ID marker
+		// continued the listing of the same key.
+		var isLatest = version.Path != lastKey && !(len(versionIdMarker) > 0 && version.Path == keyMarker)
+		lastKey = version.Path
+		if version.DeleteMarker {
+			result.DeleteMarkers = append(result.DeleteMarkers, &DeleteMarkerEntry{
+				Key:          version.Path,
+				VersionId:    displayVersionID(version.ID),
+				IsLatest:     isLatest,
+				LastModified: formatTimeISO(version.ModifyTime),
+				Owner:        bucketOwner,
+			})
+		} else {
+			result.Versions = append(result.Versions, &ObjectVersion{
+				Key:          version.Path,
+				VersionId:    displayVersionID(version.ID),
+				IsLatest:     isLatest,
+				LastModified: formatTimeISO(version.ModifyTime),
+				ETag:         wrapUnescapedQuot(version.ETag),
+				Size:         int(version.Size),
+				StorageClass: StorageClassStandard,
+				Owner:        bucketOwner,
+			})
+		}
+		result.NextKeyMarker = version.Path
+		result.NextVersionIdMarker = displayVersionID(version.ID)
+		count++
+	}
+	if !result.IsTruncated {
+		result.NextKeyMarker = ""
+		result.NextVersionIdMarker = ""
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(result); err != nil {
+		log.LogErrorf("listObjectVersionsHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
@@ -0,0 +1,216 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/WebsiteHosting.html
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chubaofs/chubaofs/util/errors"
+)
+
+const (
+	MaxWebsiteRoutingRules = 50
+
+	WebsiteProtocolHTTP  = "http"
+	WebsiteProtocolHTTPS = "https"
+)
+
+// WebsiteConfiguration configures a bucket to be served as a static website on the website endpoint.
+// Either RedirectAllRequestsTo or IndexDocument must be specified.
+type WebsiteConfiguration struct {
+	XMLName               xml.Name               `xml:"WebsiteConfiguration" json:"-"`
+	RedirectAllRequestsTo *RedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty" json:"redirect_all,omitempty"`
+	IndexDocument         *IndexDocument         `xml:"IndexDocument,omitempty" json:"index,omitempty"`
+	ErrorDocument         *ErrorDocument         `xml:"ErrorDocument,omitempty" json:"error,omitempty"`
+	RoutingRules          []*RoutingRule         `xml:"RoutingRules>RoutingRule,omitempty" json:"rules,omitempty"`
+}
+
+type RedirectAllRequestsTo struct {
+	HostName string `xml:"HostName" json:"host"`
+	Protocol string `xml:"Protocol,omitempty" json:"protocol,omitempty"`
+}
+
+type IndexDocument struct {
+	Suffix string `xml:"Suffix" json:"suffix"`
+}
+
+type ErrorDocument struct {
+	Key string `xml:"Key" json:"key"`
+}
+
+type RoutingRule struct {
+	Condition *RoutingRuleCondition `xml:"Condition,omitempty" json:"condition,omitempty"`
+	Redirect  *RoutingRuleRedirect  `xml:"Redirect" json:"redirect"`
+}
+
+type RoutingRuleCondition struct {
+	HttpErrorCodeReturnedEquals string `xml:"HttpErrorCodeReturnedEquals,omitempty" json:"error_code,omitempty"`
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty" json:"prefix,omitempty"`
+}
+
+type RoutingRuleRedirect struct {
+	HostName             string `xml:"HostName,omitempty" json:"host,omitempty"`
+	HttpRedirectCode     string `xml:"HttpRedirectCode,omitempty" json:"code,omitempty"`
+	Protocol             string `xml:"Protocol,omitempty" json:"protocol,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty" json:"replace_prefix,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty" json:"replace_key,omitempty"`
+}
+
+func validWebsiteProtocol(protocol string) bool {
+	return len(protocol) == 0 || protocol == WebsiteProtocolHTTP || protocol == WebsiteProtocolHTTPS
+}
+
+// validHttpCode checks whether the code is an integer in the range [min, max).
+func validHttpCode(code string, min, max int) bool {
+	value, err := strconv.Atoi(code)
+	return err == nil && value >= min && value < max
+}
+
+func (rule *RoutingRule) validate() bool {
+	var redirect = rule.Redirect
+	if redirect == nil {
+		return false
+	}
+	if len(redirect.HostName) == 0 && len(redirect.HttpRedirectCode) == 0 && len(redirect.Protocol) == 0 &&
+		len(redirect.ReplaceKeyPrefixWith) == 0 && len(redirect.ReplaceKeyWith) == 0 {
+		return false
+	}
+	if len(redirect.ReplaceKeyPrefixWith) > 0 && len(redirect.ReplaceKeyWith) > 0 {
+		return false
+	}
+	if !validWebsiteProtocol(redirect.Protocol) {
+		return false
+	}
+	if len(redirect.HttpRedirectCode) > 0 && !validHttpCode(redirect.HttpRedirectCode, 300, 400) {
+		return false
+	}
+	if rule.Condition != nil {
+		var errorCode = rule.Condition.HttpErrorCodeReturnedEquals
+		if len(errorCode) > 0 && !validHttpCode(errorCode, 400, 600) {
+			return false
+		}
+	}
+	return true
+}
+
+func (config *WebsiteConfiguration) validate() bool {
+	if config.RedirectAllRequestsTo != nil {
+		// No other element is allowed if all requests are redirected.
+		return len(config.RedirectAllRequestsTo.HostName) > 0 &&
+			validWebsiteProtocol(config.RedirectAllRequestsTo.Protocol) &&
+			config.IndexDocument == nil && config.ErrorDocument == nil && len(config.RoutingRules) == 0
+	}
+	if config.IndexDocument == nil || len(config.IndexDocument.Suffix) == 0 ||
+		strings.Contains(config.IndexDocument.Suffix, pathSep) {
+		return false
+	}
+	if config.ErrorDocument != nil && len(config.ErrorDocument.Key) == 0 {
+		return false
+	}
+	if len(config.RoutingRules) > MaxWebsiteRoutingRules {
+		return false
+	}
+	for _, rule := range config.RoutingRules {
+		if !rule.validate() {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchRoutingRule returns the first routing rule whose condition matches the key and the HTTP status code
+// which is going to be returned, or nil if no rule matches. A status code of 0 means the object is to be
+// returned successfully, which only matches the rules without an error code condition.
+func (config *WebsiteConfiguration) MatchRoutingRule(key string, statusCode int) *RoutingRule {
+	for _, rule := range config.RoutingRules {
+		var condition = rule.Condition
+		if condition == nil {
+			if statusCode == 0 {
+				return rule
+			}
+			continue
+		}
+		if !strings.HasPrefix(key, condition.KeyPrefixEquals) {
+			continue
+		}
+		if len(condition.HttpErrorCodeReturnedEquals) == 0 {
+			if statusCode == 0 {
+				return rule
+			}
+			continue
+		}
+		if condition.HttpErrorCodeReturnedEquals == strconv.Itoa(statusCode) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// RedirectLocation returns the location and the status code to redirect the request for the key.
+// The host name and protocol of the request are used if they are not specified by the rule.
+func (rule *RoutingRule) RedirectLocation(key, host, protocol string) (location string, statusCode int) {
+	var redirect = rule.Redirect
+	if len(redirect.HostName) > 0 {
+		host = redirect.HostName
+	}
+	if len(redirect.Protocol) > 0 {
+		protocol = redirect.Protocol
+	}
+	switch {
+	case len(redirect.ReplaceKeyWith) > 0:
+		key = redirect.ReplaceKeyWith
+	case len(redirect.ReplaceKeyPrefixWith) > 0:
+		var prefix string
+		if rule.Condition != nil {
+			prefix = rule.Condition.KeyPrefixEquals
+		}
+		key = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, prefix)
+	}
+	statusCode = http.StatusMovedPermanently
+	if len(redirect.HttpRedirectCode) > 0 {
+		statusCode, _ = strconv.Atoi(redirect.HttpRedirectCode)
+	}
+	return protocol + "://" + host + "/" + key, statusCode
+}
+
+func parseWebsiteConfig(bytes []byte) (config *WebsiteConfiguration, err error) {
+	config = &WebsiteConfiguration{}
+	if err = xml.Unmarshal(bytes, config); err != nil {
+		return
+	}
+	if ok := config.validate(); !ok {
+		return nil, errors.New("invalid website configuration")
+	}
+	return
+}
+
+func storeBucketWebsite(bytes []byte, vol *Volume) (err error) {
+	if err = vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSWebsite, bytes); err != nil {
+		return
+	}
+	return nil
+}
+
+func deleteBucketWebsite(vol *Volume) (err error) {
+	if err = vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSWebsite); err != nil {
+		return err
+	}
+	return nil
+}
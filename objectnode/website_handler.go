@@ -0,0 +1,374 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+
+	"github.com/gorilla/mux"
+)
+
+// Get bucket website
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketWebsite.html
+func (o *ObjectNode) getBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketWebsiteHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var website *WebsiteConfiguration
+	if website, err = vol.metaLoader.loadWebsite(); err != nil {
+		log.LogErrorf("getBucketWebsiteHandler: load website fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if website == nil {
+		errorCode = NoSuchWebsiteConfiguration
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = MarshalXMLEntity(website); err != nil {
+		log.LogErrorf("getBucketWebsiteHandler: marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	w.Header()[HeaderNameContentType] = []string{HeaderValueContentTypeXML}
+	w.Header()[HeaderNameContentLength] = []string{strconv.Itoa(len(bytes))}
+	_, _ = w.Write(bytes)
+	return
+}
+
+// Put bucket website
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
+func (o *ObjectNode) putBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketWebsiteHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	var bytes []byte
+	if bytes, err = ioutil.ReadAll(r.Body); err != nil && err != io.EOF {
+		log.LogErrorf("putBucketWebsiteHandler: read request body fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+
+	var website *WebsiteConfiguration
+	if website, err = parseWebsiteConfig(bytes); err != nil {
+		log.LogErrorf("putBucketWebsiteHandler: parse website configuration fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		errorCode = InvalidWebsiteConfiguration
+		return
+	}
+
+	var newBytes []byte
+	if newBytes, err = json.Marshal(website); err != nil {
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	if err = storeBucketWebsite(newBytes, vol); err != nil {
+		log.LogErrorf("putBucketWebsiteHandler: store website fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeWebsite(website)
+
+	log.LogInfof("putBucketWebsiteHandler: put bucket website: requestID(%v) volume(%v)",
+		GetRequestID(r), param.Bucket())
+	return
+}
+
+// Delete bucket website
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketWebsite.html
+func (o *ObjectNode) deleteBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		if errorCode != nil {
+			_ = errorCode.ServeResponse(w, r)
+			return
+		}
+	}()
+
+	var param = ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketWebsiteHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = NoSuchBucket
+		return
+	}
+
+	if err = deleteBucketWebsite(vol); err != nil {
+		log.LogErrorf("deleteBucketWebsiteHandler: delete website fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		errorCode = InternalErrorCode(err)
+		return
+	}
+	vol.metaLoader.storeWebsite(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// isWebsiteRequest returns whether the request is sent to the website endpoint.
+// The requests of the website endpoint are anonymous, so they are not authenticated.
+func (o *ObjectNode) isWebsiteRequest(r *http.Request) bool {
+	_, is := o.webWilds.Parse(r.Host)
+	return is
+}
+
+func websiteRequestProtocol(r *http.Request) string {
+	if r.TLS != nil || r.Header.Get(HeaderNameXForwardedProto) == WebsiteProtocolHTTPS {
+		return WebsiteProtocolHTTPS
+	}
+	return WebsiteProtocolHTTP
+}
+
+// websiteAllowed checks whether the bucket policy allows anonymous users to get the object.
+func (o *ObjectNode) websiteAllowed(r *http.Request, vol *Volume, key string) bool {
+	policy, err := vol.metaLoader.loadPolicy()
+	if err != nil || policy == nil || policy.IsEmpty() {
+		return false
+	}
+	publicAccessBlock, err := o.publicAccessBlock(vol)
+	if err != nil {
+		return false
+	}
+	var param = ParseRequestParam(r)
+	param.accessKey = ""
+	param.action = proto.OSSGetObjectAction
+	param.object = key
+	param.resource = vol.name + "/" + key
+	return policy.IsAllowed(param, false, publicAccessBlock.RestrictPublicBuckets)
+}
+
+// websiteObjectStatus returns 0 if the object can be served on the website endpoint,
+// or the HTTP status code to be returned.
+func (o *ObjectNode) websiteObjectStatus(r *http.Request, vol *Volume, key string) (statusCode int, err error) {
+	if !o.websiteAllowed(r, vol, key) {
+		return http.StatusForbidden, nil
+	}
+	var fileInfo *FSFileInfo
+	if fileInfo, err = vol.ObjectMeta(key); err == syscall.ENOENT {
+		return http.StatusNotFound, nil
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if fileInfo.Mode.IsDir() {
+		return http.StatusNotFound, nil
+	}
+	return 0, nil
+}
+
+// websiteErrorWriter writes the error document with the status code of the error.
+type websiteErrorWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (w *websiteErrorWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if statusCode == http.StatusOK {
+		statusCode = w.statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *websiteErrorWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// websiteHandler serves the bucket as a static website on the website endpoint. The objects are served to
+// anonymous users as far as the bucket policy allows, and the index document is served for the keys ending
+// with a slash. The error document is served with the status code if the object is not found or not allowed.
+// websiteQueryRejected checks whether the website request has the parameters which select a version
+// or override the response headers, which are only available to the authenticated requests.
+func websiteQueryRejected(query url.Values) bool {
+	for key := range query {
+		if key == ParamVersionId || strings.HasPrefix(key, "response-") {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *ObjectNode) websiteHandler(w http.ResponseWriter, r *http.Request) {
+	// The website requests are anonymous, which only read the current objects as they are.
+	if websiteQueryRejected(r.URL.Query()) {
+		_ = InvalidArgument.ServeResponse(w, r)
+		return
+	}
+	var vars = mux.Vars(r)
+	var vol *Volume
+	var err error
+	if vol, err = o.getVol(vars["bucket"]); err != nil {
+		if err == proto.ErrVolNotExists {
+			_ = NoSuchBucket.ServeResponse(w, r)
+			return
+		}
+		_ = InternalErrorCode(err).ServeResponse(w, r)
+		return
+	}
+	var website *WebsiteConfiguration
+	if website, err = vol.metaLoader.loadWebsite(); err != nil {
+		log.LogErrorf("websiteHandler: load website fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		_ = InternalErrorCode(err).ServeResponse(w, r)
+		return
+	}
+	if website == nil {
+		_ = NoSuchWebsiteConfiguration.ServeResponse(w, r)
+		return
+	}
+
+	var protocol = websiteRequestProtocol(r)
+	if redirectAll := website.RedirectAllRequestsTo; redirectAll != nil {
+		if len(redirectAll.Protocol) > 0 {
+			protocol = redirectAll.Protocol
+		}
+		http.Redirect(w, r, protocol+"://"+redirectAll.HostName+r.URL.RequestURI(), http.StatusMovedPermanently)
+		return
+	}
+	// None of the parameters is passed to the object handlers which serve the documents.
+	r.URL.RawQuery = ""
+
+	var requestKey = strings.TrimPrefix(r.URL.Path, pathSep)
+	if rule := website.MatchRoutingRule(requestKey, 0); rule != nil {
+		location, code := rule.RedirectLocation(requestKey, r.Host, protocol)
+		http.Redirect(w, r, location, code)
+		return
+	}
+
+	var key = requestKey
+	if len(key) == 0 || strings.HasSuffix(key, pathSep) {
+		key += website.IndexDocument.Suffix
+	}
+	var statusCode int
+	if statusCode, err = o.websiteObjectStatus(r, vol, key); err != nil {
+		log.LogErrorf("websiteHandler: get object meta fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), key, err)
+		_ = InternalErrorCode(err).ServeResponse(w, r)
+		return
+	}
+	if statusCode == http.StatusNotFound && key == requestKey {
+		// The key may refer to a directory with the index document, redirect to the directory.
+		if indexStatus, _ := o.websiteObjectStatus(r, vol, key+pathSep+website.IndexDocument.Suffix); indexStatus == 0 {
+			http.Redirect(w, r, pathSep+key+pathSep, http.StatusFound)
+			return
+		}
+	}
+	if statusCode != 0 {
+		o.websiteErrorResponse(w, r, vol, website, requestKey, statusCode)
+		return
+	}
+
+	log.LogDebugf("websiteHandler: serve object: requestID(%v) volume(%v) path(%v)",
+		GetRequestID(r), vol.Name(), key)
+	vars["object"] = key
+	if r.Method == http.MethodHead {
+		o.headObjectHandler(w, r)
+		return
+	}
+	o.getObjectHandler(w, r)
+}
+
+func (o *ObjectNode) websiteErrorResponse(w http.ResponseWriter, r *http.Request, vol *Volume,
+	website *WebsiteConfiguration, requestKey string, statusCode int) {
+	if rule := website.MatchRoutingRule(requestKey, statusCode); rule != nil {
+		location, code := rule.RedirectLocation(requestKey, r.Host, websiteRequestProtocol(r))
+		http.Redirect(w, r, location, code)
+		return
+	}
+	if errorDocument := website.ErrorDocument; errorDocument != nil && r.Method == http.MethodGet {
+		if documentStatus, _ := o.websiteObjectStatus(r, vol, errorDocument.Key); documentStatus == 0 {
+			mux.Vars(r)["object"] = errorDocument.Key
+			o.getObjectHandler(&websiteErrorWriter{ResponseWriter: w, statusCode: statusCode}, r)
+			return
+		}
+	}
+	if statusCode == http.StatusForbidden {
+		_ = AccessDenied.ServeResponse(w, r)
+		return
+	}
+	_ = NoSuchKey.ServeResponse(w, r)
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestWebsite_Parse(t *testing.T) {
+	var valid = `<WebsiteConfiguration>
+	<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+	<ErrorDocument><Key>error.html</Key></ErrorDocument>
+	<RoutingRules>
+		<RoutingRule>
+			<Condition><KeyPrefixEquals>docs/</KeyPrefixEquals></Condition>
+			<Redirect><ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith></Redirect>
+		</RoutingRule>
+		<RoutingRule>
+			<Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition>
+			<Redirect><HostName>backup.example.com</HostName><HttpRedirectCode>302</HttpRedirectCode></Redirect>
+		</RoutingRule>
+	</RoutingRules>
+</WebsiteConfiguration>`
+	config, err := parseWebsiteConfig([]byte(valid))
+	if err != nil {
+		t.Fatalf("parse website configuration fail: err(%v)", err)
+	}
+	if config.IndexDocument.Suffix != "index.html" || config.ErrorDocument.Key != "error.html" {
+		t.Fatalf("website documents mismatch: index(%v) error(%v)", config.IndexDocument, config.ErrorDocument)
+	}
+	if len(config.RoutingRules) != 2 {
+		t.Fatalf("routing rule count mismatch: expect(2) actual(%v)", len(config.RoutingRules))
+	}
+
+	var invalids = []string{
+		`<WebsiteConfiguration></WebsiteConfiguration>`,
+		`<WebsiteConfiguration><IndexDocument><Suffix>a/index.html</Suffix></IndexDocument></WebsiteConfiguration>`,
+		`<WebsiteConfiguration>
+			<RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo>
+			<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+		</WebsiteConfiguration>`,
+		`<WebsiteConfiguration>
+			<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+			<RoutingRules><RoutingRule><Redirect><HttpRedirectCode>200</HttpRedirectCode></Redirect></RoutingRule></RoutingRules>
+		</WebsiteConfiguration>`,
+	}
+	for _, invalid := range invalids {
+		if _, err = parseWebsiteConfig([]byte(invalid)); err == nil {
+			t.Fatalf("invalid website configuration is accepted: %v", invalid)
+		}
+	}
+}
+
+func TestWebsite_RoutingRule(t *testing.T) {
+	var config = &WebsiteConfiguration{
+		IndexDocument: &IndexDocument{Suffix: "index.html"},
+		RoutingRules: []*RoutingRule{
+			{
+				Condition: &RoutingRuleCondition{KeyPrefixEquals: "docs/"},
+				Redirect:  &RoutingRuleRedirect{ReplaceKeyPrefixWith: "documents/"},
+			},
+			{
+				Condition: &RoutingRuleCondition{HttpErrorCodeReturnedEquals: "404"},
+				Redirect:  &RoutingRuleRedirect{HostName: "backup.example.com", Protocol: "https", HttpRedirectCode: "302"},
+			},
+		},
+	}
+	if rule := config.MatchRoutingRule("images/a.png", 0); rule != nil {
+		t.Fatalf("unexpected routing rule matched: %v", rule)
+	}
+
+	rule := config.MatchRoutingRule("docs/a.html", 0)
+	if rule == nil {
+		t.Fatalf("routing rule of prefix is not matched")
+	}
+	location, code := rule.RedirectLocation("docs/a.html", "site.website.chubao.io", "http")
+	if location != "http://site.website.chubao.io/documents/a.html" || code != http.StatusMovedPermanently {
+		t.Fatalf("redirect mismatch: location(%v) code(%v)", location, code)
+	}
+
+	if rule = config.MatchRoutingRule("images/a.png", http.StatusNotFound); rule == nil {
+		t.Fatalf("routing rule of error code is not matched")
+	}
+	location, code = rule.RedirectLocation("images/a.png", "site.website.chubao.io", "http")
+	if location != "https://backup.example.com/images/a.png" || code != http.StatusFound {
+		t.Fatalf("redirect mismatch: location(%v) code(%v)", location, code)
+	}
+	if rule = config.MatchRoutingRule("images/a.png", http.StatusForbidden); rule != nil {
+		t.Fatalf("unexpected routing rule matched: %v", rule)
+	}
+}
+
+func TestWebsite_RejectQuery(t *testing.T) {
+	var o = &ObjectNode{}
+	for _, query := range []string{"versionId=v1", "response-content-type=text/html", "response-cache-control=no-cache", "a=1&versionId="} {
+		req := httptest.NewRequest(http.MethodGet, "http://bucket.website.chubao.io/index.html?"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"bucket": "bucket"})
+		var recorder = httptest.NewRecorder()
+		o.websiteHandler(recorder, req)
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("website request with query %v expect rejected: status(%v)", query, recorder.Code)
+		}
+	}
+	if websiteQueryRejected(map[string][]string{"utm_source": {"docs"}}) {
+		t.Fatalf("website request with other parameters should not be rejected")
+	}
+}
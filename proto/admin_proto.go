@@ -39,6 +39,14 @@ const (
 	AdminListVols                  = "/vol/list"
 	AdminSetNodeInfo               = "/admin/setNodeInfo"
 	AdminGetNodeInfo               = "/admin/getNodeInfo"
+	AdminSetQuota                  = "/quota/set"
+	AdminDeleteQuota               = "/quota/delete"
+	AdminListQuota                 = "/quota/list"
+	AdminCreateSnapshot            = "/snapshot/create"
+	AdminDeleteSnapshot            = "/snapshot/delete"
+	AdminListSnapshot              = "/snapshot/list"
+	AdminGetVolEncryptionKey       = "/vol/encryptionKey"
+	AdminAcquireTaskLease          = "/task/lease"
 
 	//graphql master api
 	AdminClusterAPI = "/api/cluster"
@@ -100,17 +108,21 @@ const (
 	ForceDelete         = "Force-Delete"
 
 	// APIs for user management
-	UserCreate          = "/user/create"
-	UserDelete          = "/user/delete"
-	UserUpdate          = "/user/update"
-	UserUpdatePolicy    = "/user/updatePolicy"
-	UserRemovePolicy    = "/user/removePolicy"
-	UserDeleteVolPolicy = "/user/deleteVolPolicy"
-	UserGetInfo         = "/user/info"
-	UserGetAKInfo       = "/user/akInfo"
-	UserTransferVol     = "/user/transferVol"
-	UserList            = "/user/list"
-	UsersOfVol          = "/vol/users"
+	UserCreate               = "/user/create"
+	UserDelete               = "/user/delete"
+	UserUpdate               = "/user/update"
+	UserUpdatePolicy         = "/user/updatePolicy"
+	UserRemovePolicy         = "/user/removePolicy"
+	UserDeleteVolPolicy      = "/user/deleteVolPolicy"
+	UserUpdateIdentityPolicy = "/user/updateIdentityPolicy"
+	UserRemoveIdentityPolicy = "/user/removeIdentityPolicy"
+	UserSimulatePolicy       = "/user/simulatePolicy"
+	UserUpdateS3QoS          = "/user/updateS3QoS"
+	UserGetInfo              = "/user/info"
+	UserGetAKInfo            = "/user/akInfo"
+	UserTransferVol          = "/user/transferVol"
+	UserList                 = "/user/list"
+	UsersOfVol               = "/vol/users"
 	//graphql api for header
 	HeadAuthorized  = "Authorization"
 	ParamAuthorized = "_authorization"
@@ -163,6 +175,8 @@ type CreateDataPartitionRequest struct {
 	Members       []Peer
 	Hosts         []string
 	CreateType    int
+	ECDataNum     uint8
+	ECParityNum   uint8
 }
 
 // CreateDataPartitionResponse defines the response to the request of creating a data partition.
@@ -259,8 +273,10 @@ type LoadMetaPartitionMetricResponse struct {
 
 // HeartBeatRequest define the heartbeat request.
 type HeartBeatRequest struct {
-	CurrTime   int64
-	MasterAddr string
+	CurrTime       int64
+	MasterAddr     string
+	ExceededQuotas map[string][]uint32 // volume name -> IDs of the quotas which have been exceeded
+	Compressions   map[string]string   // volume name -> codec compressing the blocks of the data partitions
 }
 
 // PartitionReport defines the partition report.
@@ -303,6 +319,7 @@ type MetaPartitionReport struct {
 	VolName     string
 	InodeCnt    uint64
 	DentryCnt   uint64
+	QuotaUsages []*QuotaUsage
 }
 
 // MetaNodeHeartbeatResponse defines the response to the meta node heartbeat request.
@@ -392,13 +409,16 @@ type MetaPartitionLoadResponse struct {
 
 // DataPartitionResponse defines the response from a data node to the master that is related to a data partition.
 type DataPartitionResponse struct {
-	PartitionID uint64
-	Status      int8
-	ReplicaNum  uint8
-	Hosts       []string
-	LeaderAddr  string
-	Epoch       uint64
-	IsRecover   bool
+	PartitionID  uint64
+	Status       int8
+	ReplicaNum   uint8
+	Hosts        []string
+	LeaderAddr   string
+	Epoch        uint64
+	IsRecover    bool
+	ECDataNum    uint8 // the number of data shards of the erasure coded partition, 0 means the partition is replicated
+	ECParityNum  uint8
+	StorageClass uint8
 }
 
 // DataPartitionsView defines the view of a data partition
@@ -441,6 +461,9 @@ type VolView struct {
 	DataPartitions []*DataPartitionResponse
 	OSSSecure      *OSSSecure
 	CreateTime     int64
+	TrashDays      uint32 // retention of the deleted files in the trash, 0 means the trash is disabled
+	S3RequestRate  uint64 // requests per second served by the ObjectNodes, 0 means unlimited
+	S3Bandwidth    uint64 // bytes per second transferred by the ObjectNodes, 0 means unlimited
 }
 
 func (v *VolView) SetOwner(owner string) {
@@ -498,6 +521,15 @@ type SimpleVolView struct {
 	Description        string
 	DpSelectorName     string
 	DpSelectorParm     string
+	TrashDays          uint32
+	ECDataNum          uint8
+	ECParityNum        uint8
+	ECColdDays         uint32
+	Compression        string
+	Encrypted          bool
+	S3RequestRate      uint64
+	S3Bandwidth        uint64
+	ColdZoneName       string
 }
 
 // MasterAPIAccessResp defines the response for getting meta partition
@@ -0,0 +1,62 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+const (
+	// ECStripeUnitSize is the number of bytes written to one shard before moving to the next one,
+	// a stripe is made up of one unit of each data shard and the parity units computed from them.
+	ECStripeUnitSize = 64 * 1024
+	// ECMaxShardSize limits the size of the extent holding a shard,
+	// which limits the memory used to encode a stripe group as well.
+	ECMaxShardSize = 8 * 1024 * 1024
+
+	// The extended attribute of a directory overriding the cold days of the volume,
+	// the files not modified for such days are migrated into erasure coded partitions.
+	ECColdDaysXAttrKey = "cfs.ec.colddays"
+
+	MaxECShardNum = 16
+)
+
+// RepairErasureCodedShardRequest defines the request to rebuild the shard of an erasure coded data partition
+// on the data node from the shards on the other hosts.
+type RepairErasureCodedShardRequest struct {
+	PartitionID uint64
+	Hosts       []string
+	DataNum     uint8
+	ParityNum   uint8
+}
+
+// RepairErasureCodedShardResponse defines the response to the request of repairing an erasure coded shard.
+type RepairErasureCodedShardResponse struct {
+	PartitionID    uint64
+	RepairedExtent int
+	Status         uint8
+	Result         string
+}
+
+// ECShardSize returns the size of each shard of an extent with the given logical size.
+func ECShardSize(size uint64, dataNum uint8) uint64 {
+	stripeSize := uint64(ECStripeUnitSize) * uint64(dataNum)
+	return (size + stripeSize - 1) / stripeSize * ECStripeUnitSize
+}
+
+// ECShardLocation returns the data shard and the offset in the shard of the logical offset of an extent.
+func ECShardLocation(offset uint64, dataNum uint8) (shard int, shardOffset uint64) {
+	stripeSize := uint64(ECStripeUnitSize) * uint64(dataNum)
+	inStripe := offset % stripeSize
+	shard = int(inStripe / ECStripeUnitSize)
+	shardOffset = offset/stripeSize*ECStripeUnitSize + inStripe%ECStripeUnitSize
+	return
+}
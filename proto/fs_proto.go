@@ -132,12 +132,13 @@ func (d Dentry) String() string {
 
 // CreateInodeRequest defines the request to create an inode.
 type CreateInodeRequest struct {
-	VolName     string `json:"vol"`
-	PartitionID uint64 `json:"pid"`
-	Mode        uint32 `json:"mode"`
-	Uid         uint32 `json:"uid"`
-	Gid         uint32 `json:"gid"`
-	Target      []byte `json:"tgt"`
+	VolName     string   `json:"vol"`
+	PartitionID uint64   `json:"pid"`
+	Mode        uint32   `json:"mode"`
+	Uid         uint32   `json:"uid"`
+	Gid         uint32   `json:"gid"`
+	Target      []byte   `json:"tgt"`
+	QuotaIDs    []uint32 `json:"qids"` // quotas of the parent directory which the new inode is accounted to
 }
 
 // CreateInodeResponse defines the response to the request of creating an inode.
@@ -145,6 +146,21 @@ type CreateInodeResponse struct {
 	Info *InodeInfo `json:"info"`
 }
 
+// CopyInodeRequest defines the request to create an inode which shares the extents of the specified inode.
+// If Size is not zero, the new inode shares only the extents in range [Offset, Offset+Size) of the specified inode.
+type CopyInodeRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	Inode       uint64 `json:"ino"`
+	Offset      uint64 `json:"off,omitempty"`
+	Size        uint64 `json:"size,omitempty"`
+}
+
+// CopyInodeResponse defines the response to the request of copying an inode.
+type CopyInodeResponse struct {
+	Info *InodeInfo `json:"info"`
+}
+
 // LinkInodeRequest defines the request to link an inode.
 type LinkInodeRequest struct {
 	VolName     string `json:"vol"`
@@ -208,6 +224,11 @@ type CreateDentryRequest struct {
 	Mode        uint32 `json:"mode"`
 }
 
+// CreateDentryResponse defines the response to the request of creating a dentry.
+type CreateDentryResponse struct {
+	QuotaIDs []uint32 `json:"qids"` // quotas of the parent inherited by the new inode
+}
+
 // UpdateDentryRequest defines the request to update a dentry.
 type UpdateDentryRequest struct {
 	VolName     string `json:"vol"`
@@ -219,7 +240,8 @@ type UpdateDentryRequest struct {
 
 // UpdateDentryResponse defines the response to the request of updating a dentry.
 type UpdateDentryResponse struct {
-	Inode uint64 `json:"ino"` // old inode number
+	Inode    uint64   `json:"ino"`  // old inode number
+	QuotaIDs []uint32 `json:"qids"` // quotas of the parent inherited by the new inode
 }
 
 // DeleteDentryRequest define the request tp delete a dentry.
@@ -256,6 +278,7 @@ type LookupRequest struct {
 	PartitionID uint64 `json:"pid"`
 	ParentID    uint64 `json:"pino"`
 	Name        string `json:"name"`
+	SnapshotID  uint64 `json:"snap,omitempty"`
 }
 
 // LookupResponse defines the response for the loopup request.
@@ -269,6 +292,7 @@ type InodeGetRequest struct {
 	VolName     string `json:"vol"`
 	PartitionID uint64 `json:"pid"`
 	Inode       uint64 `json:"ino"`
+	SnapshotID  uint64 `json:"snap,omitempty"`
 }
 
 // InodeGetResponse defines the response to the InodeGetRequest.
@@ -281,6 +305,7 @@ type BatchInodeGetRequest struct {
 	VolName     string   `json:"vol"`
 	PartitionID uint64   `json:"pid"`
 	Inodes      []uint64 `json:"inos"`
+	SnapshotID  uint64   `json:"snap,omitempty"`
 }
 
 // BatchInodeGetResponse defines the response to the request of getting the inode in batch.
@@ -289,10 +314,15 @@ type BatchInodeGetResponse struct {
 }
 
 // ReadDirRequest defines the request to read dir.
+// Children are returned in the order of name. If marker is specified, only the children whose name
+// is not less than the marker are returned. If limit is specified, at most limit children are returned.
 type ReadDirRequest struct {
 	VolName     string `json:"vol"`
 	PartitionID uint64 `json:"pid"`
 	ParentID    uint64 `json:"pino"`
+	Marker      string `json:"marker"`
+	Limit       uint64 `json:"limit"`
+	SnapshotID  uint64 `json:"snap,omitempty"`
 }
 
 // ReadDirResponse defines the response to the request of reading dir.
@@ -308,18 +338,42 @@ type AppendExtentKeyRequest struct {
 	Extent      ExtentKey `json:"ek"`
 }
 
+// MigratingXAttrKey is the extend attribute marking an inode being migrated, the value is the unix time of the mark.
+// The mark expires after MigratingMarkTTL in case the migration fails, and is removed when the extents are replaced.
+const (
+	MigratingXAttrKey = "cfs.migrating"
+	MigratingMarkTTL  = 3600
+)
+
+// ReplaceExtentsRequest defines the request to replace the extents of an inode by the new extents holding the same
+// data, which migrates the data of a file to other data partitions. The extents are replaced only if the extents of
+// the inode are the same as the old extents. The request to prepare marks the inode as being migrated before the
+// migration reads the data, the clients write new extents instead of overwriting the marked inode in place, so that
+// any write to the file during the migration changes its extents.
+type ReplaceExtentsRequest struct {
+	VolName     string      `json:"vol"`
+	PartitionID uint64      `json:"pid"`
+	Inode       uint64      `json:"ino"`
+	Prepare     bool        `json:"prep"`
+	OldExtents  []ExtentKey `json:"old"`
+	NewExtents  []ExtentKey `json:"new"`
+	MarkTime    int64       `json:"mt"` // set by the meta partition leader when the inode is marked
+}
+
 // GetExtentsRequest defines the reques to get extents.
 type GetExtentsRequest struct {
 	VolName     string `json:"vol"`
 	PartitionID uint64 `json:"pid"`
 	Inode       uint64 `json:"ino"`
+	SnapshotID  uint64 `json:"snap,omitempty"`
 }
 
 // GetExtentsResponse defines the response to the request of getting extents.
 type GetExtentsResponse struct {
-	Generation uint64      `json:"gen"`
-	Size       uint64      `json:"sz"`
-	Extents    []ExtentKey `json:"eks"`
+	Generation  uint64      `json:"gen"`
+	Size        uint64      `json:"sz"`
+	Extents     []ExtentKey `json:"eks"`
+	CopyOnWrite bool        `json:"cow"` // the extents are shared, and must not be overwritten in place
 }
 
 // TruncateRequest defines the request to truncate.
@@ -386,6 +440,7 @@ type GetXAttrRequest struct {
 	PartitionId uint64 `json:"pid"`
 	Inode       uint64 `json:"ino"`
 	Key         string `json:"key"`
+	SnapshotID  uint64 `json:"snap,omitempty"`
 }
 
 type GetXAttrResponse struct {
@@ -407,6 +462,7 @@ type ListXAttrRequest struct {
 	VolName     string `json:"vol"`
 	PartitionId uint64 `json:"pid"`
 	Inode       uint64 `json:"ino"`
+	SnapshotID  uint64 `json:"snap,omitempty"`
 }
 
 type ListXAttrResponse struct {
@@ -421,6 +477,7 @@ type BatchGetXAttrRequest struct {
 	PartitionId uint64   `json:"pid"`
 	Inodes      []uint64 `json:"inos"`
 	Keys        []string `json:"keys"`
+	SnapshotID  uint64   `json:"snap,omitempty"`
 }
 
 type BatchGetXAttrResponse struct {
@@ -495,3 +552,156 @@ type ListMultipartRequest struct {
 type ListMultipartResponse struct {
 	Multiparts []*MultipartInfo `json:"mps"`
 }
+
+type VersionInfo struct {
+	ID           string    `json:"id"`
+	Path         string    `json:"path"`
+	Inode        uint64    `json:"ino"`
+	Size         uint64    `json:"sz"`
+	ETag         string    `json:"etag"`
+	ModifyTime   time.Time `json:"mt"`
+	DeleteMarker bool      `json:"dm"`
+	Null         bool      `json:"null"`
+}
+
+type CreateVersionRequest struct {
+	VolName      string    `json:"vol"`
+	PartitionId  uint64    `json:"pid"`
+	Path         string    `json:"path"`
+	Inode        uint64    `json:"ino"`
+	Size         uint64    `json:"sz"`
+	ETag         string    `json:"etag"`
+	ModifyTime   time.Time `json:"mt"`
+	DeleteMarker bool      `json:"dm"`
+	Null         bool      `json:"null"`
+}
+
+type CreateVersionResponse struct {
+	Info *VersionInfo `json:"info"`
+}
+
+type GetVersionRequest struct {
+	VolName     string `json:"vol"`
+	PartitionId uint64 `json:"pid"`
+	Path        string `json:"path"`
+	VersionId   string `json:"vid"`
+}
+
+type GetVersionResponse struct {
+	Info *VersionInfo `json:"info"`
+}
+
+type RemoveVersionRequest struct {
+	VolName     string `json:"vol"`
+	PartitionId uint64 `json:"pid"`
+	Path        string `json:"path"`
+	VersionId   string `json:"vid"`
+}
+
+type ListVersionsRequest struct {
+	VolName         string `json:"vol"`
+	PartitionId     uint64 `json:"pid"`
+	Marker          string `json:"mk"`
+	VersionIdMarker string `json:"vmk"`
+	Max             uint64 `json:"max"`
+	Prefix          string `json:"pf"`
+}
+
+type ListVersionsResponse struct {
+	Versions []*VersionInfo `json:"vers"`
+}
+
+// Roles and states of rename transaction participants.
+const (
+	TxRenameRoleSrc uint8 = 1 // meta partition which holds the source dentry
+	TxRenameRoleDst uint8 = 2 // meta partition which holds the destination dentry
+
+	TxRenameStatePrepared  uint8 = 1
+	TxRenameStateCommitted uint8 = 2
+	TxRenameStateAborted   uint8 = 3
+)
+
+// TxRenamePrepareRequest defines the request to record the intent of a rename transaction on a participant.
+type TxRenamePrepareRequest struct {
+	VolName        string `json:"vol"`
+	PartitionID    uint64 `json:"pid"`
+	TxID           string `json:"tx"`
+	Role           uint8  `json:"role"`
+	SrcPartitionID uint64 `json:"spid"`
+	DstPartitionID uint64 `json:"dpid"`
+	SrcParentID    uint64 `json:"spino"`
+	SrcName        string `json:"sname"`
+	DstParentID    uint64 `json:"dpino"`
+	DstName        string `json:"dname"`
+	Inode          uint64 `json:"ino"`
+	Mode           uint32 `json:"mode"`
+}
+
+// TxRenameCommitRequest defines the request to commit a prepared rename transaction on a participant.
+type TxRenameCommitRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	TxID        string `json:"tx"`
+	Role        uint8  `json:"role"`
+}
+
+// TxRenameAbortRequest defines the request to abort a prepared rename transaction on a participant.
+type TxRenameAbortRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	TxID        string `json:"tx"`
+	Role        uint8  `json:"role"`
+}
+
+// TxRenameGetRequest defines the request to query the state of a rename transaction on a participant.
+type TxRenameGetRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	TxID        string `json:"tx"`
+	Role        uint8  `json:"role"`
+}
+
+// TxRenameGetResponse defines the response to the request of querying a rename transaction.
+type TxRenameGetResponse struct {
+	State    uint8  `json:"state"`
+	OldInode uint64 `json:"oino"`
+}
+
+// Operations recorded in the change log of objects.
+const (
+	ChangeLogOpPut    uint8 = 1 // the object has been created or overwritten
+	ChangeLogOpDelete uint8 = 2 // the object has been deleted
+)
+
+type ChangeLogInfo struct {
+	PartitionId uint64    `json:"pid"`
+	ID          string    `json:"id"`
+	Op          uint8     `json:"op"`
+	Path        string    `json:"path"`
+	CreateTime  time.Time `json:"ct"`
+}
+
+// AppendChangeLogRequest defines the request to append a change of the object to the change log.
+type AppendChangeLogRequest struct {
+	VolName     string `json:"vol"`
+	PartitionId uint64 `json:"pid"`
+	Op          uint8  `json:"op"`
+	Path        string `json:"path"`
+}
+
+type ListChangeLogsRequest struct {
+	VolName     string `json:"vol"`
+	PartitionId uint64 `json:"pid"`
+	Marker      string `json:"mk"`
+	Max         uint64 `json:"max"`
+}
+
+type ListChangeLogsResponse struct {
+	ChangeLogs []*ChangeLogInfo `json:"cls"`
+}
+
+type RemoveChangeLogRequest struct {
+	VolName     string `json:"vol"`
+	PartitionId uint64 `json:"pid"`
+	ID          string `json:"id"`
+}
@@ -0,0 +1,284 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Condition operators of the policy documents.
+// Each operator except Null also has an "...IfExists" variant which matches
+// when the condition key is absent from the request.
+//
+// Reference: https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_elements_condition_operators.html
+const (
+	IAMStringEquals              = "StringEquals"
+	IAMStringNotEquals           = "StringNotEquals"
+	IAMStringEqualsIgnoreCase    = "StringEqualsIgnoreCase"
+	IAMStringNotEqualsIgnoreCase = "StringNotEqualsIgnoreCase"
+	IAMStringLike                = "StringLike"
+	IAMStringNotLike             = "StringNotLike"
+	IAMNumericEquals             = "NumericEquals"
+	IAMNumericNotEquals          = "NumericNotEquals"
+	IAMNumericLessThan           = "NumericLessThan"
+	IAMNumericLessThanEquals     = "NumericLessThanEquals"
+	IAMNumericGreaterThan        = "NumericGreaterThan"
+	IAMNumericGreaterThanEquals  = "NumericGreaterThanEquals"
+	IAMDateEquals                = "DateEquals"
+	IAMDateNotEquals             = "DateNotEquals"
+	IAMDateLessThan              = "DateLessThan"
+	IAMDateLessThanEquals        = "DateLessThanEquals"
+	IAMDateGreaterThan           = "DateGreaterThan"
+	IAMDateGreaterThanEquals     = "DateGreaterThanEquals"
+	IAMBool                      = "Bool"
+	IAMIpAddress                 = "IpAddress"
+	IAMNotIpAddress              = "NotIpAddress"
+	IAMArnEquals                 = "ArnEquals"
+	IAMArnNotEquals              = "ArnNotEquals"
+	IAMArnLike                   = "ArnLike"
+	IAMArnNotLike                = "ArnNotLike"
+	IAMNull                      = "Null"
+
+	iamIfExistsSuffix = "IfExists"
+)
+
+// iamConditionFunc tests one value of the policy against one value of the request.
+type iamConditionFunc func(policyValue, requestValue string) bool
+
+var iamConditionFuncs = map[string]iamConditionFunc{
+	IAMStringEquals:             func(p, r string) bool { return p == r },
+	IAMStringEqualsIgnoreCase:   strings.EqualFold,
+	IAMStringLike:               IAMWildcardMatch,
+	IAMNumericEquals:            compareIAMNumeric(func(c int) bool { return c == 0 }),
+	IAMNumericLessThan:          compareIAMNumeric(func(c int) bool { return c < 0 }),
+	IAMNumericLessThanEquals:    compareIAMNumeric(func(c int) bool { return c <= 0 }),
+	IAMNumericGreaterThan:       compareIAMNumeric(func(c int) bool { return c > 0 }),
+	IAMNumericGreaterThanEquals: compareIAMNumeric(func(c int) bool { return c >= 0 }),
+	IAMDateEquals:               compareIAMDate(func(c int) bool { return c == 0 }),
+	IAMDateLessThan:             compareIAMDate(func(c int) bool { return c < 0 }),
+	IAMDateLessThanEquals:       compareIAMDate(func(c int) bool { return c <= 0 }),
+	IAMDateGreaterThan:          compareIAMDate(func(c int) bool { return c > 0 }),
+	IAMDateGreaterThanEquals:    compareIAMDate(func(c int) bool { return c >= 0 }),
+	IAMBool:                     matchIAMBool,
+	IAMIpAddress:                matchIAMIpAddress,
+	IAMArnEquals:                IAMWildcardMatch,
+	IAMArnLike:                  IAMWildcardMatch,
+}
+
+// Negated operators, which match when none of the values of the base operator matches.
+var iamNegatedConditions = map[string]string{
+	IAMStringNotEquals:           IAMStringEquals,
+	IAMStringNotEqualsIgnoreCase: IAMStringEqualsIgnoreCase,
+	IAMStringNotLike:             IAMStringLike,
+	IAMNumericNotEquals:          IAMNumericEquals,
+	IAMDateNotEquals:             IAMDateEquals,
+	IAMNotIpAddress:              IAMIpAddress,
+	IAMArnNotEquals:              IAMArnEquals,
+	IAMArnNotLike:                IAMArnLike,
+}
+
+// MatchIAMCondition tests the values of a condition key in the request against the values
+// of the policy with the condition operator. Argument present tells whether the condition
+// key exists in the request at all.
+//
+// A condition matches if any request value matches any policy value, negated operators
+// match if none does. A condition on an absent key only matches for negated operators
+// and the "...IfExists" variants. Unknown operators never match.
+func MatchIAMCondition(operator string, policyValues, requestValues []string, present bool) bool {
+	if operator == IAMNull {
+		for _, value := range policyValues {
+			if isNull, err := strconv.ParseBool(value); err == nil && isNull != present {
+				return true
+			}
+		}
+		return false
+	}
+	var ifExists = strings.HasSuffix(operator, iamIfExistsSuffix)
+	operator = strings.TrimSuffix(operator, iamIfExistsSuffix)
+	var base, negated = iamNegatedConditions[operator]
+	if !negated {
+		base = operator
+	}
+	f, exist := iamConditionFuncs[base]
+	if !exist {
+		return false
+	}
+	if !present {
+		return ifExists || negated
+	}
+	var matched bool
+	for _, requestValue := range requestValues {
+		for _, policyValue := range policyValues {
+			if f(policyValue, requestValue) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	return matched != negated
+}
+
+func validateIAMCondition(operator string, values []string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no value")
+	}
+	var base = strings.TrimSuffix(operator, iamIfExistsSuffix)
+	if negatedBase, negated := iamNegatedConditions[base]; negated {
+		base = negatedBase
+	}
+	var parse func(value string) error
+	switch {
+	case operator == IAMNull, base == IAMBool:
+		parse = func(value string) (err error) {
+			_, err = strconv.ParseBool(value)
+			return
+		}
+	case strings.HasPrefix(base, "Numeric"):
+		parse = func(value string) (err error) {
+			_, err = strconv.ParseFloat(value, 64)
+			return
+		}
+	case strings.HasPrefix(base, "Date"):
+		parse = func(value string) (err error) {
+			_, err = parseIAMDate(value)
+			return
+		}
+	case base == IAMIpAddress:
+		parse = func(value string) (err error) {
+			_, err = parseIAMIPNet(value)
+			return
+		}
+	default:
+	}
+	if _, exist := iamConditionFuncs[base]; !exist && operator != IAMNull {
+		return fmt.Errorf("unsupported condition operator")
+	}
+	if parse == nil {
+		return nil
+	}
+	for _, value := range values {
+		if err := parse(value); err != nil {
+			return fmt.Errorf("invalid value %v", value)
+		}
+	}
+	return nil
+}
+
+func compareIAMNumeric(test func(c int) bool) iamConditionFunc {
+	return func(policyValue, requestValue string) bool {
+		p, err := strconv.ParseFloat(policyValue, 64)
+		if err != nil {
+			return false
+		}
+		r, err := strconv.ParseFloat(requestValue, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case r < p:
+			return test(-1)
+		case r > p:
+			return test(1)
+		default:
+		}
+		return test(0)
+	}
+}
+
+func compareIAMDate(test func(c int) bool) iamConditionFunc {
+	return func(policyValue, requestValue string) bool {
+		p, err := parseIAMDate(policyValue)
+		if err != nil {
+			return false
+		}
+		r, err := parseIAMDate(requestValue)
+		if err != nil {
+			return false
+		}
+		switch {
+		case r.Before(p):
+			return test(-1)
+		case r.After(p):
+			return test(1)
+		default:
+		}
+		return test(0)
+	}
+}
+
+var iamDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z",
+	"20060102T150405Z",
+	"2006-01-02",
+}
+
+// parseIAMDate parses a date in the ISO 8601 formats or in epoch seconds.
+func parseIAMDate(value string) (t time.Time, err error) {
+	if epoch, parseErr := strconv.ParseInt(value, 10, 64); parseErr == nil {
+		return time.Unix(epoch, 0), nil
+	}
+	for _, layout := range iamDateLayouts {
+		if t, err = time.Parse(layout, value); err == nil {
+			return
+		}
+	}
+	return
+}
+
+func matchIAMBool(policyValue, requestValue string) bool {
+	p, err := strconv.ParseBool(policyValue)
+	if err != nil {
+		return false
+	}
+	r, err := strconv.ParseBool(requestValue)
+	if err != nil {
+		return false
+	}
+	return p == r
+}
+
+func matchIAMIpAddress(policyValue, requestValue string) bool {
+	ipNet, err := parseIAMIPNet(policyValue)
+	if err != nil {
+		return false
+	}
+	var ip = net.ParseIP(requestValue)
+	return ip != nil && ipNet.Contains(ip)
+}
+
+// parseIAMIPNet parses an IP address or a CIDR block.
+func parseIAMIPNet(value string) (ipNet *net.IPNet, err error) {
+	if !strings.Contains(value, "/") {
+		var ip = net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address: %v", value)
+		}
+		var bits = 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, ipNet, err = net.ParseCIDR(value)
+	return
+}
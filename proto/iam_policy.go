@@ -0,0 +1,329 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IAMPolicy is an identity-based policy document attached to a user. It follows the
+// AWS IAM policy grammar, so one document can describe which actions the user is allowed
+// or denied on which volumes and objects, under which conditions.
+//
+// Example:
+//
+//	{
+//	  "Version": "2012-10-17",
+//	  "Statement": [
+//	    {
+//	      "Effect": "Allow",
+//	      "Action": ["s3:GetObject", "s3:HeadObject"],
+//	      "Resource": "arn:aws:s3:::bucket/prefix/*"
+//	    },
+//	    {
+//	      "Effect": "Deny",
+//	      "Action": "s3:*",
+//	      "Resource": "*",
+//	      "Condition": {"Bool": {"aws:SecureTransport": "false"}}
+//	    }
+//	  ]
+//	}
+//
+// Reference: https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_elements.html
+type IAMPolicy struct {
+	Version    string         `json:"Version,omitempty"`
+	Statements []IAMStatement `json:"Statement"`
+}
+
+type IAMEffect string
+
+const (
+	IAMEffectAllow IAMEffect = "Allow"
+	IAMEffectDeny  IAMEffect = "Deny"
+)
+
+const (
+	IAMPolicyVersion  = "2012-10-17"
+	IAMResourcePrefix = "arn:aws:s3:::"
+)
+
+// IAMDecision is the result of evaluating an identity policy against a request.
+type IAMDecision uint8
+
+const (
+	// No statement matches the request, the request is implicitly denied
+	// unless it is granted by something else.
+	IAMDecisionNotApplicable IAMDecision = iota
+	IAMDecisionAllow
+	// A deny statement matches the request, which overrides any allow.
+	IAMDecisionDeny
+)
+
+func (d IAMDecision) String() string {
+	switch d {
+	case IAMDecisionAllow:
+		return "allowed"
+	case IAMDecisionDeny:
+		return "explicitDeny"
+	default:
+	}
+	return "implicitDeny"
+}
+
+// IAMValues is a list of strings which can be written as either a single string
+// or an array of strings in the policy document.
+type IAMValues []string
+
+func (v *IAMValues) UnmarshalJSON(b []byte) (err error) {
+	var value interface{}
+	if err = json.Unmarshal(b, &value); err != nil {
+		return
+	}
+	switch val := value.(type) {
+	case string:
+		*v = IAMValues{val}
+	case bool, float64:
+		*v = IAMValues{fmt.Sprintf("%v", val)}
+	case []interface{}:
+		var values = make(IAMValues, 0, len(val))
+		for _, elem := range val {
+			switch e := elem.(type) {
+			case string:
+				values = append(values, e)
+			case bool, float64:
+				values = append(values, fmt.Sprintf("%v", e))
+			default:
+				return fmt.Errorf("invalid policy value: %v", elem)
+			}
+		}
+		*v = values
+	case nil:
+		*v = nil
+	default:
+		return fmt.Errorf("invalid policy value: %v", value)
+	}
+	return
+}
+
+// IAMCondition maps condition operators to the condition keys and values they test,
+// e.g. {"StringLike": {"s3:prefix": ["home/*"]}}.
+type IAMCondition map[string]map[string]IAMValues
+
+type IAMStatement struct {
+	Sid         string       `json:"Sid,omitempty"`
+	Effect      IAMEffect    `json:"Effect"`
+	Action      IAMValues    `json:"Action,omitempty"`
+	NotAction   IAMValues    `json:"NotAction,omitempty"`
+	Resource    IAMValues    `json:"Resource,omitempty"`
+	NotResource IAMValues    `json:"NotResource,omitempty"`
+	Condition   IAMCondition `json:"Condition,omitempty"`
+}
+
+// IAMContext holds the condition keys of a request, such as "aws:SourceIp" or "s3:prefix".
+// Condition keys are case-insensitive.
+type IAMContext map[string][]string
+
+func (c IAMContext) Get(key string) (values []string, exist bool) {
+	if values, exist = c[key]; exist {
+		return
+	}
+	for k, v := range c {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// ParseIAMPolicy parses and validates an identity policy document.
+func ParseIAMPolicy(data []byte) (policy *IAMPolicy, err error) {
+	policy = new(IAMPolicy)
+	if err = json.Unmarshal(data, policy); err != nil {
+		return nil, err
+	}
+	if err = policy.Validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (p *IAMPolicy) Validate() error {
+	if p.Version != "" && p.Version != IAMPolicyVersion {
+		return fmt.Errorf("unsupported policy version: %v", p.Version)
+	}
+	if len(p.Statements) == 0 {
+		return fmt.Errorf("policy has no statement")
+	}
+	for i := range p.Statements {
+		if err := p.Statements[i].Validate(); err != nil {
+			return fmt.Errorf("statement %v: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *IAMStatement) Validate() error {
+	if s.Effect != IAMEffectAllow && s.Effect != IAMEffectDeny {
+		return fmt.Errorf("invalid effect: %v", s.Effect)
+	}
+	if (len(s.Action) == 0) == (len(s.NotAction) == 0) {
+		return fmt.Errorf("exactly one of Action and NotAction must be specified")
+	}
+	if (len(s.Resource) == 0) == (len(s.NotResource) == 0) {
+		return fmt.Errorf("exactly one of Resource and NotResource must be specified")
+	}
+	for _, resource := range append(s.Resource, s.NotResource...) {
+		if resource != "*" && !strings.HasPrefix(resource, "arn:") {
+			return fmt.Errorf("invalid resource: %v", resource)
+		}
+	}
+	for operator, conditions := range s.Condition {
+		for key, values := range conditions {
+			if err := validateIAMCondition(operator, values); err != nil {
+				return fmt.Errorf("condition %v on %v: %v", operator, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates the policy against an action on a resource. An explicit deny from any
+// statement overrides all allows.
+func (p *IAMPolicy) Evaluate(action Action, resource string, context IAMContext) IAMDecision {
+	var decision = IAMDecisionNotApplicable
+	for _, s := range p.Statements {
+		if !s.matches(action, resource, context) {
+			continue
+		}
+		if s.Effect == IAMEffectDeny {
+			return IAMDecisionDeny
+		}
+		decision = IAMDecisionAllow
+	}
+	return decision
+}
+
+func (s *IAMStatement) matches(action Action, resource string, context IAMContext) bool {
+	if len(s.Action) > 0 && !matchIAMActions(s.Action, action) {
+		return false
+	}
+	if len(s.NotAction) > 0 && matchIAMActions(s.NotAction, action) {
+		return false
+	}
+	if len(s.Resource) > 0 && !matchIAMResources(s.Resource, resource) {
+		return false
+	}
+	if len(s.NotResource) > 0 && matchIAMResources(s.NotResource, resource) {
+		return false
+	}
+	for operator, conditions := range s.Condition {
+		for key, values := range conditions {
+			requestValues, present := context.Get(key)
+			if !MatchIAMCondition(operator, values, requestValues, present) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Names of the S3 permissions which differ from the names of the object storage actions.
+var iamS3ActionAliases = map[string]string{
+	"ListBucket":                 "ListObjects",
+	"ListAllMyBuckets":           "ListBuckets",
+	"ListBucketMultipartUploads": "ListMultipartUploads",
+	"ListMultipartUploadParts":   "ListParts",
+	"GetBucketPublicAccessBlock": "GetPublicAccessBlock",
+	"PutBucketPublicAccessBlock": "PutPublicAccessBlock",
+}
+
+// normalizeIAMAction converts the action pattern written in policy documents, such as
+// "s3:GetObject", "s3:Get*" or "posix:Read", to the pattern of action names.
+func normalizeIAMAction(pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, "s3:"):
+		var name = strings.TrimPrefix(pattern, "s3:")
+		if alias, exist := iamS3ActionAliases[name]; exist {
+			name = alias
+		}
+		return OSSActionPrefix + name
+	case strings.HasPrefix(pattern, "posix:"):
+		return POSIXActionPrefix + strings.TrimPrefix(pattern, "posix:")
+	default:
+	}
+	return pattern
+}
+
+// ParseIAMAction parses an action written either as a policy action like "s3:GetObject"
+// or as an action name like "action:oss:GetObject".
+func ParseIAMAction(str string) Action {
+	return ParseAction(normalizeIAMAction(str))
+}
+
+func matchIAMActions(patterns IAMValues, action Action) bool {
+	for _, pattern := range patterns {
+		if IAMWildcardMatch(normalizeIAMAction(pattern), action.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchIAMResources(patterns IAMValues, resource string) bool {
+	for _, pattern := range patterns {
+		if IAMWildcardMatch(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// IAMResourceARN returns the resource name of a volume, or of an object in the volume
+// if key is not empty, e.g. "arn:aws:s3:::bucket/prefix/object".
+func IAMResourceARN(volume, key string) string {
+	if key = strings.TrimPrefix(key, "/"); key == "" {
+		return IAMResourcePrefix + volume
+	}
+	return IAMResourcePrefix + volume + "/" + key
+}
+
+// IAMWildcardMatch matches a value against a pattern in which '*' matches any sequence
+// of characters and '?' matches any single character.
+func IAMWildcardMatch(pattern, value string) bool {
+	var p, v = 0, 0
+	var starP, starV = -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starV = p, v
+			p++
+		case starP != -1:
+			p = starP + 1
+			starV++
+			v = starV
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"testing"
+)
+
+func TestIAMWildcardMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{"*", "anything", true},
+		{"arn:aws:s3:::bucket/prefix/*", "arn:aws:s3:::bucket/prefix/a/b.txt", true},
+		{"arn:aws:s3:::bucket/prefix/*", "arn:aws:s3:::bucket/prefix", false},
+		{"arn:aws:s3:::bucket/prefix/*", "arn:aws:s3:::bucket/other/a.txt", false},
+		{"arn:aws:s3:::bucket/?.txt", "arn:aws:s3:::bucket/a.txt", true},
+		{"arn:aws:s3:::bucket/?.txt", "arn:aws:s3:::bucket/ab.txt", false},
+		{"action:oss:Get*", "action:oss:GetObject", true},
+		{"action:oss:Get*", "action:oss:PutObject", false},
+	}
+	for _, c := range cases {
+		if match := IAMWildcardMatch(c.pattern, c.value); match != c.match {
+			t.Errorf("pattern %v value %v: expect %v, but %v", c.pattern, c.value, c.match, match)
+		}
+	}
+}
+
+func TestIAMPolicyEvaluate(t *testing.T) {
+	policy, err := ParseIAMPolicy([]byte(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["s3:GetObject", "s3:HeadObject"],
+				"Resource": "arn:aws:s3:::bucket/prefix/*"
+			},
+			{
+				"Effect": "Allow",
+				"Action": "s3:ListBucket",
+				"Resource": "arn:aws:s3:::bucket",
+				"Condition": {
+					"StringLike": {"s3:prefix": "prefix/*"},
+					"NumericLessThanEquals": {"s3:max-keys": 100}
+				}
+			},
+			{
+				"Effect": "Deny",
+				"Action": "s3:*",
+				"Resource": "*",
+				"Condition": {"Bool": {"aws:SecureTransport": false}}
+			}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+	secure := IAMContext{"aws:SecureTransport": {"true"}}
+	cases := []struct {
+		action   Action
+		key      string
+		context  IAMContext
+		decision IAMDecision
+	}{
+		{OSSGetObjectAction, "prefix/a.txt", secure, IAMDecisionAllow},
+		{OSSHeadObjectAction, "prefix/a/b.txt", secure, IAMDecisionAllow},
+		{OSSGetObjectAction, "other/a.txt", secure, IAMDecisionNotApplicable},
+		{OSSPutObjectAction, "prefix/a.txt", secure, IAMDecisionNotApplicable},
+		{OSSGetObjectAction, "prefix/a.txt", IAMContext{"aws:SecureTransport": {"false"}}, IAMDecisionDeny},
+		{OSSListObjectsAction, "", IAMContext{"aws:SecureTransport": {"true"}, "s3:prefix": {"prefix/"}, "s3:max-keys": {"10"}}, IAMDecisionAllow},
+		{OSSListObjectsAction, "", IAMContext{"aws:SecureTransport": {"true"}, "s3:prefix": {"prefix/"}, "s3:max-keys": {"1000"}}, IAMDecisionNotApplicable},
+		{OSSListObjectsAction, "", IAMContext{"aws:SecureTransport": {"true"}, "s3:prefix": {"other/"}, "s3:max-keys": {"10"}}, IAMDecisionNotApplicable},
+		{OSSListObjectsAction, "", IAMContext{"AWS:SecureTransport": {"true"}, "S3:Prefix": {"prefix/"}, "s3:max-keys": {"10"}}, IAMDecisionAllow},
+	}
+	for i, c := range cases {
+		if decision := policy.Evaluate(c.action, IAMResourceARN("bucket", c.key), c.context); decision != c.decision {
+			t.Errorf("case %v: expect %v, but %v", i, c.decision, decision)
+		}
+	}
+}
+
+func TestIAMPolicyValidate(t *testing.T) {
+	invalids := []string{
+		`{"Statement": []}`,
+		`{"Version": "2000-01-01", "Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}]}`,
+		`{"Statement": [{"Effect": "Permit", "Action": "s3:*", "Resource": "*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Resource": "*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "NotAction": "s3:GetObject", "Resource": "*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "bucket/*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"StringMatches": {"s3:prefix": "a"}}}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"NumericEquals": {"s3:max-keys": "ten"}}}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"DateLessThan": {"aws:CurrentTime": "tomorrow"}}}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"IpAddress": {"aws:SourceIp": "10.0.0.0/33"}}}]}`,
+	}
+	for _, invalid := range invalids {
+		if _, err := ParseIAMPolicy([]byte(invalid)); err == nil {
+			t.Errorf("expect error for policy %v", invalid)
+		}
+	}
+}
+
+func TestMatchIAMCondition(t *testing.T) {
+	cases := []struct {
+		operator      string
+		policyValues  []string
+		requestValues []string
+		present       bool
+		match         bool
+	}{
+		{IAMStringEquals, []string{"a", "b"}, []string{"b"}, true, true},
+		{IAMStringNotEquals, []string{"a", "b"}, []string{"b"}, true, false},
+		{IAMStringNotEquals, []string{"a"}, nil, false, true},
+		{IAMStringEquals, []string{"a"}, nil, false, false},
+		{IAMStringEquals + "IfExists", []string{"a"}, nil, false, true},
+		{IAMStringEqualsIgnoreCase, []string{"ABC"}, []string{"abc"}, true, true},
+		{IAMNumericGreaterThan, []string{"10"}, []string{"11"}, true, true},
+		{IAMNumericGreaterThan, []string{"10"}, []string{"10"}, true, false},
+		{IAMNumericNotEquals, []string{"10"}, []string{"10.0"}, true, false},
+		{IAMDateLessThan, []string{"2020-01-01T00:00:00Z"}, []string{"2019-12-31T23:59:59.000Z"}, true, true},
+		{IAMDateGreaterThanEquals, []string{"2020-01-01T00:00:00Z"}, []string{"1577836800"}, true, true},
+		{IAMBool, []string{"true"}, []string{"false"}, true, false},
+		{IAMIpAddress, []string{"192.168.0.0/16"}, []string{"192.168.3.4"}, true, true},
+		{IAMNotIpAddress, []string{"192.168.0.0/16", "10.0.0.1"}, []string{"10.0.0.1"}, true, false},
+		{IAMArnLike, []string{"arn:aws:iam::*:user/dev-*"}, []string{"arn:aws:iam::123:user/dev-alice"}, true, true},
+		{IAMArnNotEquals, []string{"arn:aws:iam::123:user/alice"}, []string{"arn:aws:iam::123:user/bob"}, true, true},
+		{IAMNull, []string{"true"}, nil, false, true},
+		{IAMNull, []string{"true"}, []string{"v"}, true, false},
+		{"StringMatches", []string{"a"}, []string{"a"}, true, false},
+	}
+	for i, c := range cases {
+		if match := MatchIAMCondition(c.operator, c.policyValues, c.requestValues, c.present); match != c.match {
+			t.Errorf("case %v %v: expect %v, but %v", i, c.operator, c.match, match)
+		}
+	}
+}
+
+func TestUserPolicyIsAllowed(t *testing.T) {
+	policy := NewUserPolicy()
+	policy.AddOwnVol("own")
+	policy.SetPerm("shared", BuiltinPermissionReadOnly)
+	identityPolicy, err := ParseIAMPolicy([]byte(`{
+		"Statement": [
+			{"Effect": "Allow", "Action": "s3:PutObject", "Resource": "arn:aws:s3:::shared/upload/*"},
+			{"Effect": "Deny", "Action": "s3:DeleteObject", "Resource": "arn:aws:s3:::own/*"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+	policy.SetIdentityPolicy(identityPolicy)
+	cases := []struct {
+		action  Action
+		volume  string
+		key     string
+		allowed bool
+	}{
+		{OSSGetObjectAction, "own", "a.txt", true},
+		{OSSDeleteObjectAction, "own", "a.txt", false},
+		{OSSGetObjectAction, "shared", "a.txt", true},
+		{OSSPutObjectAction, "shared", "a.txt", false},
+		{OSSPutObjectAction, "shared", "upload/a.txt", true},
+		{OSSGetObjectAction, "other", "a.txt", false},
+	}
+	for i, c := range cases {
+		if allowed := policy.IsAllowed(c.action, c.volume, c.key, nil); allowed != c.allowed {
+			t.Errorf("case %v: expect %v, but %v", i, c.allowed, allowed)
+		}
+	}
+}
@@ -172,6 +172,9 @@ type DataPartitionInfo struct {
 	OfflinePeerID           uint64
 	FileInCoreMap           map[string]*FileInCore
 	FilesWithMissingReplica map[string]int64 // key: file name, value: last time when a missing replica is found
+	ECDataNum               uint8
+	ECParityNum             uint8
+	StorageClass            uint8
 }
 
 //FileInCore define file in data partition
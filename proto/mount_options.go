@@ -47,6 +47,8 @@ const (
 	EnableXattr
 	NearRead
 	EnablePosixACL
+	Snapshot
+	ECMigrate
 
 	MaxMountOption
 )
@@ -112,6 +114,8 @@ func InitMountOptions(opts []MountOption) {
 	opts[MaxCPUs] = MountOption{"maxcpus", "The maximum number of CPUs that can be executing", "", int64(-1)}
 	opts[EnableXattr] = MountOption{"enableXattr", "Enable xattr support", "", false}
 	opts[EnablePosixACL] = MountOption{"enablePosixACL", "enable posix ACL support", "", false}
+	opts[Snapshot] = MountOption{"snapshot", "Mount the volume snapshot read-only", "", ""}
+	opts[ECMigrate] = MountOption{"ecMigrate", "Migrate cold files into erasure coded data partitions", "", false}
 
 	for i := 0; i < MaxMountOption; i++ {
 		flag.StringVar(&opts[i].cmdlineValue, opts[i].keyword, "", opts[i].description)
@@ -241,4 +245,6 @@ type MountOptions struct {
 	EnableXattr    bool
 	NearRead       bool
 	EnablePosixACL bool
+	Snapshot       string
+	ECMigrate      bool
 }
@@ -0,0 +1,87 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import "time"
+
+// Object lock protects objects from being deleted or overwritten (WORM). The state is kept in extend attributes
+// of the inode, which are enforced by the meta node for all clients.
+const (
+	XAttrKeyObjectLockMode        = "oss:object-lock-mode"
+	XAttrKeyObjectLockRetainUntil = "oss:object-lock-retain-until-date" // RFC3339 format
+	XAttrKeyObjectLockLegalHold   = "oss:object-lock-legal-hold"
+
+	// In governance mode the retention can be shortened or removed by the privileged users,
+	// while in compliance mode it can only be extended until the retain-until date.
+	ObjectLockModeGovernance = "GOVERNANCE"
+	ObjectLockModeCompliance = "COMPLIANCE"
+
+	ObjectLockLegalHoldOn  = "ON"
+	ObjectLockLegalHoldOff = "OFF"
+
+	// The inode retained as a version of an object is linked by the version record,
+	// so the dentry of a locked inode can be removed or replaced once it is retained as a version.
+	XAttrKeyObjectVersion = "oss:version"
+)
+
+// ObjectLockXAttrKeys are the extend attributes holding the object lock state.
+var ObjectLockXAttrKeys = []string{XAttrKeyObjectLockMode, XAttrKeyObjectLockRetainUntil, XAttrKeyObjectLockLegalHold}
+
+// ObjectLock is the object lock state of an inode.
+type ObjectLock struct {
+	Mode        string
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+// ParseObjectLock parses the object lock state from the values of the extend attributes.
+// The retention does not take effect until both the mode and a valid retain-until date are set,
+// so the two attributes can be set in any order.
+func ParseObjectLock(mode, retainUntil, legalHold string) *ObjectLock {
+	var lock = &ObjectLock{
+		Mode:      mode,
+		LegalHold: legalHold == ObjectLockLegalHoldOn,
+	}
+	if len(mode) > 0 {
+		lock.RetainUntil, _ = time.Parse(time.RFC3339, retainUntil)
+	}
+	return lock
+}
+
+// Retained returns whether the retention period is active.
+func (l *ObjectLock) Retained(now time.Time) bool {
+	return len(l.Mode) > 0 && now.Before(l.RetainUntil)
+}
+
+// Locked returns whether the inode can not be deleted or modified.
+func (l *ObjectLock) Locked(now time.Time) bool {
+	return l.LegalHold || l.Retained(now)
+}
+
+// AllowXAttrChange checks whether the extend attribute of the object lock can be set to the value,
+// or removed if the value is nil. An active compliance retention can only be extended.
+func (l *ObjectLock) AllowXAttrChange(key string, value []byte, now time.Time) bool {
+	if l.Mode != ObjectLockModeCompliance || !l.Retained(now) {
+		return true
+	}
+	switch key {
+	case XAttrKeyObjectLockMode:
+		return string(value) == ObjectLockModeCompliance
+	case XAttrKeyObjectLockRetainUntil:
+		retainUntil, err := time.Parse(time.RFC3339, string(value))
+		return err == nil && !retainUntil.Before(l.RetainUntil)
+	}
+	return true
+}
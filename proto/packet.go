@@ -98,6 +98,8 @@ const (
 	OpMetaRemoveXAttr     uint8 = 0x37
 	OpMetaListXAttr       uint8 = 0x38
 	OpMetaBatchGetXAttr   uint8 = 0x39
+	OpMetaCopyInode       uint8 = 0x3A // create an inode sharing the extents of specified inode
+	OpMetaExtentsReplace  uint8 = 0x3B // replace the extents of a migrated inode if they are not changed
 
 	// Operations: Master -> MetaNode
 	OpCreateMetaPartition           uint8 = 0x40
@@ -109,6 +111,8 @@ const (
 	OpAddMetaPartitionRaftMember    uint8 = 0x46
 	OpRemoveMetaPartitionRaftMember uint8 = 0x47
 	OpMetaPartitionTryToLeader      uint8 = 0x48
+	OpCreateMetaSnapshot            uint8 = 0x49
+	OpDeleteMetaSnapshot            uint8 = 0x4A
 
 	// Operations: Master -> DataNode
 	OpCreateDataPartition           uint8 = 0x60
@@ -121,6 +125,7 @@ const (
 	OpAddDataPartitionRaftMember    uint8 = 0x67
 	OpRemoveDataPartitionRaftMember uint8 = 0x68
 	OpDataPartitionTryToLeader      uint8 = 0x69
+	OpRepairErasureCodedShard       uint8 = 0x6A
 
 	// Operations: MultipartInfo
 	OpCreateMultipart  uint8 = 0x70
@@ -131,6 +136,23 @@ const (
 
 	OpBatchDeleteExtent uint8 = 0x75 // SDK to MetaNode
 
+	// Operations: VersionInfo
+	OpCreateVersion uint8 = 0x76
+	OpGetVersion    uint8 = 0x77
+	OpRemoveVersion uint8 = 0x78
+	OpListVersions  uint8 = 0x79
+
+	// Operations: rename transaction
+	OpMetaTxRenamePrepare uint8 = 0x7A
+	OpMetaTxRenameCommit  uint8 = 0x7B
+	OpMetaTxRenameAbort   uint8 = 0x7C
+	OpMetaTxRenameGet     uint8 = 0x7D
+
+	// Operations: ChangeLogInfo
+	OpAppendChangeLog uint8 = 0x7E
+	OpListChangeLogs  uint8 = 0x7F
+	OpRemoveChangeLog uint8 = 0x80
+
 	//Operations: MetaNode Leader -> MetaNode Follower
 	OpMetaBatchDeleteInode  uint8 = 0x90
 	OpMetaBatchDeleteDentry uint8 = 0x91
@@ -138,6 +160,7 @@ const (
 	OpMetaBatchEvictInode   uint8 = 0x93
 
 	// Commons
+	OpQuotaExceededErr uint8 = 0xF1
 	OpIntraGroupNetErr uint8 = 0xF3
 	OpArgMismatchErr   uint8 = 0xF4
 	OpNotExistErr      uint8 = 0xF5
@@ -351,8 +374,14 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpRemoveMetaPartitionRaftMember"
 	case OpMetaPartitionTryToLeader:
 		m = "OpMetaPartitionTryToLeader"
+	case OpCreateMetaSnapshot:
+		m = "OpCreateMetaSnapshot"
+	case OpDeleteMetaSnapshot:
+		m = "OpDeleteMetaSnapshot"
 	case OpDataPartitionTryToLeader:
 		m = "OpDataPartitionTryToLeader"
+	case OpRepairErasureCodedShard:
+		m = "OpRepairErasureCodedShard"
 	case OpMetaDeleteInode:
 		m = "OpMetaDeleteInode"
 	case OpMetaBatchDeleteInode:
@@ -369,6 +398,10 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaListXAttr"
 	case OpMetaBatchGetXAttr:
 		m = "OpMetaBatchGetXAttr"
+	case OpMetaCopyInode:
+		m = "OpMetaCopyInode"
+	case OpMetaExtentsReplace:
+		m = "OpMetaExtentsReplace"
 	case OpCreateMultipart:
 		m = "OpCreateMultipart"
 	case OpGetMultipart:
@@ -381,6 +414,28 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpListMultiparts"
 	case OpBatchDeleteExtent:
 		m = "OpBatchDeleteExtent"
+	case OpCreateVersion:
+		m = "OpCreateVersion"
+	case OpGetVersion:
+		m = "OpGetVersion"
+	case OpRemoveVersion:
+		m = "OpRemoveVersion"
+	case OpListVersions:
+		m = "OpListVersions"
+	case OpMetaTxRenamePrepare:
+		m = "OpMetaTxRenamePrepare"
+	case OpMetaTxRenameCommit:
+		m = "OpMetaTxRenameCommit"
+	case OpMetaTxRenameAbort:
+		m = "OpMetaTxRenameAbort"
+	case OpMetaTxRenameGet:
+		m = "OpMetaTxRenameGet"
+	case OpAppendChangeLog:
+		m = "OpAppendChangeLog"
+	case OpListChangeLogs:
+		m = "OpListChangeLogs"
+	case OpRemoveChangeLog:
+		m = "OpRemoveChangeLog"
 	}
 	return
 }
@@ -418,6 +473,8 @@ func (p *Packet) GetResultMsg() (m string) {
 		m = "NotPerm"
 	case OpNotEmtpy:
 		m = "DirNotEmpty"
+	case OpQuotaExceededErr:
+		m = "QuotaExceededErr"
 	default:
 		return fmt.Sprintf("Unknown ResultCode(%v)", p.ResultCode)
 	}
@@ -0,0 +1,70 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// RootQuotaID is the ID of the volume-wide quota which is bound to the root inode.
+	RootQuotaID uint32 = 0
+
+	// QuotaXAttrKey is the extend attribute holding the IDs of the quotas an inode is accounted to.
+	QuotaXAttrKey = "cfs.quota"
+)
+
+// QuotaInfo defines a quota limiting the number of inodes and bytes under a directory.
+// A limit of zero means unlimited.
+type QuotaInfo struct {
+	QuotaID   uint32
+	RootInode uint64
+	Path      string
+	MaxFiles  uint64
+	MaxBytes  uint64
+	UsedFiles uint64
+	UsedBytes uint64
+	Exceeded  bool
+}
+
+// QuotaUsage defines the usage of a quota reported by a meta partition.
+type QuotaUsage struct {
+	QuotaID   uint32
+	UsedFiles uint64
+	UsedBytes uint64
+}
+
+// QuotaIDsToXAttr encodes the quota IDs as the value of QuotaXAttrKey.
+func QuotaIDsToXAttr(ids []uint32) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(values, ",")
+}
+
+// QuotaIDsFromXAttr decodes the value of QuotaXAttrKey. Malformed IDs are skipped.
+func QuotaIDsFromXAttr(value string) []uint32 {
+	ids := make([]uint32, 0)
+	for _, v := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint32(id))
+	}
+	return ids
+}
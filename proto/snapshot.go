@@ -0,0 +1,28 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// SnapshotInfo defines the read-only snapshot of a volume, which freezes the metadata of all the meta partitions.
+type SnapshotInfo struct {
+	ID         uint64 `json:"id"`
+	Name       string `json:"name"`
+	CreateTime int64  `json:"ctime"`
+}
+
+// MetaSnapshotRequest defines the request to create or delete the snapshot of a meta partition.
+type MetaSnapshotRequest struct {
+	PartitionID uint64
+	SnapshotID  uint64
+}
@@ -0,0 +1,22 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// Storage classes of the data partitions. The cold data partitions are created in the cold zone of the volume,
+// which is usually made up of cheaper data nodes, and are only written by the files of the cold storage class.
+const (
+	StorageClassStandard uint8 = iota
+	StorageClassCold
+)
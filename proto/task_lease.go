@@ -0,0 +1,25 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// TaskLease defines the exclusive right to run a cluster-wide background task, such as purging the
+// trash of a volume. The lease is granted by the master leader and is held by Owner until it expires
+// or is renewed. TTL is in seconds.
+type TaskLease struct {
+	Name    string
+	Owner   string
+	TTL     int64
+	Granted bool
+}
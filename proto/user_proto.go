@@ -94,15 +94,17 @@ type AKUser struct {
 }
 
 type UserInfo struct {
-	UserID      string       `json:"user_id" graphql:"user_id"`
-	AccessKey   string       `json:"access_key" graphql:"access_key"`
-	SecretKey   string       `json:"secret_key" graphql:"secret_key"`
-	Policy      *UserPolicy  `json:"policy" graphql:"policy"`
-	UserType    UserType     `json:"user_type" graphql:"user_type"`
-	CreateTime  string       `json:"create_time" graphql:"create_time"`
-	Description string       `json:"description" graphql:"description"`
-	Mu          sync.RWMutex `json:"-" graphql:"-"`
-	EMPTY       bool         //graphql need ???
+	UserID        string       `json:"user_id" graphql:"user_id"`
+	AccessKey     string       `json:"access_key" graphql:"access_key"`
+	SecretKey     string       `json:"secret_key" graphql:"secret_key"`
+	Policy        *UserPolicy  `json:"policy" graphql:"policy"`
+	UserType      UserType     `json:"user_type" graphql:"user_type"`
+	CreateTime    string       `json:"create_time" graphql:"create_time"`
+	Description   string       `json:"description" graphql:"description"`
+	S3RequestRate uint64       `json:"s3_request_rate" graphql:"s3_request_rate"` // requests per second served by the ObjectNodes, 0 means unlimited
+	S3Bandwidth   uint64       `json:"s3_bandwidth" graphql:"s3_bandwidth"`       // bytes per second transferred by the ObjectNodes, 0 means unlimited
+	Mu            sync.RWMutex `json:"-" graphql:"-"`
+	EMPTY         bool         //graphql need ???
 }
 
 func (i *UserInfo) String() string {
@@ -126,6 +128,7 @@ type VolUser struct {
 type UserPolicy struct {
 	OwnVols        []string            `json:"own_vols" graphql:"own_vols"`
 	AuthorizedVols map[string][]string `json:"authorized_vols" graphql:"-"` // mapping: volume -> actions
+	IdentityPolicy *IAMPolicy          `json:"identity_policy,omitempty" graphql:"-"`
 	mu             sync.RWMutex
 }
 
@@ -172,6 +175,37 @@ func (policy *UserPolicy) IsAuthorized(volume, subdir string, action Action) boo
 	return false
 }
 
+// EvaluateIdentityPolicy evaluates the identity policy of the user against the action on the volume,
+// or on the object in the volume if key is not empty.
+func (policy *UserPolicy) EvaluateIdentityPolicy(action Action, volume, key string, context IAMContext) IAMDecision {
+	policy.mu.RLock()
+	var identityPolicy = policy.IdentityPolicy
+	policy.mu.RUnlock()
+	if identityPolicy == nil {
+		return IAMDecisionNotApplicable
+	}
+	return identityPolicy.Evaluate(action, IAMResourceARN(volume, key), context)
+}
+
+// IsAllowed checks both the identity policy and the volume permissions of the user.
+// An explicit deny of the identity policy overrides everything, including the ownership of the volume.
+func (policy *UserPolicy) IsAllowed(action Action, volume, key string, context IAMContext) bool {
+	switch policy.EvaluateIdentityPolicy(action, volume, key, context) {
+	case IAMDecisionDeny:
+		return false
+	case IAMDecisionAllow:
+		return true
+	default:
+	}
+	return policy.IsAuthorized(volume, "", action)
+}
+
+func (policy *UserPolicy) SetIdentityPolicy(identityPolicy *IAMPolicy) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	policy.IdentityPolicy = identityPolicy
+}
+
 func (policy *UserPolicy) AddOwnVol(volume string) {
 	policy.mu.Lock()
 	defer policy.mu.Unlock()
@@ -296,6 +330,7 @@ func CleanPolicy(policy *UserPolicy) (newUserPolicy *UserPolicy) {
 		}
 		newUserPolicy.AuthorizedVols[vol] = newAPI
 	}
+	newUserPolicy.IdentityPolicy = policy.IdentityPolicy
 	return
 }
 
@@ -332,6 +367,33 @@ func NewUserPermRemoveParam(userID, volmue string) *UserPermRemoveParam {
 	return &UserPermRemoveParam{UserID: userID, Volume: volmue}
 }
 
+type UserIdentityPolicyParam struct {
+	UserID string     `json:"user_id"`
+	Policy *IAMPolicy `json:"policy"`
+}
+
+type UserS3QoSParam struct {
+	UserID        string `json:"user_id"`
+	S3RequestRate uint64 `json:"s3_request_rate"`
+	S3Bandwidth   uint64 `json:"s3_bandwidth"`
+}
+
+type UserPolicySimulateParam struct {
+	UserID  string     `json:"user_id"`
+	Action  string     `json:"action"`
+	Volume  string     `json:"volume"`
+	Key     string     `json:"key"`
+	Context IAMContext `json:"context"`
+}
+
+type UserPolicySimulateResult struct {
+	UserID   string `json:"user_id"`
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	Decision string `json:"decision"` // decision of the identity policy
+	Allowed  bool   `json:"allowed"`
+}
+
 type UserTransferVolParam struct {
 	Volume  string `json:"volume"`
 	UserSrc string `json:"user_src"`
@@ -0,0 +1,58 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// fileCipher encrypts the data of the files of an encrypted volume with AES-CTR before the data is sent to
+// the data nodes. The counter block is made of a nonce derived from the data partition and the extent, and the
+// index of the cipher block in the extent, so the encrypted data keeps the size and the layout of the plain data.
+// The files of an encrypted volume are written copy-on-write, and the data of an extent is never rewritten in
+// place, so a counter block is never used to encrypt different data. The data is not authenticated, its
+// integrity is checked only by the CRC of the data nodes.
+type fileCipher struct {
+	block cipher.Block
+}
+
+func newFileCipher(key []byte) (*fileCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCipher{block: block}, nil
+}
+
+// XORKeyStream encrypts or decrypts in place the data located at the offset of the extent.
+func (c *fileCipher) XORKeyStream(partitionID, extentID uint64, offset int, data []byte) {
+	var location [16]byte
+	binary.BigEndian.PutUint64(location[:8], partitionID)
+	binary.BigEndian.PutUint64(location[8:], extentID)
+	nonce := sha256.Sum256(location[:])
+
+	var iv [aes.BlockSize]byte
+	copy(iv[:12], nonce[:12])
+	binary.BigEndian.PutUint32(iv[12:], uint32(offset/aes.BlockSize))
+	stream := cipher.NewCTR(c.block, iv[:])
+	if skip := offset % aes.BlockSize; skip > 0 {
+		var discard [aes.BlockSize]byte
+		stream.XORKeyStream(discard[:skip], discard[:skip])
+	}
+	stream.XORKeyStream(data, data)
+}
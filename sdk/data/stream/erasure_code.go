@@ -0,0 +1,309 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/data/wrapper"
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/erasure"
+	"github.com/chubaofs/chubaofs/util/errors"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// readErasureCoded reads the request from the data shards of an erasure coded extent unit by unit,
+// the units whose shard is unavailable are reconstructed from the other shards of the stripe.
+func (reader *ExtentReader) readErasureCoded(req *ExtentRequest) (readBytes int, err error) {
+	offset := req.FileOffset - int(reader.key.FileOffset) + int(reader.key.ExtentOffset)
+	for readBytes < req.Size {
+		shard, shardOffset := proto.ECShardLocation(uint64(offset+readBytes), reader.dp.ECDataNum)
+		size := util.Min(int(proto.ECStripeUnitSize-shardOffset%proto.ECStripeUnitSize), req.Size-readBytes)
+		data := req.Data[readBytes : readBytes+size]
+		if err = reader.readShard(reader.dp.Hosts[shard], int(shardOffset), data); err != nil {
+			log.LogWarnf("readErasureCoded: read shard failed, try degraded read, ino(%v) req(%v) shard(%v) host(%v) err(%v)",
+				reader.inode, req, shard, reader.dp.Hosts[shard], err)
+			if err = reader.readDegraded(shard, shardOffset, data); err != nil {
+				log.LogErrorf("readErasureCoded: degraded read failed, ino(%v) req(%v) shard(%v) err(%v)",
+					reader.inode, req, shard, err)
+				return
+			}
+		}
+		readBytes += size
+	}
+	log.LogDebugf("readErasureCoded: ino(%v) req(%v) readBytes(%v)", reader.inode, req, readBytes)
+	return
+}
+
+// readDegraded reconstructs the unit of the unavailable shard from the units of the same stripe on the other hosts.
+func (reader *ExtentReader) readDegraded(shard int, shardOffset uint64, data []byte) (err error) {
+	var encoder *erasure.Encoder
+	if encoder, err = erasure.NewEncoder(int(reader.dp.ECDataNum), int(reader.dp.ECParityNum)); err != nil {
+		return
+	}
+	unitOffset := shardOffset / proto.ECStripeUnitSize * proto.ECStripeUnitSize
+	shards := make([][]byte, len(reader.dp.Hosts))
+	available := 0
+	for i, host := range reader.dp.Hosts {
+		if i == shard || available == int(reader.dp.ECDataNum) {
+			continue
+		}
+		unit := make([]byte, proto.ECStripeUnitSize)
+		if e := reader.readShard(host, int(unitOffset), unit); e != nil {
+			log.LogWarnf("readDegraded: read shard failed, ino(%v) key(%v) shard(%v) host(%v) err(%v)",
+				reader.inode, reader.key, i, host, e)
+			continue
+		}
+		shards[i] = unit
+		available++
+	}
+	if available < int(reader.dp.ECDataNum) {
+		return fmt.Errorf("only %v of %v shards available", available, reader.dp.ECDataNum)
+	}
+	if err = encoder.Reconstruct(shards); err != nil {
+		return
+	}
+	copy(data, shards[shard][shardOffset-unitOffset:])
+	return
+}
+
+// readShard reads the data at the offset of the shard kept by the given host.
+func (reader *ExtentReader) readShard(host string, shardOffset int, data []byte) (err error) {
+	conn, err := StreamConnPool.GetConnect(host)
+	if err != nil {
+		return
+	}
+	defer func() {
+		StreamConnPool.PutConnect(conn, err != nil)
+	}()
+
+	reqPacket := NewReadPacket(reader.key, shardOffset, len(data), reader.inode, 0, true)
+	if err = reqPacket.WriteToConn(conn); err != nil {
+		return
+	}
+	for readBytes := 0; readBytes < len(data); {
+		replyPacket := NewReply(reqPacket.ReqID, reader.dp.PartitionID, reqPacket.ExtentID)
+		bufSize := util.Min(util.ReadBlockSize, len(data)-readBytes)
+		replyPacket.Data = data[readBytes : readBytes+bufSize]
+		if err = replyPacket.readFromConn(conn, proto.ReadDeadlineTime); err != nil {
+			return
+		}
+		if err = reader.checkStreamReply(reqPacket, replyPacket); err != nil {
+			return
+		}
+		readBytes += int(replyPacket.Size)
+	}
+	return
+}
+
+// ErasureCodeColdDays returns the days after which the files of the volume are migrated to erasure coded data
+// partitions, zero means the migration is disabled.
+func (client *ExtentClient) ErasureCodeColdDays() uint32 {
+	return client.dataWrapper.ErasureCodeColdDays()
+}
+
+// MigrateToErasureCoded rewrites the file into erasure coded data partitions. The extents of the file are replaced
+// only if the file is not modified during the migration, and the replaced extents are freed by the meta node.
+func (client *ExtentClient) MigrateToErasureCoded(inode uint64) error {
+	return client.migrate(inode, client.isErasureCoded, client.writeErasureCodedFile)
+}
+
+func (client *ExtentClient) writeErasureCodedFile(inode uint64, size int) (keys []proto.ExtentKey, err error) {
+	keys = make([]proto.ExtentKey, 0)
+	exclude := make(map[uint64]struct{})
+	for offset := 0; offset < size; {
+		var dp *wrapper.DataPartition
+		if dp, err = client.dataWrapper.GetErasureCodedPartition(exclude); err != nil {
+			return
+		}
+		chunk := util.Min(int(dp.ECDataNum)*proto.ECMaxShardSize, size-offset)
+		data := make([]byte, chunk)
+		var read int
+		if read, err = client.Read(inode, data, offset, chunk); err != nil && err != io.EOF {
+			return
+		}
+		if read != chunk {
+			err = fmt.Errorf("MigrateToErasureCoded: file changed, ino(%v) offset(%v) read(%v) expect(%v)",
+				inode, offset, read, chunk)
+			return
+		}
+		var key proto.ExtentKey
+		if key, err = client.writeErasureCodedExtent(dp, inode, offset, data); err != nil {
+			log.LogWarnf("MigrateToErasureCoded: write extent failed, ino(%v) offset(%v) dp(%v) err(%v)",
+				inode, offset, dp, err)
+			exclude[dp.PartitionID] = struct{}{}
+			continue
+		}
+		keys = append(keys, key)
+		offset += chunk
+	}
+	log.LogInfof("MigrateToErasureCoded: ino(%v) size(%v) keys(%v)", inode, size, keys)
+	return
+}
+
+// isErasureCodedExtent checks whether the extent is in an erasure coded data partition, whose shards are never
+// overwritten in place since the parity of the stripes would be inconsistent with the data.
+func (s *Streamer) isErasureCodedExtent(ek *proto.ExtentKey) bool {
+	dp, err := s.client.dataWrapper.GetDataPartition(ek.PartitionId)
+	return err == nil && dp.IsErasureCoded()
+}
+
+func (client *ExtentClient) isErasureCoded(extents []proto.ExtentKey) bool {
+	for _, ek := range extents {
+		dp, err := client.dataWrapper.GetDataPartition(ek.PartitionId)
+		if err != nil || !dp.IsErasureCoded() {
+			return false
+		}
+	}
+	return true
+}
+
+// writeErasureCodedExtent encodes the data into the shards of a new extent created on all the hosts of the
+// erasure coded data partition, and writes each shard to its own host.
+func (client *ExtentClient) writeErasureCodedExtent(dp *wrapper.DataPartition, inode uint64, fileOffset int, data []byte) (key proto.ExtentKey, err error) {
+	if len(dp.Hosts) != int(dp.ECDataNum)+int(dp.ECParityNum) {
+		err = fmt.Errorf("writeErasureCodedExtent: hosts(%v) mismatch shards(%v+%v)", dp.Hosts, dp.ECDataNum, dp.ECParityNum)
+		return
+	}
+	var encoder *erasure.Encoder
+	if encoder, err = erasure.NewEncoder(int(dp.ECDataNum), int(dp.ECParityNum)); err != nil {
+		return
+	}
+	var extentID uint64
+	if extentID, err = createMigrationExtent(dp, inode); err != nil {
+		return
+	}
+	// the shards written before a failure are freed with the extent
+	defer func() {
+		if err == nil {
+			return
+		}
+		if e := deleteMigrationExtent(dp, extentID); e != nil {
+			log.LogWarnf("writeErasureCodedExtent: free extent failed, ino(%v) dp(%v) extent(%v) err(%v)",
+				inode, dp.PartitionID, extentID, e)
+		}
+	}()
+
+	if client.cipher != nil {
+		client.cipher.XORKeyStream(dp.PartitionID, extentID, 0, data)
+	}
+
+	shardSize := proto.ECShardSize(uint64(len(data)), dp.ECDataNum)
+	shards := make([][]byte, len(dp.Hosts))
+	for i := range shards {
+		shards[i] = make([]byte, shardSize)
+	}
+	for offset := 0; offset < len(data); offset += proto.ECStripeUnitSize {
+		shard, shardOffset := proto.ECShardLocation(uint64(offset), dp.ECDataNum)
+		copy(shards[shard][shardOffset:], data[offset:util.Min(offset+proto.ECStripeUnitSize, len(data))])
+	}
+	if err = encoder.Encode(shards); err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(dp.Hosts))
+	for i, host := range dp.Hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			errs[i] = writeErasureCodedShard(dp, host, extentID, inode, shards[i])
+		}(i, host)
+	}
+	wg.Wait()
+	for i, e := range errs {
+		if e != nil {
+			err = errors.Trace(e, "writeErasureCodedExtent: write shard(%v) to host(%v) failed", i, dp.Hosts[i])
+			return
+		}
+	}
+
+	key = proto.ExtentKey{
+		FileOffset:  uint64(fileOffset),
+		PartitionId: dp.PartitionID,
+		ExtentId:    extentID,
+		Size:        uint32(len(data)),
+	}
+	return
+}
+
+// createMigrationExtent creates a new extent of the file being migrated on all the hosts of the data partition.
+func createMigrationExtent(dp *wrapper.DataPartition, inode uint64) (extentID uint64, err error) {
+	conn, err := StreamConnPool.GetConnect(dp.Hosts[0])
+	if err != nil {
+		return
+	}
+	defer func() {
+		StreamConnPool.PutConnect(conn, err != nil)
+	}()
+
+	p := NewCreateExtentPacket(dp, inode)
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConn(conn, proto.ReadDeadlineTime*2); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("createMigrationExtent: ResultCode NOK, packet(%v) host(%v) ResultCode(%v)",
+			p, dp.Hosts[0], p.GetResultMsg())
+		return
+	}
+	if p.ExtentID == 0 {
+		err = fmt.Errorf("createMigrationExtent: illegal extent id from host(%v)", dp.Hosts[0])
+		return
+	}
+	return p.ExtentID, nil
+}
+
+// writeErasureCodedShard writes the shard to the extent on the host only, without forwarding to the followers.
+func writeErasureCodedShard(dp *wrapper.DataPartition, host string, extentID, inode uint64, shard []byte) (err error) {
+	conn, err := StreamConnPool.GetConnect(host)
+	if err != nil {
+		return
+	}
+	defer func() {
+		StreamConnPool.PutConnect(conn, err != nil)
+	}()
+
+	for offset := 0; offset < len(shard); offset += util.BlockSize {
+		p := new(Packet)
+		p.PartitionID = dp.PartitionID
+		p.Magic = proto.ProtoMagic
+		p.ExtentType = proto.NormalExtentType
+		p.ExtentID = extentID
+		p.ExtentOffset = int64(offset)
+		p.Opcode = proto.OpWrite
+		p.ReqID = proto.GenerateRequestID()
+		p.RemainingFollowers = 0
+		p.inode = inode
+		p.Data = shard[offset:util.Min(offset+util.BlockSize, len(shard))]
+		p.Size = uint32(len(p.Data))
+		if err = p.writeToConn(conn); err != nil {
+			return
+		}
+		reply := NewReply(p.ReqID, dp.PartitionID, extentID)
+		if err = reply.readFromConn(conn, proto.ReadDeadlineTime); err != nil {
+			return
+		}
+		if reply.ResultCode != proto.OpOk || !p.isValidWriteReply(reply) {
+			return fmt.Errorf("writeErasureCodedShard: packet(%v) reply(%v)", p, reply)
+		}
+	}
+	return
+}
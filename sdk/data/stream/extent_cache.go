@@ -52,6 +52,7 @@ type ExtentCache struct {
 	inode uint64
 	gen   uint64 // generation number
 	size  uint64 // size of the cache
+	cow   bool   // the extents are shared, and the overwritten data is written to new extents
 	root  *btree.BTree
 }
 
@@ -65,20 +66,23 @@ func NewExtentCache(inode uint64) *ExtentCache {
 
 // Refresh refreshes the extent cache.
 func (cache *ExtentCache) Refresh(inode uint64, getExtents GetExtentsFunc) error {
-	gen, size, extents, err := getExtents(inode)
+	gen, size, extents, cow, err := getExtents(inode)
 	if err != nil {
 		return err
 	}
 	//log.LogDebugf("Local ExtentCache before update: gen(%v) size(%v) extents(%v)", cache.gen, cache.size, cache.List())
-	cache.update(gen, size, extents)
+	cache.update(gen, size, extents, cow)
 	//log.LogDebugf("Local ExtentCache after update: gen(%v) size(%v) extents(%v)", cache.gen, cache.size, cache.List())
 	return nil
 }
 
-func (cache *ExtentCache) update(gen, size uint64, eks []proto.ExtentKey) {
+func (cache *ExtentCache) update(gen, size uint64, eks []proto.ExtentKey, cow bool) {
 	cache.Lock()
 	defer cache.Unlock()
 
+	// the extents may be shared by copying the inode without changing the generation
+	cache.cow = cow
+
 	log.LogDebugf("ExtentCache update: ino(%v) cache.gen(%v) cache.size(%v) gen(%v) size(%v)", cache.inode, cache.gen, cache.size, gen, size)
 
 	//	cache.root.Ascend(func(bi btree.Item) bool {
@@ -115,11 +119,31 @@ func (cache *ExtentCache) Append(ek *proto.ExtentKey, sync bool) {
 	cache.Lock()
 	defer cache.Unlock()
 
-	// When doing the append, we do not care about the data after the file offset.
-	// Those data will be overwritten by the current extent anyway.
+	// The key overlapping the start of the appended key is cut at the start,
+	// and the rest of it after the end of the appended key is kept as a new key.
+	var head, tail *proto.ExtentKey
+	cache.root.DescendLessOrEqual(lower, func(i btree.Item) bool {
+		found := i.(*proto.ExtentKey)
+		if found.FileOffset < ek.FileOffset && found.FileOffset+uint64(found.Size) > ek.FileOffset {
+			head = found
+		}
+		return false
+	})
+	if head != nil {
+		if headEnd := head.FileOffset + uint64(head.Size); headEnd > ekEnd {
+			tail = splitExtentKey(head, ekEnd, headEnd)
+		}
+		cache.root.Delete(head)
+		cache.root.ReplaceOrInsert(splitExtentKey(head, head.FileOffset, ek.FileOffset))
+	}
+
+	// The keys in the range of the appended key are discarded, except the part of the last one after the range.
 	cache.root.AscendRange(lower, upper, func(i btree.Item) bool {
 		found := i.(*proto.ExtentKey)
 		discard = append(discard, found)
+		if foundEnd := found.FileOffset + uint64(found.Size); foundEnd > ekEnd {
+			tail = splitExtentKey(found, ekEnd, foundEnd)
+		}
 		return true
 	})
 
@@ -129,6 +153,9 @@ func (cache *ExtentCache) Append(ek *proto.ExtentKey, sync bool) {
 	}
 
 	cache.root.ReplaceOrInsert(ek)
+	if tail != nil {
+		cache.root.ReplaceOrInsert(tail)
+	}
 	if sync {
 		cache.gen++
 	}
@@ -139,6 +166,22 @@ func (cache *ExtentCache) Append(ek *proto.ExtentKey, sync bool) {
 	//log.LogDebugf("ExtentCache Append: ino(%v) ek(%v) discard(%v)", cache.inode, ek, discard)
 }
 
+// Return a new key referring the part of the extent key in the file range [start, end).
+func splitExtentKey(ek *proto.ExtentKey, start, end uint64) *proto.ExtentKey {
+	key := *ek
+	key.FileOffset = start
+	key.ExtentOffset = ek.ExtentOffset + (start - ek.FileOffset)
+	key.Size = uint32(end - start)
+	return &key
+}
+
+// CopyOnWrite returns whether the extents are shared and must not be overwritten in place.
+func (cache *ExtentCache) CopyOnWrite() bool {
+	cache.RLock()
+	defer cache.RUnlock()
+	return cache.cow
+}
+
 // Max returns the max extent key in the cache.
 func (cache *ExtentCache) Max() *proto.ExtentKey {
 	cache.RLock()
@@ -29,9 +29,12 @@ import (
 )
 
 type AppendExtentKeyFunc func(inode uint64, key proto.ExtentKey) error
-type GetExtentsFunc func(inode uint64) (uint64, uint64, []proto.ExtentKey, error)
+type GetExtentsFunc func(inode uint64) (uint64, uint64, []proto.ExtentKey, bool, error)
 type TruncateFunc func(inode, size uint64) error
 type EvictIcacheFunc func(inode uint64)
+type PrepareMigrationFunc func(inode uint64) error
+type ReplaceExtentsFunc func(inode uint64, oldEks, newEks []proto.ExtentKey) error
+type GetEncryptionKeyFunc func() ([]byte, error)
 
 const (
 	MaxMountRetryLimit = 5
@@ -77,16 +80,19 @@ func init() {
 }
 
 type ExtentConfig struct {
-	Volume            string
-	Masters           []string
-	FollowerRead      bool
-	NearRead          bool
-	ReadRate          int64
-	WriteRate         int64
-	OnAppendExtentKey AppendExtentKeyFunc
-	OnGetExtents      GetExtentsFunc
-	OnTruncate        TruncateFunc
-	OnEvictIcache     EvictIcacheFunc
+	Volume             string
+	Masters            []string
+	FollowerRead       bool
+	NearRead           bool
+	ReadRate           int64
+	WriteRate          int64
+	OnAppendExtentKey  AppendExtentKeyFunc
+	OnGetExtents       GetExtentsFunc
+	OnTruncate         TruncateFunc
+	OnEvictIcache      EvictIcacheFunc
+	OnPrepareMigration PrepareMigrationFunc
+	OnReplaceExtents   ReplaceExtentsFunc
+	OnGetEncryptionKey GetEncryptionKeyFunc // required by an encrypted volume
 }
 
 // ExtentClient defines the struct of the extent client.
@@ -97,11 +103,14 @@ type ExtentClient struct {
 	readLimiter  *rate.Limiter
 	writeLimiter *rate.Limiter
 
-	dataWrapper     *wrapper.Wrapper
-	appendExtentKey AppendExtentKeyFunc
-	getExtents      GetExtentsFunc
-	truncate        TruncateFunc
-	evictIcache     EvictIcacheFunc //May be null, must check before using
+	dataWrapper      *wrapper.Wrapper
+	appendExtentKey  AppendExtentKeyFunc
+	getExtents       GetExtentsFunc
+	truncate         TruncateFunc
+	evictIcache      EvictIcacheFunc //May be null, must check before using
+	prepareMigration PrepareMigrationFunc
+	replaceExtents   ReplaceExtentsFunc
+	cipher           *fileCipher //Null if the volume is not encrypted
 }
 
 // NewExtentClient returns a new extent client.
@@ -126,9 +135,24 @@ retry:
 	client.getExtents = config.OnGetExtents
 	client.truncate = config.OnTruncate
 	client.evictIcache = config.OnEvictIcache
+	client.prepareMigration = config.OnPrepareMigration
+	client.replaceExtents = config.OnReplaceExtents
 	client.dataWrapper.InitFollowerRead(config.FollowerRead)
 	client.dataWrapper.SetNearRead(config.NearRead)
 
+	if client.dataWrapper.Encrypted() {
+		if config.OnGetEncryptionKey == nil {
+			return nil, errors.New("Encryption key is required by the encrypted volume!")
+		}
+		var key []byte
+		if key, err = config.OnGetEncryptionKey(); err != nil {
+			return nil, errors.Trace(err, "Get encryption key failed!")
+		}
+		if client.cipher, err = newFileCipher(key); err != nil {
+			return nil, errors.Trace(err, "Init file cipher failed!")
+		}
+	}
+
 	var readLimit, writeLimit rate.Limit
 	if config.ReadRate <= 0 {
 		readLimit = defaultReadLimitRate
@@ -285,6 +309,11 @@ func (client *ExtentClient) Read(inode uint64, data []byte, offset int, size int
 	return
 }
 
+// Encrypted returns whether the data of the volume is encrypted at rest.
+func (client *ExtentClient) Encrypted() bool {
+	return client.cipher != nil
+}
+
 // GetStreamer returns the streamer.
 func (client *ExtentClient) GetStreamer(inode uint64) *Streamer {
 	client.streamerLock.Lock()
@@ -236,6 +236,12 @@ func (eh *ExtentHandler) sender() {
 			packet.RemainingFollowers = uint8(len(eh.dp.Hosts) - 1)
 			packet.StartT = time.Now().UnixNano()
 
+			// The data of an encrypted volume is encrypted by its location in the extent, which is known only now.
+			if cipher := eh.stream.client.cipher; cipher != nil && !packet.encrypted {
+				cipher.XORKeyStream(packet.PartitionID, packet.ExtentID, extOffset, packet.Data[:packet.Size])
+				packet.encrypted = true
+			}
+
 			//log.LogDebugf("ExtentHandler sender: extent allocated, eh(%v) dp(%v) extID(%v) packet(%v)", eh, eh.dp, eh.extID, packet.GetUniqueLogId())
 
 			if err = packet.writeToConn(eh.conn); err != nil {
@@ -451,6 +457,12 @@ func (eh *ExtentHandler) recoverPacket(packet *Packet) error {
 		return errors.New(fmt.Sprintf("recoverPacket failed: reach max error limit, eh(%v) packet(%v)", eh, packet))
 	}
 
+	// The packet is written to another extent, and encrypted again by its location in that extent.
+	if packet.encrypted {
+		eh.stream.client.cipher.XORKeyStream(packet.PartitionID, packet.ExtentID, int(packet.ExtentOffset), packet.Data[:packet.Size])
+		packet.encrypted = false
+	}
+
 	handler := eh.recoverHandler
 	if handler == nil {
 		// Always use normal extent store mode for recovery.
@@ -487,7 +499,7 @@ func (eh *ExtentHandler) allocateExtent() (err error) {
 	exclude := make(map[string]struct{})
 
 	for i := 0; i < MaxSelectDataPartitionForWrite; i++ {
-		if dp, err = eh.stream.client.dataWrapper.GetDataPartitionForWrite(exclude); err != nil {
+		if dp, err = eh.stream.getDataPartitionForWrite(exclude); err != nil {
 			log.LogWarnf("allocateExtent: failed to get write data partition, eh(%v) exclude(%v)", eh, exclude)
 			continue
 		}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/data/wrapper"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// migrateWriteFunc writes the data of the file in [0, size) to new extents. The keys of the extents written before
+// an error are returned with the error, so that they can be freed.
+type migrateWriteFunc func(inode uint64, size int) ([]proto.ExtentKey, error)
+
+// migrate rewrites the data of the file into new extents, and replaces the extents of the file by the new extents
+// in a single meta node operation. The inode is marked as being migrated before the data is read, then any write to
+// the file during the migration changes its extents, and the meta node refuses to replace the changed extents.
+// The skip function tells whether the file needs no migration. The new extents are freed if they are not used.
+func (client *ExtentClient) migrate(inode uint64, skip func(extents []proto.ExtentKey) bool, write migrateWriteFunc) (err error) {
+	if client.prepareMigration == nil || client.replaceExtents == nil {
+		return fmt.Errorf("migrate: migration is not supported by the client, ino(%v)", inode)
+	}
+	_, size, extents, _, err := client.getExtents(inode)
+	if err != nil || size == 0 || skip(extents) {
+		return
+	}
+	if err = client.prepareMigration(inode); err != nil {
+		return
+	}
+	if err = client.OpenStream(inode); err != nil {
+		return
+	}
+	defer client.CloseStream(inode)
+
+	// the extents to replace are those after the inode is marked, the data is read after them
+	if _, size, extents, _, err = client.getExtents(inode); err != nil || size == 0 {
+		return
+	}
+	if err = client.RefreshExtentsCache(inode); err != nil {
+		return
+	}
+	keys, err := write(inode, int(size))
+	if err != nil {
+		client.freeMigrationExtents(inode, keys)
+		return
+	}
+	if err = client.replaceExtents(inode, extents, keys); err != nil {
+		// the extents may have been replaced if the result is lost, so they are freed only if refused
+		if err == syscall.EINVAL {
+			client.freeMigrationExtents(inode, keys)
+			err = fmt.Errorf("migrate: file modified during migration, ino(%v)", inode)
+		}
+		return
+	}
+	if client.evictIcache != nil {
+		client.evictIcache(inode)
+	}
+	return client.RefreshExtentsCache(inode)
+}
+
+func (client *ExtentClient) freeMigrationExtents(inode uint64, keys []proto.ExtentKey) {
+	for _, key := range keys {
+		dp, err := client.dataWrapper.GetDataPartition(key.PartitionId)
+		if err == nil {
+			err = deleteMigrationExtent(dp, key.ExtentId)
+		}
+		if err != nil {
+			log.LogWarnf("freeMigrationExtents: ino(%v) key(%v) err(%v)", inode, key, err)
+		}
+	}
+}
+
+// deleteMigrationExtent deletes the extent written by the migration from all the hosts of the data partition.
+func deleteMigrationExtent(dp *wrapper.DataPartition, extentID uint64) (err error) {
+	conn, err := StreamConnPool.GetConnect(dp.Hosts[0])
+	if err != nil {
+		return
+	}
+	defer func() {
+		StreamConnPool.PutConnect(conn, err != nil)
+	}()
+
+	p := NewDeleteExtentPacket(dp, extentID)
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConn(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("deleteMigrationExtent: ResultCode NOK, packet(%v) host(%v) ResultCode(%v)",
+			p, dp.Hosts[0], p.GetResultMsg())
+	}
+	return
+}
@@ -29,8 +29,9 @@ import (
 // Packet defines a wrapper of the packet in proto.
 type Packet struct {
 	proto.Packet
-	inode    uint64
-	errCount int
+	inode     uint64
+	errCount  int
+	encrypted bool // the data is encrypted by its location in the extent
 }
 
 // String returns the string format of the packet.
@@ -112,6 +113,21 @@ func NewCreateExtentPacket(dp *wrapper.DataPartition, inode uint64) *Packet {
 	return p
 }
 
+// NewDeleteExtentPacket returns a new packet to delete the normal extent from all the hosts of the data partition.
+func NewDeleteExtentPacket(dp *wrapper.DataPartition, extentID uint64) *Packet {
+	p := new(Packet)
+	p.PartitionID = dp.PartitionID
+	p.Magic = proto.ProtoMagic
+	p.ExtentType = proto.NormalExtentType
+	p.ExtentID = extentID
+	p.Arg = ([]byte)(dp.GetAllAddrs())
+	p.ArgLen = uint32(len(p.Arg))
+	p.RemainingFollowers = uint8(len(dp.Hosts) - 1)
+	p.ReqID = proto.GenerateRequestID()
+	p.Opcode = proto.OpMarkDelete
+	return p
+}
+
 // NewReply returns a new reply packet. TODO rename to NewReplyPacket?
 func NewReply(reqID int64, partitionID uint64, extentID uint64) *Packet {
 	p := new(Packet)
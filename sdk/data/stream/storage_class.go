@@ -0,0 +1,163 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/sdk/data/wrapper"
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// ColdStorageEnabled returns whether the files of the volume can be stored in the cold data partitions.
+func (client *ExtentClient) ColdStorageEnabled() bool {
+	return client.dataWrapper.ColdStorageEnabled()
+}
+
+// SetStorageClass makes the opened stream of the inode allocate the new extents from the data partitions of
+// the storage class, the data written before is not moved.
+func (client *ExtentClient) SetStorageClass(inode uint64, storageClass uint8) error {
+	s := client.GetStreamer(inode)
+	if s == nil {
+		return fmt.Errorf("SetStorageClass: stream is not opened, ino(%v)", inode)
+	}
+	s.storageClass = storageClass
+	return nil
+}
+
+func (s *Streamer) getDataPartitionForWrite(exclude map[string]struct{}) (*wrapper.DataPartition, error) {
+	if s.storageClass == proto.StorageClassCold {
+		return s.client.dataWrapper.GetColdPartitionForWrite(exclude)
+	}
+	return s.client.dataWrapper.GetDataPartitionForWrite(exclude)
+}
+
+// MigrateToCold rewrites the file into the cold data partitions. The extents of the file are replaced only if
+// the file is not modified during the migration, and the replaced extents are freed by the meta node.
+func (client *ExtentClient) MigrateToCold(inode uint64) error {
+	return client.migrate(inode, client.isCold, client.writeColdFile)
+}
+
+func (client *ExtentClient) writeColdFile(inode uint64, size int) (keys []proto.ExtentKey, err error) {
+	keys = make([]proto.ExtentKey, 0)
+	exclude := make(map[string]struct{})
+	for offset := 0; offset < size; {
+		var dp *wrapper.DataPartition
+		if dp, err = client.dataWrapper.GetColdPartitionForWrite(exclude); err != nil {
+			return
+		}
+		chunk := util.Min(util.ExtentSize, size-offset)
+		var key proto.ExtentKey
+		if key, err = client.writeColdExtent(dp, inode, offset, chunk); err != nil {
+			log.LogWarnf("MigrateToCold: write extent failed, ino(%v) offset(%v) dp(%v) err(%v)",
+				inode, offset, dp, err)
+			exclude[dp.Hosts[0]] = struct{}{}
+			continue
+		}
+		keys = append(keys, key)
+		offset += chunk
+	}
+	log.LogInfof("MigrateToCold: ino(%v) size(%v) keys(%v)", inode, size, keys)
+	return
+}
+
+func (client *ExtentClient) isCold(extents []proto.ExtentKey) bool {
+	for _, ek := range extents {
+		dp, err := client.dataWrapper.GetDataPartition(ek.PartitionId)
+		if err != nil || !dp.IsCold() {
+			return false
+		}
+	}
+	return true
+}
+
+// writeColdExtent copies the data of the file in the range to a new extent of the cold data partition block by block,
+// the blocks are written to the leader and replicated to the followers like the normal writes.
+func (client *ExtentClient) writeColdExtent(dp *wrapper.DataPartition, inode uint64, fileOffset, size int) (key proto.ExtentKey, err error) {
+	var extentID uint64
+	if extentID, err = createMigrationExtent(dp, inode); err != nil {
+		return
+	}
+	// the blocks written before a failure are freed with the extent
+	defer func() {
+		if err == nil {
+			return
+		}
+		if e := deleteMigrationExtent(dp, extentID); e != nil {
+			log.LogWarnf("writeColdExtent: free extent failed, ino(%v) dp(%v) extent(%v) err(%v)",
+				inode, dp.PartitionID, extentID, e)
+		}
+	}()
+	conn, err := StreamConnPool.GetConnect(dp.Hosts[0])
+	if err != nil {
+		return
+	}
+	defer func() {
+		StreamConnPool.PutConnect(conn, err != nil)
+	}()
+
+	data := make([]byte, util.BlockSize)
+	for offset := 0; offset < size; offset += util.BlockSize {
+		block := util.Min(util.BlockSize, size-offset)
+		var read int
+		if read, err = client.Read(inode, data, fileOffset+offset, block); err != nil && err != io.EOF {
+			return
+		}
+		if read != block {
+			err = fmt.Errorf("writeColdExtent: file changed, ino(%v) offset(%v) read(%v) expect(%v)",
+				inode, fileOffset+offset, read, block)
+			return
+		}
+		if client.cipher != nil {
+			client.cipher.XORKeyStream(dp.PartitionID, extentID, offset, data[:block])
+		}
+		p := new(Packet)
+		p.PartitionID = dp.PartitionID
+		p.Magic = proto.ProtoMagic
+		p.ExtentType = proto.NormalExtentType
+		p.ExtentID = extentID
+		p.ExtentOffset = int64(offset)
+		p.Opcode = proto.OpWrite
+		p.ReqID = proto.GenerateRequestID()
+		p.Arg = ([]byte)(dp.GetAllAddrs())
+		p.ArgLen = uint32(len(p.Arg))
+		p.RemainingFollowers = uint8(len(dp.Hosts) - 1)
+		p.inode = inode
+		p.Data = data[:block]
+		p.Size = uint32(block)
+		if err = p.writeToConn(conn); err != nil {
+			return
+		}
+		reply := NewReply(p.ReqID, dp.PartitionID, extentID)
+		if err = reply.readFromConn(conn, proto.ReadDeadlineTime); err != nil {
+			return
+		}
+		if reply.ResultCode != proto.OpOk || !p.isValidWriteReply(reply) {
+			err = fmt.Errorf("writeColdExtent: packet(%v) reply(%v)", p, reply)
+			return
+		}
+	}
+
+	key = proto.ExtentKey{
+		FileOffset:  uint64(fileOffset),
+		PartitionId: dp.PartitionID,
+		ExtentId:    extentID,
+		Size:        uint32(size),
+	}
+	return
+}
@@ -48,6 +48,8 @@ type Streamer struct {
 	done    chan struct{}    // stream writer is being closed
 
 	writeLock sync.Mutex
+
+	storageClass uint8 // storage class of the data partitions allocating the new extents
 }
 
 // NewStreamer returns a new streamer.
@@ -147,6 +149,11 @@ func (s *Streamer) read(data []byte, offset int, size int) (total int, err error
 			}
 			readBytes, err = reader.Read(req)
 			log.LogDebugf("Stream read: ino(%v) req(%v) readBytes(%v) err(%v)", s.inode, req, readBytes, err)
+			if s.client.cipher != nil && readBytes > 0 {
+				ek := req.ExtentKey
+				extentOffset := int(ek.ExtentOffset) + req.FileOffset - int(ek.FileOffset)
+				s.client.cipher.XORKeyStream(ek.PartitionId, ek.ExtentId, extentOffset, req.Data[:readBytes])
+			}
 			total += readBytes
 			if err != nil || readBytes < req.Size {
 				if total == 0 {
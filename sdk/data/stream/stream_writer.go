@@ -279,7 +279,8 @@ func (s *Streamer) write(data []byte, offset, size, flags int) (total int, err e
 	requests := s.extents.PrepareWriteRequests(offset, size, data)
 	log.LogDebugf("Streamer write: ino(%v) prepared requests(%v)", s.inode, requests)
 
-	// Must flush before doing overwrite
+	// Must flush before doing overwrite, and the extents are refreshed since they may have been
+	// shared by copying the inode or taking a volume snapshot since the last refresh.
 	for _, req := range requests {
 		if req.ExtentKey == nil {
 			continue
@@ -288,14 +289,20 @@ func (s *Streamer) write(data []byte, offset, size, flags int) (total int, err e
 		if err != nil {
 			return
 		}
+		if err = s.GetExtents(); err != nil {
+			return
+		}
 		requests = s.extents.PrepareWriteRequests(offset, size, data)
 		log.LogDebugf("Streamer write: ino(%v) prepared requests after flush(%v)", s.inode, requests)
 		break
 	}
 
+	// The shared extents, the erasure coded extents and the extents of an encrypted volume
+	// are never overwritten in place, the data is written to new extents instead.
+	cow := s.extents.CopyOnWrite() || s.client.cipher != nil
 	for _, req := range requests {
 		var writeSize int
-		if req.ExtentKey != nil {
+		if req.ExtentKey != nil && !cow && !s.isErasureCodedExtent(req.ExtentKey) {
 			writeSize, err = s.doOverwrite(req, direct)
 		} else {
 			writeSize, err = s.doWrite(req.Data, req.FileOffset, req.Size, direct)
@@ -582,6 +589,11 @@ func (s *Streamer) truncate(size int) error {
 	return s.GetExtents()
 }
 
+// The tiny extents are not used by an encrypted volume, because the data is encrypted by its location in
+// the extent, and the location in a tiny extent is decided by the data node after the data is sent.
 func (s *Streamer) tinySizeLimit() int {
+	if s.client.cipher != nil {
+		return 0
+	}
 	return util.DefaultTinySizeLimit
 }
@@ -119,6 +119,16 @@ func NewDataPartitionMetrics() *DataPartitionMetrics {
 	return metrics
 }
 
+// IsCold returns true if the data partition is placed in the cold zone of the volume.
+func (dp *DataPartition) IsCold() bool {
+	return dp.StorageClass == proto.StorageClassCold
+}
+
+// IsErasureCoded returns true if the extents of the data partition are stored as erasure coded shards.
+func (dp *DataPartition) IsErasureCoded() bool {
+	return dp.ECDataNum > 0
+}
+
 // String returns the string format of the data partition.
 func (dp *DataPartition) String() string {
 	return fmt.Sprintf("PartitionID(%v) Status(%v) ReplicaNum(%v) PartitionType(%v) Hosts(%v) NearHosts(%v)",
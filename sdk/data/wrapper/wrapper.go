@@ -16,6 +16,7 @@ package wrapper
 
 import (
 	"fmt"
+	"math/rand"
 	"net"
 	"strings"
 	"sync"
@@ -50,6 +51,11 @@ type Wrapper struct {
 	dpSelectorChanged     bool
 	dpSelectorName        string
 	dpSelectorParm        string
+	ecColdDays            uint32
+	ecPartitions          []*DataPartition
+	coldZoneName          string
+	coldPartitions        []*DataPartition
+	encrypted             bool
 	mc                    *masterSDK.MasterClient
 	stopOnce              sync.Once
 	stopC                 chan struct{}
@@ -127,6 +133,9 @@ func (w *Wrapper) getSimpleVolView() (err error) {
 	w.followerRead = view.FollowerRead
 	w.dpSelectorName = view.DpSelectorName
 	w.dpSelectorParm = view.DpSelectorParm
+	w.ecColdDays = view.ECColdDays
+	w.coldZoneName = view.ColdZoneName
+	w.encrypted = view.Encrypted
 
 	log.LogInfof("getSimpleVolView: get volume simple info: ID(%v) name(%v) owner(%v) status(%v) capacity(%v) "+
 		"metaReplicas(%v) dataReplicas(%v) mpCnt(%v) dpCnt(%v) followerRead(%v) createTime(%v) dpSelectorName(%v) "+
@@ -174,6 +183,18 @@ func (w *Wrapper) updateSimpleVolView() (err error) {
 		w.Unlock()
 	}
 
+	if w.ecColdDays != view.ECColdDays {
+		log.LogInfof("updateSimpleVolView: update ecColdDays from old(%v) to new(%v)",
+			w.ecColdDays, view.ECColdDays)
+		w.ecColdDays = view.ECColdDays
+	}
+
+	if w.coldZoneName != view.ColdZoneName {
+		log.LogInfof("updateSimpleVolView: update coldZoneName from old(%v) to new(%v)",
+			w.coldZoneName, view.ColdZoneName)
+		w.coldZoneName = view.ColdZoneName
+	}
+
 	return nil
 }
 
@@ -194,6 +215,8 @@ func (w *Wrapper) updateDataPartition(isInit bool) (err error) {
 	}
 
 	rwPartitionGroups := make([]*DataPartition, 0)
+	ecPartitions := make([]*DataPartition, 0)
+	coldPartitions := make([]*DataPartition, 0)
 	for _, partition := range dpv.DataPartitions {
 		dp := convert(partition)
 		if w.followerRead && w.nearRead {
@@ -201,12 +224,31 @@ func (w *Wrapper) updateDataPartition(isInit bool) (err error) {
 		}
 		log.LogInfof("updateDataPartition: dp(%v)", dp)
 		w.replaceOrInsertPartition(dp)
+		if dp.IsErasureCoded() {
+			// erasure coded partitions are only written by the cold data migration
+			if dp.Status == proto.ReadWrite {
+				ecPartitions = append(ecPartitions, dp)
+			}
+			continue
+		}
+		if dp.IsCold() {
+			// cold partitions are only written by the files of the cold storage class
+			if dp.Status == proto.ReadWrite {
+				coldPartitions = append(coldPartitions, dp)
+			}
+			continue
+		}
 		if dp.Status == proto.ReadWrite {
 			dp.MetricsRefresh()
 			rwPartitionGroups = append(rwPartitionGroups, dp)
 		}
 	}
 
+	w.Lock()
+	w.ecPartitions = ecPartitions
+	w.coldPartitions = coldPartitions
+	w.Unlock()
+
 	// isInit used to identify whether this call is caused by mount action
 	if isInit || (len(rwPartitionGroups) >= MinWriteAbleDataPartitionCnt) {
 		w.refreshDpSelector(rwPartitionGroups)
@@ -254,6 +296,54 @@ func (w *Wrapper) GetDataPartition(partitionID uint64) (*DataPartition, error) {
 	return dp, nil
 }
 
+// GetErasureCodedPartition returns a random writable erasure coded data partition which is not excluded.
+func (w *Wrapper) GetErasureCodedPartition(exclude map[uint64]struct{}) (*DataPartition, error) {
+	w.RLock()
+	defer w.RUnlock()
+	candidates := make([]*DataPartition, 0, len(w.ecPartitions))
+	for _, dp := range w.ecPartitions {
+		if _, ok := exclude[dp.PartitionID]; !ok {
+			candidates = append(candidates, dp)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no writable erasure coded data partition")
+	}
+	return candidates[rand.Intn(len(candidates))], nil
+}
+
+// GetColdPartitionForWrite returns a random writable cold data partition whose hosts are not excluded.
+func (w *Wrapper) GetColdPartitionForWrite(exclude map[string]struct{}) (*DataPartition, error) {
+	w.RLock()
+	defer w.RUnlock()
+	candidates := make([]*DataPartition, 0, len(w.coldPartitions))
+	for _, dp := range w.coldPartitions {
+		if !isExcluded(dp, exclude) {
+			candidates = append(candidates, dp)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no writable cold data partition")
+	}
+	return candidates[rand.Intn(len(candidates))], nil
+}
+
+// ColdStorageEnabled returns whether the files of the volume can be stored in the cold data partitions.
+func (w *Wrapper) ColdStorageEnabled() bool {
+	return w.coldZoneName != ""
+}
+
+// ErasureCodeColdDays returns the days after which the files of the volume are migrated to erasure coded data
+// partitions, zero means the migration is disabled.
+func (w *Wrapper) ErasureCodeColdDays() uint32 {
+	return w.ecColdDays
+}
+
+// Encrypted returns whether the data of the volume is encrypted at rest, which is decided on creation.
+func (w *Wrapper) Encrypted() bool {
+	return w.encrypted
+}
+
 // WarningMsg returns the warning message that contains the cluster name.
 func (w *Wrapper) WarningMsg() string {
 	return fmt.Sprintf("%s_client_warning", w.clusterName)
@@ -223,6 +223,85 @@ func (api *AdminAPI) UpdateVolume(volName string, capacity uint64, replicas int,
 	return
 }
 
+func (api *AdminAPI) SetVolumeTrashDays(volName, authKey string, trashDays uint32) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminUpdateVol)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("trashDays", strconv.FormatUint(uint64(trashDays), 10))
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) SetVolumeErasureCode(volName, authKey string, dataNum, parityNum uint8, coldDays uint32) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminUpdateVol)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("ecDataNum", strconv.Itoa(int(dataNum)))
+	request.addParam("ecParityNum", strconv.Itoa(int(parityNum)))
+	request.addParam("ecColdDays", strconv.FormatUint(uint64(coldDays), 10))
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) SetVolumeCompression(volName, authKey, compression string) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminUpdateVol)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("compression", compression)
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) SetVolumeS3QoS(volName, authKey string, requestRate, bandwidth uint64) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminUpdateVol)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("s3RequestRate", strconv.FormatUint(requestRate, 10))
+	request.addParam("s3Bandwidth", strconv.FormatUint(bandwidth, 10))
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+// SetVolumeColdZone sets the zone of the cold data partitions of the volume, an empty zone disables the cold
+// storage class.
+func (api *AdminAPI) SetVolumeColdZone(volName, authKey, zoneName string) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminUpdateVol)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("coldZoneName", zoneName)
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+// GetVolumeEncryptionKey fetches the data key of the encrypted volume with the token of an authnode ticket,
+// the reply is decoded by the session key of the ticket.
+func (api *AdminAPI) GetVolumeEncryptionKey(volName string, token string, decoder Decoder) (key []byte, err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminGetVolEncryptionKey)
+	request.addParam("name", volName)
+	request.addParam(proto.ClientMessage, token)
+	var buf []byte
+	if buf, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	if buf, err = decoder.Decode(buf); err != nil {
+		return
+	}
+	if err = json.Unmarshal(buf, &key); err != nil {
+		return
+	}
+	return
+}
+
 func (api *AdminAPI) VolShrink(volName string, capacity uint64, authKey string) (err error) {
 	var request = newAPIRequest(http.MethodGet, proto.AdminVolShrink)
 	request.addParam("name", volName)
@@ -246,7 +325,7 @@ func (api *AdminAPI) VolExpand(volName string, capacity uint64, authKey string)
 }
 
 func (api *AdminAPI) CreateVolume(volName, owner string, mpCount int,
-	dpSize uint64, capacity uint64, replicas int, followerRead bool, zoneName string) (err error) {
+	dpSize uint64, capacity uint64, replicas int, followerRead bool, zoneName string, encryption bool) (err error) {
 	var request = newAPIRequest(http.MethodGet, proto.AdminCreateVol)
 	request.addParam("name", volName)
 	request.addParam("owner", owner)
@@ -255,6 +334,7 @@ func (api *AdminAPI) CreateVolume(volName, owner string, mpCount int,
 	request.addParam("capacity", strconv.FormatUint(capacity, 10))
 	request.addParam("followerRead", strconv.FormatBool(followerRead))
 	request.addParam("zoneName", zoneName)
+	request.addParam("encryption", strconv.FormatBool(encryption))
 	if _, err = api.mc.serveRequest(request); err != nil {
 		return
 	}
@@ -272,6 +352,91 @@ func (api *AdminAPI) CreateDefaultVolume(volName, owner string) (err error) {
 	return
 }
 
+func (api *AdminAPI) SetQuota(volName, authKey, path string, inode, maxFiles, maxBytes uint64) (quota *proto.QuotaInfo, err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminSetQuota)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("path", path)
+	request.addParam("inode", strconv.FormatUint(inode, 10))
+	request.addParam("maxFiles", strconv.FormatUint(maxFiles, 10))
+	request.addParam("maxBytes", strconv.FormatUint(maxBytes, 10))
+	var buf []byte
+	if buf, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	quota = &proto.QuotaInfo{}
+	if err = json.Unmarshal(buf, quota); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) DeleteQuota(volName, authKey string, quotaID uint32) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminDeleteQuota)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("id", strconv.FormatUint(uint64(quotaID), 10))
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) ListQuota(volName string) (quotas []*proto.QuotaInfo, err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminListQuota)
+	request.addParam("name", volName)
+	var buf []byte
+	if buf, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	quotas = make([]*proto.QuotaInfo, 0)
+	if err = json.Unmarshal(buf, &quotas); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) CreateSnapshot(volName, authKey, snapshotName string) (snapshot *proto.SnapshotInfo, err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminCreateSnapshot)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("snapshot", snapshotName)
+	var buf []byte
+	if buf, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	snapshot = &proto.SnapshotInfo{}
+	if err = json.Unmarshal(buf, snapshot); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) DeleteSnapshot(volName, authKey, snapshotName string) (err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminDeleteSnapshot)
+	request.addParam("name", volName)
+	request.addParam("authKey", authKey)
+	request.addParam("snapshot", snapshotName)
+	if _, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	return
+}
+
+func (api *AdminAPI) ListSnapshot(volName string) (snapshots []*proto.SnapshotInfo, err error) {
+	var request = newAPIRequest(http.MethodGet, proto.AdminListSnapshot)
+	request.addParam("name", volName)
+	var buf []byte
+	if buf, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	snapshots = make([]*proto.SnapshotInfo, 0)
+	if err = json.Unmarshal(buf, &snapshots); err != nil {
+		return
+	}
+	return
+}
+
 func (api *AdminAPI) GetVolumeSimpleInfo(volName string) (vv *proto.SimpleVolView, err error) {
 	var request = newAPIRequest(http.MethodGet, proto.AdminGetVol)
 	request.addParam("name", volName)
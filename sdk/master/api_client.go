@@ -153,3 +153,19 @@ func (api *ClientAPI) GetDataPartitions(volName string) (view *proto.DataPartiti
 	}
 	return
 }
+
+func (api *ClientAPI) AcquireTaskLease(name, owner string, ttl int64) (lease *proto.TaskLease, err error) {
+	var request = newAPIRequest(http.MethodPost, proto.AdminAcquireTaskLease)
+	request.addParam("name", name)
+	request.addParam("owner", owner)
+	request.addParam("ttl", strconv.FormatInt(ttl, 10))
+	var data []byte
+	if data, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	lease = &proto.TaskLease{}
+	if err = json.Unmarshal(data, lease); err != nil {
+		return
+	}
+	return
+}
@@ -120,6 +120,74 @@ func (api *UserAPI) RemovePolicy(param *proto.UserPermRemoveParam) (userInfo *pr
 	return
 }
 
+func (api *UserAPI) UpdateIdentityPolicy(param *proto.UserIdentityPolicyParam) (userInfo *proto.UserInfo, err error) {
+	var request = newAPIRequest(http.MethodPost, proto.UserUpdateIdentityPolicy)
+	var reqBody []byte
+	if reqBody, err = json.Marshal(param); err != nil {
+		return
+	}
+	request.addBody(reqBody)
+	var data []byte
+	if data, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	userInfo = &proto.UserInfo{}
+	if err = json.Unmarshal(data, userInfo); err != nil {
+		return
+	}
+	return
+}
+
+func (api *UserAPI) RemoveIdentityPolicy(userID string) (userInfo *proto.UserInfo, err error) {
+	var request = newAPIRequest(http.MethodPost, proto.UserRemoveIdentityPolicy)
+	request.addParam("user", userID)
+	var data []byte
+	if data, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	userInfo = &proto.UserInfo{}
+	if err = json.Unmarshal(data, userInfo); err != nil {
+		return
+	}
+	return
+}
+
+func (api *UserAPI) UpdateS3QoS(param *proto.UserS3QoSParam) (userInfo *proto.UserInfo, err error) {
+	var request = newAPIRequest(http.MethodPost, proto.UserUpdateS3QoS)
+	var reqBody []byte
+	if reqBody, err = json.Marshal(param); err != nil {
+		return
+	}
+	request.addBody(reqBody)
+	var data []byte
+	if data, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	userInfo = &proto.UserInfo{}
+	if err = json.Unmarshal(data, userInfo); err != nil {
+		return
+	}
+	return
+}
+
+func (api *UserAPI) SimulatePolicy(param *proto.UserPolicySimulateParam) (result *proto.UserPolicySimulateResult, err error) {
+	var request = newAPIRequest(http.MethodPost, proto.UserSimulatePolicy)
+	var reqBody []byte
+	if reqBody, err = json.Marshal(param); err != nil {
+		return
+	}
+	request.addBody(reqBody)
+	var data []byte
+	if data, err = api.mc.serveRequest(request); err != nil {
+		return
+	}
+	result = &proto.UserPolicySimulateResult{}
+	if err = json.Unmarshal(data, result); err != nil {
+		return
+	}
+	return
+}
+
 func (api *UserAPI) DeleteVolPolicy(vol string) (err error) {
 	var request = newAPIRequest(http.MethodPost, proto.UserDeleteVolPolicy)
 	request.addParam("name", vol)
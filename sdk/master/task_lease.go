@@ -0,0 +1,62 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// TaskLeaseHolder holds the task lease granted by the master, so that a cluster-wide background task
+// runs in a single process even though every client is able to run it.
+type TaskLeaseHolder struct {
+	mc     *MasterClient
+	name   string
+	owner  string
+	ttl    time.Duration
+	expire time.Time
+	mu     sync.Mutex
+}
+
+func NewTaskLeaseHolder(mc *MasterClient, name string, ttl time.Duration) *TaskLeaseHolder {
+	return &TaskLeaseHolder{mc: mc, name: name, owner: uuid.New().String(), ttl: ttl}
+}
+
+// Hold reports whether the lease is held by this holder, and renews it when half of it has elapsed.
+// The local expiration is counted from the time the request is sent, which never outlives the lease
+// on the master.
+func (h *TaskLeaseHolder) Hold() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Until(h.expire) > h.ttl/2 {
+		return true
+	}
+	start := time.Now()
+	lease, err := h.mc.ClientAPI().AcquireTaskLease(h.name, h.owner, int64(h.ttl/time.Second))
+	if err != nil {
+		log.LogWarnf("TaskLeaseHolder: acquire lease failed, task(%v) err(%v)", h.name, err)
+		return time.Now().Before(h.expire)
+	}
+	if !lease.Granted || lease.Owner != h.owner {
+		h.expire = time.Time{}
+		return false
+	}
+	h.expire = start.Add(time.Duration(lease.TTL) * time.Second)
+	return true
+}
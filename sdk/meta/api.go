@@ -34,6 +34,7 @@ import (
 
 const (
 	BatchIgetRespBuf = 1000
+	ReadDirPageLimit = 1000
 )
 
 const (
@@ -86,6 +87,12 @@ func (mw *MetaWrapper) Create_ll(parentID uint64, name string, mode, uid, gid ui
 		return nil, syscall.ENOENT
 	}
 
+	// the new inode is tagged with the quotas of parent while it is created
+	quotaIDs, err := mw.getQuotaIDs(parentMP, parentID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create Inode
 
 	//	mp = mw.getLatestPartition()
@@ -114,15 +121,18 @@ func (mw *MetaWrapper) Create_ll(parentID uint64, name string, mode, uid, gid ui
 	for i := 0; i < length; i++ {
 		index := (int(epoch) + i) % length
 		mp = rwPartitions[index]
-		status, info, err = mw.icreate(mp, mode, uid, gid, target)
+		status, info, err = mw.icreate(mp, mode, uid, gid, target, quotaIDs)
 		if err == nil && status == statusOK {
 			goto create_dentry
 		}
+		if err == nil && status == statusDquot {
+			return nil, syscall.EDQUOT
+		}
 	}
 	return nil, syscall.ENOMEM
 
 create_dentry:
-	status, err = mw.dcreate(parentMP, parentID, name, info.Inode, mode)
+	status, _, err = mw.dcreate(parentMP, parentID, name, info.Inode, mode)
 	if err != nil {
 		return nil, statusToErrno(status)
 	} else if status != statusOK {
@@ -302,6 +312,16 @@ func (mw *MetaWrapper) BatchGetXAttr(inodes []uint64, keys []string) ([]*proto.X
  * and the caller should make sure InodeInfo is valid before using it.
  */
 func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool) (*proto.InodeInfo, error) {
+	if mw.trashEnabled() {
+		// the inode is still linked by the trash, so nothing is returned to be evicted
+		if moved, err := mw.moveToTrash(parentID, name, isDir); moved || err != nil {
+			return nil, err
+		}
+	}
+	return mw.unlink(parentID, name, isDir)
+}
+
+func (mw *MetaWrapper) unlink(parentID uint64, name string, isDir bool) (*proto.InodeInfo, error) {
 	var (
 		status int
 		inode  uint64
@@ -313,7 +333,7 @@ func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool) (*pro
 
 	parentMP := mw.getPartitionByInode(parentID)
 	if parentMP == nil {
-		log.LogErrorf("Delete_ll: No parent partition, parentID(%v) name(%v)", parentID, name)
+		log.LogErrorf("unlink: No parent partition, parentID(%v) name(%v)", parentID, name)
 		return nil, syscall.ENOENT
 	}
 
@@ -327,7 +347,7 @@ func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool) (*pro
 		}
 		mp = mw.getPartitionByInode(inode)
 		if mp == nil {
-			log.LogErrorf("Delete_ll: No inode partition, parentID(%v) name(%v) ino(%v)", parentID, name, inode)
+			log.LogErrorf("unlink: No inode partition, parentID(%v) name(%v) ino(%v)", parentID, name, inode)
 			return nil, syscall.EAGAIN
 		}
 		status, info, err = mw.iget(mp, inode)
@@ -350,20 +370,41 @@ func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool) (*pro
 	// dentry is deleted successfully but inode is not, still returns success.
 	mp = mw.getPartitionByInode(inode)
 	if mp == nil {
-		log.LogErrorf("Delete_ll: No inode partition, parentID(%v) name(%v) ino(%v)", parentID, name, inode)
+		log.LogErrorf("unlink: No inode partition, parentID(%v) name(%v) ino(%v)", parentID, name, inode)
 		return nil, nil
 	}
 
 	status, info, err = mw.iunlink(mp, inode)
+	if err == nil && status == statusNotPerm {
+		// The inode is protected by object lock, restore the dentry which has been deleted.
+		mw.restoreDentry(parentMP, parentID, name, mp, inode)
+		return nil, syscall.EPERM
+	}
 	if err != nil || status != statusOK {
 		return nil, nil
 	}
 	return info, nil
 }
 
-func (mw *MetaWrapper) Rename_ll(srcParentID uint64, srcName string, dstParentID uint64, dstName string) (err error) {
-	var oldInode uint64
+func (mw *MetaWrapper) restoreDentry(parentMP *MetaPartition, parentID uint64, name string, mp *MetaPartition, inode uint64) {
+	status, info, err := mw.iget(mp, inode)
+	if err != nil || status != statusOK {
+		log.LogErrorf("restoreDentry: get inode fail, parentID(%v) name(%v) ino(%v) status(%v) err(%v)",
+			parentID, name, inode, status, err)
+		return
+	}
+	if status, _, err = mw.dcreate(parentMP, parentID, name, inode, info.Mode); err != nil || status != statusOK {
+		log.LogErrorf("restoreDentry: create dentry fail, parentID(%v) name(%v) ino(%v) status(%v) err(%v)",
+			parentID, name, inode, status, err)
+	}
+}
 
+// Rename_ll renames the source dentry to the destination dentry as a transaction, which keeps atomic
+// even if the source and destination dentries belong to different meta partitions. The destination is
+// prepared before the source, and committing the destination decides the result of the transaction.
+// The source dentry is deleted after that, so the file can always be found by one of its names.
+// Transactions abandoned halfway are resolved by the meta partitions.
+func (mw *MetaWrapper) Rename_ll(srcParentID uint64, srcName string, dstParentID uint64, dstName string) (err error) {
 	srcParentMP := mw.getPartitionByInode(srcParentID)
 	if srcParentMP == nil {
 		return syscall.ENOENT
@@ -378,82 +419,137 @@ func (mw *MetaWrapper) Rename_ll(srcParentID uint64, srcName string, dstParentID
 	if err != nil || status != statusOK {
 		return statusToErrno(status)
 	}
-	srcMP := mw.getPartitionByInode(inode)
-	if srcMP == nil {
-		return syscall.ENOENT
+	if srcParentID == dstParentID && srcName == dstName {
+		return nil
 	}
-
-	status, _, err = mw.ilink(srcMP, inode)
-	if err != nil || status != statusOK {
-		return statusToErrno(status)
+	if dstParentID == inode {
+		return syscall.EINVAL
 	}
 
-	// create dentry in dst parent
-	status, err = mw.dcreate(dstParentMP, dstParentID, dstName, inode, mode)
-	if err != nil {
-		return syscall.EAGAIN
+	txID := util.CreateMultipartID(srcParentMP.PartitionID).String()
+	req := &proto.TxRenamePrepareRequest{
+		VolName:        mw.volname,
+		TxID:           txID,
+		SrcPartitionID: srcParentMP.PartitionID,
+		DstPartitionID: dstParentMP.PartitionID,
+		SrcParentID:    srcParentID,
+		SrcName:        srcName,
+		DstParentID:    dstParentID,
+		DstName:        dstName,
+		Inode:          inode,
+		Mode:           mode,
 	}
 
-	// Note that only regular files are allowed to be overwritten.
-	if status == statusExist && proto.IsRegular(mode) {
-		status, oldInode, err = mw.dupdate(dstParentMP, dstParentID, dstName, inode)
-		if err != nil {
-			return syscall.EAGAIN
-		}
+	// prepare the destination, which checks and locks the destination dentry
+	req.PartitionID, req.Role = dstParentMP.PartitionID, proto.TxRenameRoleDst
+	status, err = mw.txRenamePrepare(dstParentMP, req)
+	if err != nil || status != statusOK {
+		return statusToErrno(status)
 	}
 
-	if status != statusOK {
-		mw.iunlink(srcMP, inode)
+	// prepare the source, which checks and locks the source dentry
+	req.PartitionID, req.Role = srcParentMP.PartitionID, proto.TxRenameRoleSrc
+	status, err = mw.txRenamePrepare(srcParentMP, req)
+	if err != nil || status != statusOK {
+		mw.txRenameAbort(dstParentMP, txID, proto.TxRenameRoleDst)
 		return statusToErrno(status)
 	}
 
-	// delete dentry from src parent
-	status, _, err = mw.ddelete(srcParentMP, srcParentID, srcName)
+	// commit the destination, which applies the destination dentry, releases the overwritten inode
+	// and decides the transaction
+	status, err = mw.txRenameCommit(dstParentMP, txID, proto.TxRenameRoleDst)
 	if err != nil {
-		return statusToErrno(status)
-	} else if status != statusOK {
-		var (
-			sts int
-			e   error
-		)
-		if oldInode == 0 {
-			sts, _, e = mw.ddelete(dstParentMP, dstParentID, dstName)
-		} else {
-			sts, _, e = mw.dupdate(dstParentMP, dstParentID, dstName, oldInode)
-		}
-		if e == nil && sts == statusOK {
-			mw.iunlink(srcMP, inode)
+		// the result is unknown, and the transaction will be resolved by the meta partitions
+		return syscall.EAGAIN
+	}
+	if status != statusOK {
+		mw.txRenameAbort(dstParentMP, txID, proto.TxRenameRoleDst)
+		mw.txRenameAbort(srcParentMP, txID, proto.TxRenameRoleSrc)
+		if status == statusNotPerm {
+			// the transaction has been aborted by the meta partitions
+			return syscall.EAGAIN
 		}
 		return statusToErrno(status)
 	}
 
-	mw.iunlink(srcMP, inode)
+	// commit the source, which deletes the source dentry.
+	// Not exist means the transaction has been rolled forward by the meta partitions.
+	status, err = mw.txRenameCommit(srcParentMP, txID, proto.TxRenameRoleSrc)
+	if err != nil || (status != statusOK && status != statusNoent) {
+		log.LogWarnf("Rename_ll: commit source fail and leave it to meta partitions: txID(%v) srcParentID(%v) srcName(%v) dstParentID(%v) dstName(%v) status(%v) err(%v)",
+			txID, srcParentID, srcName, dstParentID, dstName, status, err)
+	}
 
-	if oldInode != 0 {
-		inodeMP := mw.getPartitionByInode(oldInode)
-		if inodeMP != nil {
-			mw.iunlink(inodeMP, oldInode)
-			// evict oldInode to avoid oldInode becomes orphan inode
-			mw.ievict(inodeMP, oldInode)
+	// the renamed inode is accounted to the quotas of the destination directory from now on
+	if srcParentID != dstParentID {
+		if err = mw.moveQuota(inode, mode, srcParentMP, srcParentID, dstParentMP, dstParentID); err != nil {
+			log.LogWarnf("Rename_ll: move quota fail: txID(%v) inode(%v) srcParentID(%v) dstParentID(%v) err(%v)",
+				txID, inode, srcParentID, dstParentID, err)
 		}
 	}
-
 	return nil
 }
 
+// ReadDir_ll returns all children of the directory, which are read page by page.
 func (mw *MetaWrapper) ReadDir_ll(parentID uint64) ([]proto.Dentry, error) {
+	var children = make([]proto.Dentry, 0)
+	err := mw.ReadDirPages_ll(parentID, "", func(page []proto.Dentry) bool {
+		children = append(children, page...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// ReadDirLimit_ll returns at most limit children of the directory in the order of name,
+// starting from the child whose name is not less than the marker.
+func (mw *MetaWrapper) ReadDirLimit_ll(parentID uint64, marker string, limit uint64) ([]proto.Dentry, error) {
 	parentMP := mw.getPartitionByInode(parentID)
 	if parentMP == nil {
 		return nil, syscall.ENOENT
 	}
 
-	status, children, err := mw.readdir(parentMP, parentID)
+	status, children, err := mw.readdir(parentMP, parentID, marker, limit)
 	if err != nil || status != statusOK {
 		return nil, statusToErrno(status)
 	}
 	return children, nil
 }
 
+// ReadDirPages_ll reads the children of the directory page by page in the order of name, starting from the
+// child whose name is not less than the marker. The function is invoked for each page until it returns false
+// or all children have been read, so that the whole directory never has to be held in one packet.
+func (mw *MetaWrapper) ReadDirPages_ll(parentID uint64, marker string, fn func(children []proto.Dentry) bool) error {
+	var last string
+	for {
+		children, err := mw.ReadDirLimit_ll(parentID, marker, ReadDirPageLimit)
+		if err != nil {
+			return err
+		}
+		var received = len(children)
+		// A meta node which does not support paging ignores the marker and returns the first page again,
+		// whose first child is ordered before the marker, and all children have been returned already.
+		if len(last) > 0 && len(children) > 0 && children[0].Name < last {
+			return nil
+		}
+		// the marker is inclusive, so skip the last child of previous page
+		if len(last) > 0 && len(children) > 0 && children[0].Name == last {
+			children = children[1:]
+		}
+		if len(children) > 0 && !fn(children) {
+			return nil
+		}
+		// a meta node which does not support paging returns all children at once
+		if uint64(received) != ReadDirPageLimit || len(children) == 0 {
+			return nil
+		}
+		last = children[len(children)-1].Name
+		marker = last
+	}
+}
+
 func (mw *MetaWrapper) DentryCreate_ll(parentID uint64, name string, inode uint64, mode uint32) error {
 	parentMP := mw.getPartitionByInode(parentID)
 	if parentMP == nil {
@@ -461,9 +557,13 @@ func (mw *MetaWrapper) DentryCreate_ll(parentID uint64, name string, inode uint6
 	}
 	var err error
 	var status int
-	if status, err = mw.dcreate(parentMP, parentID, name, inode, mode); err != nil || status != statusOK {
+	var quotaIDs []uint32
+	if status, quotaIDs, err = mw.dcreate(parentMP, parentID, name, inode, mode); err != nil || status != statusOK {
 		return statusToErrno(status)
 	}
+	if mp := mw.getPartitionByInode(inode); mp != nil {
+		mw.inheritQuota(mp, inode, quotaIDs)
+	}
 	return nil
 }
 
@@ -474,11 +574,15 @@ func (mw *MetaWrapper) DentryUpdate_ll(parentID uint64, name string, inode uint6
 		return
 	}
 	var status int
-	status, oldInode, err = mw.dupdate(parentMP, parentID, name, inode)
+	var quotaIDs []uint32
+	status, oldInode, quotaIDs, err = mw.dupdate(parentMP, parentID, name, inode)
 	if err != nil || status != statusOK {
 		err = statusToErrno(status)
 		return
 	}
+	if mp := mw.getPartitionByInode(inode); mp != nil {
+		mw.inheritQuota(mp, inode, quotaIDs)
+	}
 	return
 }
 
@@ -514,19 +618,53 @@ func (mw *MetaWrapper) AppendExtentKeys(inode uint64, eks []proto.ExtentKey) err
 	return nil
 }
 
+// PrepareMigration marks the inode as being migrated, the clients write new extents instead of overwriting the
+// extents of the inode in place until its extents are replaced, so that the migration can detect any write.
+func (mw *MetaWrapper) PrepareMigration(inode uint64) error {
+	return mw.doReplaceExtents(inode, nil, nil, true)
+}
+
+// ReplaceExtents replaces the extents of the inode by the migrated extents holding the same data. It returns
+// EINVAL without changing the inode if the extents of the inode are not the same as the old extents.
+func (mw *MetaWrapper) ReplaceExtents(inode uint64, oldEks, newEks []proto.ExtentKey) error {
+	return mw.doReplaceExtents(inode, oldEks, newEks, false)
+}
+
+func (mw *MetaWrapper) doReplaceExtents(inode uint64, oldEks, newEks []proto.ExtentKey, prepare bool) error {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return syscall.ENOENT
+	}
+
+	status, err := mw.replaceExtents(mp, inode, oldEks, newEks, prepare)
+	if err != nil || status != statusOK {
+		log.LogErrorf("ReplaceExtents: inode(%v) prepare(%v) err(%v) status(%v)", inode, prepare, err, status)
+		return statusToErrno(status)
+	}
+	log.LogDebugf("ReplaceExtents: ino(%v) prepare(%v) extentKeys(%v)", inode, prepare, newEks)
+	return nil
+}
+
 func (mw *MetaWrapper) GetExtents(inode uint64) (gen uint64, size uint64, extents []proto.ExtentKey, err error) {
+	gen, size, extents, _, err = mw.GetExtentsCOW(inode)
+	return
+}
+
+// GetExtentsCOW returns the extents of the inode, and whether the extents are shared with the copied inodes
+// or the volume snapshots, in which case the new data must be written to new extents.
+func (mw *MetaWrapper) GetExtentsCOW(inode uint64) (gen uint64, size uint64, extents []proto.ExtentKey, cow bool, err error) {
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
-		return 0, 0, nil, syscall.ENOENT
+		return 0, 0, nil, false, syscall.ENOENT
 	}
 
-	status, gen, size, extents, err := mw.getExtents(mp, inode)
+	status, gen, size, extents, cow, err := mw.getExtents(mp, inode)
 	if err != nil || status != statusOK {
 		log.LogErrorf("GetExtents: ino(%v) err(%v) status(%v)", inode, err, status)
-		return 0, 0, nil, statusToErrno(status)
+		return 0, 0, nil, false, statusToErrno(status)
 	}
-	log.LogDebugf("GetExtents: ino(%v) gen(%v) size(%v)", inode, gen, size)
-	return gen, size, extents, nil
+	log.LogDebugf("GetExtents: ino(%v) gen(%v) size(%v) cow(%v)", inode, gen, size, cow)
+	return gen, size, extents, cow, nil
 }
 
 func (mw *MetaWrapper) Truncate(inode, size uint64) error {
@@ -564,7 +702,7 @@ func (mw *MetaWrapper) Link(parentID uint64, name string, ino uint64) (*proto.In
 	}
 
 	// create new dentry and refer to the inode
-	status, err = mw.dcreate(parentMP, parentID, name, ino, info.Mode)
+	status, _, err = mw.dcreate(parentMP, parentID, name, ino, info.Mode)
 	if err != nil {
 		return nil, statusToErrno(status)
 	} else if status != statusOK {
@@ -608,6 +746,25 @@ func (mw *MetaWrapper) Setattr(inode uint64, valid, mode, uid, gid uint32, atime
 }
 
 func (mw *MetaWrapper) InodeCreate_ll(mode, uid, gid uint32, target []byte) (*proto.InodeInfo, error) {
+	return mw.inodeCreate(mode, uid, gid, target, nil)
+}
+
+// InodeCreateInDir_ll creates an inode which is going to be linked into the specified directory.
+// The inode is tagged with the quotas of the directory while it is created, so that the data
+// written before it is linked is accounted to the quotas too.
+func (mw *MetaWrapper) InodeCreateInDir_ll(parentID uint64, mode, uid, gid uint32, target []byte) (*proto.InodeInfo, error) {
+	parentMP := mw.getPartitionByInode(parentID)
+	if parentMP == nil {
+		return nil, syscall.ENOENT
+	}
+	quotaIDs, err := mw.getQuotaIDs(parentMP, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return mw.inodeCreate(mode, uid, gid, target, quotaIDs)
+}
+
+func (mw *MetaWrapper) inodeCreate(mode, uid, gid uint32, target []byte, quotaIDs []uint32) (*proto.InodeInfo, error) {
 	var (
 		status       int
 		err          error
@@ -622,14 +779,81 @@ func (mw *MetaWrapper) InodeCreate_ll(mode, uid, gid uint32, target []byte) (*pr
 	for i := 0; i < length; i++ {
 		index := (int(epoch) + i) % length
 		mp = rwPartitions[index]
-		status, info, err = mw.icreate(mp, mode, uid, gid, target)
+		status, info, err = mw.icreate(mp, mode, uid, gid, target, quotaIDs)
 		if err == nil && status == statusOK {
 			return info, nil
 		}
+		if err == nil && status == statusDquot {
+			return nil, syscall.EDQUOT
+		}
 	}
 	return nil, syscall.ENOMEM
 }
 
+// InodeCopy_ll is a low-level api that creates a new inode sharing the extents of specified inode.
+// The new inode is created in the same meta partition as the specified inode.
+func (mw *MetaWrapper) InodeCopy_ll(inode uint64) (*proto.InodeInfo, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("InodeCopy_ll: No such partition, ino(%v)", inode)
+		return nil, syscall.EINVAL
+	}
+	status, info, err := mw.icopy(mp, inode, 0, 0)
+	if err != nil || status != statusOK {
+		log.LogErrorf("InodeCopy_ll: ino(%v) err(%v) status(%v)", inode, err, status)
+		if err == nil && status == statusDquot {
+			return nil, syscall.EDQUOT
+		}
+		return nil, statusToErrno(status)
+	}
+	return info, nil
+}
+
+// InodeCopyRange_ll is a low-level api that creates a new inode sharing the extents of specified inode
+// in range [offset, offset+size), the data of the new inode starts at the offset of specified inode.
+// The new inode is created in the same meta partition as the specified inode.
+func (mw *MetaWrapper) InodeCopyRange_ll(inode, offset, size uint64) (*proto.InodeInfo, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("InodeCopyRange_ll: No such partition, ino(%v)", inode)
+		return nil, syscall.EINVAL
+	}
+	status, info, err := mw.icopy(mp, inode, offset, size)
+	if err != nil || status != statusOK {
+		log.LogErrorf("InodeCopyRange_ll: ino(%v) offset(%v) size(%v) err(%v) status(%v)", inode, offset, size, err, status)
+		if err == nil && status == statusDquot {
+			return nil, syscall.EDQUOT
+		}
+		return nil, statusToErrno(status)
+	}
+	return info, nil
+}
+
+// SameInodePartition returns whether the inodes are in the same meta partition.
+func (mw *MetaWrapper) SameInodePartition(a, b uint64) bool {
+	mpA, mpB := mw.getPartitionByInode(a), mw.getPartitionByInode(b)
+	return mpA != nil && mpB != nil && mpA.PartitionID == mpB.PartitionID
+}
+
+// InodeCreateNear_ll is a low-level api that creates an inode in the same meta partition as specified inode,
+// so that the new inode may take over the extents shared by specified inode.
+func (mw *MetaWrapper) InodeCreateNear_ll(inode uint64, mode, uid, gid uint32, target []byte) (*proto.InodeInfo, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("InodeCreateNear_ll: No such partition, ino(%v)", inode)
+		return nil, syscall.EINVAL
+	}
+	status, info, err := mw.icreate(mp, mode, uid, gid, target, nil)
+	if err != nil || status != statusOK {
+		log.LogErrorf("InodeCreateNear_ll: ino(%v) err(%v) status(%v)", inode, err, status)
+		if err == nil && status == statusDquot {
+			return nil, syscall.EDQUOT
+		}
+		return nil, statusToErrno(status)
+	}
+	return info, nil
+}
+
 // InodeUnlink_ll is a low-level api that makes specified inode link value +1.
 func (mw *MetaWrapper) InodeLink_ll(inode uint64) (*proto.InodeInfo, error) {
 	mp := mw.getPartitionByInode(inode)
@@ -902,3 +1126,194 @@ func (mw *MetaWrapper) XAttrsList_ll(inode uint64) ([]string, error) {
 
 	return keys, nil
 }
+
+func (mw *MetaWrapper) CreateVersion_ll(info *proto.VersionInfo) (versionId string, err error) {
+	var (
+		status       int
+		mp           *MetaPartition
+		rwPartitions = mw.getRWPartitions()
+		length       = len(rwPartitions)
+	)
+	if length <= 0 {
+		log.LogErrorf("CreateVersion_ll: no writable partitions, path(%v)", info.Path)
+		return "", syscall.ENOENT
+	}
+
+	epoch := atomic.AddUint64(&mw.epoch, 1)
+	for i := 0; i < length; i++ {
+		index := (int(epoch) + i) % length
+		mp = rwPartitions[index]
+		status, versionId, err = mw.createVersion(mp, info)
+		if err == nil && status == statusOK && len(versionId) > 0 {
+			return versionId, nil
+		}
+		log.LogErrorf("CreateVersion_ll: create version fail, path(%v), mp(%v), status(%v), err(%v)",
+			info.Path, mp, status, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	return "", statusToErrno(status)
+}
+
+// Version ID carries the ID of the meta partition which stores the version.
+func (mw *MetaWrapper) getPartitionByVersionID(versionId string) *MetaPartition {
+	mpId, found := util.MultipartIDFromString(versionId).PartitionID()
+	if !found {
+		return nil
+	}
+	return mw.getPartitionByID(mpId)
+}
+
+func (mw *MetaWrapper) GetVersion_ll(path, versionId string) (info *proto.VersionInfo, err error) {
+	var mp = mw.getPartitionByVersionID(versionId)
+	if mp == nil {
+		log.LogDebugf("GetVersion_ll: meta partition not found by version id, path(%v) versionId(%v)", path, versionId)
+		return nil, syscall.ENOENT
+	}
+	status, info, err := mw.getVersion(mp, path, versionId)
+	if err != nil || status != statusOK {
+		log.LogErrorf("GetVersion_ll: err(%v) status(%v)", err, status)
+		return nil, statusToErrno(status)
+	}
+	return info, nil
+}
+
+func (mw *MetaWrapper) RemoveVersion_ll(path, versionId string) (err error) {
+	var mp = mw.getPartitionByVersionID(versionId)
+	if mp == nil {
+		log.LogDebugf("RemoveVersion_ll: meta partition not found by version id, path(%v) versionId(%v)", path, versionId)
+		return syscall.ENOENT
+	}
+	status, err := mw.removeVersion(mp, path, versionId)
+	if err != nil || status != statusOK {
+		log.LogErrorf("RemoveVersion_ll: partition remove version fail: "+
+			"volume(%v) partitionID(%v) versionID(%v) err(%v) status(%v)",
+			mw.volname, mp.PartitionID, versionId, err, status)
+		return statusToErrno(status)
+	}
+	return
+}
+
+// ListVersions_ll collects versions from all meta partitions and returns them ordered by path,
+// and the newest version first for the same path. Version IDs are ordered by the modify time,
+// so the first maxKeys+1 versions of all partitions are merged in the order of path and version ID.
+func (mw *MetaWrapper) ListVersions_ll(prefix, keyMarker, versionIdMarker string, maxKeys uint64) (versions []*proto.VersionInfo, err error) {
+	partitions := mw.partitions
+	var wg = sync.WaitGroup{}
+	var wl = sync.Mutex{}
+	versions = make([]*proto.VersionInfo, 0)
+
+	for _, mp := range partitions {
+		wg.Add(1)
+		go func(mp *MetaPartition) {
+			defer wg.Done()
+			status, response, listErr := mw.listVersions(mp, prefix, keyMarker, versionIdMarker, maxKeys+1)
+			wl.Lock()
+			defer wl.Unlock()
+			if listErr != nil || status != statusOK {
+				log.LogErrorf("ListVersions_ll: partition list versions fail, partitionID(%v) err(%v) status(%v)",
+					mp.PartitionID, listErr, status)
+				if listErr == nil {
+					listErr = statusToErrno(status)
+				}
+				err = listErr
+				return
+			}
+			versions = append(versions, response.Versions...)
+		}(mp)
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Path != versions[j].Path {
+			return versions[i].Path < versions[j].Path
+		}
+		return versions[i].ID < versions[j].ID
+	})
+	if uint64(len(versions)) > maxKeys+1 {
+		versions = versions[:maxKeys+1]
+	}
+	return versions, nil
+}
+
+// AppendChangeLog_ll appends a change of the object to the change log of the volume. The changes of the same
+// object may be appended to different meta partitions, so the consumer should not rely on the order of them.
+func (mw *MetaWrapper) AppendChangeLog_ll(op uint8, path string) (err error) {
+	var (
+		status       int
+		mp           *MetaPartition
+		rwPartitions = mw.getRWPartitions()
+		length       = len(rwPartitions)
+	)
+	if length <= 0 {
+		log.LogErrorf("AppendChangeLog_ll: no writable partitions, path(%v)", path)
+		return syscall.ENOENT
+	}
+
+	epoch := atomic.AddUint64(&mw.epoch, 1)
+	for i := 0; i < length; i++ {
+		index := (int(epoch) + i) % length
+		mp = rwPartitions[index]
+		status, err = mw.appendChangeLog(mp, op, path)
+		if err == nil && status == statusOK {
+			return nil
+		}
+		log.LogErrorf("AppendChangeLog_ll: append change log fail, path(%v), mp(%v), status(%v), err(%v)",
+			path, mp, status, err)
+	}
+	if err != nil {
+		return err
+	}
+	return statusToErrno(status)
+}
+
+// ListChangeLogs_ll collects at most max change logs after the marker of each meta partition,
+// the markers are indexed by the ID of meta partitions.
+func (mw *MetaWrapper) ListChangeLogs_ll(markers map[uint64]string, max uint64) (changeLogs []*proto.ChangeLogInfo, err error) {
+	partitions := mw.partitions
+	var wg = sync.WaitGroup{}
+	var wl = sync.Mutex{}
+	changeLogs = make([]*proto.ChangeLogInfo, 0)
+
+	for _, mp := range partitions {
+		wg.Add(1)
+		go func(mp *MetaPartition, marker string) {
+			defer wg.Done()
+			status, response, err := mw.listChangeLogs(mp, marker, max)
+			if err != nil || status != statusOK {
+				log.LogErrorf("ListChangeLogs_ll: partition list change logs fail, partitionID(%v) err(%v) status(%v)",
+					mp.PartitionID, err, status)
+				return
+			}
+			wl.Lock()
+			defer wl.Unlock()
+			changeLogs = append(changeLogs, response.ChangeLogs...)
+		}(mp, markers[mp.PartitionID])
+	}
+	wg.Wait()
+
+	sort.SliceStable(changeLogs, func(i, j int) bool {
+		return changeLogs[i].CreateTime.Before(changeLogs[j].CreateTime)
+	})
+	return changeLogs, nil
+}
+
+func (mw *MetaWrapper) RemoveChangeLog_ll(partitionID uint64, id string) (err error) {
+	var mp = mw.getPartitionByID(partitionID)
+	if mp == nil {
+		log.LogErrorf("RemoveChangeLog_ll: no such partition, partitionID(%v) id(%v)", partitionID, id)
+		return syscall.ENOENT
+	}
+	status, err := mw.removeChangeLog(mp, id)
+	if err != nil || status != statusOK {
+		log.LogErrorf("RemoveChangeLog_ll: partition remove change log fail: "+
+			"volume(%v) partitionID(%v) id(%v) err(%v) status(%v)",
+			mw.volname, mp.PartitionID, id, err, status)
+		return statusToErrno(status)
+	}
+	return
+}
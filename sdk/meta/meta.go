@@ -17,6 +17,7 @@ package meta
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -46,6 +47,7 @@ const (
 	statusError
 	statusInval
 	statusNotPerm
+	statusDquot
 )
 
 const (
@@ -75,6 +77,12 @@ type MetaConfig struct {
 	TicketMess       auth.TicketMess
 	ValidateOwner    bool
 	OnAsyncTaskError AsyncTaskErrorFunc
+	// Move the deleted dentries into the trash if the volume retains them.
+	EnableTrash bool
+	// Read the metadata frozen by the volume snapshot, the wrapper must not be used to modify the volume then.
+	Snapshot string
+	// ID of the client in the authnode which issues the ticket, defaults to the owner.
+	ClientID string
 }
 
 type MetaWrapper struct {
@@ -125,6 +133,18 @@ type MetaWrapper struct {
 	// Used to trigger and throttle instant partition updates
 	forceUpdate      chan struct{}
 	forceUpdateLimit *rate.Limiter
+
+	// Trash of the volume, trashDays is updated with the volume view.
+	enableTrash bool
+	trashDays   uint32
+	trashDirs   sync.Map // inodes of the trash and its buckets
+
+	// Limits of the requests to the volume served by the ObjectNodes, updated with the volume view.
+	s3RequestRate uint64
+	s3Bandwidth   uint64
+
+	// ID of the volume snapshot to read, 0 means the volume itself.
+	snapshotID uint64
 }
 
 //the ticket from authnode
@@ -143,13 +163,17 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 	if config.Authenticate {
 		var ticketMess = config.TicketMess
 		mw.ac = authSDK.NewAuthClient(ticketMess.TicketHosts, ticketMess.EnableHTTPS, ticketMess.CertFile)
-		ticket, err := mw.ac.API().GetTicket(config.Owner, ticketMess.ClientKey, proto.MasterServiceID)
+		var clientID = config.ClientID
+		if clientID == "" {
+			clientID = config.Owner
+		}
+		ticket, err := mw.ac.API().GetTicket(clientID, ticketMess.ClientKey, proto.MasterServiceID)
 		if err != nil {
 			return nil, errors.Trace(err, "Get ticket from authnode failed!")
 		}
 		mw.authenticate = config.Authenticate
 		mw.accessToken.Ticket = ticket.Ticket
-		mw.accessToken.ClientID = config.Owner
+		mw.accessToken.ClientID = clientID
 		mw.accessToken.ServiceID = proto.MasterServiceID
 		mw.sessionKey = ticket.SessionKey
 		mw.ticketMess = ticketMess
@@ -160,6 +184,7 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 	mw.ownerValidation = config.ValidateOwner
 	mw.mc = masterSDK.NewMasterClient(config.Masters, false)
 	mw.onAsyncTaskError = config.OnAsyncTaskError
+	mw.enableTrash = config.EnableTrash
 	mw.conns = util.NewConnectPool()
 	mw.partitions = make(map[uint64]*MetaPartition)
 	mw.ranges = btree.New(32)
@@ -189,7 +214,16 @@ func NewMetaWrapper(config *MetaConfig) (*MetaWrapper, error) {
 		return nil, err
 	}
 
+	if config.Snapshot != "" {
+		if err = mw.initSnapshot(config.Snapshot); err != nil {
+			return nil, err
+		}
+	}
+
 	go mw.refresh()
+	if mw.enableTrash {
+		go mw.purgeExpiredTrash()
+	}
 	return mw, nil
 }
 
@@ -221,6 +255,12 @@ func (mw *MetaWrapper) VolCreateTime() int64 {
 	return mw.volCreateTime
 }
 
+// S3QoS returns the limits of requests per second and bytes per second of the volume served by the
+// ObjectNodes, 0 means unlimited.
+func (mw *MetaWrapper) S3QoS() (requestRate, bandwidth uint64) {
+	return atomic.LoadUint64(&mw.s3RequestRate), atomic.LoadUint64(&mw.s3Bandwidth)
+}
+
 func (mw *MetaWrapper) Close() error {
 	mw.closeOnce.Do(func() {
 		close(mw.closeCh)
@@ -258,6 +298,8 @@ func parseStatus(result uint8) (status int) {
 		status = statusInval
 	case proto.OpNotPerm:
 		status = statusNotPerm
+	case proto.OpQuotaExceededErr:
+		status = statusDquot
 	default:
 		status = statusError
 	}
@@ -281,6 +323,8 @@ func statusToErrno(status int) error {
 		return syscall.EINVAL
 	case statusNotPerm:
 		return syscall.EPERM
+	case statusDquot:
+		return syscall.EDQUOT
 	case statusError:
 		return syscall.EAGAIN
 	default:
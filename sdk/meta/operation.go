@@ -28,7 +28,7 @@ import (
 // API implementations
 //
 
-func (mw *MetaWrapper) icreate(mp *MetaPartition, mode, uid, gid uint32, target []byte) (status int, info *proto.InodeInfo, err error) {
+func (mw *MetaWrapper) icreate(mp *MetaPartition, mode, uid, gid uint32, target []byte, quotaIDs []uint32) (status int, info *proto.InodeInfo, err error) {
 	req := &proto.CreateInodeRequest{
 		VolName:     mw.volname,
 		PartitionID: mp.PartitionID,
@@ -36,6 +36,7 @@ func (mw *MetaWrapper) icreate(mp *MetaPartition, mode, uid, gid uint32, target
 		Uid:         uid,
 		Gid:         gid,
 		Target:      target,
+		QuotaIDs:    quotaIDs,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -76,6 +77,53 @@ func (mw *MetaWrapper) icreate(mp *MetaPartition, mode, uid, gid uint32, target
 	return statusOK, resp.Info, nil
 }
 
+func (mw *MetaWrapper) icopy(mp *MetaPartition, inode, offset, size uint64) (status int, info *proto.InodeInfo, err error) {
+	req := &proto.CopyInodeRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		Inode:       inode,
+		Offset:      offset,
+		Size:        size,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaCopyInode
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("icopy: ino(%v) err(%v)", inode, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("icopy: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("icopy: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.CopyInodeResponse)
+	err = packet.UnmarshalData(resp)
+	if err != nil {
+		log.LogErrorf("icopy: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	if resp.Info == nil {
+		err = errors.New(fmt.Sprintf("icopy: info is nil, packet(%v) mp(%v) req(%v) PacketData(%v)", packet, mp, *req, string(packet.Data)))
+		log.LogWarn(err)
+		return
+	}
+	log.LogDebugf("icopy: packet(%v) mp(%v) req(%v) info(%v)", packet, mp, *req, resp.Info)
+	return statusOK, resp.Info, nil
+}
+
 func (mw *MetaWrapper) iunlink(mp *MetaPartition, inode uint64) (status int, info *proto.InodeInfo, err error) {
 	req := &proto.UnlinkInodeRequest{
 		VolName:     mw.volname,
@@ -151,9 +199,9 @@ func (mw *MetaWrapper) ievict(mp *MetaPartition, inode uint64) (status int, err
 	return statusOK, nil
 }
 
-func (mw *MetaWrapper) dcreate(mp *MetaPartition, parentID uint64, name string, inode uint64, mode uint32) (status int, err error) {
+func (mw *MetaWrapper) dcreate(mp *MetaPartition, parentID uint64, name string, inode uint64, mode uint32) (status int, quotaIDs []uint32, err error) {
 	if parentID == inode {
-		return statusExist, nil
+		return statusExist, nil, nil
 	}
 
 	req := &proto.CreateDentryRequest{
@@ -188,13 +236,22 @@ func (mw *MetaWrapper) dcreate(mp *MetaPartition, parentID uint64, name string,
 	} else if status == statusExist {
 		log.LogWarnf("dcreate: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
 	}
+	// a meta node which does not support quota replies without body
+	if status == statusOK && len(packet.Data) > 0 {
+		resp := new(proto.CreateDentryResponse)
+		if err = packet.UnmarshalData(resp); err != nil {
+			log.LogErrorf("dcreate: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+			return
+		}
+		quotaIDs = resp.QuotaIDs
+	}
 	log.LogDebugf("dcreate: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
 	return
 }
 
-func (mw *MetaWrapper) dupdate(mp *MetaPartition, parentID uint64, name string, newInode uint64) (status int, oldInode uint64, err error) {
+func (mw *MetaWrapper) dupdate(mp *MetaPartition, parentID uint64, name string, newInode uint64) (status int, oldInode uint64, quotaIDs []uint32, err error) {
 	if parentID == newInode {
-		return statusExist, 0, nil
+		return statusExist, 0, nil, nil
 	}
 
 	req := &proto.UpdateDentryRequest{
@@ -235,7 +292,7 @@ func (mw *MetaWrapper) dupdate(mp *MetaPartition, parentID uint64, name string,
 		return
 	}
 	log.LogDebugf("dupdate: packet(%v) mp(%v) req(%v) oldIno(%v)", packet, mp, *req, resp.Inode)
-	return statusOK, resp.Inode, nil
+	return statusOK, resp.Inode, resp.QuotaIDs, nil
 }
 
 func (mw *MetaWrapper) ddelete(mp *MetaPartition, parentID uint64, name string) (status int, inode uint64, err error) {
@@ -285,6 +342,7 @@ func (mw *MetaWrapper) lookup(mp *MetaPartition, parentID uint64, name string) (
 		PartitionID: mp.PartitionID,
 		ParentID:    parentID,
 		Name:        name,
+		SnapshotID:  mw.snapshotID,
 	}
 	packet := proto.NewPacketReqID()
 	packet.Opcode = proto.OpMetaLookup
@@ -330,6 +388,7 @@ func (mw *MetaWrapper) iget(mp *MetaPartition, inode uint64) (status int, info *
 		VolName:     mw.volname,
 		PartitionID: mp.PartitionID,
 		Inode:       inode,
+		SnapshotID:  mw.snapshotID,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -373,6 +432,7 @@ func (mw *MetaWrapper) batchIget(wg *sync.WaitGroup, mp *MetaPartition, inodes [
 		VolName:     mw.volname,
 		PartitionID: mp.PartitionID,
 		Inodes:      inodes,
+		SnapshotID:  mw.snapshotID,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -414,11 +474,14 @@ func (mw *MetaWrapper) batchIget(wg *sync.WaitGroup, mp *MetaPartition, inodes [
 	}
 }
 
-func (mw *MetaWrapper) readdir(mp *MetaPartition, parentID uint64) (status int, children []proto.Dentry, err error) {
+func (mw *MetaWrapper) readdir(mp *MetaPartition, parentID uint64, marker string, limit uint64) (status int, children []proto.Dentry, err error) {
 	req := &proto.ReadDirRequest{
 		VolName:     mw.volname,
 		PartitionID: mp.PartitionID,
 		ParentID:    parentID,
+		Marker:      marker,
+		Limit:       limit,
+		SnapshotID:  mw.snapshotID,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -487,11 +550,46 @@ func (mw *MetaWrapper) appendExtentKey(mp *MetaPartition, inode uint64, extent p
 	return status, nil
 }
 
-func (mw *MetaWrapper) getExtents(mp *MetaPartition, inode uint64) (status int, gen, size uint64, extents []proto.ExtentKey, err error) {
+func (mw *MetaWrapper) replaceExtents(mp *MetaPartition, inode uint64, oldEks, newEks []proto.ExtentKey, prepare bool) (status int, err error) {
+	req := &proto.ReplaceExtentsRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		Inode:       inode,
+		Prepare:     prepare,
+		OldExtents:  oldEks,
+		NewExtents:  newEks,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaExtentsReplace
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("replaceExtents: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("replaceExtents: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("replaceExtents: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+	}
+	return status, nil
+}
+
+func (mw *MetaWrapper) getExtents(mp *MetaPartition, inode uint64) (status int, gen, size uint64, extents []proto.ExtentKey, cow bool, err error) {
 	req := &proto.GetExtentsRequest{
 		VolName:     mw.volname,
 		PartitionID: mp.PartitionID,
 		Inode:       inode,
+		SnapshotID:  mw.snapshotID,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -524,7 +622,7 @@ func (mw *MetaWrapper) getExtents(mp *MetaPartition, inode uint64) (status int,
 		log.LogErrorf("getExtents: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
 		return
 	}
-	return statusOK, resp.Generation, resp.Size, resp.Extents, nil
+	return statusOK, resp.Generation, resp.Size, resp.Extents, resp.CopyOnWrite, nil
 }
 
 func (mw *MetaWrapper) truncate(mp *MetaPartition, inode, size uint64) (status int, err error) {
@@ -927,6 +1025,7 @@ func (mw *MetaWrapper) getXAttr(mp *MetaPartition, inode uint64, name string) (v
 		PartitionId: mp.PartitionID,
 		Inode:       inode,
 		Key:         name,
+		SnapshotID:  mw.snapshotID,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -1003,6 +1102,7 @@ func (mw *MetaWrapper) listXAttr(mp *MetaPartition, inode uint64) (keys []string
 		VolName:     mw.volname,
 		PartitionId: mp.PartitionID,
 		Inode:       inode,
+		SnapshotID:  mw.snapshotID,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -1093,6 +1193,7 @@ func (mw *MetaWrapper) batchGetXAttr(mp *MetaPartition, inodes []uint64, keys []
 		PartitionId: mp.PartitionID,
 		Inodes:      inodes,
 		Keys:        keys,
+		SnapshotID:  mw.snapshotID,
 	}
 	packet := proto.NewPacketReqID()
 	packet.Opcode = proto.OpMetaBatchGetXAttr
@@ -1125,3 +1226,368 @@ func (mw *MetaWrapper) batchGetXAttr(mp *MetaPartition, inodes []uint64, keys []
 
 	return resp.XAttrs, nil
 }
+
+func (mw *MetaWrapper) createVersion(mp *MetaPartition, info *proto.VersionInfo) (status int, versionId string, err error) {
+	req := &proto.CreateVersionRequest{
+		PartitionId:  mp.PartitionID,
+		VolName:      mw.volname,
+		Path:         info.Path,
+		Inode:        info.Inode,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		ModifyTime:   info.ModifyTime,
+		DeleteMarker: info.DeleteMarker,
+		Null:         info.Null,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpCreateVersion
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("createVersion: err(%v)", err)
+		return
+	}
+
+	log.LogDebugf("createVersion enter: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("createVersion: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("createVersion: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.CreateVersionResponse)
+	err = packet.UnmarshalData(resp)
+	if err != nil {
+		log.LogErrorf("createVersion: packet(%v) mp(%v) req(%v) err(%v) PacketData(%v)", packet, mp, *req, err, string(packet.Data))
+		return
+	}
+	return statusOK, resp.Info.ID, nil
+}
+
+func (mw *MetaWrapper) getVersion(mp *MetaPartition, path, versionId string) (status int, info *proto.VersionInfo, err error) {
+	req := &proto.GetVersionRequest{
+		PartitionId: mp.PartitionID,
+		VolName:     mw.volname,
+		Path:        path,
+		VersionId:   versionId,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpGetVersion
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("getVersion: err(%v)", err)
+		return
+	}
+
+	log.LogDebugf("getVersion enter: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("getVersion: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("getVersion: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.GetVersionResponse)
+	err = packet.UnmarshalData(resp)
+	if err != nil {
+		log.LogErrorf("getVersion: packet(%v) mp(%v) req(%v) err(%v) PacketData(%v)", packet, mp, *req, err, string(packet.Data))
+		return
+	}
+	return statusOK, resp.Info, nil
+}
+
+func (mw *MetaWrapper) removeVersion(mp *MetaPartition, path, versionId string) (status int, err error) {
+	req := &proto.RemoveVersionRequest{
+		PartitionId: mp.PartitionID,
+		VolName:     mw.volname,
+		Path:        path,
+		VersionId:   versionId,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpRemoveVersion
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("removeVersion: err[%v]", err)
+		return
+	}
+	log.LogDebugf("removeVersion: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("removeVersion: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("removeVersion: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("removeVersion: packet(%v) mp(%v) req(%v) PacketData(%v)", packet, mp, *req, packet.Data)
+	return statusOK, nil
+}
+
+func (mw *MetaWrapper) listVersions(mp *MetaPartition, prefix, keyMarker, versionIdMarker string, maxKeys uint64) (status int, versions *proto.ListVersionsResponse, err error) {
+	req := &proto.ListVersionsRequest{
+		VolName:         mw.volname,
+		PartitionId:     mp.PartitionID,
+		Marker:          keyMarker,
+		VersionIdMarker: versionIdMarker,
+		Max:             maxKeys,
+		Prefix:          prefix,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpListVersions
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("listVersions: err(%v)", err)
+		return
+	}
+
+	log.LogDebugf("listVersions enter: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("listVersions: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("listVersions: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.ListVersionsResponse)
+	err = packet.UnmarshalData(resp)
+	if err != nil {
+		log.LogErrorf("listVersions: packet(%v) mp(%v) req(%v) err(%v) PacketData(%v)", packet, mp, *req, err, string(packet.Data))
+		return
+	}
+
+	return statusOK, resp, nil
+}
+
+func (mw *MetaWrapper) txRenamePrepare(mp *MetaPartition, req *proto.TxRenamePrepareRequest) (status int, err error) {
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaTxRenamePrepare
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("txRenamePrepare: err[%v]", err)
+		return
+	}
+	log.LogDebugf("txRenamePrepare: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("txRenamePrepare: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("txRenamePrepare: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("txRenamePrepare: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
+
+func (mw *MetaWrapper) txRenameCommit(mp *MetaPartition, txID string, role uint8) (status int, err error) {
+	req := &proto.TxRenameCommitRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		TxID:        txID,
+		Role:        role,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaTxRenameCommit
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("txRenameCommit: err[%v]", err)
+		return
+	}
+	log.LogDebugf("txRenameCommit: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("txRenameCommit: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("txRenameCommit: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("txRenameCommit: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
+
+func (mw *MetaWrapper) txRenameAbort(mp *MetaPartition, txID string, role uint8) (status int, err error) {
+	req := &proto.TxRenameAbortRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		TxID:        txID,
+		Role:        role,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaTxRenameAbort
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("txRenameAbort: err[%v]", err)
+		return
+	}
+	log.LogDebugf("txRenameAbort: packet(%v) mp(%v) req(%v)", packet, mp, string(packet.Data))
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("txRenameAbort: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("txRenameAbort: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("txRenameAbort: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
+
+func (mw *MetaWrapper) appendChangeLog(mp *MetaPartition, op uint8, path string) (status int, err error) {
+	req := &proto.AppendChangeLogRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		Op:          op,
+		Path:        path,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpAppendChangeLog
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("appendChangeLog: err(%v)", err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("appendChangeLog: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("appendChangeLog: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("appendChangeLog: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
+
+func (mw *MetaWrapper) listChangeLogs(mp *MetaPartition, marker string, max uint64) (status int, changeLogs *proto.ListChangeLogsResponse, err error) {
+	req := &proto.ListChangeLogsRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		Marker:      marker,
+		Max:         max,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpListChangeLogs
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("listChangeLogs: err(%v)", err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("listChangeLogs: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("listChangeLogs: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp := new(proto.ListChangeLogsResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("listChangeLogs: packet(%v) mp(%v) req(%v) err(%v) PacketData(%v)", packet, mp, *req, err, string(packet.Data))
+		return
+	}
+	return statusOK, resp, nil
+}
+
+func (mw *MetaWrapper) removeChangeLog(mp *MetaPartition, id string) (status int, err error) {
+	req := &proto.RemoveChangeLogRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		ID:          id,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpRemoveChangeLog
+	if err = packet.MarshalData(req); err != nil {
+		log.LogErrorf("removeChangeLog: err(%v)", err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer metric.Set(err)
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("removeChangeLog: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		log.LogErrorf("removeChangeLog: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("removeChangeLog: packet(%v) mp(%v) req(%v)", packet, mp, *req)
+	return statusOK, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// Return the IDs of the quotas the inode is accounted to.
+func (mw *MetaWrapper) getQuotaIDs(mp *MetaPartition, inode uint64) ([]uint32, error) {
+	value, status, err := mw.getXAttr(mp, inode, proto.QuotaXAttrKey)
+	if err != nil || status != statusOK {
+		log.LogErrorf("getQuotaIDs: inode(%v) err(%v) status(%v)", inode, err, status)
+		return nil, statusToErrno(status)
+	}
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return proto.QuotaIDsFromXAttr(value), nil
+}
+
+// Tag a newly linked inode with the quotas of its parent, so that it is accounted by the meta partition holding it.
+func (mw *MetaWrapper) inheritQuota(mp *MetaPartition, inode uint64, quotaIDs []uint32) {
+	if len(quotaIDs) == 0 {
+		return
+	}
+	value := proto.QuotaIDsToXAttr(quotaIDs)
+	status, err := mw.setXAttr(mp, inode, []byte(proto.QuotaXAttrKey), []byte(value))
+	if err != nil || status != statusOK {
+		log.LogWarnf("inheritQuota: inode(%v) quotaIDs(%v) err(%v) status(%v)", inode, value, err, status)
+	}
+}
+
+// ApplyQuota_ll tags the directory and all the inodes beneath it with the quota,
+// which makes them accounted to the quota by the meta partitions.
+func (mw *MetaWrapper) ApplyQuota_ll(inode uint64, quotaID uint32) (err error) {
+	var dirs = []uint64{inode}
+	if err = mw.addQuotaID(inode, quotaID); err != nil {
+		return
+	}
+	for len(dirs) > 0 {
+		parentID := dirs[0]
+		dirs = dirs[1:]
+		var tagErr error
+		err = mw.ReadDirPages_ll(parentID, "", func(children []proto.Dentry) bool {
+			for _, child := range children {
+				if tagErr = mw.addQuotaID(child.Inode, quotaID); tagErr != nil {
+					return false
+				}
+				if proto.IsDir(child.Type) {
+					dirs = append(dirs, child.Inode)
+				}
+			}
+			return true
+		})
+		if err == nil {
+			err = tagErr
+		}
+		if err != nil {
+			log.LogErrorf("ApplyQuota_ll: inode(%v) quotaID(%v) err(%v)", parentID, quotaID, err)
+			return
+		}
+	}
+	return
+}
+
+func (mw *MetaWrapper) addQuotaID(inode uint64, quotaID uint32) (err error) {
+	var info *proto.XAttrInfo
+	if info, err = mw.XAttrGet_ll(inode, proto.QuotaXAttrKey); err != nil {
+		return
+	}
+	ids := proto.QuotaIDsFromXAttr(string(info.Get(proto.QuotaXAttrKey)))
+	for _, id := range ids {
+		if id == quotaID {
+			return
+		}
+	}
+	value := proto.QuotaIDsToXAttr(append(ids, quotaID))
+	return mw.XAttrSet_ll(inode, []byte(proto.QuotaXAttrKey), []byte(value))
+}
+
+// moveQuota re-tags the inode renamed from the source directory to the destination directory, and all the
+// inodes beneath it if it is a directory. The quotas of the source directory are replaced by the quotas of
+// the destination directory, while the quotas bound to the inode itself or its descendants are kept.
+func (mw *MetaWrapper) moveQuota(inode uint64, mode uint32, srcParentMP *MetaPartition, srcParentID uint64,
+	dstParentMP *MetaPartition, dstParentID uint64) (err error) {
+	var srcIDs, dstIDs []uint32
+	if srcIDs, err = mw.getQuotaIDs(srcParentMP, srcParentID); err != nil {
+		return
+	}
+	if dstIDs, err = mw.getQuotaIDs(dstParentMP, dstParentID); err != nil {
+		return
+	}
+	if proto.QuotaIDsToXAttr(srcIDs) == proto.QuotaIDsToXAttr(dstIDs) {
+		return
+	}
+	var retag = func(ino uint64) error {
+		info, getErr := mw.XAttrGet_ll(ino, proto.QuotaXAttrKey)
+		if getErr != nil {
+			return getErr
+		}
+		var value = string(info.Get(proto.QuotaXAttrKey))
+		var ids = make([]uint32, 0)
+		for _, id := range proto.QuotaIDsFromXAttr(value) {
+			if !containsQuotaID(srcIDs, id) && !containsQuotaID(dstIDs, id) {
+				ids = append(ids, id)
+			}
+		}
+		ids = append(ids, dstIDs...)
+		if newValue := proto.QuotaIDsToXAttr(ids); newValue != value {
+			return mw.XAttrSet_ll(ino, []byte(proto.QuotaXAttrKey), []byte(newValue))
+		}
+		return nil
+	}
+	if err = retag(inode); err != nil || !proto.IsDir(mode) {
+		return
+	}
+	var dirs = []uint64{inode}
+	for len(dirs) > 0 {
+		parentID := dirs[0]
+		dirs = dirs[1:]
+		var tagErr error
+		err = mw.ReadDirPages_ll(parentID, "", func(children []proto.Dentry) bool {
+			for _, child := range children {
+				if tagErr = retag(child.Inode); tagErr != nil {
+					return false
+				}
+				if proto.IsDir(child.Type) {
+					dirs = append(dirs, child.Inode)
+				}
+			}
+			return true
+		})
+		if err == nil {
+			err = tagErr
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func containsQuotaID(ids []uint32, id uint32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
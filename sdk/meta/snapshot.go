@@ -0,0 +1,43 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+
+	"github.com/chubaofs/chubaofs/proto"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+func (mw *MetaWrapper) initSnapshot(name string) (err error) {
+	var snapshots []*proto.SnapshotInfo
+	if snapshots, err = mw.mc.AdminAPI().ListSnapshot(mw.volname); err != nil {
+		log.LogErrorf("initSnapshot: list snapshots failed: volume(%v) err(%v)", mw.volname, err)
+		return
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			mw.snapshotID = snapshot.ID
+			log.LogInfof("initSnapshot: volume(%v) snapshot(%v) id(%v)", mw.volname, name, snapshot.ID)
+			return nil
+		}
+	}
+	return fmt.Errorf("snapshot %v of volume %v not found", name, mw.volname)
+}
+
+// SnapshotID returns the ID of the volume snapshot read by the wrapper, 0 means the volume itself.
+func (mw *MetaWrapper) SnapshotID() uint64 {
+	return mw.snapshotID
+}
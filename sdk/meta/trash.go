@@ -0,0 +1,361 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/chubaofs/chubaofs/proto"
+	masterSDK "github.com/chubaofs/chubaofs/sdk/master"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+const (
+	// The trash is a hidden directory under the root of the volume. Deleted dentries are
+	// moved into its hourly buckets named in UTC, and a bucket is purged as a whole once it expires.
+	TrashDirName      = ".Trash"
+	TrashBucketLayout = "2006010215"
+	TrashXAttrKey     = "cfs.trash"
+	trashDirMark      = "dir"
+
+	PurgeTrashInterval = time.Hour
+
+	// Only the client holding the lease of the volume purges its trash.
+	trashLeaseTTL           = 5 * time.Minute
+	trashLeaseCheckInterval = time.Minute
+)
+
+// TrashOrigin records where a trashed dentry comes from, it is kept in the xattr of the inode.
+type TrashOrigin struct {
+	ParentID   uint64 `json:"pino"`
+	Name       string `json:"name"`
+	DeleteTime int64  `json:"time"`
+}
+
+type TrashEntry struct {
+	Bucket string
+	Name   string
+	Inode  uint64
+	Type   uint32
+	TrashOrigin
+}
+
+func (mw *MetaWrapper) trashEnabled() bool {
+	return mw.enableTrash && atomic.LoadUint32(&mw.trashDays) > 0
+}
+
+// TrashDays returns the retention of the trash, 0 means the trash is disabled.
+func (mw *MetaWrapper) TrashDays() uint32 {
+	return atomic.LoadUint32(&mw.trashDays)
+}
+
+// Move the dentry into the current bucket of the trash. Dentries which are already in the trash
+// are not moved, the caller should delete them.
+func (mw *MetaWrapper) moveToTrash(parentID uint64, name string, isDir bool) (moved bool, err error) {
+	if parentID == proto.RootIno && name == TrashDirName {
+		return
+	}
+	if mw.isTrashDir(parentID) {
+		return
+	}
+	inode, mode, err := mw.Lookup_ll(parentID, name)
+	if err == syscall.ENOENT {
+		return false, nil
+	}
+	if err != nil {
+		return
+	}
+	if isDir {
+		if !proto.IsDir(mode) {
+			return false, syscall.EINVAL
+		}
+		var info *proto.InodeInfo
+		if info, err = mw.InodeGet_ll(inode); err != nil {
+			return
+		}
+		if info.Nlink > 2 {
+			return false, syscall.ENOTEMPTY
+		}
+	}
+
+	now := time.Now().UTC()
+	bucket, err := mw.trashBucket(now)
+	if err != nil {
+		log.LogErrorf("moveToTrash: get bucket failed, parentID(%v) name(%v) err(%v)", parentID, name, err)
+		return
+	}
+	origin, _ := json.Marshal(&TrashOrigin{ParentID: parentID, Name: name, DeleteTime: now.Unix()})
+	if err = mw.XAttrSet_ll(inode, []byte(TrashXAttrKey), origin); err != nil {
+		return
+	}
+	if err = mw.Rename_ll(parentID, name, bucket, fmt.Sprintf("%v_%v", name, now.UnixNano())); err != nil {
+		log.LogErrorf("moveToTrash: parentID(%v) name(%v) ino(%v) bucket(%v) err(%v)", parentID, name, inode, bucket, err)
+		if delErr := mw.XAttrDel_ll(inode, TrashXAttrKey); delErr != nil {
+			log.LogWarnf("moveToTrash: remove origin failed, ino(%v) err(%v)", inode, delErr)
+		}
+		return
+	}
+	log.LogDebugf("moveToTrash: parentID(%v) name(%v) ino(%v) bucket(%v)", parentID, name, inode, bucket)
+	return true, nil
+}
+
+func (mw *MetaWrapper) isTrashDir(inode uint64) bool {
+	if inode == proto.RootIno {
+		return false
+	}
+	if _, ok := mw.trashDirs.Load(inode); ok {
+		return true
+	}
+	info, err := mw.XAttrGet_ll(inode, TrashXAttrKey)
+	if err != nil || string(info.Get(TrashXAttrKey)) != trashDirMark {
+		return false
+	}
+	mw.trashDirs.Store(inode, struct{}{})
+	return true
+}
+
+func (mw *MetaWrapper) trashBucket(t time.Time) (uint64, error) {
+	trash, err := mw.makeTrashDir(proto.RootIno, TrashDirName)
+	if err != nil {
+		return 0, err
+	}
+	return mw.makeTrashDir(trash, t.Format(TrashBucketLayout))
+}
+
+func (mw *MetaWrapper) makeTrashDir(parentID uint64, name string) (uint64, error) {
+	inode, _, err := mw.Lookup_ll(parentID, name)
+	if err == syscall.ENOENT {
+		var info *proto.InodeInfo
+		info, err = mw.Create_ll(parentID, name, proto.Mode(os.ModeDir|0700), 0, 0, nil)
+		if err == syscall.EEXIST {
+			inode, _, err = mw.Lookup_ll(parentID, name)
+		} else if err == nil {
+			inode = info.Inode
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := mw.trashDirs.Load(inode); ok {
+		return inode, nil
+	}
+	if err = mw.XAttrSet_ll(inode, []byte(TrashXAttrKey), []byte(trashDirMark)); err != nil {
+		return 0, err
+	}
+	mw.trashDirs.Store(inode, struct{}{})
+	return inode, nil
+}
+
+func (mw *MetaWrapper) trashBuckets() (trash uint64, buckets []proto.Dentry, err error) {
+	if trash, _, err = mw.Lookup_ll(proto.RootIno, TrashDirName); err != nil {
+		return
+	}
+	if buckets, err = mw.ReadDir_ll(trash); err != nil {
+		return
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	return
+}
+
+// ListTrash_ll returns the dentries in the trash along with where they come from.
+func (mw *MetaWrapper) ListTrash_ll() (entries []*TrashEntry, err error) {
+	_, buckets, err := mw.trashBuckets()
+	if err == syscall.ENOENT {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	for _, bucket := range buckets {
+		var children []proto.Dentry
+		if children, err = mw.ReadDir_ll(bucket.Inode); err != nil {
+			return
+		}
+		for _, child := range children {
+			entry := &TrashEntry{Bucket: bucket.Name, Name: child.Name, Inode: child.Inode, Type: child.Type}
+			var info *proto.XAttrInfo
+			if info, err = mw.XAttrGet_ll(child.Inode, TrashXAttrKey); err != nil {
+				return
+			}
+			if err = json.Unmarshal(info.Get(TrashXAttrKey), &entry.TrashOrigin); err != nil {
+				log.LogWarnf("ListTrash_ll: bucket(%v) name(%v) ino(%v) invalid origin(%v)",
+					bucket.Name, child.Name, child.Inode, string(info.Get(TrashXAttrKey)))
+				err = nil
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return
+}
+
+// RestoreTrash_ll moves the dentry in the trash back to where it is deleted from,
+// it fails if the original path is occupied or its parent has been removed.
+// A directory is emptied before it is moved into the trash, so its children are trashed before it.
+// The parent which is in the trash as well is restored first, otherwise the child would be
+// restored into the trash.
+func (mw *MetaWrapper) RestoreTrash_ll(bucket, name string) (err error) {
+	trash, _, err := mw.Lookup_ll(proto.RootIno, TrashDirName)
+	if err != nil {
+		return
+	}
+	bucketIno, _, err := mw.Lookup_ll(trash, bucket)
+	if err != nil {
+		return
+	}
+	inode, _, err := mw.Lookup_ll(bucketIno, name)
+	if err != nil {
+		return
+	}
+	info, err := mw.XAttrGet_ll(inode, TrashXAttrKey)
+	if err != nil {
+		return
+	}
+	var origin TrashOrigin
+	if err = json.Unmarshal(info.Get(TrashXAttrKey), &origin); err != nil {
+		return syscall.EINVAL
+	}
+	if err = mw.restoreTrashedParent(origin.ParentID); err != nil {
+		return
+	}
+	if _, _, err = mw.Lookup_ll(origin.ParentID, origin.Name); err == nil {
+		return syscall.EEXIST
+	} else if err != syscall.ENOENT {
+		return
+	}
+	if err = mw.Rename_ll(bucketIno, name, origin.ParentID, origin.Name); err != nil {
+		return
+	}
+	if err = mw.XAttrDel_ll(inode, TrashXAttrKey); err != nil {
+		log.LogWarnf("RestoreTrash_ll: remove origin failed, ino(%v) err(%v)", inode, err)
+	}
+	return nil
+}
+
+// Restore the parent if it has been moved into the trash.
+func (mw *MetaWrapper) restoreTrashedParent(parentID uint64) (err error) {
+	if parentID == proto.RootIno || mw.isTrashDir(parentID) {
+		return
+	}
+	info, err := mw.XAttrGet_ll(parentID, TrashXAttrKey)
+	if err == syscall.ENOENT {
+		return nil
+	}
+	if err != nil || len(info.Get(TrashXAttrKey)) == 0 {
+		return
+	}
+	entries, err := mw.ListTrash_ll()
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.Inode == parentID {
+			return mw.RestoreTrash_ll(entry.Bucket, entry.Name)
+		}
+	}
+	return nil
+}
+
+// PurgeTrash_ll deletes the buckets of the trash whose dentries are all deleted before the specified time.
+func (mw *MetaWrapper) PurgeTrash_ll(before time.Time) (err error) {
+	return mw.purgeTrash(before, nil)
+}
+
+// Purge the expired buckets while hold reports true, hold is checked before purging each bucket.
+func (mw *MetaWrapper) purgeTrash(before time.Time, hold func() bool) (err error) {
+	trash, buckets, err := mw.trashBuckets()
+	if err == syscall.ENOENT {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	for _, bucket := range buckets {
+		t, parseErr := time.Parse(TrashBucketLayout, bucket.Name)
+		if parseErr != nil || t.Add(time.Hour).After(before) {
+			continue
+		}
+		if hold != nil && !hold() {
+			log.LogWarnf("purgeTrash: lease of vol(%v) is lost, stop purging", mw.volname)
+			return
+		}
+		if err = mw.removeTree(trash, bucket); err != nil {
+			log.LogErrorf("PurgeTrash_ll: bucket(%v) err(%v)", bucket.Name, err)
+			return
+		}
+		mw.trashDirs.Delete(bucket.Inode)
+		log.LogInfof("PurgeTrash_ll: bucket(%v) purged", bucket.Name)
+	}
+	return
+}
+
+// Delete the dentry and everything beneath it permanently.
+func (mw *MetaWrapper) removeTree(parentID uint64, dentry proto.Dentry) (err error) {
+	isDir := proto.IsDir(dentry.Type)
+	if isDir {
+		var children []proto.Dentry
+		if children, err = mw.ReadDir_ll(dentry.Inode); err != nil {
+			return
+		}
+		for _, child := range children {
+			if err = mw.removeTree(dentry.Inode, child); err != nil {
+				return
+			}
+		}
+	}
+	if _, err = mw.unlink(parentID, dentry.Name, isDir); err != nil {
+		return
+	}
+	if !isDir {
+		if err = mw.Evict(dentry.Inode); err != nil {
+			log.LogWarnf("removeTree: evict failed, ino(%v) err(%v)", dentry.Inode, err)
+			err = nil
+		}
+	}
+	return
+}
+
+// Every client with the trash enabled runs this loop, but only the one holding the trash lease of
+// the volume granted by the master purges it. The lease is renewed in every check, so that the
+// other clients stay idle as long as the holder is alive.
+func (mw *MetaWrapper) purgeExpiredTrash() {
+	t := time.NewTicker(trashLeaseCheckInterval)
+	defer t.Stop()
+
+	var (
+		lease     = masterSDK.NewTaskLeaseHolder(mw.mc, "trash."+mw.volname, trashLeaseTTL)
+		lastPurge time.Time
+	)
+	for {
+		select {
+		case <-t.C:
+			days := mw.TrashDays()
+			if days == 0 || !lease.Hold() || time.Since(lastPurge) < PurgeTrashInterval {
+				continue
+			}
+			lastPurge = time.Now()
+			if err := mw.purgeTrash(time.Now().AddDate(0, 0, -int(days)), lease.Hold); err != nil {
+				log.LogErrorf("purgeExpiredTrash: err(%v)", err)
+			}
+		case <-mw.closeCh:
+			return
+		}
+	}
+}
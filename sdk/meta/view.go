@@ -43,6 +43,9 @@ type VolumeView struct {
 	MetaPartitions []*MetaPartition
 	OSSSecure      *OSSSecure
 	CreateTime     int64
+	TrashDays      uint32
+	S3RequestRate  uint64
+	S3Bandwidth    uint64
 }
 
 type OSSSecure struct {
@@ -70,7 +73,7 @@ func (mw *MetaWrapper) fetchVolumeView() (view *VolumeView, err error) {
 				return nil, err
 			}
 			var decoder master.Decoder = func(raw []byte) ([]byte, error) {
-				return mw.parseAndVerifyResp(raw, ts)
+				return mw.parseAndVerifyResp(raw, proto.MsgMasterFetchVolViewReq, ts)
 			}
 			if vv, err = mw.mc.ClientAPI().GetVolumeWithAuthnode(mw.volname, authKey, tokenMessage, decoder); err != nil {
 				return
@@ -92,6 +95,9 @@ func (mw *MetaWrapper) fetchVolumeView() (view *VolumeView, err error) {
 			MetaPartitions: make([]*MetaPartition, len(volView.MetaPartitions)),
 			OSSSecure:      &OSSSecure{},
 			CreateTime:     volView.CreateTime,
+			TrashDays:      volView.TrashDays,
+			S3RequestRate:  volView.S3RequestRate,
+			S3Bandwidth:    volView.S3Bandwidth,
 		}
 		if volView.OSSSecure != nil {
 			result.OSSSecure.AccessKey = volView.OSSSecure.AccessKey
@@ -113,6 +119,32 @@ func (mw *MetaWrapper) fetchVolumeView() (view *VolumeView, err error) {
 	return
 }
 
+// GetEncryptionKey fetches the data key of the encrypted volume from the master, which is served only with
+// the ticket of the authnode granting the access to the volume.
+func (mw *MetaWrapper) GetEncryptionKey() (key []byte, err error) {
+	if !mw.authenticate {
+		return nil, fmt.Errorf("GetEncryptionKey: the ticket of the authnode is required by the encrypted volume")
+	}
+	var (
+		token        = mw.accessToken
+		tokenMessage string
+		ts           int64
+	)
+	token.Type = proto.MsgMasterGetVolEncryptionKeyReq
+	if tokenMessage, ts, err = genMasterToken(token, mw.sessionKey); err != nil {
+		log.LogWarnf("GetEncryptionKey generate token failed: err(%v)", err)
+		return nil, err
+	}
+	var decoder master.Decoder = func(raw []byte) ([]byte, error) {
+		return mw.parseAndVerifyResp(raw, token.Type, ts)
+	}
+	if key, err = mw.mc.AdminAPI().GetVolumeEncryptionKey(mw.volname, tokenMessage, decoder); err != nil {
+		log.LogWarnf("GetEncryptionKey: get volume encryption key fail: volume(%v) err(%v)", mw.volname, err)
+		return
+	}
+	return
+}
+
 // fetch and update cluster info if successful
 func (mw *MetaWrapper) updateClusterInfo() (err error) {
 	var info *proto.ClusterInfo
@@ -174,6 +206,9 @@ func (mw *MetaWrapper) updateMetaPartitions() error {
 	}
 	mw.ossSecure = view.OSSSecure
 	mw.volCreateTime = view.CreateTime
+	atomic.StoreUint32(&mw.trashDays, view.TrashDays)
+	atomic.StoreUint64(&mw.s3RequestRate, view.S3RequestRate)
+	atomic.StoreUint64(&mw.s3Bandwidth, view.S3Bandwidth)
 
 	if len(rwPartitions) == 0 {
 		log.LogInfof("updateMetaPartition: no valid partitions")
@@ -275,7 +310,7 @@ func genMasterToken(req proto.APIAccessReq, key string) (message string, ts int6
 }
 
 func (mw *MetaWrapper) updateTicket() error {
-	ticket, err := mw.ac.API().GetTicket(mw.owner, mw.ticketMess.ClientKey, proto.MasterServiceID)
+	ticket, err := mw.ac.API().GetTicket(mw.accessToken.ClientID, mw.ticketMess.ClientKey, proto.MasterServiceID)
 	if err != nil {
 		return errors.Trace(err, "Update ticket from authnode failed!")
 	}
@@ -284,13 +319,13 @@ func (mw *MetaWrapper) updateTicket() error {
 	return nil
 }
 
-func (mw *MetaWrapper) parseAndVerifyResp(body []byte, ts int64) (dataBody []byte, err error) {
+func (mw *MetaWrapper) parseAndVerifyResp(body []byte, msg proto.MsgType, ts int64) (dataBody []byte, err error) {
 	var resp proto.MasterAPIAccessResp
 	if resp, err = mw.parseRespWithAuth(body); err != nil {
 		log.LogWarnf("fetchVolumeView parse response failed: err(%v) body(%v)", err, string(body))
 		return nil, err
 	}
-	if err = proto.VerifyAPIRespComm(&(resp.APIResp), mw.accessToken.Type, mw.owner, proto.MasterServiceID, ts); err != nil {
+	if err = proto.VerifyAPIRespComm(&(resp.APIResp), msg, mw.accessToken.ClientID, proto.MasterServiceID, ts); err != nil {
 		log.LogWarnf("fetchVolumeView verify response: err(%v)", err)
 		return nil, err
 	}
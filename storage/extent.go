@@ -70,6 +70,9 @@ type Extent struct {
 	dataSize   int64
 	hasClose   int32
 	header     []byte
+	// compressions holds the codec and the stored size of each block, see extent_compress.go
+	compressions  []byte
+	compressMutex sync.RWMutex
 	sync.Mutex
 }
 
@@ -259,6 +262,11 @@ func (e *Extent) Read(data []byte, offset, size int64, isRepairRead bool) (crc u
 	if err = e.checkOffsetAndSize(offset, size); err != nil {
 		return
 	}
+	e.compressMutex.RLock()
+	defer e.compressMutex.RUnlock()
+	if e.hasCompressedBlock(offset, size) {
+		return e.readCompressed(data, offset, size)
+	}
 	if _, err = e.file.ReadAt(data[:size], offset); err != nil {
 		return
 	}
@@ -304,6 +312,8 @@ func (e *Extent) autoComputeExtentCrc(crcFunc UpdateCrcFunc) (crc uint32, err er
 		blockCnt += 1
 	}
 	crcData := make([]byte, blockCnt*util.PerBlockCrcSize)
+	e.compressMutex.RLock()
+	defer e.compressMutex.RUnlock()
 	for blockNo := 0; blockNo < blockCnt; blockNo++ {
 		blockCrc := binary.BigEndian.Uint32(e.header[blockNo*util.PerBlockCrcSize : (blockNo+1)*util.PerBlockCrcSize])
 		if blockCrc != 0 {
@@ -311,8 +321,7 @@ func (e *Extent) autoComputeExtentCrc(crcFunc UpdateCrcFunc) (crc uint32, err er
 			continue
 		}
 		bdata := make([]byte, util.BlockSize)
-		offset := int64(blockNo * util.BlockSize)
-		readN, err := e.file.ReadAt(bdata[:util.BlockSize], offset)
+		readN, err := e.readBlock(blockNo, bdata)
 		if readN == 0 && err != nil {
 			break
 		}
@@ -0,0 +1,226 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/compress"
+)
+
+// The blocks of a normal extent can be compressed. A block is always stored at its own offset in the extent file
+// so that it can be read randomly: a compressed block is stored at the beginning of the space of the block and the
+// rest of the space is punched, while the size of the extent file is kept as the size of the raw data.
+// The codec and the stored size of each block are kept in the compression header, which is persisted in the same
+// layout as the crc header, and a zero entry means the block is stored raw.
+// The crc of a block is always computed on the raw data.
+
+type UpdateCompressionFunc func(e *Extent, blockNo int, compression uint32) (err error)
+
+const (
+	compressionCodecShift = 24
+	compressionSizeMask   = 1<<compressionCodecShift - 1
+)
+
+func (e *Extent) blockCompression(blockNo int) (codec compress.Codec, size int) {
+	if e.compressions == nil {
+		return
+	}
+	value := binary.BigEndian.Uint32(e.compressions[blockNo*util.PerBlockCrcSize : (blockNo+1)*util.PerBlockCrcSize])
+	return compress.Codec(value >> compressionCodecShift), int(value & compressionSizeMask)
+}
+
+func (e *Extent) hasCompressedBlock(offset, size int64) bool {
+	if e.compressions == nil {
+		return false
+	}
+	for blockNo := offset / util.BlockSize; blockNo*util.BlockSize < offset+size; blockNo++ {
+		if codec, _ := e.blockCompression(int(blockNo)); codec != compress.None {
+			return true
+		}
+	}
+	return false
+}
+
+// readBlock reads the raw data of the block into buf, and returns the size of the raw data.
+func (e *Extent) readBlock(blockNo int, buf []byte) (n int, err error) {
+	blockOffset := int64(blockNo) * util.BlockSize
+	n = int(math.Min(float64(util.BlockSize), float64(e.dataSize-blockOffset)))
+	if n <= 0 {
+		return 0, nil
+	}
+	codec, size := e.blockCompression(blockNo)
+	if codec == compress.None {
+		return e.file.ReadAt(buf[:n], blockOffset)
+	}
+	compressed := make([]byte, size)
+	if _, err = e.file.ReadAt(compressed, blockOffset); err != nil {
+		return 0, err
+	}
+	raw, err := codec.Decode(buf[:0], compressed)
+	if err != nil {
+		return 0, fmt.Errorf("decode block %v of extent %v: %v", blockNo, e.extentID, err)
+	}
+	if len(raw) != n {
+		return 0, fmt.Errorf("decode block %v of extent %v: size %v mismatch %v", blockNo, e.extentID, len(raw), n)
+	}
+	return n, nil
+}
+
+func (e *Extent) readCompressed(data []byte, offset, size int64) (crc uint32, err error) {
+	block := make([]byte, util.BlockSize)
+	end := offset + size
+	for blockNo := offset / util.BlockSize; blockNo*util.BlockSize < end; blockNo++ {
+		blockOffset := blockNo * util.BlockSize
+		var n int
+		if n, err = e.readBlock(int(blockNo), block); err != nil {
+			return
+		}
+		from := int64(math.Max(float64(offset), float64(blockOffset)))
+		to := int64(math.Min(float64(end), float64(blockOffset+int64(n))))
+		if to < end && to < blockOffset+util.BlockSize {
+			err = fmt.Errorf("read extent %v offset %v size %v beyond %v", e.extentID, offset, size, e.dataSize)
+			return
+		}
+		copy(data[from-offset:to-offset], block[from-blockOffset:to-blockOffset])
+	}
+	crc = crc32.ChecksumIEEE(data[:size])
+	return
+}
+
+// WriteCompressed writes data to an extent whose blocks are compressed by the codec. The blocks covered by the
+// data are read, merged with the data and stored again, so it works for both the append and the random writes.
+// The blocks are persisted in the journal before they are stored, and the extent and the header files are always
+// synced before the write returns, see extent_compress_journal.go.
+func (e *Extent) WriteCompressed(data []byte, offset, size int64, writeType int, codec compress.Codec,
+	journal *compressJournal, crcFunc UpdateCrcFunc, compressionFunc UpdateCompressionFunc) (err error) {
+	if err = e.checkOffsetAndSize(offset, size); err != nil {
+		return
+	}
+	e.compressMutex.Lock()
+	defer e.compressMutex.Unlock()
+
+	end := offset + size
+	blocks := make([]*compressedBlock, 0, 2)
+	for blockNo := offset / util.BlockSize; blockNo*util.BlockSize < end; blockNo++ {
+		blockOffset := blockNo * util.BlockSize
+		block := make([]byte, util.BlockSize)
+		var n int
+		if n, err = e.readBlock(int(blockNo), block); err != nil {
+			return
+		}
+		from := int64(math.Max(float64(offset), float64(blockOffset)))
+		to := int64(math.Min(float64(end), float64(blockOffset+util.BlockSize)))
+		copy(block[from-blockOffset:], data[from-offset:to-offset])
+		if int(to-blockOffset) > n {
+			n = int(to - blockOffset)
+		}
+		blocks = append(blocks, e.compressBlock(int(blockNo), block[:n], codec))
+	}
+
+	journal.Lock()
+	defer journal.Unlock()
+	if err = journal.commit(blocks); err != nil {
+		return
+	}
+	for _, b := range blocks {
+		if err = storeBlock(e.file, b); err != nil {
+			return
+		}
+		if err = crcFunc(e, b.blockNo, b.crc); err != nil {
+			return
+		}
+		if err = compressionFunc(e, b.blockNo, b.compression); err != nil {
+			return
+		}
+	}
+	if end > e.dataSize {
+		if err = e.file.Truncate(end); err != nil {
+			return
+		}
+	}
+	if err = journal.complete(e.file); err != nil {
+		return
+	}
+	if IsAppendWrite(writeType) {
+		atomic.StoreInt64(&e.modifyTime, time.Now().Unix())
+		e.dataSize = int64(math.Max(float64(e.dataSize), float64(end)))
+	}
+	return
+}
+
+// compressBlock prepares the raw data of the block to be stored. The block is stored compressed only if it saves
+// at least one page, otherwise reading it raw is cheaper.
+func (e *Extent) compressBlock(blockNo int, raw []byte, codec compress.Codec) (b *compressedBlock) {
+	b = &compressedBlock{extentID: e.extentID, blockNo: blockNo, rawSize: len(raw), stored: raw}
+	if codec != compress.None {
+		if compressed := codec.Encode(nil, raw); roundUpToPage(len(compressed)) < len(raw) {
+			b.stored, b.compression = compressed, uint32(codec)<<compressionCodecShift|uint32(len(compressed))
+		}
+	}
+	if len(raw) == util.BlockSize {
+		b.crc = crc32.ChecksumIEEE(raw)
+	}
+	return
+}
+
+// storeBlock stores the block at its own offset in the extent file, and punches the space it does not use.
+func storeBlock(file *os.File, b *compressedBlock) (err error) {
+	blockOffset := int64(b.blockNo) * util.BlockSize
+	if _, err = file.WriteAt(b.stored, blockOffset); err != nil {
+		return
+	}
+	if holeOffset := roundUpToPage(len(b.stored)); holeOffset < b.rawSize {
+		err = fallocate(int(file.Fd()), FallocFLPunchHole|FallocFLKeepSize, blockOffset+int64(holeOffset),
+			int64(b.rawSize-holeOffset))
+	}
+	return
+}
+
+func roundUpToPage(size int) int {
+	return (size + PageSize - 1) / PageSize * PageSize
+}
+
+// SetCompression sets the codec to compress the blocks written afterwards, the blocks written before are
+// read as they are stored.
+func (s *ExtentStore) SetCompression(codec compress.Codec) {
+	atomic.StoreUint32(&s.compression, uint32(codec))
+}
+
+// Compression returns the codec to compress the blocks.
+func (s *ExtentStore) Compression() compress.Codec {
+	return compress.Codec(atomic.LoadUint32(&s.compression))
+}
+
+func (s *ExtentStore) PersistenceBlockCompression(e *Extent, blockNo int, compression uint32) (err error) {
+	startIdx := blockNo * util.PerBlockCrcSize
+	endIdx := startIdx + util.PerBlockCrcSize
+	binary.BigEndian.PutUint32(e.compressions[startIdx:endIdx], compression)
+	_, err = s.compressExtentFp.WriteAt(e.compressions[startIdx:endIdx], int64(startIdx)+int64(util.BlockHeaderSize*e.extentID))
+	return
+}
+
+func (s *ExtentStore) DeleteBlockCompression(extentID uint64) (err error) {
+	err = fallocate(int(s.compressExtentFp.Fd()), FallocFLPunchHole|FallocFLKeepSize,
+		int64(util.BlockHeaderSize*extentID), util.BlockHeaderSize)
+	return
+}
@@ -0,0 +1,228 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/log"
+)
+
+// A compressed block is rewritten in place, and its crc and compression entries are updated in the header files,
+// which can not be done atomically. So the blocks of a compressed write are persisted in the journal first, then
+// stored into the extent file and the header files, which are synced before the journal is cleared. The journal
+// is replayed when the extent store is opened, so the blocks of a write interrupted by a crash are either stored
+// completely or not at all, and the write is not acknowledged in both cases.
+// The compressed writes of an extent store are serialized by its journal.
+//
+// The layout of the journal is the crc and the size of the records followed by the records, and the layout of a
+// record is the extent ID, the block number, the compression entry, the crc entry, the raw size, the stored size
+// and the stored data of the block.
+
+const (
+	ExtCompressJournalFileName = "EXTENT_COMPRESS_JOURNAL"
+
+	compressJournalHeaderSize = 8
+	compressRecordHeaderSize  = 28
+)
+
+var ErrCompressJournalPending = errors.New("compress journal: the last write is not completed")
+
+// compressedBlock is a block to be stored by a compressed write.
+type compressedBlock struct {
+	extentID    uint64
+	blockNo     int
+	compression uint32 // entry of the compression header
+	crc         uint32 // entry of the crc header
+	rawSize     int
+	stored      []byte
+}
+
+type compressJournal struct {
+	sync.Mutex
+	store   *ExtentStore
+	file    *os.File
+	pending bool // the blocks in the journal are not completely stored
+}
+
+func openCompressJournal(s *ExtentStore) (j *compressJournal, err error) {
+	j = &compressJournal{store: s}
+	if j.file, err = os.OpenFile(path.Join(s.dataPath, ExtCompressJournalFileName), os.O_CREATE|os.O_RDWR, 0666); err != nil {
+		return
+	}
+	if err = j.replay(); err != nil {
+		j.file.Close()
+		return nil, err
+	}
+	return
+}
+
+// commit persists the blocks in the journal before they are stored.
+func (j *compressJournal) commit(blocks []*compressedBlock) (err error) {
+	if j.pending {
+		return ErrCompressJournalPending
+	}
+	size := compressJournalHeaderSize
+	for _, b := range blocks {
+		size += compressRecordHeaderSize + len(b.stored)
+	}
+	buf := make([]byte, size)
+	off := compressJournalHeaderSize
+	for _, b := range blocks {
+		binary.BigEndian.PutUint64(buf[off:], b.extentID)
+		binary.BigEndian.PutUint32(buf[off+8:], uint32(b.blockNo))
+		binary.BigEndian.PutUint32(buf[off+12:], b.compression)
+		binary.BigEndian.PutUint32(buf[off+16:], b.crc)
+		binary.BigEndian.PutUint32(buf[off+20:], uint32(b.rawSize))
+		binary.BigEndian.PutUint32(buf[off+24:], uint32(len(b.stored)))
+		off += compressRecordHeaderSize
+		off += copy(buf[off:], b.stored)
+	}
+	binary.BigEndian.PutUint32(buf[0:], crc32.ChecksumIEEE(buf[compressJournalHeaderSize:]))
+	binary.BigEndian.PutUint32(buf[4:], uint32(size-compressJournalHeaderSize))
+
+	j.pending = true
+	if _, err = j.file.WriteAt(buf, 0); err != nil {
+		return
+	}
+	if err = j.file.Truncate(int64(size)); err != nil {
+		return
+	}
+	return j.file.Sync()
+}
+
+// complete syncs the extent file and the header files after the blocks are stored, then clears the journal.
+func (j *compressJournal) complete(extentFile *os.File) (err error) {
+	if err = extentFile.Sync(); err != nil {
+		return
+	}
+	if err = j.store.verifyExtentFp.Sync(); err != nil {
+		return
+	}
+	if err = j.store.compressExtentFp.Sync(); err != nil {
+		return
+	}
+	if err = j.file.Truncate(0); err != nil {
+		return
+	}
+	j.pending = false
+	return
+}
+
+// replay stores the blocks of the journal again. The journal which is not completely persisted is discarded,
+// since none of its blocks has been stored.
+func (j *compressJournal) replay() (err error) {
+	data, err := ioutil.ReadAll(j.file)
+	if err != nil {
+		return
+	}
+	if len(data) < compressJournalHeaderSize {
+		return j.file.Truncate(0)
+	}
+	size := int(binary.BigEndian.Uint32(data[4:]))
+	if size != len(data)-compressJournalHeaderSize ||
+		binary.BigEndian.Uint32(data[0:]) != crc32.ChecksumIEEE(data[compressJournalHeaderSize:]) {
+		log.LogWarnf("compress journal of %v is incomplete, size(%v) discarded", j.store.dataPath, len(data))
+		return j.file.Truncate(0)
+	}
+	var blocks []*compressedBlock
+	if blocks, err = decodeCompressedBlocks(data[compressJournalHeaderSize:]); err != nil {
+		return
+	}
+	for _, b := range blocks {
+		if err = j.store.restoreCompressedBlock(b); err != nil {
+			return fmt.Errorf("replay compress journal of %v: extent(%v) block(%v) err(%v)",
+				j.store.dataPath, b.extentID, b.blockNo, err)
+		}
+		log.LogInfof("replay compress journal of %v: extent(%v) block(%v) stored", j.store.dataPath, b.extentID, b.blockNo)
+	}
+	if err = j.store.verifyExtentFp.Sync(); err != nil {
+		return
+	}
+	if err = j.store.compressExtentFp.Sync(); err != nil {
+		return
+	}
+	return j.file.Truncate(0)
+}
+
+func decodeCompressedBlocks(data []byte) (blocks []*compressedBlock, err error) {
+	for off := 0; off < len(data); {
+		if off+compressRecordHeaderSize > len(data) {
+			return nil, fmt.Errorf("compress journal: record header at %v is truncated", off)
+		}
+		b := &compressedBlock{
+			extentID:    binary.BigEndian.Uint64(data[off:]),
+			blockNo:     int(binary.BigEndian.Uint32(data[off+8:])),
+			compression: binary.BigEndian.Uint32(data[off+12:]),
+			crc:         binary.BigEndian.Uint32(data[off+16:]),
+			rawSize:     int(binary.BigEndian.Uint32(data[off+20:])),
+		}
+		storedSize := int(binary.BigEndian.Uint32(data[off+24:]))
+		off += compressRecordHeaderSize
+		if off+storedSize > len(data) || storedSize > util.BlockSize || b.rawSize > util.BlockSize {
+			return nil, fmt.Errorf("compress journal: record of extent %v block %v is corrupt", b.extentID, b.blockNo)
+		}
+		b.stored = data[off : off+storedSize]
+		off += storedSize
+		blocks = append(blocks, b)
+	}
+	return
+}
+
+// restoreCompressedBlock stores a block of the journal into the extent file and the header files,
+// the block of a deleted extent is skipped.
+func (s *ExtentStore) restoreCompressedBlock(b *compressedBlock) (err error) {
+	file, err := os.OpenFile(path.Join(s.dataPath, strconv.FormatUint(b.extentID, 10)), os.O_RDWR, 0666)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if err = storeBlock(file, b); err != nil {
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	if end := int64(b.blockNo)*util.BlockSize + int64(b.rawSize); info.Size() < end {
+		if err = file.Truncate(end); err != nil {
+			return
+		}
+	}
+	if err = file.Sync(); err != nil {
+		return
+	}
+	entry := make([]byte, util.PerBlockCrcSize)
+	headerOffset := int64(util.BlockHeaderSize*b.extentID) + int64(b.blockNo*util.PerBlockCrcSize)
+	binary.BigEndian.PutUint32(entry, b.crc)
+	if _, err = s.verifyExtentFp.WriteAt(entry, headerOffset); err != nil {
+		return
+	}
+	binary.BigEndian.PutUint32(entry, b.compression)
+	_, err = s.compressExtentFp.WriteAt(entry, headerOffset)
+	return
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/compress"
+)
+
+func TestExtentStore_CompressedWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extent_compress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewExtentStore(dir, 1, util.GB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	const extentID = MinExtentID + 1
+	if err = store.Create(extentID); err != nil {
+		t.Fatal(err)
+	}
+	text := []byte(strings.Repeat("2020-06-01 12:00:00,host-1,GET,/chubaofs/volume,200,1024\n", 10000))
+	expect := text[:3*util.BlockSize+1000]
+
+	// append the first block raw, then the rest compressed in packets not aligned to the blocks
+	if err = store.Write(extentID, 0, util.BlockSize, expect[:util.BlockSize], crc32.ChecksumIEEE(expect[:util.BlockSize]), AppendWriteType, false); err != nil {
+		t.Fatal(err)
+	}
+	store.SetCompression(compress.LZ4)
+	for offset := util.BlockSize; offset < len(expect); {
+		size := util.Min(rand.Intn(util.BlockSize)+1, len(expect)-offset)
+		if err = store.Write(extentID, int64(offset), int64(size), expect[offset:offset+size], 0, AppendWriteType, false); err != nil {
+			t.Fatal(err)
+		}
+		offset += size
+	}
+	// overwrite a compressed block with incompressible data, with the compression disabled
+	store.SetCompression(compress.None)
+	random := make([]byte, 5000)
+	rand.Read(random)
+	offset := 2*util.BlockSize + 1000
+	copy(expect[offset:], random)
+	if err = store.Write(extentID, int64(offset), int64(len(random)), random, 0, RandomWriteType, false); err != nil {
+		t.Fatal(err)
+	}
+
+	ei, err := store.Watermark(extentID)
+	if err != nil || ei.Size != uint64(len(expect)) {
+		t.Fatalf("watermark %v err %v, expect size %v", ei, err, len(expect))
+	}
+	for _, c := range []struct{ offset, size int }{{0, 4096}, {100, util.BlockSize}, {util.BlockSize - 10, 20},
+		{2*util.BlockSize - 3000, 7000}, {3 * util.BlockSize, 1000}} {
+		data := make([]byte, c.size)
+		crc, err := store.Read(extentID, int64(c.offset), int64(c.size), data, false)
+		if err != nil {
+			t.Fatalf("read offset %v size %v: %v", c.offset, c.size, err)
+		}
+		if !bytes.Equal(data, expect[c.offset:c.offset+c.size]) || crc != crc32.ChecksumIEEE(data) {
+			t.Fatalf("read offset %v size %v: data mismatch", c.offset, c.size)
+		}
+	}
+	e, _ := store.extentWithHeader(ei)
+	if codec, _ := e.blockCompression(1); codec != compress.LZ4 {
+		t.Fatalf("block 1 is stored as %v", codec)
+	}
+	for _, blockNo := range []int{0, 2, 3} {
+		if codec, _ := e.blockCompression(blockNo); codec != compress.None {
+			t.Fatalf("block %v is stored as %v", blockNo, codec)
+		}
+	}
+	if e.getRealBlockCnt()*512 >= int64(len(expect)) {
+		t.Fatalf("extent is not compressed, allocated %v", e.getRealBlockCnt()*512)
+	}
+
+	// reload the extent from the disk
+	store.cache.Del(extentID)
+	data := make([]byte, util.BlockSize)
+	if _, err = store.Read(extentID, util.BlockSize, util.BlockSize, data, false); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, expect[util.BlockSize:2*util.BlockSize]) {
+		t.Fatalf("reloaded block mismatch")
+	}
+}
+
+func TestExtentStore_CompressJournalReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extent_compress_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewExtentStore(dir, 1, util.GB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const extentID = MinExtentID + 1
+	if err = store.Create(extentID); err != nil {
+		t.Fatal(err)
+	}
+	store.SetCompression(compress.LZ4)
+	text := []byte(strings.Repeat("2020-06-01 12:00:00,host-1,GET,/chubaofs/volume,200,1024\n", 5000))[:2*util.BlockSize]
+	for offset := 0; offset < len(text); offset += util.BlockSize {
+		if err = store.Write(extentID, int64(offset), util.BlockSize, text[offset:offset+util.BlockSize], 0, AppendWriteType, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ei, _ := store.Watermark(extentID)
+	e, _ := store.extentWithHeader(ei)
+
+	// the datanode crashes after the journal is persisted, while block 1 is partially rewritten
+	expect := append([]byte(nil), text...)
+	copy(expect[util.BlockSize:], strings.Repeat("2020-06-02 08:30:00,host-2,PUT,/chubaofs/bucket,200,4096\n", 3000))
+	block := e.compressBlock(1, expect[util.BlockSize:], compress.LZ4)
+	if err = store.compressJournal.commit([]*compressedBlock{block}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.file.WriteAt(expect[util.BlockSize:util.BlockSize+5000], util.BlockSize); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	if store, err = NewExtentStore(dir, 1, util.GB); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, util.BlockSize)
+	for offset := 0; offset < len(expect); offset += util.BlockSize {
+		if _, err = store.Read(extentID, int64(offset), util.BlockSize, data, false); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expect[offset:offset+util.BlockSize]) {
+			t.Fatalf("replayed block at %v mismatch", offset)
+		}
+	}
+	ei, _ = store.Watermark(extentID)
+	e, _ = store.extentWithHeader(ei)
+	if crc := binary.BigEndian.Uint32(e.header[util.PerBlockCrcSize:]); crc != crc32.ChecksumIEEE(expect[util.BlockSize:]) {
+		t.Fatalf("replayed block crc mismatch")
+	}
+	if info, _ := store.compressJournal.file.Stat(); info.Size() != 0 {
+		t.Fatalf("journal is not cleared, size %v", info.Size())
+	}
+
+	// the journal which is not completely persisted is discarded
+	block = e.compressBlock(0, make([]byte, util.BlockSize), compress.LZ4)
+	if err = store.compressJournal.commit([]*compressedBlock{block}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := store.compressJournal.file.Stat()
+	if err = store.compressJournal.file.Truncate(info.Size() - 1); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	if store, err = NewExtentStore(dir, 1, util.GB); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if _, err = store.Read(extentID, 0, util.BlockSize, data, false); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, expect[:util.BlockSize]) {
+		t.Fatalf("discarded journal is stored")
+	}
+}
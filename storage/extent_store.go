@@ -36,11 +36,13 @@ import (
 
 	"github.com/chubaofs/chubaofs/proto"
 	"github.com/chubaofs/chubaofs/util"
+	"github.com/chubaofs/chubaofs/util/compress"
 	"github.com/chubaofs/chubaofs/util/log"
 )
 
 const (
 	ExtCrcHeaderFileName         = "EXTENT_CRC"
+	ExtCompressHeaderFileName    = "EXTENT_COMPRESS"
 	ExtBaseExtentIDFileName      = "EXTENT_META"
 	TinyDeleteFileOpt            = os.O_CREATE | os.O_RDWR | os.O_APPEND
 	TinyExtDeletedFileName       = "TINYEXTENT_DELETE"
@@ -125,6 +127,9 @@ type ExtentStore struct {
 	blockSize                         int
 	partitionID                       uint64
 	verifyExtentFp                    *os.File
+	compressExtentFp                  *os.File
+	compressJournal                   *compressJournal
+	compression                       uint32 // codec to compress the blocks of the normal extents
 	hasAllocSpaceExtentIDOnVerfiyFile uint64
 	hasDeleteNormalExtentsCache       sync.Map
 }
@@ -155,6 +160,12 @@ func NewExtentStore(dataDir string, partitionID uint64, storeSize int) (s *Exten
 	if s.verifyExtentFp, err = os.OpenFile(path.Join(s.dataPath, ExtCrcHeaderFileName), os.O_CREATE|os.O_RDWR, 0666); err != nil {
 		return
 	}
+	if s.compressExtentFp, err = os.OpenFile(path.Join(s.dataPath, ExtCompressHeaderFileName), os.O_CREATE|os.O_RDWR, 0666); err != nil {
+		return
+	}
+	if s.compressJournal, err = openCompressJournal(s); err != nil {
+		return
+	}
 	if s.metadataFp, err = os.OpenFile(path.Join(s.dataPath, ExtBaseExtentIDFileName), os.O_CREATE|os.O_RDWR, 0666); err != nil {
 		return
 	}
@@ -235,6 +246,7 @@ func (s *ExtentStore) Create(extentID uint64) (err error) {
 	}
 	e = NewExtentInCore(name, extentID)
 	e.header = make([]byte, util.BlockHeaderSize)
+	e.compressions = make([]byte, util.BlockHeaderSize)
 	err = e.InitToFS()
 	if err != nil {
 		return err
@@ -310,7 +322,11 @@ func (s *ExtentStore) Write(extentID uint64, offset, size int64, data []byte, cr
 	if err = s.checkOffsetAndSize(extentID, offset, size); err != nil {
 		return err
 	}
-	err = e.Write(data, offset, size, crc, writeType, isSync, s.PersistenceBlockCrc, ei)
+	if codec := s.Compression(); !IsTinyExtent(extentID) && (codec != compress.None || e.hasCompressedBlock(offset, size)) {
+		err = e.WriteCompressed(data, offset, size, writeType, codec, s.compressJournal, s.PersistenceBlockCrc, s.PersistenceBlockCompression)
+	} else {
+		err = e.Write(data, offset, size, crc, writeType, isSync, s.PersistenceBlockCrc, ei)
+	}
 	if err != nil {
 		return err
 	}
@@ -406,6 +422,7 @@ func (s *ExtentStore) MarkDelete(extentID uint64, offset, size int64) (err error
 	ei.ModifyTime = time.Now().Unix()
 	s.cache.Del(extentID)
 	s.DeleteBlockCrc(extentID)
+	s.DeleteBlockCompression(extentID)
 	s.PutNormalExtentToDeleteCache(extentID)
 
 	s.eiMutex.Lock()
@@ -441,6 +458,9 @@ func (s *ExtentStore) Close() {
 	s.normalExtentDeleteFp.Close()
 	s.verifyExtentFp.Sync()
 	s.verifyExtentFp.Close()
+	s.compressExtentFp.Sync()
+	s.compressExtentFp.Close()
+	s.compressJournal.file.Close()
 	s.closed = true
 }
 
@@ -828,6 +848,10 @@ func (s *ExtentStore) loadExtentFromDisk(extentID uint64, putCache bool) (e *Ext
 		if _, err = s.verifyExtentFp.ReadAt(e.header, int64(extentID*util.BlockHeaderSize)); err != nil && err != io.EOF {
 			return
 		}
+		e.compressions = make([]byte, util.BlockHeaderSize)
+		if _, err = s.compressExtentFp.ReadAt(e.compressions, int64(extentID*util.BlockHeaderSize)); err != nil && err != io.EOF {
+			return
+		}
 	}
 	err = nil
 	s.cache.Put(e)
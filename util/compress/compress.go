@@ -0,0 +1,111 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package compress implements the block codecs used to compress the data blocks of the extents.
+// The codecs are self-contained implementations of the snappy and lz4 block formats,
+// so that a block compressed by any data node can be decompressed by the others.
+// The zstd format is not supported, since there is no implementation of its encoder in the tree.
+package compress
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chubaofs/chubaofs/util"
+)
+
+// Codec identifies the algorithm of a compressed block.
+type Codec uint8
+
+const (
+	None Codec = iota
+	Snappy
+	LZ4
+)
+
+// MaxDecodedSize is the limit of the size of a decoded block, which is a block of an extent.
+const MaxDecodedSize = util.BlockSize
+
+var (
+	ErrCorrupt = errors.New("compress: corrupt input")
+)
+
+// ParseCodec returns the codec of the given name, an empty name means no compression.
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case "", "none":
+		return None, nil
+	case "snappy":
+		return Snappy, nil
+	case "lz4":
+		return LZ4, nil
+	case "zstd":
+		return None, fmt.Errorf("compression codec %v is not supported yet", name)
+	default:
+		return None, fmt.Errorf("unsupported compression codec %v", name)
+	}
+}
+
+// String returns the name of the codec.
+func (c Codec) String() string {
+	switch c {
+	case None:
+		return "none"
+	case Snappy:
+		return "snappy"
+	case LZ4:
+		return "lz4"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// Encode compresses src and returns the result in dst, which is reallocated if it is too small.
+func (c Codec) Encode(dst, src []byte) []byte {
+	switch c {
+	case Snappy:
+		return snappyEncode(dst[:0], src)
+	case LZ4:
+		return lz4Encode(dst[:0], src)
+	default:
+		return append(dst[:0], src...)
+	}
+}
+
+// Decode decompresses src and returns the result in dst, which is reallocated if it is too small.
+func (c Codec) Decode(dst, src []byte) ([]byte, error) {
+	switch c {
+	case Snappy:
+		return snappyDecode(dst[:0], src)
+	case LZ4:
+		return lz4Decode(dst[:0], src)
+	case None:
+		return append(dst[:0], src...), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %v", c)
+	}
+}
+
+const (
+	hashBits = 14
+	minMatch = 4
+)
+
+func load32(b []byte, i int) uint32 {
+	return uint32(b[i]) | uint32(b[i+1])<<8 | uint32(b[i+2])<<16 | uint32(b[i+3])<<24
+}
+
+func hash4(v uint32) uint32 {
+	return (v * 2654435761) >> (32 - hashBits)
+}
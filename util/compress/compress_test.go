@@ -0,0 +1,99 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package compress
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func newTestBlocks() [][]byte {
+	random := make([]byte, 128*1024)
+	rand.Read(random)
+	text := []byte(strings.Repeat("2020-06-01 12:00:00,host-1,GET,/chubaofs/volume,200,1024\n", 3000))[:128*1024]
+	mixed := append(append([]byte(nil), text[:60000]...), random[:30000]...)
+	mixed = append(mixed, text[:38000]...)
+	return [][]byte{{}, []byte("a"), []byte("abcdefghijklmnop"), random, text, mixed, make([]byte, 128*1024)}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	for _, codec := range []Codec{Snappy, LZ4} {
+		for i, block := range newTestBlocks() {
+			encoded := codec.Encode(nil, block)
+			decoded, err := codec.Decode(make([]byte, 0, len(block)), encoded)
+			if err != nil {
+				t.Fatalf("%v: decode block %v: %v", codec, i, err)
+			}
+			if !bytes.Equal(decoded, block) {
+				t.Fatalf("%v: block %v of size %v is not decoded", codec, i, len(block))
+			}
+		}
+		text := newTestBlocks()[4]
+		if encoded := codec.Encode(nil, text); len(encoded) > len(text)/5 {
+			t.Fatalf("%v: text of size %v is compressed to %v", codec, len(text), len(encoded))
+		}
+	}
+}
+
+func TestCodec_Corrupt(t *testing.T) {
+	for _, codec := range []Codec{Snappy, LZ4} {
+		encoded := codec.Encode(nil, newTestBlocks()[5])
+		for round := 0; round < 1000; round++ {
+			corrupt := append([]byte(nil), encoded[:rand.Intn(len(encoded))]...)
+			if len(corrupt) > 0 {
+				corrupt[rand.Intn(len(corrupt))] ^= byte(rand.Intn(255) + 1)
+			}
+			// decoding corrupt data must not panic
+			codec.Decode(nil, corrupt)
+		}
+	}
+}
+
+func TestCodec_DecodedSizeLimit(t *testing.T) {
+	// the length prefix of snappy claims a huge block, which must be rejected before it is allocated
+	prefix := make([]byte, binary.MaxVarintLen64)
+	encoded := append(prefix[:binary.PutUvarint(prefix, 1<<30)], 0)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := Snappy.Decode(nil, encoded)
+	runtime.ReadMemStats(&after)
+	if err != ErrCorrupt || after.TotalAlloc-before.TotalAlloc > MaxDecodedSize {
+		t.Fatalf("snappy: decode oversize block: err(%v) allocated(%v)", err, after.TotalAlloc-before.TotalAlloc)
+	}
+	// a lz4 block of a literal and a match repeated beyond the limit
+	encoded = []byte{0x1f, 'a', 1, 0}
+	for n := MaxDecodedSize; n >= 0; n -= 255 {
+		encoded = append(encoded, 255)
+	}
+	encoded = append(encoded, 0, 0x10, 'a')
+	if _, err := LZ4.Decode(nil, encoded); err != ErrCorrupt {
+		t.Fatalf("lz4: decode oversize block: %v", err)
+	}
+}
+
+func TestParseCodec(t *testing.T) {
+	for _, name := range []string{"", "none", "snappy", "lz4"} {
+		if _, err := ParseCodec(name); err != nil {
+			t.Fatalf("parse %v: %v", name, err)
+		}
+	}
+	if _, err := ParseCodec("zstd"); err == nil {
+		t.Fatalf("expect error for zstd")
+	}
+}
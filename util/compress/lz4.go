@@ -0,0 +1,151 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package compress
+
+const (
+	// The last literals of a block can not be covered by a match,
+	// and the last match must start at least lz4MFLimit bytes before the end of the block.
+	lz4LastLiterals = 5
+	lz4MFLimit      = 12
+	lz4MaxOffset    = 1<<16 - 1
+)
+
+// lz4Encode appends the lz4 block encoding of src to dst, which is a series of sequences made up of
+// a token, the literals, the offset and the length of the match.
+func lz4Encode(dst, src []byte) []byte {
+	var table [1 << hashBits]int32
+	lit := 0
+	for i := 0; i < len(src)-lz4MFLimit; {
+		v := load32(src, i)
+		h := hash4(v)
+		candidate := int(table[h])
+		table[h] = int32(i)
+		if candidate >= i || i-candidate > lz4MaxOffset || load32(src, candidate) != v {
+			i++
+			continue
+		}
+		length := minMatch
+		for i+length < len(src)-lz4LastLiterals && src[candidate+length] == src[i+length] {
+			length++
+		}
+		dst = lz4EmitSequence(dst, src[lit:i], i-candidate, length)
+		i += length
+		lit = i
+	}
+	return lz4EmitLiterals(dst, src[lit:])
+}
+
+func lz4EmitSequence(dst, literal []byte, offset, length int) []byte {
+	litLen, matchLen := len(literal), length-minMatch
+	token := byte(min(litLen, 15))<<4 | byte(min(matchLen, 15))
+	dst = append(dst, token)
+	if litLen >= 15 {
+		dst = lz4EmitLength(dst, litLen-15)
+	}
+	dst = append(dst, literal...)
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if matchLen >= 15 {
+		dst = lz4EmitLength(dst, matchLen-15)
+	}
+	return dst
+}
+
+func lz4EmitLiterals(dst, literal []byte) []byte {
+	litLen := len(literal)
+	dst = append(dst, byte(min(litLen, 15))<<4)
+	if litLen >= 15 {
+		dst = lz4EmitLength(dst, litLen-15)
+	}
+	return append(dst, literal...)
+}
+
+func lz4EmitLength(dst []byte, n int) []byte {
+	for ; n >= 0xff; n -= 0xff {
+		dst = append(dst, 0xff)
+	}
+	return append(dst, byte(n))
+}
+
+func lz4DecodeLength(src []byte, s int) (n, next int, err error) {
+	for {
+		if s >= len(src) {
+			return 0, 0, ErrCorrupt
+		}
+		b := src[s]
+		s++
+		n += int(b)
+		if b != 0xff {
+			return n, s, nil
+		}
+	}
+}
+
+func lz4Decode(dst, src []byte) ([]byte, error) {
+	var err error
+	for s := 0; s < len(src); {
+		token := src[s]
+		s++
+		litLen := int(token >> 4)
+		if litLen == 15 {
+			var n int
+			if n, s, err = lz4DecodeLength(src, s); err != nil {
+				return nil, err
+			}
+			litLen += n
+		}
+		if litLen > len(src)-s || litLen > MaxDecodedSize-len(dst) {
+			return nil, ErrCorrupt
+		}
+		dst = append(dst, src[s:s+litLen]...)
+		s += litLen
+		if s == len(src) {
+			// the last sequence has no match
+			break
+		}
+
+		if s+2 > len(src) {
+			return nil, ErrCorrupt
+		}
+		offset := int(src[s]) | int(src[s+1])<<8
+		s += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, ErrCorrupt
+		}
+		matchLen := int(token & 0x0f)
+		if matchLen == 15 {
+			var n int
+			if n, s, err = lz4DecodeLength(src, s); err != nil {
+				return nil, err
+			}
+			matchLen += n
+		}
+		matchLen += minMatch
+		if matchLen > MaxDecodedSize-len(dst) {
+			return nil, ErrCorrupt
+		}
+		start := len(dst) - offset
+		for i := 0; i < matchLen; i++ {
+			dst = append(dst, dst[start+i])
+		}
+	}
+	return dst, nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
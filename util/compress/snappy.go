@@ -0,0 +1,183 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package compress
+
+import (
+	"encoding/binary"
+)
+
+// The tags of the elements in the snappy block format.
+const (
+	snappyTagLiteral = 0x00
+	snappyTagCopy1   = 0x01
+	snappyTagCopy2   = 0x02
+	snappyTagCopy4   = 0x03
+)
+
+// snappyEncode appends the snappy block encoding of src to dst, which is the uvarint length of src followed by
+// the literal and copy elements.
+func snappyEncode(dst, src []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(src)))
+	dst = append(dst, buf[:n]...)
+
+	var table [1 << hashBits]int32
+	lit := 0
+	for i := 0; i+minMatch <= len(src); {
+		v := load32(src, i)
+		h := hash4(v)
+		candidate := int(table[h])
+		table[h] = int32(i)
+		if candidate >= i || load32(src, candidate) != v {
+			i++
+			continue
+		}
+		length := minMatch
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+		dst = snappyEmitLiteral(dst, src[lit:i])
+		dst = snappyEmitCopy(dst, i-candidate, length)
+		i += length
+		lit = i
+	}
+	return snappyEmitLiteral(dst, src[lit:])
+}
+
+func snappyEmitLiteral(dst, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+	n := uint32(len(literal) - 1)
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2|snappyTagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|snappyTagLiteral, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|snappyTagLiteral, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
+
+func snappyEmitCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		l := length
+		if l > 64 {
+			l = 64
+		}
+		switch {
+		case l >= 4 && l <= 11 && offset < 1<<11:
+			dst = append(dst, byte(offset>>8)<<5|byte(l-4)<<2|snappyTagCopy1, byte(offset))
+		case offset < 1<<16:
+			dst = append(dst, byte(l-1)<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		default:
+			dst = append(dst, byte(l-1)<<2|snappyTagCopy4, byte(offset), byte(offset>>8), byte(offset>>16), byte(offset>>24))
+		}
+		length -= l
+	}
+	return dst
+}
+
+func snappyDecode(dst, src []byte) ([]byte, error) {
+	decodedLen, n := binary.Uvarint(src)
+	if n <= 0 || decodedLen > MaxDecodedSize {
+		return nil, ErrCorrupt
+	}
+	if uint64(cap(dst)) < decodedLen {
+		dst = make([]byte, decodedLen)
+	}
+	dst = dst[:decodedLen]
+
+	d, s := 0, n
+	for s < len(src) {
+		var offset, length int
+		switch src[s] & 0x03 {
+		case snappyTagLiteral:
+			x := uint32(src[s] >> 2)
+			switch {
+			case x < 60:
+				s++
+			case x == 60:
+				s += 2
+				if s > len(src) {
+					return nil, ErrCorrupt
+				}
+				x = uint32(src[s-1])
+			case x == 61:
+				s += 3
+				if s > len(src) {
+					return nil, ErrCorrupt
+				}
+				x = uint32(src[s-2]) | uint32(src[s-1])<<8
+			case x == 62:
+				s += 4
+				if s > len(src) {
+					return nil, ErrCorrupt
+				}
+				x = uint32(src[s-3]) | uint32(src[s-2])<<8 | uint32(src[s-1])<<16
+			default:
+				s += 5
+				if s > len(src) {
+					return nil, ErrCorrupt
+				}
+				x = uint32(src[s-4]) | uint32(src[s-3])<<8 | uint32(src[s-2])<<16 | uint32(src[s-1])<<24
+			}
+			length = int(x) + 1
+			if length <= 0 || length > len(dst)-d || length > len(src)-s {
+				return nil, ErrCorrupt
+			}
+			copy(dst[d:], src[s:s+length])
+			d += length
+			s += length
+			continue
+		case snappyTagCopy1:
+			s += 2
+			if s > len(src) {
+				return nil, ErrCorrupt
+			}
+			length = 4 + int(src[s-2])>>2&0x07
+			offset = int(src[s-2])&0xe0<<3 | int(src[s-1])
+		case snappyTagCopy2:
+			s += 3
+			if s > len(src) {
+				return nil, ErrCorrupt
+			}
+			length = 1 + int(src[s-3])>>2
+			offset = int(src[s-2]) | int(src[s-1])<<8
+		case snappyTagCopy4:
+			s += 5
+			if s > len(src) {
+				return nil, ErrCorrupt
+			}
+			length = 1 + int(src[s-5])>>2
+			offset = int(src[s-4]) | int(src[s-3])<<8 | int(src[s-2])<<16 | int(src[s-1])<<24
+		}
+		if offset <= 0 || offset > d || length > len(dst)-d {
+			return nil, ErrCorrupt
+		}
+		for end := d + length; d < end; d++ {
+			dst[d] = dst[d-offset]
+		}
+	}
+	if d != len(dst) {
+		return nil, ErrCorrupt
+	}
+	return dst, nil
+}
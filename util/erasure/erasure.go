@@ -0,0 +1,208 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package erasure implements a systematic Reed-Solomon code over GF(2^8).
+//
+// The first DataNum shards carry the data unchanged and the following ParityNum shards
+// are computed from them, so that the data can be recovered from any DataNum of the shards.
+package erasure
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidShardNum = errors.New("invalid number of data or parity shards")
+	ErrShardNum        = errors.New("wrong number of shards")
+	ErrShardSize       = errors.New("shards are of different size")
+	ErrTooFewShards    = errors.New("too few shards to reconstruct")
+)
+
+const (
+	// the total number of shards is limited by the size of the field
+	MaxShardNum = 256
+)
+
+// Encoder encodes and reconstructs the shards of a stripe.
+type Encoder struct {
+	dataNum   int
+	parityNum int
+	// parity[i][j] is the coefficient of the data shard j in the parity shard i
+	parity [][]byte
+}
+
+// NewEncoder returns a new encoder with the specified number of data and parity shards.
+func NewEncoder(dataNum, parityNum int) (e *Encoder, err error) {
+	if dataNum <= 0 || parityNum <= 0 || dataNum+parityNum > MaxShardNum {
+		return nil, ErrInvalidShardNum
+	}
+	e = &Encoder{
+		dataNum:   dataNum,
+		parityNum: parityNum,
+		parity:    make([][]byte, parityNum),
+	}
+	// Cauchy matrix, every square sub matrix of [I; C] is invertible.
+	for i := 0; i < parityNum; i++ {
+		e.parity[i] = make([]byte, dataNum)
+		for j := 0; j < dataNum; j++ {
+			e.parity[i][j] = galInverse(byte(dataNum+i) ^ byte(j))
+		}
+	}
+	return
+}
+
+// DataNum returns the number of data shards.
+func (e *Encoder) DataNum() int {
+	return e.dataNum
+}
+
+// ParityNum returns the number of parity shards.
+func (e *Encoder) ParityNum() int {
+	return e.parityNum
+}
+
+// Encode computes the parity shards from the data shards.
+// All the shards must be allocated with the same size.
+func (e *Encoder) Encode(shards [][]byte) (err error) {
+	if len(shards) != e.dataNum+e.parityNum {
+		return ErrShardNum
+	}
+	if _, err = checkShardSize(shards, false); err != nil {
+		return
+	}
+	for i := 0; i < e.parityNum; i++ {
+		e.codeShard(e.parity[i], shards[:e.dataNum], shards[e.dataNum+i])
+	}
+	return
+}
+
+// Verify checks whether the parity shards match the data shards.
+func (e *Encoder) Verify(shards [][]byte) (ok bool, err error) {
+	if len(shards) != e.dataNum+e.parityNum {
+		return false, ErrShardNum
+	}
+	var size int
+	if size, err = checkShardSize(shards, false); err != nil {
+		return
+	}
+	buf := make([]byte, size)
+	for i := 0; i < e.parityNum; i++ {
+		e.codeShard(e.parity[i], shards[:e.dataNum], buf)
+		for k := range buf {
+			if buf[k] != shards[e.dataNum+i][k] {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Reconstruct rebuilds the missing shards which are nil or empty.
+// At least DataNum shards must be present.
+func (e *Encoder) Reconstruct(shards [][]byte) (err error) {
+	if len(shards) != e.dataNum+e.parityNum {
+		return ErrShardNum
+	}
+	var size int
+	if size, err = checkShardSize(shards, true); err != nil {
+		return
+	}
+	present := make([]int, 0, e.dataNum)
+	dataMissing := false
+	for i := range shards {
+		if len(shards[i]) != 0 {
+			if len(present) < e.dataNum {
+				present = append(present, i)
+			}
+		} else if i < e.dataNum {
+			dataMissing = true
+		}
+	}
+	if len(present) < e.dataNum {
+		return ErrTooFewShards
+	}
+	if dataMissing {
+		// rows of the encoding matrix of the present shards
+		matrix := make([][]byte, e.dataNum)
+		inputs := make([][]byte, e.dataNum)
+		for r, i := range present {
+			matrix[r] = e.encodingRow(i)
+			inputs[r] = shards[i]
+		}
+		var decode [][]byte
+		if decode, err = invertMatrix(matrix); err != nil {
+			return
+		}
+		for i := 0; i < e.dataNum; i++ {
+			if len(shards[i]) != 0 {
+				continue
+			}
+			shards[i] = make([]byte, size)
+			e.codeShard(decode[i], inputs, shards[i])
+		}
+	}
+	for i := 0; i < e.parityNum; i++ {
+		if len(shards[e.dataNum+i]) != 0 {
+			continue
+		}
+		shards[e.dataNum+i] = make([]byte, size)
+		e.codeShard(e.parity[i], shards[:e.dataNum], shards[e.dataNum+i])
+	}
+	return
+}
+
+func (e *Encoder) encodingRow(index int) []byte {
+	if index >= e.dataNum {
+		return e.parity[index-e.dataNum]
+	}
+	row := make([]byte, e.dataNum)
+	row[index] = 1
+	return row
+}
+
+// codeShard sets output to the linear combination of the inputs with the given coefficients.
+func (e *Encoder) codeShard(coefficients []byte, inputs [][]byte, output []byte) {
+	for k := range output {
+		output[k] = 0
+	}
+	for j, c := range coefficients {
+		if c == 0 {
+			continue
+		}
+		table := mulTable[c]
+		for k, b := range inputs[j] {
+			output[k] ^= table[b]
+		}
+	}
+}
+
+func checkShardSize(shards [][]byte, allowEmpty bool) (size int, err error) {
+	for _, shard := range shards {
+		if len(shard) == 0 {
+			if allowEmpty {
+				continue
+			}
+			return 0, ErrShardSize
+		}
+		if size == 0 {
+			size = len(shard)
+		} else if size != len(shard) {
+			return 0, ErrShardSize
+		}
+	}
+	if size == 0 {
+		return 0, ErrShardSize
+	}
+	return
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package erasure
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func newTestShards(e *Encoder, size int) [][]byte {
+	shards := make([][]byte, e.DataNum()+e.ParityNum())
+	for i := range shards {
+		shards[i] = make([]byte, size)
+		if i < e.DataNum() {
+			rand.Read(shards[i])
+		}
+	}
+	return shards
+}
+
+func TestEncoder_Reconstruct(t *testing.T) {
+	for _, c := range []struct{ dataNum, parityNum int }{{2, 1}, {4, 2}, {6, 3}, {10, 4}} {
+		e, err := NewEncoder(c.dataNum, c.parityNum)
+		if err != nil {
+			t.Fatalf("new encoder %v+%v: %v", c.dataNum, c.parityNum, err)
+		}
+		shards := newTestShards(e, 1000)
+		if err = e.Encode(shards); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if ok, _ := e.Verify(shards); !ok {
+			t.Fatalf("verify encoded shards of %v+%v failed", c.dataNum, c.parityNum)
+		}
+		origin := make([][]byte, len(shards))
+		for i := range shards {
+			origin[i] = append([]byte(nil), shards[i]...)
+		}
+		// lose as many shards as the parity shards at random positions
+		for round := 0; round < 20; round++ {
+			for _, i := range rand.Perm(len(shards))[:c.parityNum] {
+				shards[i] = nil
+			}
+			if err = e.Reconstruct(shards); err != nil {
+				t.Fatalf("reconstruct %v+%v: %v", c.dataNum, c.parityNum, err)
+			}
+			for i := range shards {
+				if !bytes.Equal(shards[i], origin[i]) {
+					t.Fatalf("shard %v of %v+%v is not reconstructed", i, c.dataNum, c.parityNum)
+				}
+			}
+		}
+	}
+}
+
+func TestEncoder_TooFewShards(t *testing.T) {
+	e, _ := NewEncoder(4, 2)
+	shards := newTestShards(e, 64)
+	if err := e.Encode(shards); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	shards[0], shards[3], shards[5] = nil, nil, nil
+	if err := e.Reconstruct(shards); err != ErrTooFewShards {
+		t.Fatalf("expect ErrTooFewShards, got %v", err)
+	}
+	shards = newTestShards(e, 64)
+	shards[1] = shards[1][:32]
+	if err := e.Encode(shards); err != ErrShardSize {
+		t.Fatalf("expect ErrShardSize, got %v", err)
+	}
+	if _, err := NewEncoder(0, 2); err != ErrInvalidShardNum {
+		t.Fatalf("expect ErrInvalidShardNum, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The Chubao Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package erasure
+
+import (
+	"errors"
+)
+
+const (
+	// x^8 + x^4 + x^3 + x^2 + 1
+	generatorPolynomial = 0x11d
+)
+
+var (
+	expTable [510]byte
+	logTable [256]byte
+	mulTable [256][256]byte
+
+	errSingularMatrix = errors.New("matrix is singular")
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = byte(x)
+		expTable[i+255] = byte(x)
+		logTable[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= generatorPolynomial
+		}
+	}
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			mulTable[a][b] = expTable[int(logTable[a])+int(logTable[b])]
+		}
+	}
+}
+
+func galMultiply(a, b byte) byte {
+	return mulTable[a][b]
+}
+
+func galInverse(a byte) byte {
+	if a == 0 {
+		panic("erasure: inverse of zero")
+	}
+	return expTable[255-int(logTable[a])]
+}
+
+// invertMatrix returns the inverse of the square matrix by Gauss-Jordan elimination.
+func invertMatrix(matrix [][]byte) (inverse [][]byte, err error) {
+	n := len(matrix)
+	work := make([][]byte, n)
+	for i := range matrix {
+		work[i] = make([]byte, 2*n)
+		copy(work[i], matrix[i])
+		work[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if work[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, errSingularMatrix
+		}
+		work[col], work[pivot] = work[pivot], work[col]
+		if c := work[col][col]; c != 1 {
+			scale := galInverse(c)
+			for k := range work[col] {
+				work[col][k] = galMultiply(work[col][k], scale)
+			}
+		}
+		for row := 0; row < n; row++ {
+			if row == col || work[row][col] == 0 {
+				continue
+			}
+			factor := work[row][col]
+			for k := range work[row] {
+				work[row][k] ^= galMultiply(factor, work[col][k])
+			}
+		}
+	}
+	inverse = make([][]byte, n)
+	for i := range work {
+		inverse[i] = work[i][n:]
+	}
+	return
+}
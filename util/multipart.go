@@ -16,8 +16,10 @@ package util
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -26,6 +28,10 @@ const (
 	multipartIDMetaLength = 25
 	multipartIDFlagLength = 2
 	multipartIDDelimiter  = "x"
+
+	versionIDRandomLength = 15
+	versionIDFlagNormal   = '0'
+	versionIDFlagNull     = 'n'
 )
 
 type MultipartID string
@@ -71,12 +77,15 @@ func MultipartIDFromString(src string) MultipartID {
 }
 
 func CreateMultipartID(mpId uint64) MultipartID {
-	var (
-		mpIdLength  string
-		multipartId string
-	)
+	nextId := strings.ReplaceAll(uuid.New().String(), "-", "")
+	return MultipartID(nextId + multipartIDSuffix(mpId))
+}
+
+// multipartIDSuffix returns the special char 'x' and the meta partition id which are appended after
+// the generated id, so that the meta partition can be found by the id.
+func multipartIDSuffix(mpId uint64) string {
+	var mpIdLength string
 
-	// Append special char 'x' and meta partition id after generated multipart id.
 	// If appended string length is less then 25, completion using random string
 	tempLength := len(strconv.FormatUint(mpId, 10))
 
@@ -89,14 +98,31 @@ func CreateMultipartID(mpId uint64) MultipartID {
 		mpIdLength += strconv.Itoa(tempLength)
 	}
 	appendMultipart := fmt.Sprintf("%s%d", mpIdLength, mpId)
-	nextId := strings.ReplaceAll(uuid.New().String(), "-", "")
 	if len(appendMultipart) < multipartIDMetaLength-1 {
 		l := multipartIDMetaLength - 1 - len(appendMultipart)
 		t := strings.ReplaceAll(uuid.New().String(), "-", "")
 		r := string([]rune(t)[:l])
-		multipartId = fmt.Sprintf("%s%s%s%s", nextId, multipartIDDelimiter, appendMultipart, r)
-	} else {
-		multipartId = fmt.Sprintf("%s%s%s", nextId, multipartIDDelimiter, appendMultipart)
+		return fmt.Sprintf("%s%s%s", multipartIDDelimiter, appendMultipart, r)
+	}
+	return fmt.Sprintf("%s%s", multipartIDDelimiter, appendMultipart)
+}
+
+// CreateVersionID creates an object version ID which shares the same layout with multipart ID.
+// The generated part starts with the inverted timestamp in hex, so that the newer version
+// of the same object sorts before the older ones. The last char of the generated part marks
+// whether the version is the null version, which is the object stored before versioning is enabled.
+func CreateVersionID(mpId uint64, ts time.Time, null bool) MultipartID {
+	var flag = versionIDFlagNormal
+	if null {
+		flag = versionIDFlagNull
 	}
-	return MultipartID(multipartId)
+	random := strings.ReplaceAll(uuid.New().String(), "-", "")[:versionIDRandomLength]
+	nextId := fmt.Sprintf("%016x%s%c", uint64(math.MaxInt64-ts.UnixNano()), random, flag)
+	return MultipartID(nextId + multipartIDSuffix(mpId))
+}
+
+// IsNullVersionID checks whether the version ID is created for the null version.
+func IsNullVersionID(id string) bool {
+	var flagIndex = len(id) - multipartIDMetaLength - 1
+	return flagIndex >= 0 && id[flagIndex] == versionIDFlagNull
 }